@@ -5,24 +5,46 @@ package internal // import "github.com/open-telemetry/opentelemetry-collector-co
 
 import (
 	"errors"
+	"fmt"
 	"strings"
 	"time"
 
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/pdatatest/plogtest"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/pdatatest/pmetrictest"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/pdatatest/ptracetest"
+)
+
+// Signal is the type of telemetry compared by the golden tester.
+type Signal string
+
+const (
+	SignalMetrics Signal = "metrics"
+	SignalTraces  Signal = "traces"
+	SignalLogs    Signal = "logs"
 )
 
 type Config struct {
-	ExpectedFile    string
-	WriteExpected   bool
-	CompareOptions  []pmetrictest.CompareMetricsOption
-	OTLPEndpoint    string
-	OTLPHTTPEndoint string
-	Timeout         time.Duration
+	ExpectedFile  string
+	WriteExpected bool
+	// Record writes the first payload received to the expected file
+	// without comparing it.
+	Record               bool
+	Signal               Signal
+	CompareOptions       []pmetrictest.CompareMetricsOption
+	TracesCompareOptions []ptracetest.CompareTracesOption
+	LogsCompareOptions   []plogtest.CompareLogsOption
+	OTLPEndpoint         string
+	OTLPHTTPEndoint      string
+	Timeout              time.Duration
 }
 
 func ReadConfig(args []string) (*Config, error) {
 	opts := []pmetrictest.CompareMetricsOption{}
+	traceOpts := []ptracetest.CompareTracesOption{}
+	logOpts := []plogtest.CompareLogsOption{}
 	writeExpected := false
+	record := false
+	signal := SignalMetrics
 	var expectedFile string
 	var otlpEndpoint string
 	var otlpHTTPEndpoint string
@@ -58,12 +80,50 @@ func ReadConfig(args []string) (*Config, error) {
 				return nil, errors.New("--otlp-endpoint requires an argument")
 			}
 			otlpHTTPEndpoint = args[i]
+		case "--signal":
+			i++
+			if i == len(args) {
+				return nil, errors.New("--signal requires an argument")
+			}
+			switch Signal(args[i]) {
+			case SignalMetrics, SignalTraces, SignalLogs:
+				signal = Signal(args[i])
+			default:
+				return nil, fmt.Errorf("--signal must be one of %s, %s or %s, got %q", SignalMetrics, SignalTraces, SignalLogs, args[i])
+			}
 		case "--write-expected":
 			writeExpected = true
+		case "--record":
+			record = true
 		case "--ignore-start-timestamp":
 			opts = append(opts, pmetrictest.IgnoreStartTimestamp())
+			traceOpts = append(traceOpts, ptracetest.IgnoreStartTimestamp())
 		case "--ignore-timestamp":
 			opts = append(opts, pmetrictest.IgnoreTimestamp())
+			traceOpts = append(traceOpts, ptracetest.IgnoreStartTimestamp(), ptracetest.IgnoreEndTimestamp())
+			logOpts = append(logOpts, plogtest.IgnoreTimestamp(), plogtest.IgnoreObservedTimestamp())
+		case "--ignore-end-timestamp":
+			traceOpts = append(traceOpts, ptracetest.IgnoreEndTimestamp())
+		case "--ignore-observed-timestamp":
+			logOpts = append(logOpts, plogtest.IgnoreObservedTimestamp())
+		case "--ignore-trace-id":
+			traceOpts = append(traceOpts, ptracetest.IgnoreTraceID())
+			logOpts = append(logOpts, plogtest.IgnoreTraceID())
+		case "--ignore-span-id":
+			traceOpts = append(traceOpts, ptracetest.IgnoreSpanID(), ptracetest.IgnoreParentSpanID())
+			logOpts = append(logOpts, plogtest.IgnoreSpanID())
+		case "--ignore-resource-spans-order":
+			traceOpts = append(traceOpts, ptracetest.IgnoreResourceSpansOrder())
+		case "--ignore-scope-spans-order":
+			traceOpts = append(traceOpts, ptracetest.IgnoreScopeSpansOrder())
+		case "--ignore-spans-order":
+			traceOpts = append(traceOpts, ptracetest.IgnoreSpansOrder())
+		case "--ignore-resource-logs-order":
+			logOpts = append(logOpts, plogtest.IgnoreResourceLogsOrder())
+		case "--ignore-scope-logs-order":
+			logOpts = append(logOpts, plogtest.IgnoreScopeLogsOrder())
+		case "--ignore-log-records-order":
+			logOpts = append(logOpts, plogtest.IgnoreLogRecordsOrder())
 		case "--ignore-metrics-data-points-order":
 			opts = append(opts, pmetrictest.IgnoreMetricDataPointsOrder())
 		case "--ignore-metrics-order":
@@ -78,6 +138,8 @@ func ReadConfig(args []string) (*Config, error) {
 			opts = append(opts, pmetrictest.IgnoreExemplarSlice())
 		case "--ignore-scope-version":
 			opts = append(opts, pmetrictest.IgnoreScopeVersion())
+			traceOpts = append(traceOpts, ptracetest.IgnoreScopeSpanInstrumentationScopeVersion())
+			logOpts = append(logOpts, plogtest.IgnoreScopeLogsVersion())
 		case "--ignore-data-points-attributes-order":
 			opts = append(opts, pmetrictest.IgnoreDatapointAttributesOrder())
 		case "--ignore-resource-attribute-value":
@@ -86,12 +148,26 @@ func ReadConfig(args []string) (*Config, error) {
 				return nil, errors.New("--ignore-resource-attribute-value requires an argument")
 			}
 			opts = append(opts, pmetrictest.IgnoreResourceAttributeValue(args[i]))
+			traceOpts = append(traceOpts, ptracetest.IgnoreResourceAttributeValue(args[i]))
+			logOpts = append(logOpts, plogtest.IgnoreResourceAttributeValue(args[i]))
 		case "--ignore-metric-attribute-value":
 			i++
 			if i == len(args) {
 				return nil, errors.New("--ignore-metric-attribute-value requires an argument")
 			}
 			opts = append(opts, pmetrictest.IgnoreMetricAttributeValue(args[i]))
+		case "--ignore-span-attribute-value":
+			i++
+			if i == len(args) {
+				return nil, errors.New("--ignore-span-attribute-value requires an argument")
+			}
+			traceOpts = append(traceOpts, ptracetest.IgnoreSpanAttributeValue(args[i]))
+		case "--ignore-log-record-attribute-value":
+			i++
+			if i == len(args) {
+				return nil, errors.New("--ignore-log-record-attribute-value requires an argument")
+			}
+			logOpts = append(logOpts, plogtest.IgnoreLogRecordAttributeValue(args[i]))
 		case "--ignore-metric-values":
 			if i < len(args)-1 && !strings.HasPrefix(args[i+1], "--") {
 				i++
@@ -101,12 +177,19 @@ func ReadConfig(args []string) (*Config, error) {
 			}
 		}
 	}
+	if record && expectedFile == "" {
+		return nil, errors.New("--record requires --expected")
+	}
 	return &Config{
-		WriteExpected:   writeExpected,
-		CompareOptions:  opts,
-		ExpectedFile:    expectedFile,
-		OTLPEndpoint:    otlpEndpoint,
-		OTLPHTTPEndoint: otlpHTTPEndpoint,
-		Timeout:         timeout,
+		WriteExpected:        writeExpected,
+		Record:               record,
+		Signal:               signal,
+		CompareOptions:       opts,
+		TracesCompareOptions: traceOpts,
+		LogsCompareOptions:   logOpts,
+		ExpectedFile:         expectedFile,
+		OTLPEndpoint:         otlpEndpoint,
+		OTLPHTTPEndoint:      otlpHTTPEndpoint,
+		Timeout:              timeout,
 	}, nil
 }
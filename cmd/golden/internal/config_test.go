@@ -9,7 +9,9 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/pdatatest/plogtest"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/pdatatest/pmetrictest"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/pdatatest/ptracetest"
 )
 
 func Test_ReadConfig(t *testing.T) {
@@ -33,8 +35,9 @@ func Test_ReadConfig(t *testing.T) {
 				"--ignore-start-timestamp",
 			},
 			cfg: &Config{
-				ExpectedFile:   "foo.yaml",
-				CompareOptions: []pmetrictest.CompareMetricsOption{pmetrictest.IgnoreStartTimestamp()},
+				ExpectedFile:         "foo.yaml",
+				CompareOptions:       []pmetrictest.CompareMetricsOption{pmetrictest.IgnoreStartTimestamp()},
+				TracesCompareOptions: []ptracetest.CompareTracesOption{ptracetest.IgnoreStartTimestamp()},
 			},
 		},
 		{
@@ -88,6 +91,95 @@ func Test_ReadConfig(t *testing.T) {
 				CompareOptions: []pmetrictest.CompareMetricsOption{pmetrictest.IgnoreMetricValues("foo.bar")},
 			},
 		},
+		{
+			name: "traces",
+			args: []string{
+				"--expected", "foo.yaml",
+				"--signal", "traces",
+				"--ignore-timestamp",
+				"--ignore-span-id",
+				"--ignore-spans-order",
+				"--ignore-span-attribute-value", "http.url",
+			},
+			cfg: &Config{
+				ExpectedFile:   "foo.yaml",
+				Signal:         SignalTraces,
+				CompareOptions: []pmetrictest.CompareMetricsOption{pmetrictest.IgnoreTimestamp()},
+				TracesCompareOptions: []ptracetest.CompareTracesOption{
+					ptracetest.IgnoreStartTimestamp(),
+					ptracetest.IgnoreEndTimestamp(),
+					ptracetest.IgnoreSpanID(),
+					ptracetest.IgnoreParentSpanID(),
+					ptracetest.IgnoreSpansOrder(),
+					ptracetest.IgnoreSpanAttributeValue("http.url"),
+				},
+				LogsCompareOptions: []plogtest.CompareLogsOption{
+					plogtest.IgnoreTimestamp(),
+					plogtest.IgnoreObservedTimestamp(),
+					plogtest.IgnoreSpanID(),
+				},
+			},
+		},
+		{
+			name: "logs",
+			args: []string{
+				"--expected", "foo.yaml",
+				"--signal", "logs",
+				"--ignore-trace-id",
+				"--ignore-log-records-order",
+				"--ignore-resource-attribute-value", "host.name",
+				"--ignore-log-record-attribute-value", "user.id",
+			},
+			cfg: &Config{
+				ExpectedFile:   "foo.yaml",
+				Signal:         SignalLogs,
+				CompareOptions: []pmetrictest.CompareMetricsOption{pmetrictest.IgnoreResourceAttributeValue("host.name")},
+				TracesCompareOptions: []ptracetest.CompareTracesOption{
+					ptracetest.IgnoreTraceID(),
+					ptracetest.IgnoreResourceAttributeValue("host.name"),
+				},
+				LogsCompareOptions: []plogtest.CompareLogsOption{
+					plogtest.IgnoreTraceID(),
+					plogtest.IgnoreLogRecordsOrder(),
+					plogtest.IgnoreResourceAttributeValue("host.name"),
+					plogtest.IgnoreLogRecordAttributeValue("user.id"),
+				},
+			},
+		},
+		{
+			name: "record",
+			args: []string{
+				"--expected", "foo.yaml",
+				"--record",
+			},
+			cfg: &Config{
+				ExpectedFile:   "foo.yaml",
+				Record:         true,
+				Signal:         SignalMetrics,
+				CompareOptions: []pmetrictest.CompareMetricsOption{},
+			},
+		},
+		{
+			name: "record without expected",
+			args: []string{"--record"},
+			err:  "--record requires --expected",
+		},
+		{
+			name: "invalid signal",
+			args: []string{
+				"--expected", "foo.yaml",
+				"--signal", "profiles",
+			},
+			err: `--signal must be one of metrics, traces or logs, got "profiles"`,
+		},
+		{
+			name: "missing signal",
+			args: []string{
+				"--expected", "foo.yaml",
+				"--signal",
+			},
+			err: "--signal requires an argument",
+		},
 	}
 
 	for _, test := range tests {
@@ -99,6 +191,12 @@ func Test_ReadConfig(t *testing.T) {
 				assert.Equal(tt, test.cfg.WriteExpected, cfg.WriteExpected)
 				assert.Equal(tt, test.cfg.ExpectedFile, cfg.ExpectedFile)
 				assert.Len(tt, test.cfg.CompareOptions, len(cfg.CompareOptions))
+				assert.Len(tt, test.cfg.TracesCompareOptions, len(cfg.TracesCompareOptions))
+				assert.Len(tt, test.cfg.LogsCompareOptions, len(cfg.LogsCompareOptions))
+				assert.Equal(tt, test.cfg.Record, cfg.Record)
+				if test.cfg.Signal != "" {
+					assert.Equal(tt, test.cfg.Signal, cfg.Signal)
+				}
 			}
 		})
 	}
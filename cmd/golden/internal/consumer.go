@@ -5,16 +5,24 @@ package internal // import "github.com/open-telemetry/opentelemetry-collector-co
 
 import (
 	"context"
-	"testing"
+	"sync"
 
 	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/plog"
 	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/golden"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/pdatatest/plogtest"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/pdatatest/pmetrictest"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/pdatatest/ptracetest"
 )
 
-var _ consumer.Metrics = (*MetricsSink)(nil)
+var (
+	_ consumer.Metrics = (*Sink)(nil)
+	_ consumer.Traces  = (*Sink)(nil)
+	_ consumer.Logs    = (*Sink)(nil)
+)
 
 // AttemptError tracks an error from a specific comparison attempt
 type AttemptError struct {
@@ -22,65 +30,124 @@ type AttemptError struct {
 	Error         error
 }
 
-type MetricsSink struct {
-	cfg            *Config
-	noExpected     bool
-	expected       pmetric.Metrics
-	DoneChan       chan struct{}
-	Errors         []AttemptError
-	attemptCounter int
+// Sink compares the payloads it receives to the expected file of the
+// configured signal until one matches.
+type Sink struct {
+	cfg             *Config
+	noExpected      bool
+	expectedMetrics pmetric.Metrics
+	expectedTraces  ptrace.Traces
+	expectedLogs    plog.Logs
+	DoneChan        chan struct{}
+	Errors          []AttemptError
+	attemptCounter  int
+	mu              sync.Mutex
+	done            bool
 }
 
-func (*MetricsSink) Capabilities() consumer.Capabilities {
+func (*Sink) Capabilities() consumer.Capabilities {
 	return consumer.Capabilities{
 		MutatesData: false,
 	}
 }
 
-func (m *MetricsSink) ConsumeMetrics(_ context.Context, md pmetric.Metrics) error {
-	if m.noExpected {
-		if m.cfg.WriteExpected {
-			err := golden.WriteMetricsToFile(m.cfg.ExpectedFile, md)
-			if err != nil {
+func (s *Sink) ConsumeMetrics(_ context.Context, md pmetric.Metrics) error {
+	return s.consume(
+		func() error { return pmetrictest.CompareMetrics(s.expectedMetrics, md, s.cfg.CompareOptions...) },
+		func() error { return golden.WriteMetricsToFile(s.cfg.ExpectedFile, md) },
+	)
+}
+
+func (s *Sink) ConsumeTraces(_ context.Context, td ptrace.Traces) error {
+	return s.consume(
+		func() error { return ptracetest.CompareTraces(s.expectedTraces, td, s.cfg.TracesCompareOptions...) },
+		func() error { return golden.WriteTracesToFile(s.cfg.ExpectedFile, td) },
+	)
+}
+
+func (s *Sink) ConsumeLogs(_ context.Context, ld plog.Logs) error {
+	return s.consume(
+		func() error { return plogtest.CompareLogs(s.expectedLogs, ld, s.cfg.LogsCompareOptions...) },
+		func() error { return golden.WriteLogsToFile(s.cfg.ExpectedFile, ld) },
+	)
+}
+
+// consume compares a payload to the expected one, writing it to the
+// expected file when recording, when there is no expected file yet or when
+// it matches and WriteExpected is set. Payloads received once done are
+// ignored.
+func (s *Sink) consume(compare, write func() error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.done {
+		return nil
+	}
+	if s.cfg.Record || s.noExpected {
+		if s.cfg.Record || s.cfg.WriteExpected {
+			if err := write(); err != nil {
 				return err
 			}
 		}
-		close(m.DoneChan)
+		s.finish()
 		return nil
 	}
-	m.attemptCounter++
-	err := pmetrictest.CompareMetrics(m.expected, md, m.cfg.CompareOptions...)
+	s.attemptCounter++
+	err := compare()
 	if err == nil {
 		// Clear errors on success
-		m.Errors = nil
-		if m.cfg.WriteExpected {
-			err = golden.WriteMetrics(&testing.T{}, m.cfg.ExpectedFile, md)
-			if err != nil {
+		s.Errors = nil
+		if s.cfg.WriteExpected {
+			if err = write(); err != nil {
 				return err
 			}
 		}
-		close(m.DoneChan)
+		s.finish()
 	} else {
 		// Append error with attempt number
-		m.Errors = append(m.Errors, AttemptError{
-			AttemptNumber: m.attemptCounter,
+		s.Errors = append(s.Errors, AttemptError{
+			AttemptNumber: s.attemptCounter,
 			Error:         err,
 		})
 	}
 	return nil
 }
 
-func NewConsumer(cfg *Config) (*MetricsSink, error) {
-	expected, err := golden.ReadMetrics(cfg.ExpectedFile)
-	noExpected := err != nil
+func (s *Sink) finish() {
+	s.done = true
+	close(s.DoneChan)
+}
+
+// AttemptErrors returns the errors of the comparisons so far.
+func (s *Sink) AttemptErrors() []AttemptError {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.Errors
+}
+
+// NewConsumer returns a Sink comparing the payloads of the configured
+// signal to the expected file. The expected file may only be missing when
+// it is to be written.
+func NewConsumer(cfg *Config) (*Sink, error) {
+	s := &Sink{
+		cfg:      cfg,
+		DoneChan: make(chan struct{}),
+		Errors:   []AttemptError{},
+	}
+	if cfg.Record {
+		return s, nil
+	}
+	var err error
+	switch cfg.Signal {
+	case SignalTraces:
+		s.expectedTraces, err = golden.ReadTraces(cfg.ExpectedFile)
+	case SignalLogs:
+		s.expectedLogs, err = golden.ReadLogs(cfg.ExpectedFile)
+	default:
+		s.expectedMetrics, err = golden.ReadMetrics(cfg.ExpectedFile)
+	}
+	s.noExpected = err != nil
 	if err != nil && !cfg.WriteExpected {
 		return nil, err
 	}
-	return &MetricsSink{
-		expected:   expected,
-		cfg:        cfg,
-		noExpected: noExpected,
-		DoneChan:   make(chan struct{}),
-		Errors:     []AttemptError{},
-	}, nil
+	return s, nil
 }
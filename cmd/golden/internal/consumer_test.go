@@ -4,13 +4,21 @@
 package internal
 
 import (
+	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
 	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
 
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/golden"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/pdatatest/plogtest"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/pdatatest/pmetrictest"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/pdatatest/ptracetest"
 )
 
 func TestConsumeMetrics(t *testing.T) {
@@ -138,3 +146,92 @@ func TestConsumeMetrics_AttemptCounterIncrementsCorrectly(t *testing.T) {
 		require.Equal(t, i+1, s.Errors[i].AttemptNumber, "attempt number should increment correctly")
 	}
 }
+
+func newTestTraces(spanName string, traceID pcommon.TraceID) ptrace.Traces {
+	td := ptrace.NewTraces()
+	rs := td.ResourceSpans().AppendEmpty()
+	rs.Resource().Attributes().PutStr("service.name", "checkout")
+	span := rs.ScopeSpans().AppendEmpty().Spans().AppendEmpty()
+	span.SetName(spanName)
+	span.SetTraceID(traceID)
+	span.SetSpanID(pcommon.SpanID{1, 2, 3, 4, 5, 6, 7, 8})
+	span.SetStartTimestamp(pcommon.NewTimestampFromTime(time.Now()))
+	return td
+}
+
+func TestConsumeTraces(t *testing.T) {
+	s, err := NewConsumer(&Config{
+		ExpectedFile: filepath.Join("testdata", "expected_traces.yaml"),
+		Signal:       SignalTraces,
+		TracesCompareOptions: []ptracetest.CompareTracesOption{
+			ptracetest.IgnoreStartTimestamp(),
+			ptracetest.IgnoreSpanID(),
+		},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, s.ConsumeTraces(t.Context(), newTestTraces("GET /cart", pcommon.TraceID{1})))
+	require.Len(t, s.AttemptErrors(), 1)
+
+	traceID := pcommon.TraceID{0x8c, 0x8b, 0x17, 0x65, 0xa7, 0xb0, 0xac, 0xf0, 0xb6, 0x6a, 0xa4, 0x62, 0x3f, 0xcb, 0x7b, 0xd5}
+	require.NoError(t, s.ConsumeTraces(t.Context(), newTestTraces("GET /checkout", traceID)))
+	require.Empty(t, s.AttemptErrors())
+	<-s.DoneChan
+
+	// Payloads received once done are ignored.
+	require.NoError(t, s.ConsumeTraces(t.Context(), newTestTraces("GET /cart", traceID)))
+	require.Empty(t, s.AttemptErrors())
+}
+
+func TestConsumeLogs(t *testing.T) {
+	s, err := NewConsumer(&Config{
+		ExpectedFile: filepath.Join("testdata", "expected_logs.yaml"),
+		Signal:       SignalLogs,
+		LogsCompareOptions: []plogtest.CompareLogsOption{
+			plogtest.IgnoreTraceID(),
+			plogtest.IgnoreSpanID(),
+			plogtest.IgnoreTimestamp(),
+		},
+	})
+	require.NoError(t, err)
+
+	ld := plog.NewLogs()
+	rl := ld.ResourceLogs().AppendEmpty()
+	rl.Resource().Attributes().PutStr("service.name", "checkout")
+	lr := rl.ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
+	lr.Body().SetStr("order placed")
+	lr.SetTraceID(pcommon.TraceID{1})
+	lr.SetTimestamp(pcommon.NewTimestampFromTime(time.Now()))
+	require.NoError(t, s.ConsumeLogs(t.Context(), ld))
+	require.Empty(t, s.AttemptErrors())
+	<-s.DoneChan
+}
+
+func TestConsumeMissingExpected(t *testing.T) {
+	_, err := NewConsumer(&Config{
+		ExpectedFile: filepath.Join("testdata", "missing.yaml"),
+		Signal:       SignalLogs,
+	})
+	require.Error(t, err)
+}
+
+func TestRecord(t *testing.T) {
+	// Record overwrites an existing expected file without comparing.
+	expectedFile := filepath.Join(t.TempDir(), "expected.yaml")
+	require.NoError(t, os.WriteFile(expectedFile, []byte("resourceSpans: []\n"), 0o600))
+	s, err := NewConsumer(&Config{
+		ExpectedFile: expectedFile,
+		Signal:       SignalTraces,
+		Record:       true,
+	})
+	require.NoError(t, err)
+
+	td := newTestTraces("GET /checkout", pcommon.TraceID{1})
+	require.NoError(t, s.ConsumeTraces(t.Context(), td))
+	<-s.DoneChan
+	require.NoError(t, s.ConsumeTraces(t.Context(), newTestTraces("GET /cart", pcommon.TraceID{1})))
+
+	recorded, err := golden.ReadTraces(expectedFile)
+	require.NoError(t, err)
+	require.NoError(t, ptracetest.CompareTraces(td, recorded))
+}
@@ -83,7 +83,15 @@ func run(args []string) error {
 		BuildInfo: component.BuildInfo{},
 	}
 
-	otlpReceiver, err := factory.CreateMetrics(context.Background(), set, receiverConfig, sink)
+	var otlpReceiver component.Component
+	switch cfg.Signal {
+	case internal.SignalTraces:
+		otlpReceiver, err = factory.CreateTraces(context.Background(), set, receiverConfig, sink)
+	case internal.SignalLogs:
+		otlpReceiver, err = factory.CreateLogs(context.Background(), set, receiverConfig, sink)
+	default:
+		otlpReceiver, err = factory.CreateMetrics(context.Background(), set, receiverConfig, sink)
+	}
 	if err != nil {
 		return err
 	}
@@ -105,10 +113,10 @@ func run(args []string) error {
 	case <-sink.DoneChan:
 	}
 
-	if len(sink.Errors) > 0 {
+	if attemptErrors := sink.AttemptErrors(); len(attemptErrors) > 0 {
 		var errMsg strings.Builder
-		fmt.Fprintf(&errMsg, "comparison failed with %d error(s):\n", len(sink.Errors))
-		for _, attemptErr := range sink.Errors {
+		fmt.Fprintf(&errMsg, "comparison failed with %d error(s):\n", len(attemptErrors))
+		for _, attemptErr := range attemptErrors {
 			fmt.Fprintf(&errMsg, "  Attempt %d: %v\n", attemptErr.AttemptNumber, attemptErr.Error)
 		}
 		return errors.New(errMsg.String())
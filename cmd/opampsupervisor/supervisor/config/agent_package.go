@@ -3,7 +3,10 @@
 
 package config
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+)
 
 // AgentPackage describes how collector executable updates downloaded by the
 // supervisor are verified. The archive format is not configured here; the
@@ -16,12 +19,15 @@ type AgentPackage struct {
 	_ struct{}
 }
 
-// Verifier configures how downloaded packages are verified. The verification
-// methods themselves (e.g. cosign) are added in later PRs; for now only the
-// no-op verifier is supported.
+// Verifier configures how downloaded packages and local config bundles are
+// verified. Only the no-op and Ed25519 verifiers are supported for now; other
+// verification methods (e.g. cosign) are added in later PRs.
 type Verifier struct {
 	// Type selects the verification method. An empty string disables verification.
 	Type string `mapstructure:"type"`
+	// PublicKeyFile is the PEM encoded public key signatures are verified
+	// with. Required by the ed25519 verifier.
+	PublicKeyFile string `mapstructure:"public_key_file"`
 	// prevent unkeyed literal initialization
 	_ struct{}
 }
@@ -29,6 +35,8 @@ type Verifier struct {
 const (
 	// VerifierTypeNone disables package signature verification.
 	VerifierTypeNone = ""
+	// VerifierTypeEd25519 verifies Ed25519 signatures with a public key.
+	VerifierTypeEd25519 = "ed25519"
 )
 
 // Validate validates the verifier configuration.
@@ -36,6 +44,11 @@ func (v Verifier) Validate() error {
 	switch v.Type {
 	case VerifierTypeNone:
 		return nil
+	case VerifierTypeEd25519:
+		if v.PublicKeyFile == "" {
+			return errors.New("public_key_file must be specified for the ed25519 verifier")
+		}
+		return nil
 	default:
 		return fmt.Errorf("unsupported verifier type: %q", v.Type)
 	}
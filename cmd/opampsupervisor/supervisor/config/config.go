@@ -45,6 +45,9 @@ type Supervisor struct {
 	Telemetry    Telemetry         `mapstructure:"telemetry"`
 	HealthCheck  HealthCheck       `mapstructure:"healthcheck"`
 	Extensions   extensions.Config `mapstructure:"extensions,omitempty"`
+	// LocalConfigSource replaces the OpAMP server with a local directory
+	// the remote configs are read from.
+	LocalConfigSource LocalConfigSource `mapstructure:"local_config_source"`
 }
 
 // Load loads the Supervisor config from a file.
@@ -67,6 +70,12 @@ func Load(configFile string) (Supervisor, error) {
 }
 
 func (s *Supervisor) Validate() error {
+	if err := s.validateConfigSource(); err != nil {
+		return err
+	}
+	if err := s.validateConfigProbationCapabilities(); err != nil {
+		return err
+	}
 	if s.Server.Auth == (component.ID{}) {
 		return nil
 	}
@@ -187,8 +196,10 @@ func (o OpAMPServer) OpaqueHeaders() map[string][]configopaque.String {
 }
 
 func (o OpAMPServer) Validate() error {
+	// Whether the endpoint is required depends on local_config_source, see
+	// [Supervisor.Validate].
 	if o.Endpoint == "" {
-		return errors.New("server::endpoint must be specified")
+		return nil
 	}
 
 	url, err := url.Parse(o.Endpoint)
@@ -231,6 +242,9 @@ type Agent struct {
 	StartupFallbackConfigs []string `mapstructure:"startup_fallback_configs"`
 	// Package configures how collector executable updates are formatted and verified.
 	Package AgentPackage `mapstructure:"package"`
+	// ConfigProbation configures the probation window remote configurations
+	// go through before they are considered working.
+	ConfigProbation ConfigProbation `mapstructure:"config_probation"`
 }
 
 func (a Agent) Validate() error {
@@ -290,6 +304,10 @@ func (a Agent) Validate() error {
 		return err
 	}
 
+	if err := a.validateConfigProbation(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -469,6 +487,9 @@ func DefaultSupervisor() Supervisor {
 			Package: AgentPackage{
 				Verifier: Verifier{Type: VerifierTypeNone},
 			},
+			ConfigProbation: ConfigProbation{
+				CheckInterval: 5 * time.Second,
+			},
 		},
 		Telemetry: Telemetry{
 			Logs: Logs{
@@ -480,5 +501,9 @@ func DefaultSupervisor() Supervisor {
 		HealthCheck: HealthCheck{
 			ServerConfig: serverConfig,
 		},
+		LocalConfigSource: LocalConfigSource{
+			PollInterval: 10 * time.Second,
+			Verifier:     Verifier{Type: VerifierTypeNone},
+		},
 	}
 }
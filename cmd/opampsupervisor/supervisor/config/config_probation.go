@@ -0,0 +1,102 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// ConfigProbation configures the probation window a remote configuration goes
+// through after the Collector reports itself healthy with it. During the
+// window the Supervisor keeps watching the Collector's health and, when a
+// metrics endpoint is set, its own metrics. If the Collector becomes unhealthy
+// or the thresholds are exceeded, the remote configuration is reported as
+// failed and the last working remote configuration is restored.
+type ConfigProbation struct {
+	// Duration is the length of the probation window. Zero disables probation.
+	Duration time.Duration `mapstructure:"duration"`
+	// CheckInterval is how often the Collector is checked during probation.
+	CheckInterval time.Duration `mapstructure:"check_interval"`
+	// MetricsEndpoint is the URL of the Collector's own metrics in the
+	// Prometheus text format, e.g. http://localhost:8888/metrics. When empty,
+	// only the Collector's health is watched.
+	MetricsEndpoint string `mapstructure:"metrics_endpoint"`
+	// MaxSendFailedItems is the number of items exporters may fail to send
+	// during probation.
+	MaxSendFailedItems int64 `mapstructure:"max_send_failed_items"`
+	// MaxRefusedItems is the number of items receivers and processors may
+	// refuse during probation.
+	MaxRefusedItems int64 `mapstructure:"max_refused_items"`
+	// MinSentRatio is the minimum ratio of the items exporters sent to the
+	// items receivers accepted during probation, checked at the end of the
+	// window, e.g. to catch a config dropping all the data. Zero disables the
+	// check.
+	MinSentRatio float64 `mapstructure:"min_sent_ratio"`
+	// prevent unkeyed literal initialization
+	_ struct{}
+}
+
+// Enabled returns true if remote configurations go through probation.
+func (p ConfigProbation) Enabled() bool {
+	return p.Duration > 0
+}
+
+func (a Agent) validateConfigProbation() error {
+	p := a.ConfigProbation
+	if p.Duration < 0 {
+		return errors.New("agent::config_probation::duration must be non-negative")
+	}
+	if !p.Enabled() {
+		return nil
+	}
+
+	if !a.AutomaticConfigRollback {
+		return errors.New("agent::config_probation requires agent::automatic_config_rollback to be enabled")
+	}
+
+	if p.CheckInterval <= 0 {
+		return errors.New("agent::config_probation::check_interval must be positive")
+	}
+
+	if p.MaxSendFailedItems < 0 {
+		return errors.New("agent::config_probation::max_send_failed_items must be non-negative")
+	}
+
+	if p.MaxRefusedItems < 0 {
+		return errors.New("agent::config_probation::max_refused_items must be non-negative")
+	}
+
+	if p.MinSentRatio < 0 {
+		return errors.New("agent::config_probation::min_sent_ratio must be non-negative")
+	}
+
+	if p.MinSentRatio > 0 && p.MetricsEndpoint == "" {
+		return errors.New("agent::config_probation::min_sent_ratio requires agent::config_probation::metrics_endpoint to be set")
+	}
+
+	if p.MetricsEndpoint != "" {
+		u, err := url.Parse(p.MetricsEndpoint)
+		if err != nil {
+			return fmt.Errorf("invalid URL for agent::config_probation::metrics_endpoint: %w", err)
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return fmt.Errorf(`invalid scheme %q for agent::config_probation::metrics_endpoint, must be one of "http" or "https"`, u.Scheme)
+		}
+	}
+
+	return nil
+}
+
+// validateConfigProbationCapabilities checks that the remote config statuses
+// are reported, as the last working remote config the probation rolls back
+// to is only saved along with them.
+func (s *Supervisor) validateConfigProbationCapabilities() error {
+	if s.Agent.ConfigProbation.Enabled() && !s.Capabilities.ReportsRemoteConfig {
+		return errors.New("agent::config_probation requires capabilities::reports_remote_config to be enabled")
+	}
+	return nil
+}
@@ -657,6 +657,251 @@ func TestValidate(t *testing.T) {
 			},
 			expectedErrorFunc: simpleError("unsupported verifier type"),
 		},
+		{
+			name: "Valid config probation",
+			config: Supervisor{
+				Server: OpAMPServer{
+					Endpoint: "wss://localhost:9090/opamp",
+					TLS:      tlsConfig,
+				},
+				Agent: Agent{
+					Executable:              "${file_path}",
+					OrphanDetectionInterval: 5 * time.Second,
+					ConfigApplyTimeout:      2 * time.Second,
+					BootstrapTimeout:        5 * time.Second,
+					AutomaticConfigRollback: true,
+					ConfigProbation: ConfigProbation{
+						Duration:           time.Minute,
+						CheckInterval:      5 * time.Second,
+						MetricsEndpoint:    "http://localhost:8888/metrics",
+						MaxSendFailedItems: 10,
+						MinSentRatio:       0.5,
+					},
+				},
+				Capabilities: Capabilities{AcceptsRemoteConfig: true, ReportsRemoteConfig: true},
+				Storage:      Storage{Directory: "/etc/opamp-supervisor/storage"},
+				HealthCheck:  defaultHealthCheck,
+			},
+		},
+		{
+			name: "Negative config probation duration",
+			config: Supervisor{
+				Server: OpAMPServer{
+					Endpoint: "wss://localhost:9090/opamp",
+					TLS:      tlsConfig,
+				},
+				Agent: Agent{
+					Executable:              "${file_path}",
+					OrphanDetectionInterval: 5 * time.Second,
+					ConfigApplyTimeout:      2 * time.Second,
+					BootstrapTimeout:        5 * time.Second,
+					AutomaticConfigRollback: true,
+					ConfigProbation: ConfigProbation{
+						Duration: -time.Minute,
+					},
+				},
+				Capabilities: Capabilities{AcceptsRemoteConfig: true},
+				Storage:      Storage{Directory: "/etc/opamp-supervisor/storage"},
+				HealthCheck:  defaultHealthCheck,
+			},
+			expectedErrorFunc: simpleError("agent::config_probation::duration must be non-negative"),
+		},
+		{
+			name: "Config probation without automatic config rollback",
+			config: Supervisor{
+				Server: OpAMPServer{
+					Endpoint: "wss://localhost:9090/opamp",
+					TLS:      tlsConfig,
+				},
+				Agent: Agent{
+					Executable:              "${file_path}",
+					OrphanDetectionInterval: 5 * time.Second,
+					ConfigApplyTimeout:      2 * time.Second,
+					BootstrapTimeout:        5 * time.Second,
+					ConfigProbation: ConfigProbation{
+						Duration:      time.Minute,
+						CheckInterval: 5 * time.Second,
+					},
+				},
+				Capabilities: Capabilities{AcceptsRemoteConfig: true},
+				Storage:      Storage{Directory: "/etc/opamp-supervisor/storage"},
+				HealthCheck:  defaultHealthCheck,
+			},
+			expectedErrorFunc: simpleError("agent::config_probation requires agent::automatic_config_rollback to be enabled"),
+		},
+		{
+			name: "Invalid config probation check interval",
+			config: Supervisor{
+				Server: OpAMPServer{
+					Endpoint: "wss://localhost:9090/opamp",
+					TLS:      tlsConfig,
+				},
+				Agent: Agent{
+					Executable:              "${file_path}",
+					OrphanDetectionInterval: 5 * time.Second,
+					ConfigApplyTimeout:      2 * time.Second,
+					BootstrapTimeout:        5 * time.Second,
+					AutomaticConfigRollback: true,
+					ConfigProbation: ConfigProbation{
+						Duration: time.Minute,
+					},
+				},
+				Capabilities: Capabilities{AcceptsRemoteConfig: true},
+				Storage:      Storage{Directory: "/etc/opamp-supervisor/storage"},
+				HealthCheck:  defaultHealthCheck,
+			},
+			expectedErrorFunc: simpleError("agent::config_probation::check_interval must be positive"),
+		},
+		{
+			name: "Invalid config probation max send failed items",
+			config: Supervisor{
+				Server: OpAMPServer{
+					Endpoint: "wss://localhost:9090/opamp",
+					TLS:      tlsConfig,
+				},
+				Agent: Agent{
+					Executable:              "${file_path}",
+					OrphanDetectionInterval: 5 * time.Second,
+					ConfigApplyTimeout:      2 * time.Second,
+					BootstrapTimeout:        5 * time.Second,
+					AutomaticConfigRollback: true,
+					ConfigProbation: ConfigProbation{
+						Duration:           time.Minute,
+						CheckInterval:      5 * time.Second,
+						MaxSendFailedItems: -1,
+					},
+				},
+				Capabilities: Capabilities{AcceptsRemoteConfig: true},
+				Storage:      Storage{Directory: "/etc/opamp-supervisor/storage"},
+				HealthCheck:  defaultHealthCheck,
+			},
+			expectedErrorFunc: simpleError("agent::config_probation::max_send_failed_items must be non-negative"),
+		},
+		{
+			name: "Invalid config probation max refused items",
+			config: Supervisor{
+				Server: OpAMPServer{
+					Endpoint: "wss://localhost:9090/opamp",
+					TLS:      tlsConfig,
+				},
+				Agent: Agent{
+					Executable:              "${file_path}",
+					OrphanDetectionInterval: 5 * time.Second,
+					ConfigApplyTimeout:      2 * time.Second,
+					BootstrapTimeout:        5 * time.Second,
+					AutomaticConfigRollback: true,
+					ConfigProbation: ConfigProbation{
+						Duration:        time.Minute,
+						CheckInterval:   5 * time.Second,
+						MaxRefusedItems: -1,
+					},
+				},
+				Capabilities: Capabilities{AcceptsRemoteConfig: true},
+				Storage:      Storage{Directory: "/etc/opamp-supervisor/storage"},
+				HealthCheck:  defaultHealthCheck,
+			},
+			expectedErrorFunc: simpleError("agent::config_probation::max_refused_items must be non-negative"),
+		},
+		{
+			name: "Invalid config probation metrics endpoint scheme",
+			config: Supervisor{
+				Server: OpAMPServer{
+					Endpoint: "wss://localhost:9090/opamp",
+					TLS:      tlsConfig,
+				},
+				Agent: Agent{
+					Executable:              "${file_path}",
+					OrphanDetectionInterval: 5 * time.Second,
+					ConfigApplyTimeout:      2 * time.Second,
+					BootstrapTimeout:        5 * time.Second,
+					AutomaticConfigRollback: true,
+					ConfigProbation: ConfigProbation{
+						Duration:        time.Minute,
+						CheckInterval:   5 * time.Second,
+						MetricsEndpoint: "ws://localhost:8888/metrics",
+					},
+				},
+				Capabilities: Capabilities{AcceptsRemoteConfig: true},
+				Storage:      Storage{Directory: "/etc/opamp-supervisor/storage"},
+				HealthCheck:  defaultHealthCheck,
+			},
+			expectedErrorFunc: simpleError(`invalid scheme "ws" for agent::config_probation::metrics_endpoint, must be one of "http" or "https"`),
+		},
+		{
+			name: "Negative config probation min sent ratio",
+			config: Supervisor{
+				Server: OpAMPServer{
+					Endpoint: "wss://localhost:9090/opamp",
+					TLS:      tlsConfig,
+				},
+				Agent: Agent{
+					Executable:              "${file_path}",
+					OrphanDetectionInterval: 5 * time.Second,
+					ConfigApplyTimeout:      2 * time.Second,
+					BootstrapTimeout:        5 * time.Second,
+					AutomaticConfigRollback: true,
+					ConfigProbation: ConfigProbation{
+						Duration:        time.Minute,
+						CheckInterval:   5 * time.Second,
+						MetricsEndpoint: "http://localhost:8888/metrics",
+						MinSentRatio:    -0.5,
+					},
+				},
+				Capabilities: Capabilities{AcceptsRemoteConfig: true},
+				Storage:      Storage{Directory: "/etc/opamp-supervisor/storage"},
+				HealthCheck:  defaultHealthCheck,
+			},
+			expectedErrorFunc: simpleError("agent::config_probation::min_sent_ratio must be non-negative"),
+		},
+		{
+			name: "Config probation min sent ratio without metrics endpoint",
+			config: Supervisor{
+				Server: OpAMPServer{
+					Endpoint: "wss://localhost:9090/opamp",
+					TLS:      tlsConfig,
+				},
+				Agent: Agent{
+					Executable:              "${file_path}",
+					OrphanDetectionInterval: 5 * time.Second,
+					ConfigApplyTimeout:      2 * time.Second,
+					BootstrapTimeout:        5 * time.Second,
+					AutomaticConfigRollback: true,
+					ConfigProbation: ConfigProbation{
+						Duration:      time.Minute,
+						CheckInterval: 5 * time.Second,
+						MinSentRatio:  0.5,
+					},
+				},
+				Capabilities: Capabilities{AcceptsRemoteConfig: true},
+				Storage:      Storage{Directory: "/etc/opamp-supervisor/storage"},
+				HealthCheck:  defaultHealthCheck,
+			},
+			expectedErrorFunc: simpleError("agent::config_probation::min_sent_ratio requires agent::config_probation::metrics_endpoint to be set"),
+		},
+		{
+			name: "Config probation without reports_remote_config",
+			config: Supervisor{
+				Server: OpAMPServer{
+					Endpoint: "wss://localhost:9090/opamp",
+					TLS:      tlsConfig,
+				},
+				Agent: Agent{
+					Executable:              "${file_path}",
+					OrphanDetectionInterval: 5 * time.Second,
+					ConfigApplyTimeout:      2 * time.Second,
+					BootstrapTimeout:        5 * time.Second,
+					AutomaticConfigRollback: true,
+					ConfigProbation: ConfigProbation{
+						Duration:      time.Minute,
+						CheckInterval: 5 * time.Second,
+					},
+				},
+				Capabilities: Capabilities{AcceptsRemoteConfig: true},
+				Storage:      Storage{Directory: "/etc/opamp-supervisor/storage"},
+				HealthCheck:  defaultHealthCheck,
+			},
+			expectedErrorFunc: simpleError("agent::config_probation requires capabilities::reports_remote_config to be enabled"),
+		},
 	}
 
 	// create some fake files for validating agent config
@@ -763,7 +1008,7 @@ func TestSupervisor_Validate(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			cfg := Supervisor{
-				Server:     OpAMPServer{Auth: tc.auth},
+				Server:     OpAMPServer{Endpoint: "ws://localhost/v1/opamp", Auth: tc.auth},
 				Extensions: tc.extensions,
 			}
 			err := cfg.Validate()
@@ -776,6 +1021,93 @@ func TestSupervisor_Validate(t *testing.T) {
 	}
 }
 
+func TestSupervisor_ValidateConfigSource(t *testing.T) {
+	localConfigSource := LocalConfigSource{
+		Directory:    "/etc/otelcol/bundle",
+		PollInterval: 10 * time.Second,
+		Verifier:     Verifier{Type: VerifierTypeEd25519, PublicKeyFile: "/etc/otelcol/bundle.pub"},
+	}
+
+	testCases := []struct {
+		name        string
+		cfg         Supervisor
+		errContains string
+	}{
+		{
+			name: "server endpoint",
+			cfg: Supervisor{
+				Server: OpAMPServer{Endpoint: "ws://localhost/v1/opamp"},
+			},
+		},
+		{
+			name:        "neither server endpoint nor local config source",
+			cfg:         Supervisor{},
+			errContains: "server::endpoint must be specified",
+		},
+		{
+			name: "local config source",
+			cfg: Supervisor{
+				Capabilities:      Capabilities{AcceptsRemoteConfig: true},
+				Agent:             Agent{ValidateConfig: true},
+				LocalConfigSource: localConfigSource,
+			},
+		},
+		{
+			name: "both server endpoint and local config source",
+			cfg: Supervisor{
+				Server:            OpAMPServer{Endpoint: "ws://localhost/v1/opamp"},
+				Capabilities:      Capabilities{AcceptsRemoteConfig: true},
+				LocalConfigSource: localConfigSource,
+			},
+			errContains: "server::endpoint and local_config_source::directory cannot both be specified",
+		},
+		{
+			name: "local config source without accepts_remote_config",
+			cfg: Supervisor{
+				Agent:             Agent{ValidateConfig: true},
+				LocalConfigSource: localConfigSource,
+			},
+			errContains: "local_config_source requires capabilities::accepts_remote_config to be enabled",
+		},
+		{
+			name: "local config source without validate_config",
+			cfg: Supervisor{
+				Capabilities:      Capabilities{AcceptsRemoteConfig: true},
+				LocalConfigSource: localConfigSource,
+			},
+			errContains: "local_config_source requires agent::validate_config to be enabled",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.cfg.Validate()
+			if tc.errContains != "" {
+				require.ErrorContains(t, err, tc.errContains)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestLocalConfigSource_Validate(t *testing.T) {
+	require.NoError(t, LocalConfigSource{}.Validate())
+	verifier := Verifier{Type: VerifierTypeEd25519, PublicKeyFile: "/etc/otelcol/bundle.pub"}
+	require.NoError(t, LocalConfigSource{Directory: "/etc/otelcol/bundle", PollInterval: time.Second, Verifier: verifier}.Validate())
+	require.EqualError(t, LocalConfigSource{Directory: "/etc/otelcol/bundle", Verifier: verifier}.Validate(), "local_config_source::poll_interval must be positive")
+	// An unsigned bundle would let anyone able to write to the directory
+	// configure the Collector.
+	require.EqualError(t, LocalConfigSource{Directory: "/etc/otelcol/bundle", PollInterval: time.Second}.Validate(), "local_config_source::verifier::type must be specified")
+}
+
+func TestVerifier_Validate(t *testing.T) {
+	require.NoError(t, Verifier{Type: VerifierTypeNone}.Validate())
+	require.NoError(t, Verifier{Type: VerifierTypeEd25519, PublicKeyFile: "/etc/otelcol/bundle.pub"}.Validate())
+	require.EqualError(t, Verifier{Type: VerifierTypeEd25519}.Validate(), "public_key_file must be specified for the ed25519 verifier")
+	require.EqualError(t, Verifier{Type: "cosign"}.Validate(), `unsupported verifier type: "cosign"`)
+}
+
 // TestSupervisor_TopLevelValidate confirms that confmap.Validate produces
 // path-prefixed errors when called at the supervisor-config root, which is
 // what NewSupervisor relies on for actionable validation messages.
@@ -1071,9 +1403,11 @@ agent:
 						CollectorCrashLogSnippetKiB: DefaultSupervisor().Agent.CollectorCrashLogSnippetKiB,
 						ValidateConfig:              DefaultSupervisor().Agent.ValidateConfig,
 						Package:                     DefaultSupervisor().Agent.Package,
+						ConfigProbation:             DefaultSupervisor().Agent.ConfigProbation,
 					},
-					Telemetry:   DefaultSupervisor().Telemetry,
-					HealthCheck: DefaultSupervisor().HealthCheck,
+					Telemetry:         DefaultSupervisor().Telemetry,
+					HealthCheck:       DefaultSupervisor().HealthCheck,
+					LocalConfigSource: DefaultSupervisor().LocalConfigSource,
 				}
 
 				cfgPath := setupSupervisorConfigFile(t, tmpDir, config)
@@ -1117,6 +1451,13 @@ agent:
   passthrough_logs: true
   automatic_config_rollback: true
   collector_crash_log_snippet_kib: 100
+  config_probation:
+    duration: 2m
+    check_interval: 10s
+    metrics_endpoint: http://localhost:8888/metrics
+    max_send_failed_items: 10
+    max_refused_items: 5
+    min_sent_ratio: 0.9
 
 telemetry:
   logs:
@@ -1169,6 +1510,14 @@ telemetry:
 						AutomaticConfigRollback:     true,
 						ValidateConfig:              DefaultSupervisor().Agent.ValidateConfig,
 						Package:                     DefaultSupervisor().Agent.Package,
+						ConfigProbation: ConfigProbation{
+							Duration:           2 * time.Minute,
+							CheckInterval:      10 * time.Second,
+							MetricsEndpoint:    "http://localhost:8888/metrics",
+							MaxSendFailedItems: 10,
+							MaxRefusedItems:    5,
+							MinSentRatio:       0.9,
+						},
 					},
 					Telemetry: Telemetry{
 						Logs: Logs{
@@ -1178,7 +1527,8 @@ telemetry:
 							Encoding:         "console",
 						},
 					},
-					HealthCheck: DefaultSupervisor().HealthCheck,
+					HealthCheck:       DefaultSupervisor().HealthCheck,
+					LocalConfigSource: DefaultSupervisor().LocalConfigSource,
 				}
 
 				cfgPath := setupSupervisorConfigFile(t, tmpDir, config)
@@ -1209,9 +1559,11 @@ agent:
 						CollectorCrashLogSnippetKiB: DefaultSupervisor().Agent.CollectorCrashLogSnippetKiB,
 						ValidateConfig:              DefaultSupervisor().Agent.ValidateConfig,
 						Package:                     DefaultSupervisor().Agent.Package,
+						ConfigProbation:             DefaultSupervisor().Agent.ConfigProbation,
 					},
-					Telemetry:   DefaultSupervisor().Telemetry,
-					HealthCheck: DefaultSupervisor().HealthCheck,
+					Telemetry:         DefaultSupervisor().Telemetry,
+					HealthCheck:       DefaultSupervisor().HealthCheck,
+					LocalConfigSource: DefaultSupervisor().LocalConfigSource,
 				}
 
 				t.Setenv("TEST_ENDPOINT", "ws://localhost/v1/opamp")
@@ -1221,6 +1573,58 @@ agent:
 				runSupervisorConfigLoadTest(t, cfgPath, expected, nil)
 			},
 		},
+		{
+			desc: "Local Config Source Supervisor",
+			testFunc: func(t *testing.T) {
+				config := `
+capabilities:
+  accepts_remote_config: true
+
+agent:
+  executable: %s
+
+local_config_source:
+  directory: /etc/otelcol/bundle
+  poll_interval: 30s
+  status_file: /var/lib/otelcol/status.json
+  verifier:
+    type: ed25519
+    public_key_file: /etc/otelcol/bundle.pub
+`
+				config = fmt.Sprintf(config, executablePath)
+
+				capabilities := DefaultSupervisor().Capabilities
+				capabilities.AcceptsRemoteConfig = true
+				expected := Supervisor{
+					Capabilities: capabilities,
+					Storage:      DefaultSupervisor().Storage,
+					Agent: Agent{
+						Executable:                  executablePath,
+						OrphanDetectionInterval:     DefaultSupervisor().Agent.OrphanDetectionInterval,
+						ConfigApplyTimeout:          DefaultSupervisor().Agent.ConfigApplyTimeout,
+						BootstrapTimeout:            DefaultSupervisor().Agent.BootstrapTimeout,
+						CollectorCrashLogSnippetKiB: DefaultSupervisor().Agent.CollectorCrashLogSnippetKiB,
+						ValidateConfig:              DefaultSupervisor().Agent.ValidateConfig,
+						Package:                     DefaultSupervisor().Agent.Package,
+						ConfigProbation:             DefaultSupervisor().Agent.ConfigProbation,
+					},
+					Telemetry:   DefaultSupervisor().Telemetry,
+					HealthCheck: DefaultSupervisor().HealthCheck,
+					LocalConfigSource: LocalConfigSource{
+						Directory:    "/etc/otelcol/bundle",
+						PollInterval: 30 * time.Second,
+						StatusFile:   "/var/lib/otelcol/status.json",
+						Verifier: Verifier{
+							Type:          VerifierTypeEd25519,
+							PublicKeyFile: "/etc/otelcol/bundle.pub",
+						},
+					},
+				}
+
+				cfgPath := setupSupervisorConfigFile(t, tmpDir, config)
+				runSupervisorConfigLoadTest(t, cfgPath, expected, nil)
+			},
+		},
 		{
 			desc: "Empty Config Filepath",
 			testFunc: func(t *testing.T) {
@@ -0,0 +1,74 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"errors"
+	"time"
+)
+
+// LocalConfigSource configures the Supervisor to read remote configs from a
+// local directory instead of receiving them from an OpAMP server, e.g. on
+// sites without access to one. The config files in the directory form a
+// config bundle that goes through the same lifecycle as remote configs
+// received from a server, and the statuses that would be reported to the
+// server are written to a local JSON file instead.
+type LocalConfigSource struct {
+	// Directory is the directory the config bundle is read from, e.g. a git
+	// checkout. Setting it enables the local config source.
+	Directory string `mapstructure:"directory"`
+	// PollInterval is how often the directory is checked for a new bundle.
+	PollInterval time.Duration `mapstructure:"poll_interval"`
+	// StatusFile is the JSON file the statuses are written to. Defaults to
+	// local_config_status.json in the storage directory.
+	StatusFile string `mapstructure:"status_file"`
+	// Verifier configures how the signature of the bundle is verified. It is
+	// required, as anyone able to write to the directory could otherwise
+	// configure the Collector.
+	Verifier Verifier `mapstructure:"verifier"`
+	// prevent unkeyed literal initialization
+	_ struct{}
+}
+
+// Enabled returns true if the remote configs are read from a local
+// directory.
+func (l LocalConfigSource) Enabled() bool {
+	return l.Directory != ""
+}
+
+func (l LocalConfigSource) Validate() error {
+	if !l.Enabled() {
+		return nil
+	}
+	if l.PollInterval <= 0 {
+		return errors.New("local_config_source::poll_interval must be positive")
+	}
+	if l.Verifier.Type == VerifierTypeNone {
+		return errors.New("local_config_source::verifier::type must be specified")
+	}
+	return nil
+}
+
+// validateConfigSource checks that remote configs come either from an OpAMP
+// server or from a local directory.
+func (s *Supervisor) validateConfigSource() error {
+	if !s.LocalConfigSource.Enabled() {
+		if s.Server.Endpoint == "" {
+			return errors.New("server::endpoint must be specified")
+		}
+		return nil
+	}
+	if s.Server.Endpoint != "" {
+		return errors.New("server::endpoint and local_config_source::directory cannot both be specified")
+	}
+	if !s.Capabilities.AcceptsRemoteConfig {
+		return errors.New("local_config_source requires capabilities::accepts_remote_config to be enabled")
+	}
+	// There is no server to roll a broken bundle back, so bundles are only
+	// applied after the Collector validated them.
+	if !s.Agent.ValidateConfig {
+		return errors.New("local_config_source requires agent::validate_config to be enabled")
+	}
+	return nil
+}
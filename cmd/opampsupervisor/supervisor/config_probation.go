@@ -0,0 +1,215 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package supervisor
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/prometheus/common/model"
+	"go.uber.org/zap"
+)
+
+var (
+	// sendFailedMetricPrefixes match the Collector's own metrics counting the
+	// items exporters failed to send.
+	sendFailedMetricPrefixes = []string{"otelcol_exporter_send_failed_"}
+	// refusedMetricPrefixes match the Collector's own metrics counting the
+	// items refused by receivers and processors.
+	refusedMetricPrefixes = []string{"otelcol_receiver_refused_", "otelcol_processor_refused_"}
+	// acceptedMetricPrefixes match the Collector's own metrics counting the
+	// items accepted by receivers.
+	acceptedMetricPrefixes = []string{"otelcol_receiver_accepted_"}
+	// sentMetricPrefixes match the Collector's own metrics counting the items
+	// exporters sent.
+	sentMetricPrefixes = []string{"otelcol_exporter_sent_"}
+)
+
+// probationCounters holds the Collector's own counters watched during
+// probation.
+type probationCounters struct {
+	sendFailed float64
+	refused    float64
+	accepted   float64
+	sent       float64
+}
+
+// sub returns the increase of the counters since base. A counter lower than
+// its base value was reset, e.g. because the Collector restarted, and its
+// current value is the increase.
+func (c probationCounters) sub(base probationCounters) probationCounters {
+	increase := func(current, base float64) float64 {
+		if current < base {
+			return current
+		}
+		return current - base
+	}
+	return probationCounters{
+		sendFailed: increase(c.sendFailed, base.sendFailed),
+		refused:    increase(c.refused, base.refused),
+		accepted:   increase(c.accepted, base.accepted),
+		sent:       increase(c.sent, base.sent),
+	}
+}
+
+// configProbation tracks the probation of the active remote config.
+type configProbation struct {
+	deadline time.Time
+	// baseline holds the counters when the probation started. It is nil if
+	// they could not be scraped, in which case the first successful scrape is
+	// used instead.
+	baseline *probationCounters
+	// latest holds the counters of the last successful scrape.
+	latest *probationCounters
+}
+
+// shouldStartConfigProbation returns true if the active remote config has to
+// go through probation before being reported as applied. The last working
+// remote config is never put on probation again.
+func (s *Supervisor) shouldStartConfigProbation() bool {
+	if !s.config.Agent.ConfigProbation.Enabled() {
+		return false
+	}
+	remoteConfig := s.remoteConfig.Load()
+	if remoteConfig == nil {
+		return false
+	}
+	lastWorkingRemoteConfig := s.lastWorkingRemoteConfig.Load()
+	return lastWorkingRemoteConfig == nil || !bytes.Equal(remoteConfig.GetConfigHash(), lastWorkingRemoteConfig.GetConfigHash())
+}
+
+func (s *Supervisor) startConfigProbation() *configProbation {
+	probation := &configProbation{
+		deadline: time.Now().Add(s.config.Agent.ConfigProbation.Duration),
+	}
+	if s.config.Agent.ConfigProbation.MetricsEndpoint != "" {
+		if counters, err := s.scrapeProbationCounters(); err != nil {
+			s.telemetrySettings.Logger.Warn("Could not scrape Collector metrics at the start of the config probation", zap.Error(err))
+		} else {
+			probation.baseline = &counters
+		}
+	}
+	s.telemetrySettings.Logger.Info("Remote config is on probation", zap.Duration("duration", s.config.Agent.ConfigProbation.Duration))
+	return probation
+}
+
+// checkConfigProbation checks the Collector while the active remote config is
+// on probation. It returns the reason the config failed its probation, if
+// any, and whether the probation is over.
+func (s *Supervisor) checkConfigProbation(probation *configProbation) (string, bool) {
+	if health := s.lastHealthFromClient.Load(); health != nil && !health.Healthy {
+		reason := "Collector reported an unhealthy status during config probation"
+		if health.LastError != "" {
+			reason = fmt.Sprintf("%s: %s", reason, health.LastError)
+		}
+		return reason, true
+	}
+
+	cfg := s.config.Agent.ConfigProbation
+	if cfg.MetricsEndpoint != "" {
+		counters, err := s.scrapeProbationCounters()
+		switch {
+		case err != nil:
+			s.telemetrySettings.Logger.Warn("Could not scrape Collector metrics during config probation", zap.Error(err))
+		case probation.baseline == nil:
+			probation.baseline = &counters
+			probation.latest = &counters
+		default:
+			probation.latest = &counters
+			increase := counters.sub(*probation.baseline)
+			if increase.sendFailed > float64(cfg.MaxSendFailedItems) {
+				return fmt.Sprintf("Exporters failed to send %.0f items during config probation, more than the %d allowed", increase.sendFailed, cfg.MaxSendFailedItems), true
+			}
+			if increase.refused > float64(cfg.MaxRefusedItems) {
+				return fmt.Sprintf("%.0f items were refused during config probation, more than the %d allowed", increase.refused, cfg.MaxRefusedItems), true
+			}
+		}
+	}
+
+	if time.Now().Before(probation.deadline) {
+		return "", false
+	}
+	// The throughput is only checked at the end of the window, as accepted
+	// items may still be batched or queued before being sent.
+	if cfg.MinSentRatio > 0 && probation.latest != nil {
+		increase := probation.latest.sub(*probation.baseline)
+		if increase.accepted > 0 && increase.sent < cfg.MinSentRatio*increase.accepted {
+			return fmt.Sprintf("Exporters sent %.0f of the %.0f items accepted during config probation, less than the %g ratio required", increase.sent, increase.accepted, cfg.MinSentRatio), true
+		}
+	}
+	return "", true
+}
+
+func (s *Supervisor) scrapeProbationCounters() (probationCounters, error) {
+	ctx, cancel := context.WithTimeout(s.runCtx, s.config.Agent.ConfigProbation.CheckInterval)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.config.Agent.ConfigProbation.MetricsEndpoint, http.NoBody)
+	if err != nil {
+		return probationCounters{}, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return probationCounters{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return probationCounters{}, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return parseProbationCounters(resp.Body)
+}
+
+// parseProbationCounters sums the counters watched during probation from
+// metrics in the Prometheus text format.
+func parseProbationCounters(r io.Reader) (probationCounters, error) {
+	parser := expfmt.NewTextParser(model.UTF8Validation)
+	families, err := parser.TextToMetricFamilies(r)
+	if err != nil {
+		return probationCounters{}, fmt.Errorf("parse metrics: %w", err)
+	}
+
+	var counters probationCounters
+	for name, family := range families {
+		switch {
+		case hasAnyPrefix(name, sendFailedMetricPrefixes):
+			counters.sendFailed += sumSamples(family)
+		case hasAnyPrefix(name, refusedMetricPrefixes):
+			counters.refused += sumSamples(family)
+		case hasAnyPrefix(name, acceptedMetricPrefixes):
+			counters.accepted += sumSamples(family)
+		case hasAnyPrefix(name, sentMetricPrefixes):
+			counters.sent += sumSamples(family)
+		}
+	}
+	return counters, nil
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func sumSamples(family *dto.MetricFamily) float64 {
+	var sum float64
+	for _, m := range family.GetMetric() {
+		switch {
+		case m.Counter != nil:
+			sum += m.GetCounter().GetValue()
+		case m.Untyped != nil:
+			sum += m.GetUntyped().GetValue()
+		}
+	}
+	return sum
+}
@@ -0,0 +1,265 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package supervisor
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/open-telemetry/opamp-go/protobufs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/cmd/opampsupervisor/supervisor/config"
+)
+
+func TestParseProbationCounters(t *testing.T) {
+	metrics := `# HELP otelcol_exporter_send_failed_spans_total Number of spans in failed attempts to send to destination.
+# TYPE otelcol_exporter_send_failed_spans_total counter
+otelcol_exporter_send_failed_spans_total{exporter="otlp"} 3
+otelcol_exporter_send_failed_spans_total{exporter="otlp/2"} 4
+# HELP otelcol_exporter_send_failed_log_records_total Number of log records in failed attempts to send to destination.
+# TYPE otelcol_exporter_send_failed_log_records_total counter
+otelcol_exporter_send_failed_log_records_total{exporter="otlp"} 1
+# HELP otelcol_receiver_refused_metric_points_total Number of metric points that could not be pushed into the pipeline.
+# TYPE otelcol_receiver_refused_metric_points_total counter
+otelcol_receiver_refused_metric_points_total{receiver="otlp"} 10
+# HELP otelcol_processor_refused_spans_total Number of spans that were rejected by the next component in the pipeline.
+# TYPE otelcol_processor_refused_spans_total counter
+otelcol_processor_refused_spans_total{processor="memory_limiter"} 2
+# HELP otelcol_exporter_sent_spans_total Number of spans successfully sent to destination.
+# TYPE otelcol_exporter_sent_spans_total counter
+otelcol_exporter_sent_spans_total{exporter="otlp"} 100
+# HELP otelcol_receiver_accepted_spans_total Number of spans successfully pushed into the pipeline.
+# TYPE otelcol_receiver_accepted_spans_total counter
+otelcol_receiver_accepted_spans_total{receiver="otlp"} 120
+`
+	counters, err := parseProbationCounters(strings.NewReader(metrics))
+	require.NoError(t, err)
+	assert.Equal(t, probationCounters{sendFailed: 8, refused: 12, accepted: 120, sent: 100}, counters)
+
+	_, err = parseProbationCounters(strings.NewReader("not metrics {"))
+	require.Error(t, err)
+}
+
+func TestProbationCountersSub(t *testing.T) {
+	base := probationCounters{sendFailed: 5, refused: 5}
+	assert.Equal(t, probationCounters{sendFailed: 2, refused: 0}, probationCounters{sendFailed: 7, refused: 5}.sub(base))
+	// A counter lower than its base value was reset.
+	assert.Equal(t, probationCounters{sendFailed: 3, refused: 1}, probationCounters{sendFailed: 3, refused: 6}.sub(base))
+}
+
+func TestSupervisor_shouldStartConfigProbation(t *testing.T) {
+	remoteConfig := &protobufs.AgentRemoteConfig{ConfigHash: []byte("new-hash")}
+	lastWorkingRemoteConfig := &protobufs.AgentRemoteConfig{ConfigHash: []byte("working-hash")}
+
+	testCases := []struct {
+		name                    string
+		probation               config.ConfigProbation
+		remoteConfig            *protobufs.AgentRemoteConfig
+		lastWorkingRemoteConfig *protobufs.AgentRemoteConfig
+		expected                bool
+	}{
+		{
+			name:         "probation disabled",
+			remoteConfig: remoteConfig,
+		},
+		{
+			name:      "no remote config",
+			probation: config.ConfigProbation{Duration: time.Minute},
+		},
+		{
+			name:         "no last working remote config",
+			probation:    config.ConfigProbation{Duration: time.Minute},
+			remoteConfig: remoteConfig,
+			expected:     true,
+		},
+		{
+			name:                    "new remote config",
+			probation:               config.ConfigProbation{Duration: time.Minute},
+			remoteConfig:            remoteConfig,
+			lastWorkingRemoteConfig: lastWorkingRemoteConfig,
+			expected:                true,
+		},
+		{
+			name:                    "last working remote config",
+			probation:               config.ConfigProbation{Duration: time.Minute},
+			remoteConfig:            lastWorkingRemoteConfig,
+			lastWorkingRemoteConfig: lastWorkingRemoteConfig,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &Supervisor{
+				config: config.Supervisor{
+					Agent: config.Agent{ConfigProbation: tc.probation},
+				},
+			}
+			s.remoteConfig.Store(tc.remoteConfig)
+			s.lastWorkingRemoteConfig.Store(tc.lastWorkingRemoteConfig)
+			assert.Equal(t, tc.expected, s.shouldStartConfigProbation())
+		})
+	}
+}
+
+func TestSupervisor_checkConfigProbation(t *testing.T) {
+	var sendFailed, refused, accepted, sent atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = fmt.Fprintf(w, "# TYPE otelcol_exporter_send_failed_spans_total counter\notelcol_exporter_send_failed_spans_total %d\n", sendFailed.Load())
+		_, _ = fmt.Fprintf(w, "# TYPE otelcol_receiver_refused_spans_total counter\notelcol_receiver_refused_spans_total %d\n", refused.Load())
+		_, _ = fmt.Fprintf(w, "# TYPE otelcol_receiver_accepted_spans_total counter\notelcol_receiver_accepted_spans_total %d\n", accepted.Load())
+		_, _ = fmt.Fprintf(w, "# TYPE otelcol_exporter_sent_spans_total counter\notelcol_exporter_sent_spans_total %d\n", sent.Load())
+	}))
+	t.Cleanup(srv.Close)
+
+	newSupervisor := func(probation config.ConfigProbation) *Supervisor {
+		s := &Supervisor{
+			runCtx:            context.Background(),
+			telemetrySettings: newNopTelemetrySettings(),
+			config: config.Supervisor{
+				Agent: config.Agent{ConfigProbation: probation},
+			},
+		}
+		s.lastHealthFromClient.Store(&protobufs.ComponentHealth{Healthy: true})
+		return s
+	}
+
+	t.Run("passes once the window is over", func(t *testing.T) {
+		sendFailed.Store(50)
+		refused.Store(50)
+		s := newSupervisor(config.ConfigProbation{
+			Duration:           time.Hour,
+			CheckInterval:      time.Second,
+			MetricsEndpoint:    srv.URL,
+			MaxSendFailedItems: 5,
+			MaxRefusedItems:    5,
+		})
+		probation := s.startConfigProbation()
+		require.NotNil(t, probation.baseline)
+
+		// Counters from before the probation do not count.
+		sendFailed.Add(5)
+		reason, done := s.checkConfigProbation(probation)
+		assert.Empty(t, reason)
+		assert.False(t, done)
+
+		probation.deadline = time.Now()
+		reason, done = s.checkConfigProbation(probation)
+		assert.Empty(t, reason)
+		assert.True(t, done)
+	})
+
+	t.Run("fails on send failures", func(t *testing.T) {
+		sendFailed.Store(0)
+		refused.Store(0)
+		s := newSupervisor(config.ConfigProbation{
+			Duration:        time.Hour,
+			CheckInterval:   time.Second,
+			MetricsEndpoint: srv.URL,
+		})
+		probation := s.startConfigProbation()
+
+		sendFailed.Add(3)
+		reason, done := s.checkConfigProbation(probation)
+		assert.Equal(t, "Exporters failed to send 3 items during config probation, more than the 0 allowed", reason)
+		assert.True(t, done)
+	})
+
+	t.Run("fails on refused items", func(t *testing.T) {
+		sendFailed.Store(0)
+		refused.Store(0)
+		s := newSupervisor(config.ConfigProbation{
+			Duration:        time.Hour,
+			CheckInterval:   time.Second,
+			MetricsEndpoint: srv.URL,
+			MaxRefusedItems: 10,
+		})
+		probation := s.startConfigProbation()
+
+		refused.Add(11)
+		reason, done := s.checkConfigProbation(probation)
+		assert.Equal(t, "11 items were refused during config probation, more than the 10 allowed", reason)
+		assert.True(t, done)
+	})
+
+	t.Run("fails on low throughput at the end of the window", func(t *testing.T) {
+		sendFailed.Store(0)
+		refused.Store(0)
+		accepted.Store(1000)
+		sent.Store(1000)
+		s := newSupervisor(config.ConfigProbation{
+			Duration:        time.Hour,
+			CheckInterval:   time.Second,
+			MetricsEndpoint: srv.URL,
+			MinSentRatio:    0.5,
+		})
+		probation := s.startConfigProbation()
+
+		// The accepted items may still be on their way to the exporters.
+		accepted.Add(100)
+		reason, done := s.checkConfigProbation(probation)
+		assert.Empty(t, reason)
+		assert.False(t, done)
+
+		sent.Add(40)
+		probation.deadline = time.Now()
+		reason, done = s.checkConfigProbation(probation)
+		assert.Equal(t, "Exporters sent 40 of the 100 items accepted during config probation, less than the 0.5 ratio required", reason)
+		assert.True(t, done)
+	})
+
+	t.Run("passes the throughput check without traffic", func(t *testing.T) {
+		accepted.Store(0)
+		sent.Store(0)
+		s := newSupervisor(config.ConfigProbation{
+			Duration:        time.Hour,
+			CheckInterval:   time.Second,
+			MetricsEndpoint: srv.URL,
+			MinSentRatio:    0.5,
+		})
+		probation := s.startConfigProbation()
+
+		probation.deadline = time.Now()
+		reason, done := s.checkConfigProbation(probation)
+		assert.Empty(t, reason)
+		assert.True(t, done)
+	})
+
+	t.Run("fails on unhealthy Collector", func(t *testing.T) {
+		s := newSupervisor(config.ConfigProbation{
+			Duration:      time.Hour,
+			CheckInterval: time.Second,
+		})
+		probation := s.startConfigProbation()
+		s.lastHealthFromClient.Store(&protobufs.ComponentHealth{Healthy: false, LastError: "exporter is down"})
+
+		reason, done := s.checkConfigProbation(probation)
+		assert.Equal(t, "Collector reported an unhealthy status during config probation: exporter is down", reason)
+		assert.True(t, done)
+	})
+
+	t.Run("uses the first successful scrape as baseline", func(t *testing.T) {
+		sendFailed.Store(20)
+		refused.Store(0)
+		s := newSupervisor(config.ConfigProbation{
+			Duration:        time.Hour,
+			CheckInterval:   time.Second,
+			MetricsEndpoint: srv.URL,
+		})
+		probation := &configProbation{deadline: time.Now().Add(time.Hour)}
+
+		reason, done := s.checkConfigProbation(probation)
+		assert.Empty(t, reason)
+		assert.False(t, done)
+		require.NotNil(t, probation.baseline)
+		assert.Equal(t, probationCounters{sendFailed: 20}, *probation.baseline)
+	})
+}
@@ -0,0 +1,113 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package localsource
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/open-telemetry/opamp-go/protobufs"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/cmd/opampsupervisor/supervisor/verifier"
+)
+
+// signatureFile is the name of the file holding the signature of the bundle
+// hash, which covers the names and contents of all the bundle files.
+const signatureFile = "bundle.sig"
+
+// errNoBundle is returned when the directory holds no config file.
+var errNoBundle = errors.New("no config file found")
+
+// isConfigFile returns true for the files making up a bundle. Hidden files,
+// e.g. a .git directory, are ignored.
+func isConfigFile(entry os.DirEntry) bool {
+	name := entry.Name()
+	if !entry.Type().IsRegular() || strings.HasPrefix(name, ".") {
+		return false
+	}
+	ext := filepath.Ext(name)
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// bundleHash returns the hash identifying the bundle made of the given files.
+func bundleHash(names []string, files map[string][]byte) []byte {
+	h := sha256.New()
+	for _, name := range names {
+		h.Write([]byte(name))
+		h.Write([]byte{0})
+		h.Write(files[name])
+		h.Write([]byte{0})
+	}
+	return h.Sum(nil)
+}
+
+// bundle is a config bundle read from a directory.
+type bundle struct {
+	remoteConfig *protobufs.AgentRemoteConfig
+	// hash identifies the bundle, see bundleHash.
+	hash []byte
+	// signature is the content of the signature file, nil if it is missing.
+	signature []byte
+}
+
+// readBundle reads the config files of dir into a remote config, keyed by
+// file name, and verifies the signature of their hash. A single signature
+// covers the whole bundle, so that files cannot be removed, or mixed or
+// replayed from other signed bundles. The hash and signature are returned
+// even when the signature cannot be verified, so that the failure can be
+// reported for the bundle.
+func readBundle(dir string, v verifier.Verifier) (bundle, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return bundle{}, err
+	}
+
+	files := map[string][]byte{}
+	var names []string
+	for _, entry := range entries {
+		if !isConfigFile(entry) {
+			continue
+		}
+		body, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return bundle{}, err
+		}
+		files[entry.Name()] = body
+		names = append(names, entry.Name())
+	}
+	if len(names) == 0 {
+		return bundle{}, errNoBundle
+	}
+	slices.Sort(names)
+	b := bundle{hash: bundleHash(names, files)}
+
+	b.signature, err = os.ReadFile(filepath.Join(dir, signatureFile))
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return b, fmt.Errorf("missing bundle signature file %s", signatureFile)
+	case err != nil:
+		return b, err
+	}
+	if err := v.Verify(b.hash, b.signature); err != nil {
+		return b, fmt.Errorf("verify bundle signature: %w", err)
+	}
+
+	configMap := make(map[string]*protobufs.AgentConfigFile, len(names))
+	for _, name := range names {
+		configMap[name] = &protobufs.AgentConfigFile{
+			Body:        files[name],
+			ContentType: "text/yaml",
+		}
+	}
+	b.remoteConfig = &protobufs.AgentRemoteConfig{
+		Config:     &protobufs.AgentConfigMap{ConfigMap: configMap},
+		ConfigHash: b.hash,
+	}
+	return b, nil
+}
@@ -0,0 +1,135 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package localsource
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/cmd/opampsupervisor/supervisor/config"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/cmd/opampsupervisor/supervisor/verifier"
+)
+
+// testKey is the Ed25519 key test bundles are signed with.
+type testKey struct {
+	privateKey ed25519.PrivateKey
+	verifier   config.Verifier
+}
+
+func newTestKey(t *testing.T) testKey {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+	require.NoError(t, err)
+	publicKeyFile := filepath.Join(t.TempDir(), "bundle.pub")
+	require.NoError(t, os.WriteFile(publicKeyFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600))
+	return testKey{
+		privateKey: privateKey,
+		verifier:   config.Verifier{Type: config.VerifierTypeEd25519, PublicKeyFile: publicKeyFile},
+	}
+}
+
+func (k testKey) newVerifier(t *testing.T) verifier.Verifier {
+	v, err := verifier.NewVerifier(k.verifier)
+	require.NoError(t, err)
+	return v
+}
+
+// sign writes the signature of the bundle of dir.
+func (k testKey) sign(t *testing.T, dir string) {
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	files := map[string][]byte{}
+	var names []string
+	for _, entry := range entries {
+		if !isConfigFile(entry) {
+			continue
+		}
+		files[entry.Name()], err = os.ReadFile(filepath.Join(dir, entry.Name()))
+		require.NoError(t, err)
+		names = append(names, entry.Name())
+	}
+	slices.Sort(names)
+	writeFile(t, dir, signatureFile, string(ed25519.Sign(k.privateKey, bundleHash(names, files))))
+}
+
+func TestReadBundle(t *testing.T) {
+	key := newTestKey(t)
+	dir := t.TempDir()
+	writeFile(t, dir, "config.yaml", "receivers:\n  nop:\n")
+	key.sign(t, dir)
+
+	b, err := readBundle(dir, key.newVerifier(t))
+	require.NoError(t, err)
+	assert.Equal(t, b.hash, b.remoteConfig.ConfigHash)
+	assert.Equal(t, "text/yaml", b.remoteConfig.GetConfig().GetConfigMap()["config.yaml"].ContentType)
+
+	// The hash only depends on the file names and contents.
+	same, err := readBundle(dir, key.newVerifier(t))
+	require.NoError(t, err)
+	assert.Equal(t, b.hash, same.hash)
+
+	require.NoError(t, os.Rename(filepath.Join(dir, "config.yaml"), filepath.Join(dir, "renamed.yaml")))
+	key.sign(t, dir)
+	renamed, err := readBundle(dir, key.newVerifier(t))
+	require.NoError(t, err)
+	assert.NotEqual(t, b.hash, renamed.hash)
+}
+
+func TestReadBundleVerifiesSignature(t *testing.T) {
+	key := newTestKey(t)
+	dir := t.TempDir()
+	writeFile(t, dir, "config.yaml", "receivers:\n  nop:\n")
+	writeFile(t, dir, "redaction.yaml", "processors:\n  redaction:\n")
+	key.sign(t, dir)
+	signed, err := readBundle(dir, key.newVerifier(t))
+	require.NoError(t, err)
+
+	// Removing a file invalidates the signature of the bundle.
+	require.NoError(t, os.Remove(filepath.Join(dir, "redaction.yaml")))
+	b, err := readBundle(dir, key.newVerifier(t))
+	require.EqualError(t, err, "verify bundle signature: invalid ed25519 signature")
+	assert.NotNil(t, b.hash)
+	assert.Nil(t, b.remoteConfig)
+
+	// So does changing a file, e.g. to its version from another signed bundle.
+	writeFile(t, dir, "redaction.yaml", "processors:\n  redaction:\n    allow_all_keys: true\n")
+	_, err = readBundle(dir, key.newVerifier(t))
+	require.EqualError(t, err, "verify bundle signature: invalid ed25519 signature")
+
+	// A missing signature is an error, whatever the verifier.
+	writeFile(t, dir, "redaction.yaml", "processors:\n  redaction:\n")
+	require.NoError(t, os.Remove(filepath.Join(dir, signatureFile)))
+	b, err = readBundle(dir, noneVerifier(t))
+	require.EqualError(t, err, "missing bundle signature file bundle.sig")
+	assert.Equal(t, signed.hash, b.hash)
+	assert.Nil(t, b.remoteConfig)
+}
+
+func noneVerifier(t *testing.T) verifier.Verifier {
+	v, err := verifier.NewVerifier(config.Verifier{Type: config.VerifierTypeNone})
+	require.NoError(t, err)
+	return v
+}
+
+func TestReadBundleErrors(t *testing.T) {
+	b, err := readBundle(filepath.Join(t.TempDir(), "missing"), noneVerifier(t))
+	require.ErrorIs(t, err, os.ErrNotExist)
+	assert.Nil(t, b.hash)
+
+	dir := t.TempDir()
+	writeFile(t, dir, "notes.txt", "not a config\n")
+	b, err = readBundle(dir, noneVerifier(t))
+	require.ErrorIs(t, err, errNoBundle)
+	assert.Nil(t, b.hash)
+}
@@ -0,0 +1,244 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package localsource implements an OpAMP client reading remote configs from
+// a local directory instead of an OpAMP server, for Supervisors without
+// access to one.
+package localsource
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/open-telemetry/opamp-go/client"
+	"github.com/open-telemetry/opamp-go/client/types"
+	"github.com/open-telemetry/opamp-go/protobufs"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/cmd/opampsupervisor/supervisor/config"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/cmd/opampsupervisor/supervisor/verifier"
+)
+
+var _ client.OpAMPClient = (*Client)(nil)
+
+// Client is an OpAMP client whose server is a local directory. The config
+// files of the directory are delivered as a remote config message whenever
+// they change, and everything the Supervisor reports is written to a JSON
+// status file.
+type Client struct {
+	cfg        config.LocalConfigSource
+	statusFile string
+	verifier   verifier.Verifier
+	logger     *zap.Logger
+
+	// writeMu serializes the writes of the status file.
+	writeMu   sync.Mutex
+	mu        sync.Mutex
+	callbacks types.Callbacks
+	status    Status
+	agentDesc *protobufs.AgentDescription
+	// lastHash is the hash of the last bundle that was delivered or failed
+	// verification, so that it is not handled again until it changes.
+	lastHash []byte
+	// rejected is true if the last bundle failed verification, in which case
+	// it is handled again once its signature changes from rejectedSignature,
+	// e.g. when the signature file is written after the config files.
+	rejected          bool
+	rejectedSignature []byte
+
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+// NewClient creates a Client for the given local config source, writing the
+// statuses to statusFile.
+func NewClient(cfg config.LocalConfigSource, statusFile string, logger *zap.Logger) (*Client, error) {
+	v, err := verifier.NewVerifier(cfg.Verifier)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{
+		cfg:        cfg,
+		statusFile: statusFile,
+		verifier:   v,
+		logger:     logger,
+		stopCh:     make(chan struct{}),
+	}, nil
+}
+
+// Start records the settings. The directory is not read until
+// [Client.StartWatching] is called, so that the Supervisor can finish
+// loading its persisted state first.
+func (c *Client) Start(ctx context.Context, settings types.StartSettings) error {
+	c.mu.Lock()
+	c.callbacks = settings.Callbacks
+	c.status.InstanceUID = uuid.UUID(settings.InstanceUid).String()
+	// A bundle the Supervisor already has a status for was handled before a
+	// restart and must not be applied again, e.g. after it was rolled back.
+	if rcs := settings.RemoteConfigStatus; rcs != nil {
+		c.lastHash = rcs.LastRemoteConfigHash
+		c.status.setRemoteConfigStatus(rcs)
+	}
+	c.mu.Unlock()
+
+	if c.callbacks.OnConnect != nil {
+		c.callbacks.OnConnect(ctx)
+	}
+	return c.writeStatus()
+}
+
+// StartWatching reads the directory now and then every poll interval until
+// the client is stopped.
+func (c *Client) StartWatching(ctx context.Context) {
+	c.wg.Go(func() {
+		ticker := time.NewTicker(c.cfg.PollInterval)
+		defer ticker.Stop()
+		for {
+			c.poll(ctx)
+			select {
+			case <-ticker.C:
+			case <-c.stopCh:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	})
+}
+
+func (c *Client) poll(ctx context.Context) {
+	b, err := readBundle(c.cfg.Directory, c.verifier)
+
+	c.mu.Lock()
+	if b.hash != nil && bytes.Equal(b.hash, c.lastHash) &&
+		(!c.rejected || bytes.Equal(b.signature, c.rejectedSignature)) {
+		c.mu.Unlock()
+		return
+	}
+	switch {
+	case errors.Is(err, errNoBundle):
+		c.mu.Unlock()
+		c.logger.Debug("No config bundle found in local config source", zap.String("directory", c.cfg.Directory))
+		return
+	case err != nil && b.hash == nil:
+		c.mu.Unlock()
+		c.logger.Error("Could not read config bundle from local config source", zap.String("directory", c.cfg.Directory), zap.Error(err))
+		return
+	case err != nil:
+		// The bundle is rejected before reaching the Supervisor, so its
+		// status is reported here.
+		c.lastHash = b.hash
+		c.rejected, c.rejectedSignature = true, b.signature
+		c.status.setRemoteConfigStatus(&protobufs.RemoteConfigStatus{
+			LastRemoteConfigHash: b.hash,
+			Status:               protobufs.RemoteConfigStatuses_RemoteConfigStatuses_FAILED,
+			ErrorMessage:         err.Error(),
+		})
+		c.mu.Unlock()
+		c.logger.Error("Rejected config bundle from local config source", zap.String("directory", c.cfg.Directory), zap.Error(err))
+		if err := c.writeStatus(); err != nil {
+			c.logger.Error("Could not write local config source status", zap.Error(err))
+		}
+		return
+	}
+	c.lastHash = b.hash
+	c.rejected, c.rejectedSignature = false, nil
+	onMessage := c.callbacks.OnMessage
+	c.mu.Unlock()
+
+	c.logger.Info("New config bundle found in local config source", zap.String("directory", c.cfg.Directory))
+	if onMessage != nil {
+		onMessage(ctx, &types.MessageData{RemoteConfig: b.remoteConfig})
+	}
+}
+
+// Stop stops watching the directory.
+func (c *Client) Stop(context.Context) error {
+	select {
+	case <-c.stopCh:
+	default:
+		close(c.stopCh)
+	}
+	c.wg.Wait()
+	return nil
+}
+
+func (c *Client) SetAgentDescription(descr *protobufs.AgentDescription) error {
+	c.mu.Lock()
+	c.agentDesc = descr
+	c.mu.Unlock()
+	return nil
+}
+
+func (c *Client) AgentDescription() *protobufs.AgentDescription {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.agentDesc
+}
+
+func (c *Client) SetHealth(health *protobufs.ComponentHealth) error {
+	c.mu.Lock()
+	c.status.setHealth(health)
+	c.mu.Unlock()
+	return c.writeStatus()
+}
+
+func (c *Client) UpdateEffectiveConfig(ctx context.Context) error {
+	c.mu.Lock()
+	getEffectiveConfig := c.callbacks.GetEffectiveConfig
+	c.mu.Unlock()
+	if getEffectiveConfig == nil {
+		return nil
+	}
+	effectiveConfig, err := getEffectiveConfig(ctx)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	c.status.setEffectiveConfig(effectiveConfig)
+	c.mu.Unlock()
+	return c.writeStatus()
+}
+
+func (c *Client) SetRemoteConfigStatus(status *protobufs.RemoteConfigStatus) error {
+	c.mu.Lock()
+	c.status.setRemoteConfigStatus(status)
+	c.mu.Unlock()
+	return c.writeStatus()
+}
+
+// SetPackageStatuses is a no-op, packages are not supported without an
+// OpAMP server.
+func (*Client) SetPackageStatuses(*protobufs.PackageStatuses) error {
+	return nil
+}
+
+// RequestConnectionSettings is a no-op, there are no connection settings to
+// request without an OpAMP server.
+func (*Client) RequestConnectionSettings(*protobufs.ConnectionSettingsRequest) error {
+	return nil
+}
+
+func (*Client) SetCustomCapabilities(*protobufs.CustomCapabilities) error {
+	return nil
+}
+
+func (*Client) SetFlags(protobufs.AgentToServerFlags) {}
+
+// SendCustomMessage rejects every message, there is no server to send them
+// to.
+func (*Client) SendCustomMessage(*protobufs.CustomMessage) (chan struct{}, error) {
+	return nil, types.ErrCustomCapabilityNotSupported
+}
+
+func (*Client) SetAvailableComponents(*protobufs.AvailableComponents) error {
+	return nil
+}
+
+func (*Client) SetCapabilities(*protobufs.AgentCapabilities) error {
+	return nil
+}
@@ -0,0 +1,228 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package localsource
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/open-telemetry/opamp-go/client/types"
+	"github.com/open-telemetry/opamp-go/protobufs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/cmd/opampsupervisor/supervisor/config"
+)
+
+type messageRecorder struct {
+	mu       sync.Mutex
+	messages []*types.MessageData
+}
+
+func (r *messageRecorder) onMessage(_ context.Context, msg *types.MessageData) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.messages = append(r.messages, msg)
+}
+
+func (r *messageRecorder) get() []*types.MessageData {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.messages
+}
+
+func newTestClient(t *testing.T, cfg config.LocalConfigSource) (*Client, string) {
+	statusFile := filepath.Join(t.TempDir(), "status.json")
+	if cfg.PollInterval == 0 {
+		cfg.PollInterval = time.Hour
+	}
+	c, err := NewClient(cfg, statusFile, zap.NewNop())
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, c.Stop(t.Context())) })
+	return c, statusFile
+}
+
+func startTestClient(t *testing.T, c *Client, status *protobufs.RemoteConfigStatus) *messageRecorder {
+	recorder := &messageRecorder{}
+	require.NoError(t, c.Start(t.Context(), types.StartSettings{
+		InstanceUid:        types.InstanceUid(uuid.MustParse("018fee23-4a51-7303-a441-73faed7d9deb")),
+		RemoteConfigStatus: status,
+		Callbacks: types.Callbacks{
+			OnMessage: recorder.onMessage,
+		},
+	}))
+	return recorder
+}
+
+func readStatus(t *testing.T, statusFile string) Status {
+	data, err := os.ReadFile(statusFile)
+	require.NoError(t, err)
+	var status Status
+	require.NoError(t, json.Unmarshal(data, &status))
+	return status
+}
+
+func writeFile(t *testing.T, dir, name, body string) {
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(body), 0o600))
+}
+
+func TestClientDeliversBundle(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "b.yml", "exporters:\n  debug:\n")
+	writeFile(t, dir, "a.yaml", "receivers:\n  nop:\n")
+	writeFile(t, dir, ".hidden.yaml", "ignored: true\n")
+	writeFile(t, dir, "README.md", "ignored\n")
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "dir.yaml"), 0o700))
+	key := newTestKey(t)
+	key.sign(t, dir)
+
+	c, _ := newTestClient(t, config.LocalConfigSource{Directory: dir, Verifier: key.verifier})
+	recorder := startTestClient(t, c, nil)
+
+	c.poll(t.Context())
+	require.Len(t, recorder.get(), 1)
+	remoteConfig := recorder.get()[0].RemoteConfig
+	require.NotNil(t, remoteConfig)
+	assert.NotEmpty(t, remoteConfig.ConfigHash)
+	configMap := remoteConfig.GetConfig().GetConfigMap()
+	require.Len(t, configMap, 2)
+	assert.Equal(t, "receivers:\n  nop:\n", string(configMap["a.yaml"].Body))
+	assert.Equal(t, "exporters:\n  debug:\n", string(configMap["b.yml"].Body))
+
+	// An unchanged bundle is not delivered again.
+	c.poll(t.Context())
+	require.Len(t, recorder.get(), 1)
+
+	writeFile(t, dir, "a.yaml", "receivers:\n  otlp:\n")
+	key.sign(t, dir)
+	c.poll(t.Context())
+	require.Len(t, recorder.get(), 2)
+	assert.NotEqual(t, remoteConfig.ConfigHash, recorder.get()[1].RemoteConfig.ConfigHash)
+}
+
+func TestClientSkipsBundleWithKnownStatus(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "config.yaml", "receivers:\n  nop:\n")
+	key := newTestKey(t)
+	key.sign(t, dir)
+	b, err := readBundle(dir, key.newVerifier(t))
+	require.NoError(t, err)
+	remoteConfig := b.remoteConfig
+
+	c, statusFile := newTestClient(t, config.LocalConfigSource{Directory: dir, Verifier: key.verifier})
+	recorder := startTestClient(t, c, &protobufs.RemoteConfigStatus{
+		LastRemoteConfigHash: remoteConfig.ConfigHash,
+		Status:               protobufs.RemoteConfigStatuses_RemoteConfigStatuses_FAILED,
+		ErrorMessage:         "rolled back",
+	})
+
+	c.poll(t.Context())
+	assert.Empty(t, recorder.get())
+
+	status := readStatus(t, statusFile)
+	assert.Equal(t, "018fee23-4a51-7303-a441-73faed7d9deb", status.InstanceUID)
+	assert.Equal(t, &RemoteConfigStatus{
+		ConfigHash:   hex.EncodeToString(remoteConfig.ConfigHash),
+		Status:       "FAILED",
+		ErrorMessage: "rolled back",
+	}, status.RemoteConfigStatus)
+}
+
+func TestClientVerifiesSignatures(t *testing.T) {
+	key := newTestKey(t)
+	dir := t.TempDir()
+	body := "receivers:\n  nop:\n"
+	writeFile(t, dir, "config.yaml", body)
+	key.sign(t, dir)
+
+	c, statusFile := newTestClient(t, config.LocalConfigSource{Directory: dir, Verifier: key.verifier})
+	recorder := startTestClient(t, c, nil)
+
+	c.poll(t.Context())
+	require.Len(t, recorder.get(), 1)
+
+	// A tampered file is rejected and reported as failed.
+	writeFile(t, dir, "config.yaml", "receivers:\n  otlp:\n")
+	c.poll(t.Context())
+	require.Len(t, recorder.get(), 1)
+
+	status := readStatus(t, statusFile)
+	require.NotNil(t, status.RemoteConfigStatus)
+	assert.Equal(t, "FAILED", status.RemoteConfigStatus.Status)
+	assert.Equal(t, "verify bundle signature: invalid ed25519 signature", status.RemoteConfigStatus.ErrorMessage)
+
+	// A missing signature is rejected as well.
+	require.NoError(t, os.Remove(filepath.Join(dir, signatureFile)))
+	writeFile(t, dir, "config.yaml", "receivers:\n  debug:\n")
+	c.poll(t.Context())
+	require.Len(t, recorder.get(), 1)
+	status = readStatus(t, statusFile)
+	assert.Equal(t, "missing bundle signature file bundle.sig", status.RemoteConfigStatus.ErrorMessage)
+
+	// The rejected bundle is delivered once it is signed.
+	key.sign(t, dir)
+	c.poll(t.Context())
+	require.Len(t, recorder.get(), 2)
+	assert.Equal(t, "receivers:\n  debug:\n", string(recorder.get()[1].RemoteConfig.GetConfig().GetConfigMap()["config.yaml"].Body))
+	c.poll(t.Context())
+	require.Len(t, recorder.get(), 2)
+}
+
+func TestClientWritesStatus(t *testing.T) {
+	c, statusFile := newTestClient(t, config.LocalConfigSource{Directory: t.TempDir()})
+	require.NoError(t, c.Start(t.Context(), types.StartSettings{
+		Callbacks: types.Callbacks{
+			GetEffectiveConfig: func(context.Context) (*protobufs.EffectiveConfig, error) {
+				return &protobufs.EffectiveConfig{
+					ConfigMap: &protobufs.AgentConfigMap{
+						ConfigMap: map[string]*protobufs.AgentConfigFile{
+							"": {Body: []byte("receivers:\n  nop:\n")},
+						},
+					},
+				}, nil
+			},
+		},
+	}))
+
+	require.NoError(t, c.SetHealth(&protobufs.ComponentHealth{Healthy: false, LastError: "exporter is down"}))
+	require.NoError(t, c.SetRemoteConfigStatus(&protobufs.RemoteConfigStatus{
+		LastRemoteConfigHash: []byte{0xab, 0xcd},
+		Status:               protobufs.RemoteConfigStatuses_RemoteConfigStatuses_APPLIED,
+	}))
+	require.NoError(t, c.UpdateEffectiveConfig(t.Context()))
+
+	status := readStatus(t, statusFile)
+	assert.Equal(t, &Health{Healthy: false, LastError: "exporter is down"}, status.Health)
+	assert.Equal(t, &RemoteConfigStatus{ConfigHash: "abcd", Status: "APPLIED"}, status.RemoteConfigStatus)
+	assert.Equal(t, map[string]string{"": "receivers:\n  nop:\n"}, status.EffectiveConfig)
+	assert.False(t, status.UpdatedAt.IsZero())
+
+	_, err := c.SendCustomMessage(&protobufs.CustomMessage{})
+	require.ErrorIs(t, err, types.ErrCustomCapabilityNotSupported)
+}
+
+func TestClientStartWatching(t *testing.T) {
+	dir := t.TempDir()
+	key := newTestKey(t)
+	c, _ := newTestClient(t, config.LocalConfigSource{Directory: dir, PollInterval: 10 * time.Millisecond, Verifier: key.verifier})
+	recorder := startTestClient(t, c, nil)
+	c.StartWatching(t.Context())
+
+	// The bundle is picked up once it appears in the directory.
+	writeFile(t, dir, "config.yaml", "receivers:\n  nop:\n")
+	key.sign(t, dir)
+	require.Eventually(t, func() bool {
+		return len(recorder.get()) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, c.Stop(t.Context()))
+}
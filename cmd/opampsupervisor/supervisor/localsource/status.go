@@ -0,0 +1,108 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package localsource
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/open-telemetry/opamp-go/protobufs"
+)
+
+// Status is the content of the status file, holding what the Supervisor
+// would report to an OpAMP server.
+type Status struct {
+	InstanceUID        string              `json:"instance_uid"`
+	UpdatedAt          time.Time           `json:"updated_at"`
+	Health             *Health             `json:"health,omitempty"`
+	RemoteConfigStatus *RemoteConfigStatus `json:"remote_config_status,omitempty"`
+	// EffectiveConfig maps the effective config file names to their bodies.
+	EffectiveConfig map[string]string `json:"effective_config,omitempty"`
+}
+
+// Health is the health of the Collector.
+type Health struct {
+	Healthy   bool   `json:"healthy"`
+	Status    string `json:"status,omitempty"`
+	LastError string `json:"last_error,omitempty"`
+}
+
+// RemoteConfigStatus is the status of the last bundle.
+type RemoteConfigStatus struct {
+	// ConfigHash is the hex encoded hash of the bundle.
+	ConfigHash string `json:"config_hash"`
+	// Status is one of APPLIED, APPLYING, FAILED or UNSET.
+	Status       string `json:"status"`
+	ErrorMessage string `json:"error_message,omitempty"`
+}
+
+func (s *Status) setHealth(health *protobufs.ComponentHealth) {
+	if health == nil {
+		s.Health = nil
+		return
+	}
+	s.Health = &Health{
+		Healthy:   health.Healthy,
+		Status:    health.Status,
+		LastError: health.LastError,
+	}
+}
+
+func (s *Status) setRemoteConfigStatus(status *protobufs.RemoteConfigStatus) {
+	if status == nil {
+		s.RemoteConfigStatus = nil
+		return
+	}
+	s.RemoteConfigStatus = &RemoteConfigStatus{
+		ConfigHash:   hex.EncodeToString(status.LastRemoteConfigHash),
+		Status:       strings.TrimPrefix(status.Status.String(), "RemoteConfigStatuses_"),
+		ErrorMessage: status.ErrorMessage,
+	}
+}
+
+func (s *Status) setEffectiveConfig(effectiveConfig *protobufs.EffectiveConfig) {
+	configMap := effectiveConfig.GetConfigMap().GetConfigMap()
+	if len(configMap) == 0 {
+		s.EffectiveConfig = nil
+		return
+	}
+	s.EffectiveConfig = make(map[string]string, len(configMap))
+	for name, file := range configMap {
+		s.EffectiveConfig[name] = string(file.GetBody())
+	}
+}
+
+// writeStatus writes the status file, replacing it atomically so that
+// readers never see a partial file.
+func (c *Client) writeStatus() error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	c.mu.Lock()
+	c.status.UpdatedAt = time.Now().UTC()
+	data, err := json.MarshalIndent(c.status, "", "  ")
+	c.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(c.statusFile), filepath.Base(c.statusFile)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err = tmp.Write(append(data, '\n')); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), c.statusFile)
+}
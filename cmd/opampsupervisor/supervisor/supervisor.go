@@ -53,6 +53,7 @@ import (
 	"github.com/open-telemetry/opentelemetry-collector-contrib/cmd/opampsupervisor/supervisor/commander"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/cmd/opampsupervisor/supervisor/config"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/cmd/opampsupervisor/supervisor/extensions"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/cmd/opampsupervisor/supervisor/localsource"
 	supervisorTelemetry "github.com/open-telemetry/opentelemetry-collector-contrib/cmd/opampsupervisor/supervisor/telemetry"
 )
 
@@ -80,6 +81,7 @@ const (
 	persistentStateFileName     = "persistent_state.yaml"
 	agentConfigFileName         = "effective.yaml"
 	agentLogFileName            = "agent.log"
+	localConfigStatusFileName   = "local_config_status.json"
 	AllowNoPipelinesFeatureGate = "service.AllowNoPipelines"
 )
 
@@ -169,6 +171,10 @@ type Supervisor struct {
 
 	// The OpAMP client to connect to the OpAMP Server.
 	opampClient client.OpAMPClient
+	// localConfigSource is the OpAMP client reading remote configs from a
+	// local directory. nil unless local_config_source is configured, in which
+	// case it is also [opampClient].
+	localConfigSource *localsource.Client
 
 	doneChan chan struct{}
 	agentWG  sync.WaitGroup
@@ -427,6 +433,12 @@ func (s *Supervisor) Start(ctx context.Context) error {
 		s.forwardCustomMessagesToServerLoop()
 	})
 
+	if s.localConfigSource != nil {
+		// Bundles are read once the initial config is loaded, so that they
+		// are not overwritten by the persisted remote config.
+		s.localConfigSource.StartWatching(s.runCtx)
+	}
+
 	return nil
 }
 
@@ -667,6 +679,10 @@ func (s *Supervisor) startOpAMP() error {
 }
 
 func (s *Supervisor) startOpAMPClient() error {
+	if s.config.LocalConfigSource.Enabled() {
+		return s.startLocalConfigSourceClient()
+	}
+
 	// determine if we need to load a TLS config or not
 	var tlsConfig *tls.Config
 	parsedURL, err := url.Parse(s.config.Server.Endpoint)
@@ -774,6 +790,43 @@ func (s *Supervisor) startOpAMPClient() error {
 	return nil
 }
 
+// startLocalConfigSourceClient starts the OpAMP client reading remote configs
+// from the local config source directory in place of an OpAMP server.
+func (s *Supervisor) startLocalConfigSourceClient() error {
+	statusFile := s.config.LocalConfigSource.StatusFile
+	if statusFile == "" {
+		statusFile = filepath.Join(s.config.Storage.Directory, localConfigStatusFileName)
+	}
+	localClient, err := localsource.NewClient(s.config.LocalConfigSource, statusFile, s.telemetrySettings.Logger)
+	if err != nil {
+		return fmt.Errorf("create local config source: %w", err)
+	}
+	s.localConfigSource = localClient
+	s.opampClient = localClient
+
+	ad := s.agentDescription.Load().(*protobufs.AgentDescription)
+	if err := s.opampClient.SetAgentDescription(ad); err != nil {
+		return err
+	}
+
+	if err := s.SetHealth(&protobufs.ComponentHealth{Healthy: false}); err != nil {
+		return err
+	}
+
+	s.telemetrySettings.Logger.Debug("Starting local config source...", zap.String("directory", s.config.LocalConfigSource.Directory))
+	return s.opampClient.Start(s.runCtx, types.StartSettings{
+		InstanceUid:        types.InstanceUid(s.persistentState.InstanceID),
+		RemoteConfigStatus: s.persistentState.GetLastRemoteConfigStatus(),
+		Callbacks: types.Callbacks{
+			OnConnect: s.onConnect,
+			OnMessage: s.onMessage,
+			GetEffectiveConfig: func(context.Context) (*protobufs.EffectiveConfig, error) {
+				return s.createEffectiveConfigMsg(), nil
+			},
+		},
+	})
+}
+
 func (s *Supervisor) startHealthCheckServer() error {
 	if s.config.HealthCheck.Port() == 0 {
 		return nil
@@ -1975,9 +2028,21 @@ func (s *Supervisor) runAgentProcess() {
 	configApplyTimeoutTimer := time.NewTimer(0)
 	configApplyTimeoutTimer.Stop()
 
+	// The probation of the active remote config, nil when it is not on
+	// probation. probationTicker only runs during a probation.
+	var probation *configProbation
+	probationTicker := time.NewTicker(time.Hour)
+	probationTicker.Stop()
+	defer probationTicker.Stop()
+	stopProbation := func() {
+		probation = nil
+		probationTicker.Stop()
+	}
+
 	for {
 		select {
 		case <-s.hasNewConfig:
+			stopProbation()
 			s.lastHealthFromClient.Store(nil)
 			s.telemetrySettings.Logger.Debug("agent has new config", zap.String("previous_health", s.lastHealthFromClient.Load().String()))
 			if !configApplyTimeoutTimer.Stop() {
@@ -2084,6 +2149,17 @@ func (s *Supervisor) runAgentProcess() {
 					continue
 				}
 			}
+			if probation != nil {
+				// A crash during probation fails the config just like a crash
+				// while applying it.
+				stopProbation()
+				s.telemetrySettings.Logger.Info("Agent crashed during config probation, reporting FAILED status")
+				failureMsg := fmt.Sprintf("Agent exited unexpectedly with exit code %d during config probation", s.commander.ExitCode())
+				s.saveAndReportConfigStatus(protobufs.RemoteConfigStatuses_RemoteConfigStatuses_FAILED, s.appendCollectorCrashDetails(failureMsg))
+				if s.restoreLastWorkingRemoteConfig() {
+					continue
+				}
+			}
 
 			// Wait 5 seconds before starting again.
 			if !restartTimer.Stop() {
@@ -2110,8 +2186,33 @@ func (s *Supervisor) runAgentProcess() {
 				}
 				continue
 			}
+			if s.shouldStartConfigProbation() {
+				// The config is only reported as applied, and saved as the last
+				// working one, once it passes its probation.
+				probation = s.startConfigProbation()
+				probationTicker.Reset(s.config.Agent.ConfigProbation.CheckInterval)
+				continue
+			}
 			s.reportActiveConfigStatus(protobufs.RemoteConfigStatuses_RemoteConfigStatuses_APPLIED, "")
 
+		case <-probationTicker.C:
+			if probation == nil {
+				continue
+			}
+			reason, done := s.checkConfigProbation(probation)
+			if !done {
+				continue
+			}
+			stopProbation()
+			if reason != "" {
+				s.telemetrySettings.Logger.Warn("Remote config failed its probation, rolling back", zap.String("reason", reason))
+				s.saveAndReportConfigStatus(protobufs.RemoteConfigStatuses_RemoteConfigStatuses_FAILED, reason)
+				s.restoreLastWorkingRemoteConfig()
+				continue
+			}
+			s.telemetrySettings.Logger.Info("Remote config passed its probation")
+			s.saveAndReportConfigStatus(protobufs.RemoteConfigStatuses_RemoteConfigStatuses_APPLIED, "")
+
 		case <-s.doneChan:
 			err := s.commander.Stop(s.runCtx)
 			if err != nil {
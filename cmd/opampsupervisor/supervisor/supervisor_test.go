@@ -5,7 +5,11 @@ package supervisor
 
 import (
 	"context"
+	"crypto/ed25519"
+	"crypto/rand"
 	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
 	"errors"
 	"fmt"
 	"io"
@@ -2454,6 +2458,84 @@ func TestSupervisor_reportLastWorkingRemoteConfigStatus(t *testing.T) {
 	require.Equal(t, "boom", lastRemoteConfigStatus.ErrorMessage)
 }
 
+func TestSupervisor_startLocalConfigSourceClient(t *testing.T) {
+	bundleDir := t.TempDir()
+	body := []byte("receivers:\n  debug:\n")
+	require.NoError(t, os.WriteFile(filepath.Join(bundleDir, "config.yaml"), body, 0o600))
+	// The bundle hash covers the name and content of every file.
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+	require.NoError(t, err)
+	publicKeyFile := filepath.Join(t.TempDir(), "bundle.pub")
+	require.NoError(t, os.WriteFile(publicKeyFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600))
+	hash := sha256.Sum256(slices.Concat([]byte("config.yaml\x00"), body, []byte{0}))
+	require.NoError(t, os.WriteFile(filepath.Join(bundleDir, "bundle.sig"), ed25519.Sign(privateKey, hash[:]), 0o600))
+	storageDir := t.TempDir()
+
+	persistentState, err := loadOrCreatePersistentState(
+		filepath.Join(storageDir, persistentStateFileName),
+		"018fee23-4a51-7303-a441-73faed7d9deb",
+		zap.NewNop(),
+	)
+	require.NoError(t, err)
+
+	s := Supervisor{
+		runCtx:            t.Context(),
+		telemetrySettings: newNopTelemetrySettings(),
+		pidProvider:       staticPIDProvider(88888),
+		config: config.Supervisor{
+			Capabilities: config.Capabilities{AcceptsRemoteConfig: true, ReportsRemoteConfig: true},
+			Storage: config.Storage{
+				Directory: storageDir,
+			},
+			LocalConfigSource: config.LocalConfigSource{
+				Directory:    bundleDir,
+				PollInterval: time.Hour,
+				Verifier:     config.Verifier{Type: config.VerifierTypeEd25519, PublicKeyFile: publicKeyFile},
+			},
+		},
+		hasNewConfig:                   make(chan struct{}, 1),
+		persistentState:                persistentState,
+		agentConfigOwnTelemetrySection: &atomic.Value{},
+		effectiveConfig:                &atomic.Value{},
+		agentDescription:               &atomic.Value{},
+		cfgState:                       &atomic.Value{},
+		customMessageToServer:          make(chan *protobufs.CustomMessage, 10),
+		doneChan:                       make(chan struct{}),
+		metrics:                        &telemetry.Metrics{},
+	}
+	require.NoError(t, s.createTemplates())
+	s.agentDescription.Store(&protobufs.AgentDescription{})
+
+	require.NoError(t, s.startOpAMPClient())
+	require.NotNil(t, s.localConfigSource)
+	t.Cleanup(func() { require.NoError(t, s.opampClient.Stop(t.Context())) })
+
+	statusFile := filepath.Join(storageDir, localConfigStatusFileName)
+	require.FileExists(t, statusFile)
+
+	s.localConfigSource.StartWatching(t.Context())
+	select {
+	case <-s.hasNewConfig:
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "the bundle was not applied")
+	}
+	require.NoError(t, s.opampClient.Stop(t.Context()))
+	require.Equal(t, protobufs.RemoteConfigStatuses_RemoteConfigStatuses_APPLYING, s.persistentState.GetLastRemoteConfigStatus().GetStatus())
+
+	// The bundle went through the regular remote config handling.
+	fileContent, err := os.ReadFile(filepath.Join(storageDir, lastRecvRemoteConfigFile))
+	require.NoError(t, err)
+	assert.Contains(t, string(fileContent), "debug")
+	assert.Contains(t, s.cfgState.Load().(*configState).mergedConfig, "debug")
+
+	statusContent, err := os.ReadFile(statusFile)
+	require.NoError(t, err)
+	assert.Contains(t, string(statusContent), `"status": "APPLYING"`)
+	assert.Contains(t, string(statusContent), `"instance_uid": "018fee23-4a51-7303-a441-73faed7d9deb"`)
+}
+
 func TestSupervisor_setAgentDescription(t *testing.T) {
 	s := &Supervisor{
 		agentDescription: &atomic.Value{},
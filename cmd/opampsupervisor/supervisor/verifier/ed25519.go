@@ -0,0 +1,50 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package verifier
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/cmd/opampsupervisor/supervisor/config"
+)
+
+// ed25519Verifier verifies raw Ed25519 signatures, as produced by
+// `openssl pkeyutl -sign -rawin`.
+type ed25519Verifier struct {
+	publicKey ed25519.PublicKey
+}
+
+func newEd25519Verifier(publicKeyFile string) (*ed25519Verifier, error) {
+	data, err := os.ReadFile(publicKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("read public key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data found in %s", publicKeyFile)
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse public key: %w", err)
+	}
+	publicKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("public key in %s is a %T, not an Ed25519 key", publicKeyFile, key)
+	}
+	return &ed25519Verifier{publicKey: publicKey}, nil
+}
+
+func (v *ed25519Verifier) Verify(packageBytes, signature []byte) error {
+	if !ed25519.Verify(v.publicKey, packageBytes, signature) {
+		return errors.New("invalid ed25519 signature")
+	}
+	return nil
+}
+
+func (*ed25519Verifier) Type() string { return config.VerifierTypeEd25519 }
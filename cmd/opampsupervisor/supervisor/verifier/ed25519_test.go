@@ -0,0 +1,80 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package verifier
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/cmd/opampsupervisor/supervisor/config"
+)
+
+func writePublicKey(t *testing.T, key any) string {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "key.pub")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600))
+	return path
+}
+
+func TestEd25519Verifier(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	v, err := NewVerifier(config.Verifier{Type: config.VerifierTypeEd25519, PublicKeyFile: writePublicKey(t, publicKey)})
+	require.NoError(t, err)
+	assert.Equal(t, config.VerifierTypeEd25519, v.Type())
+
+	data := []byte("receivers:\n  nop:\n")
+	require.NoError(t, v.Verify(data, ed25519.Sign(privateKey, data)))
+	require.EqualError(t, v.Verify([]byte("tampered"), ed25519.Sign(privateKey, data)), "invalid ed25519 signature")
+	require.EqualError(t, v.Verify(data, nil), "invalid ed25519 signature")
+}
+
+func TestNewEd25519VerifierErrors(t *testing.T) {
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	notPEM := filepath.Join(t.TempDir(), "key.pub")
+	require.NoError(t, os.WriteFile(notPEM, []byte("not a key"), 0o600))
+
+	testCases := []struct {
+		name          string
+		publicKeyFile string
+		expectedErr   string
+	}{
+		{
+			name:          "missing file",
+			publicKeyFile: filepath.Join(t.TempDir(), "missing.pub"),
+			expectedErr:   "read public key",
+		},
+		{
+			name:          "not PEM",
+			publicKeyFile: notPEM,
+			expectedErr:   "no PEM data found",
+		},
+		{
+			name:          "not an Ed25519 key",
+			publicKeyFile: writePublicKey(t, &ecdsaKey.PublicKey),
+			expectedErr:   "not an Ed25519 key",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			v, err := NewVerifier(config.Verifier{Type: config.VerifierTypeEd25519, PublicKeyFile: tc.publicKeyFile})
+			require.ErrorContains(t, err, tc.expectedErr)
+			assert.Nil(t, v)
+		})
+	}
+}
@@ -2,7 +2,7 @@
 // SPDX-License-Identifier: Apache-2.0
 
 // Package verifier verifies the signatures of collector executable packages
-// downloaded by the supervisor.
+// downloaded by the supervisor and of local config bundles.
 package verifier
 
 import (
@@ -24,6 +24,8 @@ func NewVerifier(cfg config.Verifier) (Verifier, error) {
 	switch cfg.Type {
 	case config.VerifierTypeNone:
 		return &noneVerifier{}, nil
+	case config.VerifierTypeEd25519:
+		return newEd25519Verifier(cfg.PublicKeyFile)
 	default:
 		return nil, fmt.Errorf("unsupported verifier type: %q", cfg.Type)
 	}
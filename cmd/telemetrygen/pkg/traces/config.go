@@ -23,6 +23,9 @@ type Config struct {
 	NumSpanLinks     int
 
 	SpanDuration time.Duration
+	// ScenarioFile is the path of a YAML scenario replayed instead of the
+	// flat root and child spans topology.
+	ScenarioFile string
 }
 
 func NewConfig() *Config {
@@ -43,6 +46,7 @@ func (c *Config) Flags(fs *pflag.FlagSet) {
 	fs.StringVar(&c.StatusCode, "status-code", c.StatusCode, "Status code to use for the spans, one of (Unset, Error, Ok) or the equivalent integer (0,1,2)")
 	fs.IntVar(&c.NumSpanLinks, "span-links", c.NumSpanLinks, "Number of span links to generate for each span")
 	fs.DurationVar(&c.SpanDuration, "span-duration", c.SpanDuration, "The duration of each generated span.")
+	fs.StringVar(&c.ScenarioFile, "scenario", c.ScenarioFile, "Path of a YAML scenario describing the services and spans of each trace. Overrides --child-spans, --marshal, --span-duration and --status-code")
 }
 
 // SetDefaults sets the default values for the configuration
@@ -58,6 +62,7 @@ func (c *Config) SetDefaults() {
 	c.StatusCode = "0"
 	c.NumSpanLinks = 0
 	c.SpanDuration = 123 * time.Microsecond
+	c.ScenarioFile = ""
 }
 
 // Validate validates the test scenario parameters.
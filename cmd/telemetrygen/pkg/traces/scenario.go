@@ -0,0 +1,558 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package traces
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	conventions "go.opentelemetry.io/otel/semconv/v1.40.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"golang.org/x/time/rate"
+	"gopkg.in/yaml.v3"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/cmd/telemetrygen/internal/config"
+)
+
+// Scenario describes a multi-service call graph replayed for each generated
+// trace.
+type Scenario struct {
+	// Services holds the resource attributes of each service, in addition to
+	// service.name and the --otlp-attributes. Services without extra
+	// attributes do not need to be listed.
+	Services map[string]ServiceSpec `yaml:"services"`
+	// Root is the root span of each trace.
+	Root SpanSpec `yaml:"root"`
+}
+
+// ServiceSpec describes a service of a scenario.
+type ServiceSpec struct {
+	ResourceAttributes map[string]any `yaml:"resource_attributes"`
+}
+
+// SpanSpec describes a span of a scenario and its children.
+type SpanSpec struct {
+	// Name is the span name. Like string attribute values, it may be a
+	// template.
+	Name string `yaml:"name"`
+	// Service emitting the span. Inherited from the parent when empty.
+	Service string `yaml:"service"`
+	// Kind is one of internal, server, client, producer or consumer.
+	// Defaults to client for DB spans, producer or consumer for messaging
+	// spans and internal otherwise.
+	Kind string `yaml:"kind"`
+	// Count is the number of sibling spans generated from this spec,
+	// defaults to 1.
+	Count int `yaml:"count"`
+	// Parallel starts all children at the same time instead of one after
+	// the other.
+	Parallel bool `yaml:"parallel"`
+	// Latency is the time spent in the span itself, excluding its children.
+	Latency Latency `yaml:"latency"`
+	// ErrorProbability is the probability, between 0 and 1, that the span
+	// has an error status.
+	ErrorProbability float64 `yaml:"error_probability"`
+	// ErrorType is the error.type attribute of failed spans, defaults to
+	// _OTHER.
+	ErrorType string `yaml:"error_type"`
+	// Attributes of the span. String values may be templates.
+	Attributes map[string]any `yaml:"attributes"`
+	// DB sets the database client attributes.
+	DB *DBSpec `yaml:"db"`
+	// Messaging sets the messaging attributes.
+	Messaging *MessagingSpec `yaml:"messaging"`
+	// Children are the child spans.
+	Children []SpanSpec `yaml:"children"`
+}
+
+// DBSpec describes a database call.
+type DBSpec struct {
+	System     string `yaml:"system"`
+	Namespace  string `yaml:"namespace"`
+	Operation  string `yaml:"operation"`
+	Collection string `yaml:"collection"`
+	Query      string `yaml:"query"`
+}
+
+// MessagingSpec describes a messaging operation.
+type MessagingSpec struct {
+	System      string `yaml:"system"`
+	Destination string `yaml:"destination"`
+	// Operation is the messaging.operation.type: create, send, receive,
+	// process or settle.
+	Operation string `yaml:"operation"`
+}
+
+// Latency describes a latency distribution.
+type Latency struct {
+	// Distribution is one of constant (the default), uniform, normal or
+	// exponential.
+	Distribution string `yaml:"distribution"`
+	// Value is the latency of the constant distribution.
+	Value time.Duration `yaml:"value"`
+	// Min and Max bound the uniform distribution. Max also caps the other
+	// distributions when set.
+	Min time.Duration `yaml:"min"`
+	Max time.Duration `yaml:"max"`
+	// Mean of the normal and exponential distributions.
+	Mean time.Duration `yaml:"mean"`
+	// StdDev of the normal distribution.
+	StdDev time.Duration `yaml:"stddev"`
+}
+
+var spanKinds = map[string]trace.SpanKind{
+	"internal": trace.SpanKindInternal,
+	"server":   trace.SpanKindServer,
+	"client":   trace.SpanKindClient,
+	"producer": trace.SpanKindProducer,
+	"consumer": trace.SpanKindConsumer,
+}
+
+var messagingOperations = map[string]trace.SpanKind{
+	"create":  trace.SpanKindProducer,
+	"send":    trace.SpanKindProducer,
+	"receive": trace.SpanKindConsumer,
+	"process": trace.SpanKindConsumer,
+	"settle":  trace.SpanKindClient,
+}
+
+// LoadScenario reads and validates a scenario file. Unknown keys are
+// rejected.
+func LoadScenario(path string) (*Scenario, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scenario file: %w", err)
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(content))
+	dec.KnownFields(true)
+	scn := &Scenario{}
+	if err = dec.Decode(scn); err != nil {
+		return nil, fmt.Errorf("failed to parse scenario file %q: %w", path, err)
+	}
+	if err = scn.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid scenario file %q: %w", path, err)
+	}
+	return scn, nil
+}
+
+// Validate checks the scenario and sets the defaults of its spans.
+func (s *Scenario) Validate() error {
+	if s.Root.Service == "" {
+		return errors.New("root: `service` must be set")
+	}
+	if s.Root.Count > 1 {
+		return errors.New("root: `count` must be 1")
+	}
+	return s.Root.validate("root", "")
+}
+
+func (s *SpanSpec) validate(path, parentService string) error {
+	if s.Name == "" {
+		return fmt.Errorf("%s: `name` must be set", path)
+	}
+	if s.Service == "" {
+		s.Service = parentService
+	}
+	if s.Count < 0 {
+		return fmt.Errorf("%s: `count` cannot be negative", path)
+	}
+	if s.Count == 0 {
+		s.Count = 1
+	}
+	if s.Kind != "" {
+		if _, ok := spanKinds[s.Kind]; !ok {
+			return fmt.Errorf("%s: unknown `kind` %q, expected one of internal, server, client, producer or consumer", path, s.Kind)
+		}
+	}
+	if s.ErrorProbability < 0 || s.ErrorProbability > 1 {
+		return fmt.Errorf("%s: `error_probability` must be between 0 and 1", path)
+	}
+	if err := s.Latency.validate(); err != nil {
+		return fmt.Errorf("%s: latency: %w", path, err)
+	}
+	if s.DB != nil && s.Messaging != nil {
+		return fmt.Errorf("%s: `db` and `messaging` cannot both be set", path)
+	}
+	if s.DB != nil && s.DB.System == "" {
+		return fmt.Errorf("%s: db: `system` must be set", path)
+	}
+	if s.Messaging != nil {
+		if s.Messaging.System == "" {
+			return fmt.Errorf("%s: messaging: `system` must be set", path)
+		}
+		if _, ok := messagingOperations[s.Messaging.Operation]; !ok {
+			return fmt.Errorf("%s: messaging: unknown `operation` %q, expected one of create, send, receive, process or settle", path, s.Messaging.Operation)
+		}
+	}
+	if _, err := newSpanTemplate(s); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	for i := range s.Children {
+		if err := s.Children[i].validate(fmt.Sprintf("%s.children[%d]", path, i), s.Service); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (l *Latency) validate() error {
+	if l.Value < 0 || l.Min < 0 || l.Max < 0 || l.Mean < 0 || l.StdDev < 0 {
+		return errors.New("durations cannot be negative")
+	}
+	switch l.Distribution {
+	case "", "constant":
+	case "uniform":
+		if l.Max < l.Min {
+			return errors.New("`max` must be greater than or equal to `min`")
+		}
+	case "normal", "exponential":
+		if l.Mean == 0 {
+			return fmt.Errorf("`mean` must be set for the %s distribution", l.Distribution)
+		}
+	default:
+		return fmt.Errorf("unknown `distribution` %q, expected one of constant, uniform, normal or exponential", l.Distribution)
+	}
+	return nil
+}
+
+// sample returns a random latency from the distribution.
+func (l *Latency) sample() time.Duration {
+	var d time.Duration
+	switch l.Distribution {
+	case "uniform":
+		d = l.Min + time.Duration(rand.Int64N(int64(l.Max-l.Min)+1))
+	case "normal":
+		d = l.Mean + time.Duration(rand.NormFloat64()*float64(l.StdDev))
+	case "exponential":
+		d = time.Duration(rand.ExpFloat64() * float64(l.Mean))
+	default:
+		return l.Value
+	}
+	if l.Max > 0 && d > l.Max {
+		d = l.Max
+	}
+	return max(d, 0)
+}
+
+// kind returns the span kind, defaulting from the DB or messaging
+// semantics.
+func (s *SpanSpec) kind() trace.SpanKind {
+	switch {
+	case s.Kind != "":
+		return spanKinds[s.Kind]
+	case s.DB != nil:
+		return trace.SpanKindClient
+	case s.Messaging != nil:
+		return messagingOperations[s.Messaging.Operation]
+	default:
+		return trace.SpanKindInternal
+	}
+}
+
+// semanticAttributes returns the DB or messaging attributes of the span.
+func (s *SpanSpec) semanticAttributes() []attribute.KeyValue {
+	var attrs []attribute.KeyValue
+	if s.DB != nil {
+		attrs = append(attrs, conventions.DBSystemNameKey.String(s.DB.System))
+		for _, kv := range []struct {
+			key   attribute.Key
+			value string
+		}{
+			{conventions.DBNamespaceKey, s.DB.Namespace},
+			{conventions.DBOperationNameKey, s.DB.Operation},
+			{conventions.DBCollectionNameKey, s.DB.Collection},
+			{conventions.DBQueryTextKey, s.DB.Query},
+		} {
+			if kv.value != "" {
+				attrs = append(attrs, kv.key.String(kv.value))
+			}
+		}
+	}
+	if s.Messaging != nil {
+		attrs = append(attrs,
+			conventions.MessagingSystemKey.String(s.Messaging.System),
+			conventions.MessagingOperationTypeKey.String(s.Messaging.Operation),
+		)
+		if s.Messaging.Destination != "" {
+			attrs = append(attrs, conventions.MessagingDestinationNameKey.String(s.Messaging.Destination))
+		}
+	}
+	return attrs
+}
+
+// templateData is the data available to the span name and attribute
+// templates.
+type templateData struct {
+	// Service emitting the span.
+	Service string
+	// Index of the span among the siblings generated by `count`.
+	Index int
+	// Trace is the number of the trace generated by the worker.
+	Trace int
+}
+
+var templateFuncs = template.FuncMap{
+	"randInt": func(lo, hi int) int {
+		if hi <= lo {
+			return lo
+		}
+		return lo + rand.IntN(hi-lo+1)
+	},
+	"randChoice": func(values ...string) string {
+		if len(values) == 0 {
+			return ""
+		}
+		return values[rand.IntN(len(values))]
+	},
+}
+
+// spanTemplate holds the parsed name and attributes of a SpanSpec.
+type spanTemplate struct {
+	name      *template.Template // nil if the name is not a template
+	static    []attribute.KeyValue
+	templates map[string]*template.Template
+}
+
+func newSpanTemplate(s *SpanSpec) (*spanTemplate, error) {
+	st := &spanTemplate{
+		static:    s.semanticAttributes(),
+		templates: make(map[string]*template.Template),
+	}
+	var err error
+	if strings.Contains(s.Name, "{{") {
+		if st.name, err = template.New("name").Funcs(templateFuncs).Option("missingkey=error").Parse(s.Name); err != nil {
+			return nil, fmt.Errorf("invalid `name` template: %w", err)
+		}
+	}
+	for k, v := range s.Attributes {
+		if str, ok := v.(string); ok && strings.Contains(str, "{{") {
+			if st.templates[k], err = template.New(k).Funcs(templateFuncs).Option("missingkey=error").Parse(str); err != nil {
+				return nil, fmt.Errorf("attribute %q: invalid template: %w", k, err)
+			}
+			continue
+		}
+		kv, ok := toAttribute(k, v)
+		if !ok {
+			return nil, fmt.Errorf("attribute %q: unsupported value type %T", k, v)
+		}
+		st.static = append(st.static, kv)
+	}
+	return st, nil
+}
+
+// spanName returns the span name, executing its template if any.
+func (st *spanTemplate) spanName(name string, data templateData) string {
+	if st.name == nil {
+		return name
+	}
+	return execute(st.name, data)
+}
+
+// attributes returns the static and templated attributes of the span.
+func (st *spanTemplate) attributes(data templateData) []attribute.KeyValue {
+	attrs := make([]attribute.KeyValue, 0, len(st.static)+len(st.templates))
+	attrs = append(attrs, st.static...)
+	for k, tmpl := range st.templates {
+		attrs = append(attrs, attribute.String(k, execute(tmpl, data)))
+	}
+	return attrs
+}
+
+func execute(tmpl *template.Template, data templateData) string {
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "error: " + err.Error()
+	}
+	return sb.String()
+}
+
+// toAttribute converts a YAML scalar or list of scalars to an attribute.
+func toAttribute(k string, v any) (attribute.KeyValue, bool) {
+	switch val := v.(type) {
+	case string:
+		return attribute.String(k, val), true
+	case bool:
+		return attribute.Bool(k, val), true
+	case int:
+		return attribute.Int(k, val), true
+	case float64:
+		return attribute.Float64(k, val), true
+	case []any:
+		return toSliceAttribute(k, val)
+	}
+	return attribute.KeyValue{}, false
+}
+
+func toSliceAttribute(k string, values []any) (attribute.KeyValue, bool) {
+	if len(values) == 0 {
+		return attribute.StringSlice(k, nil), true
+	}
+	switch values[0].(type) {
+	case string:
+		return convertSlice(values, attribute.StringSlice, k)
+	case bool:
+		return convertSlice(values, attribute.BoolSlice, k)
+	case int:
+		return convertSlice(values, attribute.IntSlice, k)
+	case float64:
+		return convertSlice(values, attribute.Float64Slice, k)
+	}
+	return attribute.KeyValue{}, false
+}
+
+// convertSlice converts values to a homogeneous slice attribute.
+func convertSlice[T any](values []any, ctor func(string, []T) attribute.KeyValue, k string) (attribute.KeyValue, bool) {
+	out := make([]T, 0, len(values))
+	for _, v := range values {
+		t, ok := v.(T)
+		if !ok {
+			return attribute.KeyValue{}, false
+		}
+		out = append(out, t)
+	}
+	return ctor(k, out), true
+}
+
+// scenarioNode is a SpanSpec with its parsed templates.
+type scenarioNode struct {
+	spec     *SpanSpec
+	template *spanTemplate
+	children []*scenarioNode
+}
+
+// compile returns the tree of the validated scenario spans.
+func (s *Scenario) compile() (*scenarioNode, error) {
+	return compileSpan(&s.Root)
+}
+
+func compileSpan(spec *SpanSpec) (*scenarioNode, error) {
+	tmpl, err := newSpanTemplate(spec)
+	if err != nil {
+		return nil, err
+	}
+	node := &scenarioNode{spec: spec, template: tmpl}
+	for i := range spec.Children {
+		child, err := compileSpan(&spec.Children[i])
+		if err != nil {
+			return nil, err
+		}
+		node.children = append(node.children, child)
+	}
+	return node, nil
+}
+
+// serviceNames returns the services emitting the spans of the scenario.
+func (s *Scenario) serviceNames() []string {
+	seen := make(map[string]struct{})
+	var names []string
+	var walk func(spec *SpanSpec)
+	walk = func(spec *SpanSpec) {
+		if _, ok := seen[spec.Service]; !ok {
+			seen[spec.Service] = struct{}{}
+			names = append(names, spec.Service)
+		}
+		for i := range spec.Children {
+			walk(&spec.Children[i])
+		}
+	}
+	walk(&s.Root)
+	return names
+}
+
+// resourceAttributes returns the resource attributes of a service, on top
+// of the common ones.
+func (s *Scenario) resourceAttributes(common []attribute.KeyValue, service string) ([]attribute.KeyValue, error) {
+	attrs := append([]attribute.KeyValue{}, common...)
+	attrs = append(attrs, conventions.ServiceNameKey.String(service))
+	for k, v := range s.Services[service].ResourceAttributes {
+		kv, ok := toAttribute(k, v)
+		if !ok {
+			return nil, fmt.Errorf("service %q: resource attribute %q: unsupported value type %T", service, k, v)
+		}
+		attrs = append(attrs, kv)
+	}
+	return attrs, nil
+}
+
+// scenarioReplayer generates the spans of a scenario, each service with its
+// own tracer so that its spans carry its resource.
+type scenarioReplayer struct {
+	root   *scenarioNode
+	tracer func(service string) trace.Tracer
+}
+
+func newScenarioReplayer(scn *Scenario, tracer func(service string) trace.Tracer) (*scenarioReplayer, error) {
+	root, err := scn.compile()
+	if err != nil {
+		return nil, err
+	}
+	return &scenarioReplayer{root: root, tracer: tracer}, nil
+}
+
+// replay generates one trace of the scenario, starting now.
+func (r *scenarioReplayer) replay(w *worker, limiter *rate.Limiter, traceNum int, telemetryAttributes []attribute.KeyValue) {
+	r.emit(context.Background(), w, limiter, r.root, templateData{Service: r.root.spec.Service, Trace: traceNum}, time.Now(), telemetryAttributes)
+}
+
+// emit generates the span of node starting at start, then its children, and
+// returns the end of the span: the end of its children plus its own latency.
+func (r *scenarioReplayer) emit(ctx context.Context, w *worker, limiter *rate.Limiter, node *scenarioNode, data templateData, start time.Time, telemetryAttributes []attribute.KeyValue) time.Time {
+	if err := limiter.Wait(context.Background()); err != nil {
+		w.logger.Fatal("limiter waited failed, retry", zap.Error(err))
+	}
+
+	spec := node.spec
+	ctx, sp := r.tracer(spec.Service).Start(ctx, node.template.spanName(spec.Name, data),
+		trace.WithAttributes(node.template.attributes(data)...),
+		trace.WithSpanKind(spec.kind()),
+		trace.WithTimestamp(start),
+		trace.WithLinks(w.generateSpanLinks()...),
+	)
+	sp.SetAttributes(telemetryAttributes...)
+	for j := 0; j < w.loadSize; j++ {
+		sp.SetAttributes(config.CreateLoadAttribute(fmt.Sprintf("load-%v", j), 1))
+	}
+	w.addSpanContext(sp.SpanContext())
+
+	end := start
+	for _, child := range node.children {
+		for i := 0; i < child.spec.Count; i++ {
+			childData := templateData{Service: child.spec.Service, Index: i, Trace: data.Trace}
+			if spec.Parallel {
+				end = maxTime(end, r.emit(ctx, w, limiter, child, childData, start, telemetryAttributes))
+			} else {
+				end = r.emit(ctx, w, limiter, child, childData, end, telemetryAttributes)
+			}
+		}
+	}
+	end = end.Add(spec.Latency.sample())
+
+	if spec.ErrorProbability > 0 && rand.Float64() < spec.ErrorProbability {
+		errorType := spec.ErrorType
+		if errorType == "" {
+			errorType = conventions.ErrorTypeOther.Value.AsString()
+		}
+		sp.SetAttributes(conventions.ErrorTypeKey.String(errorType))
+		sp.SetStatus(codes.Error, "simulated error")
+	}
+	sp.End(trace.WithTimestamp(end))
+	return end
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
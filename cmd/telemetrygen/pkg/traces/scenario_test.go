@@ -0,0 +1,283 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package traces
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/cmd/telemetrygen/internal/config"
+)
+
+func writeScenario(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "scenario.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+// newScenarioTracers returns a tracer per service of the scenario, all
+// exporting to syncer.
+func newScenarioTracers(t *testing.T, scn *Scenario, syncer *mockSyncer) func(string) trace.Tracer {
+	t.Helper()
+	tracers := make(map[string]trace.Tracer)
+	for _, service := range scn.serviceNames() {
+		attrs, err := scn.resourceAttributes(nil, service)
+		require.NoError(t, err)
+		tp := sdktrace.NewTracerProvider(sdktrace.WithResource(resource.NewSchemaless(attrs...)))
+		tp.RegisterSpanProcessor(sdktrace.NewSimpleSpanProcessor(syncer))
+		tracers[service] = tp.Tracer("telemetrygen")
+	}
+	return func(service string) trace.Tracer { return tracers[service] }
+}
+
+func attributeValue(attrs []attribute.KeyValue, key string) (attribute.Value, bool) {
+	for _, kv := range attrs {
+		if string(kv.Key) == key {
+			return kv.Value, true
+		}
+	}
+	return attribute.Value{}, false
+}
+
+func TestScenarioReplay(t *testing.T) {
+	scn, err := LoadScenario(filepath.Join("testdata", "checkout.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"frontend", "cart"}, scn.serviceNames())
+
+	syncer := &mockSyncer{}
+	replayer, err := newScenarioReplayer(scn, newScenarioTracers(t, scn, syncer))
+	require.NoError(t, err)
+
+	cfg := &Config{
+		Config:    config.Config{WorkerCount: 1},
+		NumTraces: 2,
+	}
+	require.NoError(t, runWorkers(cfg, zap.NewNop(), replayer))
+
+	// Each trace has a root, a client and a server span, 3 DB spans and a
+	// messaging span.
+	require.Len(t, syncer.spans, 14)
+	byName := make(map[string][]sdktrace.ReadOnlySpan)
+	for _, span := range syncer.spans {
+		byName[span.Name()] = append(byName[span.Name()], span)
+	}
+
+	root := byName["GET /checkout"][0]
+	assert.Equal(t, trace.SpanKindServer, root.SpanKind())
+	assert.False(t, root.Parent().IsValid())
+	service, _ := root.Resource().Set().Value("service.name")
+	assert.Equal(t, "frontend", service.AsString())
+	env, _ := root.Resource().Set().Value("deployment.environment.name")
+	assert.Equal(t, "test", env.AsString())
+	userID, ok := attributeValue(root.Attributes(), "user.id")
+	require.True(t, ok)
+	assert.True(t, strings.HasPrefix(userID.AsString(), "user-"))
+
+	require.Len(t, byName["POST /cart"], 4)
+	var client, server sdktrace.ReadOnlySpan
+	for _, span := range byName["POST /cart"][:2] {
+		if span.SpanKind() == trace.SpanKindClient {
+			client = span
+		} else {
+			server = span
+		}
+	}
+	require.NotNil(t, client)
+	require.NotNil(t, server)
+	service, _ = server.Resource().Set().Value("service.name")
+	assert.Equal(t, "cart", service.AsString())
+	_, ok = server.Resource().Set().Value("deployment.environment.name")
+	assert.False(t, ok)
+	assert.Equal(t, client.SpanContext().SpanID(), server.Parent().SpanID())
+	assert.Equal(t, root.SpanContext().TraceID(), server.SpanContext().TraceID())
+
+	// Parallel children all start with their parent, which ends after them.
+	for i := 0; i < 3; i++ {
+		db := byName[fmt.Sprintf("SELECT items %d", i)]
+		require.Len(t, db, 2, "SELECT items %d", i)
+		assert.Equal(t, trace.SpanKindClient, db[0].SpanKind())
+		system, _ := attributeValue(db[0].Attributes(), "db.system.name")
+		assert.Equal(t, "postgresql", system.AsString())
+		collection, _ := attributeValue(db[0].Attributes(), "db.collection.name")
+		assert.Equal(t, "items", collection.AsString())
+		service, _ = db[0].Resource().Set().Value("service.name")
+		assert.Equal(t, "cart", service.AsString())
+		assert.LessOrEqual(t, db[0].EndTime().Sub(db[0].StartTime()), 10*time.Millisecond)
+	}
+
+	for _, publish := range byName["orders publish"] {
+		assert.Equal(t, trace.SpanKindProducer, publish.SpanKind())
+		assert.Equal(t, codes.Error, publish.Status().Code)
+		errorType, _ := attributeValue(publish.Attributes(), "error.type")
+		assert.Equal(t, "timeout", errorType.AsString())
+		operation, _ := attributeValue(publish.Attributes(), "messaging.operation.type")
+		assert.Equal(t, "send", operation.AsString())
+		assert.Equal(t, trace.SpanKindServer, parentKind(syncer.spans, publish))
+	}
+
+	for _, span := range syncer.spans {
+		if !span.Parent().IsValid() {
+			continue
+		}
+		for _, parent := range syncer.spans {
+			if parent.SpanContext().SpanID() == span.Parent().SpanID() {
+				assert.False(t, span.StartTime().Before(parent.StartTime()), span.Name())
+				assert.False(t, span.EndTime().After(parent.EndTime()), span.Name())
+			}
+		}
+	}
+	// The uniform latency of the client span adds at least 1ms to the 5ms
+	// mean of the server span.
+	assert.Greater(t, client.EndTime().Sub(client.StartTime()), server.EndTime().Sub(server.StartTime()))
+}
+
+func parentKind(spans []sdktrace.ReadOnlySpan, span sdktrace.ReadOnlySpan) trace.SpanKind {
+	for _, parent := range spans {
+		if parent.SpanContext().SpanID() == span.Parent().SpanID() {
+			return parent.SpanKind()
+		}
+	}
+	return trace.SpanKindUnspecified
+}
+
+func TestScenarioSequentialChildren(t *testing.T) {
+	scn, err := LoadScenario(writeScenario(t, `
+root:
+  name: batch
+  service: worker
+  children:
+    - name: step
+      count: 3
+      latency:
+        value: 10ms
+`))
+	require.NoError(t, err)
+
+	syncer := &mockSyncer{}
+	replayer, err := newScenarioReplayer(scn, newScenarioTracers(t, scn, syncer))
+	require.NoError(t, err)
+	require.NoError(t, runWorkers(&Config{Config: config.Config{WorkerCount: 1}, NumTraces: 1}, zap.NewNop(), replayer))
+
+	require.Len(t, syncer.spans, 4)
+	steps := syncer.spans[:3]
+	for i := 1; i < len(steps); i++ {
+		assert.Equal(t, steps[i-1].EndTime(), steps[i].StartTime())
+	}
+	root := syncer.spans[3]
+	assert.Equal(t, trace.SpanKindInternal, root.SpanKind())
+	assert.Equal(t, 30*time.Millisecond, root.EndTime().Sub(root.StartTime()))
+}
+
+func TestLoadScenarioErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		errMsg  string
+	}{
+		{
+			name:    "unknown key",
+			content: "root:\n  name: a\n  service: s\n  unknown: 1\n",
+			errMsg:  "field unknown not found",
+		},
+		{
+			name:    "missing root service",
+			content: "root:\n  name: a\n",
+			errMsg:  "root: `service` must be set",
+		},
+		{
+			name:    "missing name",
+			content: "root:\n  name: a\n  service: s\n  children:\n    - kind: client\n",
+			errMsg:  "root.children[0]: `name` must be set",
+		},
+		{
+			name:    "unknown kind",
+			content: "root:\n  name: a\n  service: s\n  kind: remote\n",
+			errMsg:  "root: unknown `kind` \"remote\"",
+		},
+		{
+			name:    "invalid error probability",
+			content: "root:\n  name: a\n  service: s\n  error_probability: 2\n",
+			errMsg:  "root: `error_probability` must be between 0 and 1",
+		},
+		{
+			name:    "unknown distribution",
+			content: "root:\n  name: a\n  service: s\n  latency:\n    distribution: pareto\n",
+			errMsg:  "root: latency: unknown `distribution` \"pareto\"",
+		},
+		{
+			name:    "normal without mean",
+			content: "root:\n  name: a\n  service: s\n  latency:\n    distribution: normal\n",
+			errMsg:  "root: latency: `mean` must be set for the normal distribution",
+		},
+		{
+			name:    "invalid uniform bounds",
+			content: "root:\n  name: a\n  service: s\n  latency:\n    distribution: uniform\n    min: 2ms\n    max: 1ms\n",
+			errMsg:  "root: latency: `max` must be greater than or equal to `min`",
+		},
+		{
+			name:    "unknown messaging operation",
+			content: "root:\n  name: a\n  service: s\n  messaging:\n    system: kafka\n    operation: publish\n",
+			errMsg:  "root: messaging: unknown `operation` \"publish\"",
+		},
+		{
+			name:    "db and messaging",
+			content: "root:\n  name: a\n  service: s\n  db:\n    system: redis\n  messaging:\n    system: kafka\n    operation: send\n",
+			errMsg:  "root: `db` and `messaging` cannot both be set",
+		},
+		{
+			name:    "invalid template",
+			content: "root:\n  name: a\n  service: s\n  attributes:\n    k: \"{{ .Index \"\n",
+			errMsg:  "root: attribute \"k\": invalid template",
+		},
+		{
+			name:    "unsupported attribute",
+			content: "root:\n  name: a\n  service: s\n  attributes:\n    k:\n      nested: 1\n",
+			errMsg:  "root: attribute \"k\": unsupported value type",
+		},
+		{
+			name:    "root count",
+			content: "root:\n  name: a\n  service: s\n  count: 2\n",
+			errMsg:  "root: `count` must be 1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadScenario(writeScenario(t, tt.content))
+			assert.ErrorContains(t, err, tt.errMsg)
+		})
+	}
+
+	_, err := LoadScenario(filepath.Join("testdata", "missing.yaml"))
+	assert.ErrorContains(t, err, "failed to read scenario file")
+}
+
+func TestLatencySample(t *testing.T) {
+	assert.Equal(t, 5*time.Millisecond, (&Latency{Value: 5 * time.Millisecond}).sample())
+	for i := 0; i < 100; i++ {
+		d := (&Latency{Distribution: "uniform", Min: time.Millisecond, Max: 2 * time.Millisecond}).sample()
+		assert.GreaterOrEqual(t, d, time.Millisecond)
+		assert.LessOrEqual(t, d, 2*time.Millisecond)
+
+		d = (&Latency{Distribution: "normal", Mean: time.Millisecond, StdDev: 10 * time.Millisecond}).sample()
+		assert.GreaterOrEqual(t, d, time.Duration(0))
+
+		d = (&Latency{Distribution: "exponential", Mean: time.Millisecond, Max: 3 * time.Millisecond}).sample()
+		assert.GreaterOrEqual(t, d, time.Duration(0))
+		assert.LessOrEqual(t, d, 3*time.Millisecond)
+	}
+}
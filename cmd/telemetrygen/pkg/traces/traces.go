@@ -91,6 +91,14 @@ func Start(cfg *Config) error {
 	var attributes []attribute.KeyValue
 	attributes = append(attributes, cfg.GetAttributes()...)
 
+	if cfg.ScenarioFile != "" {
+		if err = startScenario(cfg, logger, ssp, attributes); err != nil {
+			logger.Error("failed to execute the test scenario.", zap.Error(err))
+			return err
+		}
+		return nil
+	}
+
 	tracerProvider := sdktrace.NewTracerProvider(
 		sdktrace.WithResource(resource.NewWithAttributes(conventions.SchemaURL, attributes...)),
 	)
@@ -107,8 +115,39 @@ func Start(cfg *Config) error {
 	return nil
 }
 
+// startScenario replays the scenario file, with one tracer provider per
+// service so that each service has its own resource.
+func startScenario(cfg *Config, logger *zap.Logger, ssp sdktrace.SpanProcessor, attributes []attribute.KeyValue) error {
+	scn, err := LoadScenario(cfg.ScenarioFile)
+	if err != nil {
+		return err
+	}
+	tracers := make(map[string]trace.Tracer)
+	for _, service := range scn.serviceNames() {
+		serviceAttributes, attrErr := scn.resourceAttributes(attributes, service)
+		if attrErr != nil {
+			return attrErr
+		}
+		tracerProvider := sdktrace.NewTracerProvider(
+			sdktrace.WithResource(resource.NewWithAttributes(conventions.SchemaURL, serviceAttributes...)),
+		)
+		tracerProvider.RegisterSpanProcessor(ssp)
+		tracers[service] = tracerProvider.Tracer("telemetrygen")
+	}
+	replayer, err := newScenarioReplayer(scn, func(service string) trace.Tracer { return tracers[service] })
+	if err != nil {
+		return err
+	}
+	return runWorkers(cfg, logger, replayer)
+}
+
 // run executes the test scenario.
 func run(c *Config, logger *zap.Logger) error {
+	return runWorkers(c, logger, nil)
+}
+
+// runWorkers starts the workers, which replay the scenario if set.
+func runWorkers(c *Config, logger *zap.Logger, scenario *scenarioReplayer) error {
 	if err := c.Validate(); err != nil {
 		return err
 	}
@@ -162,6 +201,7 @@ func run(c *Config, logger *zap.Logger) error {
 			allowFailures:    c.AllowExportFailures,
 			numSpanLinks:     c.NumSpanLinks,
 			spanContexts:     make([]trace.SpanContext, 0),
+			scenario:         scenario,
 		}
 
 		go w.simulateTraces(telemetryAttributes)
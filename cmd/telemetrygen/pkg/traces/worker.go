@@ -41,6 +41,7 @@ type worker struct {
 	allowFailures    bool                // whether to continue on export failures
 	spanContexts     []trace.SpanContext // collection of span contexts for linking
 	spanContextsMu   sync.RWMutex        // mutex for spanContexts slice
+	scenario         *scenarioReplayer   // replays a scenario instead of the flat topology, if set
 }
 
 const (
@@ -101,82 +102,91 @@ func (w *worker) simulateTraces(telemetryAttributes []attribute.KeyValue) {
 	var i int
 
 	for w.running.Load() {
-		spanStart := time.Now()
-		spanEnd := spanStart.Add(w.spanDuration)
+		if w.scenario != nil {
+			w.scenario.replay(w, limiter, i, telemetryAttributes)
+		} else {
+			w.simulateTrace(tracer, limiter, telemetryAttributes)
+		}
+
+		i++
+		if w.numTraces != 0 {
+			if i >= w.numTraces {
+				break
+			}
+		}
+	}
+	w.logger.Info("traces generated", zap.Int("traces", i))
+	w.wg.Done()
+}
+
+// simulateTrace generates a root span with numChildSpans children.
+func (w *worker) simulateTrace(tracer trace.Tracer, limiter *rate.Limiter, telemetryAttributes []attribute.KeyValue) {
+	spanStart := time.Now()
+	spanEnd := spanStart.Add(w.spanDuration)
+
+	if err := limiter.Wait(context.Background()); err != nil {
+		w.logger.Fatal("limiter waited failed, retry", zap.Error(err))
+	}
+
+	// Generate span links for the parent span
+	parentLinks := w.generateSpanLinks()
+
+	ctx, sp := tracer.Start(context.Background(), "lets-go", trace.WithAttributes(
+		conventions.NetworkPeerAddress(fakeIP),
+		conventions.ServicePeerName("telemetrygen-server"),
+	),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithTimestamp(spanStart),
+		trace.WithLinks(parentLinks...),
+	)
+	sp.SetAttributes(telemetryAttributes...)
+	for j := 0; j < w.loadSize; j++ {
+		sp.SetAttributes(config.CreateLoadAttribute(fmt.Sprintf("load-%v", j), 1))
+	}
+
+	// Store the parent span context for potential future linking
+	w.addSpanContext(sp.SpanContext())
 
+	childCtx := ctx
+	if w.propagateContext {
+		header := propagation.HeaderCarrier{}
+		// simulates going remote
+		otel.GetTextMapPropagator().Inject(childCtx, header)
+
+		// simulates getting a request from a client
+		childCtx = otel.GetTextMapPropagator().Extract(childCtx, header)
+	}
+	var endTimestamp trace.SpanEventOption
+
+	for j := 0; j < w.numChildSpans; j++ {
 		if err := limiter.Wait(context.Background()); err != nil {
 			w.logger.Fatal("limiter waited failed, retry", zap.Error(err))
 		}
 
-		// Generate span links for the parent span
-		parentLinks := w.generateSpanLinks()
+		// Generate span links for child spans
+		childLinks := w.generateSpanLinks()
 
-		ctx, sp := tracer.Start(context.Background(), "lets-go", trace.WithAttributes(
+		_, child := tracer.Start(childCtx, "okey-dokey-"+strconv.Itoa(j), trace.WithAttributes(
 			conventions.NetworkPeerAddress(fakeIP),
-			conventions.ServicePeerName("telemetrygen-server"),
+			conventions.ServicePeerName("telemetrygen-client"),
 		),
-			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithSpanKind(trace.SpanKindServer),
 			trace.WithTimestamp(spanStart),
-			trace.WithLinks(parentLinks...),
+			trace.WithLinks(childLinks...),
 		)
-		sp.SetAttributes(telemetryAttributes...)
-		for j := 0; j < w.loadSize; j++ {
-			sp.SetAttributes(config.CreateLoadAttribute(fmt.Sprintf("load-%v", j), 1))
-		}
-
-		// Store the parent span context for potential future linking
-		w.addSpanContext(sp.SpanContext())
+		child.SetAttributes(telemetryAttributes...)
 
-		childCtx := ctx
-		if w.propagateContext {
-			header := propagation.HeaderCarrier{}
-			// simulates going remote
-			otel.GetTextMapPropagator().Inject(childCtx, header)
-
-			// simulates getting a request from a client
-			childCtx = otel.GetTextMapPropagator().Extract(childCtx, header)
-		}
-		var endTimestamp trace.SpanEventOption
-
-		for j := 0; j < w.numChildSpans; j++ {
-			if err := limiter.Wait(context.Background()); err != nil {
-				w.logger.Fatal("limiter waited failed, retry", zap.Error(err))
-			}
+		// Store the child span context for potential future linking
+		w.addSpanContext(child.SpanContext())
 
-			// Generate span links for child spans
-			childLinks := w.generateSpanLinks()
-
-			_, child := tracer.Start(childCtx, "okey-dokey-"+strconv.Itoa(j), trace.WithAttributes(
-				conventions.NetworkPeerAddress(fakeIP),
-				conventions.ServicePeerName("telemetrygen-client"),
-			),
-				trace.WithSpanKind(trace.SpanKindServer),
-				trace.WithTimestamp(spanStart),
-				trace.WithLinks(childLinks...),
-			)
-			child.SetAttributes(telemetryAttributes...)
-
-			// Store the child span context for potential future linking
-			w.addSpanContext(child.SpanContext())
-
-			endTimestamp = trace.WithTimestamp(spanEnd)
-			child.SetStatus(w.statusCode, "")
-			child.End(endTimestamp)
-
-			// Reset the start and end for next span
-			spanStart = spanEnd
-			spanEnd = spanStart.Add(w.spanDuration)
-		}
-		sp.SetStatus(w.statusCode, "")
-		sp.End(endTimestamp)
+		endTimestamp = trace.WithTimestamp(spanEnd)
+		child.SetStatus(w.statusCode, "")
+		child.End(endTimestamp)
 
-		i++
-		if w.numTraces != 0 {
-			if i >= w.numTraces {
-				break
-			}
-		}
+		// Reset the start and end for next span
+		spanStart = spanEnd
+		spanEnd = spanStart.Add(w.spanDuration)
 	}
-	w.logger.Info("traces generated", zap.Int("traces", i))
-	w.wg.Done()
+	sp.SetStatus(w.statusCode, "")
+	sp.End(endTimestamp)
 }
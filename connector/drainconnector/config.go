@@ -0,0 +1,58 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package drainconnector // import "github.com/open-telemetry/opentelemetry-collector-contrib/connector/drainconnector"
+
+import (
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/drain"
+)
+
+var _ component.Config = (*Config)(nil)
+
+// Config defines configuration for the drain connector. It accepts every
+// option of the drain processor, plus the options of the template volume
+// metrics and new template events.
+type Config struct {
+	drain.Config `mapstructure:",squash"`
+
+	// TemplateMetrics configures the per-template log volume metrics emitted
+	// to metrics pipelines.
+	TemplateMetrics TemplateMetricsConfig `mapstructure:"template_metrics"`
+
+	// NewTemplateEvents, when true, emits an event log record to the logs
+	// pipelines each time a line creates a new template, so that sudden new
+	// log patterns can be alerted on downstream. The events are sent in a
+	// batch of their own, never added to the annotated records. Events are
+	// not emitted during warmup. Default: false.
+	NewTemplateEvents bool `mapstructure:"new_template_events"`
+}
+
+// TemplateMetricsConfig configures per-template log volume metrics.
+type TemplateMetricsConfig struct {
+	// MaxTemplates bounds the number of distinct template IDs reported. Once
+	// reached, records of further templates are reported under the
+	// "_overflow" template ID until a reported template expires. Must be > 0.
+	// Default: 1000.
+	MaxTemplates int `mapstructure:"max_templates"`
+
+	// Expiration is the time after which a template that has not been seen
+	// stops counting toward MaxTemplates. Must be > 0. Default: 1h.
+	Expiration time.Duration `mapstructure:"expiration"`
+}
+
+// Validate checks the connector specific options. The embedded processor
+// configuration is validated on its own.
+func (cfg *Config) Validate() error {
+	if cfg.TemplateMetrics.MaxTemplates <= 0 {
+		return fmt.Errorf("template_metrics.max_templates must be > 0, got %d", cfg.TemplateMetrics.MaxTemplates)
+	}
+	if cfg.TemplateMetrics.Expiration <= 0 {
+		return fmt.Errorf("template_metrics.expiration must be > 0, got %s", cfg.TemplateMetrics.Expiration)
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package drainconnector // import "github.com/open-telemetry/opentelemetry-collector-contrib/connector/drainconnector"
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/connector"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/drain"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/sharedcomponent"
+)
+
+// drainConnector annotates log records, forwards them and the new template
+// events to the logs pipelines, and emits the log volume per template to the
+// metrics pipelines of a connector.
+type drainConnector struct {
+	processor *drain.Processor
+	config    *Config
+	volume    *templateVolume
+	logs      consumer.Logs    // nil unless the connector feeds logs pipelines
+	metrics   consumer.Metrics // nil unless the connector feeds metrics pipelines
+}
+
+func newDrainConnector(set connector.Settings, cfg *Config) (*drainConnector, error) {
+	p, err := drain.NewProcessor(set.TelemetrySettings, component.KindConnector, set.ID, &cfg.Config)
+	if err != nil {
+		return nil, err
+	}
+	return &drainConnector{
+		processor: p,
+		config:    cfg,
+		volume:    newTemplateVolume(cfg.TemplateMetrics, time.Now()),
+	}, nil
+}
+
+func (c *drainConnector) Start(ctx context.Context, host component.Host) error {
+	return c.processor.Start(ctx, host)
+}
+
+// consumeLogs annotates ld, forwards it and the new template events to the
+// logs pipelines, and emits its volume per template to the metrics pipelines.
+func (c *drainConnector) consumeLogs(ctx context.Context, ld plog.Logs) error {
+	now := time.Now()
+	var volume *volumeBuilder
+	if c.metrics != nil {
+		volume = newVolumeBuilder(c.volume)
+	}
+	var events *eventsBuilder
+	if c.logs != nil && c.config.NewTemplateEvents {
+		events = newEventsBuilder(&c.config.Config)
+	}
+
+	var record func(pcommon.Resource, plog.LogRecord, drain.Annotation)
+	if volume != nil || events != nil {
+		record = func(resource pcommon.Resource, lr plog.LogRecord, a drain.Annotation) {
+			if volume != nil {
+				volume.record(resource, lr, a, now)
+			}
+			if events != nil && a.IsNew {
+				events.record(resource, a, now)
+			}
+		}
+	}
+	c.processor.AnnotateLogs(ctx, ld, record)
+
+	// The metrics are built before the records are forwarded, as they copy
+	// the resources of the records.
+	var md pmetric.Metrics
+	if volume != nil {
+		md = volume.build(time.Now())
+	}
+
+	var errs error
+	if c.logs != nil {
+		errs = c.logs.ConsumeLogs(ctx, ld)
+		if events != nil && events.events.ResourceLogs().Len() > 0 {
+			errs = errors.Join(errs, c.logs.ConsumeLogs(ctx, events.events))
+		}
+	}
+	if volume != nil && md.ResourceMetrics().Len() > 0 {
+		errs = errors.Join(errs, c.metrics.ConsumeMetrics(ctx, md))
+	}
+	return errs
+}
+
+func (c *drainConnector) Shutdown(ctx context.Context) error {
+	return c.processor.Shutdown(ctx)
+}
+
+// logsToLogsConnector is the logs to logs instance of a connector.
+type logsToLogsConnector struct {
+	*sharedcomponent.SharedComponent
+	connector *drainConnector
+}
+
+func (*logsToLogsConnector) Capabilities() consumer.Capabilities {
+	return consumer.Capabilities{MutatesData: true}
+}
+
+func (c *logsToLogsConnector) ConsumeLogs(ctx context.Context, ld plog.Logs) error {
+	return c.connector.consumeLogs(ctx, ld)
+}
+
+// logsToMetricsConnector is the logs to metrics instance of a connector. The
+// annotated records are dropped unless the connector feeds logs pipelines.
+type logsToMetricsConnector struct {
+	*sharedcomponent.SharedComponent
+	connector *drainConnector
+}
+
+func (*logsToMetricsConnector) Capabilities() consumer.Capabilities {
+	// Annotation edits the records in place.
+	return consumer.Capabilities{MutatesData: true}
+}
+
+func (c *logsToMetricsConnector) ConsumeLogs(ctx context.Context, ld plog.Logs) error {
+	// The logs to logs instance receives the same records, and emits the
+	// metrics while annotating them.
+	if c.connector.logs != nil {
+		return nil
+	}
+	return c.connector.consumeLogs(ctx, ld)
+}
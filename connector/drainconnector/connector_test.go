@@ -0,0 +1,303 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package drainconnector
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/connector"
+	"go.opentelemetry.io/collector/connector/connectortest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata/metricdatatest"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/connector/drainconnector/internal/metadata"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/drain"
+)
+
+func newTestConnectorConfig() *Config {
+	return createDefaultConfig().(*Config)
+}
+
+func newLogsToMetricsConnector(t *testing.T, cfg *Config) (connector.Logs, *consumertest.MetricsSink) {
+	t.Helper()
+	sink := new(consumertest.MetricsSink)
+	conn, err := NewFactory().CreateLogsToMetrics(t.Context(), connectortest.NewNopSettings(metadata.Type), cfg, sink)
+	require.NoError(t, err)
+	require.NoError(t, conn.Start(t.Context(), componenttest.NewNopHost()))
+	t.Cleanup(func() { require.NoError(t, conn.Shutdown(t.Context())) })
+	return conn, sink
+}
+
+func newLogsToLogsConnector(t *testing.T, cfg *Config) (connector.Logs, *consumertest.LogsSink) {
+	t.Helper()
+	sink := new(consumertest.LogsSink)
+	conn, err := NewFactory().CreateLogsToLogs(t.Context(), connectortest.NewNopSettings(metadata.Type), cfg, sink)
+	require.NoError(t, err)
+	require.NoError(t, conn.Start(t.Context(), componenttest.NewNopHost()))
+	t.Cleanup(func() { require.NoError(t, conn.Shutdown(t.Context())) })
+	return conn, sink
+}
+
+func makeLogRecord(body string) plog.Logs {
+	ld := plog.NewLogs()
+	lr := ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
+	lr.Body().SetStr(body)
+	lr.SetTimestamp(pcommon.NewTimestampFromTime(time.Now()))
+	return ld
+}
+
+func getFirstRecord(ld plog.Logs) plog.LogRecord {
+	return ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+}
+
+// templateAttr returns the log.record.template attribute value for the first
+// log record in ld, failing the test if it is absent.
+func templateAttr(t *testing.T, ld plog.Logs) string {
+	t.Helper()
+	v, ok := getFirstRecord(ld).Attributes().Get("log.record.template")
+	require.True(t, ok, "log.record.template attribute must be set")
+	return v.Str()
+}
+
+func makeSeverityLogRecord(body string, sev plog.SeverityNumber) plog.Logs {
+	ld := makeLogRecord(body)
+	getFirstRecord(ld).SetSeverityNumber(sev)
+	return ld
+}
+
+// volumeDataPoints returns the values of the data points of the named metric
+// by template ID and severity.
+func volumeDataPoints(t *testing.T, mds []pmetric.Metrics, name string) map[[2]string]int64 {
+	t.Helper()
+	values := map[[2]string]int64{}
+	for _, md := range mds {
+		for i := 0; i < md.ResourceMetrics().Len(); i++ {
+			metrics := md.ResourceMetrics().At(i).ScopeMetrics().At(0).Metrics()
+			for j := 0; j < metrics.Len(); j++ {
+				if metrics.At(j).Name() != name {
+					continue
+				}
+				sum := metrics.At(j).Sum()
+				assert.True(t, sum.IsMonotonic())
+				assert.Equal(t, pmetric.AggregationTemporalityDelta, sum.AggregationTemporality())
+				for k := 0; k < sum.DataPoints().Len(); k++ {
+					dp := sum.DataPoints().At(k)
+					id, _ := dp.Attributes().Get(templateIDKey)
+					severity, _ := dp.Attributes().Get(severityKey)
+					values[[2]string{id.Str(), severity.Str()}] += dp.IntValue()
+				}
+			}
+		}
+	}
+	return values
+}
+
+func TestConnectorConfigValidate(t *testing.T) {
+	cfg := newTestConnectorConfig()
+	assert.NoError(t, confmap.Validate(cfg))
+
+	cfg.TemplateMetrics.MaxTemplates = 0
+	assert.EqualError(t, confmap.Validate(cfg), "template_metrics.max_templates must be > 0, got 0")
+
+	cfg = newTestConnectorConfig()
+	cfg.TemplateMetrics.Expiration = 0
+	assert.EqualError(t, confmap.Validate(cfg), "template_metrics.expiration must be > 0, got 0s")
+
+	cfg = newTestConnectorConfig()
+	cfg.TreeDepth = 2
+	assert.ErrorContains(t, confmap.Validate(cfg), "tree_depth must be >= 3")
+}
+
+// TestConnectorTemplateMetrics verifies that records and bytes are counted
+// per resource, template ID and severity.
+func TestConnectorTemplateMetrics(t *testing.T) {
+	cfg := newTestConnectorConfig()
+	cfg.SeedTemplates = []string{"user <*> logged in", "disk <*> is full"}
+	conn, sink := newLogsToMetricsConnector(t, cfg)
+
+	for _, l := range []plog.Logs{
+		makeSeverityLogRecord("user alice logged in", plog.SeverityNumberInfo),
+		makeSeverityLogRecord("user bob logged in", plog.SeverityNumberInfo2),
+		makeSeverityLogRecord("user carol logged in", plog.SeverityNumberWarn),
+		makeSeverityLogRecord("disk sda is full", plog.SeverityNumberError),
+		makeLogRecord("disk sdb is full"),
+	} {
+		l.ResourceLogs().At(0).Resource().Attributes().PutStr("service.name", "auth")
+		require.NoError(t, conn.ConsumeLogs(t.Context(), l))
+	}
+
+	require.Len(t, sink.AllMetrics(), 5)
+	rm := sink.AllMetrics()[0].ResourceMetrics().At(0)
+	assert.Equal(t, map[string]any{"service.name": "auth"}, rm.Resource().Attributes().AsRaw())
+	assert.Equal(t, metadata.ScopeName, rm.ScopeMetrics().At(0).Scope().Name())
+
+	login, disk := drain.TemplateID("user <*> logged in"), drain.TemplateID("disk <*> is full")
+	assert.Equal(t, map[[2]string]int64{
+		{login, "info"}:       2,
+		{login, "warn"}:       1,
+		{disk, "error"}:       1,
+		{disk, "unspecified"}: 1,
+	}, volumeDataPoints(t, sink.AllMetrics(), templateLogRecordsMetric))
+	assert.Equal(t, map[[2]string]int64{
+		{login, "info"}:       int64(len("user alice logged in") + len("user bob logged in")),
+		{login, "warn"}:       int64(len("user carol logged in")),
+		{disk, "error"}:       int64(len("disk sda is full")),
+		{disk, "unspecified"}: int64(len("disk sdb is full")),
+	}, volumeDataPoints(t, sink.AllMetrics(), templateLogBytesMetric))
+}
+
+// TestConnectorTemplateMetricsContiguousIntervals verifies that each data
+// point of a stream starts where the previous one ended.
+func TestConnectorTemplateMetricsContiguousIntervals(t *testing.T) {
+	conn, sink := newLogsToMetricsConnector(t, newTestConnectorConfig())
+
+	require.NoError(t, conn.ConsumeLogs(t.Context(), makeLogRecord("user alice logged in")))
+	require.NoError(t, conn.ConsumeLogs(t.Context(), makeLogRecord("user alice logged in")))
+
+	require.Len(t, sink.AllMetrics(), 2)
+	var points []pmetric.NumberDataPoint
+	for _, md := range sink.AllMetrics() {
+		points = append(points, md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Sum().DataPoints().At(0))
+	}
+	assert.Less(t, points[0].StartTimestamp(), points[0].Timestamp())
+	assert.Equal(t, points[0].Timestamp(), points[1].StartTimestamp())
+	assert.Less(t, points[1].StartTimestamp(), points[1].Timestamp())
+}
+
+// TestConnectorTemplateMetricsOverflow verifies that template IDs beyond
+// max_templates are reported under a single overflow ID.
+func TestConnectorTemplateMetricsOverflow(t *testing.T) {
+	cfg := newTestConnectorConfig()
+	cfg.TemplateMetrics.MaxTemplates = 1
+	conn, sink := newLogsToMetricsConnector(t, cfg)
+
+	for _, line := range []string{"a b c", "d e f g", "h i j k l"} {
+		require.NoError(t, conn.ConsumeLogs(t.Context(), makeLogRecord(line)))
+	}
+
+	assert.Equal(t, map[[2]string]int64{
+		{drain.TemplateID("a b c"), "unspecified"}: 1,
+		{overflowTemplateID, "unspecified"}:        2,
+	}, volumeDataPoints(t, sink.AllMetrics(), templateLogRecordsMetric))
+}
+
+// TestConnectorSharesTree verifies that the logs to logs and logs to metrics
+// instances of a connector annotate every record once.
+func TestConnectorSharesTree(t *testing.T) {
+	testTel := componenttest.NewTelemetry()
+	defer func() { require.NoError(t, testTel.Shutdown(t.Context())) }()
+
+	factory := NewFactory()
+	cfg := newTestConnectorConfig()
+	set := connectortest.NewNopSettings(metadata.Type)
+	set.TelemetrySettings = testTel.NewTelemetrySettings()
+
+	logsSink := new(consumertest.LogsSink)
+	l2l, err := factory.CreateLogsToLogs(t.Context(), set, cfg, logsSink)
+	require.NoError(t, err)
+	metricsSink := new(consumertest.MetricsSink)
+	l2m, err := factory.CreateLogsToMetrics(t.Context(), set, cfg, metricsSink)
+	require.NoError(t, err)
+	require.NoError(t, l2l.Start(t.Context(), componenttest.NewNopHost()))
+	require.NoError(t, l2m.Start(t.Context(), componenttest.NewNopHost()))
+
+	// Both instances receive the records of the pipelines they are exporters of.
+	ld := makeLogRecord("user alice logged in")
+	other := plog.NewLogs()
+	ld.CopyTo(other)
+	require.NoError(t, l2l.ConsumeLogs(t.Context(), ld))
+	require.NoError(t, l2m.ConsumeLogs(t.Context(), other))
+
+	require.NoError(t, l2l.Shutdown(t.Context()))
+	require.NoError(t, l2m.Shutdown(t.Context()))
+
+	require.Len(t, logsSink.AllLogs(), 1)
+	assert.Equal(t, "user alice logged in", templateAttr(t, logsSink.AllLogs()[0]))
+	assert.Equal(t, map[[2]string]int64{
+		{drain.TemplateID("user alice logged in"), "unspecified"}: 1,
+	}, volumeDataPoints(t, metricsSink.AllMetrics(), templateLogRecordsMetric))
+	annotated, err := testTel.GetMetric("otelcol_processor_drain_log_records_annotated")
+	require.NoError(t, err)
+	metricdatatest.AssertAggregationsEqual(t, metricdata.Sum[int64]{
+		Temporality: metricdata.CumulativeTemporality,
+		IsMonotonic: true,
+		DataPoints:  []metricdata.DataPoint[int64]{{Value: 1}},
+	}, annotated.Data, metricdatatest.IgnoreTimestamp())
+}
+
+// TestConnectorNewTemplateEvents verifies that an event record is emitted in
+// a batch of its own, in the resource of the record that created a template,
+// and only for new templates.
+func TestConnectorNewTemplateEvents(t *testing.T) {
+	cfg := newTestConnectorConfig()
+	cfg.NewTemplateEvents = true
+	cfg.TemplateIDAttribute = "log.record.template.id"
+	cfg.SeedTemplates = []string{"user <*> logged in"}
+	conn, sink := newLogsToLogsConnector(t, cfg)
+
+	ld := makeLogRecord("user alice logged in")
+	ld.ResourceLogs().At(0).Resource().Attributes().PutStr("service.name", "disk")
+	lrs := ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	lrs.AppendEmpty().Body().SetStr("disk sda is full")
+	lrs.AppendEmpty().Body().SetStr("disk sdb is full")
+	rl := ld.ResourceLogs().AppendEmpty()
+	rl.ScopeLogs().AppendEmpty().LogRecords().AppendEmpty().Body().SetStr("user bob logged in")
+
+	require.NoError(t, conn.ConsumeLogs(t.Context(), ld))
+
+	require.Len(t, sink.AllLogs(), 2)
+	out := sink.AllLogs()[0]
+	assert.Equal(t, 4, out.LogRecordCount(), "the annotated records are forwarded unchanged")
+	assert.Equal(t, 1, out.ResourceLogs().At(0).ScopeLogs().Len())
+
+	events := sink.AllLogs()[1]
+	require.Equal(t, 1, events.ResourceLogs().Len())
+	assert.Equal(t, map[string]any{"service.name": "disk"}, events.ResourceLogs().At(0).Resource().Attributes().AsRaw())
+	sl := events.ResourceLogs().At(0).ScopeLogs().At(0)
+	assert.Equal(t, metadata.ScopeName, sl.Scope().Name())
+	require.Equal(t, 1, sl.LogRecords().Len(), "only the record creating the template emits an event")
+	event := sl.LogRecords().At(0)
+	assert.Equal(t, newTemplateEventName, event.EventName())
+	assert.Equal(t, plog.SeverityNumberInfo, event.SeverityNumber())
+	assert.NotZero(t, event.Timestamp())
+	assert.Equal(t, "new log template first seen: disk sda is full", event.Body().Str())
+	tmpl, _ := event.Attributes().Get("log.record.template")
+	assert.Equal(t, "disk sda is full", tmpl.Str())
+	id, _ := event.Attributes().Get("log.record.template.id")
+	assert.Equal(t, drain.TemplateID("disk sda is full"), id.Str())
+}
+
+// TestConnectorNewTemplateEventsDisabled verifies that no events are emitted
+// by default.
+func TestConnectorNewTemplateEventsDisabled(t *testing.T) {
+	conn, sink := newLogsToLogsConnector(t, newTestConnectorConfig())
+
+	require.NoError(t, conn.ConsumeLogs(t.Context(), makeLogRecord("disk sda is full")))
+	require.Len(t, sink.AllLogs(), 1)
+	assert.Equal(t, 1, sink.AllLogs()[0].LogRecordCount())
+}
+
+// TestConnectorNewTemplateEventsSuppressedDuringWarmup verifies that
+// templates created during warmup do not emit events.
+func TestConnectorNewTemplateEventsSuppressedDuringWarmup(t *testing.T) {
+	cfg := newTestConnectorConfig()
+	cfg.NewTemplateEvents = true
+	cfg.WarmupMinClusters = 2
+	conn, sink := newLogsToLogsConnector(t, cfg)
+
+	require.NoError(t, conn.ConsumeLogs(t.Context(), makeLogRecord("a b c")))
+	assert.Len(t, sink.AllLogs(), 1)
+
+	require.NoError(t, conn.ConsumeLogs(t.Context(), makeLogRecord("d e f g")))
+	assert.Len(t, sink.AllLogs(), 3, "the template completing warmup is reported")
+}
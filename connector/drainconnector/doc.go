@@ -0,0 +1,9 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:generate make mdatagen
+
+// Package drainconnector provides the Drain connector, which annotates log
+// records with templates like the Drain processor and emits the log volume per
+// template as metrics.
+package drainconnector // import "github.com/open-telemetry/opentelemetry-collector-contrib/connector/drainconnector"
@@ -0,0 +1,89 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package drainconnector // import "github.com/open-telemetry/opentelemetry-collector-contrib/connector/drainconnector"
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/connector"
+	"go.opentelemetry.io/collector/consumer"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/connector/drainconnector/internal/metadata"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/drain"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/sharedcomponent"
+)
+
+// connectors holds the state shared by the logs to logs and logs to metrics
+// instances of a connector, keyed by component ID, so that a connector
+// feeding both logs and metrics pipelines trains every record once.
+var connectors = sharedcomponent.NewSharedComponents()
+
+// NewFactory returns a new factory for the Drain connector. The logs to logs
+// and logs to metrics instances of a connector share its Drain tree, so that
+// every record is trained once.
+func NewFactory() connector.Factory {
+	return connector.NewFactory(
+		metadata.Type,
+		createDefaultConfig,
+		connector.WithLogsToLogs(createLogsToLogs, metadata.LogsToLogsStability),
+		connector.WithLogsToMetrics(createLogsToMetrics, metadata.LogsToMetricsStability),
+	)
+}
+
+func createDefaultConfig() component.Config {
+	return &Config{
+		Config: *drain.NewDefaultConfig(),
+		TemplateMetrics: TemplateMetricsConfig{
+			MaxTemplates: 1000,
+			Expiration:   time.Hour,
+		},
+	}
+}
+
+// createLogsToLogs creates the logs to logs instance of a connector, which
+// annotates records like the processor and forwards them, and the new
+// template events, to logs pipelines.
+func createLogsToLogs(
+	_ context.Context,
+	set connector.Settings,
+	cfg component.Config,
+	nextConsumer consumer.Logs,
+) (connector.Logs, error) {
+	shared, err := getOrAddConnector(set, cfg)
+	if err != nil {
+		return nil, err
+	}
+	c := shared.Unwrap().(*drainConnector)
+	c.logs = nextConsumer
+	return &logsToLogsConnector{SharedComponent: shared, connector: c}, nil
+}
+
+// createLogsToMetrics creates the logs to metrics instance of a connector,
+// which emits the log volume per template to metrics pipelines.
+func createLogsToMetrics(
+	_ context.Context,
+	set connector.Settings,
+	cfg component.Config,
+	nextConsumer consumer.Metrics,
+) (connector.Logs, error) {
+	shared, err := getOrAddConnector(set, cfg)
+	if err != nil {
+		return nil, err
+	}
+	c := shared.Unwrap().(*drainConnector)
+	c.metrics = nextConsumer
+	return &logsToMetricsConnector{SharedComponent: shared, connector: c}, nil
+}
+
+func getOrAddConnector(set connector.Settings, cfg component.Config) (*sharedcomponent.SharedComponent, error) {
+	var err error
+	shared := connectors.GetOrAdd(set.ID, func() component.Component {
+		var c *drainConnector
+		c, err = newDrainConnector(set, cfg.(*Config))
+		return c
+	})
+	return shared, err
+}
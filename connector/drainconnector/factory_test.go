@@ -0,0 +1,22 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package drainconnector
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/collector/component"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/connector/drainconnector/internal/metadata"
+)
+
+func TestNewFactory(t *testing.T) {
+	factory := NewFactory()
+	assert.Equal(t, metadata.Type, factory.Type())
+	assert.Equal(t, component.StabilityLevelDevelopment, factory.LogsToLogsStability())
+	assert.Equal(t, component.StabilityLevelDevelopment, factory.LogsToMetricsStability())
+	assert.Equal(t, component.StabilityLevelUndefined, factory.LogsToTracesStability())
+	assert.Equal(t, createDefaultConfig(), factory.CreateDefaultConfig())
+}
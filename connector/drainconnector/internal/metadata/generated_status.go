@@ -0,0 +1,19 @@
+// Code generated by mdatagen. DO NOT EDIT.
+
+// Package metadata contains the autogenerated telemetry and
+// build information for the connector/drain component.
+package metadata
+
+import (
+	"go.opentelemetry.io/collector/component"
+)
+
+var (
+	Type      = component.MustNewType("drain")
+	ScopeName = "github.com/open-telemetry/opentelemetry-collector-contrib/connector/drainconnector"
+)
+
+const (
+	LogsToLogsStability    = component.StabilityLevelDevelopment
+	LogsToMetricsStability = component.StabilityLevelDevelopment
+)
@@ -0,0 +1,296 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package drainconnector // import "github.com/open-telemetry/opentelemetry-collector-contrib/connector/drainconnector"
+
+import (
+	"sync"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/connector/drainconnector/internal/metadata"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/drain"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/pdatautil"
+)
+
+const (
+	// newTemplateEventName is the event name of the records emitted when
+	// new_template_events is enabled.
+	newTemplateEventName = "drain.template.first_seen"
+	// overflowTemplateID is the template ID that records are reported under
+	// once template_metrics.max_templates template IDs are reported.
+	overflowTemplateID = "_overflow"
+
+	templateLogRecordsMetric = "drain.template.log_records"
+	templateLogBytesMetric   = "drain.template.log_bytes"
+	templateIDKey            = "log.record.template.id"
+	severityKey              = "log.record.severity"
+)
+
+// severityLabel maps a severity number to the name of its range, as defined
+// by the logs data model.
+func severityLabel(sev plog.SeverityNumber) string {
+	switch {
+	case sev >= plog.SeverityNumberTrace && sev <= plog.SeverityNumberTrace4:
+		return "trace"
+	case sev >= plog.SeverityNumberDebug && sev <= plog.SeverityNumberDebug4:
+		return "debug"
+	case sev >= plog.SeverityNumberInfo && sev <= plog.SeverityNumberInfo4:
+		return "info"
+	case sev >= plog.SeverityNumberWarn && sev <= plog.SeverityNumberWarn4:
+		return "warn"
+	case sev >= plog.SeverityNumberError && sev <= plog.SeverityNumberError4:
+		return "error"
+	case sev >= plog.SeverityNumberFatal && sev <= plog.SeverityNumberFatal4:
+		return "fatal"
+	default:
+		return "unspecified"
+	}
+}
+
+// streamKey identifies a metric stream of a template.
+type streamKey struct {
+	resource [16]byte
+	severity string
+}
+
+// reportedTemplate holds the state of a template the volume is reported for.
+type reportedTemplate struct {
+	lastSeen time.Time
+	// lastEmits holds the end time of the last data point of every stream,
+	// which is the start time of the next one.
+	lastEmits map[streamKey]pcommon.Timestamp
+}
+
+func newReportedTemplate(now time.Time) *reportedTemplate {
+	return &reportedTemplate{lastSeen: now, lastEmits: make(map[streamKey]pcommon.Timestamp)}
+}
+
+// templateVolume holds the templates the volume is reported for, across the
+// batches of a connector, so that the delta data points of a stream cover
+// contiguous intervals.
+type templateVolume struct {
+	mu           sync.Mutex
+	maxTemplates int
+	expiration   time.Duration
+	start        pcommon.Timestamp
+	// evicted is the latest end time of the data points of the templates
+	// that expired. New streams start after it, so that a template reported
+	// again does not overlap the data points emitted before it expired.
+	evicted   pcommon.Timestamp
+	templates *lru.Cache[string, *reportedTemplate]
+	overflow  *reportedTemplate
+}
+
+func newTemplateVolume(cfg TemplateMetricsConfig, now time.Time) *templateVolume {
+	v := &templateVolume{
+		maxTemplates: cfg.MaxTemplates,
+		expiration:   cfg.Expiration,
+		start:        pcommon.NewTimestampFromTime(now),
+		overflow:     newReportedTemplate(now),
+	}
+	// The size is validated by Config.Validate.
+	v.templates, _ = lru.NewWithEvict(cfg.MaxTemplates, func(_ string, t *reportedTemplate) {
+		for _, lastEmit := range t.lastEmits {
+			v.evicted = max(v.evicted, lastEmit)
+		}
+	})
+	return v
+}
+
+// templateID returns the template ID to report id's volume under: id itself
+// when it is reported or a slot is available, or overflowTemplateID. The
+// least recently seen template gives up its slot once it has not been seen
+// for the expiration. Must be called with v.mu held.
+func (v *templateVolume) templateID(id string, now time.Time) string {
+	if t, ok := v.templates.Get(id); ok {
+		t.lastSeen = now
+		return id
+	}
+	if v.templates.Len() >= v.maxTemplates {
+		_, oldest, _ := v.templates.GetOldest()
+		if now.Sub(oldest.lastSeen) < v.expiration {
+			return overflowTemplateID
+		}
+		v.templates.RemoveOldest()
+	}
+	v.templates.Add(id, newReportedTemplate(now))
+	return id
+}
+
+// next returns the start time of the data point of the stream key of the
+// template id ending at now, and records now as the stream's last emit time.
+// Must be called with v.mu held.
+func (v *templateVolume) next(id string, key streamKey, now pcommon.Timestamp) pcommon.Timestamp {
+	t := v.overflow
+	if id != overflowTemplateID {
+		var ok bool
+		if t, ok = v.templates.Peek(id); !ok {
+			// The template expired since its records were counted.
+			return max(v.start, v.evicted)
+		}
+	}
+	start, ok := t.lastEmits[key]
+	if !ok {
+		start = max(v.start, v.evicted)
+	}
+	t.lastEmits[key] = now
+	return start
+}
+
+// volumeCounts holds the volume of a template and severity.
+type volumeCounts struct {
+	records int64
+	bytes   int64
+}
+
+type volumeKey struct {
+	id       string
+	severity string
+}
+
+// resourceVolume holds the volume of the records of a resource.
+type resourceVolume struct {
+	resource pcommon.Resource
+	key      [16]byte
+	counts   map[volumeKey]*volumeCounts
+	order    []volumeKey
+}
+
+// volumeBuilder counts the annotated records of a batch per resource,
+// template and severity, and builds the template volume metrics.
+type volumeBuilder struct {
+	volume    *templateVolume
+	resources map[[16]byte]*resourceVolume
+	order     []*resourceVolume
+	// lastResource and last cache the volume of the resource of the last
+	// record, as records come grouped by resource.
+	lastResource pcommon.Resource
+	last         *resourceVolume
+}
+
+func newVolumeBuilder(volume *templateVolume) *volumeBuilder {
+	return &volumeBuilder{volume: volume, resources: make(map[[16]byte]*resourceVolume)}
+}
+
+func (b *volumeBuilder) record(resource pcommon.Resource, lr plog.LogRecord, a drain.Annotation, now time.Time) {
+	b.volume.mu.Lock()
+	id := b.volume.templateID(a.ID, now)
+	b.volume.mu.Unlock()
+
+	rv := b.resourceVolume(resource)
+	key := volumeKey{id: id, severity: severityLabel(lr.SeverityNumber())}
+	counts, ok := rv.counts[key]
+	if !ok {
+		counts = &volumeCounts{}
+		rv.counts[key] = counts
+		rv.order = append(rv.order, key)
+	}
+	counts.records++
+	counts.bytes += int64(a.Size)
+}
+
+func (b *volumeBuilder) resourceVolume(resource pcommon.Resource) *resourceVolume {
+	if b.last != nil && b.lastResource == resource {
+		return b.last
+	}
+	key := pdatautil.MapHash(resource.Attributes())
+	rv, ok := b.resources[key]
+	if !ok {
+		rv = &resourceVolume{resource: resource, key: key, counts: make(map[volumeKey]*volumeCounts)}
+		b.resources[key] = rv
+		b.order = append(b.order, rv)
+	}
+	b.lastResource, b.last = resource, rv
+	return rv
+}
+
+// build returns the template volume metrics of the batch, as delta sums
+// ending at now.
+func (b *volumeBuilder) build(now time.Time) pmetric.Metrics {
+	md := pmetric.NewMetrics()
+	if len(b.order) == 0 {
+		return md
+	}
+	end := pcommon.NewTimestampFromTime(now)
+
+	b.volume.mu.Lock()
+	defer b.volume.mu.Unlock()
+	for _, rv := range b.order {
+		rm := md.ResourceMetrics().AppendEmpty()
+		rv.resource.CopyTo(rm.Resource())
+		sm := rm.ScopeMetrics().AppendEmpty()
+		sm.Scope().SetName(metadata.ScopeName)
+		records := newDeltaSum(sm.Metrics(), templateLogRecordsMetric, "Number of annotated log records per template and severity.", "{records}")
+		bytes := newDeltaSum(sm.Metrics(), templateLogBytesMetric, "Size of the text templated for annotated log records, per template and severity.", "By")
+		for _, key := range rv.order {
+			counts := rv.counts[key]
+			start := b.volume.next(key.id, streamKey{resource: rv.key, severity: key.severity}, end)
+			appendVolumeDataPoint(records, key, start, end, counts.records)
+			appendVolumeDataPoint(bytes, key, start, end, counts.bytes)
+		}
+	}
+	return md
+}
+
+func newDeltaSum(metrics pmetric.MetricSlice, name, description, unit string) pmetric.Sum {
+	m := metrics.AppendEmpty()
+	m.SetName(name)
+	m.SetDescription(description)
+	m.SetUnit(unit)
+	sum := m.SetEmptySum()
+	sum.SetIsMonotonic(true)
+	sum.SetAggregationTemporality(pmetric.AggregationTemporalityDelta)
+	return sum
+}
+
+func appendVolumeDataPoint(sum pmetric.Sum, key volumeKey, start, end pcommon.Timestamp, value int64) {
+	dp := sum.DataPoints().AppendEmpty()
+	dp.Attributes().PutStr(templateIDKey, key.id)
+	dp.Attributes().PutStr(severityKey, key.severity)
+	dp.SetStartTimestamp(start)
+	dp.SetTimestamp(end)
+	dp.SetIntValue(value)
+}
+
+// eventsBuilder builds the new template events of a batch, in a scope named
+// after the component, within a copy of the resource of the record that
+// created the template.
+type eventsBuilder struct {
+	config   *drain.Config
+	events   plog.Logs
+	resource pcommon.Resource
+	records  plog.LogRecordSlice
+}
+
+func newEventsBuilder(config *drain.Config) *eventsBuilder {
+	return &eventsBuilder{config: config, events: plog.NewLogs()}
+}
+
+func (b *eventsBuilder) record(resource pcommon.Resource, a drain.Annotation, now time.Time) {
+	if b.events.ResourceLogs().Len() == 0 || b.resource != resource {
+		rl := b.events.ResourceLogs().AppendEmpty()
+		resource.CopyTo(rl.Resource())
+		sl := rl.ScopeLogs().AppendEmpty()
+		sl.Scope().SetName(metadata.ScopeName)
+		b.resource = resource
+		b.records = sl.LogRecords()
+	}
+
+	ts := pcommon.NewTimestampFromTime(now)
+	lr := b.records.AppendEmpty()
+	lr.SetEventName(newTemplateEventName)
+	lr.SetTimestamp(ts)
+	lr.SetObservedTimestamp(ts)
+	lr.SetSeverityNumber(plog.SeverityNumberInfo)
+	lr.SetSeverityText("INFO")
+	lr.Body().SetStr("new log template first seen: " + a.Template)
+	lr.Attributes().PutStr(b.config.TemplateAttribute, a.Template)
+	if b.config.TemplateIDAttribute != "" {
+		lr.Attributes().PutStr(b.config.TemplateIDAttribute, a.ID)
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package drainconnector
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+// TestTemplateVolumeExpiresTemplates verifies that a template not seen for
+// the expiration gives up its slot to a new template.
+func TestTemplateVolumeExpiresTemplates(t *testing.T) {
+	now := time.Now()
+	v := newTemplateVolume(TemplateMetricsConfig{MaxTemplates: 2, Expiration: time.Minute}, now)
+
+	assert.Equal(t, "a", v.templateID("a", now))
+	assert.Equal(t, "b", v.templateID("b", now.Add(30*time.Second)))
+	assert.Equal(t, overflowTemplateID, v.templateID("c", now.Add(45*time.Second)))
+
+	// "a" has not been seen for a minute, "b" has.
+	assert.Equal(t, "c", v.templateID("c", now.Add(time.Minute)))
+	assert.Equal(t, "b", v.templateID("b", now.Add(time.Minute)))
+	assert.Equal(t, overflowTemplateID, v.templateID("a", now.Add(time.Minute)))
+}
+
+// TestTemplateVolumeStreamIntervals verifies that the intervals of a stream
+// are contiguous, and that a template reported again after it expired starts
+// after the data points emitted before.
+func TestTemplateVolumeStreamIntervals(t *testing.T) {
+	now := time.Now()
+	start := pcommon.NewTimestampFromTime(now)
+	v := newTemplateVolume(TemplateMetricsConfig{MaxTemplates: 1, Expiration: time.Minute}, now)
+	key := streamKey{severity: "info"}
+	at := func(d time.Duration) pcommon.Timestamp { return pcommon.NewTimestampFromTime(now.Add(d)) }
+
+	v.templateID("a", now)
+	assert.Equal(t, start, v.next("a", key, at(time.Second)))
+	assert.Equal(t, at(time.Second), v.next("a", key, at(2*time.Second)))
+	assert.Equal(t, start, v.next("a", streamKey{severity: "warn"}, at(2*time.Second)))
+
+	// "b" takes the slot of "a", and starts after the last data point of "a".
+	v.templateID("b", now.Add(2*time.Minute))
+	assert.Equal(t, at(2*time.Second), v.next("b", key, at(2*time.Minute)))
+	v.templateID("a", now.Add(4*time.Minute))
+	assert.Equal(t, at(2*time.Minute), v.next("a", key, at(4*time.Minute)))
+
+	// New streams start after the last data point of the expired templates.
+	assert.Equal(t, at(2*time.Minute), v.next(overflowTemplateID, key, at(4*time.Minute)))
+	assert.Equal(t, at(4*time.Minute), v.next(overflowTemplateID, key, at(5*time.Minute)))
+}
+
+func TestSeverityLabel(t *testing.T) {
+	for sev, want := range map[plog.SeverityNumber]string{
+		plog.SeverityNumberUnspecified: "unspecified",
+		plog.SeverityNumberTrace3:      "trace",
+		plog.SeverityNumberDebug:       "debug",
+		plog.SeverityNumberInfo4:       "info",
+		plog.SeverityNumberWarn2:       "warn",
+		plog.SeverityNumberError:       "error",
+		plog.SeverityNumberFatal4:      "fatal",
+		plog.SeverityNumber(42):        "unspecified",
+	} {
+		assert.Equal(t, want, severityLabel(sev), "severity number %d", sev)
+	}
+}
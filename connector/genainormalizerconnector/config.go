@@ -0,0 +1,60 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package genainormalizerconnector // import "github.com/open-telemetry/opentelemetry-collector-contrib/connector/genainormalizerconnector"
+
+import (
+	"fmt"
+
+	"github.com/lightstep/go-expohisto/structure"
+	"go.opentelemetry.io/collector/confmap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/genainormalizer"
+)
+
+// Config holds the configuration for the GenAI Normalizer connector, which
+// emits GenAI client metrics derived from spans.
+type Config struct {
+	_ struct{}
+
+	// Sources optionally normalizes spans before the metrics are derived,
+	// like the processor. When empty, spans are expected to follow the GenAI
+	// semantic conventions already, e.g. normalized by the processor
+	// upstream.
+	Sources []genainormalizer.Source `mapstructure:"sources"`
+
+	// ResourceAttributes lists the resource attributes copied to the
+	// resource of the emitted metrics. Metrics are aggregated per distinct
+	// set of values.
+	ResourceAttributes []string `mapstructure:"resource_attributes"`
+
+	// ExponentialHistogram configures the histograms of the emitted metrics.
+	ExponentialHistogram ExponentialHistogramConfig `mapstructure:"exponential_histogram"`
+}
+
+// ExponentialHistogramConfig configures exponential histograms.
+type ExponentialHistogramConfig struct {
+	_ struct{}
+
+	// MaxSize is the maximum number of buckets per positive or negative
+	// range.
+	MaxSize int32 `mapstructure:"max_size"`
+}
+
+var _ confmap.Validator = (*Config)(nil)
+
+// Validate checks that the connector configuration is valid.
+func (c *Config) Validate() error {
+	if err := genainormalizer.ValidateSources(c.Sources); err != nil {
+		return err
+	}
+	for i, key := range c.ResourceAttributes {
+		if key == "" {
+			return fmt.Errorf("resource_attributes[%d]: cannot be empty", i)
+		}
+	}
+	if c.ExponentialHistogram.MaxSize < structure.MinSize || c.ExponentialHistogram.MaxSize > structure.MaximumMaxSize {
+		return fmt.Errorf("exponential_histogram.max_size must be between %d and %d", structure.MinSize, structure.MaximumMaxSize)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package genainormalizerconnector // import "github.com/open-telemetry/opentelemetry-collector-contrib/connector/genainormalizerconnector"
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/genainormalizer"
+)
+
+// metricsConnector emits the GenAI client metrics of each batch of spans to
+// metrics pipelines. The spans are dropped.
+type metricsConnector struct {
+	component.StartFunc
+	component.ShutdownFunc
+
+	config     *Config
+	normalizer *genainormalizer.Normalizer // nil unless sources are configured
+	streams    *streamIntervals
+	next       consumer.Metrics
+}
+
+func (c *metricsConnector) Capabilities() consumer.Capabilities {
+	// Normalization edits the spans in place.
+	return consumer.Capabilities{MutatesData: c.normalizer != nil}
+}
+
+func (c *metricsConnector) ConsumeTraces(ctx context.Context, td ptrace.Traces) error {
+	if c.normalizer != nil {
+		var err error
+		if td, err = c.normalizer.ProcessTraces(ctx, td); err != nil {
+			return err
+		}
+	}
+	builder := newGenaiMetricsBuilder(c.config)
+	builder.addTraces(td)
+	md := builder.build(pcommon.NewTimestampFromTime(time.Now()), c.streams)
+	if md.ResourceMetrics().Len() == 0 {
+		return nil
+	}
+	return c.next.ConsumeMetrics(ctx, md)
+}
@@ -0,0 +1,379 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package genainormalizerconnector
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/connector/connectortest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/connector/genainormalizerconnector/internal/metadata"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/genainormalizer"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/genainormalizer/otelsemconv"
+)
+
+var testStart = pcommon.NewTimestampFromTime(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+
+// appendGenAISpan appends a span lasting the given duration, starting at
+// testStart plus offset.
+func appendGenAISpan(spans ptrace.SpanSlice, offset, duration time.Duration, attrs map[string]any) ptrace.Span {
+	span := spans.AppendEmpty()
+	span.SetName("chat")
+	span.SetStartTimestamp(testStart + pcommon.Timestamp(offset))
+	span.SetEndTimestamp(testStart + pcommon.Timestamp(offset+duration))
+	if err := span.Attributes().FromRaw(attrs); err != nil {
+		panic(err)
+	}
+	return span
+}
+
+func newGenAITraces(service string) (ptrace.Traces, ptrace.SpanSlice) {
+	td := ptrace.NewTraces()
+	rs := td.ResourceSpans().AppendEmpty()
+	rs.Resource().Attributes().PutStr("service.name", service)
+	rs.Resource().Attributes().PutStr("host.name", "h")
+	return td, rs.ScopeSpans().AppendEmpty().Spans()
+}
+
+func newTestConnector(t *testing.T, cfg *Config) (*consumertest.MetricsSink, func(ptrace.Traces)) {
+	t.Helper()
+	sink := new(consumertest.MetricsSink)
+	conn, err := NewFactory().CreateTracesToMetrics(t.Context(), connectortest.NewNopSettings(metadata.Type), cfg, sink)
+	require.NoError(t, err)
+	require.NoError(t, conn.Start(t.Context(), nil))
+	t.Cleanup(func() { require.NoError(t, conn.Shutdown(t.Context())) })
+	return sink, func(td ptrace.Traces) { require.NoError(t, conn.ConsumeTraces(t.Context(), td)) }
+}
+
+func findMetric(t *testing.T, sm pmetric.ScopeMetrics, name string) pmetric.Metric {
+	t.Helper()
+	for i := 0; i < sm.Metrics().Len(); i++ {
+		if sm.Metrics().At(i).Name() == name {
+			return sm.Metrics().At(i)
+		}
+	}
+	require.Failf(t, "metric not found", "metric %q not found", name)
+	return pmetric.Metric{}
+}
+
+// dataPointsByAttrs indexes the data points of an exponential histogram by
+// their attributes.
+func dataPointsByAttrs(metric pmetric.Metric) map[string]pmetric.ExponentialHistogramDataPoint {
+	out := make(map[string]pmetric.ExponentialHistogramDataPoint)
+	dps := metric.ExponentialHistogram().DataPoints()
+	for i := 0; i < dps.Len(); i++ {
+		dp := dps.At(i)
+		key := ""
+		for _, attr := range []string{
+			otelsemconv.GenAIOperationName, otelsemconv.GenAIProviderName, otelsemconv.GenAIRequestModel,
+			otelsemconv.GenAIResponseModel, otelsemconv.GenAITokenType, otelsemconv.ErrorType,
+		} {
+			if v, ok := dp.Attributes().Get(attr); ok {
+				key += attr + "=" + v.Str() + ";"
+			}
+		}
+		out[key] = dp
+	}
+	return out
+}
+
+func TestCreateDefaultConfig(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	assert.Equal(t, []string{"service.name"}, cfg.ResourceAttributes)
+	assert.Equal(t, int32(160), cfg.ExponentialHistogram.MaxSize)
+	assert.Empty(t, cfg.Sources)
+	assert.NoError(t, confmap.Validate(cfg))
+}
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*Config)
+		errMsg string
+	}{
+		{
+			name: "invalid source",
+			modify: func(cfg *Config) {
+				cfg.Sources = []genainormalizer.Source{{Name: "custom"}}
+			},
+			errMsg: `sources[0]: user-defined source "custom" requires non-empty "mappings" or "events"`,
+		},
+		{
+			name: "empty resource attribute",
+			modify: func(cfg *Config) {
+				cfg.ResourceAttributes = []string{"service.name", ""}
+			},
+			errMsg: "resource_attributes[1]: cannot be empty",
+		},
+		{
+			name: "max_size too small",
+			modify: func(cfg *Config) {
+				cfg.ExponentialHistogram.MaxSize = 1
+			},
+			errMsg: "exponential_histogram.max_size must be between 2 and 16384",
+		},
+		{
+			name: "max_size too large",
+			modify: func(cfg *Config) {
+				cfg.ExponentialHistogram.MaxSize = 16385
+			},
+			errMsg: "exponential_histogram.max_size must be between 2 and 16384",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := NewFactory().CreateDefaultConfig().(*Config)
+			tt.modify(cfg)
+			assert.ErrorContains(t, confmap.Validate(cfg), tt.errMsg)
+		})
+	}
+}
+
+func TestConnectorMetrics(t *testing.T) {
+	sink, consume := newTestConnector(t, NewFactory().CreateDefaultConfig().(*Config))
+
+	td, spans := newGenAITraces("chatbot")
+	appendGenAISpan(spans, 0, 2*time.Second, map[string]any{
+		otelsemconv.GenAIOperationName:            "chat",
+		otelsemconv.GenAIProviderName:             "openai",
+		otelsemconv.GenAIRequestModel:             "gpt-4o",
+		otelsemconv.GenAIResponseModel:            "gpt-4o-2024-08-06",
+		otelsemconv.GenAIUsageInputTokens:         int64(100),
+		otelsemconv.GenAIUsageOutputTokens:        int64(20),
+		otelsemconv.GenAIResponseTimeToFirstChunk: 0.25,
+	})
+	appendGenAISpan(spans, time.Second, 4*time.Second, map[string]any{
+		otelsemconv.GenAIOperationName:     "chat",
+		otelsemconv.GenAIProviderName:      "openai",
+		otelsemconv.GenAIRequestModel:      "gpt-4o",
+		otelsemconv.GenAIResponseModel:     "gpt-4o-2024-08-06",
+		otelsemconv.GenAIUsageInputTokens:  int64(300),
+		otelsemconv.GenAIUsageOutputTokens: float64(40),
+	})
+	// Not a GenAI span.
+	appendGenAISpan(spans, 0, time.Second, map[string]any{"http.request.method": "GET"})
+	consume(td)
+
+	require.Len(t, sink.AllMetrics(), 1)
+	md := sink.AllMetrics()[0]
+	require.Equal(t, 1, md.ResourceMetrics().Len())
+	rm := md.ResourceMetrics().At(0)
+	assert.Equal(t, map[string]any{"service.name": "chatbot"}, rm.Resource().Attributes().AsRaw())
+	require.Equal(t, 1, rm.ScopeMetrics().Len())
+	sm := rm.ScopeMetrics().At(0)
+	assert.Equal(t, metadata.ScopeName, sm.Scope().Name())
+	assert.Equal(t, otelsemconv.SchemaURL, sm.SchemaUrl())
+	assert.Equal(t, 3, sm.Metrics().Len())
+
+	base := "gen_ai.operation.name=chat;gen_ai.provider.name=openai;gen_ai.request.model=gpt-4o;gen_ai.response.model=gpt-4o-2024-08-06;"
+
+	usage := findMetric(t, sm, "gen_ai.client.token.usage")
+	assert.Equal(t, "{token}", usage.Unit())
+	assert.Equal(t, pmetric.AggregationTemporalityDelta, usage.ExponentialHistogram().AggregationTemporality())
+	usageDPs := dataPointsByAttrs(usage)
+	require.Len(t, usageDPs, 2)
+	input := usageDPs[base+"gen_ai.token.type=input;"]
+	assert.Equal(t, uint64(2), input.Count())
+	assert.Equal(t, 400.0, input.Sum())
+	assert.Equal(t, 100.0, input.Min())
+	assert.Equal(t, 300.0, input.Max())
+	assert.Less(t, input.StartTimestamp(), input.Timestamp())
+	output := usageDPs[base+"gen_ai.token.type=output;"]
+	assert.Equal(t, uint64(2), output.Count())
+	assert.Equal(t, 60.0, output.Sum())
+
+	duration := findMetric(t, sm, "gen_ai.client.operation.duration")
+	assert.Equal(t, "s", duration.Unit())
+	durationDPs := dataPointsByAttrs(duration)
+	require.Len(t, durationDPs, 1)
+	dp := durationDPs[base]
+	assert.Equal(t, uint64(2), dp.Count())
+	assert.Equal(t, 6.0, dp.Sum())
+	assert.Equal(t, 2.0, dp.Min())
+	assert.Equal(t, 4.0, dp.Max())
+	assert.Positive(t, dp.Positive().BucketCounts().Len())
+
+	ttfc := findMetric(t, sm, "gen_ai.client.operation.time_to_first_chunk")
+	ttfcDPs := dataPointsByAttrs(ttfc)
+	require.Len(t, ttfcDPs, 1)
+	assert.Equal(t, uint64(1), ttfcDPs[base].Count())
+	assert.Equal(t, 0.25, ttfcDPs[base].Sum())
+}
+
+func TestConnectorContiguousIntervals(t *testing.T) {
+	sink, consume := newTestConnector(t, createDefaultConfig().(*Config))
+
+	// The spans of both batches share their timestamps, the data points of
+	// the second batch must still start where the ones of the first batch
+	// ended.
+	for range 2 {
+		td, spans := newGenAITraces("chatbot")
+		appendGenAISpan(spans, 0, time.Second, map[string]any{
+			otelsemconv.GenAIOperationName:    "chat",
+			otelsemconv.GenAIUsageInputTokens: int64(10),
+		})
+		consume(td)
+	}
+	// A new stream starts when the connector was created.
+	td, spans := newGenAITraces("chatbot")
+	appendGenAISpan(spans, 0, time.Second, map[string]any{otelsemconv.GenAIOperationName: "embeddings"})
+	consume(td)
+
+	require.Len(t, sink.AllMetrics(), 3)
+	var points []pmetric.ExponentialHistogramDataPoint
+	for _, md := range sink.AllMetrics() {
+		sm := md.ResourceMetrics().At(0).ScopeMetrics().At(0)
+		points = append(points, findMetric(t, sm, "gen_ai.client.operation.duration").ExponentialHistogram().DataPoints().At(0))
+	}
+	assert.Less(t, points[0].StartTimestamp(), points[0].Timestamp())
+	assert.Equal(t, points[0].Timestamp(), points[1].StartTimestamp())
+	assert.Less(t, points[1].StartTimestamp(), points[1].Timestamp())
+	assert.Equal(t, points[0].StartTimestamp(), points[2].StartTimestamp())
+
+	sm := sink.AllMetrics()[1].ResourceMetrics().At(0).ScopeMetrics().At(0)
+	usage := findMetric(t, sm, "gen_ai.client.token.usage").ExponentialHistogram().DataPoints().At(0)
+	assert.Equal(t, points[0].Timestamp(), usage.StartTimestamp(), "every metric is a stream of its own")
+}
+
+func TestStreamIntervalsEviction(t *testing.T) {
+	start := pcommon.Timestamp(1)
+	s := newStreamIntervals(start)
+	key := streamKey{resource: "a", metric: "m"}
+
+	assert.Equal(t, start, s.next(key, 10))
+	assert.Equal(t, pcommon.Timestamp(10), s.next(key, 20))
+	for i := range maxTrackedStreams {
+		s.next(streamKey{resource: "b", metric: strconv.Itoa(i)}, 30)
+	}
+	// An evicted stream seen again starts after its past intervals.
+	assert.Equal(t, pcommon.Timestamp(20), s.next(key, 40))
+}
+
+func TestConnectorMetricsAttributes(t *testing.T) {
+	sink, consume := newTestConnector(t, NewFactory().CreateDefaultConfig().(*Config))
+
+	td, spans := newGenAITraces("chatbot")
+	// Deprecated gen_ai.system is used when gen_ai.provider.name is missing.
+	appendGenAISpan(spans, 0, time.Second, map[string]any{
+		otelsemconv.GenAIOperationName: "embeddings",
+		otelsemconv.GenAISystem:        "cohere",
+	})
+	failed := appendGenAISpan(spans, 0, time.Second, map[string]any{
+		otelsemconv.GenAIOperationName: "chat",
+		otelsemconv.GenAIProviderName:  "openai",
+		otelsemconv.ErrorType:          "timeout",
+	})
+	failed.Status().SetCode(ptrace.StatusCodeError)
+	failedNoType := appendGenAISpan(spans, 0, time.Second, map[string]any{
+		otelsemconv.GenAIOperationName: "chat",
+		otelsemconv.GenAIProviderName:  "openai",
+	})
+	failedNoType.Status().SetCode(ptrace.StatusCodeError)
+	consume(td)
+
+	require.Len(t, sink.AllMetrics(), 1)
+	sm := sink.AllMetrics()[0].ResourceMetrics().At(0).ScopeMetrics().At(0)
+	// No token usage or time to first chunk attributes.
+	assert.Equal(t, 1, sm.Metrics().Len())
+	durationDPs := dataPointsByAttrs(findMetric(t, sm, "gen_ai.client.operation.duration"))
+	assert.Len(t, durationDPs, 3)
+	assert.Contains(t, durationDPs, "gen_ai.operation.name=embeddings;gen_ai.provider.name=cohere;")
+	assert.Contains(t, durationDPs, "gen_ai.operation.name=chat;gen_ai.provider.name=openai;error.type=timeout;")
+	assert.Contains(t, durationDPs, "gen_ai.operation.name=chat;gen_ai.provider.name=openai;error.type=_OTHER;")
+	// The provider is also set under the deprecated gen_ai.system.
+	for _, dp := range durationDPs {
+		provider, ok := dp.Attributes().Get(otelsemconv.GenAIProviderName)
+		require.True(t, ok)
+		system, ok := dp.Attributes().Get(otelsemconv.GenAISystem)
+		require.True(t, ok)
+		assert.Equal(t, provider.Str(), system.Str())
+	}
+}
+
+func TestConnectorResourceAttributes(t *testing.T) {
+	cfg := NewFactory().CreateDefaultConfig().(*Config)
+	cfg.ResourceAttributes = []string{"service.name", "deployment.environment.name"}
+	sink, consume := newTestConnector(t, cfg)
+
+	td := ptrace.NewTraces()
+	for _, service := range []string{"a", "b", "a"} {
+		rs := td.ResourceSpans().AppendEmpty()
+		rs.Resource().Attributes().PutStr("service.name", service)
+		rs.Resource().Attributes().PutStr("host.name", "h-"+service)
+		appendGenAISpan(rs.ScopeSpans().AppendEmpty().Spans(), 0, time.Second, map[string]any{
+			otelsemconv.GenAIOperationName: "chat",
+		})
+	}
+	// Only non-GenAI spans: no resource metrics.
+	rs := td.ResourceSpans().AppendEmpty()
+	rs.Resource().Attributes().PutStr("service.name", "c")
+	appendGenAISpan(rs.ScopeSpans().AppendEmpty().Spans(), 0, time.Second, nil)
+	consume(td)
+
+	require.Len(t, sink.AllMetrics(), 1)
+	rms := sink.AllMetrics()[0].ResourceMetrics()
+	require.Equal(t, 2, rms.Len())
+	for i, service := range []string{"a", "b"} {
+		rm := rms.At(i)
+		assert.Equal(t, map[string]any{"service.name": service}, rm.Resource().Attributes().AsRaw())
+		dps := rm.ScopeMetrics().At(0).Metrics().At(0).ExponentialHistogram().DataPoints()
+		require.Equal(t, 1, dps.Len())
+		assert.Equal(t, uint64(2-i), dps.At(0).Count())
+	}
+}
+
+func TestConnectorNoGenAISpans(t *testing.T) {
+	sink, consume := newTestConnector(t, NewFactory().CreateDefaultConfig().(*Config))
+	td, spans := newGenAITraces("web")
+	appendGenAISpan(spans, 0, time.Second, map[string]any{"http.request.method": "GET"})
+	consume(td)
+	assert.Empty(t, sink.AllMetrics())
+}
+
+func TestConnectorSources(t *testing.T) {
+	cfg := NewFactory().CreateDefaultConfig().(*Config)
+	cfg.Sources = []genainormalizer.Source{{
+		Name: "custom",
+		Mappings: map[string]string{
+			"llm.operation":     otelsemconv.GenAIOperationName,
+			"llm.model":         otelsemconv.GenAIRequestModel,
+			"llm.tokens.prompt": otelsemconv.GenAIUsageInputTokens,
+		},
+	}}
+	sink := new(consumertest.MetricsSink)
+	conn, err := NewFactory().CreateTracesToMetrics(t.Context(), connectortest.NewNopSettings(metadata.Type), cfg, sink)
+	require.NoError(t, err)
+	assert.True(t, conn.Capabilities().MutatesData)
+
+	td, spans := newGenAITraces("chatbot")
+	appendGenAISpan(spans, 0, time.Second, map[string]any{
+		"llm.operation":     "chat",
+		"llm.model":         "mistral-large",
+		"llm.tokens.prompt": int64(12),
+	})
+	require.NoError(t, conn.ConsumeTraces(t.Context(), td))
+
+	require.Len(t, sink.AllMetrics(), 1)
+	sm := sink.AllMetrics()[0].ResourceMetrics().At(0).ScopeMetrics().At(0)
+	usageDPs := dataPointsByAttrs(findMetric(t, sm, "gen_ai.client.token.usage"))
+	dp, ok := usageDPs["gen_ai.operation.name=chat;gen_ai.request.model=mistral-large;gen_ai.token.type=input;"]
+	require.True(t, ok)
+	assert.Equal(t, 12.0, dp.Sum())
+}
+
+func TestConnectorWithoutSourcesDoesNotMutate(t *testing.T) {
+	sink := new(consumertest.MetricsSink)
+	conn, err := NewFactory().CreateTracesToMetrics(t.Context(), connectortest.NewNopSettings(metadata.Type), createDefaultConfig(), sink)
+	require.NoError(t, err)
+	assert.False(t, conn.Capabilities().MutatesData)
+}
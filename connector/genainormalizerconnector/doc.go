@@ -0,0 +1,9 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:generate make mdatagen
+
+// Package genainormalizerconnector provides the GenAI Normalizer connector,
+// which derives the GenAI client metrics from spans, optionally normalizing
+// them first like the GenAI Normalizer processor.
+package genainormalizerconnector // import "github.com/open-telemetry/opentelemetry-collector-contrib/connector/genainormalizerconnector"
@@ -0,0 +1,64 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package genainormalizerconnector // import "github.com/open-telemetry/opentelemetry-collector-contrib/connector/genainormalizerconnector"
+
+import (
+	"context"
+	"time"
+
+	"github.com/lightstep/go-expohisto/structure"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/connector"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/connector/genainormalizerconnector/internal/metadata"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/genainormalizer"
+)
+
+// NewFactory returns a new factory for the GenAI Normalizer connector. The
+// connector derives the GenAI client metrics (gen_ai.client.token.usage,
+// gen_ai.client.operation.duration and
+// gen_ai.client.operation.time_to_first_chunk) from spans.
+func NewFactory() connector.Factory {
+	return connector.NewFactory(
+		metadata.Type,
+		createDefaultConfig,
+		connector.WithTracesToMetrics(createTracesToMetrics, metadata.TracesToMetricsStability),
+	)
+}
+
+func createDefaultConfig() component.Config {
+	return &Config{
+		ResourceAttributes: []string{"service.name"},
+		ExponentialHistogram: ExponentialHistogramConfig{
+			MaxSize: structure.DefaultMaxSize,
+		},
+	}
+}
+
+// createTracesToMetrics creates the traces to metrics instance of a
+// connector, which derives the GenAI client metrics from spans, optionally
+// normalizing them first like the processor.
+func createTracesToMetrics(
+	_ context.Context,
+	_ connector.Settings,
+	cfg component.Config,
+	next consumer.Metrics,
+) (connector.Traces, error) {
+	c := cfg.(*Config)
+	conn := &metricsConnector{
+		config:  c,
+		next:    next,
+		streams: newStreamIntervals(pcommon.NewTimestampFromTime(time.Now())),
+	}
+	if len(c.Sources) > 0 {
+		normalizer, err := genainormalizer.NewNormalizer(&genainormalizer.Config{Sources: c.Sources})
+		if err != nil {
+			return nil, err
+		}
+		conn.normalizer = normalizer
+	}
+	return conn, nil
+}
@@ -0,0 +1,21 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package genainormalizerconnector
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/collector/component"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/connector/genainormalizerconnector/internal/metadata"
+)
+
+func TestNewFactory(t *testing.T) {
+	factory := NewFactory()
+	assert.Equal(t, metadata.Type, factory.Type())
+	assert.Equal(t, component.StabilityLevelDevelopment, factory.TracesToMetricsStability())
+	assert.Equal(t, component.StabilityLevelUndefined, factory.TracesToTracesStability())
+	assert.Equal(t, createDefaultConfig(), factory.CreateDefaultConfig())
+}
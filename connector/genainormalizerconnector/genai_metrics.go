@@ -0,0 +1,342 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package genainormalizerconnector // import "github.com/open-telemetry/opentelemetry-collector-contrib/connector/genainormalizerconnector"
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+	"github.com/lightstep/go-expohisto/structure"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/connector/genainormalizerconnector/internal/metadata"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/genainormalizer/otelsemconv"
+)
+
+// maxTrackedStreams bounds the streams whose last emit time is tracked.
+const maxTrackedStreams = 10000
+
+// dataPointKey identifies a data point by its attribute values. Empty values
+// are not set on the data point. extra holds gen_ai.token.type for token
+// usage and error.type for durations.
+type dataPointKey struct {
+	operation     string
+	provider      string
+	requestModel  string
+	responseModel string
+	extra         string
+}
+
+// streamKey identifies the data points of a metric, i.e. a stream, by the
+// key of their resource, the name of the metric and their attribute values.
+type streamKey struct {
+	resource string
+	metric   string
+	point    dataPointKey
+}
+
+// streamIntervals tracks the time the data points of every stream were last
+// emitted, so that the delta data points of a stream cover contiguous,
+// non-overlapping intervals across batches.
+type streamIntervals struct {
+	mu        sync.Mutex
+	start     pcommon.Timestamp
+	evicted   pcommon.Timestamp // the latest last emit time of the evicted streams
+	lastEmits *lru.Cache[streamKey, pcommon.Timestamp]
+}
+
+func newStreamIntervals(start pcommon.Timestamp) *streamIntervals {
+	s := &streamIntervals{start: start}
+	// The size is a positive constant, so creating the cache cannot fail.
+	s.lastEmits, _ = lru.NewWithEvict(maxTrackedStreams, func(_ streamKey, lastEmit pcommon.Timestamp) {
+		s.evicted = max(s.evicted, lastEmit)
+	})
+	return s
+}
+
+// next returns the start of the interval of the next data point of the stream
+// identified by key, which ends at now. A stream seen for the first time
+// starts when the connector was created, or when the last stream was evicted
+// so that an evicted stream seen again does not overlap its past intervals.
+// The caller must hold s.mu.
+func (s *streamIntervals) next(key streamKey, now pcommon.Timestamp) pcommon.Timestamp {
+	start, ok := s.lastEmits.Get(key)
+	if !ok {
+		start = max(s.start, s.evicted)
+	}
+	s.lastEmits.Add(key, now)
+	return start
+}
+
+// genaiMetric aggregates the data points of a single metric.
+type genaiMetric struct {
+	metric    otelsemconv.Metric
+	extraKey  string
+	maxSize   int32
+	histogram map[dataPointKey]*structure.Histogram[float64]
+}
+
+func newGenaiMetric(metric otelsemconv.Metric, extraKey string, maxSize int32) *genaiMetric {
+	return &genaiMetric{
+		metric:    metric,
+		extraKey:  extraKey,
+		maxSize:   maxSize,
+		histogram: make(map[dataPointKey]*structure.Histogram[float64]),
+	}
+}
+
+func (m *genaiMetric) record(key dataPointKey, value float64) {
+	h, ok := m.histogram[key]
+	if !ok {
+		h = new(structure.Histogram[float64])
+		h.Init(structure.NewConfig(structure.WithMaxSize(m.maxSize)))
+		m.histogram[key] = h
+	}
+	h.Update(value)
+}
+
+// appendTo appends the metric to metrics, with a data point per attribute
+// values ending at now. The caller must hold streams.mu.
+func (m *genaiMetric) appendTo(metrics pmetric.MetricSlice, resource string, now pcommon.Timestamp, streams *streamIntervals) {
+	if len(m.histogram) == 0 {
+		return
+	}
+	metric := metrics.AppendEmpty()
+	metric.SetName(m.metric.Name)
+	metric.SetUnit(m.metric.Unit)
+	metric.SetDescription(m.metric.Description)
+	hist := metric.SetEmptyExponentialHistogram()
+	hist.SetAggregationTemporality(pmetric.AggregationTemporalityDelta)
+	for key, h := range m.histogram {
+		dp := hist.DataPoints().AppendEmpty()
+		dp.SetStartTimestamp(streams.next(streamKey{resource: resource, metric: m.metric.Name, point: key}, now))
+		dp.SetTimestamp(now)
+		expoHistToExponentialDataPoint(h, dp)
+		putIfSet(dp.Attributes(), otelsemconv.GenAIOperationName, key.operation)
+		// The provider is set under both its current and deprecated names,
+		// so that dashboards keyed by gen_ai.system keep working.
+		putIfSet(dp.Attributes(), otelsemconv.GenAIProviderName, key.provider)
+		putIfSet(dp.Attributes(), otelsemconv.GenAISystem, key.provider)
+		putIfSet(dp.Attributes(), otelsemconv.GenAIRequestModel, key.requestModel)
+		putIfSet(dp.Attributes(), otelsemconv.GenAIResponseModel, key.responseModel)
+		putIfSet(dp.Attributes(), m.extraKey, key.extra)
+	}
+}
+
+// expoHistToExponentialDataPoint copies `lightstep/go-expohisto` structure.Histogram to
+// pmetric.ExponentialHistogramDataPoint
+func expoHistToExponentialDataPoint(agg *structure.Histogram[float64], dp pmetric.ExponentialHistogramDataPoint) {
+	dp.SetCount(agg.Count())
+	dp.SetSum(agg.Sum())
+	if agg.Count() != 0 {
+		dp.SetMin(agg.Min())
+		dp.SetMax(agg.Max())
+	}
+
+	dp.SetZeroCount(agg.ZeroCount())
+	dp.SetScale(agg.Scale())
+
+	for _, half := range []struct {
+		inFunc  func() *structure.Buckets
+		outFunc func() pmetric.ExponentialHistogramDataPointBuckets
+	}{
+		{agg.Positive, dp.Positive},
+		{agg.Negative, dp.Negative},
+	} {
+		in := half.inFunc()
+		out := half.outFunc()
+		out.SetOffset(in.Offset())
+		out.BucketCounts().EnsureCapacity(int(in.Len()))
+
+		for i := uint32(0); i < in.Len(); i++ {
+			out.BucketCounts().Append(in.At(i))
+		}
+	}
+}
+
+func putIfSet(attrs pcommon.Map, key, value string) {
+	if value != "" {
+		attrs.PutStr(key, value)
+	}
+}
+
+// resourceGenaiMetrics holds the metrics of the spans sharing the same
+// values of the configured resource attributes.
+type resourceGenaiMetrics struct {
+	resource    pcommon.Map
+	tokenUsage  *genaiMetric
+	duration    *genaiMetric
+	timeToChunk *genaiMetric
+}
+
+// genaiMetricsBuilder aggregates the GenAI client metrics of a batch of
+// spans. Spans without gen_ai.operation.name are ignored.
+type genaiMetricsBuilder struct {
+	config    *Config
+	resources map[string]*resourceGenaiMetrics
+	// order keeps the resources in the order they were first seen.
+	order []string
+}
+
+func newGenaiMetricsBuilder(config *Config) *genaiMetricsBuilder {
+	return &genaiMetricsBuilder{
+		config:    config,
+		resources: make(map[string]*resourceGenaiMetrics),
+	}
+}
+
+func (b *genaiMetricsBuilder) addTraces(td ptrace.Traces) {
+	rss := td.ResourceSpans()
+	for i := 0; i < rss.Len(); i++ {
+		rs := rss.At(i)
+		var rm *resourceGenaiMetrics
+		ilss := rs.ScopeSpans()
+		for j := 0; j < ilss.Len(); j++ {
+			spans := ilss.At(j).Spans()
+			for k := 0; k < spans.Len(); k++ {
+				span := spans.At(k)
+				attrs := span.Attributes()
+				operation := stringAttribute(attrs, otelsemconv.GenAIOperationName)
+				if operation == "" {
+					continue
+				}
+				if rm == nil {
+					rm = b.resourceMetrics(rs.Resource())
+				}
+				b.addSpan(rm, span, dataPointKey{
+					operation:     operation,
+					provider:      providerName(attrs),
+					requestModel:  stringAttribute(attrs, otelsemconv.GenAIRequestModel),
+					responseModel: stringAttribute(attrs, otelsemconv.GenAIResponseModel),
+				})
+			}
+		}
+	}
+}
+
+func (*genaiMetricsBuilder) addSpan(rm *resourceGenaiMetrics, span ptrace.Span, key dataPointKey) {
+	attrs := span.Attributes()
+	for _, usage := range []struct {
+		attribute string
+		tokenType string
+	}{
+		{otelsemconv.GenAIUsageInputTokens, otelsemconv.GenAITokenTypeInput},
+		{otelsemconv.GenAIUsageOutputTokens, otelsemconv.GenAITokenTypeOutput},
+	} {
+		if tokens, ok := numberAttribute(attrs, usage.attribute); ok {
+			usageKey := key
+			usageKey.extra = usage.tokenType
+			rm.tokenUsage.record(usageKey, tokens)
+		}
+	}
+
+	if span.EndTimestamp() >= span.StartTimestamp() {
+		durationKey := key
+		if span.Status().Code() == ptrace.StatusCodeError {
+			durationKey.extra = stringAttribute(attrs, otelsemconv.ErrorType)
+			if durationKey.extra == "" {
+				durationKey.extra = otelsemconv.ErrorTypeOther
+			}
+		}
+		duration := span.EndTimestamp().AsTime().Sub(span.StartTimestamp().AsTime())
+		rm.duration.record(durationKey, duration.Seconds())
+	}
+
+	if ttfc, ok := numberAttribute(attrs, otelsemconv.GenAIResponseTimeToFirstChunk); ok {
+		rm.timeToChunk.record(key, ttfc)
+	}
+}
+
+// resourceMetrics returns the metrics of the resource, keyed by the values
+// of the configured resource attributes.
+func (b *genaiMetricsBuilder) resourceMetrics(resource pcommon.Resource) *resourceGenaiMetrics {
+	var key strings.Builder
+	selected := pcommon.NewMap()
+	for _, attr := range b.config.ResourceAttributes {
+		v, ok := resource.Attributes().Get(attr)
+		if !ok {
+			key.WriteString("-;")
+			continue
+		}
+		value := v.AsString()
+		key.WriteString(strconv.Itoa(len(value)))
+		key.WriteByte(':')
+		key.WriteString(value)
+		v.CopyTo(selected.PutEmpty(attr))
+	}
+
+	rm, ok := b.resources[key.String()]
+	if !ok {
+		maxSize := b.config.ExponentialHistogram.MaxSize
+		rm = &resourceGenaiMetrics{
+			resource:    selected,
+			tokenUsage:  newGenaiMetric(otelsemconv.GenAIClientTokenUsage, otelsemconv.GenAITokenType, maxSize),
+			duration:    newGenaiMetric(otelsemconv.GenAIClientOperationDuration, otelsemconv.ErrorType, maxSize),
+			timeToChunk: newGenaiMetric(otelsemconv.GenAIClientOperationTimeToFirstChunk, "", maxSize),
+		}
+		b.resources[key.String()] = rm
+		b.order = append(b.order, key.String())
+	}
+	return rm
+}
+
+// build returns the accumulated metrics, with delta temporality. Each data
+// point covers the interval since the previous data point of its stream was
+// emitted, up to now.
+func (b *genaiMetricsBuilder) build(now pcommon.Timestamp, streams *streamIntervals) pmetric.Metrics {
+	streams.mu.Lock()
+	defer streams.mu.Unlock()
+
+	md := pmetric.NewMetrics()
+	for _, key := range b.order {
+		rm := b.resources[key]
+		out := md.ResourceMetrics().AppendEmpty()
+		rm.resource.CopyTo(out.Resource().Attributes())
+		sm := out.ScopeMetrics().AppendEmpty()
+		sm.Scope().SetName(metadata.ScopeName)
+		sm.SetSchemaUrl(otelsemconv.SchemaURL)
+		rm.tokenUsage.appendTo(sm.Metrics(), key, now, streams)
+		rm.duration.appendTo(sm.Metrics(), key, now, streams)
+		rm.timeToChunk.appendTo(sm.Metrics(), key, now, streams)
+	}
+	return md
+}
+
+// providerName returns gen_ai.provider.name, falling back to the deprecated
+// gen_ai.system.
+func providerName(attrs pcommon.Map) string {
+	if provider := stringAttribute(attrs, otelsemconv.GenAIProviderName); provider != "" {
+		return provider
+	}
+	return stringAttribute(attrs, otelsemconv.GenAISystem)
+}
+
+func stringAttribute(attrs pcommon.Map, key string) string {
+	if v, ok := attrs.Get(key); ok && v.Type() == pcommon.ValueTypeStr {
+		return v.Str()
+	}
+	return ""
+}
+
+// numberAttribute returns the value of a non-negative numeric attribute.
+func numberAttribute(attrs pcommon.Map, key string) (float64, bool) {
+	v, ok := attrs.Get(key)
+	if !ok {
+		return 0, false
+	}
+	var f float64
+	switch v.Type() {
+	case pcommon.ValueTypeInt:
+		f = float64(v.Int())
+	case pcommon.ValueTypeDouble:
+		f = v.Double()
+	default:
+		return 0, false
+	}
+	return f, f >= 0
+}
@@ -0,0 +1,18 @@
+// Code generated by mdatagen. DO NOT EDIT.
+
+// Package metadata contains the autogenerated telemetry and
+// build information for the connector/gen_ai_normalizer component.
+package metadata
+
+import (
+	"go.opentelemetry.io/collector/component"
+)
+
+var (
+	Type      = component.MustNewType("gen_ai_normalizer")
+	ScopeName = "github.com/open-telemetry/opentelemetry-collector-contrib/connector/genainormalizerconnector"
+)
+
+const (
+	TracesToMetricsStability = component.StabilityLevelDevelopment
+)
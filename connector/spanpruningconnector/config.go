@@ -0,0 +1,38 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package spanpruningconnector // import "github.com/open-telemetry/opentelemetry-collector-contrib/connector/spanpruningconnector"
+
+import (
+	"errors"
+	"strings"
+
+	"go.opentelemetry.io/collector/component"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/spanpruning"
+)
+
+var _ component.Config = (*Config)(nil)
+
+// Config defines the configuration options for the span pruning connector.
+// It accepts every processor option, plus the options of the aggregation
+// group metrics emitted to metrics pipelines.
+type Config struct {
+	spanpruning.Config `mapstructure:",squash"`
+
+	// Metrics configures the aggregation group metrics.
+	Metrics spanpruning.GroupMetricsConfig `mapstructure:"metrics"`
+}
+
+// Validate checks if the connector specific configuration is valid. The
+// embedded processor configuration is validated on its own.
+func (cfg *Config) Validate() error {
+	namespace := strings.TrimSpace(cfg.Metrics.Namespace)
+	if namespace == "" {
+		return errors.New("metrics.namespace cannot be empty")
+	}
+	if strings.ContainsAny(namespace, " \t\n\r") {
+		return errors.New("metrics.namespace cannot contain whitespace")
+	}
+	return nil
+}
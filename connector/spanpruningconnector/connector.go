@@ -0,0 +1,124 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package spanpruningconnector // import "github.com/open-telemetry/opentelemetry-collector-contrib/connector/spanpruningconnector"
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/connector/spanpruningconnector/internal/metadata"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/sharedcomponent"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/spanpruning"
+)
+
+// connectorCapabilities are the capabilities of both instances of a
+// connector: pruning edits the traces in place.
+var connectorCapabilities = consumer.Capabilities{MutatesData: true}
+
+// spanPruningConnector prunes traces, forwards them to the traces pipelines
+// and emits the metrics of their aggregation groups to the metrics pipelines
+// of a connector.
+type spanPruningConnector struct {
+	pruner       *spanpruning.Pruner
+	buffer       *spanpruning.TraceBuffer // nil unless the buffered mode is enabled
+	groupMetrics *spanpruning.GroupMetrics
+	traces       consumer.Traces  // nil unless the connector feeds traces pipelines
+	metrics      consumer.Metrics // nil unless the connector feeds metrics pipelines
+}
+
+func newSpanPruningConnector(set component.TelemetrySettings, cfg *Config) (*spanPruningConnector, error) {
+	p, err := spanpruning.NewPruner(set, &cfg.Config)
+	if err != nil {
+		return nil, err
+	}
+	c := &spanPruningConnector{
+		pruner:       p,
+		groupMetrics: spanpruning.NewGroupMetrics(cfg.Metrics, metadata.ScopeName),
+	}
+	if cfg.Buffer.Enabled {
+		c.buffer = p.NewBuffer(set.Logger, c.process)
+	}
+	return c, nil
+}
+
+func (c *spanPruningConnector) Start(ctx context.Context, host component.Host) error {
+	if c.buffer == nil {
+		return nil
+	}
+	return c.buffer.Start(ctx, host)
+}
+
+func (c *spanPruningConnector) consumeTraces(ctx context.Context, td ptrace.Traces) error {
+	if c.buffer != nil {
+		return c.buffer.Add(ctx, td)
+	}
+	return c.process(ctx, td)
+}
+
+// process prunes td, forwards it to the traces pipelines and emits the
+// metrics of its aggregation groups to the metrics pipelines.
+func (c *spanPruningConnector) process(ctx context.Context, td ptrace.Traces) error {
+	var groupMetrics *spanpruning.GroupMetrics
+	if c.metrics != nil {
+		groupMetrics = c.groupMetrics
+	}
+	td, md, err := c.pruner.Prune(ctx, td, groupMetrics)
+	if err != nil {
+		return err
+	}
+
+	var errs error
+	if c.traces != nil {
+		errs = c.traces.ConsumeTraces(ctx, td)
+	}
+	if md.ResourceMetrics().Len() > 0 {
+		errs = errors.Join(errs, c.metrics.ConsumeMetrics(ctx, md))
+	}
+	return errs
+}
+
+func (c *spanPruningConnector) Shutdown(ctx context.Context) error {
+	if c.buffer == nil {
+		return c.pruner.Shutdown(ctx)
+	}
+	return errors.Join(c.buffer.Shutdown(ctx), c.pruner.Shutdown(ctx))
+}
+
+// tracesToTracesConnector is the traces to traces instance of a connector.
+type tracesToTracesConnector struct {
+	*sharedcomponent.SharedComponent
+	connector *spanPruningConnector
+}
+
+func (*tracesToTracesConnector) Capabilities() consumer.Capabilities {
+	return connectorCapabilities
+}
+
+func (c *tracesToTracesConnector) ConsumeTraces(ctx context.Context, td ptrace.Traces) error {
+	return c.connector.consumeTraces(ctx, td)
+}
+
+// tracesToMetricsConnector is the traces to metrics instance of a connector.
+// The pruned traces are dropped unless the connector feeds traces pipelines.
+type tracesToMetricsConnector struct {
+	*sharedcomponent.SharedComponent
+	connector *spanPruningConnector
+}
+
+func (*tracesToMetricsConnector) Capabilities() consumer.Capabilities {
+	return connectorCapabilities
+}
+
+func (c *tracesToMetricsConnector) ConsumeTraces(ctx context.Context, td ptrace.Traces) error {
+	// The traces to traces instance receives the same traces, and emits the
+	// metrics while pruning them.
+	if c.connector.traces != nil {
+		return nil
+	}
+	return c.connector.consumeTraces(ctx, td)
+}
@@ -0,0 +1,425 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package spanpruningconnector
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/connector"
+	"go.opentelemetry.io/collector/connector/connectortest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata/metricdatatest"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/connector/spanpruningconnector/internal/metadata"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/spanpruning"
+)
+
+func TestCreateDefaultConfig(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	assert.Equal(t, *spanpruning.NewDefaultConfig(), cfg.Config)
+	assert.Equal(t, spanpruning.GroupMetricsConfig{Namespace: "span_pruning", Exemplars: true}, cfg.Metrics)
+	assert.NoError(t, confmap.Validate(cfg))
+}
+
+func TestConfigValidate(t *testing.T) {
+	cfg := NewFactory().CreateDefaultConfig().(*Config)
+	cfg.Metrics.Namespace = " "
+	assert.EqualError(t, confmap.Validate(cfg), "metrics.namespace cannot be empty")
+
+	cfg.Metrics.Namespace = "span pruning"
+	assert.EqualError(t, confmap.Validate(cfg), "metrics.namespace cannot contain whitespace")
+
+	cfg.Metrics.Namespace = "span_pruning"
+	cfg.MinSpansToAggregate = 1
+	assert.ErrorContains(t, confmap.Validate(cfg), "min_spans_to_aggregate must be at least 2")
+}
+
+func TestTracesToTracesConnector(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.MinSpansToAggregate = 2
+
+	sink := new(consumertest.TracesSink)
+	conn, err := factory.CreateTracesToTraces(t.Context(), connectortest.NewNopSettings(metadata.Type), cfg, sink)
+	require.NoError(t, err)
+	require.NoError(t, conn.Start(t.Context(), nil))
+	defer func() { require.NoError(t, conn.Shutdown(t.Context())) }()
+	assert.True(t, conn.Capabilities().MutatesData)
+
+	require.NoError(t, conn.ConsumeTraces(t.Context(), createTestTraceWithLeafSpans(t, 3, map[string]string{"db.operation": "select"})))
+	require.Len(t, sink.AllTraces(), 1)
+	assert.Equal(t, 2, countSpans(sink.AllTraces()[0]))
+}
+
+func findMetric(t *testing.T, sm pmetric.ScopeMetrics, name string) pmetric.Metric {
+	t.Helper()
+	for i := 0; i < sm.Metrics().Len(); i++ {
+		if sm.Metrics().At(i).Name() == name {
+			return sm.Metrics().At(i)
+		}
+	}
+	require.Failf(t, "metric not found", "metric %q not found", name)
+	return pmetric.Metric{}
+}
+
+func TestTracesToMetricsConnectorOutliers(t *testing.T) {
+	cfg := NewFactory().CreateDefaultConfig().(*Config)
+	cfg.GroupByAttributes = []string{"db.operation"}
+	cfg.EnableOutlierAnalysis = true
+	cfg.OutlierAnalysis.PreserveOutliers = true
+
+	sink := new(consumertest.MetricsSink)
+	conn, err := NewFactory().CreateTracesToMetrics(t.Context(), connectortest.NewNopSettings(metadata.Type), cfg, sink)
+	require.NoError(t, err)
+	require.NoError(t, conn.Start(t.Context(), nil))
+	defer func() { require.NoError(t, conn.Shutdown(t.Context())) }()
+
+	td := createTestTraceWithOutliers(t)
+	td.ResourceSpans().At(0).Resource().Attributes().PutStr("service.name", "checkout")
+	require.NoError(t, conn.ConsumeTraces(t.Context(), td))
+
+	require.Len(t, sink.AllMetrics(), 1)
+	md := sink.AllMetrics()[0]
+	require.Equal(t, 1, md.ResourceMetrics().Len())
+	rm := md.ResourceMetrics().At(0)
+	assert.Equal(t, map[string]any{"service.name": "checkout"}, rm.Resource().Attributes().AsRaw())
+	require.Equal(t, 1, rm.ScopeMetrics().Len())
+	sm := rm.ScopeMetrics().At(0)
+	assert.Equal(t, metadata.ScopeName, sm.Scope().Name())
+	assert.Equal(t, 4, sm.Metrics().Len())
+
+	expectedAttrs := map[string]any{
+		"span.name":    "SELECT",
+		"span.kind":    "SPAN_KIND_UNSPECIFIED",
+		"status.code":  "STATUS_CODE_UNSET",
+		"db.operation": "SELECT",
+	}
+
+	duration := findMetric(t, sm, "span_pruning.group.duration")
+	assert.Equal(t, "s", duration.Unit())
+	assert.Equal(t, pmetric.AggregationTemporalityDelta, duration.Histogram().AggregationTemporality())
+	require.Equal(t, 1, duration.Histogram().DataPoints().Len())
+	dp := duration.Histogram().DataPoints().At(0)
+	assert.Equal(t, expectedAttrs, dp.Attributes().AsRaw())
+	assert.Equal(t, uint64(10), dp.Count())
+	assert.InDelta(t, 1.168, dp.Sum(), 1e-9)
+	assert.InDelta(t, 0.005, dp.Min(), 1e-9)
+	assert.InDelta(t, 0.6, dp.Max(), 1e-9)
+	assert.Equal(t, []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}, dp.ExplicitBounds().AsRaw())
+	assert.Equal(t, []uint64{1, 5, 2, 0, 0, 0, 1, 1, 0, 0, 0, 0}, dp.BucketCounts().AsRaw())
+	assert.Less(t, dp.StartTimestamp(), dp.Timestamp())
+
+	require.Equal(t, 2, dp.Exemplars().Len())
+	spanIDs := []pcommon.SpanID{}
+	for i := 0; i < dp.Exemplars().Len(); i++ {
+		exemplar := dp.Exemplars().At(i)
+		spanIDs = append(spanIDs, exemplar.SpanID())
+		assert.Equal(t, pcommon.TraceID([16]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}), exemplar.TraceID())
+		assert.Equal(t, map[string]any{"cache_hit": "false"}, exemplar.FilteredAttributes().AsRaw())
+	}
+	assert.ElementsMatch(t, []pcommon.SpanID{{3, 0, 0, 0, 0, 0, 0, 0}, {3, 1, 0, 0, 0, 0, 0, 0}}, spanIDs)
+
+	for name, value := range map[string]int64{
+		"span_pruning.group.count":              1,
+		"span_pruning.group.spans":              8,
+		"span_pruning.group.outliers.preserved": 2,
+	} {
+		sum := findMetric(t, sm, name).Sum()
+		assert.True(t, sum.IsMonotonic())
+		assert.Equal(t, pmetric.AggregationTemporalityDelta, sum.AggregationTemporality())
+		require.Equal(t, 1, sum.DataPoints().Len())
+		assert.Equal(t, expectedAttrs, sum.DataPoints().At(0).Attributes().AsRaw())
+		assert.Equal(t, value, sum.DataPoints().At(0).IntValue(), name)
+	}
+}
+
+func TestTracesToMetricsConnectorMergesGroups(t *testing.T) {
+	cfg := NewFactory().CreateDefaultConfig().(*Config)
+	cfg.MinSpansToAggregate = 2
+	cfg.EnableAttributeLossAnalysis = true
+	cfg.Metrics.Namespace = "pruning"
+
+	sink := new(consumertest.MetricsSink)
+	conn, err := NewFactory().CreateTracesToMetrics(t.Context(), connectortest.NewNopSettings(metadata.Type), cfg, sink)
+	require.NoError(t, err)
+	require.NoError(t, conn.Start(t.Context(), nil))
+	defer func() { require.NoError(t, conn.Shutdown(t.Context())) }()
+
+	// Two traces with the same N+1 pattern merge into the same data points.
+	td := createTestTraceWithLeafSpans(t, 3, map[string]string{"db.operation": "select"})
+	other := createTestTraceWithLeafSpans(t, 4, map[string]string{"db.operation": "select"})
+	otherSpans := other.ResourceSpans().At(0).ScopeSpans().At(0).Spans()
+	for i := 0; i < otherSpans.Len(); i++ {
+		otherSpans.At(i).SetTraceID([16]byte{2})
+		otherSpans.At(i).Attributes().PutInt("db.rows", int64(i))
+	}
+	other.ResourceSpans().MoveAndAppendTo(td.ResourceSpans())
+	require.NoError(t, conn.ConsumeTraces(t.Context(), td))
+
+	require.Len(t, sink.AllMetrics(), 1)
+	md := sink.AllMetrics()[0]
+	require.Equal(t, 1, md.ResourceMetrics().Len())
+	sm := md.ResourceMetrics().At(0).ScopeMetrics().At(0)
+	assert.Equal(t, 4, sm.Metrics().Len())
+
+	dp := findMetric(t, sm, "pruning.group.duration").Histogram().DataPoints()
+	require.Equal(t, 1, dp.Len())
+	assert.Equal(t, uint64(7), dp.At(0).Count())
+	assert.Equal(t, 0, dp.At(0).Exemplars().Len())
+	assert.Equal(t, int64(2), findMetric(t, sm, "pruning.group.count").Sum().DataPoints().At(0).IntValue())
+	assert.Equal(t, int64(7), findMetric(t, sm, "pruning.group.spans").Sum().DataPoints().At(0).IntValue())
+
+	loss := findMetric(t, sm, "pruning.group.attribute_loss").Sum().DataPoints()
+	require.Equal(t, 1, loss.Len())
+	assert.Equal(t, map[string]any{
+		"span.name":      "SELECT",
+		"span.kind":      "SPAN_KIND_UNSPECIFIED",
+		"status.code":    "STATUS_CODE_UNSET",
+		"attribute.key":  "db.rows",
+		"attribute.loss": "diverse",
+	}, loss.At(0).Attributes().AsRaw())
+	assert.Equal(t, int64(3), loss.At(0).IntValue())
+}
+
+func TestTracesToMetricsConnectorNothingPruned(t *testing.T) {
+	sink := new(consumertest.MetricsSink)
+	conn, err := NewFactory().CreateTracesToMetrics(t.Context(), connectortest.NewNopSettings(metadata.Type), NewFactory().CreateDefaultConfig(), sink)
+	require.NoError(t, err)
+	require.NoError(t, conn.Start(t.Context(), nil))
+	defer func() { require.NoError(t, conn.Shutdown(t.Context())) }()
+
+	require.NoError(t, conn.ConsumeTraces(t.Context(), createTestTraceWithLeafSpans(t, 2, nil)))
+	assert.Empty(t, sink.AllMetrics())
+}
+
+func newTestConnectorSettings(tt *componenttest.Telemetry) connector.Settings {
+	set := connectortest.NewNopSettings(metadata.Type)
+	set.TelemetrySettings = tt.NewTelemetrySettings()
+	return set
+}
+
+func TestConnectorSharesPruningAcrossPipelines(t *testing.T) {
+	testTel := componenttest.NewTelemetry()
+	defer func() { require.NoError(t, testTel.Shutdown(t.Context())) }()
+
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.MinSpansToAggregate = 2
+	set := newTestConnectorSettings(testTel)
+
+	tracesSink := new(consumertest.TracesSink)
+	t2t, err := factory.CreateTracesToTraces(t.Context(), set, cfg, tracesSink)
+	require.NoError(t, err)
+	metricsSink := new(consumertest.MetricsSink)
+	t2m, err := factory.CreateTracesToMetrics(t.Context(), set, cfg, metricsSink)
+	require.NoError(t, err)
+	require.NoError(t, t2t.Start(t.Context(), nil))
+	require.NoError(t, t2m.Start(t.Context(), nil))
+
+	// Both instances receive the traces of the pipelines they are exporters of.
+	td := createTestTraceWithLeafSpans(t, 3, map[string]string{"db.operation": "select"})
+	other := ptrace.NewTraces()
+	td.CopyTo(other)
+	require.NoError(t, t2t.ConsumeTraces(t.Context(), td))
+	require.NoError(t, t2m.ConsumeTraces(t.Context(), other))
+
+	require.NoError(t, t2t.Shutdown(t.Context()))
+	require.NoError(t, t2m.Shutdown(t.Context()))
+
+	require.Len(t, tracesSink.AllTraces(), 1)
+	assert.Equal(t, 2, countSpans(tracesSink.AllTraces()[0]))
+	require.Len(t, metricsSink.AllMetrics(), 1)
+	sm := metricsSink.AllMetrics()[0].ResourceMetrics().At(0).ScopeMetrics().At(0)
+	assert.Equal(t, int64(1), findMetric(t, sm, "span_pruning.group.count").Sum().DataPoints().At(0).IntValue())
+	assertTracesProcessed(t, testTel, 1)
+}
+
+func TestTracesToMetricsConnectorReportsTelemetry(t *testing.T) {
+	testTel := componenttest.NewTelemetry()
+	defer func() { require.NoError(t, testTel.Shutdown(t.Context())) }()
+
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.MinSpansToAggregate = 2
+
+	sink := new(consumertest.MetricsSink)
+	conn, err := factory.CreateTracesToMetrics(t.Context(), newTestConnectorSettings(testTel), cfg, sink)
+	require.NoError(t, err)
+	require.NoError(t, conn.Start(t.Context(), nil))
+	defer func() { require.NoError(t, conn.Shutdown(t.Context())) }()
+
+	require.NoError(t, conn.ConsumeTraces(t.Context(), createTestTraceWithLeafSpans(t, 3, map[string]string{"db.operation": "select"})))
+	require.Len(t, sink.AllMetrics(), 1)
+	assertTracesProcessed(t, testTel, 1)
+}
+
+func TestTracesToMetricsConnectorContiguousIntervals(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.MinSpansToAggregate = 2
+
+	sink := new(consumertest.MetricsSink)
+	conn, err := factory.CreateTracesToMetrics(t.Context(), connectortest.NewNopSettings(metadata.Type), cfg, sink)
+	require.NoError(t, err)
+	require.NoError(t, conn.Start(t.Context(), nil))
+	defer func() { require.NoError(t, conn.Shutdown(t.Context())) }()
+
+	// The spans of both traces share their timestamps, the data points of the
+	// second batch must still start where the ones of the first batch ended.
+	require.NoError(t, conn.ConsumeTraces(t.Context(), createTestTraceWithLeafSpans(t, 3, map[string]string{"db.operation": "select"})))
+	require.NoError(t, conn.ConsumeTraces(t.Context(), createTestTraceWithLeafSpans(t, 3, map[string]string{"db.operation": "select"})))
+
+	require.Len(t, sink.AllMetrics(), 2)
+	var points []pmetric.NumberDataPoint
+	for _, md := range sink.AllMetrics() {
+		sm := md.ResourceMetrics().At(0).ScopeMetrics().At(0)
+		points = append(points, findMetric(t, sm, "span_pruning.group.count").Sum().DataPoints().At(0))
+	}
+	assert.Less(t, points[0].StartTimestamp(), points[0].Timestamp())
+	assert.Equal(t, points[0].Timestamp(), points[1].StartTimestamp())
+	assert.Less(t, points[1].StartTimestamp(), points[1].Timestamp())
+}
+
+func TestBufferedConnector(t *testing.T) {
+	cfg := NewFactory().CreateDefaultConfig().(*Config)
+	cfg.Buffer.Enabled = true
+	cfg.Buffer.WaitDuration = time.Hour
+
+	sink := new(consumertest.MetricsSink)
+	conn, err := NewFactory().CreateTracesToMetrics(t.Context(), connectortest.NewNopSettings(metadata.Type), cfg, sink)
+	require.NoError(t, err)
+	require.NoError(t, conn.Start(t.Context(), componenttest.NewNopHost()))
+
+	first := createTestTraceWithLeafSpans(t, 3, map[string]string{"db.operation": "select"})
+	traceID := first.ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0).TraceID()
+	require.NoError(t, conn.ConsumeTraces(t.Context(), first))
+	require.NoError(t, conn.ConsumeTraces(t.Context(), createTestLeafSpanBatch(t, traceID, 1, 3)))
+	assert.Empty(t, sink.AllMetrics())
+
+	require.NoError(t, conn.Shutdown(t.Context()))
+	require.Len(t, sink.AllMetrics(), 1)
+	sm := sink.AllMetrics()[0].ResourceMetrics().At(0).ScopeMetrics().At(0)
+	assert.Equal(t, int64(6), findMetric(t, sm, "span_pruning.group.spans").Sum().DataPoints().At(0).IntValue())
+}
+
+// assertTracesProcessed checks the traces processed telemetry the pruning
+// logic reports, under the name it shares with the processor.
+func assertTracesProcessed(t *testing.T, tt *componenttest.Telemetry, value int64) {
+	t.Helper()
+	processed, err := tt.GetMetric("otelcol_processor_spanpruning_traces_processed")
+	require.NoError(t, err)
+	metricdatatest.AssertAggregationsEqual(t, metricdata.Sum[int64]{
+		Temporality: metricdata.CumulativeTemporality,
+		IsMonotonic: true,
+		DataPoints:  []metricdata.DataPoint[int64]{{Value: value}},
+	}, processed.Data, metricdatatest.IgnoreTimestamp())
+}
+
+func createTestTraceWithLeafSpans(t *testing.T, numLeafSpans int, attrs map[string]string) ptrace.Traces {
+	t.Helper()
+	td := ptrace.NewTraces()
+	ss := td.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty()
+
+	traceID := pcommon.TraceID([16]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16})
+	parentSpanID := pcommon.SpanID([8]byte{1, 0, 0, 0, 0, 0, 0, 0})
+
+	parentSpan := ss.Spans().AppendEmpty()
+	parentSpan.SetTraceID(traceID)
+	parentSpan.SetSpanID(parentSpanID)
+	parentSpan.SetName("parent")
+
+	for i := range numLeafSpans {
+		span := ss.Spans().AppendEmpty()
+		span.SetTraceID(traceID)
+		span.SetSpanID(pcommon.SpanID([8]byte{2, byte(i), 0, 0, 0, 0, 0, 0}))
+		span.SetParentSpanID(parentSpanID)
+		span.SetName("SELECT")
+		span.SetStartTimestamp(pcommon.Timestamp(1000000000 + int64(i)*100))
+		span.SetEndTimestamp(pcommon.Timestamp(1000000100 + int64(i)*100))
+		for k, v := range attrs {
+			span.Attributes().PutStr(k, v)
+		}
+	}
+
+	return td
+}
+
+// createTestLeafSpanBatch returns a batch holding only leaf spans of the
+// trace created by createTestTraceWithLeafSpans, as a later batch of the same
+// trace would.
+func createTestLeafSpanBatch(t *testing.T, traceID pcommon.TraceID, batch byte, numLeafSpans int) ptrace.Traces {
+	t.Helper()
+	td := ptrace.NewTraces()
+	ss := td.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty()
+	for i := range numLeafSpans {
+		span := ss.Spans().AppendEmpty()
+		span.SetTraceID(traceID)
+		span.SetSpanID(pcommon.SpanID([8]byte{3, batch, byte(i), 0, 0, 0, 0, 0}))
+		span.SetParentSpanID(pcommon.SpanID([8]byte{1, 0, 0, 0, 0, 0, 0, 0}))
+		span.SetName("SELECT")
+		span.SetStartTimestamp(pcommon.Timestamp(1000000000 + int64(i)*100))
+		span.SetEndTimestamp(pcommon.Timestamp(1000000100 + int64(i)*100))
+		span.Attributes().PutStr("db.operation", "select")
+	}
+	return td
+}
+
+// createTestTraceWithOutliers returns a trace with eight fast and two slow
+// SELECT spans, the slow ones differing by their cache_hit attribute.
+func createTestTraceWithOutliers(t *testing.T) ptrace.Traces {
+	t.Helper()
+	td := ptrace.NewTraces()
+	ss := td.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty()
+
+	traceID := pcommon.TraceID([16]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16})
+	parentSpanID := pcommon.SpanID([8]byte{1, 0, 0, 0, 0, 0, 0, 0})
+
+	parentSpan := ss.Spans().AppendEmpty()
+	parentSpan.SetTraceID(traceID)
+	parentSpan.SetSpanID(parentSpanID)
+	parentSpan.SetName("handler")
+	parentSpan.SetStartTimestamp(pcommon.Timestamp(1000000000))
+	parentSpan.SetEndTimestamp(pcommon.Timestamp(1001000000))
+
+	baseTime := int64(1000000000)
+	ms := int64(1000000)
+	addSpans := func(prefix byte, durations []int64, cacheHit string) {
+		for i, dur := range durations {
+			span := ss.Spans().AppendEmpty()
+			span.SetTraceID(traceID)
+			span.SetSpanID(pcommon.SpanID([8]byte{prefix, byte(i), 0, 0, 0, 0, 0, 0}))
+			span.SetParentSpanID(parentSpanID)
+			span.SetName("SELECT")
+			span.SetStartTimestamp(pcommon.Timestamp(baseTime))
+			span.SetEndTimestamp(pcommon.Timestamp(baseTime + dur*ms))
+			span.Attributes().PutStr("db.operation", "SELECT")
+			span.Attributes().PutStr("cache_hit", cacheHit)
+		}
+	}
+	addSpans(2, []int64{5, 6, 7, 8, 9, 10, 11, 12}, "true")
+	addSpans(3, []int64{500, 600}, "false")
+
+	return td
+}
+
+func countSpans(td ptrace.Traces) int {
+	count := 0
+	rss := td.ResourceSpans()
+	for i := 0; i < rss.Len(); i++ {
+		ilss := rss.At(i).ScopeSpans()
+		for j := 0; j < ilss.Len(); j++ {
+			count += ilss.At(j).Spans().Len()
+		}
+	}
+	return count
+}
@@ -0,0 +1,9 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:generate make mdatagen
+
+// Package spanpruningconnector provides the Span Pruning connector, which
+// prunes traces like the Span Pruning processor and emits metrics about the
+// aggregation groups, so that the pruned patterns remain observable.
+package spanpruningconnector // import "github.com/open-telemetry/opentelemetry-collector-contrib/connector/spanpruningconnector"
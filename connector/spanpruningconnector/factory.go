@@ -0,0 +1,90 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package spanpruningconnector // import "github.com/open-telemetry/opentelemetry-collector-contrib/connector/spanpruningconnector"
+
+import (
+	"context"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/connector"
+	"go.opentelemetry.io/collector/consumer"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/connector/spanpruningconnector/internal/metadata"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/sharedcomponent"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/spanpruning"
+)
+
+// connectors holds the state shared by the traces to traces and traces to
+// metrics instances of a connector, keyed by component ID, so that a
+// connector feeding both traces and metrics pipelines prunes and buffers
+// every trace once.
+var connectors = sharedcomponent.NewSharedComponents()
+
+// NewFactory returns a new factory for the Span Pruning connector. The
+// traces to traces and traces to metrics instances of a connector share its
+// pruning state, so that every trace is pruned once.
+func NewFactory() connector.Factory {
+	return connector.NewFactory(
+		metadata.Type,
+		createDefaultConfig,
+		connector.WithTracesToTraces(createTracesToTraces, metadata.TracesToTracesStability),
+		connector.WithTracesToMetrics(createTracesToMetrics, metadata.TracesToMetricsStability),
+	)
+}
+
+func createDefaultConfig() component.Config {
+	return &Config{
+		Config: *spanpruning.NewDefaultConfig(),
+		Metrics: spanpruning.GroupMetricsConfig{
+			Namespace: "span_pruning",
+			Exemplars: true,
+		},
+	}
+}
+
+// createTracesToTraces creates the traces to traces instance of a connector,
+// which prunes traces like the processor and forwards them to traces
+// pipelines.
+func createTracesToTraces(
+	_ context.Context,
+	set connector.Settings,
+	cfg component.Config,
+	nextConsumer consumer.Traces,
+) (connector.Traces, error) {
+	shared, err := getOrAddConnector(set, cfg)
+	if err != nil {
+		return nil, err
+	}
+	c := shared.Unwrap().(*spanPruningConnector)
+	c.traces = nextConsumer
+	return &tracesToTracesConnector{SharedComponent: shared, connector: c}, nil
+}
+
+// createTracesToMetrics creates the traces to metrics instance of a
+// connector, which emits metrics about the aggregation groups of the pruned
+// traces to metrics pipelines.
+func createTracesToMetrics(
+	_ context.Context,
+	set connector.Settings,
+	cfg component.Config,
+	nextConsumer consumer.Metrics,
+) (connector.Traces, error) {
+	shared, err := getOrAddConnector(set, cfg)
+	if err != nil {
+		return nil, err
+	}
+	c := shared.Unwrap().(*spanPruningConnector)
+	c.metrics = nextConsumer
+	return &tracesToMetricsConnector{SharedComponent: shared, connector: c}, nil
+}
+
+func getOrAddConnector(set connector.Settings, cfg component.Config) (*sharedcomponent.SharedComponent, error) {
+	var err error
+	shared := connectors.GetOrAdd(set.ID, func() component.Component {
+		var c *spanPruningConnector
+		c, err = newSpanPruningConnector(set.TelemetrySettings, cfg.(*Config))
+		return c
+	})
+	return shared, err
+}
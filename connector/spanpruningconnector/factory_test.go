@@ -0,0 +1,22 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package spanpruningconnector
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/collector/component"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/connector/spanpruningconnector/internal/metadata"
+)
+
+func TestNewFactory(t *testing.T) {
+	factory := NewFactory()
+	assert.Equal(t, metadata.Type, factory.Type())
+	assert.Equal(t, component.StabilityLevelDevelopment, factory.TracesToTracesStability())
+	assert.Equal(t, component.StabilityLevelDevelopment, factory.TracesToMetricsStability())
+	assert.Equal(t, component.StabilityLevelUndefined, factory.TracesToLogsStability())
+	assert.Equal(t, createDefaultConfig(), factory.CreateDefaultConfig())
+}
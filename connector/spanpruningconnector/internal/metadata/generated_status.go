@@ -0,0 +1,19 @@
+// Code generated by mdatagen. DO NOT EDIT.
+
+// Package metadata contains the autogenerated telemetry and
+// build information for the connector/span_pruning component.
+package metadata
+
+import (
+	"go.opentelemetry.io/collector/component"
+)
+
+var (
+	Type      = component.MustNewType("span_pruning")
+	ScopeName = "github.com/open-telemetry/opentelemetry-collector-contrib/connector/spanpruningconnector"
+)
+
+const (
+	TracesToTracesStability  = component.StabilityLevelDevelopment
+	TracesToMetricsStability = component.StabilityLevelDevelopment
+)
@@ -0,0 +1,99 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package prometheusremotereadexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/prometheusremotereadexporter"
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/prometheus/prometheus/tsdb/chunkenc"
+)
+
+// samplesPerChunk is the number of points after which a chunk is cut, as
+// in the Prometheus TSDB head.
+const samplesPerChunk = 120
+
+// chunkBuilder cuts the points of a series into chunks.
+type chunkBuilder struct {
+	chunks []prompb.Chunk
+
+	chunk chunkenc.Chunk
+	app   chunkenc.Appender
+	mint  int64
+	maxt  int64
+}
+
+// cut finishes the current chunk, if any.
+func (b *chunkBuilder) cut() {
+	if b.chunk == nil || b.chunk.NumSamples() == 0 {
+		return
+	}
+	b.chunks = append(b.chunks, prompb.Chunk{
+		MinTimeMs: b.mint,
+		MaxTimeMs: b.maxt,
+		Type:      prompb.Chunk_Encoding(b.chunk.Encoding()),
+		Data:      b.chunk.Bytes(),
+	})
+	b.chunk, b.app = nil, nil
+}
+
+// start begins a new chunk at t.
+func (b *chunkBuilder) start(c chunkenc.Chunk, t int64) error {
+	b.cut()
+	app, err := c.Appender()
+	if err != nil {
+		return err
+	}
+	b.chunk, b.app, b.mint = c, app, t
+	return nil
+}
+
+// encodeChunks encodes the samples of ts as XOR chunks and its histograms
+// as float histogram chunks, ordered by their start time.
+func encodeChunks(ts *prompb.TimeSeries) ([]prompb.Chunk, error) {
+	var b chunkBuilder
+	for _, s := range ts.Samples {
+		if b.chunk == nil || b.chunk.NumSamples() >= samplesPerChunk {
+			if err := b.start(chunkenc.NewXORChunk(), s.Timestamp); err != nil {
+				return nil, err
+			}
+		}
+		b.app.Append(0, s.Timestamp, s.Value)
+		b.maxt = s.Timestamp
+	}
+	b.cut()
+
+	var prev chunkenc.Appender
+	for _, h := range ts.Histograms {
+		if b.chunk == nil || b.chunk.NumSamples() >= samplesPerChunk {
+			prev = b.app
+			if err := b.start(chunkenc.NewFloatHistogramChunk(), h.Timestamp); err != nil {
+				return nil, err
+			}
+		}
+		c, recoded, app, err := b.app.AppendFloatHistogram(prev, 0, h.Timestamp, h.ToFloatHistogram(), false)
+		if err != nil {
+			return nil, err
+		}
+		prev = nil
+		if c != nil {
+			// The histogram did not fit the chunk: either the chunk was
+			// recoded to a new layout, or a new chunk was started because
+			// of a counter reset.
+			if !recoded {
+				b.cut()
+				b.mint = h.Timestamp
+			}
+			b.chunk, b.app = c, app
+		}
+		b.maxt = h.Timestamp
+	}
+	b.cut()
+
+	slices.SortStableFunc(b.chunks, func(a, c prompb.Chunk) int {
+		return cmp.Compare(a.MinTimeMs, c.MinTimeMs)
+	})
+	return b.chunks, nil
+}
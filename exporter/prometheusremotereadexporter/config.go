@@ -0,0 +1,79 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package prometheusremotereadexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/prometheusremotereadexporter"
+
+import (
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/confighttp"
+)
+
+// Config defines configuration for the Prometheus remote read exporter.
+type Config struct {
+	// ServerConfig configures the HTTP server serving the remote read endpoint.
+	ServerConfig confighttp.ServerConfig `mapstructure:",squash"`
+
+	// Namespace if set, exports metrics under the provided value.
+	Namespace string `mapstructure:"namespace"`
+
+	// ExternalLabels are added to every series.
+	ExternalLabels map[string]string `mapstructure:"external_labels"`
+
+	// AddMetricSuffixes controls whether unit and type suffixes are added to
+	// metric names. Defaults to true.
+	AddMetricSuffixes bool `mapstructure:"add_metric_suffixes"`
+
+	// Retention is how long samples are kept. Defaults to 1h.
+	Retention time.Duration `mapstructure:"retention"`
+
+	// MaxSeries is the maximum number of series kept. Samples of new series
+	// are dropped once the limit is reached. Defaults to 100000.
+	MaxSeries int `mapstructure:"max_series"`
+
+	// MaxSamplesPerSeries is the maximum number of samples kept per series;
+	// the oldest samples are overwritten first. Defaults to 720.
+	MaxSamplesPerSeries int `mapstructure:"max_samples_per_series"`
+
+	// SampleLimit is the maximum number of samples a single query may return
+	// in a non-streamed response. 0 means no limit. Defaults to 50000000.
+	SampleLimit int `mapstructure:"sample_limit"`
+
+	// Storage is the ID of a storage extension. When set, the samples are
+	// flushed to storage periodically and on shutdown, and restored on start.
+	Storage *component.ID `mapstructure:"storage"`
+
+	// FlushInterval is how often the samples are flushed to storage. Defaults
+	// to 1m.
+	FlushInterval time.Duration `mapstructure:"flush_interval"`
+}
+
+var _ component.Config = (*Config)(nil)
+
+// Validate checks if the exporter configuration is valid.
+func (cfg *Config) Validate() error {
+	var errs []error
+	if cfg.Retention <= 0 {
+		errs = append(errs, errors.New("retention must be positive"))
+	}
+	if cfg.MaxSeries <= 0 {
+		errs = append(errs, errors.New("max_series must be positive"))
+	}
+	if cfg.MaxSamplesPerSeries <= 0 {
+		errs = append(errs, errors.New("max_samples_per_series must be positive"))
+	}
+	if cfg.FlushInterval <= 0 {
+		errs = append(errs, errors.New("flush_interval must be positive"))
+	}
+	if cfg.SampleLimit < 0 {
+		errs = append(errs, errors.New("sample_limit must be >= 0"))
+	}
+	for name := range cfg.ExternalLabels {
+		if name == "" {
+			errs = append(errs, errors.New("external label names must not be empty"))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,86 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package prometheusremotereadexporter
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/prometheusremotereadexporter/internal/metadata"
+)
+
+func TestLoadConfig(t *testing.T) {
+	t.Parallel()
+
+	cm, err := confmaptest.LoadConf(filepath.Join("testdata", "config.yaml"))
+	require.NoError(t, err)
+
+	storageID := component.MustNewID("file_storage")
+	tests := []struct {
+		id          component.ID
+		expected    component.Config
+		errMessages []string
+	}{
+		{
+			id:       component.NewIDWithName(metadata.Type, ""),
+			expected: createDefaultConfig(),
+		},
+		{
+			id: component.NewIDWithName(metadata.Type, "2"),
+			expected: func() component.Config {
+				cfg := createDefaultConfig().(*Config)
+				cfg.ServerConfig.NetAddr.Endpoint = "0.0.0.0:9201"
+				cfg.Namespace = "test-space"
+				cfg.ExternalLabels = map[string]string{"cluster": "east"}
+				cfg.AddMetricSuffixes = false
+				cfg.Retention = 30 * time.Minute
+				cfg.MaxSeries = 1000
+				cfg.MaxSamplesPerSeries = 360
+				cfg.SampleLimit = 0
+				cfg.Storage = &storageID
+				cfg.FlushInterval = 30 * time.Second
+				return cfg
+			}(),
+		},
+		{
+			id:          component.NewIDWithName(metadata.Type, "bad_retention"),
+			errMessages: []string{"retention must be positive", "flush_interval must be positive"},
+		},
+		{
+			id: component.NewIDWithName(metadata.Type, "bad_limits"),
+			errMessages: []string{
+				"max_series must be positive",
+				"max_samples_per_series must be positive",
+				"sample_limit must be >= 0",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id.String(), func(t *testing.T) {
+			cfg := NewFactory().CreateDefaultConfig()
+
+			sub, err := cm.Sub(tt.id.String())
+			require.NoError(t, err)
+			require.NoError(t, sub.Unmarshal(cfg))
+
+			err = confmap.Validate(cfg)
+			if len(tt.errMessages) > 0 {
+				for _, msg := range tt.errMessages {
+					assert.ErrorContains(t, err, msg)
+				}
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, cfg)
+		})
+	}
+}
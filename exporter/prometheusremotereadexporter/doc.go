@@ -0,0 +1,9 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:generate make mdatagen
+
+// Package prometheusremotereadexporter keeps a bounded window of recent
+// samples from the metrics pipeline and serves them over the Prometheus
+// remote read API.
+package prometheusremotereadexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/prometheusremotereadexporter"
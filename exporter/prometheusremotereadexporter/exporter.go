@@ -0,0 +1,173 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package prometheusremotereadexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/prometheusremotereadexporter"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/exporter"
+	"go.opentelemetry.io/collector/extension/xextension/storage"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/translator/prometheusremotewrite"
+)
+
+// readPath is the path of the remote read endpoint, as served by Prometheus.
+const readPath = "/api/v1/read"
+
+// maxRetentionInterval bounds how often points older than the retention are
+// removed.
+const maxRetentionInterval = time.Minute
+
+type remoteReadExporter struct {
+	config            *Config
+	componentID       component.ID
+	logger            *zap.Logger
+	telemetrySettings component.TelemetrySettings
+	settings          prometheusremotewrite.Settings
+
+	store         *store
+	storageClient storage.Client
+
+	server     *http.Server
+	serverDone sync.WaitGroup
+	cancel     context.CancelFunc
+	loopDone   sync.WaitGroup
+}
+
+func newRemoteReadExporter(cfg *Config, set exporter.Settings) *remoteReadExporter {
+	e := &remoteReadExporter{
+		config:            cfg,
+		componentID:       set.ID,
+		logger:            set.Logger,
+		telemetrySettings: set.TelemetrySettings,
+		settings: prometheusremotewrite.Settings{
+			Namespace:         cfg.Namespace,
+			ExternalLabels:    cfg.ExternalLabels,
+			AddMetricSuffixes: cfg.AddMetricSuffixes,
+		},
+		store: newStore(cfg.MaxSeries, cfg.MaxSamplesPerSeries),
+	}
+	e.store.persist = cfg.Storage != nil
+	return e
+}
+
+func (e *remoteReadExporter) Start(ctx context.Context, host component.Host) error {
+	if e.config.Storage != nil {
+		client, err := getStorageClient(ctx, host, e.config.Storage, e.componentID)
+		if err != nil {
+			return err
+		}
+		e.storageClient = client
+		e.load(ctx)
+	}
+
+	if err := e.startServer(ctx, host); err != nil {
+		if e.storageClient != nil {
+			err = errors.Join(err, e.storageClient.Close(ctx))
+			e.storageClient = nil
+		}
+		return err
+	}
+
+	loopCtx, cancel := context.WithCancel(context.Background())
+	e.cancel = cancel
+	e.loopDone.Go(func() {
+		e.loop(loopCtx)
+	})
+	return nil
+}
+
+func (e *remoteReadExporter) Shutdown(ctx context.Context) error {
+	if e.cancel != nil {
+		e.cancel()
+	}
+	e.loopDone.Wait()
+
+	var errs []error
+	if e.server != nil {
+		errs = append(errs, e.server.Shutdown(ctx))
+		e.serverDone.Wait()
+	}
+	if e.storageClient != nil {
+		errs = append(errs, e.flush(ctx))
+		errs = append(errs, e.storageClient.Close(ctx))
+	}
+	return errors.Join(errs...)
+}
+
+func (e *remoteReadExporter) ConsumeMetrics(_ context.Context, md pmetric.Metrics) error {
+	tsMap, err := prometheusremotewrite.FromMetrics(md, e.settings)
+	if err != nil {
+		e.logger.Debug("failed to translate metrics, storing remaining metrics", zap.Error(err), zap.Int("translated", len(tsMap)))
+	}
+
+	var res appendResult
+	for _, ts := range tsMap {
+		r := e.store.append(ts)
+		res.appended += r.appended
+		res.outOfOrder += r.outOfOrder
+		res.seriesLimited += r.seriesLimited
+	}
+	if res.outOfOrder > 0 || res.seriesLimited > 0 {
+		e.logger.Debug("dropped samples",
+			zap.Int("out_of_order", res.outOfOrder),
+			zap.Int("series_limit", res.seriesLimited),
+			zap.Int("max_series", e.config.MaxSeries))
+	}
+	return nil
+}
+
+// loop periodically removes the points older than the retention, and
+// flushes the series to storage when configured, until ctx is canceled.
+func (e *remoteReadExporter) loop(ctx context.Context) {
+	ticker := time.NewTicker(min(e.config.Retention, maxRetentionInterval))
+	defer ticker.Stop()
+
+	var flush <-chan time.Time
+	if e.storageClient != nil {
+		flushTicker := time.NewTicker(e.config.FlushInterval)
+		defer flushTicker.Stop()
+		flush = flushTicker.C
+	}
+
+	for {
+		select {
+		case <-ticker.C:
+			e.applyRetention()
+		case <-flush:
+			if err := e.flush(ctx); err != nil {
+				e.logger.Warn("Failed to flush series to storage", zap.Error(err))
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (e *remoteReadExporter) applyRetention() {
+	e.store.dropBefore(time.Now().Add(-e.config.Retention).UnixMilli())
+}
+
+// getStorageClient resolves a storage.Client for the exporter.
+func getStorageClient(ctx context.Context, host component.Host, storageID *component.ID, componentID component.ID) (storage.Client, error) {
+	ext, ok := host.GetExtensions()[*storageID]
+	if !ok {
+		return nil, fmt.Errorf("storage extension %q not found", storageID)
+	}
+
+	storageExt, ok := ext.(storage.Extension)
+	if !ok {
+		return nil, fmt.Errorf("extension %q is not a storage extension", storageID)
+	}
+
+	return storageExt.GetClient(ctx, component.KindExporter, componentID, "")
+}
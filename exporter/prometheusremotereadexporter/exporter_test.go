@@ -0,0 +1,348 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package prometheusremotereadexporter
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/prometheus/prometheus/storage/remote"
+	"github.com/prometheus/prometheus/tsdb/chunkenc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/exporter/exportertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+
+	"go.opentelemetry.io/collector/extension/xextension/storage"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/prometheusremotereadexporter/internal/metadata"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/storage/storagetest"
+)
+
+func newTestExporter(t *testing.T, cfg *Config, host component.Host) *remoteReadExporter {
+	t.Helper()
+	cfg.ServerConfig.NetAddr.Endpoint = "localhost:0"
+	set := exportertest.NewNopSettings(metadata.Type)
+	// A fixed ID, so that restarted exporters share their storage client.
+	set.ID = component.NewID(metadata.Type)
+	e := newRemoteReadExporter(cfg, set)
+	require.NoError(t, e.Start(t.Context(), host))
+	return e
+}
+
+// newTestMetrics returns a gauge with two points and an exponential
+// histogram with one point, both of service "api".
+func newTestMetrics(start time.Time) pmetric.Metrics {
+	md := pmetric.NewMetrics()
+	rm := md.ResourceMetrics().AppendEmpty()
+	rm.Resource().Attributes().PutStr("service.name", "api")
+	sm := rm.ScopeMetrics().AppendEmpty()
+
+	gauge := sm.Metrics().AppendEmpty()
+	gauge.SetName("temperature")
+	points := gauge.SetEmptyGauge().DataPoints()
+	for i := range 2 {
+		dp := points.AppendEmpty()
+		dp.SetTimestamp(pcommon.NewTimestampFromTime(start.Add(time.Duration(i) * time.Second)))
+		dp.SetDoubleValue(20 + float64(i))
+	}
+
+	hist := sm.Metrics().AppendEmpty()
+	hist.SetName("latency")
+	h := hist.SetEmptyExponentialHistogram()
+	h.SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+	dp := h.DataPoints().AppendEmpty()
+	dp.SetTimestamp(pcommon.NewTimestampFromTime(start))
+	dp.SetScale(0)
+	dp.SetCount(3)
+	dp.SetSum(6)
+	dp.Positive().BucketCounts().FromRaw([]uint64{1, 2})
+	return md
+}
+
+func doRead(t *testing.T, e *remoteReadExporter, req *prompb.ReadRequest) *httptest.ResponseRecorder {
+	t.Helper()
+	data, err := req.Marshal()
+	require.NoError(t, err)
+	r := httptest.NewRequest(http.MethodPost, readPath, bytes.NewReader(snappy.Encode(nil, data)))
+	w := httptest.NewRecorder()
+	e.server.Handler.ServeHTTP(w, r)
+	return w
+}
+
+func newQuery(t *testing.T, start time.Time, name string) *prompb.Query {
+	t.Helper()
+	matchers, err := remote.ToLabelMatchers([]*labels.Matcher{
+		labels.MustNewMatcher(labels.MatchEqual, labels.MetricName, name),
+	})
+	require.NoError(t, err)
+	return &prompb.Query{
+		StartTimestampMs: start.Add(-time.Minute).UnixMilli(),
+		EndTimestampMs:   start.Add(time.Minute).UnixMilli(),
+		Matchers:         matchers,
+	}
+}
+
+func decodeSamplesResponse(t *testing.T, w *httptest.ResponseRecorder) *prompb.ReadResponse {
+	t.Helper()
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "snappy", w.Header().Get("Content-Encoding"))
+	data, err := snappy.Decode(nil, w.Body.Bytes())
+	require.NoError(t, err)
+	var resp prompb.ReadResponse
+	require.NoError(t, resp.Unmarshal(data))
+	return &resp
+}
+
+func TestReadSamples(t *testing.T) {
+	start := time.Now().Truncate(time.Second)
+	e := newTestExporter(t, createDefaultConfig().(*Config), componenttest.NewNopHost())
+	defer func() { require.NoError(t, e.Shutdown(t.Context())) }()
+	require.NoError(t, e.ConsumeMetrics(t.Context(), newTestMetrics(start)))
+
+	resp := decodeSamplesResponse(t, doRead(t, e, &prompb.ReadRequest{
+		Queries: []*prompb.Query{newQuery(t, start, "temperature"), newQuery(t, start, "latency")},
+	}))
+	require.Len(t, resp.Results, 2)
+
+	require.Len(t, resp.Results[0].Timeseries, 1)
+	ts := resp.Results[0].Timeseries[0]
+	assert.Equal(t, "api", ts.ToLabels(&labels.ScratchBuilder{}, nil).Get("job"))
+	assert.Equal(t, []prompb.Sample{
+		{Timestamp: start.UnixMilli(), Value: 20},
+		{Timestamp: start.Add(time.Second).UnixMilli(), Value: 21},
+	}, ts.Samples)
+
+	require.Len(t, resp.Results[1].Timeseries, 1)
+	histograms := resp.Results[1].Timeseries[0].Histograms
+	require.Len(t, histograms, 1)
+	assert.Equal(t, start.UnixMilli(), histograms[0].Timestamp)
+	assert.Equal(t, 6.0, histograms[0].ToFloatHistogram().Sum)
+}
+
+func TestReadSamplesLimit(t *testing.T) {
+	start := time.Now().Truncate(time.Second)
+	cfg := createDefaultConfig().(*Config)
+	cfg.SampleLimit = 1
+	e := newTestExporter(t, cfg, componenttest.NewNopHost())
+	defer func() { require.NoError(t, e.Shutdown(t.Context())) }()
+	require.NoError(t, e.ConsumeMetrics(t.Context(), newTestMetrics(start)))
+
+	w := doRead(t, e, &prompb.ReadRequest{Queries: []*prompb.Query{newQuery(t, start, "temperature")}})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "exceeded sample limit")
+}
+
+func TestReadBadRequest(t *testing.T) {
+	e := newTestExporter(t, createDefaultConfig().(*Config), componenttest.NewNopHost())
+	defer func() { require.NoError(t, e.Shutdown(t.Context())) }()
+
+	r := httptest.NewRequest(http.MethodPost, readPath, bytes.NewReader([]byte("not snappy")))
+	w := httptest.NewRecorder()
+	e.server.Handler.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+type streamedPoint struct {
+	t int64
+	v float64
+}
+
+// decodeStreamedResponse returns the points of every series of a streamed
+// response, keyed by metric name, and the number of chunks per series.
+func decodeStreamedResponse(t *testing.T, w *httptest.ResponseRecorder) (map[string][]streamedPoint, map[string]int) {
+	t.Helper()
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	points := map[string][]streamedPoint{}
+	chunks := map[string]int{}
+	reader := remote.NewChunkedReader(w.Body, 50*1024*1024, nil)
+	for {
+		var resp prompb.ChunkedReadResponse
+		err := reader.NextProto(&resp)
+		if errors.Is(err, io.EOF) {
+			return points, chunks
+		}
+		require.NoError(t, err)
+		for _, series := range resp.ChunkedSeries {
+			name := series.ToLabels(&labels.ScratchBuilder{}, nil).Get(labels.MetricName)
+			for _, c := range series.Chunks {
+				chunks[name]++
+				chk, err := chunkenc.FromData(chunkenc.Encoding(c.Type), c.Data)
+				require.NoError(t, err)
+				it := chk.Iterator(nil)
+				for vt := it.Next(); vt != chunkenc.ValNone; vt = it.Next() {
+					switch vt {
+					case chunkenc.ValFloat:
+						ts, v := it.At()
+						points[name] = append(points[name], streamedPoint{t: ts, v: v})
+					case chunkenc.ValFloatHistogram:
+						ts, h := it.AtFloatHistogram(nil)
+						points[name] = append(points[name], streamedPoint{t: ts, v: h.Sum})
+					default:
+						t.Fatalf("unexpected value type %v", vt)
+					}
+				}
+				require.NoError(t, it.Err())
+			}
+		}
+	}
+}
+
+func TestReadStreamedChunks(t *testing.T) {
+	start := time.Now().Truncate(time.Second)
+	e := newTestExporter(t, createDefaultConfig().(*Config), componenttest.NewNopHost())
+	defer func() { require.NoError(t, e.Shutdown(t.Context())) }()
+	require.NoError(t, e.ConsumeMetrics(t.Context(), newTestMetrics(start)))
+
+	w := doRead(t, e, &prompb.ReadRequest{
+		Queries:               []*prompb.Query{newQuery(t, start, "temperature"), newQuery(t, start, "latency")},
+		AcceptedResponseTypes: []prompb.ReadRequest_ResponseType{prompb.ReadRequest_STREAMED_XOR_CHUNKS},
+	})
+	assert.Equal(t, "application/x-streamed-protobuf; proto=prometheus.ChunkedReadResponse", w.Header().Get("Content-Type"))
+	points, _ := decodeStreamedResponse(t, w)
+	assert.Equal(t, map[string][]streamedPoint{
+		"temperature": {{t: start.UnixMilli(), v: 20}, {t: start.Add(time.Second).UnixMilli(), v: 21}},
+		"latency":     {{t: start.UnixMilli(), v: 6}},
+	}, points)
+}
+
+func TestReadStreamedChunksCutsChunks(t *testing.T) {
+	e := newTestExporter(t, createDefaultConfig().(*Config), componenttest.NewNopHost())
+	defer func() { require.NoError(t, e.Shutdown(t.Context())) }()
+	start := time.Now().Truncate(time.Second)
+	var timestamps []int64
+	for i := range 300 {
+		timestamps = append(timestamps, start.Add(time.Duration(i)*time.Millisecond).UnixMilli())
+	}
+	e.store.append(newTestSeries("up", "a", timestamps...))
+
+	w := doRead(t, e, &prompb.ReadRequest{
+		Queries:               []*prompb.Query{newQuery(t, start, "up")},
+		AcceptedResponseTypes: []prompb.ReadRequest_ResponseType{prompb.ReadRequest_STREAMED_XOR_CHUNKS},
+	})
+	points, chunks := decodeStreamedResponse(t, w)
+	assert.Len(t, points["up"], 300)
+	assert.Equal(t, 3, chunks["up"])
+}
+
+func TestPersistence(t *testing.T) {
+	start := time.Now().Truncate(time.Second)
+	host := storagetest.NewStorageHost().WithFileBackedStorageExtension("test", t.TempDir())
+	id := storagetest.NewStorageID("test")
+	newConfig := func() *Config {
+		cfg := createDefaultConfig().(*Config)
+		cfg.Storage = &id
+		return cfg
+	}
+
+	e1 := newTestExporter(t, newConfig(), host)
+	require.NoError(t, e1.ConsumeMetrics(t.Context(), newTestMetrics(start)))
+	require.NoError(t, e1.Shutdown(t.Context()))
+
+	e2 := newTestExporter(t, newConfig(), host)
+	defer func() { require.NoError(t, e2.Shutdown(t.Context())) }()
+	assert.Equal(t, 2, e2.store.numSeries())
+
+	resp := decodeSamplesResponse(t, doRead(t, e2, &prompb.ReadRequest{
+		Queries: []*prompb.Query{newQuery(t, start, "temperature")},
+	}))
+	require.Len(t, resp.Results[0].Timeseries, 1)
+	assert.Len(t, resp.Results[0].Timeseries[0].Samples, 2)
+}
+
+func TestPersistenceRestoresChunks(t *testing.T) {
+	start := time.Now().Add(-time.Minute).UnixMilli()
+	host := storagetest.NewStorageHost().WithFileBackedStorageExtension("test", t.TempDir())
+	id := storagetest.NewStorageID("test")
+	newConfig := func() *Config {
+		cfg := createDefaultConfig().(*Config)
+		cfg.Storage = &id
+		cfg.MaxSamplesPerSeries = 200
+		return cfg
+	}
+
+	e1 := newTestExporter(t, newConfig(), host)
+	ts := &prompb.TimeSeries{Labels: []prompb.Label{{Name: labels.MetricName, Value: "up"}}}
+	for i := range int64(300) {
+		ts.Samples = append(ts.Samples, prompb.Sample{Timestamp: start + i, Value: float64(i)})
+	}
+	e1.store.append(ts)
+	require.NoError(t, e1.Shutdown(t.Context()))
+
+	e2 := newTestExporter(t, newConfig(), host)
+	defer func() { require.NoError(t, e2.Shutdown(t.Context())) }()
+	got := e2.store.query(math.MinInt64, math.MaxInt64, nil)
+	require.Len(t, got, 1)
+	require.Len(t, got[0].Samples, 200)
+	assert.Equal(t, start+100, got[0].Samples[0].Timestamp)
+	assert.Equal(t, start+299, got[0].Samples[199].Timestamp)
+	// The points were restored from a sealed chunk and the head.
+	for _, ms := range e2.store.series {
+		assert.Equal(t, []chunkBounds{{maxSample: start + 219, maxHistogram: math.MinInt64}}, ms.persisted.chunks)
+		assert.Equal(t, uint64(1), ms.persisted.nextChunk)
+	}
+}
+
+func TestPersistenceFlushesPeriodically(t *testing.T) {
+	id := storagetest.NewStorageID("test")
+	cfg := createDefaultConfig().(*Config)
+	cfg.Storage = &id
+	cfg.FlushInterval = 10 * time.Millisecond
+	e := newTestExporter(t, cfg, storagetest.NewStorageHost().WithInMemoryStorageExtension("test"))
+	defer func() { require.NoError(t, e.Shutdown(t.Context())) }()
+
+	require.NoError(t, e.ConsumeMetrics(t.Context(), newTestMetrics(time.Now())))
+	assert.Eventually(t, func() bool {
+		index, err := e.storageClient.Get(t.Context(), indexKey)
+		return err == nil && len(index) > 0
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+// clientTrackingStorage records the last client it returned.
+type clientTrackingStorage struct {
+	*storagetest.TestStorage
+	client storage.Client
+}
+
+func (s *clientTrackingStorage) GetClient(ctx context.Context, kind component.Kind, id component.ID, name string) (storage.Client, error) {
+	client, err := s.TestStorage.GetClient(ctx, kind, id, name)
+	s.client = client
+	return client, err
+}
+
+func TestStartClosesStorageOnServerError(t *testing.T) {
+	id := storagetest.NewStorageID("test")
+	ext := &clientTrackingStorage{TestStorage: storagetest.NewInMemoryStorageExtension("test")}
+	cfg := createDefaultConfig().(*Config)
+	cfg.Storage = &id
+	cfg.ServerConfig.NetAddr.Endpoint = "localhost:-1"
+	e := newRemoteReadExporter(cfg, exportertest.NewNopSettings(metadata.Type))
+	require.Error(t, e.Start(t.Context(), storagetest.NewStorageHost().WithExtension(id, ext)))
+
+	require.NotNil(t, ext.client)
+	_, err := ext.client.Get(t.Context(), indexKey)
+	assert.ErrorContains(t, err, "client closed")
+	require.NoError(t, e.Shutdown(t.Context()))
+}
+
+func TestPersistenceMissingStorage(t *testing.T) {
+	id := storagetest.NewStorageID("test")
+	cfg := createDefaultConfig().(*Config)
+	cfg.Storage = &id
+	e := newRemoteReadExporter(cfg, exportertest.NewNopSettings(metadata.Type))
+	assert.ErrorContains(t, e.Start(t.Context(), storagetest.NewStorageHost()), "storage extension")
+}
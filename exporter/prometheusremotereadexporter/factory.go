@@ -0,0 +1,68 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package prometheusremotereadexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/prometheusremotereadexporter"
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/confighttp"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/exporter"
+	"go.opentelemetry.io/collector/exporter/exporterhelper"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/prometheusremotereadexporter/internal/metadata"
+)
+
+const (
+	defaultEndpoint            = "localhost:9201"
+	defaultRetention           = time.Hour
+	defaultMaxSeries           = 100000
+	defaultMaxSamplesPerSeries = 720
+	defaultSampleLimit         = 50000000
+	defaultFlushInterval       = time.Minute
+)
+
+// NewFactory creates a new Prometheus remote read exporter factory.
+func NewFactory() exporter.Factory {
+	return exporter.NewFactory(
+		metadata.Type,
+		createDefaultConfig,
+		exporter.WithMetrics(createMetricsExporter, metadata.MetricsStability),
+	)
+}
+
+func createDefaultConfig() component.Config {
+	serverConfig := confighttp.NewDefaultServerConfig()
+	serverConfig.NetAddr.Endpoint = defaultEndpoint
+	return &Config{
+		ServerConfig:        serverConfig,
+		ExternalLabels:      map[string]string{},
+		AddMetricSuffixes:   true,
+		Retention:           defaultRetention,
+		MaxSeries:           defaultMaxSeries,
+		MaxSamplesPerSeries: defaultMaxSamplesPerSeries,
+		SampleLimit:         defaultSampleLimit,
+		FlushInterval:       defaultFlushInterval,
+	}
+}
+
+func createMetricsExporter(
+	ctx context.Context,
+	set exporter.Settings,
+	cfg component.Config,
+) (exporter.Metrics, error) {
+	rrCfg := cfg.(*Config)
+	rre := newRemoteReadExporter(rrCfg, set)
+	return exporterhelper.NewMetrics(
+		ctx,
+		set,
+		cfg,
+		rre.ConsumeMetrics,
+		exporterhelper.WithStart(rre.Start),
+		exporterhelper.WithShutdown(rre.Shutdown),
+		exporterhelper.WithCapabilities(consumer.Capabilities{MutatesData: false}),
+	)
+}
@@ -0,0 +1,18 @@
+// Code generated by mdatagen. DO NOT EDIT.
+
+// Package metadata contains the autogenerated telemetry and
+// build information for the exporter/prometheusremoteread component.
+package metadata
+
+import (
+	"go.opentelemetry.io/collector/component"
+)
+
+var (
+	Type      = component.MustNewType("prometheusremoteread")
+	ScopeName = "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/prometheusremotereadexporter"
+)
+
+const (
+	MetricsStability = component.StabilityLevelDevelopment
+)
@@ -0,0 +1,327 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package prometheusremotereadexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/prometheusremotereadexporter"
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/prometheus/prometheus/prompb"
+	"go.opentelemetry.io/collector/extension/xextension/storage"
+	"go.uber.org/zap"
+)
+
+// The series are persisted under the following storage keys, so that a flush
+// only writes what changed since the previous one:
+//   - indexKey: the refs of the persisted series, as uvarints.
+//   - series/<ref>: the manifest of a series, its first and next chunk
+//     sequence numbers as uvarints followed by its labels, encoded as a
+//     remote write time series.
+//   - chunk/<ref>/<seq>: a sealed chunk of samplesPerChunk points, encoded
+//     as a remote write time series without labels. Chunks are written once,
+//     and deleted once all of their points have left the ring.
+//   - head/<ref>: the points not sealed in a chunk yet, rewritten by every
+//     flush that follows new points.
+const indexKey = "index"
+
+func seriesKey(ref uint64) string {
+	return "series/" + strconv.FormatUint(ref, 10)
+}
+
+func chunkKey(ref, seq uint64) string {
+	return "chunk/" + strconv.FormatUint(ref, 10) + "/" + strconv.FormatUint(seq, 10)
+}
+
+func headKey(ref uint64) string {
+	return "head/" + strconv.FormatUint(ref, 10)
+}
+
+// chunkBounds holds the timestamps of the newest sample and histogram of a
+// sealed chunk, or math.MinInt64 if it has none.
+type chunkBounds struct {
+	maxSample    int64
+	maxHistogram int64
+}
+
+// persistedSeries holds what is persisted of a series. Guarded by store.mu.
+type persistedSeries struct {
+	// saved is set once the manifest of the series is written.
+	saved           bool
+	manifestChanged bool
+	// changed is set when points were appended since the last flush.
+	changed bool
+	// chunks holds the bounds of the sealed chunks firstChunk to
+	// nextChunk-1 still in storage.
+	chunks     []chunkBounds
+	firstChunk uint64
+	nextChunk  uint64
+	// sealedSample and sealedHistogram are the timestamps of the newest
+	// sample and histogram in a sealed chunk. Newer points are in the head.
+	sealedSample    int64
+	sealedHistogram int64
+}
+
+func newPersistedSeries() persistedSeries {
+	return persistedSeries{sealedSample: math.MinInt64, sealedHistogram: math.MinInt64}
+}
+
+// flushOperations returns the storage operations that persist the changes of
+// the series since the last flush: new sealed chunks, the head, the
+// eviction of the chunks that left the rings, the manifests, and the index.
+func (s *store) flushOperations() ([]*storage.Operation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var ops []*storage.Operation
+	for _, ms := range s.removed {
+		ops = append(ops, ms.deleteOperations()...)
+	}
+	s.removed = nil
+	for _, ms := range s.series {
+		seriesOps, err := ms.flushOperations()
+		if err != nil {
+			return nil, err
+		}
+		ops = append(ops, seriesOps...)
+	}
+	if s.indexChanged {
+		index := make([]byte, 0, len(s.series)*binary.MaxVarintLen64)
+		for _, ms := range s.series {
+			index = binary.AppendUvarint(index, ms.ref)
+		}
+		ops = append(ops, storage.SetOperation(indexKey, index))
+		s.indexChanged = false
+	}
+	return ops, nil
+}
+
+func (ms *memSeries) flushOperations() ([]*storage.Operation, error) {
+	p := &ms.persisted
+	var ops []*storage.Operation
+	if p.changed {
+		samples, histograms := ms.unsealed()
+		for len(samples)+len(histograms) >= samplesPerChunk {
+			n := min(len(samples), samplesPerChunk)
+			m := min(len(histograms), samplesPerChunk-n)
+			chunk := prompb.TimeSeries{Samples: samples[:n], Histograms: histograms[:m]}
+			data, err := chunk.Marshal()
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode chunk: %w", err)
+			}
+			ops = append(ops, storage.SetOperation(chunkKey(ms.ref, p.nextChunk), data))
+
+			bounds := chunkBounds{maxSample: math.MinInt64, maxHistogram: math.MinInt64}
+			if n > 0 {
+				bounds.maxSample = samples[n-1].Timestamp
+				p.sealedSample = bounds.maxSample
+			}
+			if m > 0 {
+				bounds.maxHistogram = histograms[m-1].Timestamp
+				p.sealedHistogram = bounds.maxHistogram
+			}
+			p.chunks = append(p.chunks, bounds)
+			p.nextChunk++
+			p.manifestChanged = true
+			samples, histograms = samples[n:], histograms[m:]
+		}
+
+		if len(samples) == 0 && len(histograms) == 0 {
+			ops = append(ops, storage.DeleteOperation(headKey(ms.ref)))
+		} else {
+			head := prompb.TimeSeries{Samples: samples, Histograms: histograms}
+			data, err := head.Marshal()
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode head: %w", err)
+			}
+			ops = append(ops, storage.SetOperation(headKey(ms.ref), data))
+		}
+		p.changed = false
+	}
+
+	// Chunks are evicted once the rings overwrote or dropped all of their
+	// points.
+	oldestSample, oldestHistogram := int64(math.MaxInt64), int64(math.MaxInt64)
+	if ms.samples.len() > 0 {
+		oldestSample = ms.samples.at(0).Timestamp
+	}
+	if ms.histograms.len() > 0 {
+		oldestHistogram = ms.histograms.at(0).Timestamp
+	}
+	for len(p.chunks) > 0 && p.chunks[0].maxSample < oldestSample && p.chunks[0].maxHistogram < oldestHistogram {
+		ops = append(ops, storage.DeleteOperation(chunkKey(ms.ref, p.firstChunk)))
+		p.chunks = p.chunks[1:]
+		p.firstChunk++
+		p.manifestChanged = true
+	}
+
+	if !p.saved || p.manifestChanged {
+		manifest := binary.AppendUvarint(nil, p.firstChunk)
+		manifest = binary.AppendUvarint(manifest, p.nextChunk)
+		lbls := prompb.TimeSeries{Labels: prompb.FromLabels(ms.labels, nil)}
+		data, err := lbls.Marshal()
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode series labels: %w", err)
+		}
+		ops = append(ops, storage.SetOperation(seriesKey(ms.ref), append(manifest, data...)))
+		p.saved = true
+		p.manifestChanged = false
+	}
+	return ops, nil
+}
+
+// unsealed returns the points of ms that are not in a sealed chunk.
+func (ms *memSeries) unsealed() ([]prompb.Sample, []prompb.Histogram) {
+	var samples []prompb.Sample
+	for i := range ms.samples.len() {
+		if p := ms.samples.at(i); p.Timestamp > ms.persisted.sealedSample {
+			samples = append(samples, p)
+		}
+	}
+	var histograms []prompb.Histogram
+	for i := range ms.histograms.len() {
+		if p := ms.histograms.at(i); p.Timestamp > ms.persisted.sealedHistogram {
+			histograms = append(histograms, p)
+		}
+	}
+	return samples, histograms
+}
+
+// deleteOperations returns the storage operations that delete the series.
+func (ms *memSeries) deleteOperations() []*storage.Operation {
+	p := &ms.persisted
+	if !p.saved {
+		return nil
+	}
+	ops := []*storage.Operation{
+		storage.DeleteOperation(seriesKey(ms.ref)),
+		storage.DeleteOperation(headKey(ms.ref)),
+	}
+	for seq := p.firstChunk; seq < p.nextChunk; seq++ {
+		ops = append(ops, storage.DeleteOperation(chunkKey(ms.ref, seq)))
+	}
+	return ops
+}
+
+// restore adds a series read from storage. A series that cannot be added,
+// as max_series is reached, is deleted from storage by the next flush.
+func (s *store) restore(ref uint64, ts *prompb.TimeSeries, firstChunk uint64, chunks []chunkBounds) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	persisted := newPersistedSeries()
+	persisted.saved = true
+	persisted.chunks = chunks
+	persisted.firstChunk = firstChunk
+	persisted.nextChunk = firstChunk + uint64(len(chunks))
+	for _, c := range chunks {
+		persisted.sealedSample = max(persisted.sealedSample, c.maxSample)
+		persisted.sealedHistogram = max(persisted.sealedHistogram, c.maxHistogram)
+	}
+
+	ms := s.getOrCreate(ts)
+	if ms == nil || ms.persisted.saved {
+		// The series does not fit, or its labels are restored already.
+		s.removed = append(s.removed, &memSeries{ref: ref, persisted: persisted})
+		return
+	}
+	ms.ref = ref
+	s.nextRef = max(s.nextRef, ref)
+	ms.append(ts, s.maxSamplesPerSeries)
+	ms.persisted = persisted
+}
+
+// flush writes the changes of the store since the last flush to storage.
+func (e *remoteReadExporter) flush(ctx context.Context) error {
+	ops, err := e.store.flushOperations()
+	if err != nil {
+		return err
+	}
+	if len(ops) == 0 {
+		return nil
+	}
+	if err := e.storageClient.Batch(ctx, ops...); err != nil {
+		return fmt.Errorf("failed to write series to storage: %w", err)
+	}
+	e.logger.Debug("Flushed series to storage", zap.Int("operations", len(ops)))
+	return nil
+}
+
+// load restores the series persisted by flush. Points older than the
+// retention are dropped.
+func (e *remoteReadExporter) load(ctx context.Context) {
+	index, err := e.storageClient.Get(ctx, indexKey)
+	if err != nil {
+		e.logger.Warn("Failed to read series from storage, starting empty", zap.Error(err))
+		return
+	}
+	for len(index) > 0 {
+		ref, n := binary.Uvarint(index)
+		if n <= 0 {
+			e.logger.Warn("Ignoring unreadable series index in storage")
+			break
+		}
+		index = index[n:]
+		if err := e.loadSeries(ctx, ref); err != nil {
+			e.logger.Warn("Ignoring unreadable series in storage", zap.Uint64("ref", ref), zap.Error(err))
+		}
+	}
+	e.applyRetention()
+	e.logger.Info("Restored series from storage", zap.Int("series", e.store.numSeries()))
+}
+
+func (e *remoteReadExporter) loadSeries(ctx context.Context, ref uint64) error {
+	manifestOp, headOp := storage.GetOperation(seriesKey(ref)), storage.GetOperation(headKey(ref))
+	if err := e.storageClient.Batch(ctx, manifestOp, headOp); err != nil {
+		return err
+	}
+	manifest := manifestOp.Value
+	firstChunk, n := binary.Uvarint(manifest)
+	if n <= 0 {
+		return errors.New("invalid manifest")
+	}
+	manifest = manifest[n:]
+	nextChunk, n := binary.Uvarint(manifest)
+	if n <= 0 || nextChunk < firstChunk {
+		return errors.New("invalid manifest")
+	}
+	var ts prompb.TimeSeries
+	if err := ts.Unmarshal(manifest[n:]); err != nil {
+		return err
+	}
+
+	chunkOps := make([]*storage.Operation, 0, nextChunk-firstChunk)
+	for seq := firstChunk; seq < nextChunk; seq++ {
+		chunkOps = append(chunkOps, storage.GetOperation(chunkKey(ref, seq)))
+	}
+	if len(chunkOps) > 0 {
+		if err := e.storageClient.Batch(ctx, chunkOps...); err != nil {
+			return err
+		}
+	}
+	chunks := make([]chunkBounds, 0, len(chunkOps))
+	for _, op := range append(chunkOps, headOp) {
+		var points prompb.TimeSeries
+		if err := points.Unmarshal(op.Value); err != nil {
+			return err
+		}
+		if op != headOp {
+			bounds := chunkBounds{maxSample: math.MinInt64, maxHistogram: math.MinInt64}
+			if n := len(points.Samples); n > 0 {
+				bounds.maxSample = points.Samples[n-1].Timestamp
+			}
+			if n := len(points.Histograms); n > 0 {
+				bounds.maxHistogram = points.Histograms[n-1].Timestamp
+			}
+			chunks = append(chunks, bounds)
+		}
+		ts.Samples = append(ts.Samples, points.Samples...)
+		ts.Histograms = append(ts.Histograms, points.Histograms...)
+	}
+	e.store.restore(ref, &ts, firstChunk, chunks)
+	return nil
+}
@@ -0,0 +1,174 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package prometheusremotereadexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/prometheusremotereadexporter"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/prometheus/prometheus/storage/remote"
+	"go.opentelemetry.io/collector/component"
+	"go.uber.org/zap"
+)
+
+// maxBytesInFrame is the maximum size of a frame of a streamed response, as
+// in the Prometheus remote read handler. A series is split over several
+// frames when its chunks do not fit in one.
+const maxBytesInFrame = 1024 * 1024
+
+// startServer starts the HTTP server serving the remote read endpoint.
+func (e *remoteReadExporter) startServer(ctx context.Context, host component.Host) error {
+	cfg := e.config.ServerConfig
+	ln, err := cfg.ToListener(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to bind to address %s: %w", cfg.NetAddr.Endpoint, err)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST "+readPath, e.handleRead)
+	e.server, err = cfg.ToServer(ctx, host.GetExtensions(), e.telemetrySettings, mux)
+	if err != nil {
+		_ = ln.Close()
+		return err
+	}
+
+	e.serverDone.Go(func() {
+		if err := e.server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			e.logger.Error("Remote read server failed", zap.Error(err))
+		}
+	})
+	return nil
+}
+
+func (e *remoteReadExporter) handleRead(w http.ResponseWriter, r *http.Request) {
+	req, err := remote.DecodeReadRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	responseType, err := remote.NegotiateResponseType(req.AcceptedResponseTypes)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	switch responseType {
+	case prompb.ReadRequest_STREAMED_XOR_CHUNKS:
+		e.readStreamedChunks(w, req)
+	default:
+		e.readSamples(w, req)
+	}
+}
+
+// querySeries returns the series matching query.
+func (e *remoteReadExporter) querySeries(query *prompb.Query) ([]prompb.TimeSeries, error) {
+	matchers, err := remote.FromLabelMatchers(query.Matchers)
+	if err != nil {
+		return nil, err
+	}
+	return e.store.query(query.StartTimestampMs, query.EndTimestampMs, matchers), nil
+}
+
+// readSamples answers req with a single response holding the raw samples of
+// every query.
+func (e *remoteReadExporter) readSamples(w http.ResponseWriter, req *prompb.ReadRequest) {
+	resp := prompb.ReadResponse{
+		Results: make([]*prompb.QueryResult, len(req.Queries)),
+	}
+	for i, query := range req.Queries {
+		series, err := e.querySeries(query)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		numSamples := 0
+		result := &prompb.QueryResult{Timeseries: make([]*prompb.TimeSeries, len(series))}
+		for j := range series {
+			numSamples += len(series[j].Samples) + len(series[j].Histograms)
+			if e.config.SampleLimit > 0 && numSamples > e.config.SampleLimit {
+				http.Error(w, fmt.Sprintf("exceeded sample limit (%d)", e.config.SampleLimit), http.StatusBadRequest)
+				return
+			}
+			result.Timeseries[j] = &series[j]
+		}
+		resp.Results[i] = result
+	}
+
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	w.Header().Set("Content-Encoding", "snappy")
+	if err := remote.EncodeReadResponse(&resp, w); err != nil {
+		e.logger.Debug("Failed to write remote read response", zap.Error(err))
+	}
+}
+
+// readStreamedChunks answers req with a stream of frames, each holding the
+// chunks of at most one series.
+func (e *remoteReadExporter) readStreamedChunks(w http.ResponseWriter, req *prompb.ReadRequest) {
+	f, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "internal http.ResponseWriter does not implement http.Flusher interface", http.StatusInternalServerError)
+		return
+	}
+
+	// Matchers are validated before the first frame is written, as the
+	// status code cannot be changed afterwards.
+	results := make([][]prompb.TimeSeries, len(req.Queries))
+	for i, query := range req.Queries {
+		series, err := e.querySeries(query)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		results[i] = series
+	}
+
+	w.Header().Set("Content-Type", "application/x-streamed-protobuf; proto=prometheus.ChunkedReadResponse")
+	stream := remote.NewChunkedWriter(w, f)
+	for i, series := range results {
+		for j := range series {
+			if err := writeSeriesFrames(stream, int64(i), &series[j]); err != nil {
+				e.logger.Debug("Failed to write remote read response", zap.Error(err))
+				return
+			}
+		}
+	}
+}
+
+// writeSeriesFrames writes the chunks of ts to stream, splitting them over
+// several frames when they exceed maxBytesInFrame.
+func writeSeriesFrames(stream *remote.ChunkedWriter, queryIndex int64, ts *prompb.TimeSeries) error {
+	chunks, err := encodeChunks(ts)
+	if err != nil {
+		return fmt.Errorf("failed to encode chunks: %w", err)
+	}
+
+	maxDataLength := maxBytesInFrame
+	for _, l := range ts.Labels {
+		maxDataLength -= l.Size()
+	}
+	for len(chunks) > 0 {
+		// A frame holds at least one chunk, even when it exceeds the frame
+		// size on its own.
+		n, frameBytes := 1, chunks[0].Size()
+		for n < len(chunks) && frameBytes+chunks[n].Size() <= maxDataLength {
+			frameBytes += chunks[n].Size()
+			n++
+		}
+		resp := prompb.ChunkedReadResponse{
+			ChunkedSeries: []*prompb.ChunkedSeries{{Labels: ts.Labels, Chunks: chunks[:n]}},
+			QueryIndex:    queryIndex,
+		}
+		b, err := resp.Marshal()
+		if err != nil {
+			return fmt.Errorf("failed to encode frame: %w", err)
+		}
+		if _, err := stream.Write(b); err != nil {
+			return fmt.Errorf("failed to write frame: %w", err)
+		}
+		chunks = chunks[n:]
+	}
+	return nil
+}
@@ -0,0 +1,274 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package prometheusremotereadexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/prometheusremotereadexporter"
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/prompb"
+)
+
+// ring is a fixed-capacity buffer of points ordered by time. Pushing to a
+// full ring overwrites its oldest point.
+type ring[T any] struct {
+	buf   []T
+	start int
+	n     int
+}
+
+func newRing[T any](capacity int) ring[T] {
+	return ring[T]{buf: make([]T, capacity)}
+}
+
+func (r *ring[T]) len() int {
+	return r.n
+}
+
+// at returns the i-th oldest point.
+func (r *ring[T]) at(i int) T {
+	return r.buf[(r.start+i)%len(r.buf)]
+}
+
+func (r *ring[T]) push(v T) {
+	if r.n < len(r.buf) {
+		r.buf[(r.start+r.n)%len(r.buf)] = v
+		r.n++
+		return
+	}
+	r.buf[r.start] = v
+	r.start = (r.start + 1) % len(r.buf)
+}
+
+// dropOldest removes the n oldest points.
+func (r *ring[T]) dropOldest(n int) {
+	var zero T
+	for range n {
+		r.buf[r.start] = zero
+		r.start = (r.start + 1) % len(r.buf)
+		r.n--
+	}
+}
+
+// memSeries holds the recent samples and native histograms of one series.
+type memSeries struct {
+	// ref identifies the series in storage.
+	ref        uint64
+	labels     labels.Labels
+	samples    ring[prompb.Sample]
+	histograms ring[prompb.Histogram]
+	persisted  persistedSeries
+}
+
+// lastTimestamps returns the timestamp of the newest sample and histogram,
+// or -1 if there are none.
+func (s *memSeries) lastTimestamps() (sample, histogram int64) {
+	sample, histogram = -1, -1
+	if n := s.samples.len(); n > 0 {
+		sample = s.samples.at(n - 1).Timestamp
+	}
+	if n := s.histograms.len(); n > 0 {
+		histogram = s.histograms.at(n - 1).Timestamp
+	}
+	return sample, histogram
+}
+
+// toTimeSeries copies the points of s within [mint, maxt] into a TimeSeries.
+func (s *memSeries) toTimeSeries(mint, maxt int64) prompb.TimeSeries {
+	ts := prompb.TimeSeries{Labels: prompb.FromLabels(s.labels, nil)}
+	for i := range s.samples.len() {
+		if p := s.samples.at(i); p.Timestamp >= mint && p.Timestamp <= maxt {
+			ts.Samples = append(ts.Samples, p)
+		}
+	}
+	for i := range s.histograms.len() {
+		if p := s.histograms.at(i); p.Timestamp >= mint && p.Timestamp <= maxt {
+			ts.Histograms = append(ts.Histograms, p)
+		}
+	}
+	return ts
+}
+
+// store is a bounded, in-memory set of series. It is safe for concurrent
+// use.
+type store struct {
+	maxSeries           int
+	maxSamplesPerSeries int
+
+	mu      sync.RWMutex
+	series  map[string]*memSeries
+	builder labels.ScratchBuilder
+	keyBuf  []byte
+
+	// persist is set when the series are persisted to storage, see
+	// flushOperations.
+	persist bool
+	nextRef uint64
+	// removed holds the series removed since the last flush.
+	removed      []*memSeries
+	indexChanged bool
+}
+
+func newStore(maxSeries, maxSamplesPerSeries int) *store {
+	return &store{
+		maxSeries:           maxSeries,
+		maxSamplesPerSeries: maxSamplesPerSeries,
+		series:              make(map[string]*memSeries),
+	}
+}
+
+// appendResult counts the points handled by store.append.
+type appendResult struct {
+	appended int
+	// outOfOrder counts points not newer than the newest point of their
+	// series.
+	outOfOrder int
+	// seriesLimited counts points of new series dropped because max_series
+	// was reached.
+	seriesLimited int
+}
+
+// append adds the samples and histograms of ts. Points must be newer than
+// the newest point of the series; older points are dropped, as remote read
+// clients expect points in time order.
+func (s *store) append(ts *prompb.TimeSeries) appendResult {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ms := s.getOrCreate(ts)
+	if ms == nil {
+		return appendResult{seriesLimited: len(ts.Samples) + len(ts.Histograms)}
+	}
+	return ms.append(ts, s.maxSamplesPerSeries)
+}
+
+// getOrCreate returns the series of the labels of ts, creating it unless
+// max_series is reached. Must be called with s.mu held.
+func (s *store) getOrCreate(ts *prompb.TimeSeries) *memSeries {
+	lbls := ts.ToLabels(&s.builder, nil)
+	s.keyBuf = lbls.Bytes(s.keyBuf)
+	if ms, ok := s.series[string(s.keyBuf)]; ok {
+		return ms
+	}
+	if len(s.series) >= s.maxSeries {
+		return nil
+	}
+	s.nextRef++
+	ms := &memSeries{
+		ref:        s.nextRef,
+		labels:     lbls,
+		samples:    newRing[prompb.Sample](s.maxSamplesPerSeries),
+		histograms: newRing[prompb.Histogram](0),
+		persisted:  newPersistedSeries(),
+	}
+	s.series[string(s.keyBuf)] = ms
+	s.indexChanged = true
+	return ms
+}
+
+// append adds the points of ts that are newer than the newest point of ms.
+func (ms *memSeries) append(ts *prompb.TimeSeries, maxSamplesPerSeries int) appendResult {
+	var res appendResult
+	lastSample, lastHistogram := ms.lastTimestamps()
+	for _, p := range ts.Samples {
+		if p.Timestamp <= lastSample {
+			res.outOfOrder++
+			continue
+		}
+		ms.samples.push(p)
+		lastSample = p.Timestamp
+		res.appended++
+	}
+	if len(ts.Histograms) > 0 && len(ms.histograms.buf) == 0 {
+		// Most series only hold samples, so the histogram ring is only
+		// allocated once the series receives its first histogram.
+		ms.histograms = newRing[prompb.Histogram](maxSamplesPerSeries)
+	}
+	for _, p := range ts.Histograms {
+		if p.Timestamp <= lastHistogram {
+			res.outOfOrder++
+			continue
+		}
+		ms.histograms.push(p)
+		lastHistogram = p.Timestamp
+		res.appended++
+	}
+	if res.appended > 0 {
+		ms.persisted.changed = true
+	}
+	return res
+}
+
+// dropBefore removes the points older than mint, and the series left
+// without points.
+func (s *store) dropBefore(mint int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, ms := range s.series {
+		n := 0
+		for n < ms.samples.len() && ms.samples.at(n).Timestamp < mint {
+			n++
+		}
+		ms.samples.dropOldest(n)
+		n = 0
+		for n < ms.histograms.len() && ms.histograms.at(n).Timestamp < mint {
+			n++
+		}
+		ms.histograms.dropOldest(n)
+		if ms.samples.len() == 0 && ms.histograms.len() == 0 {
+			s.remove(key, ms)
+		}
+	}
+}
+
+// remove removes the series ms stored under key. Must be called with s.mu
+// held.
+func (s *store) remove(key string, ms *memSeries) {
+	delete(s.series, key)
+	if s.persist {
+		s.removed = append(s.removed, ms)
+		s.indexChanged = true
+	}
+}
+
+// query returns copies of the series matching all matchers that have points
+// within [mint, maxt], sorted by labels.
+func (s *store) query(mint, maxt int64, matchers []*labels.Matcher) []prompb.TimeSeries {
+	s.mu.RLock()
+	var matched []*memSeries
+	for _, ms := range s.series {
+		if matchesAll(ms.labels, matchers) {
+			matched = append(matched, ms)
+		}
+	}
+	slices.SortFunc(matched, func(a, b *memSeries) int {
+		return labels.Compare(a.labels, b.labels)
+	})
+	result := make([]prompb.TimeSeries, 0, len(matched))
+	for _, ms := range matched {
+		if ts := ms.toTimeSeries(mint, maxt); len(ts.Samples) > 0 || len(ts.Histograms) > 0 {
+			result = append(result, ts)
+		}
+	}
+	s.mu.RUnlock()
+	return result
+}
+
+func matchesAll(lbls labels.Labels, matchers []*labels.Matcher) bool {
+	for _, m := range matchers {
+		if !m.Matches(lbls.Get(m.Name)) {
+			return false
+		}
+	}
+	return true
+}
+
+// numSeries returns the number of series in the store.
+func (s *store) numSeries() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.series)
+}
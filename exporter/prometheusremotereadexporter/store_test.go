@@ -0,0 +1,138 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package prometheusremotereadexporter
+
+import (
+	"math"
+	"testing"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/extension/xextension/storage"
+)
+
+func newTestSeries(name, job string, timestamps ...int64) *prompb.TimeSeries {
+	ts := &prompb.TimeSeries{Labels: []prompb.Label{
+		{Name: labels.MetricName, Value: name},
+		{Name: "job", Value: job},
+	}}
+	for _, t := range timestamps {
+		ts.Samples = append(ts.Samples, prompb.Sample{Timestamp: t, Value: float64(t)})
+	}
+	return ts
+}
+
+func sampleTimestamps(ts prompb.TimeSeries) []int64 {
+	var timestamps []int64
+	for _, s := range ts.Samples {
+		timestamps = append(timestamps, s.Timestamp)
+	}
+	return timestamps
+}
+
+func TestStoreRingOverwritesOldestSamples(t *testing.T) {
+	s := newStore(10, 3)
+	assert.Equal(t, appendResult{appended: 5}, s.append(newTestSeries("up", "a", 1, 2, 3, 4, 5)))
+
+	got := s.query(math.MinInt64, math.MaxInt64, nil)
+	assert.Len(t, got, 1)
+	assert.Equal(t, []int64{3, 4, 5}, sampleTimestamps(got[0]))
+}
+
+func TestStoreDropsOutOfOrderSamples(t *testing.T) {
+	s := newStore(10, 10)
+	s.append(newTestSeries("up", "a", 10, 20))
+	assert.Equal(t, appendResult{appended: 1, outOfOrder: 2}, s.append(newTestSeries("up", "a", 5, 20, 30)))
+
+	got := s.query(math.MinInt64, math.MaxInt64, nil)
+	assert.Equal(t, []int64{10, 20, 30}, sampleTimestamps(got[0]))
+}
+
+func TestStoreMaxSeries(t *testing.T) {
+	s := newStore(1, 10)
+	s.append(newTestSeries("up", "a", 1))
+	assert.Equal(t, appendResult{seriesLimited: 2}, s.append(newTestSeries("up", "b", 1, 2)))
+	assert.Equal(t, appendResult{appended: 1}, s.append(newTestSeries("up", "a", 2)), "existing series still receive samples")
+	assert.Equal(t, 1, s.numSeries())
+}
+
+func TestStoreDropBefore(t *testing.T) {
+	s := newStore(10, 10)
+	s.append(newTestSeries("up", "a", 1, 2, 3))
+	s.append(newTestSeries("up", "b", 1))
+	s.dropBefore(2)
+
+	got := s.query(math.MinInt64, math.MaxInt64, nil)
+	assert.Len(t, got, 1, "series without samples are removed")
+	assert.Equal(t, []int64{2, 3}, sampleTimestamps(got[0]))
+}
+
+func TestStoreQuery(t *testing.T) {
+	s := newStore(10, 10)
+	s.append(newTestSeries("up", "b", 1, 2, 3))
+	s.append(newTestSeries("up", "a", 1, 2, 3))
+	s.append(newTestSeries("down", "a", 1, 2, 3))
+
+	got := s.query(2, 3, []*labels.Matcher{
+		labels.MustNewMatcher(labels.MatchEqual, labels.MetricName, "up"),
+	})
+	assert.Len(t, got, 2)
+	assert.Equal(t, "a", got[0].Labels[1].Value, "series are sorted by labels")
+	assert.Equal(t, "b", got[1].Labels[1].Value)
+	assert.Equal(t, []int64{2, 3}, sampleTimestamps(got[0]))
+
+	got = s.query(2, 3, []*labels.Matcher{
+		labels.MustNewMatcher(labels.MatchRegexp, "job", "a|c"),
+		labels.MustNewMatcher(labels.MatchNotEqual, labels.MetricName, "up"),
+	})
+	assert.Len(t, got, 1)
+	assert.Equal(t, "down", got[0].Labels[0].Value)
+
+	assert.Empty(t, s.query(10, 20, nil), "series without samples in range are not returned")
+}
+
+func timestampRange(from, to int64) []int64 {
+	var timestamps []int64
+	for t := from; t <= to; t++ {
+		timestamps = append(timestamps, t)
+	}
+	return timestamps
+}
+
+// flushedKeys flushes s and returns the operations, as "set <key>" or
+// "delete <key>".
+func flushedKeys(t *testing.T, s *store) []string {
+	t.Helper()
+	ops, err := s.flushOperations()
+	require.NoError(t, err)
+	var keys []string
+	for _, op := range ops {
+		switch op.Type {
+		case storage.Set:
+			keys = append(keys, "set "+op.Key)
+		case storage.Delete:
+			keys = append(keys, "delete "+op.Key)
+		}
+	}
+	return keys
+}
+
+func TestStoreFlushSealsAndEvictsChunks(t *testing.T) {
+	s := newStore(10, 150)
+	s.persist = true
+
+	s.append(newTestSeries("up", "a", timestampRange(1, 130)...))
+	assert.Equal(t, []string{"set chunk/1/0", "set head/1", "set series/1", "set index"}, flushedKeys(t, s))
+	assert.Empty(t, flushedKeys(t, s), "nothing changed since the last flush")
+
+	// The ring now holds 131 to 280: the first chunk left it, and the points
+	// since the first chunk fill a second one.
+	s.append(newTestSeries("up", "a", timestampRange(131, 280)...))
+	assert.Equal(t, []string{"set chunk/1/1", "set head/1", "delete chunk/1/0", "set series/1"}, flushedKeys(t, s))
+
+	s.dropBefore(1000)
+	assert.Equal(t, []string{"delete series/1", "delete head/1", "delete chunk/1/1", "set index"}, flushedKeys(t, s))
+}
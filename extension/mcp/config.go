@@ -5,6 +5,8 @@ package mcp // import "github.com/open-telemetry/opentelemetry-collector-contrib
 
 import (
 	"errors"
+	"fmt"
+	"net/url"
 
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/config/confighttp"
@@ -15,6 +17,22 @@ var errHTTPEndpointRequired = errors.New("http endpoint required")
 // Config represents the extension config settings within the collector's config.yaml
 type Config struct {
 	ServerConfig confighttp.ServerConfig `mapstructure:",squash"`
+	// LiveTools configures the tools that inspect the running collector.
+	LiveTools LiveToolsConfig `mapstructure:"live_tools"`
+	// prevent unkeyed literal initialization
+	_ struct{}
+}
+
+// LiveToolsConfig configures the tools that inspect the running collector.
+type LiveToolsConfig struct {
+	// Enabled registers the live collector tools, which let any client of the
+	// server read the pipelines, telemetry and metrics of the collector.
+	// Default: false
+	Enabled bool `mapstructure:"enabled"`
+	// MetricsEndpoint is the URL of the collector's own Prometheus metrics,
+	// used to report the exporter queues. When empty, it is derived from the
+	// service telemetry configuration, defaulting to http://localhost:8888/metrics.
+	MetricsEndpoint string `mapstructure:"metrics_endpoint"`
 	// prevent unkeyed literal initialization
 	_ struct{}
 }
@@ -26,5 +44,10 @@ func (cfg *Config) Validate() error {
 	if cfg.ServerConfig.NetAddr.Endpoint == "" {
 		return errHTTPEndpointRequired
 	}
+	if cfg.LiveTools.MetricsEndpoint != "" {
+		if _, err := url.ParseRequestURI(cfg.LiveTools.MetricsEndpoint); err != nil {
+			return fmt.Errorf("invalid live_tools::metrics_endpoint: %w", err)
+		}
+	}
 	return nil
 }
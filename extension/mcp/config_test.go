@@ -28,3 +28,12 @@ func TestValidate(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateMetricsEndpoint(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.LiveTools.MetricsEndpoint = "http://localhost:8888/metrics"
+	assert.NoError(t, cfg.Validate())
+
+	cfg.LiveTools.MetricsEndpoint = "localhost 8888"
+	assert.ErrorContains(t, cfg.Validate(), "invalid live_tools::metrics_endpoint")
+}
@@ -4,34 +4,53 @@
 package mcp // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/mcp"
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"net"
 	"net/http"
+	"slices"
+	"strings"
 	"sync"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/component/componentstatus"
+	"go.opentelemetry.io/collector/confmap"
 	"go.opentelemetry.io/collector/extension"
+	"go.opentelemetry.io/collector/extension/extensioncapabilities"
+	"go.opentelemetry.io/collector/pipeline"
+	"go.opentelemetry.io/collector/service/hostcapabilities"
 	"go.uber.org/zap"
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/mcp/internal/mcp/tools"
 )
 
-var _ extension.Extension = (*mcpExtension)(nil)
+var (
+	_ extension.Extension                 = (*mcpExtension)(nil)
+	_ extensioncapabilities.ConfigWatcher = (*mcpExtension)(nil)
+	_ componentstatus.Watcher             = (*mcpExtension)(nil)
+	_ tools.CollectorState                = (*mcpExtension)(nil)
+)
 
 type mcpExtension struct {
 	cfg        *Config
 	settings   component.TelemetrySettings
 	server     *http.Server
 	shutdownWG sync.WaitGroup
+
+	// mu guards the state of the running collector used by the live tools.
+	mu       sync.RWMutex
+	host     component.Host
+	conf     *confmap.Conf
+	statuses map[*componentstatus.InstanceID]*componentstatus.Event
 }
 
 func newExtension(cfg *Config, telemetry component.TelemetrySettings) *mcpExtension {
 	jrse := &mcpExtension{
 		cfg:      cfg,
 		settings: telemetry,
+		statuses: map[*componentstatus.InstanceID]*componentstatus.Event{},
 	}
 	return jrse
 }
@@ -44,11 +63,14 @@ func (mcpe *mcpExtension) Start(ctx context.Context, host component.Host) error
 		Version: "0.0.1",
 	}, nil)
 
-	allTools, err := tools.GetAllTools()
+	mcpe.mu.Lock()
+	mcpe.host = host
+	mcpe.mu.Unlock()
+
+	allTools, err := mcpe.tools()
 	if err != nil {
 		return err
 	}
-
 	for _, tool := range allTools {
 		s.AddTool(tool.Tool, tool.Handler)
 	}
@@ -77,6 +99,19 @@ func (mcpe *mcpExtension) Start(ctx context.Context, host component.Host) error
 	return nil
 }
 
+// tools returns the tools served by the extension. The live tools are only
+// included when they are enabled, as they expose the running collector.
+func (mcpe *mcpExtension) tools() ([]tools.Tool, error) {
+	allTools, err := tools.GetAllTools(mcpe)
+	if err != nil {
+		return nil, err
+	}
+	if mcpe.cfg.LiveTools.Enabled {
+		allTools = append(allTools, tools.GetLiveTools(mcpe, mcpe.cfg.LiveTools.MetricsEndpoint)...)
+	}
+	return allTools, nil
+}
+
 func (mcpe *mcpExtension) Shutdown(ctx context.Context) error {
 	if mcpe.server != nil {
 		if err := mcpe.server.Shutdown(ctx); err != nil {
@@ -86,3 +121,63 @@ func (mcpe *mcpExtension) Shutdown(ctx context.Context) error {
 
 	return nil
 }
+
+// NotifyConfig implements extensioncapabilities.ConfigWatcher.
+func (mcpe *mcpExtension) NotifyConfig(_ context.Context, conf *confmap.Conf) error {
+	mcpe.mu.Lock()
+	defer mcpe.mu.Unlock()
+	mcpe.conf = conf
+	return nil
+}
+
+// ComponentStatusChanged implements componentstatus.Watcher.
+func (mcpe *mcpExtension) ComponentStatusChanged(source *componentstatus.InstanceID, event *componentstatus.Event) {
+	mcpe.mu.Lock()
+	defer mcpe.mu.Unlock()
+	mcpe.statuses[source] = event
+}
+
+// EffectiveConfig implements tools.CollectorState.
+func (mcpe *mcpExtension) EffectiveConfig() *confmap.Conf {
+	mcpe.mu.RLock()
+	defer mcpe.mu.RUnlock()
+	return mcpe.conf
+}
+
+// Components implements tools.CollectorState.
+func (mcpe *mcpExtension) Components() []tools.ComponentInstance {
+	mcpe.mu.RLock()
+	defer mcpe.mu.RUnlock()
+	components := make([]tools.ComponentInstance, 0, len(mcpe.statuses))
+	for source, event := range mcpe.statuses {
+		instance := tools.ComponentInstance{
+			ID:     source.ComponentID().String(),
+			Kind:   strings.ToLower(source.Kind().String()),
+			Status: event.Status().String(),
+		}
+		source.AllPipelineIDs(func(id pipeline.ID) bool {
+			instance.Pipelines = append(instance.Pipelines, id.String())
+			return true
+		})
+		slices.Sort(instance.Pipelines)
+		if err := event.Err(); err != nil {
+			instance.Error = err.Error()
+		}
+		components = append(components, instance)
+	}
+	slices.SortFunc(components, func(a, b tools.ComponentInstance) int {
+		return cmp.Or(cmp.Compare(a.Kind, b.Kind), cmp.Compare(a.ID, b.ID))
+	})
+	return components
+}
+
+// Factory implements tools.CollectorState.
+func (mcpe *mcpExtension) Factory(kind component.Kind, componentType component.Type) component.Factory {
+	mcpe.mu.RLock()
+	defer mcpe.mu.RUnlock()
+	factories, ok := mcpe.host.(hostcapabilities.ComponentFactory)
+	if !ok {
+		return nil
+	}
+	return factories.GetFactory(kind, componentType)
+}
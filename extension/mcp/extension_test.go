@@ -4,10 +4,18 @@
 package mcp
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componentstatus"
 	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/pipeline"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/mcp/internal/mcp/tools"
 )
 
 func TestNewExtension(t *testing.T) {
@@ -24,3 +32,57 @@ func testConfig() *Config {
 	cfg.ServerConfig.NetAddr.Endpoint = "127.0.0.1:5778"
 	return cfg
 }
+
+func TestDefaultConfigExposesNoLiveTools(t *testing.T) {
+	liveTools := map[string]bool{}
+	for _, tool := range tools.GetLiveTools(nil, "") {
+		liveTools[tool.Tool.Name] = true
+	}
+	require.NotEmpty(t, liveTools)
+
+	e := newExtension(testConfig(), componenttest.NewNopTelemetrySettings())
+	allTools, err := e.tools()
+	require.NoError(t, err)
+	require.NotEmpty(t, allTools)
+	for _, tool := range allTools {
+		assert.False(t, liveTools[tool.Tool.Name], "live tool %q is exposed by default", tool.Tool.Name)
+	}
+
+	cfg := testConfig()
+	cfg.LiveTools.Enabled = true
+	e = newExtension(cfg, componenttest.NewNopTelemetrySettings())
+	allTools, err = e.tools()
+	require.NoError(t, err)
+	exposed := 0
+	for _, tool := range allTools {
+		if liveTools[tool.Tool.Name] {
+			exposed++
+		}
+	}
+	assert.Len(t, liveTools, exposed)
+}
+
+func TestCollectorState(t *testing.T) {
+	e := newExtension(testConfig(), componenttest.NewNopTelemetrySettings())
+	assert.Nil(t, e.EffectiveConfig())
+	assert.Empty(t, e.Components())
+
+	conf := confmap.NewFromStringMap(map[string]any{"receivers": map[string]any{"otlp": nil}})
+	require.NoError(t, e.NotifyConfig(t.Context(), conf))
+	assert.Equal(t, conf, e.EffectiveConfig())
+
+	tracesID := pipeline.NewID(pipeline.SignalTraces)
+	logsID := pipeline.NewIDWithName(pipeline.SignalLogs, "backend")
+	receiverID := componentstatus.NewInstanceID(component.MustNewID("otlp"), component.KindReceiver, tracesID, logsID)
+	exporterID := componentstatus.NewInstanceID(component.MustNewIDWithName("otlp", "backend"), component.KindExporter, logsID)
+	e.ComponentStatusChanged(receiverID, componentstatus.NewEvent(componentstatus.StatusStarting))
+	e.ComponentStatusChanged(receiverID, componentstatus.NewEvent(componentstatus.StatusOK))
+	e.ComponentStatusChanged(exporterID, componentstatus.NewRecoverableErrorEvent(errors.New("connection refused")))
+
+	assert.Equal(t, []tools.ComponentInstance{
+		{ID: "otlp/backend", Kind: "exporter", Pipelines: []string{"logs/backend"}, Status: "StatusRecoverableError", Error: "connection refused"},
+		{ID: "otlp", Kind: "receiver", Pipelines: []string{"logs/backend", "traces"}, Status: "StatusOK"},
+	}, e.Components())
+
+	assert.Nil(t, e.Factory(component.KindReceiver, component.MustNewType("otlp")), "the host does not expose factories before start")
+}
@@ -0,0 +1,488 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package tools // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/mcp/internal/mcp/tools"
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/connector"
+	"go.opentelemetry.io/collector/pipeline"
+	"go.yaml.in/yaml/v3"
+)
+
+const (
+	severityError   = "error"
+	severityWarning = "warning"
+
+	inlineScheme = "yaml"
+	envScheme    = "env"
+)
+
+// schemaError is an error found validating a component configuration against
+// its JSON schema. Field is the dotted path of the invalid field, or
+// "(root)".
+type schemaError struct {
+	Field   string
+	Message string
+}
+
+// schemaValidator validates the JSON configuration of a component against the
+// schema of the given collector version.
+type schemaValidator func(kind, name, version string, config []byte) ([]schemaError, error)
+
+type diagnostic struct {
+	Severity string `json:"severity"`
+	Path     string `json:"path"`
+	Line     int    `json:"line,omitempty"`
+	Message  string `json:"message"`
+}
+
+type lintResult struct {
+	Valid       bool         `json:"valid"`
+	Diagnostics []diagnostic `json:"diagnostics"`
+}
+
+func getLintConfigTool(state CollectorState, validate schemaValidator, latestCollectorVersion string) Tool {
+	tool := &mcp.Tool{
+		Name:        "lint-config",
+		Description: "Lint a whole OpenTelemetry collector configuration YAML. The configuration is resolved, every component is checked against its schema, and the pipelines are checked for wiring errors: references to undefined components, unused components, connectors used on one side only or between unsupported signals, and storage or auth settings referencing extensions that are not configured or enabled. Returns diagnostics with the line they refer to. When the MCP server runs in a collector, components are also validated with the factories of its build.",
+		Annotations: &mcp.ToolAnnotations{
+			DestructiveHint: boolPtr(false),
+			OpenWorldHint:   boolPtr(false),
+		},
+		InputSchema: json.RawMessage(`{"type":"object","properties":{"config":{"type":"string","description":"The collector configuration in YAML"},"env":{"type":"object","additionalProperties":{"type":"string"},"description":"Values of the environment variables referenced by the configuration e.g. {\"OTLP_ENDPOINT\":\"backend:4317\"}"},"version":{"type":"string","description":"The OpenTelemetry Collector version e.g. 0.138.0"}},"required":["config"]}`),
+	}
+
+	handler := func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := parseArgs(req)
+		yamlConfig, ok := requireStringArg(args, "config")
+		if !ok {
+			return errResult("config argument is required"), nil
+		}
+		version := stringArg(args, "version", latestCollectorVersion)
+		env := map[string]string{}
+		if rawEnv, ok := args["env"].(map[string]any); ok {
+			for name, value := range rawEnv {
+				env[name] = fmt.Sprint(value)
+			}
+		}
+		return jsonResult(lintConfig(ctx, state, validate, version, yamlConfig, env)), nil
+	}
+
+	return Tool{Tool: tool, Handler: handler}
+}
+
+// lintConfig lints a collector configuration. state is nil when the factories
+// of a running collector are not available.
+func lintConfig(ctx context.Context, state CollectorState, validate schemaValidator, version, yamlConfig string, env map[string]string) lintResult {
+	result := lintResult{Diagnostics: []diagnostic{}}
+	var root yaml.Node
+	if err := yaml.Unmarshal([]byte(yamlConfig), &root); err != nil {
+		result.Diagnostics = append(result.Diagnostics, diagnostic{
+			Severity: severityError,
+			Line:     yamlErrorLine(err),
+			Message:  err.Error(),
+		})
+		return result
+	}
+	lines := yamlLines{}
+	lines.index(&root, "")
+
+	reporter := func(severity string) reportFunc {
+		return func(path, format string, args ...any) {
+			result.Diagnostics = append(result.Diagnostics, diagnostic{
+				Severity: severity,
+				Path:     path,
+				Line:     lines.line(path),
+				Message:  fmt.Sprintf(format, args...),
+			})
+		}
+	}
+	reportError, reportWarning := reporter(severityError), reporter(severityWarning)
+
+	conf, err := resolveConfig(ctx, yamlConfig, env, func(name string) {
+		reportWarning("", "environment variable %q is not set, it resolves to an empty value", name)
+	})
+	if err != nil {
+		reportError("", "failed to resolve the configuration: %v", err)
+		return result
+	}
+
+	defined := definedComponents(conf)
+	if validate != nil {
+		checkSchemas(conf, defined, validate, version, reportError)
+	}
+	if state != nil {
+		checkComponents(state, conf, reportError, false)
+	}
+	checkReferences(conf, defined, reportError)
+	checkUnused(conf, defined, reportWarning)
+	checkConnectors(state, conf, defined, reportError)
+	checkExtensionReferences(conf, defined, reportError)
+
+	slices.SortStableFunc(result.Diagnostics, func(a, b diagnostic) int {
+		return a.Line - b.Line
+	})
+	result.Valid = !slices.ContainsFunc(result.Diagnostics, func(d diagnostic) bool {
+		return d.Severity == severityError
+	})
+	return result
+}
+
+var yamlErrorLineRegexp = regexp.MustCompile(`line (\d+)`)
+
+func yamlErrorLine(err error) int {
+	if match := yamlErrorLineRegexp.FindStringSubmatch(err.Error()); match != nil {
+		line, _ := strconv.Atoi(match[1])
+		return line
+	}
+	return 0
+}
+
+// yamlLines maps the configuration paths of a YAML document, e.g.
+// "receivers::otlp::protocols" or "service::pipelines::traces::receivers::0",
+// to the line they are defined at.
+type yamlLines map[string]int
+
+func (l yamlLines) index(node *yaml.Node, path string) {
+	join := func(key string) string {
+		if path == "" {
+			return key
+		}
+		return path + confmap.KeyDelimiter + key
+	}
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			l.index(child, path)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			l[join(key.Value)] = key.Line
+			l.index(value, join(key.Value))
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			l[join(strconv.Itoa(i))] = item.Line
+			l.index(item, join(strconv.Itoa(i)))
+		}
+	}
+}
+
+// line returns the line of path, or of its closest parent defined in the
+// document.
+func (l yamlLines) line(path string) int {
+	for path != "" {
+		if line, ok := l[path]; ok {
+			return line
+		}
+		i := strings.LastIndex(path, confmap.KeyDelimiter)
+		if i < 0 {
+			break
+		}
+		path = path[:i]
+	}
+	return 0
+}
+
+// resolveConfig resolves the ${...} references of a configuration. Only the
+// environment variables of env are resolved, the ones of the process running
+// the tool are not exposed; missing is called for variables not in env.
+func resolveConfig(ctx context.Context, yamlConfig string, env map[string]string, missing func(name string)) (*confmap.Conf, error) {
+	resolver, err := confmap.NewResolver(confmap.ResolverSettings{
+		URIs: []string{inlineScheme + ":" + yamlConfig},
+		ProviderFactories: []confmap.ProviderFactory{
+			confmap.NewProviderFactory(func(confmap.ProviderSettings) confmap.Provider {
+				return &inlineProvider{}
+			}),
+			confmap.NewProviderFactory(func(confmap.ProviderSettings) confmap.Provider {
+				return &envProvider{env: env, missing: missing}
+			}),
+		},
+		DefaultScheme: envScheme,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resolver.Shutdown(ctx) }()
+	return resolver.Resolve(ctx)
+}
+
+// inlineProvider retrieves the configuration inlined in "yaml:<content>" URIs.
+type inlineProvider struct{}
+
+func (*inlineProvider) Retrieve(_ context.Context, uri string, _ confmap.WatcherFunc) (*confmap.Retrieved, error) {
+	return confmap.NewRetrievedFromYAML([]byte(strings.TrimPrefix(uri, inlineScheme+":")))
+}
+
+func (*inlineProvider) Scheme() string {
+	return inlineScheme
+}
+
+func (*inlineProvider) Shutdown(context.Context) error {
+	return nil
+}
+
+// envProvider resolves "env:NAME" and "env:NAME:-default" URIs from a map.
+type envProvider struct {
+	env     map[string]string
+	missing func(name string)
+}
+
+func (p *envProvider) Retrieve(_ context.Context, uri string, _ confmap.WatcherFunc) (*confmap.Retrieved, error) {
+	name, defaultValue, hasDefault := strings.Cut(strings.TrimPrefix(uri, envScheme+":"), ":-")
+	value, ok := p.env[name]
+	if !ok {
+		if !hasDefault {
+			p.missing(name)
+		}
+		value = defaultValue
+	}
+	return confmap.NewRetrievedFromYAML([]byte(value))
+}
+
+func (*envProvider) Scheme() string {
+	return envScheme
+}
+
+func (*envProvider) Shutdown(context.Context) error {
+	return nil
+}
+
+// checkSchemas validates the configuration of each component against the
+// schema of its type.
+func checkSchemas(conf *confmap.Conf, defined map[string][]string, validate schemaValidator, version string, report reportFunc) {
+	for _, section := range componentSections {
+		for _, key := range defined[section.name] {
+			path := section.name + confmap.KeyDelimiter + key
+			var id component.ID
+			if err := id.UnmarshalText([]byte(key)); err != nil {
+				report(path, "invalid component ID: %v", err)
+				continue
+			}
+			componentConf, err := conf.Sub(path)
+			if err != nil {
+				report(path, "%v", err)
+				continue
+			}
+			config, err := json.Marshal(componentConf.ToStringMap())
+			if err != nil {
+				report(path, "%v", err)
+				continue
+			}
+			errs, err := validate(kindName(section.kind), id.Type().String(), version, config)
+			if err != nil {
+				report(path, "failed to validate against the %s %q schema: %v", kindName(section.kind), id.Type(), err)
+				continue
+			}
+			for _, schemaErr := range errs {
+				fieldPath := path
+				if schemaErr.Field != "" && schemaErr.Field != "(root)" {
+					fieldPath += confmap.KeyDelimiter + strings.ReplaceAll(schemaErr.Field, ".", confmap.KeyDelimiter)
+				}
+				report(fieldPath, "%s", schemaErr.Message)
+			}
+		}
+	}
+}
+
+// checkUnused reports the components that are defined but not used by any
+// pipeline, and the extensions that are not enabled in the service.
+func checkUnused(conf *confmap.Conf, defined map[string][]string, report reportFunc) {
+	used := map[string]map[string]bool{}
+	use := func(section string, ids []string) {
+		if used[section] == nil {
+			used[section] = map[string]bool{}
+		}
+		for _, id := range ids {
+			used[section][id] = true
+		}
+	}
+	for _, p := range pipelinesFromConfig(conf) {
+		use("receivers", p.Receivers)
+		use("processors", p.Processors)
+		use("exporters", p.Exporters)
+		use("connectors", p.Receivers)
+		use("connectors", p.Exporters)
+	}
+	use("extensions", stringList(conf.Get("service::extensions")))
+
+	for _, section := range componentSections {
+		for _, id := range defined[section.name] {
+			if used[section.name][id] {
+				continue
+			}
+			path := section.name + confmap.KeyDelimiter + id
+			if section.kind == component.KindExtension {
+				report(path, "extension %q is not enabled in service::extensions", id)
+				continue
+			}
+			report(path, "%s %q is not used in any pipeline", kindName(section.kind), id)
+		}
+	}
+}
+
+// connectorUse is a use of a connector in a pipeline.
+type connectorUse struct {
+	path   string
+	signal pipeline.Signal
+}
+
+// checkConnectors checks that connectors are used both as an exporter and as
+// a receiver and, when the factories of a running collector are available,
+// that each use is paired with a use of a signal the connector supports.
+func checkConnectors(state CollectorState, conf *confmap.Conf, defined map[string][]string, report reportFunc) {
+	asExporter := map[string][]connectorUse{}
+	asReceiver := map[string][]connectorUse{}
+	for _, p := range pipelinesFromConfig(conf) {
+		var pipelineID pipeline.ID
+		if err := pipelineID.UnmarshalText([]byte(p.ID)); err != nil {
+			continue
+		}
+		path := "service::pipelines::" + p.ID
+		for i, id := range p.Exporters {
+			if slices.Contains(defined["connectors"], id) {
+				asExporter[id] = append(asExporter[id], connectorUse{path: fmt.Sprintf("%s::exporters::%d", path, i), signal: pipelineID.Signal()})
+			}
+		}
+		for i, id := range p.Receivers {
+			if slices.Contains(defined["connectors"], id) {
+				asReceiver[id] = append(asReceiver[id], connectorUse{path: fmt.Sprintf("%s::receivers::%d", path, i), signal: pipelineID.Signal()})
+			}
+		}
+	}
+
+	for _, id := range defined["connectors"] {
+		exporterUses, receiverUses := asExporter[id], asReceiver[id]
+		switch {
+		case len(exporterUses) == 0 && len(receiverUses) == 0:
+			continue
+		case len(receiverUses) == 0:
+			report(exporterUses[0].path, "connector %q is used as an exporter but not as a receiver in any pipeline", id)
+			continue
+		case len(exporterUses) == 0:
+			report(receiverUses[0].path, "connector %q is used as a receiver but not as an exporter in any pipeline", id)
+			continue
+		}
+
+		factory := connectorFactory(state, id)
+		if factory == nil {
+			continue
+		}
+		for _, exporterUse := range exporterUses {
+			if !slices.ContainsFunc(receiverUses, func(receiverUse connectorUse) bool {
+				return connectorSupports(factory, exporterUse.signal, receiverUse.signal)
+			}) {
+				report(exporterUse.path, "connector %q used as exporter in %s pipeline but not used in any supported receiver pipeline", id, exporterUse.signal)
+			}
+		}
+		for _, receiverUse := range receiverUses {
+			if !slices.ContainsFunc(exporterUses, func(exporterUse connectorUse) bool {
+				return connectorSupports(factory, exporterUse.signal, receiverUse.signal)
+			}) {
+				report(receiverUse.path, "connector %q used as receiver in %s pipeline but not used in any supported exporter pipeline", id, receiverUse.signal)
+			}
+		}
+	}
+}
+
+func connectorFactory(state CollectorState, key string) connector.Factory {
+	if state == nil {
+		return nil
+	}
+	var id component.ID
+	if err := id.UnmarshalText([]byte(key)); err != nil {
+		return nil
+	}
+	factory, _ := state.Factory(component.KindConnector, id.Type()).(connector.Factory)
+	return factory
+}
+
+// connectorSupports returns whether the connector converts the from signal
+// to the to signal. Signals that the connector API does not describe, such as
+// profiles, are assumed to be supported.
+func connectorSupports(factory connector.Factory, from, to pipeline.Signal) bool {
+	var stability component.StabilityLevel
+	switch {
+	case from == pipeline.SignalTraces && to == pipeline.SignalTraces:
+		stability = factory.TracesToTracesStability()
+	case from == pipeline.SignalTraces && to == pipeline.SignalMetrics:
+		stability = factory.TracesToMetricsStability()
+	case from == pipeline.SignalTraces && to == pipeline.SignalLogs:
+		stability = factory.TracesToLogsStability()
+	case from == pipeline.SignalMetrics && to == pipeline.SignalTraces:
+		stability = factory.MetricsToTracesStability()
+	case from == pipeline.SignalMetrics && to == pipeline.SignalMetrics:
+		stability = factory.MetricsToMetricsStability()
+	case from == pipeline.SignalMetrics && to == pipeline.SignalLogs:
+		stability = factory.MetricsToLogsStability()
+	case from == pipeline.SignalLogs && to == pipeline.SignalTraces:
+		stability = factory.LogsToTracesStability()
+	case from == pipeline.SignalLogs && to == pipeline.SignalMetrics:
+		stability = factory.LogsToMetricsStability()
+	case from == pipeline.SignalLogs && to == pipeline.SignalLogs:
+		stability = factory.LogsToLogsStability()
+	default:
+		return true
+	}
+	return stability != component.StabilityLevelUndefined
+}
+
+// checkExtensionReferences checks that the extensions referenced by the
+// storage and auth settings of the components are configured and enabled.
+func checkExtensionReferences(conf *confmap.Conf, defined map[string][]string, report reportFunc) {
+	enabled := stringList(conf.Get("service::extensions"))
+	for _, section := range componentSections {
+		for _, key := range defined[section.name] {
+			path := section.name + confmap.KeyDelimiter + key
+			componentConf, err := conf.Sub(path)
+			if err != nil {
+				continue
+			}
+			walkExtensionReferences(componentConf.ToStringMap(), path, func(refPath, extensionID string) {
+				switch {
+				case !slices.Contains(defined["extensions"], extensionID):
+					report(refPath, "references extension %q which is not configured", extensionID)
+				case !slices.Contains(enabled, extensionID):
+					report(refPath, "references extension %q which is not enabled in service::extensions", extensionID)
+				}
+			})
+		}
+	}
+}
+
+// walkExtensionReferences calls fn for each "storage" and "authenticator"
+// setting of a component configuration, which reference extensions by ID.
+func walkExtensionReferences(value any, path string, fn func(path, extensionID string)) {
+	switch v := value.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+		for _, key := range keys {
+			keyPath := path + confmap.KeyDelimiter + key
+			if id, ok := v[key].(string); ok && id != "" && (key == "storage" || key == "authenticator") {
+				fn(keyPath, id)
+				continue
+			}
+			walkExtensionReferences(v[key], keyPath, fn)
+		}
+	case []any:
+		for i, item := range v {
+			walkExtensionReferences(item, path+confmap.KeyDelimiter+strconv.Itoa(i), fn)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !windows
+
+package tools
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/connector"
+)
+
+const lintTestConfig = `receivers:
+  otlp:
+    endpoint: ${env:OTLP_ENDPOINT}
+  otlp/unused:
+  zipkin:
+processors:
+  batch:
+    timeout: fast
+exporters:
+  otlp/backend:
+    sending_queue:
+      storage: file_storage/queue
+    auth:
+      authenticator: oauth2client
+connectors:
+  count:
+  forward:
+extensions:
+  file_storage/queue:
+  health_check:
+service:
+  extensions: [health_check]
+  pipelines:
+    traces:
+      receivers: [otlp, zipkin]
+      processors: [batch]
+      exporters: [otlp/backend, count, forward]
+    logs:
+      receivers: [count]
+      exporters: [otlp/backend]
+`
+
+func fakeSchemaValidator(kind, name, version string, config []byte) ([]schemaError, error) {
+	if version != "0.138.0" {
+		return nil, errors.New("unexpected version")
+	}
+	if kind == "receiver" && name == "zipkin" {
+		return nil, errors.New("component not found")
+	}
+	var values map[string]any
+	if err := json.Unmarshal(config, &values); err != nil {
+		return nil, err
+	}
+	if kind == "processor" && values["timeout"] == "fast" {
+		return []schemaError{{Field: "timeout", Message: "Invalid type. Expected: duration, given: string"}}, nil
+	}
+	return nil, nil
+}
+
+func newLintState(t *testing.T) *fakeState {
+	state := newFakeState(t, nil)
+	state.factories[component.KindConnector]["count"] = connector.NewFactory(
+		component.MustNewType("count"),
+		func() component.Config { return &fakeConfig{Endpoint: "localhost:4317"} },
+		connector.WithTracesToMetrics(nil, component.StabilityLevelDevelopment),
+	)
+	return state
+}
+
+func TestLintConfig(t *testing.T) {
+	result := lintConfig(t.Context(), newLintState(t), fakeSchemaValidator, "0.138.0", lintTestConfig, map[string]string{
+		"OTLP_ENDPOINT": "localhost:4317",
+	})
+
+	assert.False(t, result.Valid)
+	assert.Equal(t, []diagnostic{
+		{Severity: severityWarning, Path: "receivers::otlp/unused", Line: 4, Message: `receiver "otlp/unused" is not used in any pipeline`},
+		{Severity: severityError, Path: "receivers::zipkin", Line: 5, Message: `failed to validate against the receiver "zipkin" schema: component not found`},
+		{Severity: severityError, Path: "processors::batch::timeout", Line: 8, Message: "Invalid type. Expected: duration, given: string"},
+		{Severity: severityError, Path: "exporters::otlp/backend::sending_queue::storage", Line: 12, Message: `references extension "file_storage/queue" which is not enabled in service::extensions`},
+		{Severity: severityError, Path: "exporters::otlp/backend::auth::authenticator", Line: 14, Message: `references extension "oauth2client" which is not configured`},
+		{Severity: severityWarning, Path: "extensions::file_storage/queue", Line: 19, Message: `extension "file_storage/queue" is not enabled in service::extensions`},
+		{Severity: severityError, Path: "service::pipelines::traces::exporters::1", Line: 27, Message: `connector "count" used as exporter in traces pipeline but not used in any supported receiver pipeline`},
+		{Severity: severityError, Path: "service::pipelines::traces::exporters::2", Line: 27, Message: `connector "forward" is used as an exporter but not as a receiver in any pipeline`},
+		{Severity: severityError, Path: "service::pipelines::logs::receivers::0", Line: 29, Message: `connector "count" used as receiver in logs pipeline but not used in any supported exporter pipeline`},
+	}, result.Diagnostics)
+}
+
+func TestLintConfigValid(t *testing.T) {
+	config := `receivers:
+  otlp:
+exporters:
+  otlp:
+    endpoint: ${env:BACKEND:-backend:4317}
+connectors:
+  count:
+extensions:
+  health_check:
+service:
+  extensions: [health_check]
+  pipelines:
+    traces:
+      receivers: [otlp]
+      exporters: [count]
+    metrics:
+      receivers: [count]
+      exporters: [otlp]
+`
+	result := lintConfig(t.Context(), newLintState(t), fakeSchemaValidator, "0.138.0", config, nil)
+	assert.True(t, result.Valid)
+	assert.Empty(t, result.Diagnostics)
+}
+
+func TestLintConfigWithoutFactories(t *testing.T) {
+	config := `receivers:
+  otlp:
+    endpoint: ${OTLP_ENDPOINT}
+exporters:
+  debug:
+service:
+  pipelines:
+    traces:
+      receivers: [otlp]
+      processors: [batch]
+      exporters: [debug]
+`
+	result := lintConfig(t.Context(), nil, nil, "", config, nil)
+	assert.False(t, result.Valid)
+	assert.Equal(t, []diagnostic{
+		{Severity: severityWarning, Message: `environment variable "OTLP_ENDPOINT" is not set, it resolves to an empty value`},
+		{Severity: severityError, Path: "service::pipelines::traces::processors::0", Line: 10, Message: `references processor "batch" which is not configured`},
+	}, result.Diagnostics)
+}
+
+func TestLintConfigSyntaxError(t *testing.T) {
+	result := lintConfig(t.Context(), nil, nil, "", "receivers:\n  otlp:\n endpoint: [", nil)
+	assert.False(t, result.Valid)
+	require.Len(t, result.Diagnostics, 1)
+	assert.Equal(t, severityError, result.Diagnostics[0].Severity)
+	assert.Equal(t, 2, result.Diagnostics[0].Line)
+}
+
+func TestLintConfigTool(t *testing.T) {
+	tool := getLintConfigTool(newLintState(t), fakeSchemaValidator, "0.138.0")
+
+	result, err := tool.Handler(t.Context(), newRequest(map[string]any{
+		"config": "receivers:\n  otlp:\n    endpoint: ${env:OTLP_ENDPOINT}\nexporters:\n  debug:\nservice:\n  pipelines:\n    traces:\n      receivers: [otlp]\n      exporters: [debug]\n",
+		"env":    map[string]any{"OTLP_ENDPOINT": "localhost:4317"},
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	assert.JSONEq(t, `{"valid": true, "diagnostics": []}`, resultText(t, result))
+
+	result, err = tool.Handler(t.Context(), newRequest(nil))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+}
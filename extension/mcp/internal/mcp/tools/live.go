@@ -0,0 +1,319 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package tools // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/mcp/internal/mcp/tools"
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/pipeline"
+)
+
+// CollectorState gives the live tools access to the running collector.
+type CollectorState interface {
+	// EffectiveConfig returns the configuration the collector is running
+	// with, or nil if the collector has not notified it yet.
+	EffectiveConfig() *confmap.Conf
+	// Components returns the component instances reported by the collector,
+	// with their latest status.
+	Components() []ComponentInstance
+	// Factory returns the factory of a component type in the running build,
+	// or nil if the build does not include it.
+	Factory(kind component.Kind, componentType component.Type) component.Factory
+}
+
+// ComponentInstance is a component instance of the running collector.
+type ComponentInstance struct {
+	ID        string   `json:"id"`
+	Kind      string   `json:"kind"`
+	Pipelines []string `json:"pipelines,omitempty"`
+	Status    string   `json:"status"`
+	Error     string   `json:"error,omitempty"`
+}
+
+// GetLiveTools returns the tools that inspect the running collector.
+// metricsEndpoint is the URL of the collector's own Prometheus metrics; when
+// empty, it is derived from the effective configuration.
+func GetLiveTools(state CollectorState, metricsEndpoint string) []Tool {
+	return []Tool{
+		getPipelinesTool(state),
+		getSampleTelemetryTool(state),
+		getExporterQueuesTool(state, metricsEndpoint),
+		getValidateConfigTool(state),
+	}
+}
+
+// componentSections maps the top-level configuration sections to the kind
+// of the components they define.
+var componentSections = []struct {
+	name string
+	kind component.Kind
+}{
+	{"receivers", component.KindReceiver},
+	{"processors", component.KindProcessor},
+	{"exporters", component.KindExporter},
+	{"connectors", component.KindConnector},
+	{"extensions", component.KindExtension},
+}
+
+type pipelineInfo struct {
+	ID         string   `json:"id"`
+	Receivers  []string `json:"receivers"`
+	Processors []string `json:"processors"`
+	Exporters  []string `json:"exporters"`
+}
+
+// pipelinesFromConfig returns the pipelines of the service section of conf,
+// sorted by ID.
+func pipelinesFromConfig(conf *confmap.Conf) []pipelineInfo {
+	if conf == nil {
+		return nil
+	}
+	pipelinesConf, err := conf.Sub("service::pipelines")
+	if err != nil {
+		return nil
+	}
+	var pipelines []pipelineInfo
+	for id := range pipelinesConf.ToStringMap() {
+		pipelines = append(pipelines, pipelineInfo{
+			ID:         id,
+			Receivers:  stringList(pipelinesConf.Get(id + "::receivers")),
+			Processors: stringList(pipelinesConf.Get(id + "::processors")),
+			Exporters:  stringList(pipelinesConf.Get(id + "::exporters")),
+		})
+	}
+	sort.Slice(pipelines, func(i, j int) bool { return pipelines[i].ID < pipelines[j].ID })
+	return pipelines
+}
+
+func stringList(v any) []string {
+	raw, ok := v.([]any)
+	if !ok {
+		return []string{}
+	}
+	result := make([]string, 0, len(raw))
+	for _, item := range raw {
+		if s, ok := item.(string); ok {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+func getPipelinesTool(state CollectorState) Tool {
+	tool := &mcp.Tool{
+		Name:        "pipelines",
+		Description: "List the pipelines of the running collector with their receivers, processors and exporters, and the running component instances with their status",
+		Annotations: &mcp.ToolAnnotations{
+			DestructiveHint: boolPtr(false),
+			OpenWorldHint:   boolPtr(false),
+		},
+		InputSchema: json.RawMessage(`{"type":"object","properties":{}}`),
+	}
+
+	handler := func(_ context.Context, _ *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return jsonResult(struct {
+			Pipelines  []pipelineInfo      `json:"pipelines"`
+			Components []ComponentInstance `json:"components"`
+		}{
+			Pipelines:  pipelinesFromConfig(state.EffectiveConfig()),
+			Components: state.Components(),
+		}), nil
+	}
+
+	return Tool{Tool: tool, Handler: handler}
+}
+
+type configError struct {
+	Path  string `json:"path"`
+	Error string `json:"error"`
+}
+
+type configValidation struct {
+	Valid  bool          `json:"valid"`
+	Errors []configError `json:"errors"`
+}
+
+func getValidateConfigTool(state CollectorState) Tool {
+	tool := &mcp.Tool{
+		Name:        "validate-config",
+		Description: "Validate a candidate collector configuration against the component factories of the running collector build. Each component configuration is unmarshaled and validated as the collector would at startup, and the pipelines are checked to only reference defined components. ${...} references are not resolved.",
+		Annotations: &mcp.ToolAnnotations{
+			DestructiveHint: boolPtr(false),
+			OpenWorldHint:   boolPtr(false),
+		},
+		InputSchema: json.RawMessage(`{"type":"object","properties":{"config":{"type":"string","description":"The collector configuration in YAML"}},"required":["config"]}`),
+	}
+
+	handler := func(_ context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := parseArgs(req)
+		yamlConfig, ok := requireStringArg(args, "config")
+		if !ok {
+			return errResult("config argument is required"), nil
+		}
+		retrieved, err := confmap.NewRetrievedFromYAML([]byte(yamlConfig))
+		if err != nil {
+			return errResult("failed to parse config: %v", err), nil
+		}
+		conf, err := retrieved.AsConf()
+		if err != nil {
+			return errResult("failed to parse config: %v", err), nil
+		}
+		return jsonResult(validateConfig(state, conf)), nil
+	}
+
+	return Tool{Tool: tool, Handler: handler}
+}
+
+// validateConfig validates the components defined in conf with the factories
+// of the running build, and checks that the pipelines and the service only
+// reference defined components.
+func validateConfig(state CollectorState, conf *confmap.Conf) configValidation {
+	result := configValidation{Errors: []configError{}}
+	addError := func(path, format string, args ...any) {
+		result.Errors = append(result.Errors, configError{Path: path, Error: fmt.Sprintf(format, args...)})
+	}
+
+	checkComponents(state, conf, addError, true)
+	checkReferences(conf, definedComponents(conf), addError)
+
+	result.Valid = len(result.Errors) == 0
+	return result
+}
+
+// reportFunc reports a problem found at a configuration path.
+type reportFunc func(path, format string, args ...any)
+
+// definedComponents returns the sorted IDs of the components defined in each
+// section of conf.
+func definedComponents(conf *confmap.Conf) map[string][]string {
+	defined := map[string][]string{}
+	for _, section := range componentSections {
+		sectionConf, err := conf.Sub(section.name)
+		if err != nil {
+			continue
+		}
+		keys := make([]string, 0)
+		for key := range sectionConf.ToStringMap() {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		defined[section.name] = keys
+	}
+	return defined
+}
+
+// checkComponents unmarshals and validates the configuration of each
+// component of conf with the factories of the running build, as the collector
+// does at startup. Components whose type is not included in the build are
+// reported when reportUnknown is set, and skipped otherwise.
+func checkComponents(state CollectorState, conf *confmap.Conf, report reportFunc, reportUnknown bool) {
+	for _, section := range componentSections {
+		sectionConf, err := conf.Sub(section.name)
+		if err != nil {
+			report(section.name, "%v", err)
+			continue
+		}
+		for _, key := range definedComponents(conf)[section.name] {
+			path := section.name + confmap.KeyDelimiter + key
+			var id component.ID
+			if err := id.UnmarshalText([]byte(key)); err != nil {
+				report(path, "invalid component ID: %v", err)
+				continue
+			}
+			factory := state.Factory(section.kind, id.Type())
+			if factory == nil {
+				if reportUnknown {
+					report(path, "unknown %s type %q, it is not included in this collector build", kindName(section.kind), id.Type())
+				}
+				continue
+			}
+			componentConf, err := sectionConf.Sub(key)
+			if err != nil {
+				report(path, "%v", err)
+				continue
+			}
+			cfg := factory.CreateDefaultConfig()
+			if err := componentConf.Unmarshal(cfg); err != nil {
+				report(path, "%v", err)
+				continue
+			}
+			if err := confmap.Validate(cfg); err != nil {
+				report(path, "%v", err)
+			}
+		}
+	}
+}
+
+// checkReferences checks that the pipelines and the service only reference
+// defined components.
+func checkReferences(conf *confmap.Conf, defined map[string][]string, report reportFunc) {
+	isDefined := func(id string, sections ...string) bool {
+		for _, section := range sections {
+			if slices.Contains(defined[section], id) {
+				return true
+			}
+		}
+		return false
+	}
+
+	for i, extensionID := range stringList(conf.Get("service::extensions")) {
+		if !isDefined(extensionID, "extensions") {
+			report(fmt.Sprintf("service::extensions::%d", i), "references extension %q which is not configured", extensionID)
+		}
+	}
+
+	for _, p := range pipelinesFromConfig(conf) {
+		path := "service::pipelines::" + p.ID
+		var pipelineID pipeline.ID
+		if err := pipelineID.UnmarshalText([]byte(p.ID)); err != nil {
+			report(path, "invalid pipeline ID: %v", err)
+		}
+		if len(p.Receivers) == 0 {
+			report(path, "must have at least one receiver")
+		}
+		if len(p.Exporters) == 0 {
+			report(path, "must have at least one exporter")
+		}
+		for i, id := range p.Receivers {
+			if !isDefined(id, "receivers", "connectors") {
+				report(fmt.Sprintf("%s::receivers::%d", path, i), "references receiver %q which is not configured", id)
+			}
+		}
+		for i, id := range p.Processors {
+			if !isDefined(id, "processors") {
+				report(fmt.Sprintf("%s::processors::%d", path, i), "references processor %q which is not configured", id)
+			}
+		}
+		for i, id := range p.Exporters {
+			if !isDefined(id, "exporters", "connectors") {
+				report(fmt.Sprintf("%s::exporters::%d", path, i), "references exporter %q which is not configured", id)
+			}
+		}
+	}
+}
+
+// kindName returns the lowercase name of kind, as used in the configuration.
+func kindName(kind component.Kind) string {
+	switch kind {
+	case component.KindReceiver:
+		return "receiver"
+	case component.KindProcessor:
+		return "processor"
+	case component.KindExporter:
+		return "exporter"
+	case component.KindConnector:
+		return "connector"
+	case component.KindExtension:
+		return "extension"
+	default:
+		return kind.String()
+	}
+}
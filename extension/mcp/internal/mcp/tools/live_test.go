@@ -0,0 +1,351 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !windows
+
+package tools
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap"
+	"golang.org/x/net/websocket"
+)
+
+type fakeState struct {
+	conf       *confmap.Conf
+	components []ComponentInstance
+	factories  map[component.Kind]map[string]component.Factory
+}
+
+func (s *fakeState) EffectiveConfig() *confmap.Conf {
+	return s.conf
+}
+
+func (s *fakeState) Components() []ComponentInstance {
+	return s.components
+}
+
+func (s *fakeState) Factory(kind component.Kind, componentType component.Type) component.Factory {
+	return s.factories[kind][componentType.String()]
+}
+
+type fakeConfig struct {
+	Endpoint string         `mapstructure:"endpoint"`
+	Settings map[string]any `mapstructure:",remain"`
+}
+
+func (c *fakeConfig) Validate() error {
+	if c.Endpoint == "" {
+		return errors.New("endpoint must be specified")
+	}
+	return nil
+}
+
+type fakeFactory struct {
+	componentType component.Type
+}
+
+func (f fakeFactory) Type() component.Type {
+	return f.componentType
+}
+
+func (fakeFactory) CreateDefaultConfig() component.Config {
+	return &fakeConfig{Endpoint: "localhost:4317"}
+}
+
+func newFakeState(t *testing.T, conf map[string]any) *fakeState {
+	t.Helper()
+	factories := map[component.Kind]map[string]component.Factory{}
+	for kind, types := range map[component.Kind][]string{
+		component.KindReceiver:  {"otlp"},
+		component.KindProcessor: {"batch", "remotetap"},
+		component.KindExporter:  {"debug", "otlp"},
+		component.KindConnector: {"forward"},
+		component.KindExtension: {"health_check"},
+	} {
+		factories[kind] = map[string]component.Factory{}
+		for _, typ := range types {
+			factories[kind][typ] = fakeFactory{componentType: component.MustNewType(typ)}
+		}
+	}
+	state := &fakeState{factories: factories}
+	if conf != nil {
+		state.conf = confmap.NewFromStringMap(conf)
+	}
+	return state
+}
+
+func resultText(t *testing.T, result *mcp.CallToolResult) string {
+	t.Helper()
+	require.Len(t, result.Content, 1)
+	text, ok := result.Content[0].(*mcp.TextContent)
+	require.True(t, ok)
+	return text.Text
+}
+
+func TestGetLiveTools(t *testing.T) {
+	tools := GetLiveTools(newFakeState(t, nil), "")
+	names := make([]string, 0, len(tools))
+	for _, tool := range tools {
+		names = append(names, tool.Tool.Name)
+		assert.NotNil(t, tool.Handler)
+	}
+	assert.ElementsMatch(t, []string{"pipelines", "sample-telemetry", "exporter-queues", "validate-config"}, names)
+}
+
+func TestPipelinesTool(t *testing.T) {
+	state := newFakeState(t, map[string]any{
+		"service": map[string]any{
+			"pipelines": map[string]any{
+				"traces": map[string]any{
+					"receivers":  []any{"otlp"},
+					"processors": []any{"batch"},
+					"exporters":  []any{"otlp/backend", "forward"},
+				},
+				"logs/tap": map[string]any{
+					"receivers": []any{"forward"},
+					"exporters": []any{"debug"},
+				},
+			},
+		},
+	})
+	state.components = []ComponentInstance{
+		{ID: "otlp", Kind: "receiver", Pipelines: []string{"traces"}, Status: "StatusOK"},
+		{ID: "otlp/backend", Kind: "exporter", Pipelines: []string{"traces"}, Status: "StatusRecoverableError", Error: "connection refused"},
+	}
+
+	result, err := getPipelinesTool(state).Handler(t.Context(), newRequest(nil))
+	require.NoError(t, err)
+	assert.False(t, result.IsError)
+	assert.JSONEq(t, `{
+		"pipelines": [
+			{"id": "logs/tap", "receivers": ["forward"], "processors": [], "exporters": ["debug"]},
+			{"id": "traces", "receivers": ["otlp"], "processors": ["batch"], "exporters": ["otlp/backend", "forward"]}
+		],
+		"components": [
+			{"id": "otlp", "kind": "receiver", "pipelines": ["traces"], "status": "StatusOK"},
+			{"id": "otlp/backend", "kind": "exporter", "pipelines": ["traces"], "status": "StatusRecoverableError", "error": "connection refused"}
+		]
+	}`, resultText(t, result))
+}
+
+func TestValidateConfigTool(t *testing.T) {
+	tool := getValidateConfigTool(newFakeState(t, nil))
+
+	tests := []struct {
+		name     string
+		config   string
+		expected configValidation
+	}{
+		{
+			name: "valid",
+			config: `
+receivers:
+  otlp:
+exporters:
+  otlp/backend:
+    endpoint: backend:4317
+extensions:
+  health_check:
+service:
+  extensions: [health_check]
+  pipelines:
+    traces:
+      receivers: [otlp]
+      exporters: [otlp/backend]
+`,
+			expected: configValidation{Valid: true, Errors: []configError{}},
+		},
+		{
+			name: "invalid",
+			config: `
+receivers:
+  otlp:
+    endpoint: ""
+  zipkin:
+processors:
+  batch:
+    endpoint: [a, b]
+exporters:
+  debug:
+service:
+  extensions: [pprof]
+  pipelines:
+    traces:
+      receivers: [otlp, forward]
+      processors: [batch, memory_limiter]
+      exporters: [debug]
+    metrics:
+      receivers: [otlp]
+`,
+			expected: configValidation{Errors: []configError{
+				{Path: "receivers::otlp", Error: "endpoint must be specified"},
+				{Path: "receivers::zipkin", Error: `unknown receiver type "zipkin", it is not included in this collector build`},
+				{Path: "processors::batch", Error: "decoding failed due to the following error(s):\n\n'endpoint' expected type 'string', got unconvertible type '[]interface {}'"},
+				{Path: "service::extensions::0", Error: `references extension "pprof" which is not configured`},
+				{Path: "service::pipelines::metrics", Error: "must have at least one exporter"},
+				{Path: "service::pipelines::traces::receivers::1", Error: `references receiver "forward" which is not configured`},
+				{Path: "service::pipelines::traces::processors::1", Error: `references processor "memory_limiter" which is not configured`},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tool.Handler(t.Context(), newRequest(map[string]any{"config": tt.config}))
+			require.NoError(t, err)
+			require.False(t, result.IsError, resultText(t, result))
+			var validation configValidation
+			require.NoError(t, json.Unmarshal([]byte(resultText(t, result)), &validation))
+			assert.Equal(t, tt.expected, validation)
+		})
+	}
+
+	result, err := tool.Handler(t.Context(), newRequest(nil))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+}
+
+func TestSampleTelemetryTool(t *testing.T) {
+	server := httptest.NewServer(websocket.Server{Handler: func(conn *websocket.Conn) {
+		for i := range 5 {
+			if err := websocket.Message.Send(conn, fmt.Sprintf(`{"resourceSpans":[],"batch":%d}`, i)); err != nil {
+				return
+			}
+		}
+	}})
+	defer server.Close()
+	endpoint := strings.TrimPrefix(server.URL, "http://")
+
+	state := newFakeState(t, map[string]any{
+		"processors": map[string]any{
+			"remotetap/debug": map[string]any{"endpoint": endpoint},
+		},
+		"service": map[string]any{
+			"pipelines": map[string]any{
+				"traces": map[string]any{
+					"receivers":  []any{"otlp"},
+					"processors": []any{"batch", "remotetap/debug"},
+					"exporters":  []any{"debug"},
+				},
+				"logs": map[string]any{
+					"receivers": []any{"otlp"},
+					"exporters": []any{"debug"},
+				},
+			},
+		},
+	})
+	tool := getSampleTelemetryTool(state)
+
+	result, err := tool.Handler(t.Context(), newRequest(map[string]any{"pipeline": "traces", "limit": 3, "timeout_seconds": 2}))
+	require.NoError(t, err)
+	require.False(t, result.IsError, resultText(t, result))
+	assert.JSONEq(t, `{
+		"pipeline": "traces",
+		"processor": "remotetap/debug",
+		"samples": [
+			{"resourceSpans": [], "batch": 0},
+			{"resourceSpans": [], "batch": 1},
+			{"resourceSpans": [], "batch": 2}
+		]
+	}`, resultText(t, result))
+
+	result, err = tool.Handler(t.Context(), newRequest(map[string]any{"pipeline": "logs"}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, resultText(t, result), `pipeline "logs" has no remotetap processor`)
+
+	result, err = tool.Handler(t.Context(), newRequest(map[string]any{"pipeline": "metrics"}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, resultText(t, result), `pipeline "metrics" is not configured`)
+}
+
+func TestFindRemoteTapDefaultEndpoint(t *testing.T) {
+	conf := confmap.NewFromStringMap(map[string]any{
+		"processors": map[string]any{
+			"remotetap": nil,
+		},
+		"service": map[string]any{
+			"pipelines": map[string]any{
+				"traces": map[string]any{
+					"processors": []any{"remotetap"},
+				},
+			},
+		},
+	})
+	id, endpoint, err := findRemoteTap(conf, "traces")
+	require.NoError(t, err)
+	assert.Equal(t, "remotetap", id)
+	assert.Equal(t, "localhost:12001", endpoint)
+
+	_, _, err = findRemoteTap(nil, "traces")
+	assert.Error(t, err)
+}
+
+func TestExporterQueuesTool(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`# TYPE otelcol_exporter_queue_size gauge
+otelcol_exporter_queue_size{data_type="traces",exporter="otlp/backend"} 42
+# TYPE otelcol_exporter_queue_capacity gauge
+otelcol_exporter_queue_capacity{data_type="traces",exporter="otlp/backend"} 1000
+# TYPE otelcol_exporter_sent_spans_total counter
+otelcol_exporter_sent_spans_total{exporter="otlp/backend"} 1200
+otelcol_exporter_sent_spans_total{exporter="debug"} 10
+# TYPE otelcol_exporter_send_failed_spans_total counter
+otelcol_exporter_send_failed_spans_total{exporter="otlp/backend"} 3
+# TYPE otelcol_receiver_accepted_spans_total counter
+otelcol_receiver_accepted_spans_total{receiver="otlp"} 1300
+`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	result, err := getExporterQueuesTool(newFakeState(t, nil), server.URL+"/metrics").Handler(t.Context(), newRequest(nil))
+	require.NoError(t, err)
+	require.False(t, result.IsError, resultText(t, result))
+	assert.JSONEq(t, `{
+		"exporters": {
+			"otlp/backend": {"queue_size": 42, "queue_capacity": 1000, "sent_spans": 1200, "send_failed_spans": 3},
+			"debug": {"sent_spans": 10}
+		}
+	}`, resultText(t, result))
+
+	result, err = getExporterQueuesTool(newFakeState(t, nil), server.URL+"/missing").Handler(t.Context(), newRequest(nil))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+}
+
+func TestMetricsEndpointFromConfig(t *testing.T) {
+	assert.Equal(t, "http://localhost:8888/metrics", metricsEndpointFromConfig(nil))
+	assert.Equal(t, "http://localhost:8888/metrics", metricsEndpointFromConfig(confmap.New()))
+
+	conf := confmap.NewFromStringMap(map[string]any{
+		"service": map[string]any{
+			"telemetry": map[string]any{
+				"metrics": map[string]any{
+					"readers": []any{
+						map[string]any{"periodic": map[string]any{}},
+						map[string]any{"pull": map[string]any{"exporter": map[string]any{"prometheus": map[string]any{
+							"host": "0.0.0.0",
+							"port": 9999,
+						}}}},
+					},
+				},
+			},
+		},
+	})
+	assert.Equal(t, "http://localhost:9999/metrics", metricsEndpointFromConfig(conf))
+}
@@ -0,0 +1,316 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package tools // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/mcp/internal/mcp/tools"
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/prometheus/common/model"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap"
+	"golang.org/x/net/websocket"
+)
+
+const (
+	remoteTapType            = "remotetap"
+	defaultRemoteTapEndpoint = "localhost:12001"
+
+	defaultMetricsEndpoint = "http://localhost:8888/metrics"
+	exporterMetricPrefix   = "otelcol_exporter_"
+
+	defaultSampleLimit   = 10
+	maxSampleLimit       = 100
+	defaultSampleTimeout = 5 * time.Second
+	maxSampleTimeout     = time.Minute
+)
+
+func intArg(args map[string]any, key string, defaultVal int) int {
+	if v, ok := args[key].(float64); ok && v > 0 {
+		return int(v)
+	}
+	return defaultVal
+}
+
+func getSampleTelemetryTool(state CollectorState) Tool {
+	tool := &mcp.Tool{
+		Name:        "sample-telemetry",
+		Description: "Sample the telemetry flowing through a pipeline of the running collector. The pipeline must include a remotetap processor, which is tapped to collect the batches it publishes, in the OTLP JSON format.",
+		Annotations: &mcp.ToolAnnotations{
+			DestructiveHint: boolPtr(false),
+			OpenWorldHint:   boolPtr(false),
+		},
+		InputSchema: json.RawMessage(`{"type":"object","properties":{"pipeline":{"type":"string","description":"The pipeline ID e.g. traces or logs/backend"},"limit":{"type":"integer","description":"The maximum number of batches to collect, defaults to 10"},"timeout_seconds":{"type":"integer","description":"How long to wait for batches, defaults to 5"}},"required":["pipeline"]}`),
+	}
+
+	handler := func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := parseArgs(req)
+		pipelineID, ok := requireStringArg(args, "pipeline")
+		if !ok {
+			return errResult("pipeline argument is required"), nil
+		}
+		limit := min(intArg(args, "limit", defaultSampleLimit), maxSampleLimit)
+		timeout := min(time.Duration(intArg(args, "timeout_seconds", int(defaultSampleTimeout/time.Second)))*time.Second, maxSampleTimeout)
+
+		processorID, endpoint, err := findRemoteTap(state.EffectiveConfig(), pipelineID)
+		if err != nil {
+			return errResult("%v", err), nil
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		samples, err := tapSamples(ctx, endpoint, limit)
+		if err != nil {
+			return errResult("failed to tap %s at %s: %v", processorID, endpoint, err), nil
+		}
+		return jsonResult(struct {
+			Pipeline  string            `json:"pipeline"`
+			Processor string            `json:"processor"`
+			Samples   []json.RawMessage `json:"samples"`
+		}{
+			Pipeline:  pipelineID,
+			Processor: processorID,
+			Samples:   samples,
+		}), nil
+	}
+
+	return Tool{Tool: tool, Handler: handler}
+}
+
+// findRemoteTap returns the ID and the endpoint of the first remotetap
+// processor of the pipeline.
+func findRemoteTap(conf *confmap.Conf, pipelineID string) (string, string, error) {
+	if conf == nil {
+		return "", "", errors.New("the collector configuration is not available yet")
+	}
+	for _, p := range pipelinesFromConfig(conf) {
+		if p.ID != pipelineID {
+			continue
+		}
+		for _, processorID := range p.Processors {
+			var id component.ID
+			if err := id.UnmarshalText([]byte(processorID)); err != nil || id.Type().String() != remoteTapType {
+				continue
+			}
+			endpoint, _ := conf.Get("processors" + confmap.KeyDelimiter + processorID + confmap.KeyDelimiter + "endpoint").(string)
+			if endpoint == "" {
+				endpoint = defaultRemoteTapEndpoint
+			}
+			return processorID, dialableEndpoint(endpoint), nil
+		}
+		return "", "", fmt.Errorf("pipeline %q has no %s processor, add one to sample its telemetry", pipelineID, remoteTapType)
+	}
+	return "", "", fmt.Errorf("pipeline %q is not configured", pipelineID)
+}
+
+// dialableEndpoint replaces the unspecified host of a listening endpoint,
+// e.g. "0.0.0.0:12001", by localhost.
+func dialableEndpoint(endpoint string) string {
+	host, port, err := net.SplitHostPort(endpoint)
+	if err != nil {
+		return endpoint
+	}
+	if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+		host = "localhost"
+	}
+	return net.JoinHostPort(host, port)
+}
+
+// tapSamples connects to the websocket served by a remotetap processor and
+// collects up to limit messages, until ctx is done.
+func tapSamples(ctx context.Context, endpoint string, limit int) ([]json.RawMessage, error) {
+	wsConfig, err := websocket.NewConfig("ws://"+endpoint, "http://"+endpoint)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := wsConfig.DialContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetReadDeadline(deadline)
+	}
+
+	samples := []json.RawMessage{}
+	for len(samples) < limit {
+		var msg string
+		if err := websocket.Message.Receive(conn, &msg); err != nil {
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				break
+			}
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, err
+		}
+		if !json.Valid([]byte(msg)) {
+			continue
+		}
+		samples = append(samples, json.RawMessage(msg))
+	}
+	return samples, nil
+}
+
+func getExporterQueuesTool(state CollectorState, metricsEndpoint string) Tool {
+	tool := &mcp.Tool{
+		Name:        "exporter-queues",
+		Description: "Report the sending queue size and capacity, and the sent and failed items of each exporter of the running collector, from its own Prometheus metrics",
+		Annotations: &mcp.ToolAnnotations{
+			DestructiveHint: boolPtr(false),
+			OpenWorldHint:   boolPtr(false),
+		},
+		InputSchema: json.RawMessage(`{"type":"object","properties":{}}`),
+	}
+
+	handler := func(ctx context.Context, _ *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		endpoint := metricsEndpoint
+		if endpoint == "" {
+			endpoint = metricsEndpointFromConfig(state.EffectiveConfig())
+		}
+		exporters, err := scrapeExporterMetrics(ctx, endpoint)
+		if err != nil {
+			return errResult("failed to scrape the collector metrics at %s: %v", endpoint, err), nil
+		}
+		return jsonResult(struct {
+			Exporters map[string]map[string]float64 `json:"exporters"`
+		}{Exporters: exporters}), nil
+	}
+
+	return Tool{Tool: tool, Handler: handler}
+}
+
+// metricsEndpointFromConfig returns the URL of the first Prometheus reader
+// of the service telemetry, or the default one of the collector.
+func metricsEndpointFromConfig(conf *confmap.Conf) string {
+	if conf == nil {
+		return defaultMetricsEndpoint
+	}
+	readers, _ := conf.Get("service::telemetry::metrics::readers").([]any)
+	for _, reader := range readers {
+		readerMap, ok := reader.(map[string]any)
+		if !ok {
+			continue
+		}
+		prometheus, ok := nestedMap(readerMap, "pull", "exporter", "prometheus")
+		if !ok {
+			continue
+		}
+		host, _ := prometheus["host"].(string)
+		if host == "" {
+			host = "localhost"
+		}
+		var port string
+		switch p := prometheus["port"].(type) {
+		case int:
+			port = strconv.Itoa(p)
+		case string:
+			port = p
+		default:
+			continue
+		}
+		return "http://" + dialableEndpoint(net.JoinHostPort(host, port)) + "/metrics"
+	}
+	return defaultMetricsEndpoint
+}
+
+func nestedMap(m map[string]any, keys ...string) (map[string]any, bool) {
+	for _, key := range keys {
+		next, ok := m[key].(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		m = next
+	}
+	return m, true
+}
+
+// scrapeExporterMetrics scrapes the exporter metrics of the collector, and
+// returns their values summed by exporter, keyed by metric name without the
+// "otelcol_exporter_" prefix and "_total" suffix, e.g. "queue_size" or
+// "send_failed_spans".
+func scrapeExporterMetrics(ctx context.Context, endpoint string) (map[string]map[string]float64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", string(expfmt.NewFormat(expfmt.TypeTextPlain)))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	parser := expfmt.NewTextParser(model.UTF8Validation)
+	families, err := parser.TextToMetricFamilies(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return exporterMetrics(families), nil
+}
+
+func exporterMetrics(families map[string]*dto.MetricFamily) map[string]map[string]float64 {
+	names := make([]string, 0, len(families))
+	for name := range families {
+		if strings.HasPrefix(name, exporterMetricPrefix) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	exporters := map[string]map[string]float64{}
+	for _, name := range names {
+		metricName := strings.TrimSuffix(strings.TrimPrefix(name, exporterMetricPrefix), "_total")
+		for _, metric := range families[name].GetMetric() {
+			exporter := labelValue(metric, "exporter")
+			if exporter == "" {
+				continue
+			}
+			var value float64
+			switch {
+			case metric.GetCounter() != nil:
+				value = metric.GetCounter().GetValue()
+			case metric.GetGauge() != nil:
+				value = metric.GetGauge().GetValue()
+			case metric.GetUntyped() != nil:
+				value = metric.GetUntyped().GetValue()
+			default:
+				continue
+			}
+			if exporters[exporter] == nil {
+				exporters[exporter] = map[string]float64{}
+			}
+			exporters[exporter][metricName] += value
+		}
+	}
+	return exporters
+}
+
+func labelValue(metric *dto.Metric, name string) string {
+	for _, label := range metric.GetLabel() {
+		if label.GetName() == name {
+			return label.GetValue()
+		}
+	}
+	return ""
+}
@@ -20,8 +20,9 @@ type Tool struct {
 	Handler mcp.ToolHandler
 }
 
-// GetAllTools returns a list of all available MCP tools
-func GetAllTools() ([]Tool, error) {
+// GetAllTools returns a list of all available MCP tools. state gives access
+// to the factories of the running collector, it may be nil.
+func GetAllTools(state CollectorState) ([]Tool, error) {
 	schemaManager := collectorschema.NewSchemaManager()
 	latestCollectorVersion, err := schemaManager.GetLatestVersion()
 	if err != nil {
@@ -37,6 +38,7 @@ func GetAllTools() ([]Tool, error) {
 		getCollectorComponentDeprecatedTool(schemaManager, latestCollectorVersion),
 		getCollectorChangelogTool(schemaManager, latestCollectorVersion),
 		getCollectorDocumentationRAG(schemaManager, latestCollectorVersion),
+		getLintConfigTool(state, newSchemaValidator(schemaManager), latestCollectorVersion),
 	}
 
 	return tools, nil
@@ -274,6 +276,32 @@ func getCollectorSchemaValidationTool(schemaManager *collectorschema.SchemaManag
 	return Tool{Tool: tool, Handler: handler}
 }
 
+// newSchemaValidator returns a schemaValidator validating component
+// configurations with the schemas of schemaManager.
+func newSchemaValidator(schemaManager *collectorschema.SchemaManager) schemaValidator {
+	return func(kind, name, version string, config []byte) ([]schemaError, error) {
+		validationResult, err := schemaManager.ValidateComponentJSON(collectorschema.ComponentType(kind), name, version, config)
+		if err != nil {
+			return nil, err
+		}
+		if validationResult.Valid() {
+			return nil, nil
+		}
+		var errs []schemaError
+		for _, resultErr := range validationResult.Errors() {
+			if fieldErr, ok := any(resultErr).(interface {
+				Field() string
+				Description() string
+			}); ok {
+				errs = append(errs, schemaError{Field: fieldErr.Field(), Message: fieldErr.Description()})
+				continue
+			}
+			errs = append(errs, schemaError{Message: fmt.Sprint(resultErr)})
+		}
+		return errs, nil
+	}
+}
+
 type DeprecatedComponentFields struct {
 	ComponentName    string                            `json:"componentName"`
 	DeprecatedFields []collectorschema.DeprecatedField `json:"deprecatedFields"`
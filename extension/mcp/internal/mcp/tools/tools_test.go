@@ -50,9 +50,9 @@ func versionWithChangelog(t *testing.T, sm *collectorschema.SchemaManager) strin
 }
 
 func TestGetAllTools(t *testing.T) {
-	tools, err := GetAllTools()
+	tools, err := GetAllTools(nil)
 	require.NoError(t, err)
-	assert.Len(t, tools, 8)
+	assert.Len(t, tools, 9)
 
 	names := make([]string, 0, len(tools))
 	for _, tool := range tools {
@@ -67,6 +67,7 @@ func TestGetAllTools(t *testing.T) {
 		"component-deprecated-fields",
 		"changelog",
 		"rag",
+		"lint-config",
 	}, names)
 }
 
@@ -0,0 +1,58 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package tenantsamplingpolicyextension // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/samplingpolicy/tenantsamplingpolicyextension"
+
+import (
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/collector/component"
+)
+
+// Config defines the configuration for the tenant sampling policy extension.
+type Config struct {
+	// TenantAttribute is the resource attribute identifying the tenant of a trace.
+	TenantAttribute string `mapstructure:"tenant_attribute"`
+	// prevent unkeyed literal initialization
+	_ struct{}
+}
+
+var _ component.Config = (*Config)(nil)
+
+// Validate checks if the extension configuration is valid.
+func (cfg *Config) Validate() error {
+	if cfg.TenantAttribute == "" {
+		return errors.New("tenant_attribute must not be empty")
+	}
+	return nil
+}
+
+// policyConfig is the configuration of a tail sampling policy using this
+// extension, forwarded by the processor when the policy is created.
+type policyConfig struct {
+	// DefaultPercentage is the percentage of traces sampled for tenants
+	// not listed in Tenants, and for traces without a tenant.
+	DefaultPercentage float64 `mapstructure:"default_percentage"`
+	// Tenants maps a tenant to the percentage of its traces to sample.
+	Tenants map[string]float64 `mapstructure:"tenants"`
+}
+
+func (cfg *policyConfig) validate() error {
+	if err := validatePercentage(cfg.DefaultPercentage); err != nil {
+		return fmt.Errorf("default_percentage: %w", err)
+	}
+	for tenant, percentage := range cfg.Tenants {
+		if err := validatePercentage(percentage); err != nil {
+			return fmt.Errorf("tenants::%s: %w", tenant, err)
+		}
+	}
+	return nil
+}
+
+func validatePercentage(percentage float64) error {
+	if percentage < 0 || percentage > 100 {
+		return fmt.Errorf("percentage must be between 0 and 100, got %v", percentage)
+	}
+	return nil
+}
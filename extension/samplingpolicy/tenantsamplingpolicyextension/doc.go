@@ -0,0 +1,9 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:generate make mdatagen
+
+// Package tenantsamplingpolicyextension provides an example sampling policy
+// extension for the tail sampling processor, sampling a configurable
+// percentage of traces per tenant.
+package tenantsamplingpolicyextension // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/samplingpolicy/tenantsamplingpolicyextension"
@@ -0,0 +1,87 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package tenantsamplingpolicyextension // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/samplingpolicy/tenantsamplingpolicyextension"
+
+import (
+	"context"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/sampling"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/tailsamplingprocessor/pkg/samplingpolicy"
+)
+
+// tenantSampler samples a percentage of traces that depends on the tenant
+// of the trace. It reports the threshold it applied, so that the processor
+// can propagate it on tracestate.
+type tenantSampler struct {
+	logger           *zap.Logger
+	tenantAttribute  string
+	defaultThreshold sampling.Threshold
+	tenantThresholds map[string]sampling.Threshold
+}
+
+var _ samplingpolicy.ThresholdEvaluator = (*tenantSampler)(nil)
+
+func newTenantSampler(logger *zap.Logger, tenantAttribute string, cfg *policyConfig) *tenantSampler {
+	tenantThresholds := make(map[string]sampling.Threshold, len(cfg.Tenants))
+	for tenant, percentage := range cfg.Tenants {
+		tenantThresholds[tenant] = percentageToThreshold(percentage)
+	}
+	return &tenantSampler{
+		logger:           logger,
+		tenantAttribute:  tenantAttribute,
+		defaultThreshold: percentageToThreshold(cfg.DefaultPercentage),
+		tenantThresholds: tenantThresholds,
+	}
+}
+
+// percentageToThreshold converts a validated percentage to a threshold.
+// Percentages below the minimum sampling probability never sample.
+func percentageToThreshold(percentage float64) sampling.Threshold {
+	threshold, err := sampling.ProbabilityToThreshold(percentage / 100)
+	if err != nil {
+		return sampling.NeverSampleThreshold
+	}
+	return threshold
+}
+
+func (s *tenantSampler) Evaluate(ctx context.Context, traceID pcommon.TraceID, trace *samplingpolicy.TraceData) (samplingpolicy.Decision, error) {
+	decision, _, err := s.EvaluateWithThreshold(ctx, traceID, trace)
+	return decision, err
+}
+
+// EvaluateWithThreshold compares the randomness derived from the trace ID
+// against the threshold of the tenant of the trace.
+func (s *tenantSampler) EvaluateWithThreshold(_ context.Context, traceID pcommon.TraceID, trace *samplingpolicy.TraceData) (samplingpolicy.Decision, sampling.Threshold, error) {
+	s.logger.Debug("Evaluating spans in tenant sampler")
+
+	threshold := s.defaultThreshold
+	if tenant, ok := s.tenant(trace.ReceivedBatches); ok {
+		if tenantThreshold, ok := s.tenantThresholds[tenant]; ok {
+			threshold = tenantThreshold
+		}
+	}
+
+	if threshold.ShouldSample(sampling.TraceIDToRandomness(traceID)) {
+		return samplingpolicy.Sampled, threshold, nil
+	}
+	return samplingpolicy.NotSampled, threshold, nil
+}
+
+func (*tenantSampler) IsStateful() bool {
+	return false
+}
+
+// tenant returns the tenant of the first resource carrying the tenant attribute.
+func (s *tenantSampler) tenant(td ptrace.Traces) (string, bool) {
+	for _, rs := range td.ResourceSpans().All() {
+		if value, ok := rs.Resource().Attributes().Get(s.tenantAttribute); ok {
+			return value.AsString(), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,50 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package tenantsamplingpolicyextension // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/samplingpolicy/tenantsamplingpolicyextension"
+
+import (
+	"fmt"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/extension"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/tailsamplingprocessor/pkg/samplingpolicy"
+)
+
+type tenantSamplingPolicyExtension struct {
+	component.StartFunc
+	component.ShutdownFunc
+
+	settings extension.Settings
+	cfg      *Config
+}
+
+var (
+	_ extension.Extension      = (*tenantSamplingPolicyExtension)(nil)
+	_ samplingpolicy.Extension = (*tenantSamplingPolicyExtension)(nil)
+)
+
+func newExtension(settings extension.Settings, cfg *Config) *tenantSamplingPolicyExtension {
+	return &tenantSamplingPolicyExtension{
+		settings: settings,
+		cfg:      cfg,
+	}
+}
+
+// NewEvaluator implements samplingpolicy.Extension. It is called by the tail
+// sampling processor on start for every policy using this extension, so an
+// invalid policy configuration prevents the processor from starting.
+func (e *tenantSamplingPolicyExtension) NewEvaluator(policyName string, cfg map[string]any) (samplingpolicy.Evaluator, error) {
+	var policyCfg policyConfig
+	if err := confmap.NewFromStringMap(cfg).Unmarshal(&policyCfg); err != nil {
+		return nil, fmt.Errorf("invalid config for policy %q: %w", policyName, err)
+	}
+	if err := policyCfg.validate(); err != nil {
+		return nil, fmt.Errorf("invalid config for policy %q: %w", policyName, err)
+	}
+	logger := e.settings.Logger.With(zap.String("policy", policyName))
+	return newTenantSampler(logger, e.cfg.TenantAttribute, &policyCfg), nil
+}
@@ -0,0 +1,140 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package tenantsamplingpolicyextension
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/extension/extensiontest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/samplingpolicy/tenantsamplingpolicyextension/internal/metadata"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/sampling"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/tailsamplingprocessor/pkg/samplingpolicy"
+)
+
+func TestNewEvaluatorInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		cfg         map[string]any
+		expectedErr string
+	}{
+		{
+			name:        "default percentage out of range",
+			cfg:         map[string]any{"default_percentage": 101},
+			expectedErr: `invalid config for policy "tenants": default_percentage: percentage must be between 0 and 100, got 101`,
+		},
+		{
+			name:        "tenant percentage out of range",
+			cfg:         map[string]any{"tenants": map[string]any{"acme": -1}},
+			expectedErr: `invalid config for policy "tenants": tenants::acme: percentage must be between 0 and 100, got -1`,
+		},
+		{
+			name:        "unknown field",
+			cfg:         map[string]any{"percentage": 10},
+			expectedErr: "has invalid keys: percentage",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ext := newTestExtension()
+			_, err := ext.NewEvaluator("tenants", tt.cfg)
+			require.ErrorContains(t, err, tt.expectedErr)
+		})
+	}
+}
+
+func TestTenantSampler(t *testing.T) {
+	ext := newTestExtension()
+	evaluator, err := ext.NewEvaluator("tenants", map[string]any{
+		"default_percentage": 0,
+		"tenants": map[string]any{
+			"gold":   100,
+			"silver": 25,
+		},
+	})
+	require.NoError(t, err)
+	thresholdEvaluator, ok := evaluator.(samplingpolicy.ThresholdEvaluator)
+	require.True(t, ok)
+	assert.False(t, evaluator.IsStateful())
+
+	quarter, err := sampling.ProbabilityToThreshold(0.25)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name              string
+		tenant            string
+		traceID           pcommon.TraceID
+		expectedDecision  samplingpolicy.Decision
+		expectedThreshold sampling.Threshold
+	}{
+		{
+			name:              "tenant sampling everything",
+			tenant:            "gold",
+			traceID:           pcommon.TraceID{15: 1},
+			expectedDecision:  samplingpolicy.Sampled,
+			expectedThreshold: sampling.AlwaysSampleThreshold,
+		},
+		{
+			name:              "tenant sampling a quarter with high randomness",
+			tenant:            "silver",
+			traceID:           pcommon.TraceID{9: 0xff, 10: 0xff, 11: 0xff, 12: 0xff, 13: 0xff, 14: 0xff, 15: 0xff},
+			expectedDecision:  samplingpolicy.Sampled,
+			expectedThreshold: quarter,
+		},
+		{
+			name:              "tenant sampling a quarter with low randomness",
+			tenant:            "silver",
+			traceID:           pcommon.TraceID{15: 1},
+			expectedDecision:  samplingpolicy.NotSampled,
+			expectedThreshold: quarter,
+		},
+		{
+			name:              "unknown tenant uses the default percentage",
+			tenant:            "bronze",
+			traceID:           pcommon.TraceID{9: 0xff, 10: 0xff, 11: 0xff, 12: 0xff, 13: 0xff, 14: 0xff, 15: 0xff},
+			expectedDecision:  samplingpolicy.NotSampled,
+			expectedThreshold: sampling.NeverSampleThreshold,
+		},
+		{
+			name:              "trace without tenant uses the default percentage",
+			traceID:           pcommon.TraceID{9: 0xff, 10: 0xff, 11: 0xff, 12: 0xff, 13: 0xff, 14: 0xff, 15: 0xff},
+			expectedDecision:  samplingpolicy.NotSampled,
+			expectedThreshold: sampling.NeverSampleThreshold,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trace := newTraceWithTenant(tt.tenant)
+			decision, threshold, err := thresholdEvaluator.EvaluateWithThreshold(t.Context(), tt.traceID, trace)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedDecision, decision)
+			assert.Equal(t, tt.expectedThreshold, threshold)
+
+			decision, err = evaluator.Evaluate(t.Context(), tt.traceID, trace)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedDecision, decision)
+		})
+	}
+}
+
+func newTestExtension() *tenantSamplingPolicyExtension {
+	return newExtension(extensiontest.NewNopSettings(metadata.Type), createDefaultConfig().(*Config))
+}
+
+func newTraceWithTenant(tenant string) *samplingpolicy.TraceData {
+	td := ptrace.NewTraces()
+	rs := td.ResourceSpans().AppendEmpty()
+	if tenant != "" {
+		rs.Resource().Attributes().PutStr(defaultTenantAttribute, tenant)
+	}
+	rs.ScopeSpans().AppendEmpty().Spans().AppendEmpty()
+	return &samplingpolicy.TraceData{
+		SpanCount:       1,
+		ReceivedBatches: td,
+	}
+}
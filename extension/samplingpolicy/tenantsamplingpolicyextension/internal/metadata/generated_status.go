@@ -0,0 +1,18 @@
+// Code generated by mdatagen. DO NOT EDIT.
+
+// Package metadata contains the autogenerated telemetry and
+// build information for the extension/tenant_sampling_policy component.
+package metadata
+
+import (
+	"go.opentelemetry.io/collector/component"
+)
+
+var (
+	Type      = component.MustNewType("tenant_sampling_policy")
+	ScopeName = "github.com/open-telemetry/opentelemetry-collector-contrib/extension/samplingpolicy/tenantsamplingpolicyextension"
+)
+
+const (
+	ExtensionStability = component.StabilityLevelDevelopment
+)
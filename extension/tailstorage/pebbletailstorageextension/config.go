@@ -5,11 +5,17 @@
 
 package pebbletailstorageextension // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/tailstorage/pebbletailstorageextension"
 
-import "errors"
+import (
+	"errors"
+	"time"
+)
 
 type Config struct {
 	// Directory is where the extension stores Pebble DB files.
 	Directory string `mapstructure:"directory"`
+	// DecisionTTL is how long sampling decisions are kept on disk to rebuild
+	// the tail sampling processor's decision cache after a restart.
+	DecisionTTL time.Duration `mapstructure:"decision_ttl"`
 	// prevent unkeyed literal initialization
 	_ struct{}
 }
@@ -18,5 +24,8 @@ func (c *Config) Validate() error {
 	if c.Directory == "" {
 		return errors.New("directory must be set")
 	}
+	if c.DecisionTTL <= 0 {
+		return errors.New("decision_ttl must be positive")
+	}
 	return nil
 }
@@ -7,11 +7,14 @@ package pebbletailstorageextension // import "github.com/open-telemetry/opentele
 
 import (
 	"context"
+	"sync"
+	"time"
 
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/extension"
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.uber.org/zap"
 )
 
 type pebbleTailStorageExtension struct {
@@ -19,6 +22,9 @@ type pebbleTailStorageExtension struct {
 	cfg      *Config
 
 	storage *storage
+
+	stopPruning chan struct{}
+	pruningDone sync.WaitGroup
 }
 
 var _ extension.Extension = (*pebbleTailStorageExtension)(nil)
@@ -36,6 +42,10 @@ func (e *pebbleTailStorageExtension) Start(ctx context.Context, _ component.Host
 		return err
 	}
 	e.storage = storage
+
+	e.pruneDecisions()
+	e.stopPruning = make(chan struct{})
+	e.pruningDone.Go(e.pruneDecisionsLoop)
 	return nil
 }
 
@@ -43,11 +53,39 @@ func (e *pebbleTailStorageExtension) Shutdown(_ context.Context) error {
 	if e.storage == nil {
 		return nil
 	}
+	close(e.stopPruning)
+	e.pruningDone.Wait()
 	err := e.storage.Close()
 	e.storage = nil
 	return err
 }
 
+// pruneDecisionsLoop removes expired decisions once per decision_ttl, so
+// decisions are kept on disk for at most twice the configured TTL.
+func (e *pebbleTailStorageExtension) pruneDecisionsLoop() {
+	ticker := time.NewTicker(e.cfg.DecisionTTL)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-e.stopPruning:
+			return
+		case <-ticker.C:
+			e.pruneDecisions()
+		}
+	}
+}
+
+func (e *pebbleTailStorageExtension) pruneDecisions() {
+	pruned, err := e.storage.pruneDecisions(e.cfg.DecisionTTL)
+	if err != nil {
+		e.settings.Logger.Warn("failed to prune expired decisions from tail storage", zap.Error(err))
+		return
+	}
+	if pruned > 0 {
+		e.settings.Logger.Debug("pruned expired decisions from tail storage", zap.Int("count", pruned))
+	}
+}
+
 func (e *pebbleTailStorageExtension) Append(traceID pcommon.TraceID, td ptrace.Traces) error {
 	return e.storage.Append(traceID, td)
 }
@@ -59,3 +97,15 @@ func (e *pebbleTailStorageExtension) Take(traceID pcommon.TraceID) (ptrace.Trace
 func (e *pebbleTailStorageExtension) Delete(traceID pcommon.TraceID) error {
 	return e.storage.Delete(traceID)
 }
+
+func (e *pebbleTailStorageExtension) RecoverPendingTraces(fn func(traceID pcommon.TraceID, arrivalTime time.Time, spanCount int64, sizeBytes uint64)) error {
+	return e.storage.RecoverPendingTraces(fn)
+}
+
+func (e *pebbleTailStorageExtension) RecordDecision(traceID pcommon.TraceID, sampled bool, policyName string) error {
+	return e.storage.RecordDecision(traceID, sampled, policyName)
+}
+
+func (e *pebbleTailStorageExtension) RecoverDecisions(fn func(traceID pcommon.TraceID, sampled bool, policyName string)) error {
+	return e.storage.RecoverDecisions(e.cfg.DecisionTTL, fn)
+}
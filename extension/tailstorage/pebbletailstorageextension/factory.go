@@ -7,6 +7,7 @@ package pebbletailstorageextension // import "github.com/open-telemetry/opentele
 
 import (
 	"context"
+	"time"
 
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/extension"
@@ -24,8 +25,12 @@ func NewFactory() extension.Factory {
 	)
 }
 
+const defaultDecisionTTL = time.Hour
+
 func createDefaultConfig() component.Config {
-	return &Config{}
+	return &Config{
+		DecisionTTL: defaultDecisionTTL,
+	}
 }
 
 func createExtension(_ context.Context, settings extension.Settings, cfg component.Config) (extension.Extension, error) {
@@ -16,6 +16,7 @@ import (
 	"go.opentelemetry.io/collector/extension/extensiontest"
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/processor"
 	"go.opentelemetry.io/collector/processor/processortest"
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/tailstorage/pebbletailstorageextension"
@@ -84,6 +85,78 @@ func TestTailSamplingWithPebbleTailStorageExtension(t *testing.T) {
 	assert.Equal(t, 1, sink.AllTraces()[0].SpanCount())
 }
 
+func TestTailSamplingRecoversPendingTracesAcrossRestart(t *testing.T) {
+	enableTailStorageFeatureGateForBenchmark(t)
+
+	dir := t.TempDir()
+
+	firstSink, stopFirst := startTailSamplingWithPebble(t, dir)
+	require.NoError(t, firstSink.proc.ConsumeTraces(t.Context(), singleTrace()))
+	stopFirst()
+	assert.Empty(t, firstSink.AllTraces(), "pending traces must not be decided early on shutdown")
+
+	secondSink, stopSecond := startTailSamplingWithPebble(t, dir)
+	defer stopSecond()
+	require.Eventually(t, func() bool {
+		return len(secondSink.AllTraces()) > 0
+	}, 5*time.Second, 20*time.Millisecond)
+	assert.Equal(t, 1, secondSink.AllTraces()[0].SpanCount())
+}
+
+type tailSamplingSink struct {
+	*consumertest.TracesSink
+	proc processor.Traces
+}
+
+func startTailSamplingWithPebble(t *testing.T, dir string) (*tailSamplingSink, func()) {
+	t.Helper()
+
+	extFactory := pebbletailstorageextension.NewFactory()
+	extID := component.NewIDWithName(metadata.Type, "restart")
+
+	extCfg := extFactory.CreateDefaultConfig().(*pebbletailstorageextension.Config)
+	extCfg.Directory = dir
+
+	ext, err := extFactory.Create(t.Context(), extensiontest.NewNopSettings(metadata.Type), extCfg)
+	require.NoError(t, err)
+	require.NoError(t, ext.Start(t.Context(), componenttest.NewNopHost()))
+
+	processorFactory := tailsamplingprocessor.NewFactory()
+	processorCfg := processorFactory.CreateDefaultConfig().(*tailsamplingprocessor.Config)
+	processorCfg.DecisionWait = time.Second
+	processorCfg.NumTraces = 10
+	processorCfg.TailStorageID = &extID
+	processorCfg.DecisionCache.SampledCacheSize = 10
+	require.NoError(t, confmap.NewFromStringMap(map[string]any{
+		"policies": []any{
+			map[string]any{
+				"name": "always-sample",
+				"type": string(tailsamplingprocessor.AlwaysSample),
+			},
+		},
+	}).Unmarshal(processorCfg))
+
+	sink := &tailSamplingSink{TracesSink: new(consumertest.TracesSink)}
+	sink.proc, err = processorFactory.CreateTraces(
+		t.Context(),
+		processortest.NewNopSettings(processorFactory.Type()),
+		processorCfg,
+		sink.TracesSink,
+	)
+	require.NoError(t, err)
+
+	host := e2eHost{
+		extensions: map[component.ID]component.Component{
+			extID: ext,
+		},
+	}
+	require.NoError(t, sink.proc.Start(t.Context(), host))
+	return sink, func() {
+		require.NoError(t, sink.proc.Shutdown(t.Context()))
+		require.NoError(t, ext.Shutdown(t.Context()))
+	}
+}
+
 func singleTrace() ptrace.Traces {
 	td := ptrace.NewTraces()
 	traceID := pcommon.TraceID([16]byte{0x10, 0x20, 0x30, 0x40})
@@ -54,12 +54,13 @@ func newPebbleDB(storageDir string, logger *zap.Logger) (db *pebble.DB, created
 func traceKeyComparer() *pebble.Comparer {
 	comparer := *pebble.DefaultComparer
 	comparer.Split = func(k []byte) int {
-		// Since trace ID is fixed sized, and trace ID separator is a valid trace ID byte itself,
-		// split on fixed length instead to avoid corruption.
-		if len(k) >= traceIDBytes+1 {
-			return traceIDBytes + 1
+		// Since trace ID is fixed sized, split trace entry keys on the fixed length of
+		// the key prefix and trace ID, so that the sequence number is the suffix.
+		if len(k) >= tracePrefixBytes {
+			return tracePrefixBytes
 		}
-		return len(k) // BUG: key len should always be >= 17
+		// Metadata keys such as schemaVersionKey and range bounds are shorter than a trace prefix.
+		return len(k)
 	}
 	comparer.Compare = func(a, b []byte) int {
 		ap := comparer.Split(a)
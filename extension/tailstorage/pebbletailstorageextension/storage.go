@@ -8,9 +8,12 @@ package pebbletailstorageextension // import "github.com/open-telemetry/opentele
 import (
 	"context"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"path/filepath"
+	"slices"
 	"sync/atomic"
+	"time"
 
 	"github.com/cockroachdb/pebble/v2"
 	"go.opentelemetry.io/collector/pdata/pcommon"
@@ -19,19 +22,58 @@ import (
 )
 
 const (
-	traceIDSeparator byte = ':'
-	traceIDBytes          = len(pcommon.TraceID{})
+	traceEntryPrefix    byte = 't'
+	decisionPrefix      byte = 'd'
+	traceIDBytes             = len(pcommon.TraceID{})
+	tracePrefixBytes         = 1 + traceIDBytes
+	traceEntryKeyBytes       = tracePrefixBytes + 8
+	decisionKeyBytes         = 1 + traceIDBytes
+	traceEntryHeaderLen      = 16
+	decisionHeaderLen        = 9
 
 	// storageVersion is a version to support evolution.
+	// It names the directory holding the Pebble DB and only changes when
+	// the comparer changes in a way that cannot be migrated in place.
 	storageVersion = "v0"
+
+	// schemaVersion is the version of the key and value layout within the
+	// storageVersion directory. It is persisted under schemaVersionKey and
+	// checked on startup. Databases without the key predate persistence
+	// across restarts and are treated as version 0.
+	//
+	// Version 1 layout, where trace entries and decisions each have their own
+	// key prefix so that they can be iterated without visiting each other:
+	//   - trace entry:  't' traceID seq(8) => appendedAt(8) spanCount(8) payload
+	//   - decision:     'd' traceID        => decidedAt(8) sampled(1) policyName
+	schemaVersion uint64 = 1
+)
+
+var (
+	// schemaVersionKey falls outside the trace entry and decision key ranges.
+	schemaVersionKey = []byte("schema_version")
+
+	traceEntryRange = pebble.IterOptions{LowerBound: []byte{traceEntryPrefix}, UpperBound: []byte{traceEntryPrefix + 1}}
+	decisionRange   = pebble.IterOptions{LowerBound: []byte{decisionPrefix}, UpperBound: []byte{decisionPrefix + 1}}
 )
 
+// migrations upgrade the database from the version used as the map key to the
+// next version. A stored version with no migration path refuses to start.
+var migrations = map[uint64]func(ctx context.Context, s *storage) error{
+	// Version 0 did not support persistence across restarts, so there is
+	// nothing to carry over.
+	0: func(ctx context.Context, s *storage) error {
+		s.logger.Warn("existing database predates persistence across restarts; dropping all data")
+		return s.drop(ctx)
+	},
+}
+
 type storage struct {
 	db          *pebble.DB
 	logger      *zap.Logger
 	nextSeq     atomic.Uint64
 	unmarshaler ptrace.Unmarshaler
 	marshaler   ptrace.Marshaler
+	now         func() time.Time
 }
 
 func newStorage(ctx context.Context, storageDir string, logger *zap.Logger) (*storage, error) {
@@ -49,26 +91,100 @@ func newStorage(ctx context.Context, storageDir string, logger *zap.Logger) (*st
 		logger:      logger,
 		marshaler:   &ptrace.ProtoMarshaler{},
 		unmarshaler: &ptrace.ProtoUnmarshaler{},
+		now:         time.Now,
 	}
 
-	if !created {
-		// Persistence across restarts is not supported.
-		// Enforce this at startup to prevent users from relying on persistence.
-		logger.Warn("existing database found; dropping all data as persistence across restarts is not supported")
-		if err := s.drop(ctx); err != nil {
-			return nil, err
+	if err := s.init(ctx, created); err != nil {
+		return nil, errors.Join(err, db.Close())
+	}
+	return s, nil
+}
+
+// init brings the on-disk schema up to schemaVersion and restores the
+// sequence counter so that new entries never overwrite recovered ones.
+func (s *storage) init(ctx context.Context, created bool) error {
+	if created {
+		return s.writeSchemaVersion(schemaVersion)
+	}
+
+	version, err := s.readSchemaVersion()
+	if err != nil {
+		return err
+	}
+	if version > schemaVersion {
+		return fmt.Errorf("tail storage schema version %d is newer than supported version %d; "+
+			"upgrade the collector or remove the storage directory", version, schemaVersion)
+	}
+	for ; version < schemaVersion; version++ {
+		migrate, ok := migrations[version]
+		if !ok {
+			return fmt.Errorf("no migration from tail storage schema version %d to %d; remove the storage directory", version, schemaVersion)
+		}
+		if err := migrate(ctx, s); err != nil {
+			return fmt.Errorf("failed to migrate tail storage schema version %d: %w", version, err)
+		}
+		if err := s.writeSchemaVersion(version + 1); err != nil {
+			return err
 		}
 	}
 
-	return s, nil
+	maxSeq, err := s.maxSeq()
+	if err != nil {
+		return err
+	}
+	s.nextSeq.Store(maxSeq)
+	return nil
+}
+
+func (s *storage) readSchemaVersion() (uint64, error) {
+	val, closer, err := s.db.Get(schemaVersionKey)
+	if errors.Is(err, pebble.ErrNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read tail storage schema version: %w", err)
+	}
+	defer closer.Close()
+	if len(val) != 8 {
+		return 0, fmt.Errorf("invalid tail storage schema version of length %d", len(val))
+	}
+	return binary.BigEndian.Uint64(val), nil
+}
+
+func (s *storage) writeSchemaVersion(version uint64) error {
+	var val [8]byte
+	binary.BigEndian.PutUint64(val[:], version)
+	if err := s.db.Set(schemaVersionKey, val[:], pebble.Sync); err != nil {
+		return fmt.Errorf("failed to write tail storage schema version: %w", err)
+	}
+	return nil
 }
 
+// maxSeq returns the greatest trace entry sequence number in the database.
+func (s *storage) maxSeq() (uint64, error) {
+	iter, err := s.db.NewIter(&traceEntryRange)
+	if err != nil {
+		return 0, err
+	}
+	defer iter.Close()
+
+	var maxSeq uint64
+	for iter.First(); iter.Valid(); iter.Next() {
+		if key := iter.Key(); isTraceEntryKey(key) {
+			maxSeq = max(maxSeq, binary.BigEndian.Uint64(key[tracePrefixBytes:]))
+		}
+	}
+	return maxSeq, iter.Error()
+}
+
+// drop deletes the trace entries of the version 0 layout, traceID ':' seq(8).
 func (s *storage) drop(ctx context.Context) error {
+	const legacySeparator byte = ':'
 	var lo, hi [traceIDBytes + 1]byte
-	lo[len(lo)-1] = traceIDSeparator
+	lo[len(lo)-1] = legacySeparator
 	for i := range hi {
 		if i == len(hi)-1 {
-			hi[i] = traceIDSeparator + 1 // +1 to include the greatest trace ID with trace ID separator
+			hi[i] = legacySeparator + 1 // +1 to include the greatest trace ID with trace ID separator
 			break
 		}
 		hi[i] = 0xff
@@ -87,11 +203,16 @@ func (s *storage) Close() error {
 }
 
 func (s *storage) Append(traceID pcommon.TraceID, td ptrace.Traces) error {
-	data, err := s.marshaler.MarshalTraces(td)
+	payload, err := s.marshaler.MarshalTraces(td)
 	if err != nil {
 		return fmt.Errorf("failed to marshal trace payload: %w", err)
 	}
 
+	data := make([]byte, traceEntryHeaderLen, traceEntryHeaderLen+len(payload))
+	binary.BigEndian.PutUint64(data[0:8], uint64(s.now().UnixNano()))
+	binary.BigEndian.PutUint64(data[8:16], uint64(td.SpanCount()))
+	data = append(data, payload...)
+
 	key := traceEntryKey(traceID, s.nextSeq.Add(1))
 	if err := s.db.Set(key[:], data, pebble.NoSync); err != nil {
 		return fmt.Errorf("pebble Set error: %w", err)
@@ -105,8 +226,7 @@ func (s *storage) Take(traceID pcommon.TraceID) (ptrace.Traces, error) {
 	if out.ResourceSpans().Len() == 0 {
 		return out, nil
 	}
-	end := tracePrefixUpperBound(prefix)
-	if err := s.db.DeleteRange(prefix[:], end[:], pebble.NoSync); err != nil {
+	if err := s.db.DeleteRange(prefix[:], tracePrefixUpperBound(prefix), pebble.NoSync); err != nil {
 		return ptrace.NewTraces(), fmt.Errorf("pebble DeleteRange error: %w", err)
 	}
 	return out, nil
@@ -116,13 +236,176 @@ func (s *storage) Delete(traceID pcommon.TraceID) error {
 	prefix := tracePrefix(traceID)
 	// Delete all entries for the trace in one range operation instead of
 	// iterating keys and deleting one-by-one.
-	end := tracePrefixUpperBound(prefix)
-	if err := s.db.DeleteRange(prefix[:], end[:], pebble.NoSync); err != nil {
+	if err := s.db.DeleteRange(prefix[:], tracePrefixUpperBound(prefix), pebble.NoSync); err != nil {
 		return fmt.Errorf("pebble DeleteRange error: %w", err)
 	}
 	return nil
 }
 
+// RecoverPendingTraces calls fn once per trace with stored entries. The
+// arrival time is the time the first stored entry was appended.
+func (s *storage) RecoverPendingTraces(fn func(traceID pcommon.TraceID, arrivalTime time.Time, spanCount int64, sizeBytes uint64)) error {
+	iter, err := s.db.NewIter(&traceEntryRange)
+	if err != nil {
+		return err
+	}
+	defer iter.Close()
+
+	var (
+		current     pcommon.TraceID
+		arrivalTime time.Time
+		spanCount   int64
+		sizeBytes   uint64
+		found       bool
+	)
+	flush := func() {
+		if found {
+			fn(current, arrivalTime, spanCount, sizeBytes)
+		}
+	}
+	for iter.First(); iter.Valid(); iter.Next() {
+		key := iter.Key()
+		if !isTraceEntryKey(key) {
+			continue
+		}
+		val, err := iter.ValueAndErr()
+		if err != nil {
+			return err
+		}
+		if len(val) < traceEntryHeaderLen {
+			s.logger.Warn("skipping malformed trace entry in tail storage", zap.Int("length", len(val)))
+			continue
+		}
+		appendedAt := time.Unix(0, int64(binary.BigEndian.Uint64(val[0:8])))
+		traceID := pcommon.TraceID(key[1:tracePrefixBytes])
+		if !found || traceID != current {
+			flush()
+			current, arrivalTime, spanCount, sizeBytes, found = traceID, appendedAt, 0, 0, true
+		}
+		if appendedAt.Before(arrivalTime) {
+			arrivalTime = appendedAt
+		}
+		spanCount += int64(binary.BigEndian.Uint64(val[8:16]))
+		sizeBytes += uint64(len(val) - traceEntryHeaderLen)
+	}
+	if err := iter.Error(); err != nil {
+		return err
+	}
+	flush()
+	return nil
+}
+
+// RecordDecision persists the final sampling decision for traceID.
+func (s *storage) RecordDecision(traceID pcommon.TraceID, sampled bool, policyName string) error {
+	val := make([]byte, decisionHeaderLen, decisionHeaderLen+len(policyName))
+	binary.BigEndian.PutUint64(val[0:8], uint64(s.now().UnixNano()))
+	if sampled {
+		val[8] = 1
+	}
+	val = append(val, policyName...)
+
+	key := decisionKey(traceID)
+	if err := s.db.Set(key[:], val, pebble.NoSync); err != nil {
+		return fmt.Errorf("pebble Set error: %w", err)
+	}
+	return nil
+}
+
+type decisionRecord struct {
+	traceID    pcommon.TraceID
+	decidedAt  time.Time
+	sampled    bool
+	policyName string
+}
+
+// RecoverDecisions calls fn for every persisted decision that is younger than
+// ttl, oldest first.
+func (s *storage) RecoverDecisions(ttl time.Duration, fn func(traceID pcommon.TraceID, sampled bool, policyName string)) error {
+	cutoff := s.now().Add(-ttl)
+
+	var records []decisionRecord
+	err := s.scanDecisions(func(traceID pcommon.TraceID, decidedAt time.Time, val []byte) error {
+		if decidedAt.Before(cutoff) {
+			return nil
+		}
+		records = append(records, decisionRecord{
+			traceID:    traceID,
+			decidedAt:  decidedAt,
+			sampled:    val[8] == 1,
+			policyName: string(val[decisionHeaderLen:]),
+		})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	slices.SortFunc(records, func(a, b decisionRecord) int {
+		return a.decidedAt.Compare(b.decidedAt)
+	})
+	for _, r := range records {
+		fn(r.traceID, r.sampled, r.policyName)
+	}
+	return nil
+}
+
+// pruneDecisions deletes persisted decisions that are older than ttl and
+// returns how many were deleted.
+func (s *storage) pruneDecisions(ttl time.Duration) (int, error) {
+	cutoff := s.now().Add(-ttl)
+
+	batch := s.db.NewBatch()
+	defer batch.Close()
+
+	var pruned int
+	err := s.scanDecisions(func(traceID pcommon.TraceID, decidedAt time.Time, _ []byte) error {
+		if !decidedAt.Before(cutoff) {
+			return nil
+		}
+		pruned++
+		key := decisionKey(traceID)
+		return batch.Delete(key[:], nil)
+	})
+	if err != nil {
+		return 0, err
+	}
+	if pruned == 0 {
+		return 0, nil
+	}
+	if err := batch.Commit(pebble.NoSync); err != nil {
+		return 0, fmt.Errorf("pebble batch commit error: %w", err)
+	}
+	return pruned, nil
+}
+
+func (s *storage) scanDecisions(fn func(traceID pcommon.TraceID, decidedAt time.Time, val []byte) error) error {
+	iter, err := s.db.NewIter(&decisionRange)
+	if err != nil {
+		return err
+	}
+	defer iter.Close()
+
+	for iter.First(); iter.Valid(); iter.Next() {
+		key := iter.Key()
+		if !isDecisionKey(key) {
+			continue
+		}
+		val, err := iter.ValueAndErr()
+		if err != nil {
+			return err
+		}
+		if len(val) < decisionHeaderLen {
+			s.logger.Warn("skipping malformed decision in tail storage", zap.Int("length", len(val)))
+			continue
+		}
+		decidedAt := time.Unix(0, int64(binary.BigEndian.Uint64(val[0:8])))
+		if err := fn(pcommon.TraceID(key[1:]), decidedAt, val); err != nil {
+			return err
+		}
+	}
+	return iter.Error()
+}
+
 func (s *storage) readByTracePrefix(prefix []byte) ptrace.Traces {
 	iter, err := s.db.NewIter(nil)
 	if err != nil {
@@ -143,8 +426,12 @@ func (s *storage) readByTracePrefix(prefix []byte) ptrace.Traces {
 			s.logger.Warn("failed to read trace payload from tail storage", zap.Error(err))
 			continue
 		}
+		if len(val) < traceEntryHeaderLen {
+			s.logger.Warn("skipping malformed trace entry in tail storage", zap.Int("length", len(val)))
+			continue
+		}
 
-		td, err := s.unmarshaler.UnmarshalTraces(val)
+		td, err := s.unmarshaler.UnmarshalTraces(val[traceEntryHeaderLen:])
 		if err != nil {
 			s.logger.Warn("failed to unmarshal trace payload from tail storage", zap.Error(err))
 			continue
@@ -164,21 +451,41 @@ func (s *storage) readByTracePrefix(prefix []byte) ptrace.Traces {
 	return result
 }
 
-func tracePrefix(traceID pcommon.TraceID) (prefix [traceIDBytes + 1]byte) {
-	copy(prefix[:], traceID[:])
-	prefix[traceIDBytes] = traceIDSeparator
+func isTraceEntryKey(key []byte) bool {
+	return len(key) == traceEntryKeyBytes && key[0] == traceEntryPrefix
+}
+
+func isDecisionKey(key []byte) bool {
+	return len(key) == decisionKeyBytes && key[0] == decisionPrefix
+}
+
+func tracePrefix(traceID pcommon.TraceID) (prefix [tracePrefixBytes]byte) {
+	prefix[0] = traceEntryPrefix
+	copy(prefix[1:], traceID[:])
 	return prefix
 }
 
-func tracePrefixUpperBound(prefix [traceIDBytes + 1]byte) (upper [traceIDBytes + 1]byte) {
-	upper = prefix // copy
+// tracePrefixUpperBound returns the smallest key greater than every key with
+// the given trace prefix: the prefix with its trailing 0xff bytes removed and
+// its last byte incremented. The prefix byte is never 0xff.
+func tracePrefixUpperBound(prefix [tracePrefixBytes]byte) []byte {
+	upper := prefix[:]
+	for upper[len(upper)-1] == 0xff {
+		upper = upper[:len(upper)-1]
+	}
 	upper[len(upper)-1]++
 	return upper
 }
 
-func traceEntryKey(traceID pcommon.TraceID, seq uint64) (key [traceIDBytes + 1 + 8]byte) {
-	copy(key[:], traceID[:])
-	key[traceIDBytes] = traceIDSeparator
-	binary.BigEndian.PutUint64(key[traceIDBytes+1:], seq)
+func traceEntryKey(traceID pcommon.TraceID, seq uint64) (key [traceEntryKeyBytes]byte) {
+	prefix := tracePrefix(traceID)
+	copy(key[:], prefix[:])
+	binary.BigEndian.PutUint64(key[tracePrefixBytes:], seq)
+	return key
+}
+
+func decisionKey(traceID pcommon.TraceID) (key [decisionKeyBytes]byte) {
+	key[0] = decisionPrefix
+	copy(key[1:], traceID[:])
 	return key
 }
@@ -4,11 +4,15 @@
 package pebbletailstorageextension
 
 import (
+	"encoding/binary"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/extension"
 	"go.opentelemetry.io/collector/extension/extensiontest"
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/ptrace"
@@ -116,33 +120,194 @@ func TestTakeRemovesOnlyTargetTrace(t *testing.T) {
 	require.Equal(t, 1, out3.SpanCount())
 }
 
-func TestDropOnStart(t *testing.T) {
+func TestDeleteTraceIDsEndingInMaxBytes(t *testing.T) {
+	storage := newStartedTailStorage(t)
+
+	traceID1 := pcommon.TraceID([16]byte{1, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff})
+	traceID2 := pcommon.TraceID([16]byte{2})
+	appendTraceSpan(storage, traceID1, pcommon.SpanID([8]byte{1}), "")
+	appendTraceSpan(storage, traceID2, pcommon.SpanID([8]byte{2}), "")
+
+	require.NoError(t, storage.Delete(traceID1))
+
+	out1, err := storage.Take(traceID1)
+	require.NoError(t, err)
+	require.Equal(t, 0, out1.SpanCount())
+
+	out2, err := storage.Take(traceID2)
+	require.NoError(t, err)
+	require.Equal(t, 1, out2.SpanCount())
+}
+
+func startTailStorage(t *testing.T, set extension.Settings, cfg *Config) (extension.Extension, PersistentTailStorage) {
+	t.Helper()
+
+	ext, err := NewFactory().Create(t.Context(), set, cfg)
+	require.NoError(t, err)
+	require.NoError(t, ext.Start(t.Context(), componenttest.NewNopHost()))
+	return ext, ext.(PersistentTailStorage)
+}
+
+func TestPendingTracesSurviveRestart(t *testing.T) {
 	f := NewFactory()
 	cfg := f.CreateDefaultConfig().(*Config)
 	cfg.Directory = t.TempDir()
-
-	zc, logs := observer.New(zap.InfoLevel)
 	set := extensiontest.NewNopSettings(f.Type())
-	set.Logger = zap.New(zc)
 
-	first, err := f.Create(t.Context(), set, cfg)
+	traceID1 := pcommon.TraceID([16]byte{1, 2, 3, 4})
+	traceID2 := pcommon.TraceID([16]byte{1, 2, 3, 5})
+
+	before := time.Now()
+	first, storage := startTailStorage(t, set, cfg)
+	appendTraceSpan(storage, traceID1, pcommon.SpanID([8]byte{1}), "first")
+	appendTraceSpan(storage, traceID1, pcommon.SpanID([8]byte{2}), "second")
+	appendTraceSpan(storage, traceID2, pcommon.SpanID([8]byte{3}), "other")
+	require.NoError(t, first.Shutdown(t.Context()))
+
+	second, storage := startTailStorage(t, set, cfg)
+	defer func() {
+		require.NoError(t, second.Shutdown(t.Context()))
+	}()
+
+	recovered := map[pcommon.TraceID]int64{}
+	require.NoError(t, storage.RecoverPendingTraces(func(traceID pcommon.TraceID, arrivalTime time.Time, spanCount int64, sizeBytes uint64) {
+		assert.False(t, arrivalTime.Before(before))
+		assert.Positive(t, sizeBytes)
+		recovered[traceID] = spanCount
+	}))
+	assert.Equal(t, map[pcommon.TraceID]int64{traceID1: 2, traceID2: 1}, recovered)
+
+	// Entries appended after the restart must not overwrite recovered ones.
+	appendTraceSpan(storage, traceID1, pcommon.SpanID([8]byte{4}), "third")
+	out, err := storage.Take(traceID1)
 	require.NoError(t, err)
-	require.NoError(t, first.Start(t.Context(), componenttest.NewNopHost()))
+	assert.Equal(t, 3, out.SpanCount())
+}
 
-	traceID := pcommon.TraceID([16]byte{1, 2, 3, 4})
-	appendTraceSpan(first.(TailStorage), traceID, pcommon.SpanID{}, "")
+func TestDecisionsSurviveRestart(t *testing.T) {
+	f := NewFactory()
+	cfg := f.CreateDefaultConfig().(*Config)
+	cfg.Directory = t.TempDir()
+	set := extensiontest.NewNopSettings(f.Type())
+
+	sampledID := pcommon.TraceID([16]byte{1})
+	notSampledID := pcommon.TraceID([16]byte{2})
 
+	first, storage := startTailStorage(t, set, cfg)
+	require.NoError(t, storage.RecordDecision(sampledID, true, "errors"))
+	require.NoError(t, storage.RecordDecision(notSampledID, false, ""))
+	// Deleting pending entries must not remove the decision.
+	require.NoError(t, storage.Delete(sampledID))
 	require.NoError(t, first.Shutdown(t.Context()))
 
-	second, err := f.Create(t.Context(), set, cfg)
+	second, storage := startTailStorage(t, set, cfg)
+	defer func() {
+		require.NoError(t, second.Shutdown(t.Context()))
+	}()
+
+	type decision struct {
+		sampled    bool
+		policyName string
+	}
+	var ids []pcommon.TraceID
+	decisions := map[pcommon.TraceID]decision{}
+	require.NoError(t, storage.RecoverDecisions(func(traceID pcommon.TraceID, sampled bool, policyName string) {
+		ids = append(ids, traceID)
+		decisions[traceID] = decision{sampled: sampled, policyName: policyName}
+	}))
+	assert.Equal(t, []pcommon.TraceID{sampledID, notSampledID}, ids, "decisions are recovered oldest first")
+	assert.Equal(t, map[pcommon.TraceID]decision{
+		sampledID:    {sampled: true, policyName: "errors"},
+		notSampledID: {sampled: false},
+	}, decisions)
+
+	require.NoError(t, storage.RecoverPendingTraces(func(pcommon.TraceID, time.Time, int64, uint64) {
+		assert.Fail(t, "decisions must not be recovered as pending traces")
+	}))
+}
+
+func TestPruneExpiredDecisions(t *testing.T) {
+	s, err := newStorage(t.Context(), t.TempDir(), zap.NewNop())
 	require.NoError(t, err)
-	require.NoError(t, second.Start(t.Context(), componenttest.NewNopHost()))
+	defer func() {
+		require.NoError(t, s.Close())
+	}()
+
+	now := time.Now()
+	s.now = func() time.Time { return now.Add(-2 * time.Hour) }
+	expiredID := pcommon.TraceID([16]byte{1})
+	require.NoError(t, s.RecordDecision(expiredID, true, "old"))
+	s.now = func() time.Time { return now }
+	freshID := pcommon.TraceID([16]byte{2})
+	require.NoError(t, s.RecordDecision(freshID, true, "new"))
+
+	var recovered []pcommon.TraceID
+	collect := func(traceID pcommon.TraceID, _ bool, _ string) {
+		recovered = append(recovered, traceID)
+	}
+	require.NoError(t, s.RecoverDecisions(time.Hour, collect))
+	assert.Equal(t, []pcommon.TraceID{freshID}, recovered, "expired decisions are not recovered")
 
-	out, err := second.(TailStorage).Take(traceID)
+	pruned, err := s.pruneDecisions(time.Hour)
 	require.NoError(t, err)
-	require.Equal(t, 0, out.SpanCount())
+	assert.Equal(t, 1, pruned)
 
-	require.NoError(t, second.Shutdown(t.Context()))
+	recovered = nil
+	require.NoError(t, s.RecoverDecisions(3*time.Hour, collect))
+	assert.Equal(t, []pcommon.TraceID{freshID}, recovered)
+}
+
+func TestMigrateLegacySchema(t *testing.T) {
+	dir := t.TempDir()
+
+	// Version 0 databases have no schema version key and store raw payloads.
+	db, created, err := newPebbleDB(filepath.Join(dir, storageVersion), zap.NewNop())
+	require.NoError(t, err)
+	require.True(t, created)
+	traceID := pcommon.TraceID([16]byte{1, 2, 3, 4})
+	key := binary.BigEndian.AppendUint64(append(traceID[:], ':'), 1)
+	require.NoError(t, db.Set(key, []byte("legacy"), nil))
+	require.NoError(t, db.Close())
 
-	assert.Equal(t, 1, logs.FilterMessage("existing database found; dropping all data as persistence across restarts is not supported").Len())
+	f := NewFactory()
+	cfg := f.CreateDefaultConfig().(*Config)
+	cfg.Directory = dir
+	zc, logs := observer.New(zap.InfoLevel)
+	set := extensiontest.NewNopSettings(f.Type())
+	set.Logger = zap.New(zc)
+
+	ext, storage := startTailStorage(t, set, cfg)
+	out, err := storage.Take(traceID)
+	require.NoError(t, err)
+	assert.Equal(t, 0, out.SpanCount())
+	require.NoError(t, storage.RecoverPendingTraces(func(pcommon.TraceID, time.Time, int64, uint64) {
+		assert.Fail(t, "legacy entries must not be recovered")
+	}))
+	require.NoError(t, ext.Shutdown(t.Context()))
+	assert.Equal(t, 1, logs.FilterMessage("existing database predates persistence across restarts; dropping all data").Len())
+
+	s, err := newStorage(t.Context(), dir, zap.NewNop())
+	require.NoError(t, err)
+	version, err := s.readSchemaVersion()
+	require.NoError(t, err)
+	assert.Equal(t, schemaVersion, version)
+	require.NoError(t, s.Close())
+}
+
+func TestRefuseNewerSchema(t *testing.T) {
+	dir := t.TempDir()
+
+	s, err := newStorage(t.Context(), dir, zap.NewNop())
+	require.NoError(t, err)
+	require.NoError(t, s.writeSchemaVersion(schemaVersion+1))
+	require.NoError(t, s.Close())
+
+	f := NewFactory()
+	cfg := f.CreateDefaultConfig().(*Config)
+	cfg.Directory = dir
+	ext, err := f.Create(t.Context(), extensiontest.NewNopSettings(f.Type()), cfg)
+	require.NoError(t, err)
+	err = ext.Start(t.Context(), componenttest.NewNopHost())
+	require.ErrorContains(t, err, "is newer than supported version")
+	require.NoError(t, ext.Shutdown(t.Context()))
 }
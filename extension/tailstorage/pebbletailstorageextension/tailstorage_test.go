@@ -4,6 +4,8 @@
 package pebbletailstorageextension
 
 import (
+	"time"
+
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/ptrace"
 )
@@ -16,3 +18,13 @@ type TailStorage interface {
 	Take(traceID pcommon.TraceID) (ptrace.Traces, error)
 	Delete(traceID pcommon.TraceID) error
 }
+
+// PersistentTailStorage is duplicated for tests in this package only.
+//
+// TODO: Use tailsamplingprocessor PersistentTailStorage once it is public.
+type PersistentTailStorage interface {
+	TailStorage
+	RecoverPendingTraces(fn func(traceID pcommon.TraceID, arrivalTime time.Time, spanCount int64, sizeBytes uint64)) error
+	RecordDecision(traceID pcommon.TraceID, sampled bool, policyName string) error
+	RecoverDecisions(fn func(traceID pcommon.TraceID, sampled bool, policyName string)) error
+}
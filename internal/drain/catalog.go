@@ -0,0 +1,306 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package drain // import "github.com/open-telemetry/opentelemetry-collector-contrib/internal/drain"
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/drain/internal/tree"
+)
+
+// catalogVersion is the version of the catalog format written by this
+// processor. Catalogs with a different version are rejected on import.
+const catalogVersion = 1
+
+// catalogPath is the path the catalog HTTP server serves the catalog on.
+const catalogPath = "/catalog"
+
+// catalogReadHeaderTimeout bounds the time the catalog HTTP server waits for
+// the headers of a request.
+const catalogReadHeaderTimeout = 10 * time.Second
+
+// catalog is the JSON document exported and imported by the processor. Its
+// layout is documented in the README and must stay backwards compatible
+// within a version.
+type catalog struct {
+	Version int `json:"version"`
+	// MaskingTokens are the mask tokens (e.g. "<ip>") configured on the
+	// exporting processor. Templates containing them only match live lines
+	// on processors configured with the same masking rules.
+	MaskingTokens []string          `json:"masking_tokens"`
+	Templates     []catalogTemplate `json:"templates"`
+}
+
+type catalogTemplate struct {
+	ID       string `json:"id"`
+	Template string `json:"template"`
+	// Count is the number of lines the exporting processor trained into the
+	// template. It is informational and ignored on import.
+	Count     int64     `json:"count"`
+	FirstSeen time.Time `json:"first_seen,omitzero"`
+	LastSeen  time.Time `json:"last_seen,omitzero"`
+}
+
+// clusterInfo is what the processor tracks about a cluster beyond the tree
+// itself. Guarded by Processor.mu.
+type clusterInfo struct {
+	// id is the template ID, assigned once when the cluster is first tracked
+	// or pinned by an imported catalog. It does not change when later lines
+	// generalise the cluster's template.
+	id        string
+	firstSeen time.Time
+	lastSeen  time.Time
+}
+
+// TemplateID derives a template's ID from the template string: the FNV-1a
+// 64-bit hash of the string, as 16 hex digits.
+func TemplateID(template string) string {
+	h := fnv.New64a()
+	h.Write([]byte(template))
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+// trackCluster returns what the processor tracks about cluster. A cluster
+// not tracked yet is assigned the ID derived from its current template. Must
+// be called with p.mu held.
+func (p *Processor) trackCluster(cluster tree.Cluster) *clusterInfo {
+	info, ok := p.clusters[cluster.ID]
+	if !ok {
+		info = &clusterInfo{id: TemplateID(cluster.Template)}
+		p.clusters[cluster.ID] = info
+	}
+	return info
+}
+
+// trackClusters tracks every cluster of the tree, so that seeded clusters and
+// clusters restored without their IDs are assigned an ID before live lines
+// generalise their templates. Must be called with p.mu held.
+func (p *Processor) trackClusters() {
+	for _, c := range p.drain.Clusters() {
+		p.trackCluster(c)
+	}
+}
+
+// observe records that a line was trained into cluster at now and returns the
+// cluster's template ID. Must be called with p.mu held.
+func (p *Processor) observe(cluster tree.Cluster, now time.Time) string {
+	info := p.trackCluster(cluster)
+	if info.firstSeen.IsZero() {
+		info.firstSeen = now
+	}
+	info.lastSeen = now
+	return info.id
+}
+
+// pruneClusterInfo forgets clusters the tree evicted. It only does work once
+// evicted clusters make up half of the tracked ones, so that it runs rarely.
+// Must be called with p.mu held.
+func (p *Processor) pruneClusterInfo() {
+	if len(p.clusters) <= 2*p.drain.ClusterCount() {
+		return
+	}
+	live := make(map[int64]struct{}, p.drain.ClusterCount())
+	for _, id := range p.drain.ClusterIDs() {
+		live[id] = struct{}{}
+	}
+	for id := range p.clusters {
+		if _, ok := live[id]; !ok {
+			delete(p.clusters, id)
+		}
+	}
+}
+
+// buildCatalog returns the catalog of every cluster in the tree, sorted by
+// template string.
+func (p *Processor) buildCatalog() catalog {
+	p.mu.Lock()
+	clusters := p.drain.Clusters()
+	templates := make([]catalogTemplate, 0, len(clusters))
+	for _, c := range clusters {
+		info := p.trackCluster(c)
+		templates = append(templates, catalogTemplate{
+			ID:        info.id,
+			Template:  c.Template,
+			Count:     c.Size,
+			FirstSeen: info.firstSeen,
+			LastSeen:  info.lastSeen,
+		})
+	}
+	p.mu.Unlock()
+
+	slices.SortFunc(templates, func(a, b catalogTemplate) int {
+		return strings.Compare(a.Template, b.Template)
+	})
+	tokens := make([]string, 0, len(p.masks))
+	for _, m := range p.masks {
+		tokens = append(tokens, m.token)
+	}
+	return catalog{Version: catalogVersion, MaskingTokens: tokens, Templates: templates}
+}
+
+// importCatalog imports the catalog at Catalog.ImportPath. Each template is
+// matched against the tree and trained into it when no cluster matches; the
+// resulting cluster keeps the template's catalog ID for its lifetime, even
+// when later lines generalise its template, unless the cluster was restored
+// from storage with an ID already. A missing file is not an error.
+func (p *Processor) importCatalog() error {
+	path := p.config.Catalog.ImportPath
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		p.logger.Info("template catalog not found, skipping import", zap.String("path", path))
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read template catalog: %w", err)
+	}
+	var c catalog
+	if err := json.Unmarshal(data, &c); err != nil {
+		return fmt.Errorf("failed to parse template catalog %q: %w", path, err)
+	}
+	if c.Version != catalogVersion {
+		return fmt.Errorf("unsupported template catalog version %d in %q, expected %d", c.Version, path, catalogVersion)
+	}
+	p.warnMaskingTokenMismatch(c.MaskingTokens)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	imported := 0
+	for _, t := range c.Templates {
+		if strings.TrimSpace(t.Template) == "" {
+			continue
+		}
+		cluster, ok := p.drain.Match(t.Template)
+		if !ok {
+			cluster, _, err = p.drain.Train(t.Template)
+			if err != nil || cluster.Template == "" {
+				p.logger.Warn("failed to import template, skipping", zap.String("template", t.Template), zap.Error(err))
+				continue
+			}
+		}
+		info, ok := p.clusters[cluster.ID]
+		if !ok {
+			info = &clusterInfo{id: t.ID}
+			if info.id == "" {
+				info.id = TemplateID(cluster.Template)
+			}
+			p.clusters[cluster.ID] = info
+		}
+		if !t.FirstSeen.IsZero() && (info.firstSeen.IsZero() || t.FirstSeen.Before(info.firstSeen)) {
+			info.firstSeen = t.FirstSeen
+		}
+		imported++
+	}
+	p.logger.Info("imported template catalog", zap.String("path", path), zap.Int("templates", imported))
+	return nil
+}
+
+// warnMaskingTokenMismatch logs a warning when the catalog was exported with
+// different masking rules than the ones configured: its templates would then
+// never match the masked live lines.
+func (p *Processor) warnMaskingTokenMismatch(catalogTokens []string) {
+	configured := make([]string, 0, len(p.masks))
+	for _, m := range p.masks {
+		configured = append(configured, m.token)
+	}
+	want := slices.Sorted(slices.Values(configured))
+	got := slices.Sorted(slices.Values(catalogTokens))
+	if !slices.Equal(want, got) {
+		p.logger.Warn("template catalog was exported with different masking rules",
+			zap.Strings("catalog_masking_tokens", got), zap.Strings("configured_masking_tokens", want))
+	}
+}
+
+// exportCatalog writes the catalog to Catalog.ExportPath. The file is replaced
+// atomically, so readers never observe a partially written catalog.
+func (p *Processor) exportCatalog() error {
+	data, err := json.MarshalIndent(p.buildCatalog(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("template catalog serialization failed: %w", err)
+	}
+	path := p.config.Catalog.ExportPath
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("failed to write template catalog: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(data); err == nil {
+		err = tmp.Close()
+	} else {
+		_ = tmp.Close()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write template catalog: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write template catalog: %w", err)
+	}
+	p.logger.Debug("exported template catalog", zap.String("path", path), zap.Int("bytes", len(data)))
+	return nil
+}
+
+// startPeriodicExport launches a background goroutine that exports the
+// catalog at the configured interval.
+func (p *Processor) startPeriodicExport() {
+	ctx, cancel := context.WithCancel(context.Background())
+	p.stopExport = cancel
+
+	go func() {
+		ticker := time.NewTicker(p.config.Catalog.ExportInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := p.exportCatalog(); err != nil {
+					p.logger.Warn("periodic template catalog export failed", zap.Error(err))
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// startCatalogServer starts the HTTP server serving the catalog.
+func (p *Processor) startCatalogServer(ctx context.Context) error {
+	cfg := p.config.Catalog.HTTP
+	ln, err := cfg.Listen(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to bind to address %s: %w", cfg.Endpoint, err)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+catalogPath, p.handleCatalog)
+	p.server = &http.Server{Handler: mux, ReadHeaderTimeout: catalogReadHeaderTimeout}
+
+	p.serverDone.Go(func() {
+		if err := p.server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			p.logger.Error("template catalog server failed", zap.Error(err))
+		}
+	})
+	return nil
+}
+
+func (p *Processor) handleCatalog(w http.ResponseWriter, _ *http.Request) {
+	data, err := json.Marshal(p.buildCatalog())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	_, _ = w.Write(data)
+}
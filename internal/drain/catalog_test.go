@@ -0,0 +1,292 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package drain
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config/confignet"
+)
+
+var loginLines = []string{
+	"user alice logged in",
+	"user bob logged in",
+	"user carol logged in",
+}
+
+// templateIDAttr returns the template ID attribute value for the first log
+// record produced by processing body.
+func templateIDAttr(t *testing.T, p *Processor, body string) string {
+	t.Helper()
+	ld, err := p.ProcessLogs(t.Context(), makeLogRecord(body))
+	require.NoError(t, err)
+	v, ok := getFirstRecord(ld).Attributes().Get("log.record.template.id")
+	require.True(t, ok, "log.record.template.id attribute must be set")
+	return v.Str()
+}
+
+func readCatalog(t *testing.T, path string) catalog {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var c catalog
+	require.NoError(t, json.Unmarshal(data, &c))
+	return c
+}
+
+func writeCatalog(t *testing.T, c catalog) string {
+	t.Helper()
+	data, err := json.Marshal(c)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "catalog.json")
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	return path
+}
+
+// TestTemplateIDStableWhenTemplateGeneralises verifies that a cluster keeps
+// the ID assigned when it was created after later lines generalise its
+// template.
+func TestTemplateIDStableWhenTemplateGeneralises(t *testing.T) {
+	cfg := NewDefaultConfig()
+	cfg.TemplateIDAttribute = "log.record.template.id"
+	p := newTestProcessor(t, cfg)
+
+	for _, line := range loginLines {
+		assert.Equal(t, TemplateID(loginLines[0]), templateIDAttr(t, p, line))
+	}
+	c := p.buildCatalog()
+	require.Len(t, c.Templates, 1)
+	assert.Equal(t, "user <*> logged in", c.Templates[0].Template)
+	assert.Equal(t, TemplateID(loginLines[0]), c.Templates[0].ID)
+}
+
+// TestTemplateIDConvergesWithSeedTemplates verifies that two processors
+// seeded with the same templates assign the same IDs, whatever the order of
+// the lines they see.
+func TestTemplateIDConvergesWithSeedTemplates(t *testing.T) {
+	cfg := NewDefaultConfig()
+	cfg.TemplateIDAttribute = "log.record.template.id"
+	cfg.SeedTemplates = []string{"user <*> logged in"}
+
+	p1 := newTestProcessor(t, cfg)
+	p2 := newTestProcessor(t, cfg)
+	for _, line := range loginLines {
+		templateIDAttr(t, p1, line)
+	}
+	templateIDAttr(t, p2, "disk write error on device sda")
+	for i := len(loginLines) - 1; i >= 0; i-- {
+		templateIDAttr(t, p2, loginLines[i])
+	}
+
+	id1 := templateIDAttr(t, p1, "user dave logged in")
+	id2 := templateIDAttr(t, p2, "user dave logged in")
+	assert.Equal(t, id1, id2)
+	assert.Equal(t, TemplateID("user <*> logged in"), id1)
+}
+
+// TestTemplateIDAttributeDisabledByDefault verifies that no ID attribute is
+// written unless template_id_attribute is set.
+func TestTemplateIDAttributeDisabledByDefault(t *testing.T) {
+	p := newTestProcessor(t, NewDefaultConfig())
+	ld, err := p.ProcessLogs(t.Context(), makeLogRecord("user alice logged in"))
+	require.NoError(t, err)
+	assert.Equal(t, 1, getFirstRecord(ld).Attributes().Len(), "only the template attribute is written")
+}
+
+// TestCatalogExportOnShutdown verifies the exported catalog document.
+func TestCatalogExportOnShutdown(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "catalog.json")
+	cfg := NewDefaultConfig()
+	cfg.MaskingRules = []MaskingRule{ipRule()}
+	cfg.Catalog.ExportPath = path
+
+	before := time.Now()
+	p := newManualProcessor(t, cfg)
+	require.NoError(t, p.Start(t.Context(), componenttest.NewNopHost()))
+	for _, line := range loginLines {
+		_, err := p.ProcessLogs(t.Context(), makeLogRecord(line))
+		require.NoError(t, err)
+	}
+	_, err := p.ProcessLogs(t.Context(), makeLogRecord("connected to 10.0.0.1"))
+	require.NoError(t, err)
+	require.NoError(t, p.Shutdown(t.Context()))
+
+	c := readCatalog(t, path)
+	assert.Equal(t, catalogVersion, c.Version)
+	assert.Equal(t, []string{"<ip>"}, c.MaskingTokens)
+	require.Len(t, c.Templates, 2)
+
+	connected, login := c.Templates[0], c.Templates[1]
+	assert.Equal(t, "connected to <ip>", connected.Template)
+	assert.Equal(t, TemplateID("connected to <ip>"), connected.ID)
+	assert.Equal(t, int64(1), connected.Count)
+	assert.Equal(t, "user <*> logged in", login.Template)
+	assert.Equal(t, int64(3), login.Count)
+	assert.False(t, login.FirstSeen.Before(before.Truncate(time.Second)))
+	assert.False(t, login.LastSeen.Before(login.FirstSeen))
+}
+
+// TestCatalogImportPinsIDs verifies that imported templates keep their
+// catalog ID, even when later lines generalise the template, and that the
+// earliest first_seen wins.
+func TestCatalogImportPinsIDs(t *testing.T) {
+	firstSeen := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	path := writeCatalog(t, catalog{
+		Version: catalogVersion,
+		Templates: []catalogTemplate{
+			{ID: "login", Template: "user <*> logged in", Count: 100, FirstSeen: firstSeen},
+			{ID: "disk", Template: "disk write error on device sda"},
+			{ID: "blank", Template: "  "},
+		},
+	})
+	cfg := NewDefaultConfig()
+	cfg.TemplateIDAttribute = "log.record.template.id"
+	cfg.Catalog.ImportPath = path
+	p := newTestProcessor(t, cfg)
+
+	assert.Equal(t, 2, p.drain.ClusterCount(), "blank templates are skipped")
+	assert.Equal(t, "login", templateIDAttr(t, p, "user alice logged in"))
+	assert.Equal(t, "disk", templateIDAttr(t, p, "disk write error on device sdb"), "the ID survives the template generalising")
+
+	exported := p.buildCatalog()
+	require.Len(t, exported.Templates, 2)
+	assert.Equal(t, "disk", exported.Templates[0].ID)
+	assert.Equal(t, "disk write error on device <*>", exported.Templates[0].Template)
+	assert.Equal(t, "login", exported.Templates[1].ID)
+	assert.Equal(t, firstSeen, exported.Templates[1].FirstSeen.UTC())
+	assert.Equal(t, int64(2), exported.Templates[1].Count, "imported counts are not added to the tree")
+}
+
+// TestCatalogRoundTrip verifies that a collector importing another
+// collector's catalog annotates the same lines with the same IDs.
+func TestCatalogRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "catalog.json")
+	cfg1 := NewDefaultConfig()
+	cfg1.TemplateIDAttribute = "log.record.template.id"
+	cfg1.Catalog.ExportPath = path
+	p1 := newManualProcessor(t, cfg1)
+	require.NoError(t, p1.Start(t.Context(), componenttest.NewNopHost()))
+	// The first line alone creates a cluster whose template is the line
+	// itself; the catalog pins that ID for everyone importing it.
+	want := templateIDAttr(t, p1, "user alice logged in")
+	require.NoError(t, p1.Shutdown(t.Context()))
+
+	cfg2 := NewDefaultConfig()
+	cfg2.TemplateIDAttribute = "log.record.template.id"
+	cfg2.Catalog.ImportPath = path
+	p2 := newTestProcessor(t, cfg2)
+	assert.Equal(t, want, templateIDAttr(t, p2, "user bob logged in"))
+}
+
+func TestCatalogImportErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{
+			name:    "invalid json",
+			content: "{",
+			wantErr: "failed to parse template catalog",
+		},
+		{
+			name:    "unsupported version",
+			content: `{"version": 2, "templates": []}`,
+			wantErr: "unsupported template catalog version 2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "catalog.json")
+			require.NoError(t, os.WriteFile(path, []byte(tt.content), 0o600))
+			cfg := NewDefaultConfig()
+			cfg.Catalog.ImportPath = path
+			p := newManualProcessor(t, cfg)
+			err := p.Start(t.Context(), componenttest.NewNopHost())
+			assert.ErrorContains(t, err, tt.wantErr)
+			require.NoError(t, p.Shutdown(t.Context()))
+		})
+	}
+}
+
+// TestCatalogImportMissingFile verifies that a missing catalog is skipped, so
+// a processor can import the catalog it exports itself.
+func TestCatalogImportMissingFile(t *testing.T) {
+	cfg := NewDefaultConfig()
+	cfg.Catalog.ImportPath = filepath.Join(t.TempDir(), "missing.json")
+	p := newTestProcessor(t, cfg)
+	assert.Zero(t, p.drain.ClusterCount())
+}
+
+// TestCatalogPeriodicExport verifies that the catalog is exported at the
+// configured interval.
+func TestCatalogPeriodicExport(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "catalog.json")
+	cfg := NewDefaultConfig()
+	cfg.Catalog.ExportPath = path
+	cfg.Catalog.ExportInterval = 10 * time.Millisecond
+	p := newTestProcessor(t, cfg)
+
+	_, err := p.ProcessLogs(t.Context(), makeLogRecord("user alice logged in"))
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return false
+		}
+		var c catalog
+		return json.Unmarshal(data, &c) == nil && len(c.Templates) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+// TestCatalogHTTP verifies the catalog served over HTTP.
+func TestCatalogHTTP(t *testing.T) {
+	cfg := NewDefaultConfig()
+	cfg.Catalog.HTTP = &confignet.TCPAddrConfig{Endpoint: "localhost:0"}
+	p := newTestProcessor(t, cfg)
+	require.NotNil(t, p.server)
+
+	for _, line := range loginLines {
+		_, err := p.ProcessLogs(t.Context(), makeLogRecord(line))
+		require.NoError(t, err)
+	}
+
+	rec := httptest.NewRecorder()
+	p.server.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, catalogPath, http.NoBody))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	var c catalog
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &c))
+	require.Len(t, c.Templates, 1)
+	assert.Equal(t, "user <*> logged in", c.Templates[0].Template)
+
+	rec = httptest.NewRecorder()
+	p.server.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, catalogPath, http.NoBody))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
+
+// TestClusterInfoPrunedOnEviction verifies that the processor forgets the
+// clusters the tree evicts.
+func TestClusterInfoPrunedOnEviction(t *testing.T) {
+	cfg := NewDefaultConfig()
+	cfg.MaxClusters = 1
+	p := newTestProcessor(t, cfg)
+
+	for _, line := range []string{"a b c", "d e f g", "h i j k l", "m n o p q r"} {
+		_, err := p.ProcessLogs(t.Context(), makeLogRecord(line))
+		require.NoError(t, err)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	assert.LessOrEqual(t, len(p.clusters), 2*p.drain.ClusterCount())
+}
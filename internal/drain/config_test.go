@@ -1,10 +1,11 @@
 // Copyright The OpenTelemetry Authors
 // SPDX-License-Identifier: Apache-2.0
 
-package drainprocessor
+package drain
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -12,7 +13,7 @@ import (
 
 func TestConfigValidate(t *testing.T) {
 	validCfg := func() *Config {
-		return createDefaultConfig().(*Config)
+		return NewDefaultConfig()
 	}
 
 	tests := []struct {
@@ -124,6 +125,27 @@ func TestConfigValidate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "catalog export_interval negative",
+			mutate: func(c *Config) {
+				c.Catalog.ExportPath = "catalog.json"
+				c.Catalog.ExportInterval = -time.Second
+			},
+			wantErr: true,
+		},
+		{
+			name:    "catalog export_interval without export_path",
+			mutate:  func(c *Config) { c.Catalog.ExportInterval = time.Minute },
+			wantErr: true,
+		},
+		{
+			name: "catalog export_interval with export_path",
+			mutate: func(c *Config) {
+				c.Catalog.ExportPath = "catalog.json"
+				c.Catalog.ExportInterval = time.Minute
+			},
+			wantErr: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,9 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:generate make mdatagen
+
+// Package drain implements the Drain log clustering shared by the drain
+// processor and the drain connector: the parse tree, the annotation of log
+// records with their template, the template catalog and its persistence.
+package drain // import "github.com/open-telemetry/opentelemetry-collector-contrib/internal/drain"
@@ -12,11 +12,11 @@ import (
 )
 
 func Meter(settings component.TelemetrySettings) metric.Meter {
-	return settings.MeterProvider.Meter("github.com/open-telemetry/opentelemetry-collector-contrib/processor/drainprocessor")
+	return settings.MeterProvider.Meter("github.com/open-telemetry/opentelemetry-collector-contrib/internal/drain")
 }
 
 func Tracer(settings component.TelemetrySettings) trace.Tracer {
-	return settings.TracerProvider.Tracer("github.com/open-telemetry/opentelemetry-collector-contrib/processor/drainprocessor")
+	return settings.TracerProvider.Tracer("github.com/open-telemetry/opentelemetry-collector-contrib/internal/drain")
 }
 
 // TelemetryBuilder provides an interface for components to report telemetry
@@ -28,6 +28,7 @@ type TelemetryBuilder struct {
 	ProcessorDrainClustersActive      metric.Int64Gauge
 	ProcessorDrainLogRecordsAnnotated metric.Int64Counter
 	ProcessorDrainMasksDuplicates     metric.Int64Counter
+	ProcessorDrainTemplatesNew        metric.Int64Counter
 }
 
 // TelemetryBuilderOption applies changes to default builder.
@@ -77,5 +78,11 @@ func NewTelemetryBuilder(settings component.TelemetrySettings, options ...Teleme
 		metric.WithUnit("{records}"),
 	)
 	errs = errors.Join(errs, err)
+	builder.ProcessorDrainTemplatesNew, err = builder.meter.Int64Counter(
+		"otelcol_processor_drain_templates_new",
+		metric.WithDescription("Number of templates created by a live log record after warmup. [Development]"),
+		metric.WithUnit("{templates}"),
+	)
+	errs = errors.Join(errs, err)
 	return &builder, errs
 }
@@ -11,7 +11,7 @@ import (
 	"go.opentelemetry.io/otel/sdk/metric/metricdata"
 	"go.opentelemetry.io/otel/sdk/metric/metricdata/metricdatatest"
 
-	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/drainprocessor/internal/metadata"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/drain/internal/metadata"
 )
 
 func TestSetupTelemetry(t *testing.T) {
@@ -22,6 +22,7 @@ func TestSetupTelemetry(t *testing.T) {
 	tb.ProcessorDrainClustersActive.Record(context.Background(), 1)
 	tb.ProcessorDrainLogRecordsAnnotated.Add(context.Background(), 1)
 	tb.ProcessorDrainMasksDuplicates.Add(context.Background(), 1)
+	tb.ProcessorDrainTemplatesNew.Add(context.Background(), 1)
 	AssertEqualProcessorDrainClustersActive(t, testTel,
 		[]metricdata.DataPoint[int64]{{Value: 1}},
 		metricdatatest.IgnoreTimestamp())
@@ -31,6 +32,9 @@ func TestSetupTelemetry(t *testing.T) {
 	AssertEqualProcessorDrainMasksDuplicates(t, testTel,
 		[]metricdata.DataPoint[int64]{{Value: 1}},
 		metricdatatest.IgnoreTimestamp())
+	AssertEqualProcessorDrainTemplatesNew(t, testTel,
+		[]metricdata.DataPoint[int64]{{Value: 1}},
+		metricdatatest.IgnoreTimestamp())
 
 	require.NoError(t, testTel.Shutdown(context.Background()))
 }
@@ -1,13 +1,13 @@
 // Copyright The OpenTelemetry Authors
 // SPDX-License-Identifier: Apache-2.0
 
-// Package drain wraps the go-drain3 library behind a minimal, stable API used
-// by the drain processor. All callers go through this package; the underlying
-// library can be swapped without touching the processor.
+// Package tree wraps the go-drain3 library behind a minimal, stable API used
+// by the drain processor and connector. All callers go through this package;
+// the underlying library can be swapped without touching the components.
 //
 // Thread safety: Drain is NOT goroutine-safe. Callers must serialize access
 // (e.g. with a sync.Mutex).
-package drain // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/drainprocessor/internal/drain"
+package tree // import "github.com/open-telemetry/opentelemetry-collector-contrib/internal/drain/internal/tree"
 
 import (
 	"encoding/json"
@@ -32,6 +32,17 @@ type Config struct {
 	ExtraDelimiters []string
 }
 
+// Cluster describes a single cluster of the parse tree.
+type Cluster struct {
+	// ID is the cluster number assigned by this tree. It depends on the order
+	// lines were trained in, so it is not comparable across trees.
+	ID int64
+	// Template is the cluster's current template string.
+	Template string
+	// Size is the number of lines trained into the cluster.
+	Size int64
+}
+
 // Drain wraps the go-drain3 log clustering engine.
 type Drain struct {
 	inner           *drain3.Drain
@@ -76,36 +87,59 @@ func (d *Drain) Tokenise(s string) []string {
 }
 
 // Train feeds line to the Drain tree, updating or creating a cluster.
-// Returns the derived template string and its underlying token slice (already
-// split on " "), avoiding the need for callers to re-split the template.
-// An error is returned only on internal go-drain3 failures; callers should
-// log a warning and skip annotation rather than failing the pipeline.
-func (d *Drain) Train(line string) (templateStr string, tokens []string, err error) {
+// Returns the cluster the line was trained into, with its derived template
+// string, and the template's underlying token slice (already split on " "),
+// avoiding the need for callers to re-split the template. A zero Cluster
+// means the line could not be clustered. An error is returned only on
+// internal go-drain3 failures; callers should log a warning and skip
+// annotation rather than failing the pipeline.
+func (d *Drain) Train(line string) (Cluster, []string, error) {
 	cluster, _, err := d.inner.AddLogMessage(line)
 	if err != nil {
-		return "", nil, err
+		return Cluster{}, nil, err
 	}
 	if cluster == nil {
 		// go-drain3 returned no cluster without an error; treat as unannotatable.
-		return "", nil, nil
+		return Cluster{}, nil, nil
 	}
-	return cluster.GetTemplate(), cluster.LogTemplateTokens, nil
+	return toCluster(cluster), cluster.LogTemplateTokens, nil
 }
 
 // Match searches the existing tree for a cluster matching line without
 // creating new clusters. Returns ok=false if no cluster matches.
-func (d *Drain) Match(line string) (templateStr string, ok bool) {
+func (d *Drain) Match(line string) (Cluster, bool) {
 	cluster, err := d.inner.Match(line, drain3.SearchStrategyFallback)
 	if err != nil || cluster == nil {
-		return "", false
+		return Cluster{}, false
+	}
+	return toCluster(cluster), true
+}
+
+// Clusters returns every cluster currently tracked in the tree, in no
+// particular order.
+func (d *Drain) Clusters() []Cluster {
+	inner := d.inner.GetClusters()
+	clusters := make([]Cluster, 0, len(inner))
+	for _, c := range inner {
+		clusters = append(clusters, toCluster(c))
 	}
-	return cluster.GetTemplate(), true
+	return clusters
+}
+
+func toCluster(c *drain3.LogCluster) Cluster {
+	return Cluster{ID: c.ClusterId, Template: c.GetTemplate(), Size: c.Size}
+}
+
+// ClusterIDs returns the IDs of every cluster currently tracked in the tree,
+// without building their templates.
+func (d *Drain) ClusterIDs() []int64 {
+	return d.inner.IdToCluster.Keys()
 }
 
 // ClusterCount returns the number of clusters currently tracked in the tree.
 // Must be called with the caller's mutex held if concurrent access is possible.
 func (d *Drain) ClusterCount() int {
-	return len(d.inner.GetClusters())
+	return d.inner.IdToCluster.Len()
 }
 
 // Snapshot serializes the current tree state to JSON.
@@ -1,7 +1,7 @@
 // Copyright The OpenTelemetry Authors
 // SPDX-License-Identifier: Apache-2.0
 
-package drain
+package tree
 
 import (
 	"strings"
@@ -47,9 +47,9 @@ func TestTrainSimilarLinesShareTemplate(t *testing.T) {
 	// "connected to host <IP> on port <PORT>" — first 3 tokens identical
 	var templates []string
 	for _, line := range connectedLines {
-		tmpl, _, err := d.Train(line)
+		cluster, _, err := d.Train(line)
 		require.NoError(t, err)
-		templates = append(templates, tmpl)
+		templates = append(templates, cluster.Template)
 	}
 
 	// The first line creates a new cluster with itself as the template; abstraction
@@ -65,12 +65,13 @@ func TestTrainDistinctLinesGetDifferentClusters(t *testing.T) {
 	d, err := NewDrain(defaultCfg())
 	require.NoError(t, err)
 
-	tmpl1, _, err1 := d.Train("connected to host 10.0.0.1 on port 443")
+	c1, _, err1 := d.Train("connected to host 10.0.0.1 on port 443")
 	require.NoError(t, err1)
-	tmpl2, _, err2 := d.Train("disk write error on device sda")
+	c2, _, err2 := d.Train("disk write error on device sda")
 	require.NoError(t, err2)
 
-	assert.NotEqual(t, tmpl1, tmpl2, "structurally different lines should get different templates")
+	assert.NotEqual(t, c1.Template, c2.Template, "structurally different lines should get different templates")
+	assert.NotEqual(t, c1.ID, c2.ID)
 }
 
 // TestMatchAfterTemplateAbstracts verifies that Match finds an existing cluster
@@ -81,18 +82,18 @@ func TestMatchAfterTemplateAbstracts(t *testing.T) {
 	require.NoError(t, err)
 
 	// Build the cluster with enough examples to abstract the template.
-	var trainTmpl string
+	var trained Cluster
 	for _, line := range connectedLines {
 		var err error
-		trainTmpl, _, err = d.Train(line)
+		trained, _, err = d.Train(line)
 		require.NoError(t, err)
 	}
 
 	// Match a new, unseen line with the same structure.
-	matchTmpl, ok := d.Match("connected to host 10.10.10.10 on port 9000")
+	matched, ok := d.Match("connected to host 10.10.10.10 on port 9000")
 	require.True(t, ok, "line matching the abstracted template should be found")
-	assert.Equal(t, trainTmpl, matchTmpl)
-	assert.Contains(t, matchTmpl, "<*>")
+	assert.Equal(t, trained, matched)
+	assert.Contains(t, matched.Template, "<*>")
 }
 
 // TestMatchDoesNotCreateClusters confirms that Match on an empty tree always
@@ -110,10 +111,10 @@ func TestSnapshotRoundtrip(t *testing.T) {
 	d, err := NewDrain(defaultCfg())
 	require.NoError(t, err)
 
-	var trainTmpl string
+	var trained Cluster
 	for _, line := range connectedLines {
 		var trainErr error
-		trainTmpl, _, trainErr = d.Train(line)
+		trained, _, trainErr = d.Train(line)
 		require.NoError(t, trainErr)
 	}
 
@@ -125,9 +126,9 @@ func TestSnapshotRoundtrip(t *testing.T) {
 	require.NoError(t, err)
 	require.NoError(t, d2.Load(snap))
 
-	matchTmpl, ok := d2.Match("connected to host 10.10.10.10 on port 9000")
+	matched, ok := d2.Match("connected to host 10.10.10.10 on port 9000")
 	require.True(t, ok, "restored drain should match lines fitting the trained template")
-	assert.Equal(t, trainTmpl, matchTmpl)
+	assert.Equal(t, trained, matched, "restored clusters keep their IDs and sizes")
 }
 
 // TestTrainReturnsTokensConsistentWithTemplate verifies that the token slice
@@ -137,10 +138,10 @@ func TestTrainReturnsTokensConsistentWithTemplate(t *testing.T) {
 	require.NoError(t, err)
 
 	for _, line := range connectedLines {
-		tmpl, tokens, err := d.Train(line)
+		cluster, tokens, err := d.Train(line)
 		require.NoError(t, err)
 		require.NotEmpty(t, tokens)
-		assert.Equal(t, tmpl, strings.Join(tokens, " "), "joined tokens should equal the returned template")
+		assert.Equal(t, cluster.Template, strings.Join(tokens, " "), "joined tokens should equal the returned template")
 	}
 }
 
@@ -169,6 +170,26 @@ func TestTokeniseWithExtraDelimiters(t *testing.T) {
 	assert.Equal(t, []string{"key", "val", "foo"}, d.Tokenise("key:val foo"))
 }
 
+// TestClusters verifies that Clusters lists every cluster with its current
+// template and the number of lines trained into it.
+func TestClusters(t *testing.T) {
+	d, err := NewDrain(defaultCfg())
+	require.NoError(t, err)
+
+	var connected Cluster
+	for _, line := range connectedLines {
+		connected, _, err = d.Train(line)
+		require.NoError(t, err)
+	}
+	disk, _, err := d.Train("disk write error on device sda")
+	require.NoError(t, err)
+
+	assert.ElementsMatch(t, []Cluster{
+		{ID: connected.ID, Template: connected.Template, Size: 3},
+		{ID: disk.ID, Template: "disk write error on device sda", Size: 1},
+	}, d.Clusters())
+}
+
 func TestUnlimitedMaxClusters(t *testing.T) {
 	cfg := defaultCfg()
 	cfg.MaxClusters = 0
@@ -1,28 +1,29 @@
 // Copyright The OpenTelemetry Authors
 // SPDX-License-Identifier: Apache-2.0
 
-package drainprocessor // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/drainprocessor"
+package drain // import "github.com/open-telemetry/opentelemetry-collector-contrib/internal/drain"
 
 import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
 	"regexp"
 	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/extension/xextension/storage"
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/plog"
-	"go.opentelemetry.io/collector/processor"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/metric"
 	"go.uber.org/zap"
 
-	internaldrain "github.com/open-telemetry/opentelemetry-collector-contrib/processor/drainprocessor/internal/drain"
-	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/drainprocessor/internal/metadata"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/drain/internal/metadata"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/drain/internal/tree"
 )
 
 // compiledMaskRule is a MaskingRule with its pattern pre-compiled and its
@@ -33,15 +34,22 @@ type compiledMaskRule struct {
 	pattern *regexp.Regexp
 }
 
-type drainProcessor struct {
-	config      *Config
-	componentID component.ID
-	logger      *zap.Logger
-	telemetry   *metadata.TelemetryBuilder
+// Processor applies the Drain algorithm to log records and annotates them
+// with their template. It holds the state shared by the processor and the
+// connector: the tree, the template catalog and its persistence.
+type Processor struct {
+	config        *Config
+	componentKind component.Kind
+	componentID   component.ID
+	logger        *zap.Logger
+	telemetry     *metadata.TelemetryBuilder
 
 	mu       sync.Mutex
-	drain    *internaldrain.Drain
+	drain    *tree.Drain
 	warmedUp bool // true when WarmupMinClusters == 0 or cluster count has reached the threshold
+	// clusters tracks first/last seen times and imported template IDs per
+	// cluster ID, for the template catalog.
+	clusters map[int64]*clusterInfo
 
 	// masks holds compiled masking rules in declaration order.
 	masks []compiledMaskRule
@@ -53,10 +61,15 @@ type drainProcessor struct {
 	storageClient    storage.Client
 	stopSave         context.CancelFunc // cancels periodic save goroutine
 	lastSnapshotHash atomic.Uint64
+
+	stopExport context.CancelFunc // cancels periodic catalog export goroutine
+	server     *http.Server
+	serverDone sync.WaitGroup
 }
 
-func newDrainProcessor(set processor.Settings, cfg *Config) (*drainProcessor, error) {
-	d, err := internaldrain.NewDrain(internaldrain.Config{
+// NewProcessor creates the Processor of the component id of the given kind.
+func NewProcessor(set component.TelemetrySettings, kind component.Kind, id component.ID, cfg *Config) (*Processor, error) {
+	d, err := tree.NewDrain(tree.Config{
 		Depth:           cfg.TreeDepth,
 		SimThreshold:    cfg.MergeThreshold,
 		MaxChildren:     cfg.MaxNodeChildren,
@@ -72,18 +85,20 @@ func newDrainProcessor(set processor.Settings, cfg *Config) (*drainProcessor, er
 		return nil, err
 	}
 
-	tel, err := metadata.NewTelemetryBuilder(set.TelemetrySettings)
+	tel, err := metadata.NewTelemetryBuilder(set)
 	if err != nil {
 		return nil, err
 	}
 
-	p := &drainProcessor{
+	p := &Processor{
 		config:         cfg,
-		componentID:    set.ID,
+		componentKind:  kind,
+		componentID:    id,
 		logger:         set.Logger,
 		telemetry:      tel,
 		drain:          d,
 		warmedUp:       cfg.WarmupMinClusters == 0,
+		clusters:       make(map[int64]*clusterInfo),
 		masks:          masks,
 		maskTokenNames: tokenNames,
 	}
@@ -113,7 +128,7 @@ func compileMaskingRules(rules []MaskingRule) ([]compiledMaskRule, map[string]st
 
 // applyMasks returns text with each configured masking rule applied in order.
 // Empty input and no-rule configurations short-circuit.
-func (p *drainProcessor) applyMasks(text string) string {
+func (p *Processor) applyMasks(text string) string {
 	if len(p.masks) == 0 || text == "" {
 		return text
 	}
@@ -130,7 +145,7 @@ func (p *drainProcessor) applyMasks(text string) string {
 // SeedTemplates are trained verbatim — the user is declaring template shape,
 // so mask tokens they include appear as-is. SeedLogs go through the same
 // masking pass as live records so the tree learns the same shape.
-func (p *drainProcessor) seed() {
+func (p *Processor) seed() {
 	for _, tmpl := range p.config.SeedTemplates {
 		if strings.TrimSpace(tmpl) == "" {
 			continue
@@ -150,12 +165,13 @@ func (p *drainProcessor) seed() {
 	}
 }
 
-// Start loads a snapshot from storage (if available) and starts the periodic
-// save goroutine when configured.
-func (p *drainProcessor) Start(ctx context.Context, host component.Host) error {
+// Start loads a snapshot from storage (if available), imports the template
+// catalog, and starts the periodic save and export goroutines and the catalog
+// server when configured.
+func (p *Processor) Start(ctx context.Context, host component.Host) error {
 	if p.config.Storage != nil {
 		var err error
-		p.storageClient, err = getStorageClient(ctx, host, p.config.Storage, p.componentID)
+		p.storageClient, err = getStorageClient(ctx, host, p.config.Storage, p.componentKind, p.componentID)
 		if err != nil {
 			return fmt.Errorf("failed to get storage client: %w", err)
 		}
@@ -167,21 +183,53 @@ func (p *drainProcessor) Start(ctx context.Context, host component.Host) error {
 		if p.config.SaveInterval > 0 {
 			p.startPeriodicSave(ctx)
 		}
-		return nil
+	} else {
+		p.seed()
 	}
 
-	p.seed()
+	if p.config.Catalog.ImportPath != "" {
+		if err := p.importCatalog(); err != nil {
+			return err
+		}
+	}
+	p.mu.Lock()
+	p.trackClusters()
+	p.mu.Unlock()
+	if p.config.Catalog.ExportInterval > 0 {
+		p.startPeriodicExport()
+	}
+	if p.config.Catalog.HTTP != nil {
+		if err := p.startCatalogServer(ctx); err != nil {
+			return fmt.Errorf("failed to start template catalog server: %w", err)
+		}
+	}
 	return nil
 }
 
-// Shutdown stops the periodic save goroutine, performs a final snapshot save,
-// and closes the storage client.
-func (p *drainProcessor) Shutdown(ctx context.Context) error {
+// Shutdown stops the periodic save and export goroutines and the catalog
+// server, performs a final snapshot save and catalog export, and closes the
+// storage client.
+func (p *Processor) Shutdown(ctx context.Context) error {
 	if p.stopSave != nil {
 		p.stopSave()
 	}
+	if p.stopExport != nil {
+		p.stopExport()
+	}
 
 	var errs []error
+	if p.server != nil {
+		if err := p.server.Shutdown(ctx); err != nil {
+			errs = append(errs, err)
+		}
+		p.serverDone.Wait()
+	}
+	if p.config.Catalog.ExportPath != "" {
+		if err := p.exportCatalog(); err != nil {
+			p.logger.Warn("final template catalog export failed", zap.Error(err))
+			errs = append(errs, err)
+		}
+	}
 	if p.storageClient != nil {
 		if err := p.saveSnapshot(ctx); err != nil {
 			p.logger.Warn("final snapshot save failed", zap.Error(err))
@@ -194,36 +242,64 @@ func (p *drainProcessor) Shutdown(ctx context.Context) error {
 	return errors.Join(errs...)
 }
 
-// processLogs is the ConsumeLogs handler passed to processorhelper.NewLogs.
-func (p *drainProcessor) processLogs(ctx context.Context, ld plog.Logs) (plog.Logs, error) {
+// ProcessLogs is the ConsumeLogs handler passed to processorhelper.NewLogs.
+func (p *Processor) ProcessLogs(ctx context.Context, ld plog.Logs) (plog.Logs, error) {
+	p.AnnotateLogs(ctx, ld, nil)
+	return ld, nil
+}
+
+// Annotation describes a log record annotated with a template.
+type Annotation struct {
+	Template string
+	ID       string
+	// Size is the size of the text the template was derived from.
+	Size int
+	// IsNew is true when the record created the template after warmup.
+	IsNew bool
+}
+
+// AnnotateLogs annotates the records of ld and, when record is not nil,
+// calls it for every annotated record.
+func (p *Processor) AnnotateLogs(ctx context.Context, ld plog.Logs, record func(pcommon.Resource, plog.LogRecord, Annotation)) {
+	now := time.Now()
 	rls := ld.ResourceLogs()
 	for i := 0; i < rls.Len(); i++ {
 		sls := rls.At(i).ScopeLogs()
 		for j := 0; j < sls.Len(); j++ {
 			lrs := sls.At(j).LogRecords()
 			for k := 0; k < lrs.Len(); k++ {
-				p.annotate(ctx, lrs.At(k))
+				if a, ok := p.annotate(ctx, lrs.At(k), now); ok && record != nil {
+					record(rls.At(i).Resource(), lrs.At(k), a)
+				}
 			}
 		}
 	}
 
 	p.mu.Lock()
 	count := p.drain.ClusterCount()
+	if p.config.MaxClusters > 0 {
+		p.pruneClusterInfo()
+	}
 	p.mu.Unlock()
 	p.telemetry.ProcessorDrainClustersActive.Record(ctx, int64(count))
-
-	return ld, nil
 }
 
-func (p *drainProcessor) annotate(ctx context.Context, lr plog.LogRecord) {
+// annotate trains lr into the tree and writes its template attributes. It
+// returns the annotation and true when lr was annotated.
+func (p *Processor) annotate(ctx context.Context, lr plog.LogRecord, now time.Time) (Annotation, bool) {
 	raw := extractBody(lr, p.config.BodyField)
 	if raw == "" {
-		return
+		return Annotation{}, false
 	}
 	masked := p.applyMasks(raw)
 
 	p.mu.Lock()
-	tmpl, tmplTokens, err := p.drain.Train(masked)
+	cluster, tmplTokens, err := p.drain.Train(masked)
+	tmpl := cluster.Template
+	var id string
+	if err == nil && tmpl != "" {
+		id = p.observe(cluster, now)
+	}
 	if !p.warmedUp && p.drain.ClusterCount() >= p.config.WarmupMinClusters {
 		p.warmedUp = true
 	}
@@ -232,17 +308,28 @@ func (p *drainProcessor) annotate(ctx context.Context, lr plog.LogRecord) {
 
 	if err != nil {
 		p.logger.Warn("drain Train failed, skipping annotation", zap.Error(err))
-		return
+		return Annotation{}, false
 	}
 	if tmpl == "" || !warmedUp {
-		return
+		return Annotation{}, false
 	}
 
 	lr.Attributes().PutStr(p.config.TemplateAttribute, tmpl)
+	if p.config.TemplateIDAttribute != "" {
+		lr.Attributes().PutStr(p.config.TemplateIDAttribute, id)
+	}
 	if len(p.masks) > 0 || p.config.EmitWildcards {
 		p.extractParams(ctx, lr, raw, tmplTokens)
 	}
 	p.telemetry.ProcessorDrainLogRecordsAnnotated.Add(ctx, 1)
+
+	// A cluster of size 1 was created by this record: seeded, imported and
+	// restored clusters have already been trained at least once.
+	isNew := cluster.Size == 1
+	if isNew {
+		p.telemetry.ProcessorDrainTemplatesNew.Add(ctx, 1)
+	}
+	return Annotation{Template: tmpl, ID: id, Size: len(raw), IsNew: isNew}, true
 }
 
 // extractParams writes extracted parameters to attributes on lr:
@@ -260,7 +347,7 @@ func (p *drainProcessor) annotate(ctx context.Context, lr plog.LogRecord) {
 // fewer tokens than the raw line, alignment breaks, and this function skips
 // extraction rather than emit misaligned values. The template attribute is
 // still written by the caller.
-func (p *drainProcessor) extractParams(ctx context.Context, lr plog.LogRecord, rawBody string, tmplTokens []string) {
+func (p *Processor) extractParams(ctx context.Context, lr plog.LogRecord, rawBody string, tmplTokens []string) {
 	bodyTokens := p.drain.Tokenise(rawBody)
 	if len(bodyTokens) == 0 || len(bodyTokens) != len(tmplTokens) {
 		return
@@ -1,7 +1,7 @@
 // Copyright The OpenTelemetry Authors
 // SPDX-License-Identifier: Apache-2.0
 
-package drainprocessor
+package drain
 
 import (
 	"context"
@@ -14,24 +14,24 @@ import (
 	"go.opentelemetry.io/collector/component/componenttest"
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/plog"
-	"go.opentelemetry.io/collector/processor/processortest"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/sdk/metric/metricdata"
 	"go.opentelemetry.io/otel/sdk/metric/metricdata/metricdatatest"
 
-	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/drainprocessor/internal/metadata"
-	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/drainprocessor/internal/metadatatest"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/drain/internal/metadatatest"
 )
 
-func newTestProcessor(t *testing.T, cfg *Config) *drainProcessor {
+// testID is the ID of the component the processors under test belong to.
+var testID = component.MustNewID("drain")
+
+func newTestProcessor(t *testing.T, cfg *Config) *Processor {
 	t.Helper()
 	return newTestProcessorWithHost(t, cfg, componenttest.NewNopHost())
 }
 
-func newTestProcessorWithHost(t *testing.T, cfg *Config, host component.Host) *drainProcessor {
+func newTestProcessorWithHost(t *testing.T, cfg *Config, host component.Host) *Processor {
 	t.Helper()
-	set := processortest.NewNopSettings(metadata.Type)
-	p, err := newDrainProcessor(set, cfg)
+	p, err := NewProcessor(componenttest.NewNopTelemetrySettings(), component.KindProcessor, testID, cfg)
 	require.NoError(t, err)
 	require.NoError(t, p.Start(t.Context(), host))
 	t.Cleanup(func() { require.NoError(t, p.Shutdown(t.Context())) })
@@ -72,9 +72,9 @@ func templateAttr(t *testing.T, ld plog.Logs) string {
 // TestAnnotatesTemplate verifies that the template attribute is set after a
 // single log record is processed.
 func TestAnnotatesTemplate(t *testing.T) {
-	p := newTestProcessor(t, createDefaultConfig().(*Config))
+	p := newTestProcessor(t, NewDefaultConfig())
 
-	out, err := p.processLogs(t.Context(), makeLogRecord("connected to host 10.0.0.1 on port 443"))
+	out, err := p.ProcessLogs(t.Context(), makeLogRecord("connected to host 10.0.0.1 on port 443"))
 	require.NoError(t, err)
 
 	assert.NotEmpty(t, templateAttr(t, out))
@@ -86,7 +86,7 @@ func TestAnnotatesTemplate(t *testing.T) {
 // The first 3 tokens must be identical for go-drain3's prefix tree to route
 // all lines to the same leaf node.
 func TestSimilarLinesSameTemplate(t *testing.T) {
-	p := newTestProcessor(t, createDefaultConfig().(*Config))
+	p := newTestProcessor(t, NewDefaultConfig())
 
 	lines := []string{
 		"connected to host 10.0.0.1 on port 443",
@@ -96,7 +96,7 @@ func TestSimilarLinesSameTemplate(t *testing.T) {
 
 	var outs []plog.Logs
 	for _, line := range lines {
-		out, err := p.processLogs(t.Context(), makeLogRecord(line))
+		out, err := p.ProcessLogs(t.Context(), makeLogRecord(line))
 		require.NoError(t, err)
 		outs = append(outs, out)
 	}
@@ -112,11 +112,11 @@ func TestSimilarLinesSameTemplate(t *testing.T) {
 // TestCustomAttributeName verifies that the configured attribute key is used
 // instead of the default.
 func TestCustomAttributeName(t *testing.T) {
-	cfg := createDefaultConfig().(*Config)
+	cfg := NewDefaultConfig()
 	cfg.TemplateAttribute = "my.template"
 	p := newTestProcessor(t, cfg)
 
-	out, err := p.processLogs(t.Context(), makeLogRecord("connected to host 10.0.0.1"))
+	out, err := p.ProcessLogs(t.Context(), makeLogRecord("connected to host 10.0.0.1"))
 	require.NoError(t, err)
 
 	_, ok := getFirstRecord(out).Attributes().Get("my.template")
@@ -126,7 +126,7 @@ func TestCustomAttributeName(t *testing.T) {
 // TestBodyFieldExtraction verifies that BodyField pulls the named field from a
 // structured map body rather than using the full body string.
 func TestBodyFieldExtraction(t *testing.T) {
-	cfg := createDefaultConfig().(*Config)
+	cfg := NewDefaultConfig()
 	cfg.BodyField = "message"
 	p := newTestProcessor(t, cfg)
 
@@ -138,7 +138,7 @@ func TestBodyFieldExtraction(t *testing.T) {
 	var lastOut plog.Logs
 	for _, msg := range msgs {
 		var err error
-		lastOut, err = p.processLogs(t.Context(), makeMapBodyLogRecord("message", msg))
+		lastOut, err = p.ProcessLogs(t.Context(), makeMapBodyLogRecord("message", msg))
 		require.NoError(t, err)
 	}
 
@@ -150,9 +150,9 @@ func TestBodyFieldExtraction(t *testing.T) {
 // TestEmptyBodySkipped verifies that empty log bodies do not receive template
 // attributes.
 func TestEmptyBodySkipped(t *testing.T) {
-	p := newTestProcessor(t, createDefaultConfig().(*Config))
+	p := newTestProcessor(t, NewDefaultConfig())
 
-	out, err := p.processLogs(t.Context(), makeLogRecord(""))
+	out, err := p.ProcessLogs(t.Context(), makeLogRecord(""))
 	require.NoError(t, err)
 
 	_, ok := getFirstRecord(out).Attributes().Get("log.record.template")
@@ -162,7 +162,7 @@ func TestEmptyBodySkipped(t *testing.T) {
 // TestMultipleResourceLogs verifies that records across multiple resource log
 // groups are all annotated.
 func TestMultipleResourceLogs(t *testing.T) {
-	p := newTestProcessor(t, createDefaultConfig().(*Config))
+	p := newTestProcessor(t, NewDefaultConfig())
 
 	ld := plog.NewLogs()
 	for range 3 {
@@ -170,7 +170,7 @@ func TestMultipleResourceLogs(t *testing.T) {
 		lr.Body().SetStr("heartbeat ping from server")
 	}
 
-	out, err := p.processLogs(t.Context(), ld)
+	out, err := p.ProcessLogs(t.Context(), ld)
 	require.NoError(t, err)
 
 	for i := 0; i < out.ResourceLogs().Len(); i++ {
@@ -184,13 +184,13 @@ func TestMultipleResourceLogs(t *testing.T) {
 // clusters before any live logs arrive, so the first matching live record gets
 // a stable cluster ID.
 func TestSeedTemplatesPrePopulateTree(t *testing.T) {
-	cfg := createDefaultConfig().(*Config)
+	cfg := NewDefaultConfig()
 	cfg.SeedTemplates = []string{
 		"connected to host <*> on port <*>",
 	}
 	p := newTestProcessor(t, cfg)
 
-	out, err := p.processLogs(t.Context(), makeLogRecord("connected to host 10.0.0.1 on port 443"))
+	out, err := p.ProcessLogs(t.Context(), makeLogRecord("connected to host 10.0.0.1 on port 443"))
 	require.NoError(t, err)
 
 	tmpl := templateAttr(t, out)
@@ -200,7 +200,7 @@ func TestSeedTemplatesPrePopulateTree(t *testing.T) {
 // TestSeedLogsPrePopulateTree verifies that seed_logs trains the tree before
 // any live logs arrive.
 func TestSeedLogsPrePopulateTree(t *testing.T) {
-	cfg := createDefaultConfig().(*Config)
+	cfg := NewDefaultConfig()
 	cfg.SeedLogs = []string{
 		"connected to host 10.0.0.1 on port 443",
 		"connected to host 192.168.1.1 on port 8080",
@@ -208,7 +208,7 @@ func TestSeedLogsPrePopulateTree(t *testing.T) {
 	}
 	p := newTestProcessor(t, cfg)
 
-	out, err := p.processLogs(t.Context(), makeLogRecord("connected to host 10.10.10.10 on port 9000"))
+	out, err := p.ProcessLogs(t.Context(), makeLogRecord("connected to host 10.10.10.10 on port 9000"))
 	require.NoError(t, err)
 
 	tmpl := templateAttr(t, out)
@@ -218,13 +218,13 @@ func TestSeedLogsPrePopulateTree(t *testing.T) {
 // TestEmptySeedEntriesSkipped verifies that blank entries in seed lists do not
 // cause errors or get added to the tree.
 func TestEmptySeedEntriesSkipped(t *testing.T) {
-	cfg := createDefaultConfig().(*Config)
+	cfg := NewDefaultConfig()
 	cfg.SeedTemplates = []string{"", "   ", "connected to host <*> on port <*>"}
 	cfg.SeedLogs = []string{"", "   "}
 
 	p := newTestProcessor(t, cfg)
 
-	out, err := p.processLogs(t.Context(), makeLogRecord("connected to host 10.0.0.1 on port 443"))
+	out, err := p.ProcessLogs(t.Context(), makeLogRecord("connected to host 10.0.0.1 on port 443"))
 	require.NoError(t, err)
 	assert.NotEmpty(t, templateAttr(t, out))
 }
@@ -233,19 +233,19 @@ func TestEmptySeedEntriesSkipped(t *testing.T) {
 // records pass through immediately but are not annotated until the cluster
 // threshold is reached.
 func TestWarmupMinClustersSuppress(t *testing.T) {
-	cfg := createDefaultConfig().(*Config)
+	cfg := NewDefaultConfig()
 	cfg.WarmupMinClusters = 2
 	p := newTestProcessor(t, cfg)
 
 	// First record: one cluster, below threshold — should pass through unannotated.
-	out1, err := p.processLogs(t.Context(), makeLogRecord("connected to host 10.0.0.1 on port 443"))
+	out1, err := p.ProcessLogs(t.Context(), makeLogRecord("connected to host 10.0.0.1 on port 443"))
 	require.NoError(t, err)
 	assert.Equal(t, 1, out1.LogRecordCount(), "record should pass through immediately, not buffered")
 	_, ok := getFirstRecord(out1).Attributes().Get("log.record.template")
 	assert.False(t, ok, "record should not be annotated during warmup")
 
 	// Second record: distinct pattern, reaches threshold — should be annotated.
-	out2, err := p.processLogs(t.Context(), makeLogRecord("disk write error on device sda"))
+	out2, err := p.ProcessLogs(t.Context(), makeLogRecord("disk write error on device sda"))
 	require.NoError(t, err)
 	assert.Equal(t, 1, out2.LogRecordCount(), "record should pass through immediately")
 	_, ok = getFirstRecord(out2).Attributes().Get("log.record.template")
@@ -286,15 +286,15 @@ func ipRule() MaskingRule {
 // (no masking rules, wildcards off) no parameter or wildcards attributes
 // are written even when a template abstracts.
 func TestNoMasksNoWildcardsNoParams(t *testing.T) {
-	p := newTestProcessor(t, createDefaultConfig().(*Config))
+	p := newTestProcessor(t, NewDefaultConfig())
 	for _, line := range []string{
 		"connected to host 10.0.0.1 on port 443",
 		"connected to host 192.168.1.1 on port 8080",
 	} {
-		_, err := p.processLogs(t.Context(), makeLogRecord(line))
+		_, err := p.ProcessLogs(t.Context(), makeLogRecord(line))
 		require.NoError(t, err)
 	}
-	out, err := p.processLogs(t.Context(), makeLogRecord("connected to host 172.16.0.1 on port 80"))
+	out, err := p.ProcessLogs(t.Context(), makeLogRecord("connected to host 172.16.0.1 on port 80"))
 	require.NoError(t, err)
 	attrs := getFirstRecord(out).Attributes()
 	attrs.Range(func(k string, _ pcommon.Value) bool {
@@ -309,7 +309,7 @@ func TestNoMasksNoWildcardsNoParams(t *testing.T) {
 // configured, the template surfaces the mask token and a dynamic attribute
 // is written per matched mask name.
 func TestMaskedTemplateEmitsDynamicParameters(t *testing.T) {
-	cfg := createDefaultConfig().(*Config)
+	cfg := NewDefaultConfig()
 	cfg.MaskingRules = []MaskingRule{ipRule()}
 	p := newTestProcessor(t, cfg)
 
@@ -321,7 +321,7 @@ func TestMaskedTemplateEmitsDynamicParameters(t *testing.T) {
 	var lastOut plog.Logs
 	for _, line := range lines {
 		var err error
-		lastOut, err = p.processLogs(t.Context(), makeLogRecord(line))
+		lastOut, err = p.ProcessLogs(t.Context(), makeLogRecord(line))
 		require.NoError(t, err)
 	}
 
@@ -338,12 +338,12 @@ func TestMaskedTemplateEmitsDynamicParameters(t *testing.T) {
 // but a fully-literal template (no variable positions), no parameter
 // attribute is written.
 func TestParametersLiteralTemplate(t *testing.T) {
-	cfg := createDefaultConfig().(*Config)
+	cfg := NewDefaultConfig()
 	cfg.MaskingRules = []MaskingRule{ipRule()}
 	p := newTestProcessor(t, cfg)
 
 	// Single line with no IP: no mask match, no <*> abstraction.
-	out, err := p.processLogs(t.Context(), makeLogRecord("disk write error on device sda"))
+	out, err := p.ProcessLogs(t.Context(), makeLogRecord("disk write error on device sda"))
 	require.NoError(t, err)
 	tmpl := templateAttr(t, out)
 	assert.NotContains(t, tmpl, "<*>")
@@ -355,7 +355,7 @@ func TestParametersLiteralTemplate(t *testing.T) {
 // TestCustomParameterKeyPrefix verifies that ParameterKeyPrefix controls the
 // attribute key namespace for extracted parameters.
 func TestCustomParameterKeyPrefix(t *testing.T) {
-	cfg := createDefaultConfig().(*Config)
+	cfg := NewDefaultConfig()
 	cfg.MaskingRules = []MaskingRule{ipRule()}
 	cfg.ParameterKeyPrefix = "my.p"
 	p := newTestProcessor(t, cfg)
@@ -364,10 +364,10 @@ func TestCustomParameterKeyPrefix(t *testing.T) {
 		"connected to host 10.0.0.1 on port 443",
 		"connected to host 192.168.1.1 on port 8080",
 	} {
-		_, err := p.processLogs(t.Context(), makeLogRecord(line))
+		_, err := p.ProcessLogs(t.Context(), makeLogRecord(line))
 		require.NoError(t, err)
 	}
-	out, err := p.processLogs(t.Context(), makeLogRecord("connected to host 172.16.0.1 on port 80"))
+	out, err := p.ProcessLogs(t.Context(), makeLogRecord("connected to host 172.16.0.1 on port 80"))
 	require.NoError(t, err)
 
 	assert.Equal(t, "172.16.0.1", namedParam(t, out, "my.p", "ip"))
@@ -378,13 +378,13 @@ func TestCustomParameterKeyPrefix(t *testing.T) {
 // TestParametersSuppressedDuringWarmup verifies that neither named parameters
 // nor wildcards are written while warmup is in effect.
 func TestParametersSuppressedDuringWarmup(t *testing.T) {
-	cfg := createDefaultConfig().(*Config)
+	cfg := NewDefaultConfig()
 	cfg.MaskingRules = []MaskingRule{ipRule()}
 	cfg.EmitWildcards = true
 	cfg.WarmupMinClusters = 5
 	p := newTestProcessor(t, cfg)
 
-	out, err := p.processLogs(t.Context(), makeLogRecord("connected to host 10.0.0.1 on port 443"))
+	out, err := p.ProcessLogs(t.Context(), makeLogRecord("connected to host 10.0.0.1 on port 443"))
 	require.NoError(t, err)
 	_, hasTmpl := getFirstRecord(out).Attributes().Get("log.record.template")
 	require.False(t, hasTmpl, "template suppressed during warmup")
@@ -396,7 +396,7 @@ func TestParametersSuppressedDuringWarmup(t *testing.T) {
 // TestParametersWithExtraDelimiters verifies that body tokenisation honors
 // ExtraDelimiters when extracting parameter values.
 func TestParametersWithExtraDelimiters(t *testing.T) {
-	cfg := createDefaultConfig().(*Config)
+	cfg := NewDefaultConfig()
 	cfg.MaskingRules = []MaskingRule{ipRule()}
 	cfg.EmitWildcards = true
 	cfg.ExtraDelimiters = []string{":"}
@@ -409,10 +409,10 @@ func TestParametersWithExtraDelimiters(t *testing.T) {
 		"connected to host:beta from 192.168.1.1",
 		"connected to host:gamma from 172.16.0.1",
 	} {
-		_, err := p.processLogs(t.Context(), makeLogRecord(line))
+		_, err := p.ProcessLogs(t.Context(), makeLogRecord(line))
 		require.NoError(t, err)
 	}
-	out, err := p.processLogs(t.Context(), makeLogRecord("connected to host:delta from 8.8.8.8"))
+	out, err := p.ProcessLogs(t.Context(), makeLogRecord("connected to host:delta from 8.8.8.8"))
 	require.NoError(t, err)
 
 	tmpl := templateAttr(t, out)
@@ -426,7 +426,7 @@ func TestParametersWithExtraDelimiters(t *testing.T) {
 // TestParametersFromBodyField verifies extraction works when BodyField pulls
 // the message out of a structured map body.
 func TestParametersFromBodyField(t *testing.T) {
-	cfg := createDefaultConfig().(*Config)
+	cfg := NewDefaultConfig()
 	cfg.MaskingRules = []MaskingRule{ipRule()}
 	cfg.BodyField = "message"
 	p := newTestProcessor(t, cfg)
@@ -435,10 +435,10 @@ func TestParametersFromBodyField(t *testing.T) {
 		"connected to host 10.0.0.1 on port 443",
 		"connected to host 192.168.1.1 on port 8080",
 	} {
-		_, err := p.processLogs(t.Context(), makeMapBodyLogRecord("message", msg))
+		_, err := p.ProcessLogs(t.Context(), makeMapBodyLogRecord("message", msg))
 		require.NoError(t, err)
 	}
-	out, err := p.processLogs(t.Context(), makeMapBodyLogRecord("message", "connected to host 172.16.0.1 on port 80"))
+	out, err := p.ProcessLogs(t.Context(), makeMapBodyLogRecord("message", "connected to host 172.16.0.1 on port 80"))
 	require.NoError(t, err)
 
 	assert.Equal(t, "172.16.0.1", namedParam(t, out, "log.record.template.parameter", "ip"))
@@ -447,7 +447,7 @@ func TestParametersFromBodyField(t *testing.T) {
 // TestMaskingRulesApplyInOrder verifies that earlier rules run first and
 // their replacements are visible to later rules.
 func TestMaskingRulesApplyInOrder(t *testing.T) {
-	cfg := createDefaultConfig().(*Config)
+	cfg := NewDefaultConfig()
 	cfg.MaskingRules = []MaskingRule{
 		{Name: "ip", Pattern: `(\d{1,3}\.){3}\d{1,3}`},
 		{Name: "num", Pattern: `\d+`},
@@ -458,10 +458,10 @@ func TestMaskingRulesApplyInOrder(t *testing.T) {
 		"req 1 from 10.0.0.1",
 		"req 2 from 192.168.1.1",
 	} {
-		_, err := p.processLogs(t.Context(), makeLogRecord(line))
+		_, err := p.ProcessLogs(t.Context(), makeLogRecord(line))
 		require.NoError(t, err)
 	}
-	out, err := p.processLogs(t.Context(), makeLogRecord("req 3 from 8.8.8.8"))
+	out, err := p.ProcessLogs(t.Context(), makeLogRecord("req 3 from 8.8.8.8"))
 	require.NoError(t, err)
 
 	tmpl := templateAttr(t, out)
@@ -476,7 +476,7 @@ func TestMaskingRulesApplyInOrder(t *testing.T) {
 // spans whitespace, alignment breaks and no parameter or wildcards
 // attributes are written. The template attribute is still emitted.
 func TestParametersAlignmentMismatchSkips(t *testing.T) {
-	cfg := createDefaultConfig().(*Config)
+	cfg := NewDefaultConfig()
 	cfg.MaskingRules = []MaskingRule{{Name: "id", Pattern: `id: \d+`}}
 	cfg.EmitWildcards = true
 	p := newTestProcessor(t, cfg)
@@ -485,10 +485,10 @@ func TestParametersAlignmentMismatchSkips(t *testing.T) {
 		"request id: 111 done",
 		"request id: 222 done",
 	} {
-		_, err := p.processLogs(t.Context(), makeLogRecord(line))
+		_, err := p.ProcessLogs(t.Context(), makeLogRecord(line))
 		require.NoError(t, err)
 	}
-	out, err := p.processLogs(t.Context(), makeLogRecord("request id: 333 done"))
+	out, err := p.ProcessLogs(t.Context(), makeLogRecord("request id: 333 done"))
 	require.NoError(t, err)
 
 	tmpl := templateAttr(t, out)
@@ -501,7 +501,7 @@ func TestParametersAlignmentMismatchSkips(t *testing.T) {
 // TestSeedLogsGoThroughMasking verifies that SeedLogs are masked identically
 // to live records.
 func TestSeedLogsGoThroughMasking(t *testing.T) {
-	cfg := createDefaultConfig().(*Config)
+	cfg := NewDefaultConfig()
 	cfg.MaskingRules = []MaskingRule{ipRule()}
 	cfg.SeedLogs = []string{
 		"connected to host 10.0.0.1 on port 443",
@@ -510,7 +510,7 @@ func TestSeedLogsGoThroughMasking(t *testing.T) {
 	}
 	p := newTestProcessor(t, cfg)
 
-	out, err := p.processLogs(t.Context(), makeLogRecord("connected to host 8.8.8.8 on port 22"))
+	out, err := p.ProcessLogs(t.Context(), makeLogRecord("connected to host 8.8.8.8 on port 22"))
 	require.NoError(t, err)
 
 	tmpl := templateAttr(t, out)
@@ -524,7 +524,7 @@ func TestSeedLogsGoThroughMasking(t *testing.T) {
 // masking rules but EmitWildcards on, the wildcards slice surfaces all
 // variable positions in template order.
 func TestEmitWildcardsWithoutMasks(t *testing.T) {
-	cfg := createDefaultConfig().(*Config)
+	cfg := NewDefaultConfig()
 	cfg.EmitWildcards = true
 	p := newTestProcessor(t, cfg)
 
@@ -532,10 +532,10 @@ func TestEmitWildcardsWithoutMasks(t *testing.T) {
 		"connected to host 10.0.0.1 on port 443",
 		"connected to host 192.168.1.1 on port 8080",
 	} {
-		_, err := p.processLogs(t.Context(), makeLogRecord(line))
+		_, err := p.ProcessLogs(t.Context(), makeLogRecord(line))
 		require.NoError(t, err)
 	}
-	out, err := p.processLogs(t.Context(), makeLogRecord("connected to host 172.16.0.1 on port 80"))
+	out, err := p.ProcessLogs(t.Context(), makeLogRecord("connected to host 172.16.0.1 on port 80"))
 	require.NoError(t, err)
 
 	assert.Equal(t, []string{"172.16.0.1", "80"}, wildcardsAttr(t, out))
@@ -544,7 +544,7 @@ func TestEmitWildcardsWithoutMasks(t *testing.T) {
 // TestCustomWildcardsAttribute verifies WildcardsAttribute overrides the
 // default key.
 func TestCustomWildcardsAttribute(t *testing.T) {
-	cfg := createDefaultConfig().(*Config)
+	cfg := NewDefaultConfig()
 	cfg.EmitWildcards = true
 	cfg.WildcardsAttribute = "my.wildcards"
 	p := newTestProcessor(t, cfg)
@@ -553,10 +553,10 @@ func TestCustomWildcardsAttribute(t *testing.T) {
 		"connected to host 10.0.0.1 on port 443",
 		"connected to host 192.168.1.1 on port 8080",
 	} {
-		_, err := p.processLogs(t.Context(), makeLogRecord(line))
+		_, err := p.ProcessLogs(t.Context(), makeLogRecord(line))
 		require.NoError(t, err)
 	}
-	out, err := p.processLogs(t.Context(), makeLogRecord("connected to host 172.16.0.1 on port 80"))
+	out, err := p.ProcessLogs(t.Context(), makeLogRecord("connected to host 172.16.0.1 on port 80"))
 	require.NoError(t, err)
 
 	_, ok := getFirstRecord(out).Attributes().Get("my.wildcards")
@@ -573,11 +573,10 @@ func TestDuplicateMaskFirstMatchWins(t *testing.T) {
 	tel := componenttest.NewTelemetry()
 	t.Cleanup(func() { require.NoError(t, tel.Shutdown(context.Background())) }) //nolint:usetesting
 
-	cfg := createDefaultConfig().(*Config)
+	cfg := NewDefaultConfig()
 	cfg.MaskingRules = []MaskingRule{ipRule()}
 
-	set := metadatatest.NewSettings(tel)
-	p, err := newDrainProcessor(set, cfg)
+	p, err := NewProcessor(tel.NewTelemetrySettings(), component.KindProcessor, testID, cfg)
 	require.NoError(t, err)
 	require.NoError(t, p.Start(t.Context(), componenttest.NewNopHost()))
 	t.Cleanup(func() { require.NoError(t, p.Shutdown(context.Background())) }) //nolint:usetesting
@@ -590,10 +589,10 @@ func TestDuplicateMaskFirstMatchWins(t *testing.T) {
 		"traffic from 10.0.0.1 to 10.0.0.2 via 10.0.0.3",
 		"traffic from 192.168.1.1 to 192.168.1.2 via 192.168.1.3",
 	} {
-		_, err = p.processLogs(t.Context(), makeLogRecord(line))
+		_, err = p.ProcessLogs(t.Context(), makeLogRecord(line))
 		require.NoError(t, err)
 	}
-	out, err := p.processLogs(t.Context(), makeLogRecord("traffic from 8.8.8.8 to 9.9.9.9 via 7.7.7.7"))
+	out, err := p.ProcessLogs(t.Context(), makeLogRecord("traffic from 8.8.8.8 to 9.9.9.9 via 7.7.7.7"))
 	require.NoError(t, err)
 
 	tmpl := templateAttr(t, out)
@@ -616,11 +615,11 @@ func TestDuplicateMaskFirstMatchWins(t *testing.T) {
 // TestWarmupMinClustersZeroDisabled verifies that warmup_min_clusters=0
 // annotates from the first record (default behavior).
 func TestWarmupMinClustersZeroDisabled(t *testing.T) {
-	cfg := createDefaultConfig().(*Config)
+	cfg := NewDefaultConfig()
 	cfg.WarmupMinClusters = 0
 	p := newTestProcessor(t, cfg)
 
-	out, err := p.processLogs(t.Context(), makeLogRecord("connected to host 10.0.0.1 on port 443"))
+	out, err := p.ProcessLogs(t.Context(), makeLogRecord("connected to host 10.0.0.1 on port 443"))
 	require.NoError(t, err)
 	assert.NotEmpty(t, templateAttr(t, out), "should annotate from first record when warmup disabled")
 }
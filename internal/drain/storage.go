@@ -0,0 +1,164 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package drain // import "github.com/open-telemetry/opentelemetry-collector-contrib/internal/drain"
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/extension/xextension/storage"
+	"go.uber.org/zap"
+)
+
+const (
+	storageKey         = "drain_tree"
+	clustersStorageKey = "drain_clusters"
+)
+
+// storedCluster is the stored form of what the processor tracks about a
+// cluster, so that template IDs and first/last-seen times survive restarts.
+type storedCluster struct {
+	ClusterID int64     `json:"cluster_id"`
+	ID        string    `json:"id"`
+	FirstSeen time.Time `json:"first_seen,omitzero"`
+	LastSeen  time.Time `json:"last_seen,omitzero"`
+}
+
+// getStorageClient resolves a storage.Client for the component.
+func getStorageClient(ctx context.Context, host component.Host, storageID *component.ID, componentKind component.Kind, componentID component.ID) (storage.Client, error) {
+	ext, ok := host.GetExtensions()[*storageID]
+	if !ok {
+		return nil, fmt.Errorf("storage extension %q not found", storageID)
+	}
+
+	storageExt, ok := ext.(storage.Extension)
+	if !ok {
+		return nil, fmt.Errorf("extension %q is not a storage extension", storageID)
+	}
+
+	return storageExt.GetClient(ctx, componentKind, componentID, "")
+}
+
+// loadSnapshot attempts to restore tree state from storage. Returns true if a
+// valid snapshot was loaded, false otherwise (caller should seed the tree).
+func (p *Processor) loadSnapshot(ctx context.Context) bool {
+	data, err := p.storageClient.Get(ctx, storageKey)
+	if err != nil {
+		p.logger.Warn("failed to read snapshot from storage, starting fresh", zap.Error(err))
+		return false
+	}
+	if len(data) == 0 {
+		return false
+	}
+
+	if err := p.drain.Load(data); err != nil {
+		p.logger.Warn("failed to load snapshot, starting fresh", zap.Error(err))
+		return false
+	}
+
+	p.loadClusterInfo(ctx)
+
+	clusters := p.drain.ClusterCount()
+	p.logger.Info("loaded drain tree snapshot from storage", zap.Int("clusters", clusters))
+
+	if !p.warmedUp && clusters >= p.config.WarmupMinClusters {
+		p.warmedUp = true
+	}
+	return true
+}
+
+// loadClusterInfo restores what the processor tracks about the clusters of a
+// loaded tree. Clusters without stored info are assigned the ID derived from
+// their current template.
+func (p *Processor) loadClusterInfo(ctx context.Context) {
+	data, err := p.storageClient.Get(ctx, clustersStorageKey)
+	if err != nil {
+		p.logger.Warn("failed to read template IDs from storage", zap.Error(err))
+		return
+	}
+	if len(data) == 0 {
+		return
+	}
+	var stored []storedCluster
+	if err := json.Unmarshal(data, &stored); err != nil {
+		p.logger.Warn("failed to load template IDs from storage", zap.Error(err))
+		return
+	}
+	live := make(map[int64]struct{}, p.drain.ClusterCount())
+	for _, id := range p.drain.ClusterIDs() {
+		live[id] = struct{}{}
+	}
+	for _, c := range stored {
+		if _, ok := live[c.ClusterID]; ok && c.ID != "" {
+			p.clusters[c.ClusterID] = &clusterInfo{id: c.ID, firstSeen: c.FirstSeen, lastSeen: c.LastSeen}
+		}
+	}
+}
+
+// startPeriodicSave launches a background goroutine that saves the tree
+// snapshot at the configured interval.
+func (p *Processor) startPeriodicSave(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	p.stopSave = cancel
+
+	go func() {
+		ticker := time.NewTicker(p.config.SaveInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := p.saveSnapshot(ctx); err != nil {
+					p.logger.Warn("periodic snapshot save failed", zap.Error(err))
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// saveSnapshot serializes the tree and what the processor tracks about its
+// clusters, and writes them to storage. The write is skipped when the
+// snapshot hash matches the last saved hash (no changes).
+func (p *Processor) saveSnapshot(ctx context.Context) error {
+	p.mu.Lock()
+	data, err := p.drain.Snapshot()
+	stored := make([]storedCluster, 0, len(p.clusters))
+	for id, info := range p.clusters {
+		stored = append(stored, storedCluster{ClusterID: id, ID: info.id, FirstSeen: info.firstSeen, LastSeen: info.lastSeen})
+	}
+	p.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("snapshot serialization failed: %w", err)
+	}
+	slices.SortFunc(stored, func(a, b storedCluster) int { return cmp.Compare(a.ClusterID, b.ClusterID) })
+	clusters, err := json.Marshal(stored)
+	if err != nil {
+		return fmt.Errorf("template ID serialization failed: %w", err)
+	}
+
+	h := fnv.New64a()
+	h.Write(data)
+	h.Write(clusters)
+	hash := h.Sum64()
+	if hash == p.lastSnapshotHash.Load() {
+		return nil
+	}
+
+	if err := p.storageClient.Batch(ctx,
+		storage.SetOperation(storageKey, data),
+		storage.SetOperation(clustersStorageKey, clusters),
+	); err != nil {
+		return fmt.Errorf("failed to write snapshot to storage: %w", err)
+	}
+	p.lastSnapshotHash.Store(hash)
+	p.logger.Debug("saved drain tree snapshot to storage", zap.Int("bytes", len(data)+len(clusters)))
+	return nil
+}
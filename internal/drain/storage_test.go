@@ -1,7 +1,7 @@
 // Copyright The OpenTelemetry Authors
 // SPDX-License-Identifier: Apache-2.0
 
-package drainprocessor
+package drain
 
 import (
 	"testing"
@@ -11,10 +11,8 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/component/componenttest"
-	"go.opentelemetry.io/collector/processor/processortest"
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/storage/storagetest"
-	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/drainprocessor/internal/metadata"
 )
 
 func fileBackedStorageHost(t *testing.T) *storagetest.StorageHost {
@@ -29,10 +27,9 @@ func storageID() *component.ID {
 
 // newManualProcessor creates a processor without auto-cleanup, for tests that
 // need to control the Start/Shutdown lifecycle explicitly.
-func newManualProcessor(t *testing.T, cfg *Config) *drainProcessor {
+func newManualProcessor(t *testing.T, cfg *Config) *Processor {
 	t.Helper()
-	set := processortest.NewNopSettings(metadata.Type)
-	p, err := newDrainProcessor(set, cfg)
+	p, err := NewProcessor(componenttest.NewNopTelemetrySettings(), component.KindProcessor, testID, cfg)
 	require.NoError(t, err)
 	return p
 }
@@ -40,7 +37,7 @@ func newManualProcessor(t *testing.T, cfg *Config) *drainProcessor {
 // TestStartShutdownWithoutStorage verifies that the processor works when
 // storage is not configured (the default stateless mode).
 func TestStartShutdownWithoutStorage(t *testing.T) {
-	cfg := createDefaultConfig().(*Config)
+	cfg := NewDefaultConfig()
 	// Storage is nil by default.
 	p := newTestProcessor(t, cfg)
 	_ = p // Start/Shutdown handled by cleanup in newTestProcessor
@@ -55,7 +52,7 @@ func TestSnapshotPersistAndReload(t *testing.T) {
 	ctx := t.Context()
 
 	// First instance: train lines, then shutdown to save.
-	cfg1 := createDefaultConfig().(*Config)
+	cfg1 := NewDefaultConfig()
 	cfg1.Storage = sid
 	p1 := newManualProcessor(t, cfg1)
 	require.NoError(t, p1.Start(ctx, host))
@@ -66,19 +63,19 @@ func TestSnapshotPersistAndReload(t *testing.T) {
 		"connected to host 172.16.0.1 on port 80",
 	}
 	for _, line := range lines {
-		_, err := p1.processLogs(ctx, makeLogRecord(line))
+		_, err := p1.ProcessLogs(ctx, makeLogRecord(line))
 		require.NoError(t, err)
 	}
 	require.NoError(t, p1.Shutdown(ctx))
 
 	// Second instance: start with the same storage, verify loaded tree.
-	cfg2 := createDefaultConfig().(*Config)
+	cfg2 := NewDefaultConfig()
 	cfg2.Storage = sid
 	p2 := newManualProcessor(t, cfg2)
 	require.NoError(t, p2.Start(ctx, host))
 
 	// Match a new line that fits the trained pattern.
-	out, err := p2.processLogs(ctx, makeLogRecord("connected to host 10.10.10.10 on port 9000"))
+	out, err := p2.ProcessLogs(ctx, makeLogRecord("connected to host 10.10.10.10 on port 9000"))
 	require.NoError(t, err)
 
 	tmpl := templateAttr(t, out)
@@ -87,6 +84,37 @@ func TestSnapshotPersistAndReload(t *testing.T) {
 	require.NoError(t, p2.Shutdown(ctx))
 }
 
+// TestSnapshotPersistsTemplateIDs verifies that the template IDs and
+// first/last-seen times of the clusters survive a reload, rather than being
+// derived again from the generalised templates.
+func TestSnapshotPersistsTemplateIDs(t *testing.T) {
+	host := fileBackedStorageHost(t)
+	sid := storageID()
+	ctx := t.Context()
+
+	cfg := NewDefaultConfig()
+	cfg.Storage = sid
+	cfg.TemplateIDAttribute = "log.record.template.id"
+	p1 := newManualProcessor(t, cfg)
+	require.NoError(t, p1.Start(ctx, host))
+	for _, line := range loginLines {
+		templateIDAttr(t, p1, line)
+	}
+	before := p1.buildCatalog()
+	require.NoError(t, p1.Shutdown(ctx))
+
+	p2 := newManualProcessor(t, cfg)
+	require.NoError(t, p2.Start(ctx, host))
+	after := p2.buildCatalog()
+	require.Len(t, after.Templates, 1)
+	assert.Equal(t, before.Templates[0].ID, after.Templates[0].ID)
+	assert.Equal(t, TemplateID(loginLines[0]), after.Templates[0].ID)
+	assert.True(t, before.Templates[0].FirstSeen.Equal(after.Templates[0].FirstSeen))
+	assert.True(t, before.Templates[0].LastSeen.Equal(after.Templates[0].LastSeen))
+	assert.Equal(t, TemplateID(loginLines[0]), templateIDAttr(t, p2, "user dave logged in"))
+	require.NoError(t, p2.Shutdown(ctx))
+}
+
 // TestLoadedSnapshotSkipsSeed verifies that when a snapshot is loaded, seed
 // templates/logs from the config are not applied (the snapshot already
 // incorporates them).
@@ -96,25 +124,25 @@ func TestLoadedSnapshotSkipsSeed(t *testing.T) {
 	ctx := t.Context()
 
 	// First instance: train with specific pattern, shutdown.
-	cfg1 := createDefaultConfig().(*Config)
+	cfg1 := NewDefaultConfig()
 	cfg1.Storage = sid
 	cfg1.SeedTemplates = []string{"connected to host <*> on port <*>"}
 	p1 := newManualProcessor(t, cfg1)
 	require.NoError(t, p1.Start(ctx, host))
-	_, err := p1.processLogs(ctx, makeLogRecord("disk write error on device sda"))
+	_, err := p1.ProcessLogs(ctx, makeLogRecord("disk write error on device sda"))
 	require.NoError(t, err)
 	require.NoError(t, p1.Shutdown(ctx))
 
 	// Second instance: different seeds. If snapshot wins, the "disk" cluster
 	// should exist; the new seed should NOT create a fresh cluster.
-	cfg2 := createDefaultConfig().(*Config)
+	cfg2 := NewDefaultConfig()
 	cfg2.Storage = sid
 	cfg2.SeedTemplates = []string{"new pattern that should not appear <*>"}
 	p2 := newManualProcessor(t, cfg2)
 	require.NoError(t, p2.Start(ctx, host))
 
 	// The "disk" cluster from the first run should be present.
-	out, err := p2.processLogs(ctx, makeLogRecord("disk write error on device sdb"))
+	out, err := p2.ProcessLogs(ctx, makeLogRecord("disk write error on device sdb"))
 	require.NoError(t, err)
 	tmpl := templateAttr(t, out)
 	assert.Contains(t, tmpl, "disk", "loaded snapshot should have the trained disk cluster")
@@ -130,7 +158,7 @@ func TestLoadedSnapshotSkipsWarmup(t *testing.T) {
 	ctx := t.Context()
 
 	// First instance: train enough distinct clusters.
-	cfg1 := createDefaultConfig().(*Config)
+	cfg1 := NewDefaultConfig()
 	cfg1.Storage = sid
 	p1 := newManualProcessor(t, cfg1)
 	require.NoError(t, p1.Start(ctx, host))
@@ -143,13 +171,13 @@ func TestLoadedSnapshotSkipsWarmup(t *testing.T) {
 		"HTTP GET /api/v1/users returned 200",
 	}
 	for _, line := range distinctLines {
-		_, err := p1.processLogs(ctx, makeLogRecord(line))
+		_, err := p1.ProcessLogs(ctx, makeLogRecord(line))
 		require.NoError(t, err)
 	}
 	require.NoError(t, p1.Shutdown(ctx))
 
 	// Second instance: warmup threshold of 3. Loaded snapshot has 5 clusters.
-	cfg2 := createDefaultConfig().(*Config)
+	cfg2 := NewDefaultConfig()
 	cfg2.Storage = sid
 	cfg2.WarmupMinClusters = 3
 	p2 := newManualProcessor(t, cfg2)
@@ -158,7 +186,7 @@ func TestLoadedSnapshotSkipsWarmup(t *testing.T) {
 	assert.True(t, p2.warmedUp, "warmup should be skipped when loaded clusters >= threshold")
 
 	// First record should be annotated immediately.
-	out, err := p2.processLogs(ctx, makeLogRecord("connected to host 10.10.10.10 on port 9000"))
+	out, err := p2.ProcessLogs(ctx, makeLogRecord("connected to host 10.10.10.10 on port 9000"))
 	require.NoError(t, err)
 	_, ok := getFirstRecord(out).Attributes().Get("log.record.template")
 	assert.True(t, ok, "first record should be annotated when warmup is skipped")
@@ -175,20 +203,20 @@ func TestCorruptSnapshotFallsBackToSeed(t *testing.T) {
 	ctx := t.Context()
 
 	// Write corrupt data to storage via the getStorageClient helper.
-	corruptClient, err := getStorageClient(ctx, host, sid, component.MustNewID("drain"))
+	corruptClient, err := getStorageClient(ctx, host, sid, component.KindProcessor, testID)
 	require.NoError(t, err)
 	require.NoError(t, corruptClient.Set(ctx, storageKey, []byte("not valid json")))
 	require.NoError(t, corruptClient.Close(ctx))
 
 	// New processor should fall back to seeds.
-	cfg := createDefaultConfig().(*Config)
+	cfg := NewDefaultConfig()
 	cfg.Storage = sid
 	cfg.SeedTemplates = []string{"disk write error on device <*>"}
 	p := newManualProcessor(t, cfg)
 	require.NoError(t, p.Start(ctx, host))
 
 	// Seed should have been applied — match a disk pattern.
-	out, err := p.processLogs(ctx, makeLogRecord("disk write error on device sda"))
+	out, err := p.ProcessLogs(ctx, makeLogRecord("disk write error on device sda"))
 	require.NoError(t, err)
 	tmpl := templateAttr(t, out)
 	assert.Contains(t, tmpl, "disk", "seed should have been applied after corrupt snapshot")
@@ -199,7 +227,7 @@ func TestCorruptSnapshotFallsBackToSeed(t *testing.T) {
 // TestStorageExtensionNotFound verifies that Start returns an error when the
 // configured storage extension does not exist in the host.
 func TestStorageExtensionNotFound(t *testing.T) {
-	cfg := createDefaultConfig().(*Config)
+	cfg := NewDefaultConfig()
 	badID := component.MustNewID("nonexistent")
 	cfg.Storage = &badID
 
@@ -216,7 +244,7 @@ func TestPeriodicSave(t *testing.T) {
 	sid := storageID()
 	ctx := t.Context()
 
-	cfg := createDefaultConfig().(*Config)
+	cfg := NewDefaultConfig()
 	cfg.Storage = sid
 	cfg.SaveInterval = 50 * time.Millisecond
 
@@ -224,7 +252,7 @@ func TestPeriodicSave(t *testing.T) {
 	require.NoError(t, p.Start(ctx, host))
 
 	// Train some lines to create state worth saving.
-	_, err := p.processLogs(ctx, makeLogRecord("connected to host 10.0.0.1 on port 443"))
+	_, err := p.ProcessLogs(ctx, makeLogRecord("connected to host 10.0.0.1 on port 443"))
 	require.NoError(t, err)
 
 	// Wait for at least one periodic save tick.
@@ -232,7 +260,7 @@ func TestPeriodicSave(t *testing.T) {
 	require.NoError(t, p.Shutdown(ctx))
 
 	// Verify storage has data by starting a second processor.
-	cfg2 := createDefaultConfig().(*Config)
+	cfg2 := NewDefaultConfig()
 	cfg2.Storage = sid
 	p2 := newManualProcessor(t, cfg2)
 	require.NoError(t, p2.Start(ctx, host))
@@ -248,13 +276,13 @@ func TestSaveSkipsWhenUnchanged(t *testing.T) {
 	sid := storageID()
 	ctx := t.Context()
 
-	cfg := createDefaultConfig().(*Config)
+	cfg := NewDefaultConfig()
 	cfg.Storage = sid
 	p := newManualProcessor(t, cfg)
 	require.NoError(t, p.Start(ctx, host))
 
 	// Train a line and save.
-	_, err := p.processLogs(ctx, makeLogRecord("connected to host 10.0.0.1 on port 443"))
+	_, err := p.ProcessLogs(ctx, makeLogRecord("connected to host 10.0.0.1 on port 443"))
 	require.NoError(t, err)
 	require.NoError(t, p.saveSnapshot(ctx))
 
@@ -271,7 +299,7 @@ func TestSaveSkipsWhenUnchanged(t *testing.T) {
 // TestConfigValidateSaveIntervalWithoutStorage verifies that setting
 // save_interval without storage produces a validation error.
 func TestConfigValidateSaveIntervalWithoutStorage(t *testing.T) {
-	cfg := createDefaultConfig().(*Config)
+	cfg := NewDefaultConfig()
 	cfg.SaveInterval = 5 * time.Minute
 	// Storage is nil.
 	assert.Error(t, cfg.Validate(), "save_interval without storage should be an error")
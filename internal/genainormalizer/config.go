@@ -0,0 +1,236 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package genainormalizer // import "github.com/open-telemetry/opentelemetry-collector-contrib/internal/genainormalizer"
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.opentelemetry.io/collector/confmap"
+)
+
+// SourceName identifies a source instrumentation convention. Built-in
+// names (e.g. "openinference") get pre-defined mapping tables; any other
+// name is a user-defined source whose mappings come from the config.
+type SourceName string
+
+const (
+	// SourceOpenInference enables normalization of OpenInference attributes.
+	SourceOpenInference SourceName = "openinference"
+	// SourceOpenLLMetry enables normalization of OpenLLMetry (Traceloop) attributes.
+	SourceOpenLLMetry SourceName = "openllmetry"
+)
+
+var builtInSources = map[SourceName]struct{}{
+	SourceOpenInference: {},
+	SourceOpenLLMetry:   {},
+}
+
+// Source configures normalization behavior for a single source convention.
+type Source struct {
+	_ struct{}
+
+	// Name identifies the source. Built-in names (e.g. "openinference",
+	// "openllmetry") use pre-defined mapping tables; any other name is a
+	// user-defined source whose mappings come from this entry's Mappings
+	// and ValueMappings fields.
+	Name SourceName `mapstructure:"name"`
+
+	// RemoveOriginals deletes source attributes after mapping.
+	RemoveOriginals bool `mapstructure:"remove_originals"`
+
+	// Overwrite replaces target attributes that already exist on the span.
+	// When false (default), existing target attributes are left unchanged.
+	Overwrite bool `mapstructure:"overwrite"`
+
+	// Mappings is the source-attribute -> target-attribute rename table.
+	// Required for user-defined sources; rejected on built-in sources.
+	Mappings map[string]string `mapstructure:"mappings"`
+
+	// ValueMappings is keyed by the post-rename target attribute name and
+	// folds source string values onto preferred target string values.
+	// Source-value lookups are exact-match. Only valid on user-defined
+	// sources; each key must appear as a target in Mappings.
+	ValueMappings map[string]map[string]string `mapstructure:"value_mappings"`
+
+	// Events converts indexed attributes (e.g. llm.input_messages.0.message.content)
+	// into span events, one per index. Only valid on user-defined sources.
+	Events []EventRule `mapstructure:"events"`
+
+	// UnitConversions converts the values written by Mappings to another
+	// unit, keyed by target attribute.
+	UnitConversions []UnitConversion `mapstructure:"unit_conversions"`
+
+	// Pricing computes the gen_ai.usage.cost attribute from the token usage
+	// and model of the span.
+	Pricing PricingConfig `mapstructure:"pricing"`
+
+	// ProfileFile is the path of a YAML file holding the mappings,
+	// value_mappings, events, unit_conversions and pricing of a user-defined
+	// source, which must then not be set inline. The file is loaded when the
+	// processor is created.
+	ProfileFile string `mapstructure:"profile_file"`
+}
+
+// EventRule converts the indexed attributes "<prefix>.<N>.<field path>" of a
+// span into one span event per index N, in index order.
+type EventRule struct {
+	_ struct{}
+
+	// Prefix is the attribute key preceding the index, e.g. "llm.input_messages".
+	Prefix string `mapstructure:"prefix"`
+
+	// Name is the name of the events, unless NameField selects another one.
+	Name string `mapstructure:"name"`
+
+	// NameField is the field path, relative to the index, whose value selects
+	// the event name in Names, e.g. "message.role".
+	NameField string `mapstructure:"name_field"`
+
+	// Names maps values of NameField to event names. Events whose value is
+	// not listed are named Name.
+	Names map[string]string `mapstructure:"names"`
+
+	// Fields maps field paths, relative to the index, to event attribute
+	// keys. Fields not listed are dropped. When empty, every field is kept
+	// under its field path.
+	Fields map[string]string `mapstructure:"fields"`
+}
+
+// UnitConversion scales the numeric value written to a target attribute,
+// either between two time units or by an explicit factor.
+type UnitConversion struct {
+	_ struct{}
+
+	// Attribute is the target attribute whose value is converted.
+	Attribute string `mapstructure:"attribute"`
+
+	// From and To are the source and target time units: one of ns, us, ms,
+	// s, min and h.
+	From string `mapstructure:"from"`
+	To   string `mapstructure:"to"`
+
+	// Factor multiplies the value. Mutually exclusive with From and To.
+	Factor float64 `mapstructure:"factor"`
+}
+
+// PricingConfig holds per-model token prices used to compute gen_ai.usage.cost.
+type PricingConfig struct {
+	_ struct{}
+
+	// PerTokens is the number of tokens the prices apply to. Defaults to
+	// 1000000 when zero, i.e. prices per million tokens.
+	PerTokens int64 `mapstructure:"per_tokens"`
+
+	// Models maps model names, matched against gen_ai.response.model then
+	// gen_ai.request.model, to their prices. A name ending with "*" matches
+	// every model starting with the preceding prefix; exact names take
+	// precedence, then the longest prefix.
+	Models map[string]ModelPrice `mapstructure:"models"`
+}
+
+// ModelPrice is the price of PricingConfig.PerTokens input and output tokens.
+type ModelPrice struct {
+	_ struct{}
+
+	Input  float64 `mapstructure:"input"`
+	Output float64 `mapstructure:"output"`
+}
+
+// Config holds the configuration for the genainormalizer processor.
+type Config struct {
+	_ struct{}
+
+	// OverwriteSchemaURL replaces an existing ScopeSpans schema URL with the
+	// semantic-conventions schema URL targeted by this processor when at least
+	// one normalization writes an attribute. When false (default), an existing
+	// scope schema URL is preserved.
+	OverwriteSchemaURL bool `mapstructure:"overwrite_schema_url"`
+
+	// Sources is an ordered list of sources to normalize. Each span is
+	// processed by every source in the order specified. At least one source
+	// must be specified.
+	Sources []Source `mapstructure:"sources"`
+}
+
+var _ confmap.Validator = (*Config)(nil)
+
+// NewDefaultConfig returns the default configuration of the genainormalizer
+// processor. Sources must be explicitly specified by the user; there are no
+// built-in source defaults.
+func NewDefaultConfig() *Config {
+	return &Config{
+		Sources: []Source{},
+	}
+}
+
+// builtInSourceNames returns a sorted comma-separated list of built-in
+// source names for use in error messages.
+func builtInSourceNames() string {
+	names := make([]string, 0, len(builtInSources))
+	for name := range builtInSources {
+		names = append(names, string(name))
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// Validate checks that the configuration is valid.
+func (c *Config) Validate() error {
+	if len(c.Sources) == 0 {
+		return errors.New("at least one source must be specified")
+	}
+	return ValidateSources(c.Sources)
+}
+
+// ValidateSources checks each source and that source names are unique.
+func ValidateSources(sources []Source) error {
+	seen := make(map[SourceName]struct{}, len(sources))
+	for i, src := range sources {
+		if src.Name == "" {
+			return fmt.Errorf("sources[%d]: %q must be set", i, "name")
+		}
+		if _, dup := seen[src.Name]; dup {
+			return fmt.Errorf("sources[%d]: duplicate source %q", i, src.Name)
+		}
+		seen[src.Name] = struct{}{}
+
+		if _, builtIn := builtInSources[src.Name]; builtIn {
+			for _, f := range []struct {
+				name string
+				set  bool
+			}{
+				{"mappings", len(src.Mappings) > 0},
+				{"value_mappings", len(src.ValueMappings) > 0},
+				{"events", len(src.Events) > 0},
+				{"profile_file", src.ProfileFile != ""},
+			} {
+				if f.set {
+					return fmt.Errorf("sources[%d]: %q is not valid on built-in source %q", i, f.name, src.Name)
+				}
+			}
+			if err := src.validateProfile(); err != nil {
+				return fmt.Errorf("sources[%d]: %w", i, err)
+			}
+			continue
+		}
+
+		// User-defined source.
+		if src.ProfileFile != "" {
+			if src.hasProfile() {
+				return fmt.Errorf("sources[%d]: %q cannot be combined with inline mappings, value_mappings, events, unit_conversions or pricing", i, "profile_file")
+			}
+			continue
+		}
+		if len(src.Mappings) == 0 && len(src.Events) == 0 {
+			return fmt.Errorf("sources[%d]: user-defined source %q requires non-empty %q or %q, or a %q (built-in sources, which do not require mappings: %s)", i, src.Name, "mappings", "events", "profile_file", builtInSourceNames())
+		}
+		if err := src.validateProfile(); err != nil {
+			return fmt.Errorf("sources[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
@@ -1,7 +1,7 @@
 // Copyright The OpenTelemetry Authors
 // SPDX-License-Identifier: Apache-2.0
 
-package genainormalizerprocessor
+package genainormalizer
 
 import (
 	"path/filepath"
@@ -12,8 +12,6 @@ import (
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/confmap"
 	"go.opentelemetry.io/collector/confmap/confmaptest"
-
-	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/genainormalizerprocessor/internal/metadata"
 )
 
 func TestValidate(t *testing.T) {
@@ -89,7 +87,7 @@ func TestValidate(t *testing.T) {
 			cfg: Config{
 				Sources: []Source{{Name: "my_vendor"}},
 			},
-			wantErr: `sources[0]: user-defined source "my_vendor" requires non-empty "mappings" (built-in sources, which do not require mappings: openinference, openllmetry)`,
+			wantErr: `sources[0]: user-defined source "my_vendor" requires non-empty "mappings" or "events", or a "profile_file" (built-in sources, which do not require mappings: openinference, openllmetry)`,
 		},
 		{
 			name: "typo on built-in source name surfaces built-in list",
@@ -154,6 +152,138 @@ func TestValidate(t *testing.T) {
 			},
 			wantErr: `sources[0]: "value_mappings" key "gen_ai.operation.name" is not a target in "mappings"`,
 		},
+		{
+			name: "valid user-defined source with events only",
+			cfg: Config{
+				Sources: []Source{{
+					Name:   "my_vendor",
+					Events: []EventRule{{Prefix: "my_vendor.messages", Name: "gen_ai.user.message"}},
+				}},
+			},
+		},
+		{
+			name: "valid user-defined source with profile_file",
+			cfg: Config{
+				Sources: []Source{{Name: "my_vendor", ProfileFile: "profile.yaml"}},
+			},
+		},
+		{
+			name: "profile_file with inline pricing",
+			cfg: Config{
+				Sources: []Source{{
+					Name:        "my_vendor",
+					ProfileFile: "profile.yaml",
+					Pricing:     PricingConfig{Models: map[string]ModelPrice{"gpt-4o": {Input: 2.5}}},
+				}},
+			},
+			wantErr: `sources[0]: "profile_file" cannot be combined with inline mappings`,
+		},
+		{
+			name: "built-in source with events set",
+			cfg: Config{
+				Sources: []Source{{
+					Name:   SourceOpenInference,
+					Events: []EventRule{{Prefix: "llm.input_messages", Name: "gen_ai.user.message"}},
+				}},
+			},
+			wantErr: `sources[0]: "events" is not valid on built-in source "openinference"`,
+		},
+		{
+			name: "built-in source with profile_file set",
+			cfg: Config{
+				Sources: []Source{{Name: SourceOpenLLMetry, ProfileFile: "profile.yaml"}},
+			},
+			wantErr: `sources[0]: "profile_file" is not valid on built-in source "openllmetry"`,
+		},
+		{
+			name: "built-in source with pricing and unit conversions",
+			cfg: Config{
+				Sources: []Source{{
+					Name:            SourceOpenInference,
+					UnitConversions: []UnitConversion{{Attribute: "gen_ai.usage.input_tokens", Factor: 1000}},
+					Pricing:         PricingConfig{Models: map[string]ModelPrice{"gpt-4o*": {Input: 2.5, Output: 10}}},
+				}},
+			},
+		},
+		{
+			name: "event rule without name",
+			cfg: Config{
+				Sources: []Source{{
+					Name:   "my_vendor",
+					Events: []EventRule{{Prefix: "my_vendor.messages"}},
+				}},
+			},
+			wantErr: `sources[0]: events[0]: "name" must be set`,
+		},
+		{
+			name: "event rule with names but no name_field",
+			cfg: Config{
+				Sources: []Source{{
+					Name: "my_vendor",
+					Events: []EventRule{{
+						Prefix: "my_vendor.messages",
+						Name:   "gen_ai.user.message",
+						Names:  map[string]string{"system": "gen_ai.system.message"},
+					}},
+				}},
+			},
+			wantErr: `sources[0]: events[0]: "names" requires "name_field"`,
+		},
+		{
+			name: "unit conversion with factor and units",
+			cfg: Config{
+				Sources: []Source{{
+					Name:            "my_vendor",
+					Mappings:        map[string]string{"my_vendor.latency": "my_vendor.latency_s"},
+					UnitConversions: []UnitConversion{{Attribute: "my_vendor.latency_s", From: "ms", To: "s", Factor: 0.001}},
+				}},
+			},
+			wantErr: `sources[0]: unit_conversions[0]: "factor" cannot be combined with "from" and "to"`,
+		},
+		{
+			name: "unit conversion with unknown unit",
+			cfg: Config{
+				Sources: []Source{{
+					Name:            "my_vendor",
+					Mappings:        map[string]string{"my_vendor.latency": "my_vendor.latency_s"},
+					UnitConversions: []UnitConversion{{Attribute: "my_vendor.latency_s", From: "ms", To: "sec"}},
+				}},
+			},
+			wantErr: `sources[0]: unit_conversions[0]: unknown unit "sec", expected one of ns, us, ms, s, min, h`,
+		},
+		{
+			name: "unit conversion attribute not in mappings targets",
+			cfg: Config{
+				Sources: []Source{{
+					Name:            "my_vendor",
+					Mappings:        map[string]string{"my_vendor.latency": "my_vendor.latency_s"},
+					UnitConversions: []UnitConversion{{Attribute: "my_vendor.latency", From: "ms", To: "s"}},
+				}},
+			},
+			wantErr: `sources[0]: unit_conversions[0]: attribute "my_vendor.latency" is not a target in "mappings"`,
+		},
+		{
+			name: "pricing with wildcard in the middle",
+			cfg: Config{
+				Sources: []Source{{
+					Name:     "my_vendor",
+					Mappings: map[string]string{"my_vendor.model": "gen_ai.request.model"},
+					Pricing:  PricingConfig{Models: map[string]ModelPrice{"gpt-*-mini": {Input: 0.15}}},
+				}},
+			},
+			wantErr: `sources[0]: pricing: model "gpt-*-mini": "*" is only supported as the last character`,
+		},
+		{
+			name: "pricing with negative price",
+			cfg: Config{
+				Sources: []Source{{
+					Name:     "my_vendor",
+					Mappings: map[string]string{"my_vendor.model": "gen_ai.request.model"},
+					Pricing:  PricingConfig{Models: map[string]ModelPrice{"gpt-4o": {Input: -1}}},
+				}},
+			},
+			wantErr: `sources[0]: pricing: model "gpt-4o": prices cannot be negative`,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -170,7 +300,7 @@ func TestValidate(t *testing.T) {
 // TestDefaultConfigIsInvalid ensures the factory-returned default config fails
 // validation, since the user must explicitly configure at least one source.
 func TestDefaultConfigIsInvalid(t *testing.T) {
-	cfg := createDefaultConfig().(*Config)
+	cfg := NewDefaultConfig()
 	assert.Empty(t, cfg.Sources)
 	assert.False(t, cfg.OverwriteSchemaURL)
 	require.ErrorContains(t, cfg.Validate(), "at least one source must be specified")
@@ -186,7 +316,7 @@ func TestLoadConfig(t *testing.T) {
 		errorMessage string
 	}{
 		{
-			id: component.NewIDWithName(metadata.Type, ""),
+			id: component.NewIDWithName(testType, ""),
 			expected: &Config{
 				OverwriteSchemaURL: true,
 				Sources: []Source{
@@ -199,23 +329,23 @@ func TestLoadConfig(t *testing.T) {
 			},
 		},
 		{
-			id:           component.NewIDWithName(metadata.Type, "empty"),
+			id:           component.NewIDWithName(testType, "empty"),
 			errorMessage: "at least one source must be specified",
 		},
 		{
-			id: component.NewIDWithName(metadata.Type, "openinference_only"),
+			id: component.NewIDWithName(testType, "openinference_only"),
 			expected: &Config{
 				Sources: []Source{{Name: SourceOpenInference}},
 			},
 		},
 		{
-			id: component.NewIDWithName(metadata.Type, "openllmetry_only"),
+			id: component.NewIDWithName(testType, "openllmetry_only"),
 			expected: &Config{
 				Sources: []Source{{Name: SourceOpenLLMetry}},
 			},
 		},
 		{
-			id: component.NewIDWithName(metadata.Type, "openinference_and_openllmetry"),
+			id: component.NewIDWithName(testType, "openinference_and_openllmetry"),
 			expected: &Config{
 				Sources: []Source{
 					{Name: SourceOpenInference, RemoveOriginals: true},
@@ -224,15 +354,15 @@ func TestLoadConfig(t *testing.T) {
 			},
 		},
 		{
-			id:           component.NewIDWithName(metadata.Type, "empty_sources"),
+			id:           component.NewIDWithName(testType, "empty_sources"),
 			errorMessage: "at least one source must be specified",
 		},
 		{
-			id:           component.NewIDWithName(metadata.Type, "duplicate_source"),
+			id:           component.NewIDWithName(testType, "duplicate_source"),
 			errorMessage: `duplicate source "openinference"`,
 		},
 		{
-			id: component.NewIDWithName(metadata.Type, "user_defined_only"),
+			id: component.NewIDWithName(testType, "user_defined_only"),
 			expected: &Config{
 				Sources: []Source{{
 					Name:            "my_vendor",
@@ -245,7 +375,7 @@ func TestLoadConfig(t *testing.T) {
 			},
 		},
 		{
-			id: component.NewIDWithName(metadata.Type, "user_defined_with_builtin"),
+			id: component.NewIDWithName(testType, "user_defined_with_builtin"),
 			expected: &Config{
 				Sources: []Source{
 					{Name: SourceOpenInference, RemoveOriginals: true},
@@ -258,7 +388,7 @@ func TestLoadConfig(t *testing.T) {
 			},
 		},
 		{
-			id: component.NewIDWithName(metadata.Type, "multiple_user_defined"),
+			id: component.NewIDWithName(testType, "multiple_user_defined"),
 			expected: &Config{
 				Sources: []Source{
 					{
@@ -276,19 +406,19 @@ func TestLoadConfig(t *testing.T) {
 			},
 		},
 		{
-			id:           component.NewIDWithName(metadata.Type, "user_defined_empty_mappings"),
-			errorMessage: `user-defined source "my_vendor" requires non-empty "mappings" (built-in sources, which do not require mappings: openinference, openllmetry)`,
+			id:           component.NewIDWithName(testType, "user_defined_empty_mappings"),
+			errorMessage: `user-defined source "my_vendor" requires non-empty "mappings" or "events", or a "profile_file" (built-in sources, which do not require mappings: openinference, openllmetry)`,
 		},
 		{
-			id:           component.NewIDWithName(metadata.Type, "openinference_with_mappings"),
+			id:           component.NewIDWithName(testType, "openinference_with_mappings"),
 			errorMessage: `"mappings" is not valid on built-in source "openinference"`,
 		},
 		{
-			id:           component.NewIDWithName(metadata.Type, "user_defined_unreachable_value_mapping"),
+			id:           component.NewIDWithName(testType, "user_defined_unreachable_value_mapping"),
 			errorMessage: `"value_mappings" key "gen_ai.operation.name" is not a target in "mappings"`,
 		},
 		{
-			id: component.NewIDWithName(metadata.Type, "user_defined_with_value_mappings"),
+			id: component.NewIDWithName(testType, "user_defined_with_value_mappings"),
 			expected: &Config{
 				Sources: []Source{{
 					Name:            "my_vendor",
@@ -303,13 +433,42 @@ func TestLoadConfig(t *testing.T) {
 				}},
 			},
 		},
+		{
+			id: component.NewIDWithName(testType, "profile_file"),
+			expected: &Config{
+				Sources: []Source{{
+					Name:            "langchain",
+					RemoveOriginals: true,
+					ProfileFile:     "testdata/profiles/langchain.yaml",
+				}},
+			},
+		},
+		{
+			id:           component.NewIDWithName(testType, "profile_file_with_mappings"),
+			errorMessage: `"profile_file" cannot be combined with inline mappings`,
+		},
+		{
+			id: component.NewIDWithName(testType, "openinference_with_pricing"),
+			expected: &Config{
+				Sources: []Source{{
+					Name: SourceOpenInference,
+					Pricing: PricingConfig{
+						PerTokens: 1000,
+						Models: map[string]ModelPrice{
+							"gpt-4o":             {Input: 0.0025, Output: 0.01},
+							"claude-3-5-sonnet*": {Input: 0.003, Output: 0.015},
+						},
+					},
+				}},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.id.String(), func(t *testing.T) {
 			cm, err := confmaptest.LoadConf(filepath.Join("testdata", "config.yaml"))
 			require.NoError(t, err)
 
-			factory := NewFactory()
+			factory := newTestFactory()
 			cfg := factory.CreateDefaultConfig()
 
 			sub, err := cm.Sub(tt.id.String())
@@ -0,0 +1,10 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:generate make mdatagen
+
+// Package genainormalizer implements the normalization of GenAI span
+// attributes to the OTel GenAI Semantic Conventions shared by the
+// genainormalizer processor and the genainormalizer connector: the built-in
+// and user-defined sources, events, unit conversions and pricing.
+package genainormalizer // import "github.com/open-telemetry/opentelemetry-collector-contrib/internal/genainormalizer"
@@ -0,0 +1,109 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package genainormalizer // import "github.com/open-telemetry/opentelemetry-collector-contrib/internal/genainormalizer"
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+// eventConverter converts the indexed attributes matching an EventRule into
+// span events, e.g. llm.input_messages.N.message.* into one
+// gen_ai.user.message event per message.
+type eventConverter struct {
+	rule   EventRule
+	prefix string
+}
+
+func newEventConverter(rule EventRule) eventConverter {
+	return eventConverter{rule: rule, prefix: rule.Prefix + "."}
+}
+
+// convert appends one event per index found on span, timestamped with the
+// span start time. It returns true if at least one event was appended.
+func (ec eventConverter) convert(span ptrace.Span, removeOriginals bool) bool {
+	attrs := span.Attributes()
+	fieldsByIndex := make(map[int]pcommon.Map)
+	var keysToRemove []string
+
+	attrs.Range(func(k string, v pcommon.Value) bool {
+		if !strings.HasPrefix(k, ec.prefix) {
+			return true
+		}
+		idx, fieldPath, ok := splitIndex(k[len(ec.prefix):])
+		if !ok {
+			return true
+		}
+		fields, exists := fieldsByIndex[idx]
+		if !exists {
+			fields = pcommon.NewMap()
+			fieldsByIndex[idx] = fields
+		}
+		v.CopyTo(fields.PutEmpty(fieldPath))
+		if removeOriginals {
+			keysToRemove = append(keysToRemove, k)
+		}
+		return true
+	})
+	if len(fieldsByIndex) == 0 {
+		return false
+	}
+
+	indices := make([]int, 0, len(fieldsByIndex))
+	for idx := range fieldsByIndex {
+		indices = append(indices, idx)
+	}
+	sort.Ints(indices)
+
+	for _, idx := range indices {
+		fields := fieldsByIndex[idx]
+		event := span.Events().AppendEmpty()
+		event.SetTimestamp(span.StartTimestamp())
+		event.SetName(ec.eventName(fields))
+		if len(ec.rule.Fields) == 0 {
+			fields.MoveTo(event.Attributes())
+			continue
+		}
+		fields.Range(func(fieldPath string, v pcommon.Value) bool {
+			if key, ok := ec.rule.Fields[fieldPath]; ok {
+				v.CopyTo(event.Attributes().PutEmpty(key))
+			}
+			return true
+		})
+	}
+
+	for _, k := range keysToRemove {
+		attrs.Remove(k)
+	}
+	return true
+}
+
+func (ec eventConverter) eventName(fields pcommon.Map) string {
+	if ec.rule.NameField == "" {
+		return ec.rule.Name
+	}
+	if v, ok := fields.Get(ec.rule.NameField); ok {
+		if name, ok := ec.rule.Names[v.AsString()]; ok {
+			return name
+		}
+	}
+	return ec.rule.Name
+}
+
+// splitIndex splits "N.field.path" into (N, "field.path", true).
+func splitIndex(s string) (int, string, bool) {
+	idxStr, fieldPath, found := strings.Cut(s, ".")
+	if !found || fieldPath == "" {
+		return 0, "", false
+	}
+	idx, err := strconv.Atoi(idxStr)
+	if err != nil || idx < 0 {
+		return 0, "", false
+	}
+	return idx, fieldPath, true
+}
@@ -2,8 +2,8 @@
 // SPDX-License-Identifier: Apache-2.0
 
 // Package custom implements the value transformer for the
-// genainormalizerprocessor's "custom" source.
-package custom // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/genainormalizerprocessor/internal/custom"
+// genainormalizer processor's "custom" source.
+package custom // import "github.com/open-telemetry/opentelemetry-collector-contrib/internal/genainormalizer/internal/custom"
 
 import (
 	"go.opentelemetry.io/collector/pdata/pcommon"
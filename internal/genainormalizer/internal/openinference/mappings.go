@@ -6,12 +6,12 @@
 // conventions. Source-side keys come from the upstream Go semconv package.
 //
 // Reference: https://github.com/Arize-ai/openinference/blob/725d68c0c43778089bc99060efba74d37231f9f1/spec/semantic_conventions.md
-package openinference // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/genainormalizerprocessor/internal/openinference"
+package openinference // import "github.com/open-telemetry/opentelemetry-collector-contrib/internal/genainormalizer/internal/openinference"
 
 import (
 	oisemconv "github.com/Arize-ai/openinference/go/openinference-semantic-conventions"
 
-	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/genainormalizerprocessor/internal/otelsemconv"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/genainormalizer/otelsemconv"
 )
 
 // LookupTable maps OpenInference attribute keys to the OTel GenAI target keys.
@@ -11,7 +11,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/collector/pdata/pcommon"
 
-	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/genainormalizerprocessor/internal/otelsemconv"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/genainormalizer/otelsemconv"
 )
 
 func newAttrs(kvs map[string]string) pcommon.Map {
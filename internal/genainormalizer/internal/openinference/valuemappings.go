@@ -1,7 +1,7 @@
 // Copyright The OpenTelemetry Authors
 // SPDX-License-Identifier: Apache-2.0
 
-package openinference // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/genainormalizerprocessor/internal/openinference"
+package openinference // import "github.com/open-telemetry/opentelemetry-collector-contrib/internal/genainormalizer/internal/openinference"
 
 import (
 	"strings"
@@ -9,7 +9,7 @@ import (
 	oisemconv "github.com/Arize-ai/openinference/go/openinference-semantic-conventions"
 	"go.opentelemetry.io/collector/pdata/pcommon"
 
-	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/genainormalizerprocessor/internal/otelsemconv"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/genainormalizer/otelsemconv"
 )
 
 // operationNameValues maps openinference.span.kind values to OTel GenAI
@@ -8,12 +8,12 @@
 // semconv_ai package and hard-coded with a comment.
 //
 // Reference: https://github.com/traceloop/openllmetry/blob/1ebfd1b77cfcfede74a40f28dbb0d9709bcff365/packages/opentelemetry-semantic-conventions-ai/opentelemetry/semconv_ai/__init__.py
-package openllmetry // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/genainormalizerprocessor/internal/openllmetry"
+package openllmetry // import "github.com/open-telemetry/opentelemetry-collector-contrib/internal/genainormalizer/internal/openllmetry"
 
 import (
 	semconvai "github.com/traceloop/go-openllmetry/semconv-ai"
 
-	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/genainormalizerprocessor/internal/otelsemconv"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/genainormalizer/otelsemconv"
 )
 
 // LookupTable maps OpenLLMetry attribute keys to the OTel GenAI target keys.
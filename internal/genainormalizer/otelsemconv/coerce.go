@@ -1,7 +1,7 @@
 // Copyright The OpenTelemetry Authors
 // SPDX-License-Identifier: Apache-2.0
 
-package otelsemconv // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/genainormalizerprocessor/internal/otelsemconv"
+package otelsemconv // import "github.com/open-telemetry/opentelemetry-collector-contrib/internal/genainormalizer/otelsemconv"
 
 import (
 	"reflect"
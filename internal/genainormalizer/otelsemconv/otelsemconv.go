@@ -1,9 +1,10 @@
 // Copyright The OpenTelemetry Authors
 // SPDX-License-Identifier: Apache-2.0
 
-// Package otelsemconv pins the OTel semantic-conventions version this
-// processor targets and exports the gen_ai.* attribute keys it emits, along
-// with the Go type each key is defined to carry.
+// Package otelsemconv pins the OTel semantic-conventions version the GenAI
+// normalizer processor and connector target and exports the gen_ai.*
+// attribute keys they emit, along with the Go type each key is defined to
+// carry.
 //
 // Bumping versions: update the conventions import path below, verify any
 // referenced symbols still exist, and bump version references in public docs.
@@ -15,13 +16,14 @@
 // only a *Key constant; convert it with string(conventions.GenAIFooBarKey).
 // Values for these keys pass through Coerce verbatim, with no type
 // enforcement. If a typed constructor exists, use typed.
-package otelsemconv // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/genainormalizerprocessor/internal/otelsemconv"
+package otelsemconv // import "github.com/open-telemetry/opentelemetry-collector-contrib/internal/genainormalizer/otelsemconv"
 
 import (
 	"reflect"
 
 	"go.opentelemetry.io/otel/attribute"
 	conventions "go.opentelemetry.io/otel/semconv/v1.40.0"
+	"go.opentelemetry.io/otel/semconv/v1.40.0/genaiconv"
 )
 
 // SchemaURL is the OTel semconv schema URL for the targeted version.
@@ -114,9 +116,15 @@ var (
 	GenAIToolDescription   = typed(conventions.GenAIToolDescription)
 	GenAIToolName          = typed(conventions.GenAIToolName)
 
+	// gen_ai.token.*
+	GenAITokenType = string(conventions.GenAITokenTypeKey)
+
 	// gen_ai.usage.*
 	GenAIUsageInputTokens  = typed(conventions.GenAIUsageInputTokens)
 	GenAIUsageOutputTokens = typed(conventions.GenAIUsageOutputTokens)
+
+	// error.*
+	ErrorType = string(conventions.ErrorTypeKey)
 )
 
 // gen_ai.operation.name enum values, sourced from the semconv library.
@@ -138,3 +146,59 @@ var (
 	// raw string.
 	GenAIOperationNameInvokeWorkflow = "invoke_workflow"
 )
+
+// gen_ai.token.type enum values and the error.type fallback value, sourced
+// from the semconv library.
+//
+//nolint:gochecknoglobals // canonical enum value registry
+var (
+	GenAITokenTypeInput  = enumValue(conventions.GenAITokenTypeInput)
+	GenAITokenTypeOutput = enumValue(conventions.GenAITokenTypeOutput)
+	ErrorTypeOther       = enumValue(conventions.ErrorTypeOther)
+)
+
+// Keys outside the targeted semconv version. GenAISystem is the deprecated
+// predecessor of gen_ai.provider.name, still emitted by older
+// instrumentations and set alongside it on the connector metrics.
+// GenAIResponseTimeToFirstChunk (seconds) is defined by semconv versions
+// newer than the targeted one.
+const (
+	GenAISystem                   = "gen_ai.system"
+	GenAIResponseTimeToFirstChunk = "gen_ai.response.time_to_first_chunk"
+)
+
+// Metric describes a GenAI semconv metric.
+type Metric struct {
+	Name        string
+	Unit        string
+	Description string
+}
+
+func metricOf(inst interface {
+	Name() string
+	Unit() string
+	Description() string
+},
+) Metric {
+	return Metric{Name: inst.Name(), Unit: inst.Unit(), Description: inst.Description()}
+}
+
+// GenAI client metrics, sourced from the semconv library.
+// GenAIClientOperationTimeToFirstChunk is defined by semconv versions newer
+// than the targeted one.
+//
+//nolint:gochecknoglobals // canonical metric registry
+var (
+	GenAIClientTokenUsage                = metricOf(genaiconv.ClientTokenUsage{})
+	GenAIClientOperationDuration         = metricOf(genaiconv.ClientOperationDuration{})
+	GenAIClientOperationTimeToFirstChunk = Metric{
+		Name:        "gen_ai.client.operation.time_to_first_chunk",
+		Unit:        "s",
+		Description: "Time to receive the first chunk, measured from when the client issues the generation request to when the first chunk is received in the response stream.",
+	}
+)
+
+// GenAIUsageCost is not part of the semantic conventions. It holds the cost
+// of the token usage of a span, computed from the per-model prices configured
+// on a source.
+const GenAIUsageCost = "gen_ai.usage.cost"
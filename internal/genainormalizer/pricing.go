@@ -0,0 +1,116 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package genainormalizer // import "github.com/open-telemetry/opentelemetry-collector-contrib/internal/genainormalizer"
+
+import (
+	"sort"
+	"strings"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/genainormalizer/otelsemconv"
+)
+
+// defaultPerTokens is the number of tokens prices apply to when
+// PricingConfig.PerTokens is not set.
+const defaultPerTokens = 1_000_000
+
+type prefixPrice struct {
+	prefix string
+	price  ModelPrice
+}
+
+// pricer computes gen_ai.usage.cost from the normalized token usage and
+// model attributes of a span.
+type pricer struct {
+	perTokens float64
+	exact     map[string]ModelPrice
+	// prefixes is sorted by decreasing length so the longest match wins.
+	prefixes []prefixPrice
+}
+
+// newPricer returns nil when no model is priced.
+func newPricer(cfg PricingConfig) *pricer {
+	if len(cfg.Models) == 0 {
+		return nil
+	}
+	p := &pricer{
+		perTokens: float64(cfg.PerTokens),
+		exact:     make(map[string]ModelPrice, len(cfg.Models)),
+	}
+	if cfg.PerTokens == 0 {
+		p.perTokens = defaultPerTokens
+	}
+	for model, price := range cfg.Models {
+		if prefix, ok := strings.CutSuffix(model, "*"); ok {
+			p.prefixes = append(p.prefixes, prefixPrice{prefix: prefix, price: price})
+			continue
+		}
+		p.exact[model] = price
+	}
+	sort.Slice(p.prefixes, func(i, j int) bool {
+		return len(p.prefixes[i].prefix) > len(p.prefixes[j].prefix)
+	})
+	return p
+}
+
+func (p *pricer) lookup(model string) (ModelPrice, bool) {
+	if price, ok := p.exact[model]; ok {
+		return price, true
+	}
+	for _, pp := range p.prefixes {
+		if strings.HasPrefix(model, pp.prefix) {
+			return pp.price, true
+		}
+	}
+	return ModelPrice{}, false
+}
+
+// price returns the price of the response model of the span, falling back to
+// its request model.
+func (p *pricer) price(attrs pcommon.Map) (ModelPrice, bool) {
+	for _, key := range []string{otelsemconv.GenAIResponseModel, otelsemconv.GenAIRequestModel} {
+		model, ok := attrs.Get(key)
+		if !ok || model.Type() != pcommon.ValueTypeStr {
+			continue
+		}
+		if price, ok := p.lookup(model.Str()); ok {
+			return price, true
+		}
+	}
+	return ModelPrice{}, false
+}
+
+// apply sets gen_ai.usage.cost on attrs. It returns true if the attribute
+// was written.
+func (p *pricer) apply(attrs pcommon.Map, overwrite bool) bool {
+	if _, exists := attrs.Get(otelsemconv.GenAIUsageCost); exists && !overwrite {
+		return false
+	}
+	price, ok := p.price(attrs)
+	if !ok {
+		return false
+	}
+	input, hasInput := tokenCount(attrs, otelsemconv.GenAIUsageInputTokens)
+	output, hasOutput := tokenCount(attrs, otelsemconv.GenAIUsageOutputTokens)
+	if !hasInput && !hasOutput {
+		return false
+	}
+	attrs.PutDouble(otelsemconv.GenAIUsageCost, (input*price.Input+output*price.Output)/p.perTokens)
+	return true
+}
+
+func tokenCount(attrs pcommon.Map, key string) (float64, bool) {
+	v, ok := attrs.Get(key)
+	if !ok {
+		return 0, false
+	}
+	switch v.Type() {
+	case pcommon.ValueTypeInt:
+		return float64(v.Int()), true
+	case pcommon.ValueTypeDouble:
+		return v.Double(), true
+	}
+	return 0, false
+}
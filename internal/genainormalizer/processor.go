@@ -0,0 +1,228 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package genainormalizer // import "github.com/open-telemetry/opentelemetry-collector-contrib/internal/genainormalizer"
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/processor"
+	"go.opentelemetry.io/collector/processor/processorhelper"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/genainormalizer/internal/custom"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/genainormalizer/internal/openinference"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/genainormalizer/internal/openllmetry"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/genainormalizer/otelsemconv"
+)
+
+// valueTransformer applies source-specific value-level normalization into
+// dst. A nil transformer falls back to a plain src.CopyTo(dst).
+type valueTransformer func(targetKey string, src, dst pcommon.Value)
+
+// attributeAggregator reconstructs structured attributes from multiple
+// flat keys (e.g. flattened OpenInference messages → JSON string).
+type attributeAggregator interface {
+	AggregateAttributes(attrs pcommon.Map, removeOriginals, overwrite bool) bool
+}
+
+// sourceNormalizer holds per-source state used during normalization.
+type sourceNormalizer struct {
+	lookupTable     map[string]string
+	transformValue  valueTransformer
+	aggregators     []attributeAggregator
+	events          []eventConverter
+	conversions     map[string]float64 // target attribute -> factor
+	pricer          *pricer            // nil unless pricing is configured
+	removeOriginals bool
+	overwrite       bool
+}
+
+// newSourceNormalizer wires up a sourceNormalizer from a validated Source
+// config. Built-in source names use pre-defined mapping tables; any other
+// name is a user-defined source driven by the config's Mappings and
+// ValueMappings fields, possibly loaded from its profile file.
+func newSourceNormalizer(src Source) (sourceNormalizer, error) {
+	src, err := src.withProfileFile()
+	if err != nil {
+		return sourceNormalizer{}, err
+	}
+	sn := sourceNormalizer{
+		pricer:          newPricer(src.Pricing),
+		removeOriginals: src.RemoveOriginals,
+		overwrite:       src.Overwrite,
+	}
+	for _, rule := range src.Events {
+		sn.events = append(sn.events, newEventConverter(rule))
+	}
+	if len(src.UnitConversions) > 0 {
+		sn.conversions = make(map[string]float64, len(src.UnitConversions))
+		for _, conv := range src.UnitConversions {
+			sn.conversions[conv.Attribute] = conv.factor()
+		}
+	}
+	switch src.Name {
+	case SourceOpenInference:
+		sn.lookupTable = openinference.LookupTable
+		sn.transformValue = openinference.Transform
+		sn.aggregators = []attributeAggregator{openinference.MessageAggregator{}}
+	case SourceOpenLLMetry:
+		sn.lookupTable = openllmetry.LookupTable
+		sn.transformValue = openllmetry.Transform
+	default:
+		sn.lookupTable = src.Mappings
+		if len(src.ValueMappings) > 0 {
+			sn.transformValue = custom.Transform(src.ValueMappings)
+		}
+	}
+	return sn, nil
+}
+
+// Normalizer normalizes span attributes for each configured source.
+type Normalizer struct {
+	sources            []sourceNormalizer
+	overwriteSchemaURL bool
+}
+
+// NewNormalizer builds a Normalizer from a validated Config. Sources are
+// applied in the order specified in the configuration.
+func NewNormalizer(cfg *Config) (*Normalizer, error) {
+	p := &Normalizer{
+		sources:            make([]sourceNormalizer, 0, len(cfg.Sources)),
+		overwriteSchemaURL: cfg.OverwriteSchemaURL,
+	}
+	for i, src := range cfg.Sources {
+		sn, err := newSourceNormalizer(src)
+		if err != nil {
+			return nil, fmt.Errorf("sources[%d]: %w", i, err)
+		}
+		p.sources = append(p.sources, sn)
+	}
+	return p, nil
+}
+
+// NewTracesProcessor creates a traces processor normalizing the spans with
+// the sources of cfg.
+func NewTracesProcessor(
+	ctx context.Context,
+	set processor.Settings,
+	cfg *Config,
+	next consumer.Traces,
+) (processor.Traces, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	p, err := NewNormalizer(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return processorhelper.NewTraces(ctx, set, cfg, next, p.ProcessTraces,
+		processorhelper.WithCapabilities(consumer.Capabilities{MutatesData: true}),
+	)
+}
+
+// ProcessTraces normalizes the spans of td in place and returns it.
+func (p *Normalizer) ProcessTraces(_ context.Context, td ptrace.Traces) (ptrace.Traces, error) {
+	rss := td.ResourceSpans()
+	for i := 0; i < rss.Len(); i++ {
+		ilss := rss.At(i).ScopeSpans()
+		for j := 0; j < ilss.Len(); j++ {
+			ss := ilss.At(j)
+			spans := ss.Spans()
+			scopeWrote := false
+			for k := 0; k < spans.Len(); k++ {
+				span := spans.At(k)
+				for s := range p.sources {
+					scopeWrote = p.sources[s].normalizeSpan(span) || scopeWrote
+				}
+			}
+			if scopeWrote && (ss.SchemaUrl() == "" || p.overwriteSchemaURL) {
+				ss.SetSchemaUrl(otelsemconv.SchemaURL)
+			}
+		}
+	}
+	return td, nil
+}
+
+// normalizeSpan converts the indexed attributes of span into events, then
+// normalizes its attributes. It returns true if at least one event or
+// attribute was written.
+func (sn *sourceNormalizer) normalizeSpan(span ptrace.Span) bool {
+	wrote := false
+	for _, ec := range sn.events {
+		if ec.convert(span, sn.removeOriginals) {
+			wrote = true
+		}
+	}
+	return sn.normalizeAttributes(span.Attributes()) || wrote
+}
+
+// normalizeAttributes applies the source's rename rules to attrs, then
+// computes the cost of the span when pricing is configured. It returns true
+// if at least one attribute was written.
+func (sn *sourceNormalizer) normalizeAttributes(attrs pcommon.Map) bool {
+	wrote := false
+
+	// Phase 1: aggregators (reconstruct structured data from flat keys)
+	for _, agg := range sn.aggregators {
+		if agg.AggregateAttributes(attrs, sn.removeOriginals, sn.overwrite) {
+			wrote = true
+		}
+	}
+
+	// Phase 2: simple key renames
+	type rename struct {
+		from string
+		to   string
+	}
+	var renames []rename
+
+	attrs.Range(func(k string, _ pcommon.Value) bool {
+		if target, ok := sn.lookupTable[k]; ok {
+			renames = append(renames, rename{k, target})
+		}
+		return true
+	})
+
+	for _, r := range renames {
+		val, ok := attrs.Get(r.from)
+		if !ok {
+			continue
+		}
+		if factor, ok := sn.conversions[r.to]; ok {
+			converted := pcommon.NewValueEmpty()
+			if !convertUnit(val, converted, factor) {
+				continue
+			}
+			val = converted
+		}
+		dest, existed := attrs.GetOrPutEmpty(r.to)
+		if existed && !sn.overwrite {
+			continue
+		}
+
+		if !otelsemconv.Coerce(r.to, val, dest) {
+			if !existed {
+				attrs.Remove(r.to)
+			}
+			continue
+		}
+		if sn.transformValue != nil {
+			sn.transformValue(r.to, dest, dest)
+		}
+		wrote = true
+
+		if sn.removeOriginals {
+			attrs.Remove(r.from)
+		}
+	}
+
+	// Phase 3: cost, from the normalized usage and model attributes
+	if sn.pricer != nil && sn.pricer.apply(attrs, sn.overwrite) {
+		wrote = true
+	}
+	return wrote
+}
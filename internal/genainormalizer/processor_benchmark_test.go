@@ -1,7 +1,7 @@
 // Copyright The OpenTelemetry Authors
 // SPDX-License-Identifier: Apache-2.0
 
-package genainormalizerprocessor
+package genainormalizer
 
 import (
 	"context"
@@ -13,8 +13,6 @@ import (
 	"go.opentelemetry.io/collector/consumer"
 	"go.opentelemetry.io/collector/pdata/ptrace"
 	"go.opentelemetry.io/collector/processor/processortest"
-
-	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/genainormalizerprocessor/internal/metadata"
 )
 
 // BenchmarkProcessorBuiltIn measures the cost of normalizing spans with
@@ -169,9 +167,9 @@ func BenchmarkProcessorThroughput(b *testing.B) {
 	sources := []Source{{Name: SourceOpenInference}}
 	traces := openInferenceTraces(100)
 
-	factory := NewFactory()
+	factory := newTestFactory()
 	cfg := &Config{Sources: sources}
-	p, err := factory.CreateTraces(b.Context(), processortest.NewNopSettings(metadata.Type), cfg, dropTracesSink{})
+	p, err := factory.CreateTraces(b.Context(), processortest.NewNopSettings(testType), cfg, dropTracesSink{})
 	require.NoError(b, err)
 	require.NoError(b, p.Start(b.Context(), componenttest.NewNopHost()))
 	defer func() {
@@ -196,9 +194,9 @@ func BenchmarkProcessorThroughputWithMessages(b *testing.B) {
 	sources := []Source{{Name: SourceOpenInference, RemoveOriginals: true}}
 	traces := openInferenceFlattenedTraces(100, 4)
 
-	factory := NewFactory()
+	factory := newTestFactory()
 	cfg := &Config{Sources: sources}
-	p, err := factory.CreateTraces(b.Context(), processortest.NewNopSettings(metadata.Type), cfg, dropTracesSink{})
+	p, err := factory.CreateTraces(b.Context(), processortest.NewNopSettings(testType), cfg, dropTracesSink{})
 	require.NoError(b, err)
 	require.NoError(b, p.Start(b.Context(), componenttest.NewNopHost()))
 	defer func() { require.NoError(b, p.Shutdown(b.Context())) }()
@@ -325,10 +323,10 @@ func BenchmarkProcessorRemoveOriginals(b *testing.B) {
 // of the fixture each iteration. The processor mutates input data, so a
 // fresh clone is required per iteration.
 func runProcessorBenchmark(b *testing.B, sources []Source, traces ptrace.Traces) {
-	factory := NewFactory()
+	factory := newTestFactory()
 	cfg := &Config{Sources: sources}
 
-	p, err := factory.CreateTraces(b.Context(), processortest.NewNopSettings(metadata.Type), cfg, dropTracesSink{})
+	p, err := factory.CreateTraces(b.Context(), processortest.NewNopSettings(testType), cfg, dropTracesSink{})
 	require.NoError(b, err)
 
 	require.NoError(b, p.Start(b.Context(), componenttest.NewNopHost()))
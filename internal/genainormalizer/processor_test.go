@@ -1,22 +1,37 @@
 // Copyright The OpenTelemetry Authors
 // SPDX-License-Identifier: Apache-2.0
 
-package genainormalizerprocessor
+package genainormalizer
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
 	"go.opentelemetry.io/collector/consumer/consumertest"
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/processor"
 	"go.opentelemetry.io/collector/processor/processortest"
 
-	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/genainormalizerprocessor/internal/metadata"
-	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/genainormalizerprocessor/internal/openinference"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/genainormalizer/internal/openinference"
 )
 
+var testType = component.MustNewType("gen_ai_normalizer")
+
+func newTestFactory() processor.Factory {
+	return processor.NewFactory(
+		testType,
+		func() component.Config { return NewDefaultConfig() },
+		processor.WithTraces(func(ctx context.Context, set processor.Settings, cfg component.Config, next consumer.Traces) (processor.Traces, error) {
+			return NewTracesProcessor(ctx, set, cfg.(*Config), next)
+		}, component.StabilityLevelAlpha),
+	)
+}
+
 // newNormalizer builds a single-source sourceNormalizer from a fixture lookup
 // table so machinery tests do not depend on the populated built-in tables.
 func newNormalizer(lookup map[string]string, removeOriginals, overwrite bool) sourceNormalizer {
@@ -361,7 +376,7 @@ func TestNormalizeAttributes_AggregatorReportsWrote(t *testing.T) {
 }
 
 func TestProcessTraces_AppliesToSpans(t *testing.T) {
-	p := &genaiNormalizerProcessor{
+	p := &Normalizer{
 		sources: []sourceNormalizer{
 			newNormalizer(map[string]string{"src.model": "dst.model"}, true, false),
 		},
@@ -370,7 +385,7 @@ func TestProcessTraces_AppliesToSpans(t *testing.T) {
 	td, span := newSpan()
 	span.Attributes().PutStr("src.model", "m")
 
-	_, err := p.processTraces(t.Context(), td)
+	_, err := p.ProcessTraces(t.Context(), td)
 	require.NoError(t, err)
 
 	v, ok := span.Attributes().Get("dst.model")
@@ -379,7 +394,7 @@ func TestProcessTraces_AppliesToSpans(t *testing.T) {
 }
 
 func TestProcessTraces_StampsSchemaURLWhenMappingFires(t *testing.T) {
-	p := &genaiNormalizerProcessor{
+	p := &Normalizer{
 		sources: []sourceNormalizer{
 			newNormalizer(map[string]string{"src.model": "dst.model"}, true, false),
 		},
@@ -388,7 +403,7 @@ func TestProcessTraces_StampsSchemaURLWhenMappingFires(t *testing.T) {
 	td, span := newSpan()
 	span.Attributes().PutStr("src.model", "m")
 
-	_, err := p.processTraces(t.Context(), td)
+	_, err := p.ProcessTraces(t.Context(), td)
 	require.NoError(t, err)
 
 	ss := td.ResourceSpans().At(0).ScopeSpans().At(0)
@@ -396,7 +411,7 @@ func TestProcessTraces_StampsSchemaURLWhenMappingFires(t *testing.T) {
 }
 
 func TestProcessTraces_StampsSchemaURLWhenAggregatorFires(t *testing.T) {
-	p := &genaiNormalizerProcessor{
+	p := &Normalizer{
 		sources: []sourceNormalizer{{
 			lookupTable:     openinference.LookupTable,
 			transformValue:  openinference.Transform,
@@ -409,7 +424,7 @@ func TestProcessTraces_StampsSchemaURLWhenAggregatorFires(t *testing.T) {
 	span.Attributes().PutStr("llm.input_messages.0.message.role", "user")
 	span.Attributes().PutStr("llm.input_messages.0.message.content", "hi")
 
-	_, err := p.processTraces(t.Context(), td)
+	_, err := p.ProcessTraces(t.Context(), td)
 	require.NoError(t, err)
 
 	ss := td.ResourceSpans().At(0).ScopeSpans().At(0)
@@ -417,7 +432,7 @@ func TestProcessTraces_StampsSchemaURLWhenAggregatorFires(t *testing.T) {
 }
 
 func TestProcessTraces_LeavesSchemaURLWhenNoMappingFires(t *testing.T) {
-	p := &genaiNormalizerProcessor{
+	p := &Normalizer{
 		sources: []sourceNormalizer{
 			newNormalizer(map[string]string{"src.model": "dst.model"}, true, false),
 		},
@@ -426,7 +441,7 @@ func TestProcessTraces_LeavesSchemaURLWhenNoMappingFires(t *testing.T) {
 	td, span := newSpan()
 	span.Attributes().PutStr("http.method", "GET")
 
-	_, err := p.processTraces(t.Context(), td)
+	_, err := p.ProcessTraces(t.Context(), td)
 	require.NoError(t, err)
 
 	ss := td.ResourceSpans().At(0).ScopeSpans().At(0)
@@ -434,7 +449,7 @@ func TestProcessTraces_LeavesSchemaURLWhenNoMappingFires(t *testing.T) {
 }
 
 func TestProcessTraces_LeavesExistingSchemaURLWhenOverwriteEnabledButNoMappingFires(t *testing.T) {
-	p := &genaiNormalizerProcessor{
+	p := &Normalizer{
 		overwriteSchemaURL: true,
 		sources: []sourceNormalizer{
 			newNormalizer(map[string]string{"src.model": "dst.model"}, true, false),
@@ -445,7 +460,7 @@ func TestProcessTraces_LeavesExistingSchemaURLWhenOverwriteEnabledButNoMappingFi
 	td.ResourceSpans().At(0).ScopeSpans().At(0).SetSchemaUrl("https://opentelemetry.io/schemas/1.38.0")
 	span.Attributes().PutStr("http.method", "GET")
 
-	_, err := p.processTraces(t.Context(), td)
+	_, err := p.ProcessTraces(t.Context(), td)
 	require.NoError(t, err)
 
 	ss := td.ResourceSpans().At(0).ScopeSpans().At(0)
@@ -457,7 +472,7 @@ func TestProcessTraces_PreservesExistingSchemaURL(t *testing.T) {
 	// scope may still follow the original semantic conventions, and
 	// normalizing only the GenAI attributes while rewriting the schema_url
 	// would misrepresent those.
-	p := &genaiNormalizerProcessor{
+	p := &Normalizer{
 		sources: []sourceNormalizer{
 			newNormalizer(map[string]string{"src.model": "dst.model"}, true, false),
 		},
@@ -467,7 +482,7 @@ func TestProcessTraces_PreservesExistingSchemaURL(t *testing.T) {
 	td.ResourceSpans().At(0).ScopeSpans().At(0).SetSchemaUrl("https://opentelemetry.io/schemas/1.38.0")
 	span.Attributes().PutStr("src.model", "m")
 
-	_, err := p.processTraces(t.Context(), td)
+	_, err := p.ProcessTraces(t.Context(), td)
 	require.NoError(t, err)
 
 	ss := td.ResourceSpans().At(0).ScopeSpans().At(0)
@@ -475,7 +490,7 @@ func TestProcessTraces_PreservesExistingSchemaURL(t *testing.T) {
 }
 
 func TestProcessTraces_OverwritesExistingSchemaURLWhenEnabled(t *testing.T) {
-	p := newGenaiNormalizerProcessor(&Config{
+	p, err := NewNormalizer(&Config{
 		OverwriteSchemaURL: true,
 		Sources: []Source{{
 			Name:            "test",
@@ -483,12 +498,13 @@ func TestProcessTraces_OverwritesExistingSchemaURLWhenEnabled(t *testing.T) {
 			Mappings:        map[string]string{"src.model": "dst.model"},
 		}},
 	})
+	require.NoError(t, err)
 
 	td, span := newSpan()
 	td.ResourceSpans().At(0).ScopeSpans().At(0).SetSchemaUrl("https://opentelemetry.io/schemas/1.38.0")
 	span.Attributes().PutStr("src.model", "m")
 
-	_, err := p.processTraces(t.Context(), td)
+	_, err = p.ProcessTraces(t.Context(), td)
 	require.NoError(t, err)
 
 	ss := td.ResourceSpans().At(0).ScopeSpans().At(0)
@@ -496,7 +512,7 @@ func TestProcessTraces_OverwritesExistingSchemaURLWhenEnabled(t *testing.T) {
 }
 
 func TestProcessTraces_DoesNotModifyResourceSchemaURL(t *testing.T) {
-	p := &genaiNormalizerProcessor{
+	p := &Normalizer{
 		overwriteSchemaURL: true,
 		sources: []sourceNormalizer{
 			newNormalizer(map[string]string{"src.model": "dst.model"}, true, false),
@@ -507,7 +523,7 @@ func TestProcessTraces_DoesNotModifyResourceSchemaURL(t *testing.T) {
 	td.ResourceSpans().At(0).SetSchemaUrl("https://opentelemetry.io/schemas/1.38.0")
 	span.Attributes().PutStr("src.model", "m")
 
-	_, err := p.processTraces(t.Context(), td)
+	_, err := p.ProcessTraces(t.Context(), td)
 	require.NoError(t, err)
 
 	assert.Equal(
@@ -519,7 +535,7 @@ func TestProcessTraces_DoesNotModifyResourceSchemaURL(t *testing.T) {
 }
 
 func TestProcessTraces_IgnoresSpanEvents(t *testing.T) {
-	p := &genaiNormalizerProcessor{
+	p := &Normalizer{
 		sources: []sourceNormalizer{
 			newNormalizer(map[string]string{"src.model": "dst.model"}, true, false),
 		},
@@ -529,7 +545,7 @@ func TestProcessTraces_IgnoresSpanEvents(t *testing.T) {
 	evt := span.Events().AppendEmpty()
 	evt.Attributes().PutStr("src.model", "m")
 
-	_, err := p.processTraces(t.Context(), td)
+	_, err := p.ProcessTraces(t.Context(), td)
 	require.NoError(t, err)
 
 	evtAttrs := span.Events().At(0).Attributes()
@@ -543,7 +559,7 @@ func TestProcessTraces_IgnoresSpanEvents(t *testing.T) {
 func TestProcessTraces_AppliesSourcesInSliceOrder(t *testing.T) {
 	// Two sources targeting the same destination; overwrite=true on both.
 	// The second source's write wins, confirming iteration order is honored.
-	p := &genaiNormalizerProcessor{
+	p := &Normalizer{
 		sources: []sourceNormalizer{
 			newNormalizer(map[string]string{"src.a": "dst.model"}, true, true),
 			newNormalizer(map[string]string{"src.b": "dst.model"}, true, true),
@@ -554,7 +570,7 @@ func TestProcessTraces_AppliesSourcesInSliceOrder(t *testing.T) {
 	span.Attributes().PutStr("src.a", "from-a")
 	span.Attributes().PutStr("src.b", "from-b")
 
-	_, err := p.processTraces(t.Context(), td)
+	_, err := p.ProcessTraces(t.Context(), td)
 	require.NoError(t, err)
 
 	v, ok := span.Attributes().Get("dst.model")
@@ -563,17 +579,17 @@ func TestProcessTraces_AppliesSourcesInSliceOrder(t *testing.T) {
 }
 
 func TestProcessTraces_EmptyTraces(t *testing.T) {
-	p := &genaiNormalizerProcessor{sources: []sourceNormalizer{
+	p := &Normalizer{sources: []sourceNormalizer{
 		newNormalizer(map[string]string{"src.a": "dst.a"}, true, false),
 	}}
-	_, err := p.processTraces(t.Context(), ptrace.NewTraces())
+	_, err := p.ProcessTraces(t.Context(), ptrace.NewTraces())
 	require.NoError(t, err)
 }
 
-func TestCreateTracesProcessor_RejectsInvalidConfig(t *testing.T) {
-	_, err := createTracesProcessor(
+func TestNewTracesProcessor_RejectsInvalidConfig(t *testing.T) {
+	_, err := NewTracesProcessor(
 		t.Context(),
-		processortest.NewNopSettings(metadata.Type),
+		processortest.NewNopSettings(testType),
 		&Config{Sources: []Source{{Name: "bogus"}}},
 		new(consumertest.TracesSink),
 	)
@@ -581,13 +597,13 @@ func TestCreateTracesProcessor_RejectsInvalidConfig(t *testing.T) {
 }
 
 // TestNormalize_OpenInferenceEndToEnd exercises the real OpenInference
-// mapping table end-to-end through createTracesProcessor.
+// mapping table end-to-end through NewTracesProcessor.
 func TestNormalize_OpenInferenceEndToEnd(t *testing.T) {
 	cfg := &Config{
 		Sources: []Source{{Name: SourceOpenInference, RemoveOriginals: true}},
 	}
 	sink := new(consumertest.TracesSink)
-	p, err := createTracesProcessor(t.Context(), processortest.NewNopSettings(metadata.Type), cfg, sink)
+	p, err := NewTracesProcessor(t.Context(), processortest.NewNopSettings(testType), cfg, sink)
 	require.NoError(t, err)
 
 	td, span := newSpan()
@@ -643,7 +659,7 @@ func TestNormalize_OpenLLMetry_FinishReasonWrapsToSlice(t *testing.T) {
 		Sources: []Source{{Name: SourceOpenLLMetry, RemoveOriginals: true}},
 	}
 	sink := new(consumertest.TracesSink)
-	p, err := createTracesProcessor(t.Context(), processortest.NewNopSettings(metadata.Type), cfg, sink)
+	p, err := NewTracesProcessor(t.Context(), processortest.NewNopSettings(testType), cfg, sink)
 	require.NoError(t, err)
 
 	td, span := newSpan()
@@ -670,7 +686,7 @@ func TestNormalize_OpenLLMetry_OperationNameFolding(t *testing.T) {
 		Sources: []Source{{Name: SourceOpenLLMetry, RemoveOriginals: true}},
 	}
 	sink := new(consumertest.TracesSink)
-	p, err := createTracesProcessor(t.Context(), processortest.NewNopSettings(metadata.Type), cfg, sink)
+	p, err := NewTracesProcessor(t.Context(), processortest.NewNopSettings(testType), cfg, sink)
 	require.NoError(t, err)
 
 	td, span := newSpan()
@@ -702,7 +718,7 @@ func TestNormalize_Custom_RenamesUserDefinedAttribute(t *testing.T) {
 		}},
 	}
 	sink := new(consumertest.TracesSink)
-	p, err := createTracesProcessor(t.Context(), processortest.NewNopSettings(metadata.Type), cfg, sink)
+	p, err := NewTracesProcessor(t.Context(), processortest.NewNopSettings(testType), cfg, sink)
 	require.NoError(t, err)
 
 	td, span := newSpan()
@@ -733,7 +749,7 @@ func TestNormalize_Custom_CoercesOntoTypedTarget(t *testing.T) {
 		}},
 	}
 	sink := new(consumertest.TracesSink)
-	p, err := createTracesProcessor(t.Context(), processortest.NewNopSettings(metadata.Type), cfg, sink)
+	p, err := NewTracesProcessor(t.Context(), processortest.NewNopSettings(testType), cfg, sink)
 	require.NoError(t, err)
 
 	td, span := newSpan()
@@ -762,7 +778,7 @@ func TestNormalize_Custom_PassesThroughOnNonGenAITarget(t *testing.T) {
 		}},
 	}
 	sink := new(consumertest.TracesSink)
-	p, err := createTracesProcessor(t.Context(), processortest.NewNopSettings(metadata.Type), cfg, sink)
+	p, err := NewTracesProcessor(t.Context(), processortest.NewNopSettings(testType), cfg, sink)
 	require.NoError(t, err)
 
 	td, span := newSpan()
@@ -793,7 +809,7 @@ func TestNormalize_Custom_ComposesWithBuiltIn(t *testing.T) {
 		},
 	}
 	sink := new(consumertest.TracesSink)
-	p, err := createTracesProcessor(t.Context(), processortest.NewNopSettings(metadata.Type), cfg, sink)
+	p, err := NewTracesProcessor(t.Context(), processortest.NewNopSettings(testType), cfg, sink)
 	require.NoError(t, err)
 
 	td, span := newSpan()
@@ -837,7 +853,7 @@ func TestNormalize_Custom_TwoBlocksAppliedInOrder(t *testing.T) {
 		},
 	}
 	sink := new(consumertest.TracesSink)
-	p, err := createTracesProcessor(t.Context(), processortest.NewNopSettings(metadata.Type), cfg, sink)
+	p, err := NewTracesProcessor(t.Context(), processortest.NewNopSettings(testType), cfg, sink)
 	require.NoError(t, err)
 
 	td, span := newSpan()
@@ -863,7 +879,7 @@ func TestNormalize_Custom_OverwriteFalseSkipsExistingTarget(t *testing.T) {
 		}},
 	}
 	sink := new(consumertest.TracesSink)
-	p, err := createTracesProcessor(t.Context(), processortest.NewNopSettings(metadata.Type), cfg, sink)
+	p, err := NewTracesProcessor(t.Context(), processortest.NewNopSettings(testType), cfg, sink)
 	require.NoError(t, err)
 
 	td, span := newSpan()
@@ -893,7 +909,7 @@ func TestNormalize_Custom_ValueMappingFoldsEnum(t *testing.T) {
 		}},
 	}
 	sink := new(consumertest.TracesSink)
-	p, err := createTracesProcessor(t.Context(), processortest.NewNopSettings(metadata.Type), cfg, sink)
+	p, err := NewTracesProcessor(t.Context(), processortest.NewNopSettings(testType), cfg, sink)
 	require.NoError(t, err)
 
 	td, span := newSpan()
@@ -925,7 +941,7 @@ func TestNormalize_Custom_ValueMappingMissPassesThrough(t *testing.T) {
 		}},
 	}
 	sink := new(consumertest.TracesSink)
-	p, err := createTracesProcessor(t.Context(), processortest.NewNopSettings(metadata.Type), cfg, sink)
+	p, err := NewTracesProcessor(t.Context(), processortest.NewNopSettings(testType), cfg, sink)
 	require.NoError(t, err)
 
 	td, span := newSpan()
@@ -948,7 +964,7 @@ func TestNormalize_OpenLLMetry_StructuredMessagesPassThrough(t *testing.T) {
 		Sources: []Source{{Name: SourceOpenLLMetry, RemoveOriginals: true}},
 	}
 	sink := new(consumertest.TracesSink)
-	p, err := createTracesProcessor(t.Context(), processortest.NewNopSettings(metadata.Type), cfg, sink)
+	p, err := NewTracesProcessor(t.Context(), processortest.NewNopSettings(testType), cfg, sink)
 	require.NoError(t, err)
 
 	td, span := newSpan()
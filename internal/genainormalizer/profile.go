@@ -0,0 +1,163 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package genainormalizer // import "github.com/open-telemetry/opentelemetry-collector-contrib/internal/genainormalizer"
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/collector/confmap"
+	"gopkg.in/yaml.v3"
+)
+
+// profile holds the fields of a user-defined source that can be loaded from
+// its profile file, with the same keys as when set inline.
+type profile struct {
+	Mappings        map[string]string            `mapstructure:"mappings"`
+	ValueMappings   map[string]map[string]string `mapstructure:"value_mappings"`
+	Events          []EventRule                  `mapstructure:"events"`
+	UnitConversions []UnitConversion             `mapstructure:"unit_conversions"`
+	Pricing         PricingConfig                `mapstructure:"pricing"`
+}
+
+// hasProfile reports whether any of the fields a profile file provides is
+// set inline.
+func (src *Source) hasProfile() bool {
+	return len(src.Mappings) > 0 || len(src.ValueMappings) > 0 || len(src.Events) > 0 ||
+		len(src.UnitConversions) > 0 || len(src.Pricing.Models) > 0 || src.Pricing.PerTokens != 0
+}
+
+// withProfileFile returns src with the fields loaded from its profile file,
+// if any. Unknown keys in the file are rejected, and the loaded fields are
+// validated like inline ones.
+func (src Source) withProfileFile() (Source, error) {
+	if src.ProfileFile == "" {
+		return src, nil
+	}
+	content, err := os.ReadFile(src.ProfileFile)
+	if err != nil {
+		return src, fmt.Errorf("failed to read profile file %q: %w", src.ProfileFile, err)
+	}
+	var raw map[string]any
+	if err = yaml.Unmarshal(content, &raw); err != nil {
+		return src, fmt.Errorf("failed to parse profile file %q: %w", src.ProfileFile, err)
+	}
+	var p profile
+	if err = confmap.NewFromStringMap(raw).Unmarshal(&p); err != nil {
+		return src, fmt.Errorf("failed to parse profile file %q: %w", src.ProfileFile, err)
+	}
+
+	src.Mappings = p.Mappings
+	src.ValueMappings = p.ValueMappings
+	src.Events = p.Events
+	src.UnitConversions = p.UnitConversions
+	src.Pricing = p.Pricing
+	if len(src.Mappings) == 0 && len(src.Events) == 0 {
+		return src, fmt.Errorf("profile file %q: requires non-empty %q or %q", src.ProfileFile, "mappings", "events")
+	}
+	if err = src.validateProfile(); err != nil {
+		return src, fmt.Errorf("profile file %q: %w", src.ProfileFile, err)
+	}
+	return src, nil
+}
+
+// validateProfile checks the value mappings, events, unit conversions and
+// pricing of src.
+func (src *Source) validateProfile() error {
+	_, builtIn := builtInSources[src.Name]
+	targets := make(map[string]struct{}, len(src.Mappings))
+	for _, t := range src.Mappings {
+		targets[t] = struct{}{}
+	}
+	for k := range src.ValueMappings {
+		if _, ok := targets[k]; !ok {
+			return fmt.Errorf("%q key %q is not a target in %q", "value_mappings", k, "mappings")
+		}
+	}
+
+	for i, rule := range src.Events {
+		if err := rule.validate(); err != nil {
+			return fmt.Errorf("events[%d]: %w", i, err)
+		}
+	}
+
+	converted := make(map[string]struct{}, len(src.UnitConversions))
+	for i, conv := range src.UnitConversions {
+		if err := conv.validate(); err != nil {
+			return fmt.Errorf("unit_conversions[%d]: %w", i, err)
+		}
+		if _, dup := converted[conv.Attribute]; dup {
+			return fmt.Errorf("unit_conversions[%d]: duplicate attribute %q", i, conv.Attribute)
+		}
+		converted[conv.Attribute] = struct{}{}
+		// Built-in targets are not known here; user-defined ones are.
+		if _, ok := targets[conv.Attribute]; !ok && !builtIn {
+			return fmt.Errorf("unit_conversions[%d]: attribute %q is not a target in %q", i, conv.Attribute, "mappings")
+		}
+	}
+
+	if err := src.Pricing.validate(); err != nil {
+		return fmt.Errorf("pricing: %w", err)
+	}
+	return nil
+}
+
+func (rule *EventRule) validate() error {
+	if rule.Prefix == "" {
+		return fmt.Errorf("%q must be set", "prefix")
+	}
+	if rule.Name == "" {
+		return fmt.Errorf("%q must be set", "name")
+	}
+	if len(rule.Names) > 0 && rule.NameField == "" {
+		return fmt.Errorf("%q requires %q", "names", "name_field")
+	}
+	for path, key := range rule.Fields {
+		if path == "" || key == "" {
+			return fmt.Errorf("%q cannot contain empty field paths or attribute keys", "fields")
+		}
+	}
+	return nil
+}
+
+func (conv *UnitConversion) validate() error {
+	if conv.Attribute == "" {
+		return fmt.Errorf("%q must be set", "attribute")
+	}
+	if conv.Factor != 0 {
+		if conv.From != "" || conv.To != "" {
+			return fmt.Errorf("%q cannot be combined with %q and %q", "factor", "from", "to")
+		}
+		return nil
+	}
+	if conv.From == "" || conv.To == "" {
+		return fmt.Errorf("either %q or both %q and %q must be set", "factor", "from", "to")
+	}
+	for _, unit := range []string{conv.From, conv.To} {
+		if _, ok := timeUnits[unit]; !ok {
+			return fmt.Errorf("unknown unit %q, expected one of %s", unit, timeUnitNames)
+		}
+	}
+	return nil
+}
+
+func (cfg *PricingConfig) validate() error {
+	if cfg.PerTokens < 0 {
+		return fmt.Errorf("%q cannot be negative", "per_tokens")
+	}
+	for model, price := range cfg.Models {
+		if model == "" || model == "*" {
+			return errors.New("model names cannot be empty")
+		}
+		if strings.Contains(strings.TrimSuffix(model, "*"), "*") {
+			return fmt.Errorf("model %q: %q is only supported as the last character", model, "*")
+		}
+		if price.Input < 0 || price.Output < 0 {
+			return fmt.Errorf("model %q: prices cannot be negative", model)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,331 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package genainormalizer
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/processor/processortest"
+)
+
+func TestWithProfileFile(t *testing.T) {
+	src, err := Source{Name: "langchain", ProfileFile: filepath.Join("testdata", "profiles", "langchain.yaml")}.withProfileFile()
+	require.NoError(t, err)
+	assert.Equal(t, "gen_ai.request.model", src.Mappings["langchain.model"])
+	assert.Equal(t, map[string]string{"llm": "chat", "tool": "execute_tool"}, src.ValueMappings["gen_ai.operation.name"])
+	require.Len(t, src.Events, 1)
+	assert.Equal(t, "langchain.messages", src.Events[0].Prefix)
+	assert.Equal(t, "type", src.Events[0].NameField)
+	require.Len(t, src.UnitConversions, 1)
+	assert.InDelta(t, 0.001, src.UnitConversions[0].factor(), 1e-12)
+	assert.Equal(t, ModelPrice{Input: 2.5, Output: 10}, src.Pricing.Models["gpt-4o*"])
+
+	// Sources without a profile file are returned unchanged.
+	inline := Source{Name: "my_vendor", Mappings: map[string]string{"a": "b"}}
+	got, err := inline.withProfileFile()
+	require.NoError(t, err)
+	assert.Equal(t, inline, got)
+}
+
+func TestWithProfileFile_Errors(t *testing.T) {
+	tests := []struct {
+		file    string
+		wantErr string
+	}{
+		{
+			file:    "missing.yaml",
+			wantErr: "failed to read profile file",
+		},
+		{
+			file:    "unknown_key.yaml",
+			wantErr: "has invalid keys: mapping",
+		},
+		{
+			file:    "invalid_conversion.yaml",
+			wantErr: `unit_conversions[0]: unknown unit "days"`,
+		},
+		{
+			file:    "empty.yaml",
+			wantErr: `requires non-empty "mappings" or "events"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			_, err := Source{Name: "acme", ProfileFile: filepath.Join("testdata", "profiles", tt.file)}.withProfileFile()
+			require.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestNewTracesProcessor_RejectsInvalidProfileFile(t *testing.T) {
+	_, err := NewTracesProcessor(
+		t.Context(),
+		processortest.NewNopSettings(testType),
+		&Config{Sources: []Source{{Name: "acme", ProfileFile: filepath.Join("testdata", "profiles", "unknown_key.yaml")}}},
+		new(consumertest.TracesSink),
+	)
+	require.ErrorContains(t, err, "sources[0]: failed to parse profile file")
+}
+
+// TestNormalize_ProfileFileEndToEnd exercises a profile file with renames,
+// value mappings, events, unit conversions and pricing through ConsumeTraces.
+func TestNormalize_ProfileFileEndToEnd(t *testing.T) {
+	cfg := &Config{
+		Sources: []Source{{
+			Name:            "langchain",
+			RemoveOriginals: true,
+			ProfileFile:     filepath.Join("testdata", "profiles", "langchain.yaml"),
+		}},
+	}
+	sink := new(consumertest.TracesSink)
+	p, err := NewTracesProcessor(t.Context(), processortest.NewNopSettings(testType), cfg, sink)
+	require.NoError(t, err)
+
+	td, span := newSpan()
+	span.SetStartTimestamp(pcommon.Timestamp(1000))
+	attrs := span.Attributes()
+	attrs.PutStr("langchain.model", "gpt-4o-2024-08-06")
+	attrs.PutInt("langchain.usage.prompt_tokens", 1000)
+	attrs.PutStr("langchain.usage.completion_tokens", "500")
+	attrs.PutStr("langchain.run_type", "llm")
+	attrs.PutInt("langchain.latency_ms", 1500)
+	attrs.PutStr("langchain.messages.1.type", "human")
+	attrs.PutStr("langchain.messages.1.content", "What is the weather?")
+	attrs.PutStr("langchain.messages.0.type", "system")
+	attrs.PutStr("langchain.messages.0.content", "You are helpful.")
+	attrs.PutStr("langchain.messages.0.id", "dropped")
+
+	require.NoError(t, p.ConsumeTraces(t.Context(), td))
+	ss := sink.AllTraces()[0].ResourceSpans().At(0).ScopeSpans().At(0)
+	assert.Equal(t, "https://opentelemetry.io/schemas/1.40.0", ss.SchemaUrl())
+	out := ss.Spans().At(0)
+
+	cost, ok := out.Attributes().Get("gen_ai.usage.cost")
+	require.True(t, ok)
+	assert.InDelta(t, (1000*2.5+500*10)/1e6, cost.Double(), 1e-12)
+	assert.Equal(t, map[string]any{
+		"gen_ai.request.model":       "gpt-4o-2024-08-06",
+		"gen_ai.usage.input_tokens":  int64(1000),
+		"gen_ai.usage.output_tokens": int64(500),
+		"gen_ai.operation.name":      "chat",
+		"gen_ai.client.latency":      1.5,
+		"gen_ai.usage.cost":          cost.Double(),
+	}, out.Attributes().AsRaw())
+
+	require.Equal(t, 2, out.Events().Len())
+	system := out.Events().At(0)
+	assert.Equal(t, "gen_ai.system.message", system.Name())
+	assert.Equal(t, pcommon.Timestamp(1000), system.Timestamp())
+	assert.Equal(t, map[string]any{"role": "system", "content": "You are helpful."}, system.Attributes().AsRaw())
+	user := out.Events().At(1)
+	assert.Equal(t, "gen_ai.user.message", user.Name())
+	assert.Equal(t, map[string]any{"role": "human", "content": "What is the weather?"}, user.Attributes().AsRaw())
+}
+
+func TestEventConverter(t *testing.T) {
+	t.Run("keeps every field without a fields table", func(t *testing.T) {
+		_, span := newSpan()
+		span.Attributes().PutStr("acme.prompts.0.text", "hi")
+		span.Attributes().PutInt("acme.prompts.0.tokens", 2)
+		span.Attributes().PutStr("acme.prompts.x.text", "not indexed")
+		span.Attributes().PutStr("acme.prompts.1", "no field")
+
+		ec := newEventConverter(EventRule{Prefix: "acme.prompts", Name: "acme.prompt"})
+		require.True(t, ec.convert(span, false))
+		require.Equal(t, 1, span.Events().Len())
+		assert.Equal(t, "acme.prompt", span.Events().At(0).Name())
+		assert.Equal(t, map[string]any{"text": "hi", "tokens": int64(2)}, span.Events().At(0).Attributes().AsRaw())
+
+		// Originals are kept unless remove_originals is set.
+		assert.Equal(t, 4, span.Attributes().Len())
+	})
+
+	t.Run("no matching attributes", func(t *testing.T) {
+		_, span := newSpan()
+		span.Attributes().PutStr("acme.model", "m")
+
+		ec := newEventConverter(EventRule{Prefix: "acme.prompts", Name: "acme.prompt"})
+		assert.False(t, ec.convert(span, true))
+		assert.Equal(t, 0, span.Events().Len())
+		assert.Equal(t, 1, span.Attributes().Len())
+	})
+}
+
+func TestConvertUnit(t *testing.T) {
+	tests := []struct {
+		name   string
+		src    pcommon.Value
+		factor float64
+		want   any
+	}{
+		{name: "int to fractional double", src: pcommon.NewValueInt(1500), factor: 0.001, want: 1.5},
+		{name: "int stays int when integral", src: pcommon.NewValueInt(2), factor: 1000, want: int64(2000)},
+		{name: "double", src: pcommon.NewValueDouble(0.5), factor: 60, want: 30.0},
+		{name: "numeric string", src: pcommon.NewValueStr("250"), factor: 0.001, want: 0.25},
+		{name: "non-numeric string", src: pcommon.NewValueStr("fast"), factor: 0.001},
+		{name: "bool", src: pcommon.NewValueBool(true), factor: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst := pcommon.NewValueEmpty()
+			ok := convertUnit(tt.src, dst, tt.factor)
+			if tt.want == nil {
+				assert.False(t, ok)
+				return
+			}
+			require.True(t, ok)
+			assert.Equal(t, tt.want, dst.AsRaw())
+		})
+	}
+}
+
+func TestNormalizeAttributes_UnitConversionDropsNonNumeric(t *testing.T) {
+	_, span := newSpan()
+	span.Attributes().PutStr("acme.latency", "slow")
+
+	sn := newNormalizer(map[string]string{"acme.latency": "acme.latency_s"}, true, false)
+	sn.conversions = map[string]float64{"acme.latency_s": 0.001}
+	assert.False(t, sn.normalizeAttributes(span.Attributes()))
+	assert.Equal(t, map[string]any{"acme.latency": "slow"}, span.Attributes().AsRaw())
+}
+
+func TestPricer(t *testing.T) {
+	p := newPricer(PricingConfig{
+		PerTokens: 1000,
+		Models: map[string]ModelPrice{
+			"gpt-4o":       {Input: 1, Output: 2},
+			"gpt-4*":       {Input: 10, Output: 20},
+			"gpt-4o-mini*": {Input: 0.1, Output: 0.2},
+		},
+	})
+
+	tests := []struct {
+		name      string
+		overwrite bool
+		setup     func(pcommon.Map)
+		want      float64
+		wantWrote bool
+	}{
+		{
+			name: "exact model",
+			setup: func(attrs pcommon.Map) {
+				attrs.PutStr("gen_ai.request.model", "gpt-4o")
+				attrs.PutInt("gen_ai.usage.input_tokens", 1000)
+				attrs.PutInt("gen_ai.usage.output_tokens", 500)
+			},
+			want:      2,
+			wantWrote: true,
+		},
+		{
+			name: "longest prefix wins",
+			setup: func(attrs pcommon.Map) {
+				attrs.PutStr("gen_ai.request.model", "gpt-4o-mini-2024-07-18")
+				attrs.PutInt("gen_ai.usage.input_tokens", 1000)
+			},
+			want:      0.1,
+			wantWrote: true,
+		},
+		{
+			name: "response model takes precedence",
+			setup: func(attrs pcommon.Map) {
+				attrs.PutStr("gen_ai.request.model", "gpt-4o")
+				attrs.PutStr("gen_ai.response.model", "gpt-4-turbo")
+				attrs.PutInt("gen_ai.usage.output_tokens", 100)
+			},
+			want:      2,
+			wantWrote: true,
+		},
+		{
+			name: "unpriced response model falls back to request model",
+			setup: func(attrs pcommon.Map) {
+				attrs.PutStr("gen_ai.request.model", "gpt-4o")
+				attrs.PutStr("gen_ai.response.model", "o1")
+				attrs.PutInt("gen_ai.usage.input_tokens", 1000)
+			},
+			want:      1,
+			wantWrote: true,
+		},
+		{
+			name: "unpriced model",
+			setup: func(attrs pcommon.Map) {
+				attrs.PutStr("gen_ai.request.model", "claude-sonnet-4")
+				attrs.PutInt("gen_ai.usage.input_tokens", 1000)
+			},
+		},
+		{
+			name: "no token usage",
+			setup: func(attrs pcommon.Map) {
+				attrs.PutStr("gen_ai.request.model", "gpt-4o")
+			},
+		},
+		{
+			name: "existing cost kept without overwrite",
+			setup: func(attrs pcommon.Map) {
+				attrs.PutStr("gen_ai.request.model", "gpt-4o")
+				attrs.PutInt("gen_ai.usage.input_tokens", 1000)
+				attrs.PutDouble("gen_ai.usage.cost", 42)
+			},
+			want: 42,
+		},
+		{
+			name:      "existing cost replaced with overwrite",
+			overwrite: true,
+			setup: func(attrs pcommon.Map) {
+				attrs.PutStr("gen_ai.request.model", "gpt-4o")
+				attrs.PutInt("gen_ai.usage.input_tokens", 1000)
+				attrs.PutDouble("gen_ai.usage.cost", 42)
+			},
+			want:      1,
+			wantWrote: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attrs := pcommon.NewMap()
+			tt.setup(attrs)
+			assert.Equal(t, tt.wantWrote, p.apply(attrs, tt.overwrite))
+			cost, ok := attrs.Get("gen_ai.usage.cost")
+			if tt.want == 0 {
+				assert.False(t, ok)
+				return
+			}
+			require.True(t, ok)
+			assert.InDelta(t, tt.want, cost.Double(), 1e-12)
+		})
+	}
+
+	assert.Nil(t, newPricer(PricingConfig{}))
+}
+
+// TestNormalize_BuiltInWithPricing asserts that pricing on a built-in source
+// uses the token usage and model written by its renames.
+func TestNormalize_BuiltInWithPricing(t *testing.T) {
+	cfg := &Config{
+		Sources: []Source{{
+			Name:            SourceOpenInference,
+			RemoveOriginals: true,
+			Pricing:         PricingConfig{Models: map[string]ModelPrice{"claude-sonnet-4*": {Input: 3, Output: 15}}},
+		}},
+	}
+	sink := new(consumertest.TracesSink)
+	p, err := NewTracesProcessor(t.Context(), processortest.NewNopSettings(testType), cfg, sink)
+	require.NoError(t, err)
+
+	td, span := newSpan()
+	span.Attributes().PutInt("llm.token_count.prompt", 2000)
+	span.Attributes().PutInt("llm.token_count.completion", 1000)
+	span.Attributes().PutStr("llm.model_name", "claude-sonnet-4-20250514")
+
+	require.NoError(t, p.ConsumeTraces(t.Context(), td))
+	out := sink.AllTraces()[0].ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0).Attributes()
+
+	cost, ok := out.Get("gen_ai.usage.cost")
+	require.True(t, ok)
+	assert.InDelta(t, 0.021, cost.Double(), 1e-12)
+}
@@ -0,0 +1,64 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package genainormalizer // import "github.com/open-telemetry/opentelemetry-collector-contrib/internal/genainormalizer"
+
+import (
+	"math"
+	"strconv"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+// timeUnits maps the time units supported by unit conversions to their
+// value in seconds.
+var timeUnits = map[string]float64{
+	"ns":  1e-9,
+	"us":  1e-6,
+	"ms":  1e-3,
+	"s":   1,
+	"min": 60,
+	"h":   3600,
+}
+
+const timeUnitNames = "ns, us, ms, s, min, h"
+
+// factor returns the factor a value is multiplied by. It expects a
+// validated conversion.
+func (conv *UnitConversion) factor() float64 {
+	if conv.Factor != 0 {
+		return conv.Factor
+	}
+	return timeUnits[conv.From] / timeUnits[conv.To]
+}
+
+// convertUnit writes src multiplied by factor into dst. Integer sources stay
+// integers when the result is integral. It returns false when src is not
+// numeric; callers must drop the attribute rather than write it in the
+// wrong unit.
+func convertUnit(src, dst pcommon.Value, factor float64) bool {
+	var f float64
+	isInt := false
+	switch src.Type() {
+	case pcommon.ValueTypeInt:
+		f, isInt = float64(src.Int()), true
+	case pcommon.ValueTypeDouble:
+		f = src.Double()
+	case pcommon.ValueTypeStr:
+		if i, err := strconv.ParseInt(src.Str(), 10, 64); err == nil {
+			f, isInt = float64(i), true
+		} else if f, err = strconv.ParseFloat(src.Str(), 64); err != nil {
+			return false
+		}
+	default:
+		return false
+	}
+
+	f *= factor
+	if isInt && f == math.Trunc(f) && math.Abs(f) < math.MaxInt64 {
+		dst.SetInt(int64(f))
+		return true
+	}
+	dst.SetDouble(f)
+	return true
+}
@@ -1,7 +1,7 @@
 // Copyright The OpenTelemetry Authors
 // SPDX-License-Identifier: Apache-2.0
 
-package spanpruningprocessor // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/spanpruningprocessor"
+package spanpruning // import "github.com/open-telemetry/opentelemetry-collector-contrib/internal/spanpruning"
 
 import (
 	"encoding/binary"
@@ -60,7 +60,7 @@ func generateSpanID() pcommon.SpanID {
 
 // buildAggregationPlan sorts aggregation groups by depth (parents before
 // children) and preassigns summary SpanIDs to avoid conflicts during writes.
-func (*spanPruningProcessor) buildAggregationPlan(groups map[string]aggregationGroup) aggregationPlan {
+func (*Pruner) buildAggregationPlan(groups map[string]aggregationGroup) aggregationPlan {
 	// Convert map to slice with pre-allocation
 	groupSlice := make([]aggregationGroup, 0, len(groups))
 	for key := range groups {
@@ -83,7 +83,7 @@ func (*spanPruningProcessor) buildAggregationPlan(groups map[string]aggregationG
 // executeAggregations performs the top-down creation of summary spans, removes
 // originals using the tree's markedForRemoval flags, and returns the number of
 // pruned spans.
-func (p *spanPruningProcessor) executeAggregations(plan aggregationPlan, tree *traceTree) int {
+func (p *Pruner) executeAggregations(plan aggregationPlan, tree *traceTree) int {
 	prunedCount := 0
 	prefix := p.config.AggregationAttributePrefix
 
@@ -139,7 +139,7 @@ func (p *spanPruningProcessor) executeAggregations(plan aggregationPlan, tree *t
 // createSummarySpanWithParent builds the summary span for an aggregation
 // group, wiring it under the provided parent SpanID and attaching stats
 // and attribute-loss annotations.
-func (p *spanPruningProcessor) createSummarySpanWithParent(group aggregationGroup, data aggregationData, parentSpanID pcommon.SpanID) ptrace.Span {
+func (p *Pruner) createSummarySpanWithParent(group aggregationGroup, data aggregationData, parentSpanID pcommon.SpanID) ptrace.Span {
 	// Use the template node (longest duration span) as a template
 	templateNode := group.templateNode
 	templateSpan := templateNode.span
@@ -1,7 +1,7 @@
 // Copyright The OpenTelemetry Authors
 // SPDX-License-Identifier: Apache-2.0
 
-package spanpruningprocessor
+package spanpruning
 
 import (
 	"testing"
@@ -105,7 +105,7 @@ func TestFindLongestDurationNode_ManyNodes(t *testing.T) {
 
 func TestCreateSummarySpanWithParent_AddsAttributeLossAnnotations(t *testing.T) {
 	nodes := createSpanNodesWithDurations(t, []int64{100, 200, 300})
-	processor := &spanPruningProcessor{
+	processor := &Pruner{
 		config: &Config{
 			AggregationAttributePrefix: "aggregation.",
 		},
@@ -140,7 +140,7 @@ func TestCreateSummarySpanWithParent_AddsAttributeLossAnnotations(t *testing.T)
 
 func TestCreateSummarySpanWithParent_OmitsAttributeLossAnnotationsWhenEmpty(t *testing.T) {
 	nodes := createSpanNodesWithDurations(t, []int64{100, 200, 300})
-	processor := &spanPruningProcessor{
+	processor := &Pruner{
 		config: &Config{
 			AggregationAttributePrefix: "aggregation.",
 		},
@@ -1,7 +1,7 @@
 // Copyright The OpenTelemetry Authors
 // SPDX-License-Identifier: Apache-2.0
 
-package spanpruningprocessor // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/spanpruningprocessor"
+package spanpruning // import "github.com/open-telemetry/opentelemetry-collector-contrib/internal/spanpruning"
 
 import (
 	"sort"
@@ -0,0 +1,211 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package spanpruning // import "github.com/open-telemetry/opentelemetry-collector-contrib/internal/spanpruning"
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/processor/processorhelper"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/spanpruning/internal/metadata"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/batchpersignal"
+)
+
+const (
+	// minReleaseInterval and maxReleaseInterval bound how often the buffer
+	// checks for expired traces, a tenth of the wait duration otherwise.
+	minReleaseInterval = 10 * time.Millisecond
+	maxReleaseInterval = time.Second
+)
+
+// releaseFunc prunes and forwards traces released by the buffer.
+type releaseFunc func(ctx context.Context, td ptrace.Traces) error
+
+// bufferedTrace holds the spans received so far for a trace.
+type bufferedTrace struct {
+	id        pcommon.TraceID
+	td        ptrace.Traces
+	spans     int
+	expiresAt time.Time
+	element   *list.Element
+}
+
+// TraceBuffer holds the spans of each trace until the wait duration has
+// elapsed since its first span was received, like the groupbytrace
+// processor, so that traces spread over several batches are pruned as a
+// whole. Traces are kept in arrival order: as they all wait for the same
+// duration, they also expire in that order, and the oldest traces are the
+// ones released early when the buffer exceeds its bounds.
+type TraceBuffer struct {
+	config           BufferConfig
+	logger           *zap.Logger
+	telemetryBuilder *metadata.TelemetryBuilder
+	release          releaseFunc
+
+	mu     sync.Mutex
+	traces map[pcommon.TraceID]*bufferedTrace
+	order  *list.List
+	spans  int
+
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+func newTraceBuffer(config BufferConfig, logger *zap.Logger, telemetryBuilder *metadata.TelemetryBuilder, release releaseFunc) *TraceBuffer {
+	return &TraceBuffer{
+		config:           config,
+		logger:           logger,
+		telemetryBuilder: telemetryBuilder,
+		release:          release,
+		traces:           make(map[pcommon.TraceID]*bufferedTrace),
+		order:            list.New(),
+		stopCh:           make(chan struct{}),
+	}
+}
+
+// Start launches the goroutine releasing the expired traces.
+func (b *TraceBuffer) Start(context.Context, component.Host) error {
+	interval := min(max(b.config.WaitDuration/10, minReleaseInterval), maxReleaseInterval)
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-b.stopCh:
+				return
+			case now := <-ticker.C:
+				b.releaseExpired(now)
+			}
+		}
+	}()
+	return nil
+}
+
+// Shutdown stops releasing the expired traces and releases every trace still
+// held, so no span is lost.
+func (b *TraceBuffer) Shutdown(ctx context.Context) error {
+	close(b.stopCh)
+	b.wg.Wait()
+
+	b.mu.Lock()
+	td, released, spans := b.takeLocked(func(*bufferedTrace) bool { return true })
+	b.mu.Unlock()
+	if released == 0 {
+		return nil
+	}
+	b.recordRemoved(ctx, released, spans)
+	b.telemetryBuilder.ProcessorSpanpruningBufferTracesReleased.Add(ctx, int64(released))
+	return b.release(ctx, td)
+}
+
+// processTraces buffers td in place of the processor function of a buffered
+// processor: the traces are forwarded when released.
+func (b *TraceBuffer) processTraces(ctx context.Context, td ptrace.Traces) (ptrace.Traces, error) {
+	if err := b.Add(ctx, td); err != nil {
+		return td, err
+	}
+	return td, processorhelper.ErrSkipProcessingData
+}
+
+// Add buffers the spans of td by trace. When the buffer exceeds its bounds,
+// the oldest traces are released early, synchronously, so the pipeline
+// applies back pressure instead of growing the buffer.
+func (b *TraceBuffer) Add(ctx context.Context, td ptrace.Traces) error {
+	expiresAt := time.Now().Add(b.config.WaitDuration)
+	evicted := ptrace.NewTraces()
+	var added, addedSpans, evictedTraces, evictedSpans int
+
+	b.mu.Lock()
+	for _, trace := range batchpersignal.SplitTraces(td) {
+		traceID := trace.ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0).TraceID()
+		spans := trace.SpanCount()
+		if bt, ok := b.traces[traceID]; ok {
+			trace.ResourceSpans().MoveAndAppendTo(bt.td.ResourceSpans())
+			bt.spans += spans
+		} else {
+			bt = &bufferedTrace{id: traceID, td: trace, spans: spans, expiresAt: expiresAt}
+			bt.element = b.order.PushBack(bt)
+			b.traces[traceID] = bt
+			added++
+		}
+		b.spans += spans
+		addedSpans += spans
+
+		for b.order.Len() > 0 && (len(b.traces) > b.config.MaxTraces || b.spans > b.config.MaxSpans) {
+			oldest := b.order.Front().Value.(*bufferedTrace)
+			b.removeLocked(oldest)
+			oldest.td.ResourceSpans().MoveAndAppendTo(evicted.ResourceSpans())
+			evictedTraces++
+			evictedSpans += oldest.spans
+		}
+	}
+	b.mu.Unlock()
+
+	b.telemetryBuilder.ProcessorSpanpruningBufferTraces.Add(ctx, int64(added))
+	b.telemetryBuilder.ProcessorSpanpruningBufferSpans.Add(ctx, int64(addedSpans))
+	if evictedTraces == 0 {
+		return nil
+	}
+	b.recordRemoved(ctx, evictedTraces, evictedSpans)
+	b.telemetryBuilder.ProcessorSpanpruningBufferTracesEvicted.Add(ctx, int64(evictedTraces))
+	b.logger.Debug("traces released before the wait duration elapsed, adjust the buffer bounds and/or wait duration to avoid it",
+		zap.Int("traces", evictedTraces),
+		zap.Int("spans", evictedSpans))
+	return b.release(ctx, evicted)
+}
+
+// releaseExpired releases the traces whose wait duration elapsed at now.
+func (b *TraceBuffer) releaseExpired(now time.Time) {
+	b.mu.Lock()
+	td, released, spans := b.takeLocked(func(bt *bufferedTrace) bool { return !bt.expiresAt.After(now) })
+	b.mu.Unlock()
+	if released == 0 {
+		return
+	}
+
+	ctx := context.Background()
+	b.recordRemoved(ctx, released, spans)
+	b.telemetryBuilder.ProcessorSpanpruningBufferTracesReleased.Add(ctx, int64(released))
+	if err := b.release(ctx, td); err != nil {
+		b.logger.Error("failed to release buffered traces", zap.Int("traces", released), zap.Error(err))
+	}
+}
+
+// takeLocked removes the oldest traces as long as they match, and returns
+// them in a single batch along with their number of traces and spans.
+func (b *TraceBuffer) takeLocked(match func(*bufferedTrace) bool) (ptrace.Traces, int, int) {
+	td := ptrace.NewTraces()
+	var traces, spans int
+	for b.order.Len() > 0 {
+		oldest := b.order.Front().Value.(*bufferedTrace)
+		if !match(oldest) {
+			break
+		}
+		b.removeLocked(oldest)
+		oldest.td.ResourceSpans().MoveAndAppendTo(td.ResourceSpans())
+		traces++
+		spans += oldest.spans
+	}
+	return td, traces, spans
+}
+
+func (b *TraceBuffer) removeLocked(bt *bufferedTrace) {
+	b.order.Remove(bt.element)
+	delete(b.traces, bt.id)
+	b.spans -= bt.spans
+}
+
+func (b *TraceBuffer) recordRemoved(ctx context.Context, traces, spans int) {
+	b.telemetryBuilder.ProcessorSpanpruningBufferTraces.Add(ctx, -int64(traces))
+	b.telemetryBuilder.ProcessorSpanpruningBufferSpans.Add(ctx, -int64(spans))
+}
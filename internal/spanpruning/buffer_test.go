@@ -0,0 +1,181 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package spanpruning
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata/metricdatatest"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/spanpruning/internal/metadata"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/spanpruning/internal/metadatatest"
+)
+
+// createTestLeafSpanBatch returns a batch holding only leaf spans of the
+// trace created by createTestTraceWithLeafSpans, as a later batch of the same
+// trace would.
+func createTestLeafSpanBatch(t *testing.T, traceID pcommon.TraceID, batch byte, numLeafSpans int) ptrace.Traces {
+	t.Helper()
+	td := ptrace.NewTraces()
+	ss := td.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty()
+	for i := range numLeafSpans {
+		span := ss.Spans().AppendEmpty()
+		span.SetTraceID(traceID)
+		span.SetSpanID(pcommon.SpanID([8]byte{3, batch, byte(i), 0, 0, 0, 0, 0}))
+		span.SetParentSpanID(pcommon.SpanID([8]byte{1, 0, 0, 0, 0, 0, 0, 0}))
+		span.SetName("SELECT")
+		span.SetStartTimestamp(pcommon.Timestamp(1000000000 + int64(i)*100))
+		span.SetEndTimestamp(pcommon.Timestamp(1000000100 + int64(i)*100))
+		span.Attributes().PutStr("db.operation", "select")
+	}
+	return td
+}
+
+func newBufferedTestConfig() *Config {
+	cfg := newTestFactory().CreateDefaultConfig().(*Config)
+	cfg.MinSpansToAggregate = 5
+	cfg.Buffer.Enabled = true
+	cfg.Buffer.WaitDuration = 50 * time.Millisecond
+	return cfg
+}
+
+func TestBufferPrunesAcrossBatches(t *testing.T) {
+	testTel := componenttest.NewTelemetry()
+	defer func() { require.NoError(t, testTel.Shutdown(t.Context())) }()
+
+	sink := new(consumertest.TracesSink)
+	tp, err := newTestFactory().CreateTraces(t.Context(), newTestSettings(testTel), newBufferedTestConfig(), sink)
+	require.NoError(t, err)
+	require.NoError(t, tp.Start(t.Context(), componenttest.NewNopHost()))
+
+	// Neither batch has enough spans to be pruned on its own.
+	first := createTestTraceWithLeafSpans(t, 3, map[string]string{"db.operation": "select"})
+	traceID := first.ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0).TraceID()
+	require.NoError(t, tp.ConsumeTraces(t.Context(), first))
+	require.NoError(t, tp.ConsumeTraces(t.Context(), createTestLeafSpanBatch(t, traceID, 1, 3)))
+	assert.Zero(t, sink.SpanCount())
+
+	require.Eventually(t, func() bool { return sink.SpanCount() > 0 }, 5*time.Second, 10*time.Millisecond)
+	require.Len(t, sink.AllTraces(), 1)
+	td := sink.AllTraces()[0]
+	assert.Equal(t, 2, countSpans(td))
+	summary, found := findSummarySpan(td)
+	require.True(t, found)
+	spanCount, _ := summary.Attributes().Get("aggregation.span_count")
+	assert.Equal(t, int64(6), spanCount.Int())
+
+	require.NoError(t, tp.Shutdown(t.Context()))
+	metadatatest.AssertEqualProcessorSpanpruningBufferTracesReleased(t, testTel,
+		[]metricdata.DataPoint[int64]{{Value: 1}},
+		metricdatatest.IgnoreTimestamp())
+	metadatatest.AssertEqualProcessorSpanpruningBufferTraces(t, testTel,
+		[]metricdata.DataPoint[int64]{{Value: 0}},
+		metricdatatest.IgnoreTimestamp())
+	metadatatest.AssertEqualProcessorSpanpruningBufferSpans(t, testTel,
+		[]metricdata.DataPoint[int64]{{Value: 0}},
+		metricdatatest.IgnoreTimestamp())
+}
+
+func TestBufferEvictsOldestTraces(t *testing.T) {
+	testTel := componenttest.NewTelemetry()
+	defer func() { require.NoError(t, testTel.Shutdown(t.Context())) }()
+
+	cfg := newBufferedTestConfig()
+	cfg.Buffer.WaitDuration = time.Hour
+	cfg.Buffer.MaxSpans = 10
+
+	sink := new(consumertest.TracesSink)
+	tp, err := newTestFactory().CreateTraces(t.Context(), newTestSettings(testTel), cfg, sink)
+	require.NoError(t, err)
+	require.NoError(t, tp.Start(t.Context(), componenttest.NewNopHost()))
+
+	require.NoError(t, tp.ConsumeTraces(t.Context(), createTestTraceWithLeafSpans(t, 6, map[string]string{"db.operation": "select"})))
+	assert.Zero(t, sink.SpanCount())
+
+	// The second trace exceeds max_spans: the first trace is released early,
+	// pruned, while the second one is still held.
+	other := createTestLeafSpanBatch(t, pcommon.TraceID([16]byte{2}), 1, 5)
+	require.NoError(t, tp.ConsumeTraces(t.Context(), other))
+	require.Len(t, sink.AllTraces(), 1)
+	assert.Equal(t, 2, countSpans(sink.AllTraces()[0]))
+
+	metadatatest.AssertEqualProcessorSpanpruningBufferTracesEvicted(t, testTel,
+		[]metricdata.DataPoint[int64]{{Value: 1}},
+		metricdatatest.IgnoreTimestamp())
+	metadatatest.AssertEqualProcessorSpanpruningBufferTraces(t, testTel,
+		[]metricdata.DataPoint[int64]{{Value: 1}},
+		metricdatatest.IgnoreTimestamp())
+	metadatatest.AssertEqualProcessorSpanpruningBufferSpans(t, testTel,
+		[]metricdata.DataPoint[int64]{{Value: 5}},
+		metricdatatest.IgnoreTimestamp())
+
+	// Shutting down releases the traces still held.
+	require.NoError(t, tp.Shutdown(t.Context()))
+	require.Len(t, sink.AllTraces(), 2)
+	assert.Equal(t, 1, countSpans(sink.AllTraces()[1]))
+}
+
+func TestBufferMaxTraces(t *testing.T) {
+	var released []ptrace.Traces
+	buffer := newTraceBuffer(BufferConfig{Enabled: true, WaitDuration: time.Hour, MaxTraces: 2, MaxSpans: 100}, zap.NewNop(), newTestTelemetryBuilder(t), func(_ context.Context, td ptrace.Traces) error {
+		released = append(released, td)
+		return nil
+	})
+
+	for i := range 3 {
+		require.NoError(t, buffer.Add(t.Context(), createTestLeafSpanBatch(t, pcommon.TraceID([16]byte{byte(i + 1)}), byte(i), 1)))
+	}
+	require.Len(t, released, 1)
+	assert.Equal(t, pcommon.TraceID([16]byte{1}), released[0].ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0).TraceID())
+
+	// A trace already held does not count twice.
+	require.NoError(t, buffer.Add(t.Context(), createTestLeafSpanBatch(t, pcommon.TraceID([16]byte{3}), 4, 1)))
+	assert.Len(t, released, 1)
+	assert.Len(t, buffer.traces, 2)
+	assert.Equal(t, 3, buffer.spans)
+}
+
+func TestBufferReleaseExpired(t *testing.T) {
+	var released []ptrace.Traces
+	releaseErr := errors.New("export failed")
+	buffer := newTraceBuffer(BufferConfig{Enabled: true, WaitDuration: time.Minute, MaxTraces: 10, MaxSpans: 100}, zap.NewNop(), newTestTelemetryBuilder(t), func(_ context.Context, td ptrace.Traces) error {
+		released = append(released, td)
+		return releaseErr
+	})
+
+	require.NoError(t, buffer.Add(t.Context(), createTestLeafSpanBatch(t, pcommon.TraceID([16]byte{1}), 0, 2)))
+	expiresAt := buffer.traces[pcommon.TraceID([16]byte{1})].expiresAt
+
+	buffer.releaseExpired(expiresAt.Add(-time.Millisecond))
+	assert.Empty(t, released)
+
+	// Release errors are only logged.
+	buffer.releaseExpired(expiresAt)
+	require.Len(t, released, 1)
+	assert.Equal(t, 2, released[0].SpanCount())
+	assert.Empty(t, buffer.traces)
+	assert.Zero(t, buffer.spans)
+
+	// Nothing is left to release on shutdown.
+	require.NoError(t, buffer.Shutdown(t.Context()))
+	assert.Len(t, released, 1)
+}
+
+func newTestTelemetryBuilder(t *testing.T) *metadata.TelemetryBuilder {
+	t.Helper()
+	telemetryBuilder, err := metadata.NewTelemetryBuilder(componenttest.NewNopTelemetrySettings())
+	require.NoError(t, err)
+	return telemetryBuilder
+}
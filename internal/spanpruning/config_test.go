@@ -1,7 +1,7 @@
 // Copyright The OpenTelemetry Authors
 // SPDX-License-Identifier: Apache-2.0
 
-package spanpruningprocessor
+package spanpruning
 
 import (
 	"path/filepath"
@@ -12,8 +12,6 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/confmap/confmaptest"
-
-	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/spanpruningprocessor/internal/metadata"
 )
 
 var defaultHistogramBuckets = []time.Duration{
@@ -30,6 +28,12 @@ var defaultHistogramBuckets = []time.Duration{
 	10 * time.Second,
 }
 
+var defaultBufferConfig = BufferConfig{
+	WaitDuration: 10 * time.Second,
+	MaxTraces:    10_000,
+	MaxSpans:     1_000_000,
+}
+
 var customHistogramBuckets = []time.Duration{
 	10 * time.Millisecond,
 	50 * time.Millisecond,
@@ -47,7 +51,7 @@ func TestLoadConfig(t *testing.T) {
 		errorMessage string
 	}{
 		{
-			id: component.NewIDWithName(metadata.Type, ""),
+			id: component.NewIDWithName(testType, ""),
 			expected: &Config{
 				GroupByAttributes:           []string{"db.operation"},
 				MinSpansToAggregate:         5,
@@ -55,7 +59,7 @@ func TestLoadConfig(t *testing.T) {
 				AggregationAttributePrefix:  "aggregation.",
 				AggregationHistogramBuckets: defaultHistogramBuckets,
 				EnableAttributeLossAnalysis: false,
-				// Default from createDefaultConfig should persist when omitted in YAML.
+				// Default from NewDefaultConfig should persist when omitted in YAML.
 				AttributeLossExemplarSampleRate: 0,
 				EnableOutlierAnalysis:           false,
 				OutlierAnalysis: OutlierAnalysisConfig{
@@ -71,10 +75,11 @@ func TestLoadConfig(t *testing.T) {
 					PreserveOnlyWithCorrelation:    false,
 					MinOutlierThresholdPercent:     0.1,
 				},
+				Buffer: defaultBufferConfig,
 			},
 		},
 		{
-			id: component.NewIDWithName(metadata.Type, "custom"),
+			id: component.NewIDWithName(testType, "custom"),
 			expected: &Config{
 				GroupByAttributes:           []string{"db.operation", "db.name"},
 				MinSpansToAggregate:         3,
@@ -82,7 +87,7 @@ func TestLoadConfig(t *testing.T) {
 				AggregationAttributePrefix:  "batch.",
 				AggregationHistogramBuckets: customHistogramBuckets,
 				EnableAttributeLossAnalysis: false,
-				// Default from createDefaultConfig should persist when omitted in YAML.
+				// Default from NewDefaultConfig should persist when omitted in YAML.
 				AttributeLossExemplarSampleRate: 0,
 				EnableOutlierAnalysis:           false,
 				OutlierAnalysis: OutlierAnalysisConfig{
@@ -98,8 +103,22 @@ func TestLoadConfig(t *testing.T) {
 					PreserveOnlyWithCorrelation:    false,
 					MinOutlierThresholdPercent:     0.1,
 				},
+				Buffer: defaultBufferConfig,
 			},
 		},
+		{
+			id: component.NewIDWithName(testType, "buffer"),
+			expected: func() *Config {
+				cfg := NewDefaultConfig()
+				cfg.Buffer = BufferConfig{
+					Enabled:      true,
+					WaitDuration: 30 * time.Second,
+					MaxTraces:    500,
+					MaxSpans:     1_000_000,
+				}
+				return cfg
+			}(),
+		},
 	}
 
 	for _, tt := range tests {
@@ -107,7 +126,7 @@ func TestLoadConfig(t *testing.T) {
 			cm, err := confmaptest.LoadConf(filepath.Join("testdata", "config.yaml"))
 			require.NoError(t, err)
 
-			factory := NewFactory()
+			factory := newTestFactory()
 			cfg := factory.CreateDefaultConfig()
 
 			sub, err := cm.Sub(tt.id.String())
@@ -601,3 +620,40 @@ func TestConfig_Validate(t *testing.T) {
 		})
 	}
 }
+
+func TestBufferConfigValidate(t *testing.T) {
+	valid := BufferConfig{Enabled: true, WaitDuration: time.Second, MaxTraces: 1, MaxSpans: 1}
+	assert.NoError(t, valid.Validate())
+
+	// Bounds are not checked unless buffering is enabled.
+	assert.NoError(t, (&BufferConfig{}).Validate())
+
+	tests := []struct {
+		name   string
+		modify func(*BufferConfig)
+		err    string
+	}{
+		{
+			name:   "zero wait duration",
+			modify: func(cfg *BufferConfig) { cfg.WaitDuration = 0 },
+			err:    "buffer.wait_duration must be positive",
+		},
+		{
+			name:   "zero max traces",
+			modify: func(cfg *BufferConfig) { cfg.MaxTraces = 0 },
+			err:    "buffer.max_traces must be at least 1",
+		},
+		{
+			name:   "zero max spans",
+			modify: func(cfg *BufferConfig) { cfg.MaxSpans = 0 },
+			err:    "buffer.max_spans must be at least 1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := valid
+			tt.modify(&cfg)
+			assert.EqualError(t, cfg.Validate(), tt.err)
+		})
+	}
+}
@@ -0,0 +1,10 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:generate make mdatagen
+
+// Package spanpruning implements the span pruning shared by the span pruning
+// processor and the span pruning connector: the aggregation of similar leaf
+// spans into summary spans, the buffering of traces across batches and the
+// metrics derived from the aggregation groups.
+package spanpruning // import "github.com/open-telemetry/opentelemetry-collector-contrib/internal/spanpruning"
@@ -0,0 +1,382 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package spanpruning // import "github.com/open-telemetry/opentelemetry-collector-contrib/internal/spanpruning"
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/coreinternal/traceutil"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/pdatautil"
+)
+
+const (
+	spanNameKey          = "span.name"
+	spanKindKey          = "span.kind"
+	statusCodeKey        = "status.code"
+	lostAttributeKey     = "attribute.key"
+	lostAttributeLossKey = "attribute.loss"
+
+	attributeLossDiverse = "diverse"
+	attributeLossMissing = "missing"
+
+	// maxExemplarsPerDataPoint bounds the exemplars of a duration data point,
+	// as the preserved outliers of many traces can merge into it.
+	maxExemplarsPerDataPoint = 10
+
+	// maxTrackedStreams bounds the streams whose last emit time is tracked.
+	maxTrackedStreams = 10000
+)
+
+// streamKey identifies the data points of a group identity, i.e. a stream,
+// by the hashes of their resource and attributes.
+type streamKey struct {
+	resource   [16]byte
+	attributes [16]byte
+}
+
+// streamIntervals tracks the time the data points of every stream were last
+// emitted, so that the delta data points of a stream cover contiguous,
+// non-overlapping intervals across batches.
+type streamIntervals struct {
+	mu        sync.Mutex
+	start     pcommon.Timestamp
+	evicted   pcommon.Timestamp // the latest last emit time of the evicted streams
+	lastEmits *lru.Cache[streamKey, pcommon.Timestamp]
+}
+
+func newStreamIntervals(start pcommon.Timestamp) *streamIntervals {
+	s := &streamIntervals{start: start}
+	// The size is a positive constant, so creating the cache cannot fail.
+	s.lastEmits, _ = lru.NewWithEvict(maxTrackedStreams, func(_ streamKey, lastEmit pcommon.Timestamp) {
+		s.evicted = max(s.evicted, lastEmit)
+	})
+	return s
+}
+
+// next returns the start of the interval of the next data point of the stream
+// identified by key, which ends at now. A stream seen for the first time
+// starts when the connector was created, or when the last stream was evicted
+// so that an evicted stream seen again does not overlap its past intervals.
+// The caller must hold s.mu.
+func (s *streamIntervals) next(key streamKey, now pcommon.Timestamp) pcommon.Timestamp {
+	start, ok := s.lastEmits.Get(key)
+	if !ok {
+		start = max(s.start, s.evicted)
+	}
+	s.lastEmits.Add(key, now)
+	return start
+}
+
+// GroupMetrics holds the state of the metrics derived from the aggregation
+// groups of the traces pruned by a connector, across batches.
+type GroupMetrics struct {
+	config    GroupMetricsConfig
+	scopeName string
+	streams   *streamIntervals
+}
+
+// NewGroupMetrics creates the state of the metrics derived from the
+// aggregation groups, emitted in a scope named scopeName. The first data
+// point of every stream starts now.
+func NewGroupMetrics(cfg GroupMetricsConfig, scopeName string) *GroupMetrics {
+	return &GroupMetrics{
+		config:    cfg,
+		scopeName: scopeName,
+		streams:   newStreamIntervals(pcommon.NewTimestampFromTime(time.Now())),
+	}
+}
+
+// groupMetricsBuilder accumulates metrics about the aggregation groups of a
+// batch of traces. Groups with the same resource and identity (span name,
+// kind, status and group_by_attributes values), e.g. the same N+1 query
+// repeated across traces, are merged into a single data point.
+type groupMetricsBuilder struct {
+	metrics       *GroupMetrics
+	buckets       []time.Duration
+	patterns      []attributePattern
+	resources     []*resourceGroupMetrics
+	resourceIndex map[[16]byte]*resourceGroupMetrics
+}
+
+// resourceGroupMetrics holds the group data points of a resource, in the
+// order their groups were first recorded.
+type resourceGroupMetrics struct {
+	key        [16]byte
+	resource   pcommon.Resource
+	points     []*groupDataPoint
+	pointIndex map[[16]byte]*groupDataPoint
+}
+
+// groupDataPoint accumulates the statistics of the groups sharing an identity.
+type groupDataPoint struct {
+	key               [16]byte
+	attributes        pcommon.Map
+	groups            int64
+	aggregatedSpans   int64
+	preservedOutliers int64
+	durationCount     uint64
+	durationSum       float64
+	durationMin       float64
+	durationMax       float64
+	bucketCounts      []uint64
+	start             pcommon.Timestamp // set when the data point is built
+	end               pcommon.Timestamp // set when the data point is built
+	exemplars         pmetric.ExemplarSlice
+	attributeLoss     []*attributeLossPoint
+}
+
+// attributeLossPoint accumulates the values lost for an attribute key.
+type attributeLossPoint struct {
+	key    string
+	loss   string
+	values int64
+}
+
+func newGroupMetricsBuilder(metrics *GroupMetrics, buckets []time.Duration, patterns []attributePattern) *groupMetricsBuilder {
+	return &groupMetricsBuilder{
+		metrics:       metrics,
+		buckets:       buckets,
+		patterns:      patterns,
+		resourceIndex: make(map[[16]byte]*resourceGroupMetrics),
+	}
+}
+
+// recordAggregation adds an aggregation group to the data point matching its
+// resource and identity. Preserved outliers are part of the group duration
+// distribution and become its exemplars.
+func (b *groupMetricsBuilder) recordAggregation(group *aggregationGroup) {
+	template := group.templateNode
+	point := b.resourceMetrics(template.resourceSpans.Resource()).dataPoint(b.groupAttributes(group), len(b.buckets))
+
+	point.groups++
+	point.aggregatedSpans += int64(len(group.nodes))
+	for _, node := range group.nodes {
+		b.recordDuration(point, node.span)
+	}
+	for _, outlier := range group.preservedOutliers {
+		point.preservedOutliers++
+		duration := b.recordDuration(point, outlier.span)
+		if b.metrics.config.Exemplars && point.exemplars.Len() < maxExemplarsPerDataPoint {
+			addOutlierExemplar(point.exemplars, outlier.span, duration, group.outlierAnalysis)
+		}
+	}
+
+	for _, attr := range group.lossInfo.diverse {
+		point.recordAttributeLoss(attr, attributeLossDiverse)
+	}
+	for _, attr := range group.lossInfo.missing {
+		point.recordAttributeLoss(attr, attributeLossMissing)
+	}
+}
+
+func (b *groupMetricsBuilder) resourceMetrics(resource pcommon.Resource) *resourceGroupMetrics {
+	key := pdatautil.MapHash(resource.Attributes())
+	if rm, ok := b.resourceIndex[key]; ok {
+		return rm
+	}
+	rm := &resourceGroupMetrics{
+		key:        key,
+		resource:   resource,
+		pointIndex: make(map[[16]byte]*groupDataPoint),
+	}
+	b.resourceIndex[key] = rm
+	b.resources = append(b.resources, rm)
+	return rm
+}
+
+// groupAttributes returns the data point attributes identifying a group. The
+// group_by_attributes values only identify leaf groups, as parent groups
+// are formed regardless of their attributes.
+func (b *groupMetricsBuilder) groupAttributes(group *aggregationGroup) pcommon.Map {
+	span := group.templateNode.span
+	attrs := pcommon.NewMap()
+	attrs.PutStr(spanNameKey, span.Name())
+	attrs.PutStr(spanKindKey, traceutil.SpanKindStr(span.Kind()))
+	attrs.PutStr(statusCodeKey, traceutil.StatusCodeStr(span.Status().Code()))
+	if group.depth > 0 {
+		return attrs
+	}
+	span.Attributes().Range(func(key string, value pcommon.Value) bool {
+		for _, pattern := range b.patterns {
+			if pattern.glob.Match(key) {
+				value.CopyTo(attrs.PutEmpty(key))
+				break
+			}
+		}
+		return true
+	})
+	return attrs
+}
+
+func (rm *resourceGroupMetrics) dataPoint(attrs pcommon.Map, buckets int) *groupDataPoint {
+	key := pdatautil.MapHash(attrs)
+	if point, ok := rm.pointIndex[key]; ok {
+		return point
+	}
+	point := &groupDataPoint{
+		key:          key,
+		attributes:   attrs,
+		bucketCounts: make([]uint64, buckets+1),
+		exemplars:    pmetric.NewExemplarSlice(),
+	}
+	rm.pointIndex[key] = point
+	rm.points = append(rm.points, point)
+	return point
+}
+
+// recordDuration adds the duration of span to the distribution of point and
+// returns it in seconds.
+func (b *groupMetricsBuilder) recordDuration(point *groupDataPoint, span ptrace.Span) float64 {
+	duration := time.Duration(span.EndTimestamp() - span.StartTimestamp())
+	seconds := duration.Seconds()
+
+	if point.durationCount == 0 || seconds < point.durationMin {
+		point.durationMin = seconds
+	}
+	if point.durationCount == 0 || seconds > point.durationMax {
+		point.durationMax = seconds
+	}
+	point.durationCount++
+	point.durationSum += seconds
+
+	// Buckets are upper-inclusive, as the summary span histograms.
+	buckets := b.buckets
+	point.bucketCounts[sort.Search(len(buckets), func(i int) bool { return duration <= buckets[i] })]++
+	return seconds
+}
+
+func (point *groupDataPoint) recordAttributeLoss(attr attributeCardinality, loss string) {
+	for _, lossPoint := range point.attributeLoss {
+		if lossPoint.key == attr.key && lossPoint.loss == loss {
+			lossPoint.values += int64(attr.uniqueValues)
+			return
+		}
+	}
+	point.attributeLoss = append(point.attributeLoss, &attributeLossPoint{
+		key:    attr.key,
+		loss:   loss,
+		values: int64(attr.uniqueValues),
+	})
+}
+
+// addOutlierExemplar adds an exemplar referencing a preserved outlier span.
+// The attribute values the outlier analysis correlated with the outliers, and
+// that this span has, are set as the exemplar filtered attributes.
+func addOutlierExemplar(exemplars pmetric.ExemplarSlice, span ptrace.Span, seconds float64, analysis *outlierAnalysisResult) {
+	exemplar := exemplars.AppendEmpty()
+	exemplar.SetTraceID(span.TraceID())
+	exemplar.SetSpanID(span.SpanID())
+	exemplar.SetTimestamp(span.EndTimestamp())
+	exemplar.SetDoubleValue(seconds)
+	if analysis == nil {
+		return
+	}
+	for _, correlation := range analysis.correlations {
+		if value, ok := span.Attributes().Get(correlation.key); ok && value.AsString() == correlation.value {
+			exemplar.FilteredAttributes().PutStr(correlation.key, correlation.value)
+		}
+	}
+}
+
+// build returns the accumulated metrics, with delta temporality. Each data
+// point covers the interval since the previous data point of its stream was
+// emitted, up to now.
+func (b *groupMetricsBuilder) build(now pcommon.Timestamp) pmetric.Metrics {
+	streams := b.metrics.streams
+	streams.mu.Lock()
+	defer streams.mu.Unlock()
+
+	md := pmetric.NewMetrics()
+	namespace := b.metrics.config.Namespace
+	bounds := make([]float64, len(b.buckets))
+	for i, bucket := range b.buckets {
+		bounds[i] = bucket.Seconds()
+	}
+
+	for _, rm := range b.resources {
+		resourceMetrics := md.ResourceMetrics().AppendEmpty()
+		rm.resource.CopyTo(resourceMetrics.Resource())
+		scopeMetrics := resourceMetrics.ScopeMetrics().AppendEmpty()
+		scopeMetrics.Scope().SetName(b.metrics.scopeName)
+		metrics := scopeMetrics.Metrics()
+
+		duration := metrics.AppendEmpty()
+		duration.SetName(namespace + ".group.duration")
+		duration.SetDescription("Duration of the spans of the aggregation groups, including the preserved outliers")
+		duration.SetUnit("s")
+		histogram := duration.SetEmptyHistogram()
+		histogram.SetAggregationTemporality(pmetric.AggregationTemporalityDelta)
+
+		groups := newDeltaSum(metrics, namespace+".group.count", "Aggregation groups, each replaced by a summary span", "{groups}")
+		spans := newDeltaSum(metrics, namespace+".group.spans", "Spans aggregated into summary spans", "{spans}")
+		var outliers, attributeLoss pmetric.Sum
+
+		for _, point := range rm.points {
+			point.start = streams.next(streamKey{resource: rm.key, attributes: point.key}, now)
+			point.end = now
+
+			dp := histogram.DataPoints().AppendEmpty()
+			point.setCommon(dp.Attributes(), dp.SetStartTimestamp, dp.SetTimestamp)
+			dp.SetCount(point.durationCount)
+			dp.SetSum(point.durationSum)
+			dp.SetMin(point.durationMin)
+			dp.SetMax(point.durationMax)
+			dp.ExplicitBounds().FromRaw(bounds)
+			dp.BucketCounts().FromRaw(point.bucketCounts)
+			point.exemplars.MoveAndAppendTo(dp.Exemplars())
+
+			point.appendSumDataPoint(groups, point.groups)
+			point.appendSumDataPoint(spans, point.aggregatedSpans)
+
+			if point.preservedOutliers > 0 {
+				if outliers == (pmetric.Sum{}) {
+					outliers = newDeltaSum(metrics, namespace+".group.outliers.preserved", "Outlier spans preserved instead of being aggregated", "{spans}")
+				}
+				point.appendSumDataPoint(outliers, point.preservedOutliers)
+			}
+
+			for _, lossPoint := range point.attributeLoss {
+				if attributeLoss == (pmetric.Sum{}) {
+					attributeLoss = newDeltaSum(metrics, namespace+".group.attribute_loss", "Attribute values lost by aggregating spans, by attribute key and loss type (diverse or missing)", "{values}")
+				}
+				dp := attributeLoss.DataPoints().AppendEmpty()
+				point.setCommon(dp.Attributes(), dp.SetStartTimestamp, dp.SetTimestamp)
+				dp.Attributes().PutStr(lostAttributeKey, lossPoint.key)
+				dp.Attributes().PutStr(lostAttributeLossKey, lossPoint.loss)
+				dp.SetIntValue(lossPoint.values)
+			}
+		}
+	}
+	return md
+}
+
+func newDeltaSum(metrics pmetric.MetricSlice, name, description, unit string) pmetric.Sum {
+	metric := metrics.AppendEmpty()
+	metric.SetName(name)
+	metric.SetDescription(description)
+	metric.SetUnit(unit)
+	sum := metric.SetEmptySum()
+	sum.SetIsMonotonic(true)
+	sum.SetAggregationTemporality(pmetric.AggregationTemporalityDelta)
+	return sum
+}
+
+func (point *groupDataPoint) setCommon(attrs pcommon.Map, setStart, setTimestamp func(pcommon.Timestamp)) {
+	point.attributes.CopyTo(attrs)
+	setStart(point.start)
+	setTimestamp(point.end)
+}
+
+func (point *groupDataPoint) appendSumDataPoint(sum pmetric.Sum, value int64) {
+	dp := sum.DataPoints().AppendEmpty()
+	point.setCommon(dp.Attributes(), dp.SetStartTimestamp, dp.SetTimestamp)
+	dp.SetIntValue(value)
+}
@@ -1,7 +1,7 @@
 // Copyright The OpenTelemetry Authors
 // SPDX-License-Identifier: Apache-2.0
 
-package spanpruningprocessor // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/spanpruningprocessor"
+package spanpruning // import "github.com/open-telemetry/opentelemetry-collector-contrib/internal/spanpruning"
 
 import (
 	"encoding/base64"
@@ -24,7 +24,7 @@ var builderPool = sync.Pool{
 // buildGroupKey assembles the grouping key for a span using its name,
 // status, and configured attribute matches. A pooled builder minimizes
 // allocations in this frequently executed path.
-func (p *spanPruningProcessor) buildGroupKey(span ptrace.Span) string {
+func (p *Pruner) buildGroupKey(span ptrace.Span) string {
 	builder := builderPool.Get().(*strings.Builder)
 	builder.Reset()
 	defer builderPool.Put(builder)
@@ -137,7 +137,7 @@ func writeAttributeSliceKey(builder *strings.Builder, value pcommon.Slice) {
 // caller passes the node's tree depth so same-named ancestors at different
 // depths are never grouped together (which would anchor the summary, and any
 // reparented spans, at a non-deterministic depth).
-func (*spanPruningProcessor) buildParentGroupKey(span ptrace.Span, depth int) string {
+func (*Pruner) buildParentGroupKey(span ptrace.Span, depth int) string {
 	builder := builderPool.Get().(*strings.Builder)
 	builder.Reset()
 	defer builderPool.Put(builder)
@@ -158,7 +158,7 @@ func (*spanPruningProcessor) buildParentGroupKey(span ptrace.Span, depth int) st
 // buildLeafGroupKey derives a leaf grouping key that includes the parent's
 // span name (if present) plus the standard grouping key, caching results per
 // node to avoid recomputation.
-func (p *spanPruningProcessor) buildLeafGroupKey(node *spanNode) string {
+func (p *Pruner) buildLeafGroupKey(node *spanNode) string {
 	// Use cached group key if available
 	if node.groupKey != "" {
 		return node.groupKey
@@ -190,7 +190,7 @@ func (p *spanPruningProcessor) buildLeafGroupKey(node *spanNode) string {
 
 // groupLeafNodesByKey groups leaf nodes by their derived key so that spans
 // with identical grouping characteristics can be aggregated together.
-func (p *spanPruningProcessor) groupLeafNodesByKey(leafNodes []*spanNode) map[string][]*spanNode {
+func (p *Pruner) groupLeafNodesByKey(leafNodes []*spanNode) map[string][]*spanNode {
 	// Pre-size map based on expected number of groups (assume ~1/4 unique groups)
 	groups := make(map[string][]*spanNode, len(leafNodes)/4+1)
 	for _, node := range leafNodes {
@@ -12,11 +12,11 @@ import (
 )
 
 func Meter(settings component.TelemetrySettings) metric.Meter {
-	return settings.MeterProvider.Meter("github.com/open-telemetry/opentelemetry-collector-contrib/processor/spanpruningprocessor")
+	return settings.MeterProvider.Meter("github.com/open-telemetry/opentelemetry-collector-contrib/internal/spanpruning")
 }
 
 func Tracer(settings component.TelemetrySettings) trace.Tracer {
-	return settings.TracerProvider.Tracer("github.com/open-telemetry/opentelemetry-collector-contrib/processor/spanpruningprocessor")
+	return settings.TracerProvider.Tracer("github.com/open-telemetry/opentelemetry-collector-contrib/internal/spanpruning")
 }
 
 // TelemetryBuilder provides an interface for components to report telemetry
@@ -27,6 +27,10 @@ type TelemetryBuilder struct {
 	registrations                                    []metric.Registration
 	ProcessorSpanpruningAggregationGroupSize         metric.Int64Histogram
 	ProcessorSpanpruningAggregationsCreated          metric.Int64Counter
+	ProcessorSpanpruningBufferSpans                  metric.Int64UpDownCounter
+	ProcessorSpanpruningBufferTraces                 metric.Int64UpDownCounter
+	ProcessorSpanpruningBufferTracesEvicted          metric.Int64Counter
+	ProcessorSpanpruningBufferTracesReleased         metric.Int64Counter
 	ProcessorSpanpruningBytesEmitted                 metric.Int64Counter
 	ProcessorSpanpruningBytesProcessedInput          metric.Int64Counter
 	ProcessorSpanpruningBytesProcessedOutput         metric.Int64Counter
@@ -86,6 +90,30 @@ func NewTelemetryBuilder(settings component.TelemetrySettings, options ...Teleme
 		metric.WithUnit("{spans}"),
 	)
 	errs = errors.Join(errs, err)
+	builder.ProcessorSpanpruningBufferSpans, err = builder.meter.Int64UpDownCounter(
+		"otelcol_processor_spanpruning_buffer_spans",
+		metric.WithDescription("Spans currently held by the buffer [Development]"),
+		metric.WithUnit("{spans}"),
+	)
+	errs = errors.Join(errs, err)
+	builder.ProcessorSpanpruningBufferTraces, err = builder.meter.Int64UpDownCounter(
+		"otelcol_processor_spanpruning_buffer_traces",
+		metric.WithDescription("Traces currently held by the buffer [Development]"),
+		metric.WithUnit("{traces}"),
+	)
+	errs = errors.Join(errs, err)
+	builder.ProcessorSpanpruningBufferTracesEvicted, err = builder.meter.Int64Counter(
+		"otelcol_processor_spanpruning_buffer_traces_evicted",
+		metric.WithDescription("Traces released before the buffer wait duration elapsed, to keep the buffer within its bounds [Development]"),
+		metric.WithUnit("{traces}"),
+	)
+	errs = errors.Join(errs, err)
+	builder.ProcessorSpanpruningBufferTracesReleased, err = builder.meter.Int64Counter(
+		"otelcol_processor_spanpruning_buffer_traces_released",
+		metric.WithDescription("Traces released after the buffer wait duration elapsed [Development]"),
+		metric.WithUnit("{traces}"),
+	)
+	errs = errors.Join(errs, err)
 	builder.ProcessorSpanpruningBytesEmitted, err = builder.meter.Int64Counter(
 		"otelcol_processor_spanpruning_bytes_emitted",
 		metric.WithDescription("Total bytes of serialized traces emitted after pruning [Development]"),
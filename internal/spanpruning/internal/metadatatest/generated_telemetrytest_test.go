@@ -11,7 +11,7 @@ import (
 	"go.opentelemetry.io/otel/sdk/metric/metricdata"
 	"go.opentelemetry.io/otel/sdk/metric/metricdata/metricdatatest"
 
-	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/spanpruningprocessor/internal/metadata"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/spanpruning/internal/metadata"
 )
 
 func TestSetupTelemetry(t *testing.T) {
@@ -21,6 +21,10 @@ func TestSetupTelemetry(t *testing.T) {
 	defer tb.Shutdown()
 	tb.ProcessorSpanpruningAggregationGroupSize.Record(context.Background(), 1)
 	tb.ProcessorSpanpruningAggregationsCreated.Add(context.Background(), 1)
+	tb.ProcessorSpanpruningBufferSpans.Add(context.Background(), 1)
+	tb.ProcessorSpanpruningBufferTraces.Add(context.Background(), 1)
+	tb.ProcessorSpanpruningBufferTracesEvicted.Add(context.Background(), 1)
+	tb.ProcessorSpanpruningBufferTracesReleased.Add(context.Background(), 1)
 	tb.ProcessorSpanpruningBytesEmitted.Add(context.Background(), 1)
 	tb.ProcessorSpanpruningBytesProcessedInput.Add(context.Background(), 1)
 	tb.ProcessorSpanpruningBytesProcessedOutput.Add(context.Background(), 1)
@@ -43,6 +47,18 @@ func TestSetupTelemetry(t *testing.T) {
 	AssertEqualProcessorSpanpruningAggregationsCreated(t, testTel,
 		[]metricdata.DataPoint[int64]{{Value: 1}},
 		metricdatatest.IgnoreTimestamp())
+	AssertEqualProcessorSpanpruningBufferSpans(t, testTel,
+		[]metricdata.DataPoint[int64]{{Value: 1}},
+		metricdatatest.IgnoreTimestamp())
+	AssertEqualProcessorSpanpruningBufferTraces(t, testTel,
+		[]metricdata.DataPoint[int64]{{Value: 1}},
+		metricdatatest.IgnoreTimestamp())
+	AssertEqualProcessorSpanpruningBufferTracesEvicted(t, testTel,
+		[]metricdata.DataPoint[int64]{{Value: 1}},
+		metricdatatest.IgnoreTimestamp())
+	AssertEqualProcessorSpanpruningBufferTracesReleased(t, testTel,
+		[]metricdata.DataPoint[int64]{{Value: 1}},
+		metricdatatest.IgnoreTimestamp())
 	AssertEqualProcessorSpanpruningBytesEmitted(t, testTel,
 		[]metricdata.DataPoint[int64]{{Value: 1}},
 		metricdatatest.IgnoreTimestamp())
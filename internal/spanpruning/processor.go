@@ -1,27 +1,35 @@
 // Copyright The OpenTelemetry Authors
 // SPDX-License-Identifier: Apache-2.0
 
-package spanpruningprocessor // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/spanpruningprocessor"
+package spanpruning // import "github.com/open-telemetry/opentelemetry-collector-contrib/internal/spanpruning"
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/rand/v2"
 	"sort"
 	"time"
 
 	"github.com/gobwas/glob"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
 	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
 	"go.opentelemetry.io/collector/pdata/ptrace"
 	"go.opentelemetry.io/collector/processor"
+	"go.opentelemetry.io/collector/processor/processorhelper"
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/filter/filterottl"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/spanpruning/internal/metadata"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl/contexts/ottlspan"
-	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/spanpruningprocessor/internal/metadata"
 )
 
+var processorCapabilities = consumer.Capabilities{MutatesData: true}
+
 // spanInfo pairs a span with its owning ResourceSpans and ScopeSpans
 // containers for in-place edits, hierarchy reconstruction, and context-aware
 // evaluation.
@@ -36,9 +44,9 @@ type attributePattern struct {
 	glob glob.Glob
 }
 
-// spanPruningProcessor aggregates similar leaf spans (and eligible parents)
+// Pruner aggregates similar leaf spans (and eligible parents)
 // according to configuration while emitting telemetry about pruning actions.
-type spanPruningProcessor struct {
+type Pruner struct {
 	config                      *Config
 	logger                      *zap.Logger
 	attributePatterns           []attributePattern
@@ -48,7 +56,7 @@ type spanPruningProcessor struct {
 	enableBytesMetrics          bool
 }
 
-func newSpanPruningProcessor(set processor.Settings, cfg *Config, telemetryBuilder *metadata.TelemetryBuilder, conditions *ottl.ConditionSequence[*ottlspan.TransformContext]) (*spanPruningProcessor, error) {
+func newSpanPruningProcessor(set component.TelemetrySettings, cfg *Config, telemetryBuilder *metadata.TelemetryBuilder, conditions *ottl.ConditionSequence[*ottlspan.TransformContext]) (*Pruner, error) {
 	// Compile glob patterns for group_by_attributes
 	patterns := make([]attributePattern, 0, len(cfg.GroupByAttributes))
 	for _, pattern := range cfg.GroupByAttributes {
@@ -61,7 +69,7 @@ func newSpanPruningProcessor(set processor.Settings, cfg *Config, telemetryBuild
 		})
 	}
 
-	return &spanPruningProcessor{
+	return &Pruner{
 		config:                      cfg,
 		logger:                      set.Logger,
 		attributePatterns:           patterns,
@@ -72,15 +80,95 @@ func newSpanPruningProcessor(set processor.Settings, cfg *Config, telemetryBuild
 	}, nil
 }
 
-// shutdown releases processor resources, including telemetry providers.
-func (p *spanPruningProcessor) shutdown(_ context.Context) error {
+// NewPruner compiles the OTTL conditions of cfg and creates the span pruning
+// logic shared by the processor and the connector.
+func NewPruner(set component.TelemetrySettings, cfg *Config) (*Pruner, error) {
+	telemetryBuilder, err := metadata.NewTelemetryBuilder(set)
+	if err != nil {
+		return nil, err
+	}
+
+	// Compile OTTL conditions if configured.
+	var conditions *ottl.ConditionSequence[*ottlspan.TransformContext]
+	if len(cfg.Conditions) > 0 {
+		conditions, err = filterottl.NewBoolExprForSpan(
+			cfg.Conditions,
+			filterottl.StandardSpanFuncs(),
+			ottl.PropagateError,
+			set,
+		)
+		if err != nil {
+			return nil, err
+		}
+		set.Logger.Info("OTTL conditions configured", zap.Int("count", len(cfg.Conditions)))
+	}
+
+	return newSpanPruningProcessor(set, cfg, telemetryBuilder, conditions)
+}
+
+// NewTracesProcessor creates a traces processor pruning the traces with the
+// span pruning logic, buffering them first when the buffered mode is enabled.
+func NewTracesProcessor(
+	ctx context.Context,
+	set processor.Settings,
+	cfg *Config,
+	nextConsumer consumer.Traces,
+) (processor.Traces, error) {
+	p, err := NewPruner(set.TelemetrySettings, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if !cfg.Buffer.Enabled {
+		return processorhelper.NewTraces(
+			ctx,
+			set,
+			cfg,
+			nextConsumer,
+			p.processTraces,
+			processorhelper.WithCapabilities(processorCapabilities),
+			processorhelper.WithShutdown(p.Shutdown),
+		)
+	}
+
+	buffer := p.NewBuffer(set.Logger, func(ctx context.Context, td ptrace.Traces) error {
+		td, err := p.processTraces(ctx, td)
+		if err != nil {
+			return err
+		}
+		return nextConsumer.ConsumeTraces(ctx, td)
+	})
+	return processorhelper.NewTraces(
+		ctx,
+		set,
+		cfg,
+		nextConsumer,
+		buffer.processTraces,
+		processorhelper.WithCapabilities(processorCapabilities),
+		processorhelper.WithStart(buffer.Start),
+		processorhelper.WithShutdown(func(ctx context.Context) error {
+			// Release the buffered traces before shutting down the telemetry.
+			return errors.Join(buffer.Shutdown(ctx), p.Shutdown(ctx))
+		}),
+	)
+}
+
+// NewBuffer creates a buffer holding the spans of each trace as configured by
+// the buffer options of the pruner, and passing the traces it releases to
+// release.
+func (p *Pruner) NewBuffer(logger *zap.Logger, release func(ctx context.Context, td ptrace.Traces) error) *TraceBuffer {
+	return newTraceBuffer(p.config.Buffer, logger, p.telemetryBuilder, release)
+}
+
+// Shutdown releases processor resources, including telemetry providers.
+func (p *Pruner) Shutdown(_ context.Context) error {
 	p.telemetryBuilder.Shutdown()
 	return nil
 }
 
 // shouldSampleAttributeLossExemplar decides whether to attach exemplars to
 // attribute-loss metrics based on the configured sampling rate.
-func (p *spanPruningProcessor) shouldSampleAttributeLossExemplar() bool {
+func (p *Pruner) shouldSampleAttributeLossExemplar() bool {
 	rate := p.config.AttributeLossExemplarSampleRate
 	if rate <= 0 {
 		return false
@@ -103,7 +191,35 @@ func createExemplarContext(ctx context.Context, traceID pcommon.TraceID, spanID
 
 // processTraces runs aggregation for each trace batch and records processor
 // telemetry about received, pruned, and aggregated spans.
-func (p *spanPruningProcessor) processTraces(ctx context.Context, td ptrace.Traces) (ptrace.Traces, error) {
+func (p *Pruner) processTraces(ctx context.Context, td ptrace.Traces) (ptrace.Traces, error) {
+	return p.pruneTraces(ctx, td, nil)
+}
+
+// Prune prunes td like the processor. When metrics is not nil, it also
+// returns the metrics of the aggregation groups of td, built before td is
+// returned as they copy the resources and attributes of its spans.
+func (p *Pruner) Prune(ctx context.Context, td ptrace.Traces, metrics *GroupMetrics) (ptrace.Traces, pmetric.Metrics, error) {
+	if metrics == nil {
+		td, err := p.processTraces(ctx, td)
+		return td, pmetric.NewMetrics(), err
+	}
+	builder := newGroupMetricsBuilder(metrics, p.config.AggregationHistogramBuckets, p.attributePatterns)
+	td, err := p.pruneTraces(ctx, td, builder)
+	if err != nil {
+		return td, pmetric.NewMetrics(), err
+	}
+	return td, builder.build(pcommon.NewTimestampFromTime(time.Now())), nil
+}
+
+// aggregationRecorder observes the aggregation groups of each pruned trace
+// before they are executed, e.g. to derive metrics from them.
+type aggregationRecorder interface {
+	recordAggregation(group *aggregationGroup)
+}
+
+// pruneTraces prunes td like processTraces, passing every aggregation group
+// to recorder when it is not nil.
+func (p *Pruner) pruneTraces(ctx context.Context, td ptrace.Traces, recorder aggregationRecorder) (ptrace.Traces, error) {
 	start := time.Now()
 
 	// Measure bytes received before processing
@@ -135,7 +251,7 @@ func (p *spanPruningProcessor) processTraces(ctx context.Context, td ptrace.Trac
 	// Process each trace independently
 	tracesProcessed := int64(0)
 	for traceID := range matchedTraces {
-		p.processTrace(ctx, traceSpans[traceID])
+		p.processTrace(ctx, traceSpans[traceID], recorder)
 		tracesProcessed++
 	}
 
@@ -173,7 +289,7 @@ func (p *spanPruningProcessor) processTraces(ctx context.Context, td ptrace.Trac
 // filterTracesByConditions evaluates conditions against every trace in traceSpans and
 // returns the set of matching TraceIDs alongside a count of skipped traces.
 // When no conditions are configured, every trace matches.
-func (p *spanPruningProcessor) filterTracesByConditions(ctx context.Context, traceSpans map[pcommon.TraceID][]spanInfo) (map[pcommon.TraceID]struct{}, int64) {
+func (p *Pruner) filterTracesByConditions(ctx context.Context, traceSpans map[pcommon.TraceID][]spanInfo) (map[pcommon.TraceID]struct{}, int64) {
 	matched := make(map[pcommon.TraceID]struct{})
 	var skipped int64
 	for traceID, spans := range traceSpans {
@@ -188,7 +304,7 @@ func (p *spanPruningProcessor) filterTracesByConditions(ctx context.Context, tra
 
 // getBytes returns the serialized size of the subset of traces identified
 // by matchedTraces, preserving the original ResourceSpans/ScopeSpans hierarchy.
-func (*spanPruningProcessor) getBytes(matchedTraces map[pcommon.TraceID]struct{}, traceSpans map[pcommon.TraceID][]spanInfo) int64 {
+func (*Pruner) getBytes(matchedTraces map[pcommon.TraceID]struct{}, traceSpans map[pcommon.TraceID][]spanInfo) int64 {
 	filtered := ptrace.NewTraces()
 	// Track already-added ResourceSpans and ScopeSpans by their original object
 	// identity to preserve the original hierarchy (same RS/SS grouping).
@@ -221,7 +337,7 @@ func (*spanPruningProcessor) getBytes(matchedTraces map[pcommon.TraceID]struct{}
 
 // groupSpansByTraceID flattens incoming data into a TraceID-indexed map so
 // each trace can be analyzed independently.
-func (*spanPruningProcessor) groupSpansByTraceID(td ptrace.Traces) map[pcommon.TraceID][]spanInfo {
+func (*Pruner) groupSpansByTraceID(td ptrace.Traces) map[pcommon.TraceID][]spanInfo {
 	traceSpans := make(map[pcommon.TraceID][]spanInfo)
 
 	rss := td.ResourceSpans()
@@ -249,7 +365,7 @@ func (*spanPruningProcessor) groupSpansByTraceID(td ptrace.Traces) map[pcommon.T
 // traceMatchesConditions evaluates whether any span in the trace matches the configured
 // OTTL conditions. Returns true when no conditions are configured (prune all traces).
 // When conditions are set, returns true if at least one span matches any condition.
-func (p *spanPruningProcessor) traceMatchesConditions(ctx context.Context, spans []spanInfo) bool {
+func (p *Pruner) traceMatchesConditions(ctx context.Context, spans []spanInfo) bool {
 	if p.conditions == nil {
 		return true
 	}
@@ -275,8 +391,9 @@ func (p *spanPruningProcessor) traceMatchesConditions(ctx context.Context, spans
 
 // processTrace applies the pruning algorithm to a single trace:
 // 1) analyze aggregation candidates bottom-up, 2) build a top-down execution
-// plan, and 3) create summary spans while removing originals.
-func (p *spanPruningProcessor) processTrace(ctx context.Context, spans []spanInfo) {
+// plan, and 3) create summary spans while removing originals. The groups are
+// passed to recorder, when not nil, before their spans are removed.
+func (p *Pruner) processTrace(ctx context.Context, spans []spanInfo, recorder aggregationRecorder) {
 	// Build trace tree
 	tree := p.buildTraceTree(spans)
 	if len(tree.nodeByID) == 0 {
@@ -291,6 +408,11 @@ func (p *spanPruningProcessor) processTrace(ctx context.Context, spans []spanInf
 
 	// Phase 2: Build aggregation plan (order top-down)
 	plan := p.buildAggregationPlan(aggregationGroups)
+	if recorder != nil {
+		for i := range plan.groups {
+			recorder.recordAggregation(&plan.groups[i])
+		}
+	}
 
 	// Phase 3: Execute aggregations (top-down) and record pruned spans
 	prunedCount := p.executeAggregations(plan, tree)
@@ -306,7 +428,7 @@ func (p *spanPruningProcessor) processTrace(ctx context.Context, spans []spanInf
 // analyzeAggregationsWithTree plans the candidate aggregation groups, then
 // detects and protects outliers within them, then aggregates the non-protected
 // spans. Planning runs once and feeds both detection and execution.
-func (p *spanPruningProcessor) analyzeAggregationsWithTree(ctx context.Context, tree *traceTree) map[string]aggregationGroup {
+func (p *Pruner) analyzeAggregationsWithTree(ctx context.Context, tree *traceTree) map[string]aggregationGroup {
 	// Step 1: plan the groups that would aggregate.
 	groups := p.planCandidateGroups(tree)
 	if len(groups) == 0 {
@@ -369,7 +491,7 @@ func (p *spanPruningProcessor) analyzeAggregationsWithTree(ctx context.Context,
 // deterministically. Protection, or a child group that fell below
 // MinSpansToAggregate, can make a planned parent ineligible by the time it is
 // executed.
-func (p *spanPruningProcessor) eligibleParents(nodes []*spanNode) []*spanNode {
+func (p *Pruner) eligibleParents(nodes []*spanNode) []*spanNode {
 	out := make([]*spanNode, 0, len(nodes))
 	for _, n := range nodes {
 		if p.isEligibleForParentAggregation(n) {
@@ -384,7 +506,7 @@ func (p *spanPruningProcessor) eligibleParents(nodes []*spanNode) []*spanNode {
 
 // recordAttributeLoss computes attribute loss for a group and records the
 // matching leaf or parent telemetry, returning the loss summary.
-func (p *spanPruningProcessor) recordAttributeLoss(ctx context.Context, isLeaf bool, nodes []*spanNode, templateNode *spanNode) attributeLossSummary {
+func (p *Pruner) recordAttributeLoss(ctx context.Context, isLeaf bool, nodes []*spanNode, templateNode *spanNode) attributeLossSummary {
 	if !p.enableAttributeLossAnalysis {
 		return attributeLossSummary{}
 	}
@@ -411,7 +533,7 @@ func (p *spanPruningProcessor) recordAttributeLoss(ctx context.Context, isLeaf b
 // so it is kept instead of aggregated. It returns per-group detection results (to
 // annotate the matching summary) and the preserved-outlier roots grouped by group
 // key (to link them to their summary during execution).
-func (p *spanPruningProcessor) detectAndProtectOutliers(ctx context.Context, groups []candidateGroup) (map[string]*outlierAnalysisResult, map[string][]*spanNode) {
+func (p *Pruner) detectAndProtectOutliers(ctx context.Context, groups []candidateGroup) (map[string]*outlierAnalysisResult, map[string][]*spanNode) {
 	if !p.config.EnableOutlierAnalysis {
 		return nil, nil
 	}
@@ -485,7 +607,7 @@ type candidateGroup struct {
 // or above MinSpansToAggregate and eligible parent groups), ignoring outlier
 // protection. It mutates no node state, tracking would-aggregate membership in a
 // local set, so detection runs over the same groups the executor later forms.
-func (p *spanPruningProcessor) planCandidateGroups(tree *traceTree) []candidateGroup {
+func (p *Pruner) planCandidateGroups(tree *traceTree) []candidateGroup {
 	leafNodes := tree.getLeaves()
 	if len(leafNodes) == 0 {
 		return nil
@@ -555,7 +677,7 @@ func (p *spanPruningProcessor) planCandidateGroups(tree *traceTree) []candidateG
 
 // recordPreserved emits preserved-outlier telemetry: every span kept across the
 // preserved subtrees rooted at the given outlier roots, not just the roots.
-func (p *spanPruningProcessor) recordPreserved(ctx context.Context, roots []*spanNode) {
+func (p *Pruner) recordPreserved(ctx context.Context, roots []*spanNode) {
 	if len(roots) == 0 {
 		return
 	}
@@ -1,7 +1,7 @@
 // Copyright The OpenTelemetry Authors
 // SPDX-License-Identifier: Apache-2.0
 
-package spanpruningprocessor
+package spanpruning
 
 import (
 	"testing"
@@ -9,7 +9,7 @@ import (
 	"go.opentelemetry.io/collector/pdata/ptrace"
 	"go.opentelemetry.io/collector/processor/processortest"
 
-	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/spanpruningprocessor/internal/metadata"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/spanpruning/internal/metadata"
 )
 
 // BenchmarkProcessTrace_SmallTrace benchmarks processing a small trace (10 spans).
@@ -138,21 +138,21 @@ func BenchmarkExecuteAggregations(b *testing.B) {
 }
 
 // newBenchmarkProcessor creates a processor configured for benchmarking.
-func newBenchmarkProcessor(b *testing.B, maxParentDepth int) *spanPruningProcessor {
+func newBenchmarkProcessor(b *testing.B, maxParentDepth int) *Pruner {
 	b.Helper()
 
-	cfg := createDefaultConfig().(*Config)
+	cfg := NewDefaultConfig()
 	cfg.GroupByAttributes = []string{"http.*", "db.*"}
 	cfg.MinSpansToAggregate = 5
 	cfg.MaxParentDepth = maxParentDepth
 
-	set := processortest.NewNopSettings(metadata.Type)
+	set := processortest.NewNopSettings(testType)
 	telemetryBuilder, err := metadata.NewTelemetryBuilder(set.TelemetrySettings)
 	if err != nil {
 		b.Fatal(err)
 	}
 
-	proc, err := newSpanPruningProcessor(set, cfg, telemetryBuilder, nil)
+	proc, err := newSpanPruningProcessor(set.TelemetrySettings, cfg, telemetryBuilder, nil)
 	if err != nil {
 		b.Fatal(err)
 	}
@@ -181,17 +181,17 @@ func spanInfosFromTraces(td ptrace.Traces) []spanInfo {
 }
 
 func benchmarkProcessTrace(b *testing.B, numSpans, minSpans int) {
-	cfg := createDefaultConfig().(*Config)
+	cfg := NewDefaultConfig()
 	cfg.MinSpansToAggregate = minSpans
 	cfg.GroupByAttributes = []string{"http.*"}
 
-	set := processortest.NewNopSettings(metadata.Type)
+	set := processortest.NewNopSettings(testType)
 	telemetryBuilder, err := metadata.NewTelemetryBuilder(set.TelemetrySettings)
 	if err != nil {
 		b.Fatal(err)
 	}
 
-	proc, err := newSpanPruningProcessor(set, cfg, telemetryBuilder, nil)
+	proc, err := newSpanPruningProcessor(set.TelemetrySettings, cfg, telemetryBuilder, nil)
 	if err != nil {
 		b.Fatal(err)
 	}
@@ -210,18 +210,18 @@ func benchmarkProcessTrace(b *testing.B, numSpans, minSpans int) {
 }
 
 func benchmarkProcessTraceSparse(b *testing.B, numSpans, minSpans int) {
-	cfg := createDefaultConfig().(*Config)
+	cfg := NewDefaultConfig()
 	cfg.MinSpansToAggregate = minSpans
 	cfg.GroupByAttributes = []string{"db.*"}
 	cfg.MaxParentDepth = 3
 
-	set := processortest.NewNopSettings(metadata.Type)
+	set := processortest.NewNopSettings(testType)
 	telemetryBuilder, err := metadata.NewTelemetryBuilder(set.TelemetrySettings)
 	if err != nil {
 		b.Fatal(err)
 	}
 
-	proc, err := newSpanPruningProcessor(set, cfg, telemetryBuilder, nil)
+	proc, err := newSpanPruningProcessor(set.TelemetrySettings, cfg, telemetryBuilder, nil)
 	if err != nil {
 		b.Fatal(err)
 	}
@@ -240,18 +240,18 @@ func benchmarkProcessTraceSparse(b *testing.B, numSpans, minSpans int) {
 }
 
 func benchmarkDeepTrace(b *testing.B, depth, branchingFactor, leafsPerBranch, maxSpans, maxParentDepth int) {
-	cfg := createDefaultConfig().(*Config)
+	cfg := NewDefaultConfig()
 	cfg.MinSpansToAggregate = 2
 	cfg.GroupByAttributes = []string{"db.*"}
 	cfg.MaxParentDepth = maxParentDepth
 
-	set := processortest.NewNopSettings(metadata.Type)
+	set := processortest.NewNopSettings(testType)
 	telemetryBuilder, err := metadata.NewTelemetryBuilder(set.TelemetrySettings)
 	if err != nil {
 		b.Fatal(err)
 	}
 
-	proc, err := newSpanPruningProcessor(set, cfg, telemetryBuilder, nil)
+	proc, err := newSpanPruningProcessor(set.TelemetrySettings, cfg, telemetryBuilder, nil)
 	if err != nil {
 		b.Fatal(err)
 	}
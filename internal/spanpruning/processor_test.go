@@ -1,7 +1,7 @@
 // Copyright The OpenTelemetry Authors
 // SPDX-License-Identifier: Apache-2.0
 
-package spanpruningprocessor
+package spanpruning
 
 import (
 	"context"
@@ -11,10 +11,13 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer"
 	"go.opentelemetry.io/collector/consumer/consumertest"
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/processor"
 	"go.opentelemetry.io/collector/processor/processortest"
 	"go.opentelemetry.io/otel/sdk/metric/metricdata"
 	"go.opentelemetry.io/otel/sdk/metric/metricdata/metricdatatest"
@@ -23,27 +26,47 @@ import (
 	"go.uber.org/zap/zaptest/observer"
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/filter/filterottl"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/spanpruning/internal/metadata"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/spanpruning/internal/metadatatest"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl"
-	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/spanpruningprocessor/internal/metadata"
-	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/spanpruningprocessor/internal/metadatatest"
 )
 
+var testType = component.MustNewType("span_pruning")
+
+// newTestFactory returns a factory like the one of the Span Pruning
+// processor, which cannot be imported from this package.
+func newTestFactory() processor.Factory {
+	return processor.NewFactory(
+		testType,
+		func() component.Config { return NewDefaultConfig() },
+		processor.WithTraces(func(ctx context.Context, set processor.Settings, cfg component.Config, nextConsumer consumer.Traces) (processor.Traces, error) {
+			return NewTracesProcessor(ctx, set, cfg.(*Config), nextConsumer)
+		}, component.StabilityLevelAlpha),
+	)
+}
+
+func newTestSettings(tt *componenttest.Telemetry) processor.Settings {
+	set := processortest.NewNopSettings(testType)
+	set.TelemetrySettings = tt.NewTelemetrySettings()
+	return set
+}
+
 func TestNewTraces(t *testing.T) {
-	factory := NewFactory()
+	factory := newTestFactory()
 	cfg := factory.CreateDefaultConfig()
 
-	tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(metadata.Type), cfg, consumertest.NewNop())
+	tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(testType), cfg, consumertest.NewNop())
 	require.NoError(t, err)
 	require.NotNil(t, tp)
 }
 
 func TestLeafSpanPruning_BasicAggregation(t *testing.T) {
 	// Test: 3 identical leaf spans should be aggregated into 1 summary span
-	factory := NewFactory()
+	factory := newTestFactory()
 	cfg := factory.CreateDefaultConfig().(*Config)
 	cfg.MinSpansToAggregate = 2
 
-	tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(metadata.Type), cfg, consumertest.NewNop())
+	tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(testType), cfg, consumertest.NewNop())
 	require.NoError(t, err)
 
 	td := createTestTraceWithLeafSpans(t, 3, map[string]string{"db.operation": "select"})
@@ -70,11 +93,11 @@ func TestLeafSpanPruning_BasicAggregation(t *testing.T) {
 
 func TestLeafSpanPruning_BelowThreshold(t *testing.T) {
 	// Test: 1 leaf span with min_spans_to_aggregate=2 should not be aggregated
-	factory := NewFactory()
+	factory := newTestFactory()
 	cfg := factory.CreateDefaultConfig().(*Config)
 	cfg.MinSpansToAggregate = 2
 
-	tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(metadata.Type), cfg, consumertest.NewNop())
+	tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(testType), cfg, consumertest.NewNop())
 	require.NoError(t, err)
 
 	td := createTestTraceWithLeafSpans(t, 1, map[string]string{"db.operation": "select"})
@@ -91,11 +114,11 @@ func TestLeafSpanPruning_BelowThreshold(t *testing.T) {
 
 func TestLeafSpanPruning_MixedLeafAndNonLeaf(t *testing.T) {
 	// Test: only aggregate leaf spans, not spans with children
-	factory := NewFactory()
+	factory := newTestFactory()
 	cfg := factory.CreateDefaultConfig().(*Config)
 	cfg.MinSpansToAggregate = 2
 
-	tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(metadata.Type), cfg, consumertest.NewNop())
+	tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(testType), cfg, consumertest.NewNop())
 	require.NoError(t, err)
 
 	// Create trace: root -> intermediate -> 3 leaf spans
@@ -113,12 +136,12 @@ func TestLeafSpanPruning_MixedLeafAndNonLeaf(t *testing.T) {
 
 func TestLeafSpanPruning_DifferentGroups(t *testing.T) {
 	// Test: spans with different attributes should stay in separate groups
-	factory := NewFactory()
+	factory := newTestFactory()
 	cfg := factory.CreateDefaultConfig().(*Config)
 	cfg.MinSpansToAggregate = 2
 	cfg.GroupByAttributes = []string{"db.operation"}
 
-	tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(metadata.Type), cfg, consumertest.NewNop())
+	tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(testType), cfg, consumertest.NewNop())
 	require.NoError(t, err)
 
 	// Create trace with mixed operations: 3 SELECT + 2 INSERT
@@ -136,10 +159,10 @@ func TestLeafSpanPruning_DifferentGroups(t *testing.T) {
 
 func TestLeafSpanPruning_EmptyTrace(t *testing.T) {
 	// Test: empty trace should be handled gracefully
-	factory := NewFactory()
+	factory := newTestFactory()
 	cfg := factory.CreateDefaultConfig().(*Config)
 
-	tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(metadata.Type), cfg, consumertest.NewNop())
+	tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(testType), cfg, consumertest.NewNop())
 	require.NoError(t, err)
 
 	td := ptrace.NewTraces()
@@ -151,11 +174,11 @@ func TestLeafSpanPruning_EmptyTrace(t *testing.T) {
 
 func TestLeafSpanPruning_SingleSpanTrace(t *testing.T) {
 	// Test: single span trace (root only) should not be modified
-	factory := NewFactory()
+	factory := newTestFactory()
 	cfg := factory.CreateDefaultConfig().(*Config)
 	cfg.MinSpansToAggregate = 2
 
-	tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(metadata.Type), cfg, consumertest.NewNop())
+	tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(testType), cfg, consumertest.NewNop())
 	require.NoError(t, err)
 
 	td := createSingleSpanTrace(t)
@@ -172,11 +195,11 @@ func TestLeafSpanPruning_SingleSpanTrace(t *testing.T) {
 
 func TestLeafSpanPruning_StatusAggregation(t *testing.T) {
 	// Test: spans with different status codes should be in separate groups
-	factory := NewFactory()
+	factory := newTestFactory()
 	cfg := factory.CreateDefaultConfig().(*Config)
 	cfg.MinSpansToAggregate = 2
 
-	tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(metadata.Type), cfg, consumertest.NewNop())
+	tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(testType), cfg, consumertest.NewNop())
 	require.NoError(t, err)
 
 	// Create trace with 4 OK spans and 2 Error spans (same name)
@@ -205,11 +228,11 @@ func TestLeafSpanPruning_StatusAggregation(t *testing.T) {
 
 func TestLeafSpanPruning_StatusBelowThreshold(t *testing.T) {
 	// Test: 1 OK span + 1 Error span should not aggregate (each group below threshold)
-	factory := NewFactory()
+	factory := newTestFactory()
 	cfg := factory.CreateDefaultConfig().(*Config)
 	cfg.MinSpansToAggregate = 2
 
-	tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(metadata.Type), cfg, consumertest.NewNop())
+	tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(testType), cfg, consumertest.NewNop())
 	require.NoError(t, err)
 
 	td := createTestTraceWithErrorSpan(t) // Creates 1 OK + 1 Error span
@@ -226,11 +249,11 @@ func TestLeafSpanPruning_StatusBelowThreshold(t *testing.T) {
 
 func TestLeafSpanPruning_DurationStats(t *testing.T) {
 	// Test: verify duration statistics are calculated correctly
-	factory := NewFactory()
+	factory := newTestFactory()
 	cfg := factory.CreateDefaultConfig().(*Config)
 	cfg.MinSpansToAggregate = 2
 
-	tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(metadata.Type), cfg, consumertest.NewNop())
+	tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(testType), cfg, consumertest.NewNop())
 	require.NoError(t, err)
 
 	// Create spans with known durations: 100ns, 200ns, 300ns
@@ -258,7 +281,7 @@ func TestLeafSpanPruning_DurationStats(t *testing.T) {
 }
 
 func TestLeafSpanPruningProcessorWithHistogram(t *testing.T) {
-	factory := NewFactory()
+	factory := newTestFactory()
 	cfg := factory.CreateDefaultConfig().(*Config)
 	cfg.MinSpansToAggregate = 2
 	cfg.AggregationHistogramBuckets = []time.Duration{
@@ -267,7 +290,7 @@ func TestLeafSpanPruningProcessorWithHistogram(t *testing.T) {
 		100 * time.Millisecond,
 	}
 
-	tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(metadata.Type), cfg, consumertest.NewNop())
+	tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(testType), cfg, consumertest.NewNop())
 	require.NoError(t, err)
 
 	td := createTestTraceWithKnownDurations(t, []int64{
@@ -306,12 +329,12 @@ func TestLeafSpanPruningProcessorWithHistogram(t *testing.T) {
 }
 
 func TestLeafSpanPruningProcessorWithHistogramDisabled(t *testing.T) {
-	factory := NewFactory()
+	factory := newTestFactory()
 	cfg := factory.CreateDefaultConfig().(*Config)
 	cfg.MinSpansToAggregate = 2
 	cfg.AggregationHistogramBuckets = []time.Duration{}
 
-	tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(metadata.Type), cfg, consumertest.NewNop())
+	tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(testType), cfg, consumertest.NewNop())
 	require.NoError(t, err)
 
 	td := createTestTraceWithKnownDurations(t, []int64{
@@ -340,12 +363,12 @@ func TestLeafSpanPruningProcessorWithHistogramDisabled(t *testing.T) {
 }
 
 func TestLeafSpanPruning_GroupByNonStringAttributes(t *testing.T) {
-	factory := NewFactory()
+	factory := newTestFactory()
 	cfg := factory.CreateDefaultConfig().(*Config)
 	cfg.MinSpansToAggregate = 2
 	cfg.GroupByAttributes = []string{"db.retries", "db.cached"}
 
-	tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(metadata.Type), cfg, consumertest.NewNop())
+	tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(testType), cfg, consumertest.NewNop())
 	require.NoError(t, err)
 
 	td := createTestTraceWithNonStringAttributes(t)
@@ -382,11 +405,11 @@ func TestLeafSpanPruning_GroupByNonStringAttributes(t *testing.T) {
 }
 
 func TestLeafSpanPruning_TemplateEventsAndLinksPreserved(t *testing.T) {
-	factory := NewFactory()
+	factory := newTestFactory()
 	cfg := factory.CreateDefaultConfig().(*Config)
 	cfg.MinSpansToAggregate = 2
 
-	tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(metadata.Type), cfg, consumertest.NewNop())
+	tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(testType), cfg, consumertest.NewNop())
 	require.NoError(t, err)
 
 	td := createTestTraceWithTemplateEventsAndLinks(t)
@@ -416,12 +439,12 @@ func TestBytesMetrics_Enabled(t *testing.T) {
 	testTel := componenttest.NewTelemetry()
 	defer func() { require.NoError(t, testTel.Shutdown(t.Context())) }()
 
-	factory := NewFactory()
+	factory := newTestFactory()
 	cfg := factory.CreateDefaultConfig().(*Config)
 	cfg.MinSpansToAggregate = 2
 	cfg.EnableBytesMetrics = true
 
-	tp, err := factory.CreateTraces(t.Context(), metadatatest.NewSettings(testTel), cfg, consumertest.NewNop())
+	tp, err := factory.CreateTraces(t.Context(), newTestSettings(testTel), cfg, consumertest.NewNop())
 	require.NoError(t, err)
 
 	td := createTestTraceWithLeafSpans(t, 3, map[string]string{"db.operation": "select"})
@@ -451,11 +474,11 @@ func TestBytesMetrics_Disabled(t *testing.T) {
 	testTel := componenttest.NewTelemetry()
 	defer func() { require.NoError(t, testTel.Shutdown(t.Context())) }()
 
-	factory := NewFactory()
+	factory := newTestFactory()
 	cfg := factory.CreateDefaultConfig().(*Config)
 	cfg.MinSpansToAggregate = 2
 
-	tp, err := factory.CreateTraces(t.Context(), metadatatest.NewSettings(testTel), cfg, consumertest.NewNop())
+	tp, err := factory.CreateTraces(t.Context(), newTestSettings(testTel), cfg, consumertest.NewNop())
 	require.NoError(t, err)
 
 	td := createTestTraceWithLeafSpans(t, 3, map[string]string{"db.operation": "select"})
@@ -478,12 +501,12 @@ func TestBytesMetrics_MatchedEqualsReceivedWithNoConditions(t *testing.T) {
 	testTel := componenttest.NewTelemetry()
 	defer func() { require.NoError(t, testTel.Shutdown(t.Context())) }()
 
-	factory := NewFactory()
+	factory := newTestFactory()
 	cfg := factory.CreateDefaultConfig().(*Config)
 	cfg.MinSpansToAggregate = 2
 	cfg.EnableBytesMetrics = true
 
-	tp, err := factory.CreateTraces(t.Context(), metadatatest.NewSettings(testTel), cfg, consumertest.NewNop())
+	tp, err := factory.CreateTraces(t.Context(), newTestSettings(testTel), cfg, consumertest.NewNop())
 	require.NoError(t, err)
 
 	td := createTestTraceWithLeafSpans(t, 3, map[string]string{"db.operation": "select"})
@@ -504,12 +527,12 @@ func TestBytesMetrics_InputEqualsOutputWhenNotPruned(t *testing.T) {
 	testTel := componenttest.NewTelemetry()
 	defer func() { require.NoError(t, testTel.Shutdown(t.Context())) }()
 
-	factory := NewFactory()
+	factory := newTestFactory()
 	cfg := factory.CreateDefaultConfig().(*Config)
 	cfg.MinSpansToAggregate = 100 // too high to aggregate
 	cfg.EnableBytesMetrics = true
 
-	tp, err := factory.CreateTraces(t.Context(), metadatatest.NewSettings(testTel), cfg, consumertest.NewNop())
+	tp, err := factory.CreateTraces(t.Context(), newTestSettings(testTel), cfg, consumertest.NewNop())
 	require.NoError(t, err)
 
 	td := createTestTraceWithLeafSpans(t, 3, map[string]string{"db.operation": "select"})
@@ -529,12 +552,12 @@ func TestBytesMetrics_EmittedReflectsPruning(t *testing.T) {
 	testTel := componenttest.NewTelemetry()
 	defer func() { require.NoError(t, testTel.Shutdown(t.Context())) }()
 
-	factory := NewFactory()
+	factory := newTestFactory()
 	cfg := factory.CreateDefaultConfig().(*Config)
 	cfg.MinSpansToAggregate = 2
 	cfg.EnableBytesMetrics = true
 
-	tp, err := factory.CreateTraces(t.Context(), metadatatest.NewSettings(testTel), cfg, consumertest.NewNop())
+	tp, err := factory.CreateTraces(t.Context(), newTestSettings(testTel), cfg, consumertest.NewNop())
 	require.NoError(t, err)
 
 	td := createTestTraceWithLeafSpans(t, 12, map[string]string{
@@ -573,13 +596,13 @@ func TestAttributeLoss_RecordsMetricsAndSummaryAttributesWhenEnabled(t *testing.
 	tel := componenttest.NewTelemetry()
 	t.Cleanup(func() { require.NoError(t, tel.Shutdown(context.Background())) }) //nolint:usetesting
 
-	factory := NewFactory()
+	factory := newTestFactory()
 	cfg := factory.CreateDefaultConfig().(*Config)
 	cfg.MinSpansToAggregate = 2
 	cfg.EnableAttributeLossAnalysis = true
 	cfg.AttributeLossExemplarSampleRate = 0
 
-	tp, err := factory.CreateTraces(t.Context(), metadatatest.NewSettings(tel), cfg, consumertest.NewNop())
+	tp, err := factory.CreateTraces(t.Context(), newTestSettings(tel), cfg, consumertest.NewNop())
 	require.NoError(t, err)
 
 	td, _ := createTestTraceWithAttributeLoss(t)
@@ -614,13 +637,13 @@ func TestAttributeLoss_ExemplarSampling(t *testing.T) {
 	tel := componenttest.NewTelemetry()
 	t.Cleanup(func() { require.NoError(t, tel.Shutdown(context.Background())) }) //nolint:usetesting
 
-	factory := NewFactory()
+	factory := newTestFactory()
 	cfg := factory.CreateDefaultConfig().(*Config)
 	cfg.MinSpansToAggregate = 2
 	cfg.EnableAttributeLossAnalysis = true
 	cfg.AttributeLossExemplarSampleRate = 1
 
-	tp, err := factory.CreateTraces(t.Context(), metadatatest.NewSettings(tel), cfg, consumertest.NewNop())
+	tp, err := factory.CreateTraces(t.Context(), newTestSettings(tel), cfg, consumertest.NewNop())
 	require.NoError(t, err)
 
 	td, traceID := createTestTraceWithAttributeLoss(t)
@@ -637,12 +660,12 @@ func TestAttributeLoss_DisabledByDefault(t *testing.T) {
 	tel := componenttest.NewTelemetry()
 	t.Cleanup(func() { require.NoError(t, tel.Shutdown(context.Background())) }) //nolint:usetesting
 
-	factory := NewFactory()
+	factory := newTestFactory()
 	cfg := factory.CreateDefaultConfig().(*Config)
 	require.False(t, cfg.EnableAttributeLossAnalysis, "attribute loss analysis should be disabled by default")
 	cfg.MinSpansToAggregate = 2
 
-	tp, err := factory.CreateTraces(t.Context(), metadatatest.NewSettings(tel), cfg, consumertest.NewNop())
+	tp, err := factory.CreateTraces(t.Context(), newTestSettings(tel), cfg, consumertest.NewNop())
 	require.NoError(t, err)
 
 	td, _ := createTestTraceWithAttributeLoss(t)
@@ -677,7 +700,7 @@ func TestOutlierMetrics_IQR(t *testing.T) {
 	tel := componenttest.NewTelemetry()
 	t.Cleanup(func() { require.NoError(t, tel.Shutdown(context.Background())) }) //nolint:usetesting
 
-	factory := NewFactory()
+	factory := newTestFactory()
 	cfg := factory.CreateDefaultConfig().(*Config)
 	cfg.MinSpansToAggregate = 5
 	cfg.GroupByAttributes = []string{"db.operation"}
@@ -691,7 +714,7 @@ func TestOutlierMetrics_IQR(t *testing.T) {
 	cfg.OutlierAnalysis.CorrelationMaxNormalOccurrence = 0.25
 	cfg.OutlierAnalysis.MaxCorrelatedAttributes = 5
 
-	tp, err := factory.CreateTraces(t.Context(), metadatatest.NewSettings(tel), cfg, consumertest.NewNop())
+	tp, err := factory.CreateTraces(t.Context(), newTestSettings(tel), cfg, consumertest.NewNop())
 	require.NoError(t, err)
 
 	td := createTestTraceWithOutliers(t)
@@ -713,7 +736,7 @@ func TestOutlierMetrics_MAD(t *testing.T) {
 	tel := componenttest.NewTelemetry()
 	t.Cleanup(func() { require.NoError(t, tel.Shutdown(context.Background())) }) //nolint:usetesting
 
-	factory := NewFactory()
+	factory := newTestFactory()
 	cfg := factory.CreateDefaultConfig().(*Config)
 	cfg.MinSpansToAggregate = 5
 	cfg.GroupByAttributes = []string{"db.operation"}
@@ -727,7 +750,7 @@ func TestOutlierMetrics_MAD(t *testing.T) {
 	cfg.OutlierAnalysis.CorrelationMaxNormalOccurrence = 0.25
 	cfg.OutlierAnalysis.MaxCorrelatedAttributes = 5
 
-	tp, err := factory.CreateTraces(t.Context(), metadatatest.NewSettings(tel), cfg, consumertest.NewNop())
+	tp, err := factory.CreateTraces(t.Context(), newTestSettings(tel), cfg, consumertest.NewNop())
 	require.NoError(t, err)
 
 	td := createTestTraceWithOutliers(t)
@@ -749,7 +772,7 @@ func TestOutlierMetrics_NoPreservation(t *testing.T) {
 	tel := componenttest.NewTelemetry()
 	t.Cleanup(func() { require.NoError(t, tel.Shutdown(context.Background())) }) //nolint:usetesting
 
-	factory := NewFactory()
+	factory := newTestFactory()
 	cfg := factory.CreateDefaultConfig().(*Config)
 	cfg.MinSpansToAggregate = 5
 	cfg.GroupByAttributes = []string{"db.operation"}
@@ -762,7 +785,7 @@ func TestOutlierMetrics_NoPreservation(t *testing.T) {
 	cfg.OutlierAnalysis.CorrelationMaxNormalOccurrence = 0.25
 	cfg.OutlierAnalysis.MaxCorrelatedAttributes = 5
 
-	tp, err := factory.CreateTraces(t.Context(), metadatatest.NewSettings(tel), cfg, consumertest.NewNop())
+	tp, err := factory.CreateTraces(t.Context(), newTestSettings(tel), cfg, consumertest.NewNop())
 	require.NoError(t, err)
 
 	td := createTestTraceWithOutliers(t)
@@ -781,7 +804,7 @@ func TestOutlierMetrics_NoPreservation(t *testing.T) {
 }
 
 func TestProcessorPreservesOutlierSpans(t *testing.T) {
-	factory := NewFactory()
+	factory := newTestFactory()
 	cfg := factory.CreateDefaultConfig().(*Config)
 	cfg.MinSpansToAggregate = 5
 	cfg.GroupByAttributes = []string{"db.operation"}
@@ -794,7 +817,7 @@ func TestProcessorPreservesOutlierSpans(t *testing.T) {
 	cfg.OutlierAnalysis.CorrelationMaxNormalOccurrence = 0.25
 	cfg.OutlierAnalysis.MaxCorrelatedAttributes = 5
 
-	tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(metadata.Type), cfg, consumertest.NewNop())
+	tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(testType), cfg, consumertest.NewNop())
 	require.NoError(t, err)
 
 	td := createTestTraceWithOutliers(t)
@@ -848,7 +871,7 @@ func TestProcessorPreservesOutlierSpans(t *testing.T) {
 // handler, so the budget must go to the next leaf outlier under a normal handler
 // rather than being silently consumed by the already-covered one.
 func TestProcessorOutlierBudgetSkipsCoveredOutliers(t *testing.T) {
-	factory := NewFactory()
+	factory := newTestFactory()
 	cfg := factory.CreateDefaultConfig().(*Config)
 	cfg.MinSpansToAggregate = 5
 	cfg.MaxParentDepth = -1
@@ -863,7 +886,7 @@ func TestProcessorOutlierBudgetSkipsCoveredOutliers(t *testing.T) {
 	cfg.OutlierAnalysis.CorrelationMaxNormalOccurrence = 0.25
 	cfg.OutlierAnalysis.MaxCorrelatedAttributes = 5
 
-	tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(metadata.Type), cfg, consumertest.NewNop())
+	tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(testType), cfg, consumertest.NewNop())
 	require.NoError(t, err)
 
 	td := createTestTraceWithNestedOutliers(t)
@@ -904,7 +927,7 @@ func TestProcessorOutlierBudgetSkipsCoveredOutliers(t *testing.T) {
 // TestProcessorSkipsAggregationWhenTooFewNormalSpans tests that aggregation is skipped
 // when preserving outliers would leave too few normal spans to aggregate.
 func TestProcessorSkipsAggregationWhenTooFewNormalSpans(t *testing.T) {
-	factory := NewFactory()
+	factory := newTestFactory()
 	cfg := factory.CreateDefaultConfig().(*Config)
 	cfg.MinSpansToAggregate = 11
 	cfg.GroupByAttributes = []string{"db.operation"}
@@ -918,7 +941,7 @@ func TestProcessorSkipsAggregationWhenTooFewNormalSpans(t *testing.T) {
 	cfg.OutlierAnalysis.CorrelationMaxNormalOccurrence = 0.5
 	cfg.OutlierAnalysis.MaxCorrelatedAttributes = 5
 
-	tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(metadata.Type), cfg, consumertest.NewNop())
+	tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(testType), cfg, consumertest.NewNop())
 	require.NoError(t, err)
 
 	// 13 leaf spans: 10 normal + 3 outliers. After preserving outliers,
@@ -1609,12 +1632,12 @@ func createTestTraceWithMixedStatusSpans(t *testing.T) ptrace.Traces {
 
 func TestLeafSpanPruning_GlobPatternWildcard(t *testing.T) {
 	// Test: "db.*" pattern matches db.operation, db.name, db.statement
-	factory := NewFactory()
+	factory := newTestFactory()
 	cfg := factory.CreateDefaultConfig().(*Config)
 	cfg.MinSpansToAggregate = 2
 	cfg.GroupByAttributes = []string{"db.*"}
 
-	tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(metadata.Type), cfg, consumertest.NewNop())
+	tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(testType), cfg, consumertest.NewNop())
 	require.NoError(t, err)
 
 	// Create trace with spans having multiple db.* attributes
@@ -1639,12 +1662,12 @@ func TestLeafSpanPruning_GlobPatternWildcard(t *testing.T) {
 
 func TestLeafSpanPruning_GlobPatternSeparatesGroups(t *testing.T) {
 	// Test: spans with different db.* values should be in separate groups
-	factory := NewFactory()
+	factory := newTestFactory()
 	cfg := factory.CreateDefaultConfig().(*Config)
 	cfg.MinSpansToAggregate = 2
 	cfg.GroupByAttributes = []string{"db.*"}
 
-	tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(metadata.Type), cfg, consumertest.NewNop())
+	tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(testType), cfg, consumertest.NewNop())
 	require.NoError(t, err)
 
 	// Create trace with spans having different db.operation values
@@ -1662,12 +1685,12 @@ func TestLeafSpanPruning_GlobPatternSeparatesGroups(t *testing.T) {
 
 func TestLeafSpanPruning_GlobPatternMultiplePatterns(t *testing.T) {
 	// Test: multiple glob patterns ["db.*", "http.*"]
-	factory := NewFactory()
+	factory := newTestFactory()
 	cfg := factory.CreateDefaultConfig().(*Config)
 	cfg.MinSpansToAggregate = 2
 	cfg.GroupByAttributes = []string{"db.*", "http.*"}
 
-	tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(metadata.Type), cfg, consumertest.NewNop())
+	tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(testType), cfg, consumertest.NewNop())
 	require.NoError(t, err)
 
 	td := createTestTraceWithDbAndHTTPAttrs(t)
@@ -1684,12 +1707,12 @@ func TestLeafSpanPruning_GlobPatternMultiplePatterns(t *testing.T) {
 
 func TestLeafSpanPruning_GlobPatternExactMatch(t *testing.T) {
 	// Test: pattern without wildcard "db.operation" matches exactly
-	factory := NewFactory()
+	factory := newTestFactory()
 	cfg := factory.CreateDefaultConfig().(*Config)
 	cfg.MinSpansToAggregate = 2
 	cfg.GroupByAttributes = []string{"db.operation"}
 
-	tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(metadata.Type), cfg, consumertest.NewNop())
+	tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(testType), cfg, consumertest.NewNop())
 	require.NoError(t, err)
 
 	td := createTestTraceWithMultipleDbAttrs(t)
@@ -1704,11 +1727,11 @@ func TestLeafSpanPruning_GlobPatternExactMatch(t *testing.T) {
 
 func TestLeafSpanPruning_InvalidGlobPattern(t *testing.T) {
 	// Test: invalid glob pattern should return error during creation
-	factory := NewFactory()
+	factory := newTestFactory()
 	cfg := factory.CreateDefaultConfig().(*Config)
 	cfg.GroupByAttributes = []string{"[invalid"}
 
-	_, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(metadata.Type), cfg, consumertest.NewNop())
+	_, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(testType), cfg, consumertest.NewNop())
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "invalid glob pattern")
 }
@@ -1827,12 +1850,12 @@ func createTestTraceWithDbAndHTTPAttrs(t *testing.T) ptrace.Traces {
 // TestLeafSpanPruning_RecursiveParentAggregation tests that parent spans are aggregated
 // when all their children are aggregated
 func TestLeafSpanPruning_RecursiveParentAggregation(t *testing.T) {
-	factory := NewFactory()
+	factory := newTestFactory()
 	cfg := factory.CreateDefaultConfig().(*Config)
 	cfg.MinSpansToAggregate = 2
 	cfg.GroupByAttributes = []string{"db.op"}
 
-	tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(metadata.Type), cfg, consumertest.NewNop())
+	tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(testType), cfg, consumertest.NewNop())
 	require.NoError(t, err)
 
 	// Create the complex trace from the plan example
@@ -1893,11 +1916,11 @@ func TestLeafSpanPruning_RecursiveParentAggregation(t *testing.T) {
 // TestLeafSpanPruning_ParentNotAggregatedIfChildrenMixed tests that parents are not
 // aggregated if some children are aggregated but others are not
 func TestLeafSpanPruning_ParentNotAggregatedIfChildrenMixed(t *testing.T) {
-	factory := NewFactory()
+	factory := newTestFactory()
 	cfg := factory.CreateDefaultConfig().(*Config)
 	cfg.MinSpansToAggregate = 2
 
-	tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(metadata.Type), cfg, consumertest.NewNop())
+	tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(testType), cfg, consumertest.NewNop())
 	require.NoError(t, err)
 
 	td := createTestTraceWithMixedChildren(t)
@@ -1933,11 +1956,11 @@ func TestLeafSpanPruning_ParentNotAggregatedIfChildrenMixed(t *testing.T) {
 // TestLeafSpanPruning_RootSpansNotAggregated tests that root spans (with no parent)
 // are never aggregated
 func TestLeafSpanPruning_RootSpansNotAggregated(t *testing.T) {
-	factory := NewFactory()
+	factory := newTestFactory()
 	cfg := factory.CreateDefaultConfig().(*Config)
 	cfg.MinSpansToAggregate = 2
 
-	tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(metadata.Type), cfg, consumertest.NewNop())
+	tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(testType), cfg, consumertest.NewNop())
 	require.NoError(t, err)
 
 	td := createTestTraceWithMultipleRoots(t)
@@ -1968,12 +1991,12 @@ func TestLeafSpanPruning_RootSpansNotAggregated(t *testing.T) {
 
 // TestLeafSpanPruning_ThreeLevelAggregation tests aggregation across three levels
 func TestLeafSpanPruning_ThreeLevelAggregation(t *testing.T) {
-	factory := NewFactory()
+	factory := newTestFactory()
 	cfg := factory.CreateDefaultConfig().(*Config)
 	cfg.MinSpansToAggregate = 2
 	cfg.MaxParentDepth = -1 // unlimited for this test
 
-	tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(metadata.Type), cfg, consumertest.NewNop())
+	tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(testType), cfg, consumertest.NewNop())
 	require.NoError(t, err)
 
 	td := createTestTraceWithThreeLevels(t)
@@ -2349,12 +2372,12 @@ func findAllSpansByExactName(td ptrace.Traces, name string) []ptrace.Span {
 // TestLeafSpanPruning_LongestDurationTemplate tests that the span with the longest
 // duration is used as the template for the summary span
 func TestLeafSpanPruning_LongestDurationTemplate(t *testing.T) {
-	factory := NewFactory()
+	factory := newTestFactory()
 	cfg := factory.CreateDefaultConfig().(*Config)
 	cfg.MinSpansToAggregate = 2
 	cfg.GroupByAttributes = []string{"db.operation"}
 
-	tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(metadata.Type), cfg, consumertest.NewNop())
+	tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(testType), cfg, consumertest.NewNop())
 	require.NoError(t, err)
 
 	// Create trace with spans of varying durations and unique identifying attributes
@@ -2429,11 +2452,11 @@ func createTestTraceWithVaryingDurations(t *testing.T) ptrace.Traces {
 
 func TestLeafSpanPruning_TraceStateGrouping_SameTraceState(t *testing.T) {
 	// Test: spans with identical TraceState should be aggregated together
-	factory := NewFactory()
+	factory := newTestFactory()
 	cfg := factory.CreateDefaultConfig().(*Config)
 	cfg.MinSpansToAggregate = 2
 
-	tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(metadata.Type), cfg, consumertest.NewNop())
+	tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(testType), cfg, consumertest.NewNop())
 	require.NoError(t, err)
 
 	td := createTestTraceWithSameTraceState(t, "ot=th:fd70a4;rv:12345")
@@ -2460,11 +2483,11 @@ func TestLeafSpanPruning_TraceStateGrouping_SameTraceState(t *testing.T) {
 
 func TestLeafSpanPruning_TraceStateGrouping_DifferentThresholds(t *testing.T) {
 	// Test: spans with different th (threshold) values should be in separate groups
-	factory := NewFactory()
+	factory := newTestFactory()
 	cfg := factory.CreateDefaultConfig().(*Config)
 	cfg.MinSpansToAggregate = 2
 
-	tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(metadata.Type), cfg, consumertest.NewNop())
+	tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(testType), cfg, consumertest.NewNop())
 	require.NoError(t, err)
 
 	td := createTestTraceWithDifferentTraceStates(t)
@@ -2497,11 +2520,11 @@ func TestLeafSpanPruning_TraceStateGrouping_DifferentThresholds(t *testing.T) {
 
 func TestLeafSpanPruning_TraceStateGrouping_MixedWithEmpty(t *testing.T) {
 	// Test: spans with TraceState and spans without should be in separate groups
-	factory := NewFactory()
+	factory := newTestFactory()
 	cfg := factory.CreateDefaultConfig().(*Config)
 	cfg.MinSpansToAggregate = 2
 
-	tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(metadata.Type), cfg, consumertest.NewNop())
+	tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(testType), cfg, consumertest.NewNop())
 	require.NoError(t, err)
 
 	td := createTestTraceWithMixedTraceState(t)
@@ -2540,11 +2563,11 @@ func TestLeafSpanPruning_TraceStateGrouping_MixedWithEmpty(t *testing.T) {
 
 func TestLeafSpanPruning_TraceStateGrouping_EmptyTraceState(t *testing.T) {
 	// Test: spans with empty TraceState should be grouped together
-	factory := NewFactory()
+	factory := newTestFactory()
 	cfg := factory.CreateDefaultConfig().(*Config)
 	cfg.MinSpansToAggregate = 2
 
-	tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(metadata.Type), cfg, consumertest.NewNop())
+	tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(testType), cfg, consumertest.NewNop())
 	require.NoError(t, err)
 
 	td := createTestTraceWithSameTraceState(t, "") // Empty TraceState
@@ -2606,12 +2629,12 @@ func TestLeafSpanPruning_TraceStateGrouping_EmptyTraceState(t *testing.T) {
 //	│   └── summary(svc, aggregates 6 inner svc spans)
 //	│       └── summary(SELECT, aggregates 12 leaf spans)
 func TestParentKeyCollisionAcrossDepths(t *testing.T) {
-	factory := NewFactory()
+	factory := newTestFactory()
 	cfg := factory.CreateDefaultConfig().(*Config)
 	cfg.MinSpansToAggregate = 2
 	cfg.MaxParentDepth = -1
 
-	tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(metadata.Type), cfg, consumertest.NewNop())
+	tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(testType), cfg, consumertest.NewNop())
 	require.NoError(t, err)
 
 	td := createTestTraceWithParentKeyCollision(t)
@@ -2855,11 +2878,11 @@ func TestOTTLConditions_NoConditions(t *testing.T) {
 	tel := componenttest.NewTelemetry()
 	t.Cleanup(func() { require.NoError(t, tel.Shutdown(context.Background())) }) //nolint:usetesting
 
-	factory := NewFactory()
+	factory := newTestFactory()
 	cfg := factory.CreateDefaultConfig().(*Config)
 	cfg.MinSpansToAggregate = 2
 
-	tp, err := factory.CreateTraces(t.Context(), metadatatest.NewSettings(tel), cfg, consumertest.NewNop())
+	tp, err := factory.CreateTraces(t.Context(), newTestSettings(tel), cfg, consumertest.NewNop())
 	require.NoError(t, err)
 
 	td := createTestTraceWithLeafSpans(t, 3, map[string]string{"db.operation": "select"})
@@ -2883,12 +2906,12 @@ func TestOTTLConditions_NoConditions(t *testing.T) {
 }
 
 func TestOTTLConditions_ConditionMatches(t *testing.T) {
-	factory := NewFactory()
+	factory := newTestFactory()
 	cfg := factory.CreateDefaultConfig().(*Config)
 	cfg.MinSpansToAggregate = 2
 	cfg.Conditions = []string{`resource.attributes["service.name"] == "loki-query-engine"`}
 
-	tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(metadata.Type), cfg, consumertest.NewNop())
+	tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(testType), cfg, consumertest.NewNop())
 	require.NoError(t, err)
 
 	td := createTestTraceWithResourceAttr(t, "loki-query-engine")
@@ -2904,12 +2927,12 @@ func TestOTTLConditions_ConditionMatches(t *testing.T) {
 }
 
 func TestOTTLConditions_ConditionDoesNotMatch(t *testing.T) {
-	factory := NewFactory()
+	factory := newTestFactory()
 	cfg := factory.CreateDefaultConfig().(*Config)
 	cfg.MinSpansToAggregate = 2
 	cfg.Conditions = []string{`resource.attributes["service.name"] == "loki-query-engine"`}
 
-	tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(metadata.Type), cfg, consumertest.NewNop())
+	tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(testType), cfg, consumertest.NewNop())
 	require.NoError(t, err)
 
 	td := createTestTraceWithResourceAttr(t, "other-service")
@@ -2925,12 +2948,12 @@ func TestOTTLConditions_ConditionDoesNotMatch(t *testing.T) {
 }
 
 func TestOTTLConditions_SpanAttributeCondition(t *testing.T) {
-	factory := NewFactory()
+	factory := newTestFactory()
 	cfg := factory.CreateDefaultConfig().(*Config)
 	cfg.MinSpansToAggregate = 2
 	cfg.Conditions = []string{`attributes["db.system"] == "postgresql"`}
 
-	tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(metadata.Type), cfg, consumertest.NewNop())
+	tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(testType), cfg, consumertest.NewNop())
 	require.NoError(t, err)
 
 	td := createTestTraceWithLeafSpans(t, 3, map[string]string{
@@ -2947,7 +2970,7 @@ func TestOTTLConditions_SpanAttributeCondition(t *testing.T) {
 }
 
 func TestOTTLConditions_MultipleConditions(t *testing.T) {
-	factory := NewFactory()
+	factory := newTestFactory()
 	cfg := factory.CreateDefaultConfig().(*Config)
 	cfg.MinSpansToAggregate = 2
 	cfg.Conditions = []string{
@@ -2955,7 +2978,7 @@ func TestOTTLConditions_MultipleConditions(t *testing.T) {
 		`resource.attributes["service.name"] == "tempo-query-engine"`,
 	}
 
-	tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(metadata.Type), cfg, consumertest.NewNop())
+	tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(testType), cfg, consumertest.NewNop())
 	require.NoError(t, err)
 
 	td := createTestTraceWithResourceAttr(t, "tempo-query-engine")
@@ -2968,12 +2991,12 @@ func TestOTTLConditions_MultipleConditions(t *testing.T) {
 }
 
 func TestOTTLConditions_MixedTraces(t *testing.T) {
-	factory := NewFactory()
+	factory := newTestFactory()
 	cfg := factory.CreateDefaultConfig().(*Config)
 	cfg.MinSpansToAggregate = 2
 	cfg.Conditions = []string{`resource.attributes["service.name"] == "loki-query-engine"`}
 
-	tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(metadata.Type), cfg, consumertest.NewNop())
+	tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(testType), cfg, consumertest.NewNop())
 	require.NoError(t, err)
 
 	td := ptrace.NewTraces()
@@ -3042,12 +3065,12 @@ func TestOTTLConditions_MixedTraces(t *testing.T) {
 }
 
 func TestOTTLConditions_InvalidOTTL(t *testing.T) {
-	factory := NewFactory()
+	factory := newTestFactory()
 	cfg := factory.CreateDefaultConfig().(*Config)
 	cfg.MinSpansToAggregate = 2
 	cfg.Conditions = []string{`invalid otql syntax [[[`}
 
-	_, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(metadata.Type), cfg, consumertest.NewNop())
+	_, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(testType), cfg, consumertest.NewNop())
 	require.Error(t, err)
 }
 
@@ -3055,12 +3078,12 @@ func TestOTTLConditions_TracesSkippedMetric(t *testing.T) {
 	tel := componenttest.NewTelemetry()
 	t.Cleanup(func() { require.NoError(t, tel.Shutdown(context.Background())) }) //nolint:usetesting
 
-	factory := NewFactory()
+	factory := newTestFactory()
 	cfg := factory.CreateDefaultConfig().(*Config)
 	cfg.MinSpansToAggregate = 2
 	cfg.Conditions = []string{`resource.attributes["service.name"] == "loki-query-engine"`}
 
-	tp, err := factory.CreateTraces(t.Context(), metadatatest.NewSettings(tel), cfg, consumertest.NewNop())
+	tp, err := factory.CreateTraces(t.Context(), newTestSettings(tel), cfg, consumertest.NewNop())
 	require.NoError(t, err)
 
 	td := createTestTraceWithResourceAttr(t, "other-service")
@@ -3080,12 +3103,12 @@ func TestOTTLConditions_TracesSkippedMetric(t *testing.T) {
 }
 
 func TestOTTLConditions_SpanNameCondition(t *testing.T) {
-	factory := NewFactory()
+	factory := newTestFactory()
 	cfg := factory.CreateDefaultConfig().(*Config)
 	cfg.MinSpansToAggregate = 2
 	cfg.Conditions = []string{`name == "SELECT"`}
 
-	tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(metadata.Type), cfg, consumertest.NewNop())
+	tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(testType), cfg, consumertest.NewNop())
 	require.NoError(t, err)
 
 	td := createTestTraceWithLeafSpans(t, 3, map[string]string{"db.operation": "select"})
@@ -3101,12 +3124,12 @@ func TestOTTLConditions_SpanNameCondition(t *testing.T) {
 }
 
 func TestOTTLConditions_StatusCondition(t *testing.T) {
-	factory := NewFactory()
+	factory := newTestFactory()
 	cfg := factory.CreateDefaultConfig().(*Config)
 	cfg.MinSpansToAggregate = 2
 	cfg.Conditions = []string{`status.code == 2`} // StatusCodeError
 
-	tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(metadata.Type), cfg, consumertest.NewNop())
+	tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(testType), cfg, consumertest.NewNop())
 	require.NoError(t, err)
 
 	td := createTestTraceWithMixedStatusSpans(t)
@@ -3120,12 +3143,12 @@ func TestOTTLConditions_StatusCondition(t *testing.T) {
 }
 
 func TestOTTLConditions_NoSpansMatch(t *testing.T) {
-	factory := NewFactory()
+	factory := newTestFactory()
 	cfg := factory.CreateDefaultConfig().(*Config)
 	cfg.MinSpansToAggregate = 2
 	cfg.Conditions = []string{`attributes["never.match"] == "value"`}
 
-	tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(metadata.Type), cfg, consumertest.NewNop())
+	tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(testType), cfg, consumertest.NewNop())
 	require.NoError(t, err)
 
 	td := createTestTraceWithLeafSpans(t, 3, map[string]string{"db.operation": "select"})
@@ -3143,12 +3166,12 @@ func TestOTTLConditions_EvalErrorLogging(t *testing.T) {
 	observedZapCore, observedLogs := observer.New(zapcore.ErrorLevel)
 	logger := zap.New(observedZapCore)
 
-	factory := NewFactory()
+	factory := newTestFactory()
 	cfg := factory.CreateDefaultConfig().(*Config)
 	cfg.MinSpansToAggregate = 2
 	cfg.Conditions = []string{`ParseJSON(attributes["db.operation"])["key"] == "value"`}
 
-	settings := processortest.NewNopSettings(metadata.Type)
+	settings := processortest.NewNopSettings(testType)
 	settings.Logger = logger
 
 	telemetryBuilder, err := metadata.NewTelemetryBuilder(settings.TelemetrySettings)
@@ -3162,9 +3185,9 @@ func TestOTTLConditions_EvalErrorLogging(t *testing.T) {
 	)
 	require.NoError(t, err)
 
-	p, err := newSpanPruningProcessor(settings, cfg, telemetryBuilder, conditions)
+	p, err := newSpanPruningProcessor(settings.TelemetrySettings, cfg, telemetryBuilder, conditions)
 	require.NoError(t, err)
-	t.Cleanup(func() { require.NoError(t, p.shutdown(context.Background())) }) //nolint:usetesting
+	t.Cleanup(func() { require.NoError(t, p.Shutdown(context.Background())) }) //nolint:usetesting
 
 	td := createTestTraceWithLeafSpans(t, 3, map[string]string{"db.operation": "select"})
 	originalSpanCount := countSpans(td)
@@ -3224,7 +3247,7 @@ func createTestTraceWithResourceAttr(t *testing.T, serviceName string) ptrace.Tr
 // two depths form separate groups, so the deep-handler outliers stay at depth 3
 // on every run and carry provenance recording their original position.
 func TestOutlierReparentingDeterministicAcrossDepths(t *testing.T) {
-	factory := NewFactory()
+	factory := newTestFactory()
 	cfg := factory.CreateDefaultConfig().(*Config)
 	cfg.MinSpansToAggregate = 5
 	cfg.MaxParentDepth = -1
@@ -3246,7 +3269,7 @@ func TestOutlierReparentingDeterministicAcrossDepths(t *testing.T) {
 	observedDepths := make(map[int]bool)
 	const iterations = 50
 	for range iterations {
-		tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(metadata.Type), cfg, consumertest.NewNop())
+		tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(testType), cfg, consumertest.NewNop())
 		require.NoError(t, err)
 
 		td := createTraceWithSameNamedParentsAtDifferentDepths(t)
@@ -3385,7 +3408,7 @@ func createTraceWithSameNamedParentsAtDifferentDepths(t *testing.T) ptrace.Trace
 // log-mw — varied run to run. The auth-mw handler starts earliest, so the
 // summary must consistently anchor there.
 func TestParentReparentingDeterministicAcrossSiblings(t *testing.T) {
-	factory := NewFactory()
+	factory := newTestFactory()
 	cfg := factory.CreateDefaultConfig().(*Config)
 	cfg.MinSpansToAggregate = 5
 	cfg.MaxParentDepth = -1
@@ -3396,7 +3419,7 @@ func TestParentReparentingDeterministicAcrossSiblings(t *testing.T) {
 	observedParents := make(map[pcommon.SpanID]bool)
 	const iterations = 50
 	for range iterations {
-		tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(metadata.Type), cfg, consumertest.NewNop())
+		tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(testType), cfg, consumertest.NewNop())
 		require.NoError(t, err)
 
 		td := createTraceWithSameNamedParentsUnderDifferentGrandparents(t)
@@ -3469,7 +3492,7 @@ func createTraceWithSameNamedParentsUnderDifferentGrandparents(t *testing.T) ptr
 // interior level (a slow "handler" among many) has its entire subtree preserved
 // while the normal handlers aggregate.
 func TestOutlierInteriorSubtreePreserved(t *testing.T) {
-	factory := NewFactory()
+	factory := newTestFactory()
 	cfg := factory.CreateDefaultConfig().(*Config)
 	cfg.MinSpansToAggregate = 5
 	cfg.MaxParentDepth = -1
@@ -3486,7 +3509,7 @@ func TestOutlierInteriorSubtreePreserved(t *testing.T) {
 		MinOutlierThresholdPercent:     0.1,
 	}
 
-	tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(metadata.Type), cfg, consumertest.NewNop())
+	tp, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(testType), cfg, consumertest.NewNop())
 	require.NoError(t, err)
 
 	td, slowHandlerID := createTraceWithSlowHandler(t)
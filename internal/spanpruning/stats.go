@@ -1,7 +1,7 @@
 // Copyright The OpenTelemetry Authors
 // SPDX-License-Identifier: Apache-2.0
 
-package spanpruningprocessor // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/spanpruningprocessor"
+package spanpruning // import "github.com/open-telemetry/opentelemetry-collector-contrib/internal/spanpruning"
 
 import (
 	"time"
@@ -24,7 +24,7 @@ type aggregationData struct {
 
 // calculateAggregationData derives span counts and duration stats for the
 // provided nodes in one traversal.
-func (p *spanPruningProcessor) calculateAggregationData(nodes []*spanNode) aggregationData {
+func (p *Pruner) calculateAggregationData(nodes []*spanNode) aggregationData {
 	data := aggregationData{
 		count: int64(len(nodes)),
 	}
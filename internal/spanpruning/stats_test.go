@@ -1,7 +1,7 @@
 // Copyright The OpenTelemetry Authors
 // SPDX-License-Identifier: Apache-2.0
 
-package spanpruningprocessor
+package spanpruning
 
 import (
 	"testing"
@@ -11,7 +11,7 @@ import (
 )
 
 func TestCalculateAggregationData_CumulativeHistogramBuckets(t *testing.T) {
-	p := &spanPruningProcessor{
+	p := &Pruner{
 		config: &Config{
 			AggregationHistogramBuckets: []time.Duration{
 				10 * time.Millisecond,
@@ -34,7 +34,7 @@ func TestCalculateAggregationData_CumulativeHistogramBuckets(t *testing.T) {
 }
 
 func TestCalculateAggregationData_NoHistogramBuckets(t *testing.T) {
-	p := &spanPruningProcessor{
+	p := &Pruner{
 		config: &Config{},
 	}
 
@@ -1,7 +1,7 @@
 // Copyright The OpenTelemetry Authors
 // SPDX-License-Identifier: Apache-2.0
 
-package spanpruningprocessor // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/spanpruningprocessor"
+package spanpruning // import "github.com/open-telemetry/opentelemetry-collector-contrib/internal/spanpruning"
 
 import (
 	"bytes"
@@ -16,6 +16,7 @@ import (
 // aggregation bookkeeping.
 type spanNode struct {
 	span               ptrace.Span
+	resourceSpans      ptrace.ResourceSpans
 	scopeSpans         ptrace.ScopeSpans
 	parent             *spanNode
 	children           []*spanNode
@@ -38,7 +39,7 @@ type traceTree struct {
 // buildTraceTree constructs parent/child links for a trace and records
 // leaves, roots, and orphans so aggregation decisions can account for
 // incomplete traces.
-func (p *spanPruningProcessor) buildTraceTree(spans []spanInfo) *traceTree {
+func (p *Pruner) buildTraceTree(spans []spanInfo) *traceTree {
 	tree := &traceTree{
 		nodeByID: make(map[pcommon.SpanID]*spanNode, len(spans)),
 	}
@@ -50,9 +51,10 @@ func (p *spanPruningProcessor) buildTraceTree(spans []spanInfo) *traceTree {
 	// First pass: create nodes for all spans, initially mark all as leaves
 	for _, info := range spans {
 		node := &spanNode{
-			span:       info.span,
-			scopeSpans: info.scopeSpans,
-			isLeaf:     true, // assume leaf until a child links to it
+			span:          info.span,
+			resourceSpans: info.resourceSpans,
+			scopeSpans:    info.scopeSpans,
+			isLeaf:        true, // assume leaf until a child links to it
 		}
 		tree.nodeByID[info.span.SpanID()] = node
 	}
@@ -197,7 +199,7 @@ func collectParentCandidates(markedNodes []*spanNode) []*spanNode {
 // outlier subtree. A preserved subtree is reparented onto this node's summary,
 // so it does not block aggregation. The outlier keeps everything beneath it but
 // not its ancestors.
-func (*spanPruningProcessor) isEligibleForParentAggregation(node *spanNode) bool {
+func (*Pruner) isEligibleForParentAggregation(node *spanNode) bool {
 	// Must have children (not a leaf)
 	if node.isLeaf {
 		return false
@@ -134,6 +134,22 @@ func TestCompareLogs(t *testing.T) {
 			withoutOptions: errors.New(`resource "map[]": scope "collector": log record "map[]": observed timestamp doesn't match expected: 11651379494838206465, actual: 11651379494838206464`),
 			withOptions:    nil,
 		},
+		{
+			name: "ignore-traceid",
+			compareOptions: []CompareLogsOption{
+				IgnoreTraceID(),
+			},
+			withoutOptions: errors.New(`resource "map[type:one]": scope "": log record "map[testKey1:teststringvalue1 testKey2:teststringvalue2]": trace ID doesn't match expected: [139 32 209 52 158 249 182 214 249 212 209 212 163 172 46 130], actual: [123 32 209 52 158 249 182 214 249 212 209 212 163 172 46 130]`),
+			withOptions:    nil,
+		},
+		{
+			name: "ignore-spanid",
+			compareOptions: []CompareLogsOption{
+				IgnoreSpanID(),
+			},
+			withoutOptions: errors.New(`resource "map[type:one]": scope "": log record "map[testKey1:teststringvalue1 testKey2:teststringvalue2]": span ID doesn't match expected: [12 42 217 36 225 119 22 64], actual: [12 42 217 36 225 119 22 48]`),
+			withOptions:    nil,
+		},
 		{
 			name: "ignore-timestamp",
 			compareOptions: []CompareLogsOption{
@@ -141,6 +141,50 @@ func maskObservedTimestamp(logs plog.Logs, ts pcommon.Timestamp) {
 	}
 }
 
+// IgnoreTraceID is a CompareLogsOption that clears TraceID fields on all log records.
+func IgnoreTraceID() CompareLogsOption {
+	return compareLogsOptionFunc(func(expected, actual plog.Logs) {
+		traceID := pcommon.NewTraceIDEmpty()
+		maskTraceID(expected, traceID)
+		maskTraceID(actual, traceID)
+	})
+}
+
+func maskTraceID(logs plog.Logs, traceID pcommon.TraceID) {
+	rls := logs.ResourceLogs()
+	for i := 0; i < logs.ResourceLogs().Len(); i++ {
+		sls := rls.At(i).ScopeLogs()
+		for j := 0; j < sls.Len(); j++ {
+			lrs := sls.At(j).LogRecords()
+			for k := 0; k < lrs.Len(); k++ {
+				lrs.At(k).SetTraceID(traceID)
+			}
+		}
+	}
+}
+
+// IgnoreSpanID is a CompareLogsOption that clears SpanID fields on all log records.
+func IgnoreSpanID() CompareLogsOption {
+	return compareLogsOptionFunc(func(expected, actual plog.Logs) {
+		spanID := pcommon.NewSpanIDEmpty()
+		maskSpanID(expected, spanID)
+		maskSpanID(actual, spanID)
+	})
+}
+
+func maskSpanID(logs plog.Logs, spanID pcommon.SpanID) {
+	rls := logs.ResourceLogs()
+	for i := 0; i < logs.ResourceLogs().Len(); i++ {
+		sls := rls.At(i).ScopeLogs()
+		for j := 0; j < sls.Len(); j++ {
+			lrs := sls.At(j).LogRecords()
+			for k := 0; k < lrs.Len(); k++ {
+				lrs.At(k).SetSpanID(spanID)
+			}
+		}
+	}
+}
+
 // IgnoreResourceLogsOrder is a CompareLogsOption that ignores the order of resource traces/metrics/logs.
 func IgnoreResourceLogsOrder() CompareLogsOption {
 	return compareLogsOptionFunc(func(expected, actual plog.Logs) {
@@ -167,6 +167,28 @@ func maskSpanID(traces ptrace.Traces, spanID pcommon.SpanID) {
 	}
 }
 
+// IgnoreParentSpanID is a CompareTracesOption that clears ParentSpanID fields on all spans.
+func IgnoreParentSpanID() CompareTracesOption {
+	return compareTracesOptionFunc(func(expected, actual ptrace.Traces) {
+		spanID := pcommon.NewSpanIDEmpty()
+		maskParentSpanID(expected, spanID)
+		maskParentSpanID(actual, spanID)
+	})
+}
+
+func maskParentSpanID(traces ptrace.Traces, spanID pcommon.SpanID) {
+	for i := 0; i < traces.ResourceSpans().Len(); i++ {
+		rs := traces.ResourceSpans().At(i)
+		for j := 0; j < rs.ScopeSpans().Len(); j++ {
+			ss := rs.ScopeSpans().At(j)
+			for k := 0; k < ss.Spans().Len(); k++ {
+				span := ss.Spans().At(k)
+				span.SetParentSpanID(spanID)
+			}
+		}
+	}
+}
+
 // IgnoreSpanAttributeValue is a CompareTracesOption that clears value of the span attribute.
 func IgnoreSpanAttributeValue(attributeName string) CompareTracesOption {
 	return compareTracesOptionFunc(func(expected, actual ptrace.Traces) {
@@ -98,6 +98,16 @@ func TestCompareTraces(t *testing.T) {
 			),
 			withOptions: nil,
 		},
+		{
+			name: "ignore-parentspanid",
+			compareOptions: []CompareTracesOption{
+				IgnoreParentSpanID(),
+			},
+			withoutOptions: multierr.Combine(
+				errors.New("resource \"map[host.name:node1]\": scope \"collector\": span \"\": parent span ID doesn't match expected: bcff497b5a47310f, actual: 310fbcff497b5a47"),
+			),
+			withOptions: nil,
+		},
 		{
 			name: "resourcespans-amount-unequal",
 			withoutOptions: multierr.Combine(
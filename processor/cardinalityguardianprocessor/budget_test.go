@@ -0,0 +1,202 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package cardinalityguardianprocessor
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/processor/processortest"
+)
+
+func newBudgetTestProcessor(t *testing.T, cfg *Config) (*cardinalityProcessor, *consumertest.MetricsSink) {
+	t.Helper()
+	next := new(consumertest.MetricsSink)
+	set := processortest.NewNopSettings(component.MustNewType("cardinality_guardian"))
+	proc, err := newCardinalityProcessor(t.Context(), cfg, set, next)
+	require.NoError(t, err)
+	return proc.(*cardinalityProcessor), next
+}
+
+// appendSpreadMetrics appends n delta sums to a new resource, each with a
+// single data point carrying a unique value of key, so every metric on its
+// own stays far below any per-metric limit.
+func appendSpreadMetrics(md pmetric.Metrics, service, key string, n int) {
+	rm := md.ResourceMetrics().AppendEmpty()
+	rm.Resource().Attributes().PutStr("service.name", service)
+	sm := rm.ScopeMetrics().AppendEmpty()
+	for i := range n {
+		m := sm.Metrics().AppendEmpty()
+		m.SetName(fmt.Sprintf("%s.metric_%d", service, i))
+		m.SetEmptySum().SetAggregationTemporality(pmetric.AggregationTemporalityDelta)
+		dp := m.Sum().DataPoints().AppendEmpty()
+		dp.SetIntValue(1)
+		dp.Attributes().PutStr("region", "us-east")
+		dp.Attributes().PutStr(key, fmt.Sprintf("%s_%d", service, i))
+	}
+}
+
+// countWithAttr counts the data points of the resource with the given
+// service.name that still carry key.
+func countWithAttr(t *testing.T, md pmetric.Metrics, service, key string) (kept, total int) {
+	t.Helper()
+	for i := 0; i < md.ResourceMetrics().Len(); i++ {
+		rm := md.ResourceMetrics().At(i)
+		if v, _ := rm.Resource().Attributes().Get("service.name"); v.Str() != service {
+			continue
+		}
+		ms := rm.ScopeMetrics().At(0).Metrics()
+		for j := 0; j < ms.Len(); j++ {
+			total++
+			if _, ok := ms.At(j).Sum().DataPoints().At(0).Attributes().Get(key); ok {
+				kept++
+			}
+		}
+	}
+	return kept, total
+}
+
+// TestAttributeBudget verifies that an attribute spreading across many
+// metrics is enforced once its budget is spent, although no single metric
+// exceeds the per-metric limit.
+func TestAttributeBudget(t *testing.T) {
+	p, next := newBudgetTestProcessor(t, &Config{
+		MaxCardinalityDeltaPerEpoch: 1000,
+		EpochDurationSeconds:        300,
+		EnforcementMode:             EnforcementStripAndReaggregate,
+		AttributeBudgets:            map[string]int{"user_id": 5},
+	})
+
+	md := pmetric.NewMetrics()
+	appendSpreadMetrics(md, "checkout", "user_id", 50)
+	appendSpreadMetrics(md, "checkout", "session_id", 50)
+	require.NoError(t, p.ConsumeMetrics(t.Context(), md))
+
+	out := next.AllMetrics()[0]
+	kept, total := countWithAttr(t, out, "checkout", "user_id")
+	assert.Equal(t, 100, total)
+	assert.Equal(t, 5, kept, "user_id is stripped once its budget is spent")
+
+	rm := out.ResourceMetrics().At(1)
+	for i := 0; i < rm.ScopeMetrics().At(0).Metrics().Len(); i++ {
+		attrs := rm.ScopeMetrics().At(0).Metrics().At(i).Sum().DataPoints().At(0).Attributes()
+		_, ok := attrs.Get("session_id")
+		assert.True(t, ok, "attributes without a budget only have the per-metric limit")
+	}
+}
+
+// TestResourceBudget verifies that every resource gets its own budget, so
+// that one exploding service does not affect the others.
+func TestResourceBudget(t *testing.T) {
+	p, next := newBudgetTestProcessor(t, &Config{
+		MaxCardinalityDeltaPerEpoch: 1000,
+		EpochDurationSeconds:        300,
+		EnforcementMode:             EnforcementOverflowAttribute,
+		ResourceBudgets:             map[string]int{"service.name": 5},
+	})
+
+	md := pmetric.NewMetrics()
+	appendSpreadMetrics(md, "checkout", "user_id", 50)
+	appendSpreadMetrics(md, "cart", "user_id", 5)
+	require.NoError(t, p.ConsumeMetrics(t.Context(), md))
+
+	overflowed := func(rm pmetric.ResourceMetrics) int {
+		n := 0
+		ms := rm.ScopeMetrics().At(0).Metrics()
+		for i := 0; i < ms.Len(); i++ {
+			if v, _ := ms.At(i).Sum().DataPoints().At(0).Attributes().Get("user_id"); v.Str() == overflowSentinel {
+				n++
+			}
+		}
+		return n
+	}
+	out := next.AllMetrics()[0]
+	assert.Equal(t, 45, overflowed(out.ResourceMetrics().At(0)), "checkout exceeds its budget")
+	assert.Zero(t, overflowed(out.ResourceMetrics().At(1)), "cart has a budget of its own")
+
+	v, _ := out.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Sum().DataPoints().At(0).Attributes().Get("region")
+	assert.Equal(t, "us-east", v.Str(), "stable labels are within budget")
+}
+
+// TestResourceBudgetMissingAttribute verifies that resources without the
+// budget's attribute are not subject to it.
+func TestResourceBudgetMissingAttribute(t *testing.T) {
+	p, _ := newBudgetTestProcessor(t, &Config{
+		MaxCardinalityDeltaPerEpoch: 1000,
+		EpochDurationSeconds:        300,
+		ResourceBudgets:             map[string]int{"k8s.namespace.name": 5, "service.name": 10},
+	})
+
+	res := pcommon.NewMap()
+	res.PutStr("service.name", "checkout")
+	assert.Equal(t, []resourceScope{{resource: "service.name=checkout", limit: 10}}, p.resourceScopes(res))
+
+	res.PutStr("k8s.namespace.name", "shop")
+	assert.Equal(t, []resourceScope{
+		{resource: "k8s.namespace.name=shop", limit: 5},
+		{resource: "service.name=checkout", limit: 10},
+	}, p.resourceScopes(res))
+
+	assert.Nil(t, p.resourceScopes(pcommon.NewMap()))
+}
+
+// TestBudgetTagOnly verifies that budgets use the tag_only enforcement mode.
+func TestBudgetTagOnly(t *testing.T) {
+	p, _ := newBudgetTestProcessor(t, &Config{
+		MaxCardinalityDeltaPerEpoch: 1000,
+		EpochDurationSeconds:        300,
+		AttributeBudgets:            map[string]int{"user_id": 1},
+	})
+
+	var tagged int
+	for i := range 10 {
+		attrs := pcommon.NewMap()
+		attrs.PutStr("user_id", fmt.Sprintf("u%d", i))
+		p.handleAttributes(fmt.Sprintf("metric_%d", i), attrs)
+		if _, ok := attrs.Get("otel.metric.overflow"); ok {
+			tagged++
+			_, ok = attrs.Get("user_id")
+			assert.True(t, ok, "tag_only keeps the attribute")
+		}
+	}
+	assert.Equal(t, 9, tagged)
+}
+
+// TestBudgetTrackers verifies that budget trackers count against
+// max_tracker_count and are excluded from the per-metric top offenders.
+func TestBudgetTrackers(t *testing.T) {
+	p, _ := newBudgetTestProcessor(t, &Config{
+		MaxCardinalityDeltaPerEpoch: 1000,
+		EpochDurationSeconds:        300,
+		TopOffendersCount:           10,
+		AttributeBudgets:            map[string]int{"user_id": 1000},
+		ResourceBudgets:             map[string]int{"service.name": 1000},
+	})
+
+	scopes := []resourceScope{{resource: "service.name=checkout", limit: 1000}}
+	for i := range 10 {
+		p.shouldDropInScopes("metric", "user_id", pcommon.NewValueStr(fmt.Sprintf("u%d", i)), scopes)
+	}
+	assert.Equal(t, int64(3), p.trackerCount.Load(), "one per-metric, one attribute and one resource tracker")
+
+	key := trackerKey{attrKey: "user_id", resource: "service.name=checkout", budget: budgetResource}
+	p.shardFor(key).mu.RLock()
+	tr := p.shardFor(key).trackers[key]
+	p.shardFor(key).mu.RUnlock()
+	require.NotNil(t, tr)
+	curr, _ := tr.insert(hashAttrValue(pcommon.NewValueStr("u0")))
+	assert.Equal(t, uint64(10), curr)
+
+	p.rotate()
+	p.topOffendersMu.RLock()
+	defer p.topOffendersMu.RUnlock()
+	require.Len(t, p.topOffenders, 1)
+	assert.Equal(t, "metric", p.topOffenders[0].metricName)
+}
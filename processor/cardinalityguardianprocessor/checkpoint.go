@@ -0,0 +1,283 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package cardinalityguardianprocessor // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/cardinalityguardianprocessor"
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/axiomhq/hyperloglog"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/extension/xextension/storage"
+	"go.uber.org/zap"
+)
+
+const (
+	// checkpointVersion is bumped whenever the checkpoint format changes;
+	// checkpoints of another version are ignored.
+	checkpointVersion = 1
+
+	// topOffendersStorageKey holds the top offenders of the last rotation.
+	// Trackers are stored under shardStorageKey, one key per shard, so that no
+	// single value holds every sketch.
+	topOffendersStorageKey = "top_offenders"
+)
+
+// shardStorageKey returns the storage key of the trackers of shard i.
+func shardStorageKey(i int) string {
+	return "trackers_" + strconv.Itoa(i)
+}
+
+// shardCheckpoint is the stored form of the trackers of one shard.
+type shardCheckpoint struct {
+	Version  int                 `json:"version"`
+	SavedAt  time.Time           `json:"saved_at"`
+	Trackers []trackerCheckpoint `json:"trackers"`
+}
+
+// trackerCheckpoint is the stored form of a tracker and its key. Sketches are
+// stored in their binary encoding.
+type trackerCheckpoint struct {
+	MetricName string     `json:"metric_name,omitempty"`
+	AttrKey    string     `json:"attr_key"`
+	Resource   string     `json:"resource,omitempty"`
+	Budget     budgetKind `json:"budget,omitempty"`
+	Current    []byte     `json:"current"`
+	Previous   []byte     `json:"previous"`
+	CachedCurr uint64     `json:"cached_curr"`
+	CachedPrev uint64     `json:"cached_prev"`
+	IdleEpochs int        `json:"idle_epochs,omitempty"`
+}
+
+// offendersCheckpoint is the stored form of the top offenders.
+type offendersCheckpoint struct {
+	Version   int           `json:"version"`
+	Offenders []topOffender `json:"offenders"`
+}
+
+// getStorageClient resolves a storage.Client for the processor.
+func getStorageClient(ctx context.Context, host component.Host, storageID *component.ID, componentID component.ID) (storage.Client, error) {
+	ext, ok := host.GetExtensions()[*storageID]
+	if !ok {
+		return nil, fmt.Errorf("storage extension %q not found", storageID)
+	}
+
+	storageExt, ok := ext.(storage.Extension)
+	if !ok {
+		return nil, fmt.Errorf("extension %q is not a storage extension", storageID)
+	}
+
+	return storageExt.GetClient(ctx, component.KindProcessor, componentID, "")
+}
+
+// checkpoint returns the stored form of t. The sketches are encoded under the
+// tracker lock, so the checkpoint is consistent with concurrent inserts.
+func (t *tracker) checkpoint(key trackerKey) (trackerCheckpoint, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	current, err := t.current.MarshalBinary()
+	if err != nil {
+		return trackerCheckpoint{}, err
+	}
+	previous, err := t.previous.MarshalBinary()
+	if err != nil {
+		return trackerCheckpoint{}, err
+	}
+	return trackerCheckpoint{
+		MetricName: key.metricName,
+		AttrKey:    key.attrKey,
+		Resource:   key.resource,
+		Budget:     key.budget,
+		Current:    current,
+		Previous:   previous,
+		CachedCurr: t.cachedCurr,
+		CachedPrev: t.cachedPrev,
+		IdleEpochs: t.idleEpochs,
+	}, nil
+}
+
+// restoreTracker rebuilds a tracker and its key from a checkpoint.
+func restoreTracker(tc trackerCheckpoint) (trackerKey, *tracker, error) {
+	t := &tracker{
+		current:    new(hyperloglog.Sketch),
+		previous:   new(hyperloglog.Sketch),
+		cachedCurr: tc.CachedCurr,
+		cachedPrev: tc.CachedPrev,
+		idleEpochs: tc.IdleEpochs,
+	}
+	if err := t.current.UnmarshalBinary(tc.Current); err != nil {
+		return trackerKey{}, nil, fmt.Errorf("current sketch: %w", err)
+	}
+	if err := t.previous.UnmarshalBinary(tc.Previous); err != nil {
+		return trackerKey{}, nil, fmt.Errorf("previous sketch: %w", err)
+	}
+	key := trackerKey{metricName: tc.MetricName, attrKey: tc.AttrKey, resource: tc.Resource, budget: tc.Budget}
+	return key, t, nil
+}
+
+// saveCheckpoint writes the trackers of the shards that changed since the
+// last checkpoint, and the top offenders, to storage. Shards are encoded one
+// at a time, so only one shard's encoded sketches are held in memory at once.
+// Shards emptied by evictions are written too, so that evicted trackers are
+// not restored.
+func (p *cardinalityProcessor) saveCheckpoint(ctx context.Context) error {
+	savedAt := time.Now()
+	var trackers, written int
+	for i, shard := range p.shards {
+		// Clear the flag before the snapshot: a concurrent change marks the
+		// shard again and is written by the next checkpoint.
+		if !shard.dirty.Swap(false) {
+			continue
+		}
+		shard.mu.RLock()
+		entries := make([]trackerEntry, 0, len(shard.trackers))
+		for k, t := range shard.trackers {
+			entries = append(entries, trackerEntry{key: k, t: t})
+		}
+		shard.mu.RUnlock()
+
+		cp := shardCheckpoint{
+			Version:  checkpointVersion,
+			SavedAt:  savedAt,
+			Trackers: make([]trackerCheckpoint, 0, len(entries)),
+		}
+		for _, e := range entries {
+			tc, err := e.t.checkpoint(e.key)
+			if err != nil {
+				shard.markDirty()
+				return fmt.Errorf("failed to encode tracker sketches: %w", err)
+			}
+			cp.Trackers = append(cp.Trackers, tc)
+		}
+		data, err := json.Marshal(cp)
+		if err != nil {
+			shard.markDirty()
+			return fmt.Errorf("failed to encode checkpoint: %w", err)
+		}
+		if err := p.storageClient.Set(ctx, shardStorageKey(i), data); err != nil {
+			shard.markDirty()
+			return fmt.Errorf("failed to write checkpoint to storage: %w", err)
+		}
+		trackers += len(entries)
+		written++
+	}
+
+	data, err := json.Marshal(offendersCheckpoint{Version: checkpointVersion, Offenders: p.currentTopOffenders()})
+	if err != nil {
+		return fmt.Errorf("failed to encode top offenders: %w", err)
+	}
+	if err := p.storageClient.Set(ctx, topOffendersStorageKey, data); err != nil {
+		return fmt.Errorf("failed to write top offenders to storage: %w", err)
+	}
+	p.logger.Debug("Saved cardinality checkpoint", zap.Int("shards", written), zap.Int("trackers", trackers))
+	return nil
+}
+
+// loadCheckpoint restores the trackers and top offenders from storage.
+// Trackers are routed to shards by their key, as the shard routing seed
+// differs per process. Restoring stops at MaxTrackerCount.
+//
+// When at least one epoch has passed since the checkpoint was written, the
+// restored trackers are rotated once: the state of the epoch before the
+// restart becomes the baseline, so values seen before the restart are not
+// counted as new, while growth during the downtime is not held against the
+// new epoch.
+func (p *cardinalityProcessor) loadCheckpoint(ctx context.Context) {
+	// Trackers are routed to other shards than the ones they were stored
+	// under, so every shard key is rewritten by the first checkpoint.
+	for _, shard := range p.shards {
+		shard.markDirty()
+	}
+
+	var savedAt time.Time
+	var restored int
+	maxTrackers := int64(p.config.MaxTrackerCount)
+shards:
+	for i := range numShards {
+		data, err := p.storageClient.Get(ctx, shardStorageKey(i))
+		if err != nil {
+			p.logger.Warn("Failed to read checkpoint from storage, starting fresh", zap.Error(err))
+			return
+		}
+		if len(data) == 0 {
+			continue
+		}
+		var cp shardCheckpoint
+		if err := json.Unmarshal(data, &cp); err != nil || cp.Version != checkpointVersion {
+			p.logger.Warn("Ignoring unreadable checkpoint",
+				zap.String("key", shardStorageKey(i)), zap.Int("version", cp.Version), zap.Error(err))
+			continue
+		}
+		if cp.SavedAt.After(savedAt) {
+			savedAt = cp.SavedAt
+		}
+		for _, tc := range cp.Trackers {
+			if maxTrackers > 0 && p.trackerCount.Load() >= maxTrackers {
+				p.logger.Warn("Checkpoint exceeds max_tracker_count, not all trackers were restored",
+					zap.Int("max_tracker_count", p.config.MaxTrackerCount))
+				break shards
+			}
+			key, t, err := restoreTracker(tc)
+			if err != nil {
+				p.logger.Warn("Ignoring unreadable tracker in checkpoint",
+					zap.String("metric", tc.MetricName), zap.String("label", tc.AttrKey), zap.Error(err))
+				continue
+			}
+			shard := p.shardFor(key)
+			shard.mu.Lock()
+			if _, ok := shard.trackers[key]; !ok {
+				shard.trackers[key] = t
+				p.trackerCount.Add(1)
+				restored++
+			}
+			shard.mu.Unlock()
+		}
+	}
+
+	if data, err := p.storageClient.Get(ctx, topOffendersStorageKey); err != nil {
+		p.logger.Warn("Failed to read top offenders from storage", zap.Error(err))
+	} else if len(data) > 0 {
+		var cp offendersCheckpoint
+		if err := json.Unmarshal(data, &cp); err != nil || cp.Version != checkpointVersion {
+			p.logger.Warn("Ignoring unreadable top offenders checkpoint", zap.Int("version", cp.Version), zap.Error(err))
+		} else {
+			offenders := make([]offenderEntry, 0, len(cp.Offenders))
+			for _, o := range cp.Offenders {
+				offenders = append(offenders, offenderEntry{metricName: o.MetricName, labelKey: o.LabelKey, delta: o.Delta})
+			}
+			p.topOffendersMu.Lock()
+			p.topOffenders = offenders
+			p.topOffendersMu.Unlock()
+		}
+	}
+
+	if restored == 0 {
+		return
+	}
+	p.logger.Info("Restored cardinality trackers from checkpoint",
+		zap.Int("trackers", restored), zap.Time("saved_at", savedAt))
+	if time.Since(savedAt) >= time.Duration(p.config.EpochDurationSeconds)*time.Second {
+		p.rotate()
+	}
+}
+
+// checkpointLoop periodically saves the tracker state until ctx is canceled.
+func (p *cardinalityProcessor) checkpointLoop(ctx context.Context) {
+	ticker := time.NewTicker(time.Duration(p.config.CheckpointIntervalSeconds) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := p.saveCheckpoint(ctx); err != nil {
+				p.logger.Warn("Periodic checkpoint failed", zap.Error(err))
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
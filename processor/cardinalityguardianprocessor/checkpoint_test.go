@@ -0,0 +1,206 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package cardinalityguardianprocessor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/confighttp"
+	"go.opentelemetry.io/collector/extension/xextension/storage"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/storage/storagetest"
+)
+
+func newCheckpointTestConfig() *Config {
+	id := storagetest.NewStorageID("test")
+	return &Config{
+		MaxCardinalityDeltaPerEpoch: 10,
+		EpochDurationSeconds:        300,
+		TopOffendersCount:           5,
+		AttributeBudgets:            map[string]int{"user_id": 100},
+		Storage:                     &id,
+	}
+}
+
+func insertValues(p *cardinalityProcessor, metricName, key string, from, to int) (dropped int) {
+	for i := from; i < to; i++ {
+		if p.shouldDrop(metricName, key, pcommon.NewValueStr(fmt.Sprintf("v%d", i))) {
+			dropped++
+		}
+	}
+	return dropped
+}
+
+// TestCheckpointRestore verifies that trackers and top offenders survive a
+// restart, so that enforcement resumes immediately instead of after an epoch.
+func TestCheckpointRestore(t *testing.T) {
+	host := storagetest.NewStorageHost().WithFileBackedStorageExtension("test", t.TempDir())
+	ctx := t.Context()
+
+	p1, _ := newBudgetTestProcessor(t, newCheckpointTestConfig())
+	require.NoError(t, p1.Start(ctx, host))
+	assert.Equal(t, 40, insertValues(p1, "requests", "user_id", 0, 50))
+	insertValues(p1, "requests", "region", 0, 3)
+	p1.rotate()
+	insertValues(p1, "requests", "user_id", 50, 55)
+	require.NoError(t, p1.Shutdown(ctx))
+
+	p2, _ := newBudgetTestProcessor(t, newCheckpointTestConfig())
+	require.NoError(t, p2.Start(ctx, host))
+	defer func() { require.NoError(t, p2.Shutdown(ctx)) }()
+
+	assert.Equal(t, int64(3), p2.trackerCount.Load(), "two per-metric trackers and one attribute budget tracker")
+	assert.Equal(t, []topOffender{
+		{MetricName: "requests", LabelKey: "user_id", Delta: 50},
+		{MetricName: "requests", LabelKey: "region", Delta: 3},
+	}, p2.currentTopOffenders())
+
+	key := trackerKey{metricName: "requests", attrKey: "user_id"}
+	tr := p2.shardFor(key).trackers[key]
+	require.NotNil(t, tr)
+	assert.Equal(t, uint64(50), tr.cachedPrev)
+	assert.Equal(t, uint64(5), tr.current.Estimate())
+	assert.Equal(t, uint64(50), tr.previous.Estimate())
+
+	assert.Equal(t, 5, insertValues(p2, "requests", "user_id", 100, 160), "values seen this epoch before the restart still count")
+}
+
+// TestCheckpointRotatesStaleState verifies that a checkpoint older than an
+// epoch is rotated once on restore.
+func TestCheckpointRotatesStaleState(t *testing.T) {
+	host := storagetest.NewStorageHost().WithFileBackedStorageExtension("test", t.TempDir())
+	ctx := t.Context()
+
+	p1, _ := newBudgetTestProcessor(t, newCheckpointTestConfig())
+	require.NoError(t, p1.Start(ctx, host))
+	insertValues(p1, "requests", "user_id", 0, 50)
+	key := trackerKey{metricName: "requests", attrKey: "user_id"}
+	tc, err := p1.shardFor(key).trackers[key].checkpoint(key)
+	require.NoError(t, err)
+	require.NoError(t, p1.Shutdown(ctx))
+
+	// Rewrite the checkpoint as if it was taken an hour ago.
+	data, err := json.Marshal(shardCheckpoint{
+		Version:  checkpointVersion,
+		SavedAt:  time.Now().Add(-time.Hour),
+		Trackers: []trackerCheckpoint{tc},
+	})
+	require.NoError(t, err)
+	client, err := getStorageClient(ctx, host, newCheckpointTestConfig().Storage, component.NewID(component.MustNewType("cardinality_guardian")))
+	require.NoError(t, err)
+	for i := range numShards {
+		require.NoError(t, client.Delete(ctx, shardStorageKey(i)))
+	}
+	require.NoError(t, client.Set(ctx, shardStorageKey(0), data))
+	require.NoError(t, client.Close(ctx))
+
+	p2, _ := newBudgetTestProcessor(t, newCheckpointTestConfig())
+	require.NoError(t, p2.Start(ctx, host))
+	defer func() { require.NoError(t, p2.Shutdown(ctx)) }()
+
+	tr := p2.shardFor(key).trackers[key]
+	require.NotNil(t, tr)
+	assert.Equal(t, uint64(50), tr.cachedPrev, "the state before the restart is the baseline")
+	assert.Equal(t, uint64(0), tr.current.Estimate())
+	assert.Zero(t, insertValues(p2, "requests", "user_id", 0, 50), "known values are not new")
+}
+
+// TestCheckpointMaxTrackerCount verifies that restoring stops at
+// max_tracker_count.
+func TestCheckpointMaxTrackerCount(t *testing.T) {
+	host := storagetest.NewStorageHost().WithFileBackedStorageExtension("test", t.TempDir())
+	ctx := t.Context()
+
+	p1, _ := newBudgetTestProcessor(t, newCheckpointTestConfig())
+	require.NoError(t, p1.Start(ctx, host))
+	for i := range 10 {
+		insertValues(p1, fmt.Sprintf("metric_%d", i), "region", 0, 1)
+	}
+	require.NoError(t, p1.Shutdown(ctx))
+
+	cfg := newCheckpointTestConfig()
+	cfg.MaxTrackerCount = 4
+	p2, _ := newBudgetTestProcessor(t, cfg)
+	require.NoError(t, p2.Start(ctx, host))
+	defer func() { require.NoError(t, p2.Shutdown(ctx)) }()
+	assert.Equal(t, int64(4), p2.trackerCount.Load())
+}
+
+// TestCheckpointMissingStorage verifies that Start fails when the storage
+// extension does not exist.
+func TestCheckpointMissingStorage(t *testing.T) {
+	p, _ := newBudgetTestProcessor(t, newCheckpointTestConfig())
+	err := p.Start(t.Context(), storagetest.NewStorageHost())
+	assert.ErrorContains(t, err, "storage extension")
+}
+
+// countingClient counts the shard keys written to the wrapped client.
+type countingClient struct {
+	storage.Client
+	shardWrites int
+}
+
+func (c *countingClient) Set(ctx context.Context, key string, value []byte) error {
+	if strings.HasPrefix(key, "trackers_") {
+		c.shardWrites++
+	}
+	return c.Client.Set(ctx, key, value)
+}
+
+// TestCheckpointWritesDirtyShards verifies that only the shards that changed
+// since the last checkpoint are written.
+func TestCheckpointWritesDirtyShards(t *testing.T) {
+	ctx := t.Context()
+	p, _ := newBudgetTestProcessor(t, newCheckpointTestConfig())
+	client := &countingClient{Client: storagetest.NewInMemoryClient(component.KindProcessor, p.componentID, "")}
+	p.storageClient = client
+
+	// Every shard is rewritten after a restore, as trackers move across shards.
+	p.loadCheckpoint(ctx)
+	require.NoError(t, p.saveCheckpoint(ctx))
+	assert.Equal(t, numShards, client.shardWrites)
+
+	client.shardWrites = 0
+	require.NoError(t, p.saveCheckpoint(ctx))
+	assert.Zero(t, client.shardWrites, "nothing changed")
+
+	insertValues(p, "requests", "region", 0, 3)
+	require.NoError(t, p.saveCheckpoint(ctx))
+	assert.Equal(t, 1, client.shardWrites, "only the shard of the region tracker changed")
+
+	client.shardWrites = 0
+	p.rotate()
+	require.NoError(t, p.saveCheckpoint(ctx))
+	assert.Equal(t, 1, client.shardWrites, "rotation changes the non-empty shards only")
+}
+
+// TestCheckpointClosesStorageOnServerFailure verifies that the storage client
+// is closed when the top offenders server cannot start.
+func TestCheckpointClosesStorageOnServerFailure(t *testing.T) {
+	dir := t.TempDir()
+	host := storagetest.NewStorageHost().WithFileBackedStorageExtension("test", dir)
+	server := confighttp.NewDefaultServerConfig()
+	server.NetAddr.Endpoint = "localhost:invalid"
+	cfg := newCheckpointTestConfig()
+	cfg.HTTP = &server
+
+	p, _ := newBudgetTestProcessor(t, cfg)
+	require.ErrorContains(t, p.Start(t.Context(), host), "failed to start top offenders server")
+	assert.Nil(t, p.storageClient)
+
+	// The file backed client only writes its file when closed.
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
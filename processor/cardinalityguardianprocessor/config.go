@@ -8,6 +8,9 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/confighttp"
 )
 
 // Config defines the user-facing configuration for the cardinality_guardian
@@ -119,6 +122,28 @@ type Config struct {
 	// Each override value must be > 0.
 	MetricOverrides map[string]int `mapstructure:"metric_overrides"`
 
+	// AttributeBudgets caps the number of new unique values an attribute key
+	// may add per epoch across all metrics, keyed by attribute key. This
+	// catches a label that explodes on many metrics at once, e.g. a user_id
+	// added to every metric of a library, which per-metric limits miss because
+	// each metric only sees a fraction of the growth.
+	//
+	// Once exceeded, the attribute is handled according to EnforcementMode on
+	// every metric, regardless of the per-metric limit.
+	// Each budget value must be > 0.
+	AttributeBudgets map[string]int `mapstructure:"attribute_budgets"`
+
+	// ResourceBudgets caps, for every value of a resource attribute, the
+	// number of new unique values each attribute key may add per epoch across
+	// all metrics of that resource, keyed by resource attribute. For example
+	// {service.name: 1000} gives every service its own budget of 1000 new
+	// values per label key, so one misbehaving service is contained without
+	// tightening the limits of the others.
+	//
+	// Resources without the attribute are not subject to its budget.
+	// Each budget value must be > 0.
+	ResourceBudgets map[string]int `mapstructure:"resource_budgets"`
+
 	// DropLogMaxPerEpoch caps the number of "Dropping high-cardinality
 	// attribute" Warn logs emitted per epoch. After this many warnings,
 	// further drops are silently counted and a single summary line is
@@ -127,6 +152,30 @@ type Config struct {
 	// Set to 0 to disable the cap (log every drop — not recommended at scale).
 	// Must be ≥ 0.
 	DropLogMaxPerEpoch int `mapstructure:"drop_log_max_per_epoch"`
+
+	// Storage is the ID of a storage extension used to checkpoint the tracker
+	// sketches and the top offenders. The checkpoint is restored on start, so
+	// that a restart does not reset every tracker and let an epoch of new
+	// series through before enforcement resumes. It is written on shutdown
+	// and, optionally, periodically; see CheckpointIntervalSeconds.
+	//
+	// Optional — when unset the tracker state lives in memory only.
+	Storage *component.ID `mapstructure:"storage"`
+
+	// CheckpointIntervalSeconds is the interval between periodic checkpoints
+	// of the tracker state to Storage. Periodic checkpoints bound the state
+	// lost when the collector is killed without a clean shutdown.
+	//
+	// Set to 0 (default) to checkpoint on shutdown only.
+	// Must be ≥ 0. Requires storage to be set.
+	CheckpointIntervalSeconds int `mapstructure:"checkpoint_interval_seconds"`
+
+	// HTTP configures an HTTP server that serves the current top offenders
+	// and their estimated monthly cost, as an HTML page on GET /debug/cardinality
+	// and as JSON on GET /debug/cardinality/top_offenders.
+	//
+	// Optional — when unset no server is started.
+	HTTP *confighttp.ServerConfig `mapstructure:"http"`
 }
 
 // EnforcementMode determines how the processor handles attributes that exceed
@@ -187,9 +236,31 @@ func (c *Config) Validate() error {
 			return fmt.Errorf("metric_overrides[%q] must be greater than 0", name)
 		}
 	}
+	for key, limit := range c.AttributeBudgets {
+		if key == "" {
+			return errors.New("attribute_budgets contains an empty attribute key")
+		}
+		if limit <= 0 {
+			return fmt.Errorf("attribute_budgets[%q] must be greater than 0", key)
+		}
+	}
+	for key, limit := range c.ResourceBudgets {
+		if key == "" {
+			return errors.New("resource_budgets contains an empty resource attribute")
+		}
+		if limit <= 0 {
+			return fmt.Errorf("resource_budgets[%q] must be greater than 0", key)
+		}
+	}
 	if c.DropLogMaxPerEpoch < 0 {
 		return errors.New("drop_log_max_per_epoch must be >= 0")
 	}
+	if c.CheckpointIntervalSeconds < 0 {
+		return errors.New("checkpoint_interval_seconds must be >= 0")
+	}
+	if c.CheckpointIntervalSeconds > 0 && c.Storage == nil {
+		return errors.New("checkpoint_interval_seconds requires storage to be set")
+	}
 	if c.EnforcementMode != "" {
 		normalized := EnforcementMode(strings.ToLower(string(c.EnforcementMode)))
 		switch normalized {
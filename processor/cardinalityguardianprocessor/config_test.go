@@ -7,9 +7,11 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/collector/component"
 )
 
 func TestConfig_Validate(t *testing.T) {
+	storageID := component.MustNewID("file_storage")
 	tests := []struct {
 		name        string
 		cfg         *Config
@@ -107,6 +109,42 @@ func TestConfig_Validate(t *testing.T) {
 			},
 			expectedErr: "metric_overrides[\"http.request\"] must be greater than 0",
 		},
+		{
+			name: "invalid attribute_budgets empty key",
+			cfg: &Config{
+				MaxCardinalityDeltaPerEpoch: 50,
+				EpochDurationSeconds:        300,
+				AttributeBudgets:            map[string]int{"": 100},
+			},
+			expectedErr: "attribute_budgets contains an empty attribute key",
+		},
+		{
+			name: "invalid attribute_budgets zero limit",
+			cfg: &Config{
+				MaxCardinalityDeltaPerEpoch: 50,
+				EpochDurationSeconds:        300,
+				AttributeBudgets:            map[string]int{"user_id": 0},
+			},
+			expectedErr: "attribute_budgets[\"user_id\"] must be greater than 0",
+		},
+		{
+			name: "invalid resource_budgets empty key",
+			cfg: &Config{
+				MaxCardinalityDeltaPerEpoch: 50,
+				EpochDurationSeconds:        300,
+				ResourceBudgets:             map[string]int{"": 100},
+			},
+			expectedErr: "resource_budgets contains an empty resource attribute",
+		},
+		{
+			name: "invalid resource_budgets negative limit",
+			cfg: &Config{
+				MaxCardinalityDeltaPerEpoch: 50,
+				EpochDurationSeconds:        300,
+				ResourceBudgets:             map[string]int{"service.name": -1},
+			},
+			expectedErr: "resource_budgets[\"service.name\"] must be greater than 0",
+		},
 		{
 			name: "invalid drop_log_max_per_epoch",
 			cfg: &Config{
@@ -116,6 +154,34 @@ func TestConfig_Validate(t *testing.T) {
 			},
 			expectedErr: "drop_log_max_per_epoch must be >= 0",
 		},
+		{
+			name: "invalid checkpoint_interval_seconds",
+			cfg: &Config{
+				MaxCardinalityDeltaPerEpoch: 50,
+				EpochDurationSeconds:        300,
+				Storage:                     &storageID,
+				CheckpointIntervalSeconds:   -1,
+			},
+			expectedErr: "checkpoint_interval_seconds must be >= 0",
+		},
+		{
+			name: "checkpoint_interval_seconds without storage",
+			cfg: &Config{
+				MaxCardinalityDeltaPerEpoch: 50,
+				EpochDurationSeconds:        300,
+				CheckpointIntervalSeconds:   60,
+			},
+			expectedErr: "checkpoint_interval_seconds requires storage to be set",
+		},
+		{
+			name: "valid checkpoint config",
+			cfg: &Config{
+				MaxCardinalityDeltaPerEpoch: 50,
+				EpochDurationSeconds:        300,
+				Storage:                     &storageID,
+				CheckpointIntervalSeconds:   60,
+			},
+		},
 	}
 
 	for _, tt := range tests {
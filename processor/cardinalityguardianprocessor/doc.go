@@ -5,8 +5,8 @@
 
 // Package cardinalityguardianprocessor enforces per-metric-per-label
 // cardinality limits using HyperLogLog++ sketches. It is a drop-in pipeline
-// component with zero web-server footprint — the binary exports no HTTP
-// endpoints.
+// component; it exports no HTTP endpoints unless the optional top offenders
+// server is configured.
 //
 // # Problem
 //
@@ -25,20 +25,30 @@
 // configured EnforcementMode (EnforcementTagOnly, EnforcementOverflowAttribute,
 // or EnforcementStripAndReaggregate).
 //
+// AttributeBudgets and ResourceBudgets add trackers that span metrics: one per
+// label key across all metrics, and one per label key across all metrics of a
+// resource (e.g. per service.name value). An attribute is enforced when any
+// tracker that applies to it exceeds its limit.
+//
 // # Architecture
 //
 //	ConsumeMetrics (hot path, called concurrently by the Collector)
-//	  └─ handleAttributes           (per data point)
-//	        └─ shouldDrop           (per label key/value pair)
+//	  └─ handleAttributesWithMode   (per data point)
+//	        └─ shouldDropInScopes   (per label key/value pair)
 //	              ├─ hashAttrValue       — type-dispatched, zero-alloc Str case
-//	              ├─ getShard            — maphash routing to 1/256 of key space
-//	              ├─ shard.mu RLock      — fast path when tracker already exists
-//	              └─ tracker.insert     — HLL InsertHash + lazy cached estimate
+//	              └─ exceeds             (per-metric, attribute and resource trackers)
+//	                    ├─ shardFor          — maphash routing to 1/256 of key space
+//	                    ├─ shard.mu RLock    — fast path when tracker already exists
+//	                    └─ tracker.insert   — HLL InsertHash + lazy cached estimate
 //
 //	Background goroutine (one per processor lifetime, started in Start)
 //	  └─ rotate — every EpochDurationSeconds, advances the sliding window
 //	               across all 256 shards, one shard at a time
 //
+//	Checkpoint goroutine (only with Storage and CheckpointIntervalSeconds)
+//	  └─ saveCheckpoint — every CheckpointIntervalSeconds, and on Shutdown,
+//	                      writes each shard's sketches under its own key
+//
 // Hot-path design rationale lives on the named identifiers themselves — see
 // numShards, hashAttrValue, sketchPool, estimateInterval, and tracker.insert.
 package cardinalityguardianprocessor // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/cardinalityguardianprocessor"
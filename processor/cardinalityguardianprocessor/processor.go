@@ -5,7 +5,11 @@ package cardinalityguardianprocessor // import "github.com/open-telemetry/opente
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"hash/maphash"
+	"net/http"
+	"slices"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -13,6 +17,7 @@ import (
 	"github.com/axiomhq/hyperloglog"
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/extension/xextension/storage"
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/pmetric"
 	"go.opentelemetry.io/collector/processor"
@@ -49,9 +54,31 @@ func mustGetSketch() *hyperloglog.Sketch {
 // Using a struct key avoids the heap allocation that string concatenation
 // (e.g. metricName + ":" + attrKey) would cause in the hot path: Go can hash
 // a struct key inline without allocating a temporary string.
+//
+// Budget trackers leave metricName empty: attribute budget trackers track an
+// attribute key across all metrics, resource budget trackers across all
+// metrics of the resource identified by resource.
 type trackerKey struct {
 	metricName string
 	attrKey    string
+	resource   string // "<resource attribute>=<value>", resource budgets only
+	budget     budgetKind
+}
+
+// budgetKind tells apart the per-metric trackers from the budget trackers.
+type budgetKind uint8
+
+const (
+	budgetNone budgetKind = iota
+	budgetAttribute
+	budgetResource
+)
+
+// resourceScope is a resource budget that applies to the data points of one
+// resource, e.g. "service.name=checkout" with the service.name limit.
+type resourceScope struct {
+	resource string
+	limit    uint64
 }
 
 // estimateInterval is how often Sketch.Estimate() is recomputed in the hot
@@ -144,6 +171,17 @@ type trackerEntry struct {
 type trackerShard struct {
 	mu       sync.RWMutex
 	trackers map[trackerKey]*tracker
+	// dirty is set when the shard changed since its last checkpoint, so that
+	// unchanged shards are not written again.
+	dirty atomic.Bool
+}
+
+// markDirty flags the shard for the next checkpoint. The flag is loaded first
+// so that the hot path does not write the shared cache line on every insert.
+func (s *trackerShard) markDirty() {
+	if !s.dirty.Load() {
+		s.dirty.Store(true)
+	}
 }
 
 type cardinalityProcessor struct {
@@ -159,6 +197,9 @@ type cardinalityProcessor struct {
 	// enforcementMode mirrors config.resolvedEnforcementMode() to skip the
 	// method call on every data point.
 	enforcementMode EnforcementMode
+	// resourceBudgetKeys is the sorted list of ResourceBudgets keys, so the
+	// scopes of a resource are built in a stable order.
+	resourceBudgetKeys []string
 
 	labelsStripped   atomic.Int64
 	trackerCount     atomic.Int64
@@ -175,8 +216,16 @@ type cardinalityProcessor struct {
 	topOffenders   []offenderEntry
 	topOffendersMu sync.RWMutex
 
-	// wg tracks the rotation goroutine so Shutdown blocks until it exits.
+	// wg tracks the rotation and checkpoint goroutines so Shutdown blocks
+	// until they exit.
 	wg sync.WaitGroup
+
+	componentID       component.ID
+	telemetrySettings component.TelemetrySettings
+	storageClient     storage.Client
+
+	server     *http.Server
+	serverDone sync.WaitGroup
 }
 
 // newCardinalityProcessor constructs a cardinalityProcessor and registers its
@@ -198,7 +247,14 @@ func newCardinalityProcessor(_ context.Context, cfg *Config, set processor.Setti
 		seed:            maphash.MakeSeed(),
 		cancel:          cancel,
 		enforcementMode: cfg.resolvedEnforcementMode(),
+
+		componentID:       set.ID,
+		telemetrySettings: set.TelemetrySettings,
+	}
+	for k := range cfg.ResourceBudgets {
+		p.resourceBudgetKeys = append(p.resourceBudgetKeys, k)
 	}
+	slices.Sort(p.resourceBudgetKeys)
 
 	for i := range p.shards {
 		p.shards[i] = &trackerShard{
@@ -280,6 +336,31 @@ func (p *cardinalityProcessor) getShard(metricName string) *trackerShard {
 	return p.shards[maphash.String(p.seed, metricName)&(numShards-1)]
 }
 
+// shardFor routes a tracker key to its shard: per-metric trackers by metric
+// name, budget trackers by attribute key and resource.
+func (p *cardinalityProcessor) shardFor(key trackerKey) *trackerShard {
+	if key.budget == budgetNone {
+		return p.getShard(key.metricName)
+	}
+	h := maphash.String(p.seed, key.attrKey) ^ maphash.String(p.seed, key.resource)
+	return p.shards[h&(numShards-1)]
+}
+
+// resourceScopes returns the resource budgets that apply to the data points
+// of a resource, or nil if none is configured.
+func (p *cardinalityProcessor) resourceScopes(res pcommon.Map) []resourceScope {
+	var scopes []resourceScope
+	for _, k := range p.resourceBudgetKeys {
+		if v, ok := res.Get(k); ok {
+			scopes = append(scopes, resourceScope{
+				resource: k + "=" + v.AsString(),
+				limit:    uint64(p.config.ResourceBudgets[k]),
+			})
+		}
+	}
+	return scopes
+}
+
 // Capabilities tells the Collector that this processor mutates the data it
 // receives. Setting MutatesData: true causes the Collector to clone the metric
 // batch before passing it to this processor, ensuring that modifications to
@@ -297,13 +378,14 @@ func (p *cardinalityProcessor) ConsumeMetrics(ctx context.Context, md pmetric.Me
 	rm := md.ResourceMetrics()
 	for i := 0; i < rm.Len(); i++ {
 		resMetrics := rm.At(i)
+		scopes := p.resourceScopes(resMetrics.Resource().Attributes())
 		sm := resMetrics.ScopeMetrics()
 		for j := 0; j < sm.Len(); j++ {
 			scopeMetrics := sm.At(j)
 			ms := scopeMetrics.Metrics()
 			for k := 0; k < ms.Len(); k++ {
 				// Process each metric
-				p.processMetric(ms.At(k))
+				p.processMetric(ms.At(k), scopes)
 			}
 		}
 	}
@@ -319,12 +401,14 @@ func (p *cardinalityProcessor) ConsumeMetrics(ctx context.Context, md pmetric.Me
 // spatial reaggregation is performed after attribute mutation for supported
 // metric types (Delta Sum and Gauge). Unsupported metric types (Cumulative Sum,
 // Histogram, ExponentialHistogram, Summary) fall back to tag_only behavior.
-func (p *cardinalityProcessor) processMetric(m pmetric.Metric) {
+//
+// scopes are the resource budgets that apply to the metric's resource.
+func (p *cardinalityProcessor) processMetric(m pmetric.Metric, scopes []resourceScope) {
 	reaggMode := p.enforcementMode == EnforcementStripAndReaggregate || p.enforcementMode == EnforcementOverflowAttribute
 
 	switch m.Type() {
 	case pmetric.MetricTypeGauge:
-		p.processNumberDataPoints(m.Name(), m.Gauge().DataPoints())
+		p.processNumberDataPoints(m.Name(), m.Gauge().DataPoints(), scopes)
 		if reaggMode {
 			reaggregateNumberDataPoints(m.Gauge().DataPoints(), pmetric.MetricTypeGauge, false)
 		}
@@ -333,9 +417,9 @@ func (p *cardinalityProcessor) processMetric(m pmetric.Metric) {
 		if reaggMode && !isDelta {
 			// Cumulative Sums are not yet supported for reaggregation.
 			// Fall back to tag_only behavior for this specific metric to avoid collisions.
-			p.processNumberDataPointsWithMode(m.Name(), m.Sum().DataPoints(), EnforcementTagOnly)
+			p.processNumberDataPointsWithMode(m.Name(), m.Sum().DataPoints(), scopes, EnforcementTagOnly)
 		} else {
-			p.processNumberDataPoints(m.Name(), m.Sum().DataPoints())
+			p.processNumberDataPoints(m.Name(), m.Sum().DataPoints(), scopes)
 			if reaggMode && isDelta {
 				reaggregateNumberDataPoints(m.Sum().DataPoints(), pmetric.MetricTypeSum, true)
 			}
@@ -343,55 +427,55 @@ func (p *cardinalityProcessor) processMetric(m pmetric.Metric) {
 	case pmetric.MetricTypeHistogram:
 		if reaggMode {
 			// Histograms are not yet supported for reaggregation.
-			p.processHistogramDataPointsWithMode(m.Name(), m.Histogram().DataPoints(), EnforcementTagOnly)
+			p.processHistogramDataPointsWithMode(m.Name(), m.Histogram().DataPoints(), scopes, EnforcementTagOnly)
 		} else {
-			p.processHistogramDataPoints(m.Name(), m.Histogram().DataPoints())
+			p.processHistogramDataPoints(m.Name(), m.Histogram().DataPoints(), scopes)
 		}
 	case pmetric.MetricTypeExponentialHistogram:
 		if reaggMode {
-			p.processExponentialHistogramDataPointsWithMode(m.Name(), m.ExponentialHistogram().DataPoints(), EnforcementTagOnly)
+			p.processExponentialHistogramDataPointsWithMode(m.Name(), m.ExponentialHistogram().DataPoints(), scopes, EnforcementTagOnly)
 		} else {
-			p.processExponentialHistogramDataPoints(m.Name(), m.ExponentialHistogram().DataPoints())
+			p.processExponentialHistogramDataPoints(m.Name(), m.ExponentialHistogram().DataPoints(), scopes)
 		}
 	case pmetric.MetricTypeSummary:
 		if reaggMode {
-			p.processSummaryDataPointsWithMode(m.Name(), m.Summary().DataPoints(), EnforcementTagOnly)
+			p.processSummaryDataPointsWithMode(m.Name(), m.Summary().DataPoints(), scopes, EnforcementTagOnly)
 		} else {
-			p.processSummaryDataPoints(m.Name(), m.Summary().DataPoints())
+			p.processSummaryDataPoints(m.Name(), m.Summary().DataPoints(), scopes)
 		}
 	}
 }
 
 // processNumberDataPoints iterates over a NumberDataPointSlice and calls
-// handleAttributes for each data point. Both Gauge and Sum metric types use
+// handleAttributesWithMode for each data point. Both Gauge and Sum metric types use
 // this slice type, so a single method covers both.
-func (p *cardinalityProcessor) processNumberDataPoints(metricName string, dps pmetric.NumberDataPointSlice) {
+func (p *cardinalityProcessor) processNumberDataPoints(metricName string, dps pmetric.NumberDataPointSlice, scopes []resourceScope) {
 	for i := 0; i < dps.Len(); i++ {
-		p.handleAttributes(metricName, dps.At(i).Attributes())
+		p.handleAttributesWithMode(metricName, dps.At(i).Attributes(), scopes, p.enforcementMode)
 	}
 }
 
 // processHistogramDataPoints iterates over a HistogramDataPointSlice and calls
-// handleAttributes for each data point.
-func (p *cardinalityProcessor) processHistogramDataPoints(metricName string, dps pmetric.HistogramDataPointSlice) {
+// handleAttributesWithMode for each data point.
+func (p *cardinalityProcessor) processHistogramDataPoints(metricName string, dps pmetric.HistogramDataPointSlice, scopes []resourceScope) {
 	for i := 0; i < dps.Len(); i++ {
-		p.handleAttributes(metricName, dps.At(i).Attributes())
+		p.handleAttributesWithMode(metricName, dps.At(i).Attributes(), scopes, p.enforcementMode)
 	}
 }
 
 // processExponentialHistogramDataPoints iterates over an ExponentialHistogramDataPointSlice
-// and calls handleAttributes for each data point.
-func (p *cardinalityProcessor) processExponentialHistogramDataPoints(metricName string, dps pmetric.ExponentialHistogramDataPointSlice) {
+// and calls handleAttributesWithMode for each data point.
+func (p *cardinalityProcessor) processExponentialHistogramDataPoints(metricName string, dps pmetric.ExponentialHistogramDataPointSlice, scopes []resourceScope) {
 	for i := 0; i < dps.Len(); i++ {
-		p.handleAttributes(metricName, dps.At(i).Attributes())
+		p.handleAttributesWithMode(metricName, dps.At(i).Attributes(), scopes, p.enforcementMode)
 	}
 }
 
 // processSummaryDataPoints iterates over a SummaryDataPointSlice and calls
-// handleAttributes for each data point.
-func (p *cardinalityProcessor) processSummaryDataPoints(metricName string, dps pmetric.SummaryDataPointSlice) {
+// handleAttributesWithMode for each data point.
+func (p *cardinalityProcessor) processSummaryDataPoints(metricName string, dps pmetric.SummaryDataPointSlice, scopes []resourceScope) {
 	for i := 0; i < dps.Len(); i++ {
-		p.handleAttributes(metricName, dps.At(i).Attributes())
+		p.handleAttributesWithMode(metricName, dps.At(i).Attributes(), scopes, p.enforcementMode)
 	}
 }
 
@@ -401,13 +485,14 @@ func (p *cardinalityProcessor) processSummaryDataPoints(metricName string, dps p
 // reallocate the pdata KeyValueList while RemoveIf still holds its internal
 // cursor.
 func (p *cardinalityProcessor) handleAttributes(metricName string, attrs pcommon.Map) {
-	p.handleAttributesWithMode(metricName, attrs, p.enforcementMode)
+	p.handleAttributesWithMode(metricName, attrs, nil, p.enforcementMode)
 }
 
 // handleAttributesWithMode is the mode-parameterized version of handleAttributes.
 // It allows callers (like processMetric) to override the enforcement mode for
-// specific metric types that don't support reaggregation.
-func (p *cardinalityProcessor) handleAttributesWithMode(metricName string, attrs pcommon.Map, mode EnforcementMode) {
+// specific metric types that don't support reaggregation. scopes are the
+// resource budgets that apply to the data point's resource.
+func (p *cardinalityProcessor) handleAttributesWithMode(metricName string, attrs pcommon.Map, scopes []resourceScope, mode EnforcementMode) {
 	// shouldTag is set when tag_only mode decides an attribute should be tagged.
 	// overflowKeys collects keys whose values should be replaced with the sentinel.
 	// Both are deferred until after RemoveIf completes to avoid mutating the map
@@ -420,7 +505,7 @@ func (p *cardinalityProcessor) handleAttributesWithMode(metricName string, attrs
 			return false
 		}
 
-		if p.shouldDrop(metricName, k, v) {
+		if p.shouldDropInScopes(metricName, k, v, scopes) {
 			switch mode {
 			case EnforcementTagOnly:
 				// DUAL-ROUTE MODE: record the decision, keep the attribute.
@@ -479,33 +564,33 @@ func (p *cardinalityProcessor) handleAttributesWithMode(metricName string, attrs
 // processNumberDataPointsWithMode processes data points with an overridden
 // enforcement mode. Used when the metric type doesn't support the configured
 // mode (e.g., Cumulative Sums falling back to tag_only).
-func (p *cardinalityProcessor) processNumberDataPointsWithMode(metricName string, dps pmetric.NumberDataPointSlice, mode EnforcementMode) {
+func (p *cardinalityProcessor) processNumberDataPointsWithMode(metricName string, dps pmetric.NumberDataPointSlice, scopes []resourceScope, mode EnforcementMode) {
 	for i := 0; i < dps.Len(); i++ {
-		p.handleAttributesWithMode(metricName, dps.At(i).Attributes(), mode)
+		p.handleAttributesWithMode(metricName, dps.At(i).Attributes(), scopes, mode)
 	}
 }
 
 // processHistogramDataPointsWithMode processes histogram data points with an
 // overridden enforcement mode.
-func (p *cardinalityProcessor) processHistogramDataPointsWithMode(metricName string, dps pmetric.HistogramDataPointSlice, mode EnforcementMode) {
+func (p *cardinalityProcessor) processHistogramDataPointsWithMode(metricName string, dps pmetric.HistogramDataPointSlice, scopes []resourceScope, mode EnforcementMode) {
 	for i := 0; i < dps.Len(); i++ {
-		p.handleAttributesWithMode(metricName, dps.At(i).Attributes(), mode)
+		p.handleAttributesWithMode(metricName, dps.At(i).Attributes(), scopes, mode)
 	}
 }
 
 // processExponentialHistogramDataPointsWithMode processes exponential histogram
 // data points with an overridden enforcement mode.
-func (p *cardinalityProcessor) processExponentialHistogramDataPointsWithMode(metricName string, dps pmetric.ExponentialHistogramDataPointSlice, mode EnforcementMode) {
+func (p *cardinalityProcessor) processExponentialHistogramDataPointsWithMode(metricName string, dps pmetric.ExponentialHistogramDataPointSlice, scopes []resourceScope, mode EnforcementMode) {
 	for i := 0; i < dps.Len(); i++ {
-		p.handleAttributesWithMode(metricName, dps.At(i).Attributes(), mode)
+		p.handleAttributesWithMode(metricName, dps.At(i).Attributes(), scopes, mode)
 	}
 }
 
 // processSummaryDataPointsWithMode processes summary data points with an
 // overridden enforcement mode.
-func (p *cardinalityProcessor) processSummaryDataPointsWithMode(metricName string, dps pmetric.SummaryDataPointSlice, mode EnforcementMode) {
+func (p *cardinalityProcessor) processSummaryDataPointsWithMode(metricName string, dps pmetric.SummaryDataPointSlice, scopes []resourceScope, mode EnforcementMode) {
 	for i := 0; i < dps.Len(); i++ {
-		p.handleAttributesWithMode(metricName, dps.At(i).Attributes(), mode)
+		p.handleAttributesWithMode(metricName, dps.At(i).Attributes(), scopes, mode)
 	}
 }
 
@@ -562,6 +647,9 @@ func (p *cardinalityProcessor) rotate() {
 		// Collect keys of trackers that have been idle for staleSweepEpochs
 		// consecutive rotations.
 		staleKeys := make([]trackerKey, 0, len(entries))
+		if len(entries) > 0 {
+			shard.markDirty()
+		}
 		for i, e := range entries {
 			idle := e.t.rotate(fresh[i])
 			if idle && e.t.idleEpochs >= staleSweepEpochs {
@@ -612,6 +700,10 @@ func collectShardDeltas(entries []trackerEntry, topBuf []offenderEntry, topN int
 		return topBuf
 	}
 	for _, e := range entries {
+		if e.key.budget != budgetNone {
+			// Top offenders are reported per (metric, label) pair.
+			continue
+		}
 		e.t.mu.Lock()
 		curr, prev := e.t.cachedCurr, e.t.cachedPrev
 		e.t.mu.Unlock()
@@ -689,10 +781,29 @@ func sortOffenders(s []offenderEntry) {
 }
 
 // Start is called by the OTel Collector host when the pipeline is starting.
-// It launches the background epoch-rotation goroutine and returns immediately.
-// The goroutine exits when the internal cancel function is called, which
-// happens in Shutdown().
-func (p *cardinalityProcessor) Start(_ context.Context, _ component.Host) error {
+// It restores the tracker checkpoint when storage is configured, starts the
+// top offenders server when configured, and launches the background
+// epoch-rotation and checkpoint goroutines. The goroutines exit when the
+// internal cancel function is called, which happens in Shutdown().
+func (p *cardinalityProcessor) Start(ctx context.Context, host component.Host) error {
+	if p.config.Storage != nil {
+		var err error
+		p.storageClient, err = getStorageClient(ctx, host, p.config.Storage, p.componentID)
+		if err != nil {
+			return fmt.Errorf("failed to get storage client: %w", err)
+		}
+		p.loadCheckpoint(ctx)
+	}
+	if p.config.HTTP != nil {
+		if err := p.startServer(ctx, host); err != nil {
+			if p.storageClient != nil {
+				_ = p.storageClient.Close(ctx)
+				p.storageClient = nil
+			}
+			return fmt.Errorf("failed to start top offenders server: %w", err)
+		}
+	}
+
 	// Create a local cancelable context rooted in context.Background()
 	// to ensure it is not affected by the ephemeral startup context.
 	childCtx, cancel := context.WithCancel(context.Background())
@@ -704,6 +815,11 @@ func (p *cardinalityProcessor) Start(_ context.Context, _ component.Host) error
 	p.wg.Go(func() {
 		p.rotationLoop(childCtx)
 	})
+	if p.storageClient != nil && p.config.CheckpointIntervalSeconds > 0 {
+		p.wg.Go(func() {
+			p.checkpointLoop(childCtx)
+		})
+	}
 
 	return nil
 }
@@ -735,35 +851,78 @@ func (p *cardinalityProcessor) rotationLoop(ctx context.Context) {
 //     A nil guard is included for safety (e.g. if construction failed after the
 //     counter registrations but before RegisterCallback).
 //
-//  2. Cancel the child context. This signals the background ticker goroutine to
-//     exit on its next select iteration, stopping epoch rotations cleanly.
-func (p *cardinalityProcessor) Shutdown(_ context.Context) error {
+//  2. Cancel the child context. This signals the background ticker goroutines
+//     to exit on their next select iteration, stopping epoch rotations cleanly.
+//
+//  3. Stop the top offenders server, write a final checkpoint and close the
+//     storage client, when configured.
+func (p *cardinalityProcessor) Shutdown(ctx context.Context) error {
 	p.logger.Info("Shutting down cardinality processor")
 	if p.telemetry != nil {
 		p.telemetry.Shutdown()
 	}
 	p.cancel()
 	p.wg.Wait()
-	return nil
+
+	var errs []error
+	if p.server != nil {
+		if err := p.server.Shutdown(ctx); err != nil {
+			errs = append(errs, err)
+		}
+		p.serverDone.Wait()
+	}
+	if p.storageClient != nil {
+		if err := p.saveCheckpoint(ctx); err != nil {
+			p.logger.Warn("Final checkpoint failed", zap.Error(err))
+			errs = append(errs, err)
+		}
+		if err := p.storageClient.Close(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
 }
 
 // shouldDrop returns true when the unique-value count for (metricName, attrKey)
 // has grown by more than MaxCardinalityDeltaPerEpoch since the last epoch
-// rotation. The fast path is a shard RLock; a missed tracker triggers
-// double-checked locking to install one. curr ≤ prev is treated as no growth
-// to guard against uint64 underflow from HLL variance near sketch boundaries.
+// rotation, or when the attribute budget of attrKey is exceeded. See
+// shouldDropInScopes.
 func (p *cardinalityProcessor) shouldDrop(metricName, attrKey string, attrVal pcommon.Value) bool {
-	key := trackerKey{metricName: metricName, attrKey: attrKey}
+	return p.shouldDropInScopes(metricName, attrKey, attrVal, nil)
+}
 
+// shouldDropInScopes returns true when any budget that applies to the
+// attribute is exceeded: the per-metric limit of (metricName, attrKey), the
+// attribute budget of attrKey across all metrics, or the budget of attrKey in
+// any of the resource scopes. The value is inserted into every applicable
+// tracker, even once one of them is exceeded, so every estimate stays
+// accurate.
+func (p *cardinalityProcessor) shouldDropInScopes(metricName, attrKey string, attrVal pcommon.Value, scopes []resourceScope) bool {
 	// Hash the attribute value before acquiring any lock. hashAttrValue keeps
 	// Str/Int/Double/Bool/Bytes on a zero-allocation path; Map/Slice fall back
 	// to AsString (JSON-marshal).
 	hashVal := hashAttrValue(attrVal)
 
+	drop := p.exceeds(trackerKey{metricName: metricName, attrKey: attrKey}, hashVal, p.getLimit(metricName))
+	if limit, ok := p.config.AttributeBudgets[attrKey]; ok {
+		drop = p.exceeds(trackerKey{attrKey: attrKey, budget: budgetAttribute}, hashVal, uint64(limit)) || drop
+	}
+	for _, scope := range scopes {
+		drop = p.exceeds(trackerKey{attrKey: attrKey, resource: scope.resource, budget: budgetResource}, hashVal, scope.limit) || drop
+	}
+	return drop
+}
+
+// exceeds inserts hashVal into the tracker for key and reports whether its
+// unique-value count has grown by more than limit since the last epoch
+// rotation. The fast path is a shard RLock; a missed tracker triggers
+// double-checked locking to install one. curr ≤ prev is treated as no growth
+// to guard against uint64 underflow from HLL variance near sketch boundaries.
+func (p *cardinalityProcessor) exceeds(key trackerKey, hashVal, limit uint64) bool {
 	// Route to 1/256th of the total key space.
-	shard := p.getShard(metricName)
+	shard := p.shardFor(key)
 
-	// Phase 1: read lock — fast path for already-tracked metric+label pairs.
+	// Phase 1: read lock — fast path for already-tracked keys.
 	shard.mu.RLock()
 	t, ok := shard.trackers[key]
 	shard.mu.RUnlock()
@@ -794,6 +953,7 @@ func (p *cardinalityProcessor) shouldDrop(metricName, attrKey string, attrVal pc
 	// HLL insert and estimate happen under the per-tracker lock, completely
 	// independent of the shard lock.
 	currCount, prevCount := t.insert(hashVal)
+	shard.markDirty()
 
 	// Guard against uint64 underflow caused by HLL probabilistic estimation
 	// variance — if the current count has not grown, nothing should be dropped.
@@ -801,7 +961,7 @@ func (p *cardinalityProcessor) shouldDrop(metricName, attrKey string, attrVal pc
 		return false
 	}
 
-	return (currCount - prevCount) > p.getLimit(metricName)
+	return (currCount - prevCount) > limit
 }
 
 // getLimit returns the per-metric cardinality limit for the given metric name,
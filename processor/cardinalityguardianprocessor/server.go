@@ -0,0 +1,133 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package cardinalityguardianprocessor // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/cardinalityguardianprocessor"
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strconv"
+
+	"go.opentelemetry.io/collector/component"
+	"go.uber.org/zap"
+)
+
+const (
+	// pagePath serves the top offenders as an HTML page, in the style of the
+	// zpages extension.
+	pagePath = "/debug/cardinality"
+	// topOffendersPath serves the top offenders as JSON.
+	topOffendersPath = "/debug/cardinality/top_offenders"
+)
+
+// topOffender is the JSON form of an offenderEntry.
+type topOffender struct {
+	MetricName string `json:"metric_name"`
+	LabelKey   string `json:"label_key"`
+	Delta      uint64 `json:"delta"`
+	// EstimatedMonthlyCost is Delta times estimated_cost_per_metric_month:
+	// the monthly cost of the new series the label added in the last epoch.
+	EstimatedMonthlyCost float64 `json:"estimated_monthly_cost"`
+}
+
+// topOffendersReport is the document served on topOffendersPath.
+type topOffendersReport struct {
+	EpochDurationSeconds        int           `json:"epoch_duration_seconds"`
+	EstimatedCostPerMetricMonth float64       `json:"estimated_cost_per_metric_month"`
+	ActiveTrackers              int64         `json:"active_trackers"`
+	LabelsStripped              int64         `json:"labels_stripped"`
+	EstimatedSavingsDollars     float64       `json:"estimated_savings_dollars"`
+	TopOffenders                []topOffender `json:"top_offenders"`
+}
+
+// currentTopOffenders returns the top offenders of the last rotation with
+// their estimated monthly cost.
+func (p *cardinalityProcessor) currentTopOffenders() []topOffender {
+	p.topOffendersMu.RLock()
+	defer p.topOffendersMu.RUnlock()
+	offenders := make([]topOffender, 0, len(p.topOffenders))
+	for _, o := range p.topOffenders {
+		offenders = append(offenders, topOffender{
+			MetricName:           o.metricName,
+			LabelKey:             o.labelKey,
+			Delta:                o.delta,
+			EstimatedMonthlyCost: float64(o.delta) * p.config.EstimatedCostPerMetricMonth,
+		})
+	}
+	return offenders
+}
+
+func (p *cardinalityProcessor) buildReport() topOffendersReport {
+	stripped := p.labelsStripped.Load()
+	return topOffendersReport{
+		EpochDurationSeconds:        p.config.EpochDurationSeconds,
+		EstimatedCostPerMetricMonth: p.config.EstimatedCostPerMetricMonth,
+		ActiveTrackers:              p.trackerCount.Load(),
+		LabelsStripped:              stripped,
+		EstimatedSavingsDollars:     float64(stripped) * p.config.EstimatedCostPerMetricMonth,
+		TopOffenders:                p.currentTopOffenders(),
+	}
+}
+
+// startServer starts the HTTP server serving the top offenders.
+func (p *cardinalityProcessor) startServer(ctx context.Context, host component.Host) error {
+	cfg := p.config.HTTP
+	ln, err := cfg.ToListener(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to bind to address %s: %w", cfg.NetAddr.Endpoint, err)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+pagePath, p.handlePage)
+	mux.HandleFunc("GET "+topOffendersPath, p.handleTopOffenders)
+	p.server, err = cfg.ToServer(ctx, host.GetExtensions(), p.telemetrySettings, mux)
+	if err != nil {
+		_ = ln.Close()
+		return err
+	}
+
+	p.serverDone.Go(func() {
+		if err := p.server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			p.logger.Error("Top offenders server failed", zap.Error(err))
+		}
+	})
+	return nil
+}
+
+func (p *cardinalityProcessor) handleTopOffenders(w http.ResponseWriter, _ *http.Request) {
+	data, err := json.Marshal(p.buildReport())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	_, _ = w.Write(data)
+}
+
+var pageTemplate = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html>
+<head><title>Cardinality Guardian</title></head>
+<body>
+<h1>Cardinality Guardian</h1>
+<p>Active trackers: {{.ActiveTrackers}}. Labels stripped: {{.LabelsStripped}}.
+Estimated savings: ${{printf "%.2f" .EstimatedSavingsDollars}}.</p>
+<h2>Top offenders of the last {{.EpochDurationSeconds}}s epoch</h2>
+<table border="1" cellpadding="4">
+<tr><th>Metric</th><th>Label</th><th>New values</th><th>Estimated cost / month (${{.EstimatedCostPerMetricMonth}} per series)</th></tr>
+{{range .TopOffenders}}<tr><td>{{.MetricName}}</td><td>{{.LabelKey}}</td><td>{{.Delta}}</td><td>${{printf "%.2f" .EstimatedMonthlyCost}}</td></tr>
+{{else}}<tr><td colspan="4">No offenders.</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+func (p *cardinalityProcessor) handlePage(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := pageTemplate.Execute(w, p.buildReport()); err != nil {
+		p.logger.Debug("Failed to render top offenders page", zap.Error(err))
+	}
+}
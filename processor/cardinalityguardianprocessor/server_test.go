@@ -0,0 +1,64 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package cardinalityguardianprocessor
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config/confighttp"
+)
+
+// TestTopOffendersServer verifies the top offenders served over HTTP with
+// their estimated cost.
+func TestTopOffendersServer(t *testing.T) {
+	server := confighttp.NewDefaultServerConfig()
+	server.NetAddr.Endpoint = "localhost:0"
+	p, _ := newBudgetTestProcessor(t, &Config{
+		MaxCardinalityDeltaPerEpoch: 10,
+		EpochDurationSeconds:        300,
+		TopOffendersCount:           5,
+		EnforcementMode:             EnforcementStripAndReaggregate,
+		EstimatedCostPerMetricMonth: 0.5,
+		HTTP:                        &server,
+	})
+	require.NoError(t, p.Start(t.Context(), componenttest.NewNopHost()))
+	defer func() { require.NoError(t, p.Shutdown(t.Context())) }()
+
+	insertValues(p, "requests", "user_id", 0, 40)
+	p.rotate()
+	p.labelsStripped.Store(30)
+
+	rec := httptest.NewRecorder()
+	p.server.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, topOffendersPath, http.NoBody))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	var report topOffendersReport
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	assert.Equal(t, topOffendersReport{
+		EpochDurationSeconds:        300,
+		EstimatedCostPerMetricMonth: 0.5,
+		ActiveTrackers:              1,
+		LabelsStripped:              30,
+		EstimatedSavingsDollars:     15,
+		TopOffenders: []topOffender{
+			{MetricName: "requests", LabelKey: "user_id", Delta: 40, EstimatedMonthlyCost: 20},
+		},
+	}, report)
+
+	rec = httptest.NewRecorder()
+	p.server.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, pagePath, http.NoBody))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "<td>requests</td><td>user_id</td><td>40</td><td>$20.00</td>")
+
+	rec = httptest.NewRecorder()
+	p.server.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, topOffendersPath, http.NoBody))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
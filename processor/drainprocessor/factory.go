@@ -11,6 +11,7 @@ import (
 	"go.opentelemetry.io/collector/processor"
 	"go.opentelemetry.io/collector/processor/processorhelper"
 
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/drain"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/drainprocessor/internal/metadata"
 )
 
@@ -26,16 +27,7 @@ func NewFactory() processor.Factory {
 }
 
 func createDefaultConfig() component.Config {
-	return &Config{
-		TreeDepth:          4,
-		MergeThreshold:     0.4,
-		MaxNodeChildren:    100,
-		MaxClusters:        0,
-		TemplateAttribute:  "log.record.template",
-		ParameterKeyPrefix: "log.record.template.parameter",
-		WildcardsAttribute: "log.record.template.wildcards",
-		WarmupMinClusters:  0,
-	}
+	return drain.NewDefaultConfig()
 }
 
 func createLogsProcessor(
@@ -44,7 +36,7 @@ func createLogsProcessor(
 	cfg component.Config,
 	nextConsumer consumer.Logs,
 ) (processor.Logs, error) {
-	proc, err := newDrainProcessor(set, cfg.(*Config))
+	proc, err := drain.NewProcessor(set.TelemetrySettings, component.KindProcessor, set.ID, cfg.(*Config))
 	if err != nil {
 		return nil, err
 	}
@@ -53,7 +45,7 @@ func createLogsProcessor(
 		set,
 		cfg,
 		nextConsumer,
-		proc.processLogs,
+		proc.ProcessLogs,
 		processorhelper.WithCapabilities(processorCapabilities),
 		processorhelper.WithStart(proc.Start),
 		processorhelper.WithShutdown(proc.Shutdown),
@@ -0,0 +1,246 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package dynamicsamplingprocessor // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/dynamicsamplingprocessor"
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/sampling"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/dynamicsamplingprocessor/internal/metadata"
+)
+
+const (
+	// noTenantLabel is the tenant that traces without the configured resource
+	// attribute are budgeted under. Same sentinel convention as
+	// evictionRuleLabel.
+	noTenantLabel = "_none"
+	// overflowTenantLabel is the tenant that new tenants are budgeted under
+	// once tenant_budget.max_tenants tenants are already tracked. It takes one
+	// of the max_tenants slots.
+	overflowTenantLabel = "_overflow"
+)
+
+// tenantBudgets enforces a span-per-second budget per tenant on the traces
+// kept by the rules. Budgets are accounted over fixed intervals; at the end of
+// each interval, budget left unused by tenants below their budget is lent to
+// the tenants that exceeded theirs, and every tenant's keep ratio for the next
+// interval is derived from its demand in the interval that just ended.
+//
+// Demand is shed in two steps so that kept traces stay correctly weighted:
+// the rule's threshold is first scaled by the tenant's keep ratio, which is
+// reflected in ot=th, and the traces that still exceed the allowance (a burst
+// within an interval) are dropped outright so the budget is never exceeded.
+type tenantBudgets struct {
+	attribute     string
+	interval      time.Duration
+	defaultBudget float64
+	overrides     map[string]float64
+	redistribute  bool
+	maxTenants    int
+	telemetry     *metadata.TelemetryBuilder
+	now           func() time.Time
+
+	mu        sync.Mutex
+	windowEnd time.Time
+	tenants   map[string]*tenantBudget
+}
+
+// tenantBudget is the accounting for a single tenant. Span counts are per
+// interval rather than per second.
+type tenantBudget struct {
+	// budget is the tenant's own allowance, before redistribution.
+	budget float64
+	// allowance is what the tenant may keep in the current interval,
+	// including budget lent to it by other tenants.
+	allowance float64
+	// ratio is the fraction of the rule-selected traces kept in the current
+	// interval, derived from the previous interval's demand.
+	ratio float64
+	// offered counts the spans of traces the rules selected this interval,
+	// whether or not they fit in the budget.
+	offered float64
+	// admitted counts the spans of traces kept this interval.
+	admitted float64
+	attr     metric.MeasurementOption
+}
+
+func newTenantBudgets(cfg TenantBudgetConfig, telemetry *metadata.TelemetryBuilder) *tenantBudgets {
+	if cfg.ResourceAttribute == "" {
+		return nil
+	}
+	perInterval := cfg.Interval.Seconds()
+	overrides := make(map[string]float64, len(cfg.Overrides))
+	for tenant, spansPerSecond := range cfg.Overrides {
+		overrides[tenant] = float64(spansPerSecond) * perInterval
+	}
+	return &tenantBudgets{
+		attribute:     cfg.ResourceAttribute,
+		interval:      cfg.Interval,
+		defaultBudget: float64(cfg.SpansPerSecond) * perInterval,
+		overrides:     overrides,
+		redistribute:  cfg.RedistributeUnused,
+		maxTenants:    cfg.MaxTenants,
+		telemetry:     telemetry,
+		now:           time.Now,
+		tenants:       make(map[string]*tenantBudget),
+	}
+}
+
+// tenantOf returns the value of the tenant attribute on the first resource
+// that carries it, or noTenantLabel.
+func (b *tenantBudgets) tenantOf(spans []ptrace.ResourceSpans) string {
+	for _, rs := range spans {
+		if v, ok := rs.Resource().Attributes().Get(b.attribute); ok {
+			if s := v.AsString(); s != "" {
+				return s
+			}
+		}
+	}
+	return noTenantLabel
+}
+
+// apply charges a trace of spanCount spans against its tenant's budget. It
+// returns the threshold the trace must be decided with, and whether the trace
+// must be dropped because the tenant has used up its allowance for the
+// current interval. Traces that the rule's threshold does not keep are not
+// charged.
+func (b *tenantBudgets) apply(ctx context.Context, spans []ptrace.ResourceSpans, spanCount int, th sampling.Threshold, rnd sampling.Randomness) (sampling.Threshold, bool) {
+	if !th.ShouldSample(rnd) {
+		return th, false
+	}
+	tenant := b.tenantOf(spans)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.roll(ctx)
+	t := b.tenant(tenant)
+	count := float64(spanCount)
+	t.offered += count
+
+	scaled := scaleThreshold(th, t.ratio)
+	if !scaled.ShouldSample(rnd) {
+		b.telemetry.ProcessorDynamicSamplingTenantBudgetTracesDropped.Add(ctx, 1, t.attr)
+		return scaled, false
+	}
+	if t.admitted+count > t.allowance {
+		b.telemetry.ProcessorDynamicSamplingTenantBudgetTracesDropped.Add(ctx, 1, t.attr)
+		return scaled, true
+	}
+	t.admitted += count
+	return scaled, false
+}
+
+// tenant returns the accounting for tenant, creating it with a full budget
+// and a keep ratio of 1 when it was not active in the previous interval.
+// Guarded by b.mu.
+func (b *tenantBudgets) tenant(name string) *tenantBudget {
+	if t, ok := b.tenants[name]; ok {
+		return t
+	}
+	budget, overridden := b.overrides[name]
+	if !overridden {
+		// One slot is reserved for the overflow tenant until it exists, so
+		// that it does not take the tenant count above max_tenants.
+		overflow, hasOverflow := b.tenants[overflowTenantLabel]
+		reserved := 1
+		if hasOverflow {
+			reserved = 0
+		}
+		if len(b.tenants)+reserved >= b.maxTenants {
+			if hasOverflow {
+				return overflow
+			}
+			name = overflowTenantLabel
+		}
+		budget = b.defaultBudget
+	}
+	t := &tenantBudget{
+		budget:    budget,
+		allowance: budget,
+		ratio:     1,
+		attr:      metric.WithAttributes(attribute.String("tenant", name)),
+	}
+	b.tenants[name] = t
+	return t
+}
+
+// roll closes the current interval once it has ended: it reports every
+// tenant's utilisation, forgets tenants that had no demand, and computes the
+// allowances and keep ratios for the next interval. Guarded by b.mu.
+func (b *tenantBudgets) roll(ctx context.Context) {
+	now := b.now()
+	if now.Before(b.windowEnd) {
+		return
+	}
+	// When a whole interval passed without a decision, the last interval's
+	// demand says nothing about the next one.
+	stale := !b.windowEnd.IsZero() && now.Sub(b.windowEnd) >= b.interval
+	b.windowEnd = now.Add(b.interval)
+
+	var unused float64
+	borrowers := make([]*tenantBudget, 0, len(b.tenants))
+	for name, t := range b.tenants {
+		utilization := 0.0
+		if t.allowance > 0 {
+			utilization = t.admitted / t.allowance
+		}
+		b.telemetry.ProcessorDynamicSamplingTenantBudgetUtilization.Record(ctx, utilization, t.attr)
+		if stale || t.offered == 0 {
+			b.telemetry.ProcessorDynamicSamplingTenantBudgetAllowance.Record(ctx, 0, t.attr)
+			delete(b.tenants, name)
+			continue
+		}
+		t.allowance = t.budget
+		if t.offered < t.budget {
+			unused += t.budget - t.offered
+		} else if t.offered > t.budget {
+			borrowers = append(borrowers, t)
+		}
+	}
+
+	if b.redistribute && unused > 0 && len(borrowers) > 0 {
+		// Max-min fair share: each borrower gets an equal share of the unused
+		// budget, capped at what it needs to cover its excess; what a borrower
+		// does not need is split among the remaining ones.
+		slices.SortFunc(borrowers, func(x, y *tenantBudget) int {
+			return cmp.Compare(x.offered-x.budget, y.offered-y.budget)
+		})
+		for i, t := range borrowers {
+			lent := min(t.offered-t.budget, unused/float64(len(borrowers)-i))
+			t.allowance += lent
+			unused -= lent
+		}
+	}
+
+	for _, t := range b.tenants {
+		t.ratio = min(1, t.allowance/t.offered)
+		t.offered = 0
+		t.admitted = 0
+		b.telemetry.ProcessorDynamicSamplingTenantBudgetAllowance.Record(ctx, int64(t.allowance/b.interval.Seconds()), t.attr)
+	}
+}
+
+// scaleThreshold returns the threshold keeping the fraction ratio of the
+// traces th keeps. Because both thresholds are compared against the same
+// randomness, the traces kept by the result are a subset of those kept by th.
+func scaleThreshold(th sampling.Threshold, ratio float64) sampling.Threshold {
+	if ratio >= 1 {
+		return th
+	}
+	scaled, err := sampling.ProbabilityToThreshold(th.Probability() * ratio)
+	if err != nil {
+		// The probability is below the smallest representable one.
+		return sampling.NeverSampleThreshold
+	}
+	return scaled
+}
@@ -0,0 +1,185 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package dynamicsamplingprocessor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/sampling"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/dynamicsamplingprocessor/internal/metadata"
+)
+
+// newTestBudgets returns budgets accounted over 1s intervals on a clock the
+// test advances by hand.
+func newTestBudgets(t *testing.T, cfg TenantBudgetConfig) (*tenantBudgets, *time.Time) {
+	t.Helper()
+	cfg.ResourceAttribute = "tenant.id"
+	cfg.Interval = time.Second
+	if cfg.MaxTenants == 0 {
+		cfg.MaxTenants = 100
+	}
+	tb, err := metadata.NewTelemetryBuilder(componenttest.NewNopTelemetrySettings())
+	require.NoError(t, err)
+	b := newTenantBudgets(cfg, tb)
+	now := time.Unix(1_700_000_000, 0)
+	b.now = func() time.Time { return now }
+	return b, &now
+}
+
+func tenantSpans(tenant string) []ptrace.ResourceSpans {
+	rs := ptrace.NewResourceSpans()
+	if tenant != "" {
+		rs.Resource().Attributes().PutStr("tenant.id", tenant)
+	}
+	return []ptrace.ResourceSpans{rs}
+}
+
+// offer charges traces of one span each, with randomness spread uniformly, and
+// returns how many were kept.
+func offer(t *testing.T, b *tenantBudgets, tenant string, traces int) int {
+	t.Helper()
+	kept := 0
+	spans := tenantSpans(tenant)
+	for i := range traces {
+		rnd, err := sampling.UnsignedToRandomness(uint64(i) * (sampling.MaxAdjustedCount / uint64(traces)))
+		require.NoError(t, err)
+		th, over := b.apply(t.Context(), spans, 1, sampling.AlwaysSampleThreshold, rnd)
+		if !over && th.ShouldSample(rnd) {
+			kept++
+		}
+	}
+	return kept
+}
+
+func TestTenantBudgets_HardCap(t *testing.T) {
+	b, _ := newTestBudgets(t, TenantBudgetConfig{SpansPerSecond: 10})
+	assert.Equal(t, 10, offer(t, b, "acme", 25), "a tenant keeps at most its budget within an interval")
+	assert.Equal(t, 10, offer(t, b, "globex", 25), "each tenant has a budget of its own")
+}
+
+func TestTenantBudgets_ScalesThresholdFromPreviousDemand(t *testing.T) {
+	b, now := newTestBudgets(t, TenantBudgetConfig{SpansPerSecond: 10})
+	offer(t, b, "acme", 40)
+
+	*now = now.Add(time.Second)
+	th, over := b.apply(t.Context(), tenantSpans("acme"), 1, sampling.AlwaysSampleThreshold, sampling.AllProbabilitiesRandomness)
+	assert.False(t, over)
+	assert.InDelta(t, 0.25, th.Probability(), 1e-9, "40 spans offered against a budget of 10 keeps a quarter")
+	assert.Equal(t, 9, offer(t, b, "acme", 40), "thinning keeps demand within the remaining budget")
+}
+
+func TestTenantBudgets_NotChargedWhenRuleDrops(t *testing.T) {
+	b, _ := newTestBudgets(t, TenantBudgetConfig{SpansPerSecond: 1})
+	th, over := b.apply(t.Context(), tenantSpans("acme"), 5, sampling.NeverSampleThreshold, sampling.AllProbabilitiesRandomness)
+	assert.False(t, over)
+	assert.Equal(t, sampling.NeverSampleThreshold, th)
+	assert.Empty(t, b.tenants, "traces the rules drop are not charged")
+}
+
+func TestTenantBudgets_Redistribution(t *testing.T) {
+	tests := []struct {
+		name         string
+		redistribute bool
+		want         map[string]float64
+	}{
+		{
+			// 8 spans unused by acme are split equally between globex and
+			// initech; initech needs only 4, the rest goes to globex.
+			name:         "fair_share",
+			redistribute: true,
+			want:         map[string]float64{"acme": 10, "globex": 14, "initech": 14},
+		},
+		{
+			name: "disabled",
+			want: map[string]float64{"acme": 10, "globex": 10, "initech": 10},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, now := newTestBudgets(t, TenantBudgetConfig{SpansPerSecond: 10, RedistributeUnused: tt.redistribute})
+			offer(t, b, "acme", 2)
+			offer(t, b, "globex", 30)
+			offer(t, b, "initech", 14)
+
+			*now = now.Add(time.Second)
+			b.mu.Lock()
+			b.roll(t.Context())
+			got := make(map[string]float64, len(b.tenants))
+			for name, tenant := range b.tenants {
+				got[name] = tenant.allowance
+			}
+			b.mu.Unlock()
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestTenantBudgets_LentBudgetIsUsable(t *testing.T) {
+	b, now := newTestBudgets(t, TenantBudgetConfig{SpansPerSecond: 10, RedistributeUnused: true})
+	offer(t, b, "idle", 1)
+	offer(t, b, "busy", 19)
+
+	*now = now.Add(time.Second)
+	// busy is lent the 9 spans idle left unused, so it keeps all its demand.
+	assert.Equal(t, 19, offer(t, b, "busy", 19))
+}
+
+func TestTenantBudgets_OverridesAndSentinelTenants(t *testing.T) {
+	b, _ := newTestBudgets(t, TenantBudgetConfig{
+		SpansPerSecond: 5,
+		Overrides:      map[string]int{"premium": 20, "blocked": 0},
+		MaxTenants:     4,
+	})
+	assert.Equal(t, 20, offer(t, b, "premium", 30))
+	assert.Equal(t, 0, offer(t, b, "blocked", 30))
+	assert.Equal(t, 5, offer(t, b, "", 30), "traces without the attribute share one budget")
+	assert.Contains(t, b.tenants, noTenantLabel)
+
+	// The cap is reached: new tenants without an override share one budget.
+	assert.Equal(t, 5, offer(t, b, "acme", 3)+offer(t, b, "globex", 3)+offer(t, b, "initech", 3))
+	assert.Contains(t, b.tenants, overflowTenantLabel)
+	assert.NotContains(t, b.tenants, "acme")
+}
+
+func TestTenantBudgets_OverflowTenantWithinCap(t *testing.T) {
+	b, _ := newTestBudgets(t, TenantBudgetConfig{SpansPerSecond: 5, MaxTenants: 3})
+	for _, tenant := range []string{"acme", "globex", "initech", "umbrella"} {
+		offer(t, b, tenant, 1)
+	}
+	assert.Len(t, b.tenants, 3, "the overflow tenant takes one of the max_tenants slots")
+	assert.Contains(t, b.tenants, "acme")
+	assert.Contains(t, b.tenants, "globex")
+	assert.Contains(t, b.tenants, overflowTenantLabel)
+}
+
+func TestTenantBudgets_ForgetsIdleTenants(t *testing.T) {
+	b, now := newTestBudgets(t, TenantBudgetConfig{SpansPerSecond: 10})
+	offer(t, b, "acme", 40)
+
+	// A whole interval without decisions: the old demand is discarded, so
+	// acme starts again with its full budget and no thinning.
+	*now = now.Add(3 * time.Second)
+	assert.Equal(t, 10, offer(t, b, "acme", 10))
+}
+
+func TestTenantBudgets_Disabled(t *testing.T) {
+	assert.Nil(t, newTenantBudgets(TenantBudgetConfig{}, nil))
+}
+
+func TestTenantBudgets_TenantOf(t *testing.T) {
+	b, _ := newTestBudgets(t, TenantBudgetConfig{SpansPerSecond: 1})
+	spans := append(tenantSpans(""), tenantSpans("acme")...)
+	assert.Equal(t, "acme", b.tenantOf(spans), "the first resource carrying the attribute wins")
+
+	rs := ptrace.NewResourceSpans()
+	rs.Resource().Attributes().PutInt("tenant.id", 42)
+	assert.Equal(t, "42", b.tenantOf([]ptrace.ResourceSpans{rs}))
+	assert.Equal(t, noTenantLabel, b.tenantOf([]ptrace.ResourceSpans{ptrace.NewResourceSpans()}))
+}
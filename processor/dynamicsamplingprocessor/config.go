@@ -72,6 +72,9 @@ type Config struct {
 	// policies emit a real decision (recorded in the decision cache and, for
 	// kept traces, stamped with ot=th) rather than silently dropping spans.
 	Eviction EvictionConfig `mapstructure:"eviction"`
+	// TenantBudget caps the spans per second kept for each tenant, across all
+	// rules. Disabled unless ResourceAttribute is set.
+	TenantBudget TenantBudgetConfig `mapstructure:"tenant_budget"`
 	// prevent unkeyed literal initialization
 	_ struct{}
 }
@@ -109,6 +112,35 @@ type EvictionConfig struct {
 	_ struct{}
 }
 
+// TenantBudgetConfig configures per-tenant span budgets. A tenant is
+// identified by the value of a resource attribute; every trace a rule decides
+// to keep is charged against its tenant's budget, and traces that do not fit
+// are dropped.
+type TenantBudgetConfig struct {
+	// ResourceAttribute is the resource attribute whose value identifies the
+	// tenant of a trace, e.g. `tenant.id`. Traces without it are budgeted
+	// together under the `_none` tenant. Budgets are disabled when unset.
+	ResourceAttribute string `mapstructure:"resource_attribute"`
+	// SpansPerSecond is the budget of every tenant without an override.
+	SpansPerSecond int `mapstructure:"spans_per_second"`
+	// Overrides maps tenants to their own spans-per-second budget, replacing
+	// SpansPerSecond. A budget of 0 drops all traces of that tenant.
+	Overrides map[string]int `mapstructure:"overrides"`
+	// RedistributeUnused lends the budget left unused by tenants in one
+	// interval to the tenants that exceeded theirs, in equal shares capped at
+	// each tenant's excess demand. Defaults to true.
+	RedistributeUnused bool `mapstructure:"redistribute_unused"`
+	// Interval is the period over which budgets are accounted and unused
+	// budget is redistributed. Defaults to 1s.
+	Interval time.Duration `mapstructure:"interval"`
+	// MaxTenants caps the number of tenants tracked at once, including the
+	// `_overflow` tenant that the tenants without an override seen beyond the
+	// cap share the budget of. Tenants with an override are always tracked.
+	MaxTenants int `mapstructure:"max_tenants"`
+	// prevent unkeyed literal initialization
+	_ struct{}
+}
+
 // DecisionCacheConfig sizes the LRU caches that record sampling decisions.
 // When a span arrives for a traceID that already has a recorded decision, the
 // processor short-circuits the accumulation path: sampled traces are forwarded
@@ -258,6 +290,33 @@ func (c *Config) Validate() error {
 	if err := c.Eviction.validate(); err != nil {
 		return err
 	}
+	if err := c.TenantBudget.validate(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *TenantBudgetConfig) validate() error {
+	if t.ResourceAttribute == "" {
+		if t.SpansPerSecond != 0 || len(t.Overrides) > 0 {
+			return errors.New("tenant_budget: resource_attribute is required when spans_per_second or overrides are set")
+		}
+		return nil
+	}
+	if t.SpansPerSecond <= 0 {
+		return errors.New("tenant_budget: spans_per_second must be greater than zero")
+	}
+	for tenant, spansPerSecond := range t.Overrides {
+		if spansPerSecond < 0 {
+			return fmt.Errorf("tenant_budget: overrides[%q] must be non-negative", tenant)
+		}
+	}
+	if t.Interval <= 0 {
+		return errors.New("tenant_budget: interval must be greater than zero")
+	}
+	if t.MaxTenants <= 0 {
+		return errors.New("tenant_budget: max_tenants must be greater than zero")
+	}
 	return nil
 }
 
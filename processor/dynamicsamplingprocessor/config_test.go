@@ -319,6 +319,71 @@ func TestConfig_Validate(t *testing.T) {
 			}(),
 			wantErr: "eviction: policy must be",
 		},
+		{
+			name: "tenant_budget_valid",
+			cfg: func() Config {
+				c := baseCfg(RuleConfig{Name: "r", Sampler: SamplerConfig{Type: AlwaysSample}})
+				c.TenantBudget = TenantBudgetConfig{
+					ResourceAttribute: "tenant.id",
+					SpansPerSecond:    100,
+					Overrides:         map[string]int{"acme": 1000, "blocked": 0},
+					Interval:          time.Second,
+					MaxTenants:        10,
+				}
+				return c
+			}(),
+		},
+		{
+			name: "tenant_budget_requires_resource_attribute",
+			cfg: func() Config {
+				c := baseCfg(RuleConfig{Name: "r", Sampler: SamplerConfig{Type: AlwaysSample}})
+				c.TenantBudget = TenantBudgetConfig{SpansPerSecond: 100}
+				return c
+			}(),
+			wantErr: "tenant_budget: resource_attribute is required",
+		},
+		{
+			name: "tenant_budget_missing_spans_per_second",
+			cfg: func() Config {
+				c := baseCfg(RuleConfig{Name: "r", Sampler: SamplerConfig{Type: AlwaysSample}})
+				c.TenantBudget = TenantBudgetConfig{ResourceAttribute: "tenant.id", Interval: time.Second, MaxTenants: 10}
+				return c
+			}(),
+			wantErr: "tenant_budget: spans_per_second must be greater than zero",
+		},
+		{
+			name: "tenant_budget_negative_override",
+			cfg: func() Config {
+				c := baseCfg(RuleConfig{Name: "r", Sampler: SamplerConfig{Type: AlwaysSample}})
+				c.TenantBudget = TenantBudgetConfig{
+					ResourceAttribute: "tenant.id",
+					SpansPerSecond:    100,
+					Overrides:         map[string]int{"acme": -1},
+					Interval:          time.Second,
+					MaxTenants:        10,
+				}
+				return c
+			}(),
+			wantErr: `tenant_budget: overrides["acme"] must be non-negative`,
+		},
+		{
+			name: "tenant_budget_missing_interval",
+			cfg: func() Config {
+				c := baseCfg(RuleConfig{Name: "r", Sampler: SamplerConfig{Type: AlwaysSample}})
+				c.TenantBudget = TenantBudgetConfig{ResourceAttribute: "tenant.id", SpansPerSecond: 100, MaxTenants: 10}
+				return c
+			}(),
+			wantErr: "tenant_budget: interval must be greater than zero",
+		},
+		{
+			name: "tenant_budget_missing_max_tenants",
+			cfg: func() Config {
+				c := baseCfg(RuleConfig{Name: "r", Sampler: SamplerConfig{Type: AlwaysSample}})
+				c.TenantBudget = TenantBudgetConfig{ResourceAttribute: "tenant.id", SpansPerSecond: 100, Interval: time.Second}
+				return c
+			}(),
+			wantErr: "tenant_budget: max_tenants must be greater than zero",
+		},
 		{
 			name: "root_span_condition_valid",
 			cfg: Config{
@@ -32,6 +32,11 @@ func createDefaultConfig() component.Config {
 			SampledCacheSize:    10_000,
 			NonSampledCacheSize: 10_000,
 		},
+		TenantBudget: TenantBudgetConfig{
+			RedistributeUnused: true,
+			Interval:           time.Second,
+			MaxTenants:         1_000,
+		},
 	}
 }
 
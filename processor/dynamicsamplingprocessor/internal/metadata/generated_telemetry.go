@@ -29,6 +29,9 @@ type TelemetryBuilder struct {
 	ProcessorDynamicSamplingDecisionTriggers              metric.Int64Counter
 	ProcessorDynamicSamplingIncomingTracestateUnparseable metric.Int64Counter
 	ProcessorDynamicSamplingOttlEvalErrors                metric.Int64Counter
+	ProcessorDynamicSamplingTenantBudgetAllowance         metric.Int64Gauge
+	ProcessorDynamicSamplingTenantBudgetTracesDropped     metric.Int64Counter
+	ProcessorDynamicSamplingTenantBudgetUtilization       metric.Float64Gauge
 	ProcessorDynamicSamplingTracesActive                  metric.Int64Gauge
 	ProcessorDynamicSamplingTracesDropped                 metric.Int64Counter
 	ProcessorDynamicSamplingTracesEvicted                 metric.Int64Counter
@@ -88,6 +91,24 @@ func NewTelemetryBuilder(settings component.TelemetrySettings, options ...Teleme
 		metric.WithUnit("{errors}"),
 	)
 	errs = errors.Join(errs, err)
+	builder.ProcessorDynamicSamplingTenantBudgetAllowance, err = builder.meter.Int64Gauge(
+		"otelcol_processor_dynamic_sampling_tenant_budget_allowance",
+		metric.WithDescription("Spans per second a tenant may keep during the current budget interval, including unused budget lent to it by other tenants, labelled by tenant. [Development]"),
+		metric.WithUnit("{spans}/s"),
+	)
+	errs = errors.Join(errs, err)
+	builder.ProcessorDynamicSamplingTenantBudgetTracesDropped, err = builder.meter.Int64Counter(
+		"otelcol_processor_dynamic_sampling_tenant_budget_traces_dropped",
+		metric.WithDescription("Number of traces selected by a rule but dropped to keep their tenant within its span budget, labelled by tenant. [Development]"),
+		metric.WithUnit("{traces}"),
+	)
+	errs = errors.Join(errs, err)
+	builder.ProcessorDynamicSamplingTenantBudgetUtilization, err = builder.meter.Float64Gauge(
+		"otelcol_processor_dynamic_sampling_tenant_budget_utilization",
+		metric.WithDescription("Fraction of its allowance a tenant used during the last budget interval, labelled by tenant. [Development]"),
+		metric.WithUnit("1"),
+	)
+	errs = errors.Join(errs, err)
 	builder.ProcessorDynamicSamplingTracesActive, err = builder.meter.Int64Gauge(
 		"otelcol_processor_dynamic_sampling_traces_active",
 		metric.WithDescription("Number of traces currently in the accumulation buffer awaiting a decision. [Development]"),
@@ -23,6 +23,9 @@ func TestSetupTelemetry(t *testing.T) {
 	tb.ProcessorDynamicSamplingDecisionTriggers.Add(context.Background(), 1)
 	tb.ProcessorDynamicSamplingIncomingTracestateUnparseable.Add(context.Background(), 1)
 	tb.ProcessorDynamicSamplingOttlEvalErrors.Add(context.Background(), 1)
+	tb.ProcessorDynamicSamplingTenantBudgetAllowance.Record(context.Background(), 1)
+	tb.ProcessorDynamicSamplingTenantBudgetTracesDropped.Add(context.Background(), 1)
+	tb.ProcessorDynamicSamplingTenantBudgetUtilization.Record(context.Background(), 1)
 	tb.ProcessorDynamicSamplingTracesActive.Record(context.Background(), 1)
 	tb.ProcessorDynamicSamplingTracesDropped.Add(context.Background(), 1)
 	tb.ProcessorDynamicSamplingTracesEvicted.Add(context.Background(), 1)
@@ -39,6 +42,15 @@ func TestSetupTelemetry(t *testing.T) {
 	AssertEqualProcessorDynamicSamplingOttlEvalErrors(t, testTel,
 		[]metricdata.DataPoint[int64]{{Value: 1}},
 		metricdatatest.IgnoreTimestamp())
+	AssertEqualProcessorDynamicSamplingTenantBudgetAllowance(t, testTel,
+		[]metricdata.DataPoint[int64]{{Value: 1}},
+		metricdatatest.IgnoreTimestamp())
+	AssertEqualProcessorDynamicSamplingTenantBudgetTracesDropped(t, testTel,
+		[]metricdata.DataPoint[int64]{{Value: 1}},
+		metricdatatest.IgnoreTimestamp())
+	AssertEqualProcessorDynamicSamplingTenantBudgetUtilization(t, testTel,
+		[]metricdata.DataPoint[float64]{{Value: 1}},
+		metricdatatest.IgnoreTimestamp())
 	AssertEqualProcessorDynamicSamplingTracesActive(t, testTel,
 		[]metricdata.DataPoint[int64]{{Value: 1}},
 		metricdatatest.IgnoreTimestamp())
@@ -102,6 +102,8 @@ type dynamicSamplingProcessor struct {
 	rules   []*rule
 	stopped bool
 	cache   *decisionCache
+	// budgets is nil unless tenant_budget is configured.
+	budgets *tenantBudgets
 	// arrival records traceIDs in first-seen order so eviction can pick the
 	// oldest pending trace. Entries are appended exactly once per trace and
 	// lazily skipped when the trace has already been decided (popped ids are
@@ -154,6 +156,7 @@ func newProcessor(set processor.Settings, cfg *Config, next consumer.Traces) (*d
 		timers:               make(map[pcommon.TraceID]*time.Timer),
 		rules:                rules,
 		cache:                cache,
+		budgets:              newTenantBudgets(cfg.TenantBudget, tb),
 		rootSpanCond:         rootSpanCond,
 		rootSpanCondEvalErrs: tb.ProcessorDynamicSamplingOttlEvalErrors,
 		rootSpanCondAttrSet:  metric.WithAttributes(attribute.String("rule", rootSpanConditionRuleLabel)),
@@ -665,15 +668,20 @@ func (p *dynamicSamplingProcessor) decideTrace(ctx context.Context, pt *pendingT
 	p.finishDecision(ctx, pt, matchedRule.name, ruleAttr, effectiveTh, randomness)
 }
 
-// finishDecision applies an already-composed effective threshold: records the
-// sample-rate histogram, performs the keep/drop check, updates the decision
-// cache, and forwards sampled traces. Shared by every decision path.
+// finishDecision applies an already-composed effective threshold: charges the
+// trace against its tenant's budget, records the sample-rate histogram,
+// performs the keep/drop check, updates the decision cache, and forwards
+// sampled traces. Shared by every decision path.
 func (p *dynamicSamplingProcessor) finishDecision(ctx context.Context, pt *pendingTrace, ruleName string, ruleAttr metric.MeasurementOption, effectiveTh sampling.Threshold, randomness sampling.Randomness) {
+	overBudget := false
+	if p.budgets != nil {
+		effectiveTh, overBudget = p.budgets.apply(ctx, pt.spans, pt.spanCount, effectiveTh, randomness)
+	}
 	// Record the effective (post-composition) rate rather than the raw sampler
 	// rate: under equalizing, an upstream stricter than the sampler's rate caps
 	// what we emit, and the histogram should reflect that.
 	p.telemetry.ProcessorDynamicSamplingDecisionSampleRate.Record(ctx, int64(effectiveTh.AdjustedCount()), ruleAttr)
-	if !effectiveTh.ShouldSample(randomness) {
+	if overBudget || !effectiveTh.ShouldSample(randomness) {
 		p.telemetry.ProcessorDynamicSamplingTracesDropped.Add(ctx, 1, ruleAttr)
 		p.cache.recordNotSampled(pt.traceID)
 		return
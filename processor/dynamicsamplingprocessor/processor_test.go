@@ -493,6 +493,62 @@ func TestProcessor_EvictionMetrics(t *testing.T) {
 	)
 }
 
+func TestProcessor_TenantBudget(t *testing.T) {
+	tt := componenttest.NewTelemetry()
+	t.Cleanup(func() {
+		require.NoError(t, tt.Shutdown(context.Background())) //nolint:usetesting // cleanup after ctx cancel
+	})
+
+	sink := &consumertest.TracesSink{}
+	cfg := &Config{
+		TraceTimeout:  time.Hour,
+		DecisionDelay: time.Hour,
+		NumTraces:     1,
+		DecisionCache: DecisionCacheConfig{SampledCacheSize: 10, NonSampledCacheSize: 10},
+		Rules: []RuleConfig{
+			{Name: "default", Sampler: SamplerConfig{Type: AlwaysSample}},
+		},
+		TenantBudget: TenantBudgetConfig{
+			ResourceAttribute: "tenant.id",
+			SpansPerSecond:    1,
+			Interval:          2 * time.Minute,
+			MaxTenants:        10,
+		},
+	}
+	p, err := newProcessor(metadatatest.NewSettings(tt), cfg, sink)
+	require.NoError(t, err)
+	require.NoError(t, p.Start(t.Context(), nil))
+	t.Cleanup(func() { require.NoError(t, p.Shutdown(t.Context())) })
+
+	// With num_traces: 1 every new trace evicts, and so decides, the previous
+	// one. The interval's budget is 120 spans: the first 120 traces are kept
+	// and the rest are dropped.
+	const total = 125
+	for i := range total + 1 {
+		td := newTrace(pcommon.TraceID([16]byte{0xF0, byte(i)}), ptrace.StatusCodeUnset)
+		td.ResourceSpans().At(0).Resource().Attributes().PutStr("tenant.id", "acme")
+		require.NoError(t, p.ConsumeTraces(t.Context(), td))
+	}
+	assert.Equal(t, 120, sink.SpanCount())
+
+	metadatatest.AssertEqualProcessorDynamicSamplingTenantBudgetTracesDropped(t, tt,
+		[]metricdata.DataPoint[int64]{{
+			Value:      total - 120,
+			Attributes: attribute.NewSet(attribute.String("tenant", "acme")),
+		}},
+		metricdatatest.IgnoreTimestamp(),
+		metricdatatest.IgnoreExemplars(),
+	)
+	metadatatest.AssertEqualProcessorDynamicSamplingTracesDropped(t, tt,
+		[]metricdata.DataPoint[int64]{{
+			Value:      total - 120,
+			Attributes: attribute.NewSet(attribute.String("rule", "default")),
+		}},
+		metricdatatest.IgnoreTimestamp(),
+		metricdatatest.IgnoreExemplars(),
+	)
+}
+
 func TestProcessor_RootSpanTriggersEarlyDecision(t *testing.T) {
 	sink := &consumertest.TracesSink{}
 	cfg := &Config{
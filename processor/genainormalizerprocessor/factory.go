@@ -9,8 +9,8 @@ import (
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/consumer"
 	"go.opentelemetry.io/collector/processor"
-	"go.opentelemetry.io/collector/processor/processorhelper"
 
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/genainormalizer"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/genainormalizerprocessor/internal/metadata"
 )
 
@@ -26,9 +26,7 @@ func NewFactory() processor.Factory {
 // createDefaultConfig returns the default configuration. Sources must be
 // explicitly specified by the user; there are no built-in source defaults.
 func createDefaultConfig() component.Config {
-	return &Config{
-		Sources: []Source{},
-	}
+	return genainormalizer.NewDefaultConfig()
 }
 
 func createTracesProcessor(
@@ -37,12 +35,5 @@ func createTracesProcessor(
 	cfg component.Config,
 	next consumer.Traces,
 ) (processor.Traces, error) {
-	c := cfg.(*Config)
-	if err := c.Validate(); err != nil {
-		return nil, err
-	}
-	p := newGenaiNormalizerProcessor(c)
-	return processorhelper.NewTraces(ctx, set, cfg, next, p.processTraces,
-		processorhelper.WithCapabilities(consumer.Capabilities{MutatesData: true}),
-	)
+	return genainormalizer.NewTracesProcessor(ctx, set, cfg.(*Config), next)
 }
@@ -32,6 +32,32 @@ type Config struct {
 
 	// Adaptive window sizing configuration
 	AdaptiveWindow *AdaptiveWindowConfig `mapstructure:"adaptive_window"`
+
+	// Seasonality configures the seasonality-aware metric features
+	// (hour_of_day, hour_of_week, rolling_zscore and ewma_residual).
+	Seasonality SeasonalityConfig `mapstructure:"seasonality"`
+
+	// Output configures how anomaly results are reported.
+	Output OutputConfig `mapstructure:"output"`
+
+	// Storage is the ID of a storage extension that model snapshots are saved
+	// to and restored from, so that scoring survives restarts. Optional — when
+	// unset every start begins with untrained models.
+	Storage *component.ID `mapstructure:"storage"`
+
+	// SaveInterval is the interval between periodic model snapshot saves. 0
+	// (default) saves on shutdown only. Requires storage or model_export_file.
+	SaveInterval time.Duration `mapstructure:"save_interval"`
+
+	// ModelFile is the path of a model file, e.g. pre-trained offline on
+	// historical data, to load models from at startup. A model in the file
+	// replaces the stored snapshot of the same name unless the snapshot was
+	// derived from the same model version.
+	ModelFile string `mapstructure:"model_file"`
+
+	// ModelExportFile is the path of a file all models are written to, in the
+	// model_file format, on shutdown and every save_interval.
+	ModelExportFile string `mapstructure:"model_export_file"`
 }
 
 // AdaptiveWindowConfig configures automatic window size adjustment based on traffic patterns
@@ -48,6 +74,37 @@ type AdaptiveWindowConfig struct {
 	StabilityCheckInterval string  `mapstructure:"stability_check_interval"` // Check model accuracy interval
 }
 
+// SeasonalityConfig configures the per-series state that the seasonality-aware
+// metric features are computed from.
+type SeasonalityConfig struct {
+	// Timezone is the IANA time zone that hours of the day and days of the
+	// week are counted in.
+	Timezone string `mapstructure:"timezone"`
+	// ZScoreWindow is the number of recent values of a series that
+	// rolling_zscore is computed against.
+	ZScoreWindow int `mapstructure:"zscore_window"`
+	// EWMAAlpha is the smoothing factor of the moving average that
+	// ewma_residual is computed against, in (0, 1]. Higher reacts faster.
+	EWMAAlpha float64 `mapstructure:"ewma_alpha"`
+	// MaxSeries caps the number of series tracked for rate_of_change and the
+	// seasonality-aware features; the least recently updated series is
+	// forgotten once exceeded.
+	MaxSeries int `mapstructure:"max_series"`
+}
+
+// OutputConfig configures how anomaly results are reported.
+type OutputConfig struct {
+	// Attributes adds the score and classification attributes to the scored
+	// spans, data points and log records in enrich and both modes.
+	Attributes bool `mapstructure:"attributes"`
+	// Metrics appends an iforest.anomaly_score gauge data point for every
+	// scored data point, in a separate scope. Metrics pipelines only.
+	Metrics bool `mapstructure:"metrics"`
+	// Logs appends an iforest.anomaly event record for every anomalous log
+	// record, in a separate scope. Logs pipelines only.
+	Logs bool `mapstructure:"logs"`
+}
+
 type FeatureConfig struct {
 	Traces  []string `mapstructure:"traces"`
 	Metrics []string `mapstructure:"metrics"`
@@ -99,6 +156,17 @@ func createDefaultConfig() component.Config {
 			ParallelWorkers: 4,
 		},
 
+		Seasonality: SeasonalityConfig{
+			Timezone:     "UTC",
+			ZScoreWindow: 60,
+			EWMAAlpha:    0.3,
+			MaxSeries:    10000,
+		},
+
+		Output: OutputConfig{
+			Attributes: true,
+		},
+
 		// Default adaptive window configuration (disabled by default for backward compatibility)
 		AdaptiveWindow: &AdaptiveWindowConfig{
 			Enabled:                false,  // Disabled by default - backward compatibility
@@ -155,6 +223,24 @@ func (cfg *Config) Validate() error {
 		return errors.New("at least one feature type must be configured")
 	}
 
+	if cfg.SaveInterval < 0 {
+		return fmt.Errorf("save_interval must be >= 0, got %s", cfg.SaveInterval)
+	}
+	if cfg.SaveInterval > 0 && cfg.Storage == nil && cfg.ModelExportFile == "" {
+		return errors.New("save_interval requires storage or model_export_file to be set")
+	}
+	seen := make(map[string]struct{}, len(cfg.Models))
+	for _, model := range cfg.Models {
+		if _, ok := seen[model.Name]; ok {
+			return fmt.Errorf("models: duplicate model name %q", model.Name)
+		}
+		seen[model.Name] = struct{}{}
+	}
+
+	if err := cfg.Seasonality.validate(); err != nil {
+		return fmt.Errorf("seasonality: %w", err)
+	}
+
 	// Validate adaptive window configuration
 	if cfg.AdaptiveWindow != nil {
 		if err := cfg.validateAdaptiveWindow(); err != nil {
@@ -210,6 +296,27 @@ func (cfg *Config) validateAdaptiveWindow() error {
 	return nil
 }
 
+func (cfg SeasonalityConfig) validate() error {
+	if _, err := cfg.location(); err != nil {
+		return fmt.Errorf("invalid timezone %q: %w", cfg.Timezone, err)
+	}
+	if cfg.ZScoreWindow < 2 {
+		return fmt.Errorf("zscore_window must be >= 2, got %d", cfg.ZScoreWindow)
+	}
+	if cfg.EWMAAlpha <= 0 || cfg.EWMAAlpha > 1 {
+		return fmt.Errorf("ewma_alpha must be in (0, 1], got %v", cfg.EWMAAlpha)
+	}
+	if cfg.MaxSeries <= 0 {
+		return fmt.Errorf("max_series must be > 0, got %d", cfg.MaxSeries)
+	}
+	return nil
+}
+
+// location returns the time zone the seasonal baselines are bucketed in.
+func (cfg SeasonalityConfig) location() (*time.Location, error) {
+	return time.LoadLocation(cfg.Timezone)
+}
+
 // IsAdaptiveWindowEnabled returns true if adaptive window sizing is enabled
 func (cfg *Config) IsAdaptiveWindowEnabled() bool {
 	return cfg.AdaptiveWindow != nil && cfg.AdaptiveWindow.Enabled
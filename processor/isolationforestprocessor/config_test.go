@@ -249,6 +249,66 @@ func TestConfigurationValidation(t *testing.T) {
 			},
 			expectError: false,
 		},
+		{
+			name:          "negative save interval",
+			modifyConfig:  func(cfg *Config) { cfg.SaveInterval = -time.Second },
+			expectError:   true,
+			errorContains: "save_interval must be >= 0",
+		},
+		{
+			name:          "save interval without storage or export file",
+			modifyConfig:  func(cfg *Config) { cfg.SaveInterval = time.Minute },
+			expectError:   true,
+			errorContains: "save_interval requires storage or model_export_file to be set",
+		},
+		{
+			name: "save interval with export file",
+			modifyConfig: func(cfg *Config) {
+				cfg.SaveInterval = time.Minute
+				cfg.ModelExportFile = "models.json"
+			},
+			expectError: false,
+		},
+		{
+			name: "duplicate model names",
+			modifyConfig: func(cfg *Config) {
+				cfg.Models = []ModelConfig{{Name: "checkout"}, {Name: "checkout"}}
+			},
+			expectError:   true,
+			errorContains: `duplicate model name "checkout"`,
+		},
+		{
+			name:          "invalid seasonality timezone",
+			modifyConfig:  func(cfg *Config) { cfg.Seasonality.Timezone = "Mars/Olympus_Mons" },
+			expectError:   true,
+			errorContains: `seasonality: invalid timezone "Mars/Olympus_Mons"`,
+		},
+		{
+			name:          "seasonality zscore window too small",
+			modifyConfig:  func(cfg *Config) { cfg.Seasonality.ZScoreWindow = 1 },
+			expectError:   true,
+			errorContains: "seasonality: zscore_window must be >= 2, got 1",
+		},
+		{
+			name:          "seasonality ewma alpha out of range",
+			modifyConfig:  func(cfg *Config) { cfg.Seasonality.EWMAAlpha = 1.5 },
+			expectError:   true,
+			errorContains: "seasonality: ewma_alpha must be in (0, 1], got 1.5",
+		},
+		{
+			name:          "seasonality max series zero",
+			modifyConfig:  func(cfg *Config) { cfg.Seasonality.MaxSeries = 0 },
+			expectError:   true,
+			errorContains: "seasonality: max_series must be > 0, got 0",
+		},
+		{
+			name: "seasonal metric features",
+			modifyConfig: func(cfg *Config) {
+				cfg.Features.Metrics = []string{"value", "hour_of_day", "hour_of_week", "rolling_zscore", "ewma_residual"}
+				cfg.Seasonality.Timezone = "Europe/Berlin"
+			},
+			expectError: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -59,6 +59,8 @@ func createTracesProcessor(
 	if err != nil {
 		return nil, fmt.Errorf("failed to create processor: %w", err)
 	}
+	proc.componentID = set.ID
+	proc.signal = signalTraces
 
 	return &tracesProcessor{
 		isolationForestProcessor: proc,
@@ -92,6 +94,8 @@ func createMetricsProcessor(
 	if err != nil {
 		return nil, fmt.Errorf("failed to create processor: %w", err)
 	}
+	proc.componentID = set.ID
+	proc.signal = signalMetrics
 
 	return &metricsProcessor{
 		isolationForestProcessor: proc,
@@ -125,6 +129,8 @@ func createLogsProcessor(
 	if err != nil {
 		return nil, fmt.Errorf("failed to create processor: %w", err)
 	}
+	proc.componentID = set.ID
+	proc.signal = signalLogs
 
 	return &logsProcessor{
 		isolationForestProcessor: proc,
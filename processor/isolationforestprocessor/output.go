@@ -0,0 +1,141 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// output.go - Anomaly results reported as separate metrics and log records
+package isolationforestprocessor // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/isolationforestprocessor"
+
+import (
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/isolationforestprocessor/internal/metadata"
+)
+
+const (
+	// anomalyScoreMetricName is the gauge appended for every scored data point
+	// when output.metrics is enabled.
+	anomalyScoreMetricName = "iforest.anomaly_score"
+	// anomalyEventName is the event name of the records appended for every
+	// anomalous log record when output.logs is enabled.
+	anomalyEventName = "iforest.anomaly"
+	// metricNameAttribute holds the name of the scored metric on
+	// anomalyScoreMetricName data points.
+	metricNameAttribute = "anomaly.metric_name"
+	modelNameAttribute  = "anomaly.model_name"
+)
+
+// enrichesAttributes reports whether scored items get the score and
+// classification attributes.
+func (p *isolationForestProcessor) enrichesAttributes() bool {
+	return (p.config.Mode == "enrich" || p.config.Mode == "both") && p.config.Output.Attributes
+}
+
+// numberValue returns the value of dp as a float64, whatever its type.
+func numberValue(dp pmetric.NumberDataPoint) float64 {
+	if dp.ValueType() == pmetric.NumberDataPointValueTypeInt {
+		return float64(dp.IntValue())
+	}
+	return dp.DoubleValue()
+}
+
+// seriesKey identifies the series of a data point by its metric name and its
+// resource and data point attributes.
+func seriesKey(metricName string, resource, attrs pcommon.Map) string {
+	h := fnv.New64a()
+	h.Write([]byte(metricName))
+	for _, m := range []pcommon.Map{resource, attrs} {
+		h.Write([]byte{0})
+		keys := make([]string, 0, m.Len())
+		for k := range m.All() {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+		for _, k := range keys {
+			v, _ := m.Get(k)
+			fmt.Fprintf(h, "%s=%s\x1f", k, v.AsString())
+		}
+	}
+	return metricName + "/" + strconv.FormatUint(h.Sum64(), 16)
+}
+
+// timestampOrNow returns ts as a time, or the current time if it is unset.
+func timestampOrNow(ts pcommon.Timestamp) time.Time {
+	if ts == 0 {
+		return time.Now()
+	}
+	return ts.AsTime()
+}
+
+// appendAnomalyScore adds the score of dp, a data point of metricName, to
+// results.
+func (p *isolationForestProcessor) appendAnomalyScore(results pmetric.NumberDataPointSlice, metricName string, dp pmetric.NumberDataPoint, score float64, isAnomaly bool, modelName string) {
+	result := results.AppendEmpty()
+	dp.Attributes().CopyTo(result.Attributes())
+	result.Attributes().PutStr(metricNameAttribute, metricName)
+	result.Attributes().PutBool(p.config.ClassificationAttribute, isAnomaly)
+	if modelName != "" && modelName != defaultModelName {
+		result.Attributes().PutStr(modelNameAttribute, modelName)
+	}
+	result.SetStartTimestamp(dp.StartTimestamp())
+	result.SetTimestamp(pcommon.NewTimestampFromTime(timestampOrNow(dp.Timestamp())))
+	result.SetDoubleValue(score)
+}
+
+// appendScoreMetric appends the anomaly score data points in results to rm,
+// in a scope of their own.
+func appendScoreMetric(rm pmetric.ResourceMetrics, results pmetric.NumberDataPointSlice) {
+	if results.Len() == 0 {
+		return
+	}
+	sm := rm.ScopeMetrics().AppendEmpty()
+	sm.Scope().SetName(metadata.ScopeName)
+	m := sm.Metrics().AppendEmpty()
+	m.SetName(anomalyScoreMetricName)
+	m.SetDescription("Isolation forest anomaly score of the data points of the metric named by " + metricNameAttribute + ".")
+	m.SetUnit("1")
+	results.MoveAndAppendTo(m.SetEmptyGauge().DataPoints())
+}
+
+// appendAnomalyEvent adds an event describing the anomalous record to events.
+func (p *isolationForestProcessor) appendAnomalyEvent(events plog.LogRecordSlice, record plog.LogRecord, score float64, modelName string) {
+	event := events.AppendEmpty()
+	event.SetEventName(anomalyEventName)
+	event.SetSeverityNumber(plog.SeverityNumberWarn)
+	event.SetSeverityText("WARN")
+	event.SetTimestamp(pcommon.NewTimestampFromTime(timestampOrNow(record.Timestamp())))
+	event.SetObservedTimestamp(pcommon.NewTimestampFromTime(time.Now()))
+	event.SetTraceID(record.TraceID())
+	event.SetSpanID(record.SpanID())
+	record.Attributes().CopyTo(event.Attributes())
+	event.Attributes().PutDouble(p.config.ScoreAttribute, score)
+	event.Attributes().PutBool(p.config.ClassificationAttribute, true)
+	if modelName != "" && modelName != defaultModelName {
+		event.Attributes().PutStr(modelNameAttribute, modelName)
+	}
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "isolation forest anomaly, score %.3f", score)
+	if msg := record.Body().AsString(); msg != "" {
+		fmt.Fprintf(&body, ": %s", msg)
+	}
+	event.Body().SetStr(body.String())
+}
+
+// appendAnomalyEvents appends the anomaly events to rl, in a scope of their
+// own.
+func appendAnomalyEvents(rl plog.ResourceLogs, events plog.LogRecordSlice) {
+	if events.Len() == 0 {
+		return
+	}
+	sl := rl.ScopeLogs().AppendEmpty()
+	sl.Scope().SetName(metadata.ScopeName)
+	events.MoveAndAppendTo(sl.LogRecords())
+}
@@ -0,0 +1,168 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// output_test.go - Tests for anomaly results reported as metrics and log records
+package isolationforestprocessor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/isolationforestprocessor/internal/metadata"
+)
+
+func newOutputTestProcessor(t *testing.T, cfg *Config) *isolationForestProcessor {
+	t.Helper()
+	p, err := newIsolationForestProcessor(cfg, zaptest.NewLogger(t))
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, p.Shutdown(context.WithoutCancel(t.Context()))) })
+	return p
+}
+
+// makeSeriesMetrics returns a gauge with a data point per host.
+func makeSeriesMetrics(ts time.Time, values map[string]int64) pmetric.Metrics {
+	md := pmetric.NewMetrics()
+	rm := md.ResourceMetrics().AppendEmpty()
+	rm.Resource().Attributes().PutStr("service.name", "frontend")
+	m := rm.ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	m.SetName("queue_depth")
+	dps := m.SetEmptyGauge().DataPoints()
+	for host, value := range values {
+		dp := dps.AppendEmpty()
+		dp.Attributes().PutStr("host", host)
+		dp.SetIntValue(value)
+		dp.SetTimestamp(pcommon.NewTimestampFromTime(ts))
+	}
+	return md
+}
+
+func TestProcessMetricsScoresEachDataPoint(t *testing.T) {
+	cfg := baseTestConfig(t)
+	cfg.Features.Metrics = []string{"value", "rate_of_change"}
+	p := newOutputTestProcessor(t, cfg)
+
+	ts := time.Now()
+	_, err := p.processMetrics(t.Context(), makeSeriesMetrics(ts, map[string]int64{"a": 10, "b": 1000}))
+	require.NoError(t, err)
+	_, err = p.processMetrics(t.Context(), makeSeriesMetrics(ts.Add(10*time.Second), map[string]int64{"a": 20, "b": 1000}))
+	require.NoError(t, err)
+
+	assert.Equal(t, uint64(4), p.defaultForest.GetStatistics().TotalSamples, "every data point is a sample")
+	assert.Equal(t, 2, p.metricsExtractor.series.seriesCount(), "every host is a series")
+
+	s := p.metricsExtractor.series.series
+	for _, elem := range s {
+		state := elem.Value.(*seriesState)
+		assert.Contains(t, []float64{20, 1000}, state.previousValue, "integer values are read")
+	}
+}
+
+func TestProcessMetricsEnrichesEachDataPoint(t *testing.T) {
+	cfg := baseTestConfig(t)
+	p := newOutputTestProcessor(t, cfg)
+
+	md := pmetric.NewMetrics()
+	rm := md.ResourceMetrics().AppendEmpty()
+	m := rm.ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	m.SetName("requests_total")
+	dps := m.SetEmptySum().DataPoints()
+	for _, value := range []int64{1, 10, 1_000_000} {
+		dp := dps.AppendEmpty()
+		dp.Attributes().PutInt("value", value)
+		dp.SetIntValue(value)
+	}
+
+	md, err := p.processMetrics(t.Context(), md)
+	require.NoError(t, err)
+
+	scores := make(map[float64]bool)
+	dps = md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Sum().DataPoints()
+	for i := 0; i < dps.Len(); i++ {
+		attrs := dps.At(i).Attributes()
+		score, ok := attrs.Get(cfg.ScoreAttribute)
+		require.True(t, ok, "data point %d has no score", i)
+		_, ok = attrs.Get(cfg.ClassificationAttribute)
+		require.True(t, ok, "data point %d has no classification", i)
+		scores[score.Double()] = true
+	}
+	assert.Greater(t, len(scores), 1, "data points are scored individually, not with the first data point's score")
+	assert.Equal(t, uint64(3), p.defaultForest.GetStatistics().TotalSamples)
+}
+
+func TestOutputMetrics(t *testing.T) {
+	cfg := baseTestConfig(t)
+	cfg.Output = OutputConfig{Metrics: true}
+	p := newOutputTestProcessor(t, cfg)
+
+	ts := time.Now()
+	md, err := p.processMetrics(t.Context(), makeSeriesMetrics(ts, map[string]int64{"a": 10}))
+	require.NoError(t, err)
+
+	rm := md.ResourceMetrics().At(0)
+	require.Equal(t, 2, rm.ScopeMetrics().Len())
+	original := rm.ScopeMetrics().At(0).Metrics().At(0).Gauge().DataPoints().At(0)
+	assert.Equal(t, 1, original.Attributes().Len(), "with attributes disabled the data is not touched")
+
+	sm := rm.ScopeMetrics().At(1)
+	assert.Equal(t, metadata.ScopeName, sm.Scope().Name())
+	m := sm.Metrics().At(0)
+	assert.Equal(t, anomalyScoreMetricName, m.Name())
+	require.Equal(t, 1, m.Gauge().DataPoints().Len())
+	dp := m.Gauge().DataPoints().At(0)
+	assert.Equal(t, pcommon.NewTimestampFromTime(ts), dp.Timestamp())
+	assert.Equal(t, map[string]any{
+		"host":                      "a",
+		metricNameAttribute:         "queue_depth",
+		cfg.ClassificationAttribute: false,
+	}, dp.Attributes().AsRaw())
+	assert.GreaterOrEqual(t, dp.DoubleValue(), 0.0)
+}
+
+func TestOutputLogs(t *testing.T) {
+	cfg := baseTestConfig(t)
+	cfg.Output = OutputConfig{Attributes: true, Logs: true}
+	p := newOutputTestProcessor(t, cfg)
+
+	ld := makeLogs()
+	rl := ld.ResourceLogs().At(0)
+	lr := rl.ScopeLogs().At(0).LogRecords().At(0)
+	lr.Body().SetStr("connection refused")
+	lr.Attributes().PutStr("peer", "db")
+	lr.SetTraceID(pcommon.TraceID{1})
+
+	// Nothing is anomalous yet.
+	out, err := p.processLogs(t.Context(), ld)
+	require.NoError(t, err)
+	require.Equal(t, 1, out.ResourceLogs().At(0).ScopeLogs().Len())
+
+	// Everything is anomalous.
+	p.defaultForest.threshold = -1
+	out, err = p.processLogs(t.Context(), out)
+	require.NoError(t, err)
+
+	scopes := out.ResourceLogs().At(0).ScopeLogs()
+	require.Equal(t, 2, scopes.Len())
+	assert.Equal(t, metadata.ScopeName, scopes.At(1).Scope().Name())
+	require.Equal(t, 1, scopes.At(1).LogRecords().Len())
+	event := scopes.At(1).LogRecords().At(0)
+	assert.Equal(t, anomalyEventName, event.EventName())
+	assert.Equal(t, plog.SeverityNumberWarn, event.SeverityNumber())
+	assert.Equal(t, pcommon.TraceID{1}, event.TraceID())
+	assert.Contains(t, event.Body().Str(), ": connection refused")
+	peer, _ := event.Attributes().Get("peer")
+	assert.Equal(t, "db", peer.Str())
+	isAnomaly, _ := event.Attributes().Get(cfg.ClassificationAttribute)
+	assert.True(t, isAnomaly.Bool())
+
+	original := scopes.At(0).LogRecords().At(0)
+	isAnomaly, _ = original.Attributes().Get(cfg.ClassificationAttribute)
+	assert.True(t, isAnomaly.Bool(), "attributes are still added")
+}
@@ -0,0 +1,441 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// persistence.go - Model snapshots saved to storage and model files
+package isolationforestprocessor // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/isolationforestprocessor"
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/extension/xextension/storage"
+	"go.uber.org/zap"
+)
+
+const (
+	// snapshotFormatVersion is the version of the model snapshot format.
+	// Snapshots with a different format version are rejected.
+	snapshotFormatVersion = 1
+
+	// storageKeyPrefix is prepended to the model name to form the storage key
+	// of its snapshot.
+	storageKeyPrefix = "model_"
+
+	// defaultModelName is the name of the model used in single-model mode.
+	defaultModelName = "default"
+)
+
+// modelFile is the document read from model_file and written to
+// model_export_file.
+type modelFile struct {
+	Models []modelSnapshot `json:"models"`
+}
+
+// modelSnapshot is the persisted state of a single named model.
+type modelSnapshot struct {
+	FormatVersion int    `json:"format_version"`
+	Model         string `json:"model"`
+	// Version identifies the model a snapshot was derived from, e.g. the
+	// release of an offline pre-trained model. Snapshots saved by the
+	// processor carry the version of the model they were loaded from.
+	Version string    `json:"version,omitempty"`
+	SavedAt time.Time `json:"saved_at"`
+	// Features lists the features the model was trained on, in feature
+	// vector order. Snapshots are only restored when they match the
+	// configured features.
+	Features []string       `json:"features"`
+	Forest   forestSnapshot `json:"forest"`
+}
+
+// forestSnapshot is the state of an onlineIsolationForest.
+type forestSnapshot struct {
+	Threshold    float64         `json:"threshold"`
+	TotalSamples uint64          `json:"total_samples"`
+	AnomalyCount uint64          `json:"anomaly_count"`
+	ScoreHistory []float64       `json:"score_history"`
+	Window       [][]float64     `json:"window"` // oldest sample first
+	Trees        []*treeSnapshot `json:"trees"`
+}
+
+type treeSnapshot struct {
+	SampleCount int           `json:"sample_count"`
+	UpdateCount int           `json:"update_count"`
+	Root        *nodeSnapshot `json:"root,omitempty"`
+}
+
+// nodeSnapshot is a tree node. Nodes without children are leaves.
+type nodeSnapshot struct {
+	Feature     int           `json:"feature,omitempty"`
+	Split       float64       `json:"split,omitempty"`
+	SampleCount int           `json:"sample_count"`
+	Depth       int           `json:"depth"`
+	Left        *nodeSnapshot `json:"left,omitempty"`
+	Right       *nodeSnapshot `json:"right,omitempty"`
+}
+
+// snapshot returns the forest's current state. Each part of the state is
+// copied under its own lock, so that snapshotting does not stall scoring.
+func (oif *onlineIsolationForest) snapshot() forestSnapshot {
+	var s forestSnapshot
+
+	oif.treesMutex.RLock()
+	s.Trees = make([]*treeSnapshot, len(oif.trees))
+	for i, tree := range oif.trees {
+		s.Trees[i] = &treeSnapshot{
+			SampleCount: tree.sampleCount,
+			UpdateCount: tree.updateCount,
+			Root:        snapshotNode(tree.root),
+		}
+	}
+	oif.treesMutex.RUnlock()
+
+	oif.windowMutex.RLock()
+	size := min(oif.getCurrentWindowSize(), len(oif.dataWindow))
+	if oif.windowFull {
+		for i := range size {
+			if sample := oif.dataWindow[(oif.windowIndex+i)%size]; sample != nil {
+				s.Window = append(s.Window, slices.Clone(sample))
+			}
+		}
+	} else {
+		for i := 0; i < oif.windowIndex && i < size; i++ {
+			if sample := oif.dataWindow[i]; sample != nil {
+				s.Window = append(s.Window, slices.Clone(sample))
+			}
+		}
+	}
+	oif.windowMutex.RUnlock()
+
+	oif.thresholdMutex.RLock()
+	s.Threshold = oif.threshold
+	s.ScoreHistory = slices.Clone(oif.scoreHistory)
+	oif.thresholdMutex.RUnlock()
+
+	oif.statsMutex.RLock()
+	s.TotalSamples = oif.totalSamples
+	s.AnomalyCount = oif.anomalyCount
+	oif.statsMutex.RUnlock()
+
+	return s
+}
+
+func snapshotNode(node *onlineTreeNode) *nodeSnapshot {
+	if node == nil {
+		return nil
+	}
+	s := &nodeSnapshot{SampleCount: node.sampleCount, Depth: node.depth}
+	if !node.isLeaf && node.left != nil && node.right != nil {
+		s.Feature = node.featureIndex
+		s.Split = node.splitValue
+		s.Left = snapshotNode(node.left)
+		s.Right = snapshotNode(node.right)
+	}
+	return s
+}
+
+// restore replaces the forest's state with s. The snapshot must have as many
+// trees as the forest; the most recent samples of a window larger than the
+// forest's are kept.
+func (oif *onlineIsolationForest) restore(s forestSnapshot) error {
+	if len(s.Trees) != oif.numTrees {
+		return fmt.Errorf("snapshot has %d trees, forest_size is %d", len(s.Trees), oif.numTrees)
+	}
+
+	oif.treesMutex.Lock()
+	now := time.Now()
+	for i, ts := range s.Trees {
+		tree := &onlineIsolationTree{maxDepth: oif.maxDepth, lastUpdateTime: now}
+		if ts != nil {
+			tree.sampleCount = ts.SampleCount
+			tree.updateCount = ts.UpdateCount
+			tree.root = restoreNode(ts.Root)
+		}
+		oif.trees[i] = tree
+	}
+	oif.treesMutex.Unlock()
+
+	oif.windowMutex.Lock()
+	size := oif.getCurrentWindowSize()
+	window := s.Window
+	if len(window) > size {
+		window = window[len(window)-size:]
+	}
+	oif.dataWindow = make([][]float64, size)
+	copy(oif.dataWindow, window)
+	oif.windowIndex = len(window) % size
+	oif.windowFull = len(window) == size
+	oif.windowMutex.Unlock()
+
+	oif.thresholdMutex.Lock()
+	oif.threshold = s.Threshold
+	oif.scoreHistory = slices.Clone(s.ScoreHistory)
+	if len(oif.scoreHistory) > size {
+		oif.scoreHistory = oif.scoreHistory[len(oif.scoreHistory)-size:]
+	}
+	oif.thresholdMutex.Unlock()
+
+	oif.statsMutex.Lock()
+	oif.totalSamples = s.TotalSamples
+	oif.anomalyCount = s.AnomalyCount
+	oif.statsMutex.Unlock()
+	return nil
+}
+
+func restoreNode(s *nodeSnapshot) *onlineTreeNode {
+	if s == nil {
+		return nil
+	}
+	node := &onlineTreeNode{
+		sampleCount:    s.SampleCount,
+		depth:          s.Depth,
+		isLeaf:         true,
+		isolationScore: 0.5,
+	}
+	if s.Left != nil && s.Right != nil {
+		node.isLeaf = false
+		node.featureIndex = s.Feature
+		node.splitValue = s.Split
+		node.left = restoreNode(s.Left)
+		node.right = restoreNode(s.Right)
+	}
+	return node
+}
+
+// getStorageClient resolves a storage.Client for the processor. Processors
+// of different signals share the component ID, so the client is named after
+// the signal.
+func getStorageClient(ctx context.Context, host component.Host, storageID *component.ID, componentID component.ID, signal string) (storage.Client, error) {
+	ext, ok := host.GetExtensions()[*storageID]
+	if !ok {
+		return nil, fmt.Errorf("storage extension %q not found", storageID)
+	}
+
+	storageExt, ok := ext.(storage.Extension)
+	if !ok {
+		return nil, fmt.Errorf("extension %q is not a storage extension", storageID)
+	}
+
+	return storageExt.GetClient(ctx, component.KindProcessor, componentID, signal)
+}
+
+// signalFeatures returns the features of the processor's signal, in the order
+// they appear in feature vectors.
+func (p *isolationForestProcessor) signalFeatures() []string {
+	var features []string
+	switch p.signal {
+	case signalTraces:
+		features = p.config.Features.Traces
+	case signalMetrics:
+		features = p.config.Features.Metrics
+	case signalLogs:
+		features = p.config.Features.Logs
+	}
+	return slices.Sorted(slices.Values(features))
+}
+
+// forests returns the processor's models by name.
+func (p *isolationForestProcessor) forests() map[string]*onlineIsolationForest {
+	if p.defaultForest != nil {
+		return map[string]*onlineIsolationForest{defaultModelName: p.defaultForest}
+	}
+	return p.modelForests
+}
+
+// loadModels restores every model from the model file or storage. A model in
+// the model file wins over its stored snapshot, unless the stored snapshot was
+// derived from the same version. Models without a usable snapshot start
+// untrained.
+func (p *isolationForestProcessor) loadModels(ctx context.Context) error {
+	var fromFile map[string]modelSnapshot
+	if p.config.ModelFile != "" {
+		var err error
+		if fromFile, err = readModelFile(p.config.ModelFile); err != nil {
+			return err
+		}
+	}
+
+	for name, forest := range p.forests() {
+		stored, hasStored := p.readStoredSnapshot(ctx, name)
+		file, hasFile := fromFile[name]
+
+		var source string
+		var snapshot modelSnapshot
+		switch {
+		case hasFile && (!hasStored || stored.Version != file.Version):
+			source, snapshot = "model_file", file
+		case hasStored:
+			source, snapshot = "storage", stored
+		default:
+			continue
+		}
+
+		if err := p.restoreModel(forest, snapshot); err != nil {
+			p.logger.Warn("Failed to restore model snapshot, starting untrained",
+				zap.String("model_name", name), zap.String("source", source), zap.Error(err))
+			continue
+		}
+		p.modelVersions[name] = snapshot.Version
+		p.logger.Info("Restored model snapshot",
+			zap.String("model_name", name),
+			zap.String("source", source),
+			zap.String("version", snapshot.Version),
+			zap.Time("saved_at", snapshot.SavedAt),
+		)
+	}
+	return nil
+}
+
+func readModelFile(path string) (map[string]modelSnapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read model file: %w", err)
+	}
+	var f modelFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse model file %q: %w", path, err)
+	}
+	snapshots := make(map[string]modelSnapshot, len(f.Models))
+	for _, s := range f.Models {
+		snapshots[s.Model] = s
+	}
+	return snapshots, nil
+}
+
+// readStoredSnapshot returns the model's snapshot from storage, if any.
+// Unreadable snapshots are logged and ignored.
+func (p *isolationForestProcessor) readStoredSnapshot(ctx context.Context, name string) (modelSnapshot, bool) {
+	if p.storageClient == nil {
+		return modelSnapshot{}, false
+	}
+	data, err := p.storageClient.Get(ctx, storageKeyPrefix+name)
+	if err != nil {
+		p.logger.Warn("Failed to read model snapshot from storage", zap.String("model_name", name), zap.Error(err))
+		return modelSnapshot{}, false
+	}
+	if len(data) == 0 {
+		return modelSnapshot{}, false
+	}
+	var s modelSnapshot
+	if err := json.Unmarshal(data, &s); err != nil {
+		p.logger.Warn("Failed to parse model snapshot from storage", zap.String("model_name", name), zap.Error(err))
+		return modelSnapshot{}, false
+	}
+	return s, true
+}
+
+// restoreModel checks that s is compatible with the configuration and
+// restores it into forest.
+func (p *isolationForestProcessor) restoreModel(forest *onlineIsolationForest, s modelSnapshot) error {
+	if s.FormatVersion != snapshotFormatVersion {
+		return fmt.Errorf("unsupported snapshot format version %d, expected %d", s.FormatVersion, snapshotFormatVersion)
+	}
+	if features := p.signalFeatures(); !slices.Equal(s.Features, features) {
+		return fmt.Errorf("snapshot was trained on features %v, configured features are %v", s.Features, features)
+	}
+	return forest.restore(s.Forest)
+}
+
+// snapshotModels returns a snapshot of every model, sorted by name.
+func (p *isolationForestProcessor) snapshotModels() []modelSnapshot {
+	now := time.Now()
+	features := p.signalFeatures()
+
+	p.forestsMutex.RLock()
+	defer p.forestsMutex.RUnlock()
+	forests := p.forests()
+	snapshots := make([]modelSnapshot, 0, len(forests))
+	for name, forest := range forests {
+		snapshots = append(snapshots, modelSnapshot{
+			FormatVersion: snapshotFormatVersion,
+			Model:         name,
+			Version:       p.modelVersions[name],
+			SavedAt:       now,
+			Features:      features,
+			Forest:        forest.snapshot(),
+		})
+	}
+	slices.SortFunc(snapshots, func(a, b modelSnapshot) int {
+		return strings.Compare(a.Model, b.Model)
+	})
+	return snapshots
+}
+
+// saveModels writes every model to storage and to the model export file, when
+// configured.
+func (p *isolationForestProcessor) saveModels(ctx context.Context) error {
+	snapshots := p.snapshotModels()
+
+	var errs []error
+	if p.storageClient != nil {
+		for _, s := range snapshots {
+			data, err := json.Marshal(s)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("model %q snapshot serialization failed: %w", s.Model, err))
+				continue
+			}
+			if err := p.storageClient.Set(ctx, storageKeyPrefix+s.Model, data); err != nil {
+				errs = append(errs, fmt.Errorf("failed to write model %q snapshot to storage: %w", s.Model, err))
+			}
+		}
+	}
+	if p.config.ModelExportFile != "" {
+		if err := writeModelFile(p.config.ModelExportFile, modelFile{Models: snapshots}); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) == 0 {
+		p.logger.Debug("Saved model snapshots", zap.Int("models", len(snapshots)))
+	}
+	return errors.Join(errs...)
+}
+
+// writeModelFile writes f to path. The file is replaced atomically, so readers
+// never observe a partially written file.
+func writeModelFile(path string, f modelFile) error {
+	data, err := json.Marshal(f)
+	if err != nil {
+		return fmt.Errorf("model file serialization failed: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("failed to write model file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(data); err == nil {
+		err = tmp.Close()
+	} else {
+		_ = tmp.Close()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write model file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write model file: %w", err)
+	}
+	return nil
+}
+
+// modelSaveLoop saves the models every save_interval until the processor is
+// shut down.
+func (p *isolationForestProcessor) modelSaveLoop(ctx context.Context) {
+	ticker := time.NewTicker(p.config.SaveInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := p.saveModels(ctx); err != nil {
+				p.logger.Warn("Periodic model snapshot save failed", zap.Error(err))
+			}
+		case <-p.stopChan:
+			return
+		}
+	}
+}
@@ -0,0 +1,285 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// persistence_test.go - Tests for model snapshots
+package isolationforestprocessor
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/storage/storagetest"
+)
+
+var probes = [][]float64{{0, 0}, {5, 3}, {9, 12}, {100, -40}}
+
+// trainForest feeds n deterministic samples to forest.
+func trainForest(forest *onlineIsolationForest, n int) {
+	for i := range n {
+		forest.ProcessSample([]float64{float64(i % 10), float64((i * 7) % 13)})
+	}
+}
+
+func scores(forest *onlineIsolationForest) []float64 {
+	out := make([]float64, len(probes))
+	for i, probe := range probes {
+		out[i] = forest.calculateAnomalyScore(probe)
+	}
+	return out
+}
+
+func TestForestSnapshotRoundTrip(t *testing.T) {
+	for _, n := range []int{30, 500} { // partially filled and wrapped windows
+		forest := newOnlineIsolationForest(10, 64, 0, 0.1, 2)
+		trainForest(forest, n)
+
+		data, err := json.Marshal(forest.snapshot())
+		require.NoError(t, err)
+		var snapshot forestSnapshot
+		require.NoError(t, json.Unmarshal(data, &snapshot))
+
+		restored := newOnlineIsolationForest(10, 64, 0, 0.1, 2)
+		require.NoError(t, restored.restore(snapshot))
+
+		assert.Equal(t, scores(forest), scores(restored), "restored forest scores like the original after %d samples", n)
+		assert.Equal(t, forest.GetStatistics(), restored.GetStatistics())
+		assert.Equal(t, forest.snapshot(), restored.snapshot())
+	}
+}
+
+func TestForestRestoreShrinksWindow(t *testing.T) {
+	forest := newOnlineIsolationForest(10, 64, 0, 0.1, 2)
+	trainForest(forest, 100)
+	snapshot := forest.snapshot()
+
+	restored := newOnlineIsolationForest(10, 16, 0, 0.1, 2)
+	require.NoError(t, restored.restore(snapshot))
+	assert.Equal(t, snapshot.Window[len(snapshot.Window)-16:], restored.snapshot().Window, "the most recent samples are kept")
+}
+
+func TestForestRestoreTreeCountMismatch(t *testing.T) {
+	forest := newOnlineIsolationForest(10, 64, 0, 0.1, 2)
+	trainForest(forest, 100)
+
+	restored := newOnlineIsolationForest(5, 64, 0, 0.1, 2)
+	assert.ErrorContains(t, restored.restore(forest.snapshot()), "snapshot has 10 trees, forest_size is 5")
+}
+
+// persistenceTestConfig returns a logs config whose windows fill quickly.
+func persistenceTestConfig(t *testing.T) *Config {
+	cfg := baseTestConfig(t)
+	cfg.Performance.BatchSize = 32
+	cfg.MinNodeSamples = 2
+	return cfg
+}
+
+func newLogsTestProcessor(t *testing.T, cfg *Config) *isolationForestProcessor {
+	t.Helper()
+	p, err := newIsolationForestProcessor(cfg, zaptest.NewLogger(t))
+	require.NoError(t, err)
+	p.componentID = component.MustNewID("isolationforest")
+	p.signal = signalLogs
+	return p
+}
+
+func feedLogs(t *testing.T, p *isolationForestProcessor, n int) {
+	t.Helper()
+	for i := range n {
+		ld := makeLogs()
+		lr := ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+		lr.SetSeverityNumber(plog.SeverityNumber(1 + i%20))
+		lr.Body().SetStr(string(make([]byte, i%37)))
+		_, err := p.processLogs(t.Context(), ld)
+		require.NoError(t, err)
+	}
+}
+
+func storageHost(t *testing.T) (*storagetest.StorageHost, *component.ID) {
+	t.Helper()
+	id := storagetest.NewStorageID("test")
+	return storagetest.NewStorageHost().WithFileBackedStorageExtension("test", t.TempDir()), &id
+}
+
+func TestModelsPersistAcrossRestarts(t *testing.T) {
+	host, sid := storageHost(t)
+	ctx := context.WithoutCancel(t.Context())
+
+	cfg := persistenceTestConfig(t)
+	cfg.Storage = sid
+	p1 := newLogsTestProcessor(t, cfg)
+	require.NoError(t, p1.Start(ctx, host))
+	feedLogs(t, p1, 200)
+	require.NoError(t, p1.Shutdown(ctx))
+	want := scores(p1.defaultForest)
+
+	p2 := newLogsTestProcessor(t, cfg)
+	require.NoError(t, p2.Start(ctx, host))
+	assert.Equal(t, want, scores(p2.defaultForest))
+	assert.Equal(t, uint64(200), p2.defaultForest.GetStatistics().TotalSamples)
+	require.NoError(t, p2.Shutdown(ctx))
+}
+
+func TestModelsPersistPerModelName(t *testing.T) {
+	host, sid := storageHost(t)
+	ctx := context.WithoutCancel(t.Context())
+
+	cfg := persistenceTestConfig(t)
+	cfg.Storage = sid
+	cfg.Models = []ModelConfig{
+		{Name: "frontend", Selector: map[string]string{"service.name": "frontend"}, ForestSize: 10, ContaminationRate: 0.1},
+		{Name: "backend", Selector: map[string]string{"service.name": "backend"}, ForestSize: 5, ContaminationRate: 0.1},
+	}
+	p1 := newLogsTestProcessor(t, cfg)
+	require.NoError(t, p1.Start(ctx, host))
+	feedLogs(t, p1, 100)
+	require.NoError(t, p1.Shutdown(ctx))
+
+	p2 := newLogsTestProcessor(t, cfg)
+	require.NoError(t, p2.Start(ctx, host))
+	assert.Equal(t, uint64(100), p2.modelForests["frontend"].GetStatistics().TotalSamples)
+	assert.Zero(t, p2.modelForests["backend"].GetStatistics().TotalSamples)
+	assert.Equal(t, scores(p1.modelForests["frontend"]), scores(p2.modelForests["frontend"]))
+	require.NoError(t, p2.Shutdown(ctx))
+}
+
+func TestModelSnapshotRejectedOnFeatureChange(t *testing.T) {
+	host, sid := storageHost(t)
+	ctx := context.WithoutCancel(t.Context())
+
+	cfg := persistenceTestConfig(t)
+	cfg.Storage = sid
+	p1 := newLogsTestProcessor(t, cfg)
+	require.NoError(t, p1.Start(ctx, host))
+	feedLogs(t, p1, 50)
+	require.NoError(t, p1.Shutdown(ctx))
+
+	cfg2 := persistenceTestConfig(t)
+	cfg2.Storage = sid
+	cfg2.Features.Logs = []string{"severity_number"}
+	p2 := newLogsTestProcessor(t, cfg2)
+	require.NoError(t, p2.Start(ctx, host))
+	assert.Zero(t, p2.defaultForest.GetStatistics().TotalSamples, "a model trained on other features starts untrained")
+	require.NoError(t, p2.Shutdown(ctx))
+}
+
+// TestModelFileVersioning verifies that a model file replaces stored
+// snapshots derived from another version, and that snapshots derived from
+// the file's version are kept.
+func TestModelFileVersioning(t *testing.T) {
+	host, sid := storageHost(t)
+	ctx := context.WithoutCancel(t.Context())
+	dir := t.TempDir()
+	exportPath := filepath.Join(dir, "export.json")
+	modelPath := filepath.Join(dir, "models.json")
+
+	// Pre-train offline and export the model.
+	offline := persistenceTestConfig(t)
+	offline.ModelExportFile = exportPath
+	p := newLogsTestProcessor(t, offline)
+	require.NoError(t, p.Start(ctx, host))
+	feedLogs(t, p, 150)
+	require.NoError(t, p.Shutdown(ctx))
+
+	data, err := os.ReadFile(exportPath)
+	require.NoError(t, err)
+	var f modelFile
+	require.NoError(t, json.Unmarshal(data, &f))
+	require.Len(t, f.Models, 1)
+	assert.Equal(t, defaultModelName, f.Models[0].Model)
+	assert.Equal(t, []string{"message_length", "severity_number"}, f.Models[0].Features)
+	f.Models[0].Version = "v1"
+	writeTestModelFile(t, modelPath, f)
+
+	cfg := persistenceTestConfig(t)
+	cfg.Storage = sid
+	cfg.ModelFile = modelPath
+
+	// First deploy: nothing stored, the file is loaded.
+	p1 := newLogsTestProcessor(t, cfg)
+	require.NoError(t, p1.Start(ctx, host))
+	assert.Equal(t, uint64(150), p1.defaultForest.GetStatistics().TotalSamples)
+	feedLogs(t, p1, 10)
+	require.NoError(t, p1.Shutdown(ctx))
+
+	// Restart with the same file: the stored snapshot, derived from v1, wins.
+	p2 := newLogsTestProcessor(t, cfg)
+	require.NoError(t, p2.Start(ctx, host))
+	assert.Equal(t, uint64(160), p2.defaultForest.GetStatistics().TotalSamples)
+	assert.Equal(t, "v1", p2.modelVersions[defaultModelName])
+	require.NoError(t, p2.Shutdown(ctx))
+
+	// New model version: the file replaces the stored snapshot.
+	f.Models[0].Version = "v2"
+	writeTestModelFile(t, modelPath, f)
+	p3 := newLogsTestProcessor(t, cfg)
+	require.NoError(t, p3.Start(ctx, host))
+	assert.Equal(t, uint64(150), p3.defaultForest.GetStatistics().TotalSamples)
+	assert.Equal(t, "v2", p3.modelVersions[defaultModelName])
+	require.NoError(t, p3.Shutdown(ctx))
+}
+
+func writeTestModelFile(t *testing.T, path string, f modelFile) {
+	t.Helper()
+	data, err := json.Marshal(f)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+}
+
+func TestModelFileErrors(t *testing.T) {
+	dir := t.TempDir()
+	invalid := filepath.Join(dir, "invalid.json")
+	require.NoError(t, os.WriteFile(invalid, []byte("{"), 0o600))
+
+	for name, path := range map[string]string{
+		"missing": filepath.Join(dir, "missing.json"),
+		"invalid": invalid,
+	} {
+		t.Run(name, func(t *testing.T) {
+			cfg := persistenceTestConfig(t)
+			cfg.ModelFile = path
+			p := newLogsTestProcessor(t, cfg)
+			assert.Error(t, p.Start(t.Context(), componenttest.NewNopHost()))
+			require.NoError(t, p.Shutdown(context.WithoutCancel(t.Context())))
+		})
+	}
+}
+
+func TestModelPeriodicSave(t *testing.T) {
+	exportPath := filepath.Join(t.TempDir(), "export.json")
+	cfg := persistenceTestConfig(t)
+	cfg.ModelExportFile = exportPath
+	cfg.SaveInterval = 10 * time.Millisecond
+	p := newLogsTestProcessor(t, cfg)
+	require.NoError(t, p.Start(t.Context(), componenttest.NewNopHost()))
+	t.Cleanup(func() { require.NoError(t, p.Shutdown(context.WithoutCancel(t.Context()))) })
+	feedLogs(t, p, 10)
+
+	assert.Eventually(t, func() bool {
+		data, err := os.ReadFile(exportPath)
+		if err != nil {
+			return false
+		}
+		var f modelFile
+		return json.Unmarshal(data, &f) == nil && len(f.Models) == 1 && f.Models[0].Forest.TotalSamples == 10
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestStorageExtensionNotFound(t *testing.T) {
+	_, sid := storageHost(t)
+	cfg := persistenceTestConfig(t)
+	cfg.Storage = sid
+	p := newLogsTestProcessor(t, cfg)
+	assert.ErrorContains(t, p.Start(t.Context(), componenttest.NewNopHost()), "storage extension")
+	require.NoError(t, p.Shutdown(context.WithoutCancel(t.Context())))
+}
@@ -6,15 +6,18 @@ package isolationforestprocessor // import "github.com/open-telemetry/openteleme
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"hash/fnv"
 	"maps"
 	"math"
+	"slices"
 	"strconv"
 	"sync"
 	"time"
 
 	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/extension/xextension/storage"
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/plog"
 	"go.opentelemetry.io/collector/pdata/pmetric"
@@ -22,11 +25,20 @@ import (
 	"go.uber.org/zap"
 )
 
+// Signal names, used to tell apart the processors created for each signal.
+const (
+	signalTraces  = "traces"
+	signalMetrics = "metrics"
+	signalLogs    = "logs"
+)
+
 // isolationForestProcessor is the core processor that contains the isolation forest
 // algorithm implementation and coordinates processing across different signal types.
 type isolationForestProcessor struct {
-	config *Config
-	logger *zap.Logger
+	config      *Config
+	logger      *zap.Logger
+	componentID component.ID
+	signal      string // set by the factory; one of signalTraces, signalMetrics or signalLogs
 
 	// Machine learning components
 	defaultForest *onlineIsolationForest            // Default model for single-model mode
@@ -48,6 +60,10 @@ type isolationForestProcessor struct {
 	updateTicker    *time.Ticker
 	stopChan        chan struct{}
 	shutdownWG      sync.WaitGroup
+
+	// Model persistence
+	storageClient storage.Client
+	modelVersions map[string]string // version of the snapshot each model was restored from
 }
 
 // newIsolationForestProcessor creates a new processor instance with the specified configuration.
@@ -58,13 +74,14 @@ func newIsolationForestProcessor(config *Config, logger *zap.Logger) (*isolation
 		config:          config,
 		logger:          logger,
 		modelForests:    make(map[string]*onlineIsolationForest),
+		modelVersions:   make(map[string]string),
 		stopChan:        make(chan struct{}),
 		lastModelUpdate: time.Now(),
 	}
 
 	// Initialize feature extractors for different signal types
 	processor.traceExtractor = newTraceFeatureExtractor(config.Features.Traces, logger)
-	processor.metricsExtractor = newMetricsFeatureExtractor(config.Features.Metrics, logger)
+	processor.metricsExtractor = newMetricsFeatureExtractor(config.Features.Metrics, config.Seasonality, logger)
 	processor.logsExtractor = newLogsFeatureExtractor(config.Features.Logs, logger)
 
 	// Initialize isolation forest models based on configuration mode
@@ -143,21 +160,37 @@ func newIsolationForestProcessor(config *Config, logger *zap.Logger) (*isolation
 	return processor, nil
 }
 
-// Start initializes the processor
-func (p *isolationForestProcessor) Start(_ context.Context, _ component.Host) error {
+// Start restores model snapshots, when configured, and starts the background
+// model update and save loops.
+func (p *isolationForestProcessor) Start(ctx context.Context, host component.Host) error {
 	p.logger.Info("Starting isolation forest processor")
-	// Any additional initialization logic can go here
+
+	if p.config.Storage != nil {
+		var err error
+		p.storageClient, err = getStorageClient(ctx, host, p.config.Storage, p.componentID, p.signal)
+		if err != nil {
+			return fmt.Errorf("failed to get storage client: %w", err)
+		}
+	}
+	if err := p.loadModels(ctx); err != nil {
+		return err
+	}
 
 	// Start the background model update loop
 	p.shutdownWG.Go(func() {
 		p.modelUpdateLoop()
 	})
+	if p.config.SaveInterval > 0 {
+		p.shutdownWG.Go(func() {
+			p.modelSaveLoop(context.Background())
+		})
+	}
 
 	return nil
 }
 
 // Shutdown gracefully stops the processor and cleans up resources.
-func (p *isolationForestProcessor) Shutdown(_ context.Context) error {
+func (p *isolationForestProcessor) Shutdown(ctx context.Context) error {
 	p.logger.Info("Shutting down isolation forest processor")
 
 	// Stop the update ticker
@@ -171,8 +204,21 @@ func (p *isolationForestProcessor) Shutdown(_ context.Context) error {
 	// Wait for all background goroutines to complete
 	p.shutdownWG.Wait()
 
+	var errs []error
+	if p.storageClient != nil || p.config.ModelExportFile != "" {
+		if err := p.saveModels(ctx); err != nil {
+			p.logger.Warn("Final model snapshot save failed", zap.Error(err))
+			errs = append(errs, err)
+		}
+	}
+	if p.storageClient != nil {
+		if err := p.storageClient.Close(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
 	p.logger.Info("Isolation forest processor shutdown complete")
-	return nil
+	return errors.Join(errs...)
 }
 
 // modelUpdateLoop runs periodic model updates in the background to adapt to changing patterns.
@@ -274,10 +320,12 @@ func (p *isolationForestProcessor) processFeatures(features map[string][]float64
 		return 0.0, false, ""
 	}
 
-	// Combine all features into a single feature vector
+	// Combine all features into a single feature vector, in feature name
+	// order so that each position always holds the same feature, including
+	// across restarts of a persisted model.
 	var combinedFeatures []float64
-	for _, featureVector := range features {
-		combinedFeatures = append(combinedFeatures, featureVector...)
+	for _, name := range slices.Sorted(maps.Keys(features)) {
+		combinedFeatures = append(combinedFeatures, features[name]...)
 	}
 
 	if len(combinedFeatures) == 0 {
@@ -340,7 +388,7 @@ func (p *isolationForestProcessor) processTraces(ctx context.Context, td ptrace.
 				span.CopyTo(newSpan)
 
 				// Add anomaly attributes in enrich or both modes
-				if p.config.Mode == "enrich" || p.config.Mode == "both" {
+				if p.enrichesAttributes() {
 					newSpan.Attributes().PutDouble(p.config.ScoreAttribute, score)
 					newSpan.Attributes().PutBool(p.config.ClassificationAttribute, isAnomaly)
 					if modelName != "" && modelName != "default" {
@@ -372,29 +420,69 @@ func (p *isolationForestProcessor) processMetrics(ctx context.Context, md pmetri
 		rm := md.ResourceMetrics().At(i)
 		resourceAttrs := attributeMapToGeneric(rm.Resource().Attributes())
 
+		// Anomaly scores reported as a separate metric
+		results := pmetric.NewNumberDataPointSlice()
+
 		for j := 0; j < rm.ScopeMetrics().Len(); j++ {
 			sm := rm.ScopeMetrics().At(j)
 
 			for k := 0; k < sm.Metrics().Len(); k++ {
 				metric := sm.Metrics().At(k)
 
-				// Extract features based on metric type
-				features := p.metricsExtractor.ExtractFeatures(metric, resourceAttrs)
-
-				// Process through isolation forest
-				score, isAnomaly, modelName := p.processFeatures(features, resourceAttrs)
-
-				// Add anomaly attributes to metric data points
-				if p.config.Mode == "enrich" || p.config.Mode == "both" {
-					p.addAnomalyAttributesToMetric(metric, score, isAnomaly, modelName)
+				switch metric.Type() {
+				case pmetric.MetricTypeGauge:
+					p.processNumberDataPoints(metric.Name(), metric.Gauge().DataPoints(), rm.Resource().Attributes(), resourceAttrs, results)
+				case pmetric.MetricTypeSum:
+					p.processNumberDataPoints(metric.Name(), metric.Sum().DataPoints(), rm.Resource().Attributes(), resourceAttrs, results)
+				default:
+					// Extract features based on metric type
+					features := p.metricsExtractor.ExtractFeatures(metric, resourceAttrs)
+
+					// Process through isolation forest
+					score, isAnomaly, modelName := p.processFeatures(features, resourceAttrs)
+
+					// Add anomaly attributes to metric data points
+					if p.enrichesAttributes() {
+						p.addAnomalyAttributesToMetric(metric, score, isAnomaly, modelName)
+					}
 				}
 			}
 		}
+
+		if p.config.Output.Metrics {
+			appendScoreMetric(rm, results)
+		}
 	}
 
 	return md, nil
 }
 
+// processNumberDataPoints scores each data point of a gauge or sum as a
+// sample of its own series, adding the scores to results when the scores are
+// reported as a separate metric.
+func (p *isolationForestProcessor) processNumberDataPoints(metricName string, dps pmetric.NumberDataPointSlice, resource pcommon.Map, resourceAttrs map[string]any, results pmetric.NumberDataPointSlice) {
+	for i := 0; i < dps.Len(); i++ {
+		dp := dps.At(i)
+		features := p.metricsExtractor.ExtractDataPointFeatures(
+			seriesKey(metricName, resource, dp.Attributes()), numberValue(dp), timestampOrNow(dp.Timestamp()))
+
+		// Combine data point and resource attributes for model selection
+		allAttrs := mergeAttributes(resourceAttrs, attributeMapToGeneric(dp.Attributes()))
+		score, isAnomaly, modelName := p.processFeatures(features, allAttrs)
+
+		if p.config.Output.Metrics {
+			p.appendAnomalyScore(results, metricName, dp, score, isAnomaly, modelName)
+		}
+		if p.enrichesAttributes() {
+			dp.Attributes().PutDouble(p.config.ScoreAttribute, score)
+			dp.Attributes().PutBool(p.config.ClassificationAttribute, isAnomaly)
+			if modelName != "" && modelName != "default" {
+				dp.Attributes().PutStr("anomaly.model_name", modelName)
+			}
+		}
+	}
+}
+
 // processLogs processes log telemetry
 func (p *isolationForestProcessor) processLogs(ctx context.Context, ld plog.Logs) (plog.Logs, error) {
 	// Honor cancellation/deadline; satisfies unparam + uses ctx.
@@ -407,6 +495,9 @@ func (p *isolationForestProcessor) processLogs(ctx context.Context, ld plog.Logs
 		rl := ld.ResourceLogs().At(i)
 		resourceAttrs := attributeMapToGeneric(rl.Resource().Attributes())
 
+		// Anomaly events reported as separate log records
+		events := plog.NewLogRecordSlice()
+
 		for j := 0; j < rl.ScopeLogs().Len(); j++ {
 			sl := rl.ScopeLogs().At(j)
 
@@ -426,6 +517,10 @@ func (p *isolationForestProcessor) processLogs(ctx context.Context, ld plog.Logs
 				// Process through isolation forest
 				score, isAnomaly, modelName := p.processFeatures(features, allAttrs)
 
+				if isAnomaly && p.config.Output.Logs {
+					p.appendAnomalyEvent(events, record, score, modelName)
+				}
+
 				// Apply processing mode
 				if p.config.Mode == "filter" && !isAnomaly {
 					// Skip this log record - don't add to newLogs
@@ -437,7 +532,7 @@ func (p *isolationForestProcessor) processLogs(ctx context.Context, ld plog.Logs
 				record.CopyTo(newRecord)
 
 				// Add anomaly attributes in enrich or both modes
-				if p.config.Mode == "enrich" || p.config.Mode == "both" {
+				if p.enrichesAttributes() {
 					newRecord.Attributes().PutDouble(p.config.ScoreAttribute, score)
 					newRecord.Attributes().PutBool(p.config.ClassificationAttribute, isAnomaly)
 					if modelName != "" && modelName != "default" {
@@ -453,6 +548,8 @@ func (p *isolationForestProcessor) processLogs(ctx context.Context, ld plog.Logs
 				newLogs.At(k).CopyTo(newRecord)
 			}
 		}
+
+		appendAnomalyEvents(rl, events)
 	}
 
 	return ld, nil
@@ -578,24 +675,24 @@ type metricsFeatureExtractor struct {
 	features []string
 	logger   *zap.Logger
 
-	// Track previous values for rate calculation
-	previousValues map[string]float64
-	previousTimes  map[string]time.Time
-	mutex          sync.Mutex
+	// Per-series history for rate_of_change and the seasonality-aware
+	// features; nil if none is configured
+	series *seriesTracker
+	mutex  sync.Mutex
 }
 
-func newMetricsFeatureExtractor(features []string, logger *zap.Logger) *metricsFeatureExtractor {
+func newMetricsFeatureExtractor(features []string, seasonality SeasonalityConfig, logger *zap.Logger) *metricsFeatureExtractor {
 	return &metricsFeatureExtractor{
-		features:       features,
-		logger:         logger,
-		previousValues: make(map[string]float64),
-		previousTimes:  make(map[string]time.Time),
+		features: features,
+		logger:   logger,
+		series:   newSeriesTracker(features, seasonality),
 	}
 }
 
+// ExtractFeatures extracts the features of the first data point of a metric,
+// keyed by metric name. Number data points are scored individually through
+// ExtractDataPointFeatures instead.
 func (mfe *metricsFeatureExtractor) ExtractFeatures(metric pmetric.Metric, _ map[string]any) map[string][]float64 {
-	features := make(map[string][]float64)
-
 	// Extract primary metric value based on type
 	var currentValue float64
 	var timestamp time.Time
@@ -604,40 +701,32 @@ func (mfe *metricsFeatureExtractor) ExtractFeatures(metric pmetric.Metric, _ map
 	case pmetric.MetricTypeGauge:
 		if metric.Gauge().DataPoints().Len() > 0 {
 			dp := metric.Gauge().DataPoints().At(0)
-			currentValue = dp.DoubleValue()
+			currentValue = numberValue(dp)
 			timestamp = time.Unix(0, int64(dp.Timestamp()))
 		}
 	case pmetric.MetricTypeSum:
 		if metric.Sum().DataPoints().Len() > 0 {
 			dp := metric.Sum().DataPoints().At(0)
-			currentValue = dp.DoubleValue()
+			currentValue = numberValue(dp)
 			timestamp = time.Unix(0, int64(dp.Timestamp()))
 		}
 	}
 
-	metricKey := metric.Name()
+	return mfe.ExtractDataPointFeatures(metric.Name(), currentValue, timestamp)
+}
 
-	for _, featureName := range mfe.features {
-		switch featureName {
-		case "value":
-			features["value"] = []float64{currentValue}
-
-		case "rate_of_change":
-			// Calculate rate of change from previous value
-			mfe.mutex.Lock()
-			if prevValue, exists := mfe.previousValues[metricKey]; exists {
-				if prevTime, timeExists := mfe.previousTimes[metricKey]; timeExists {
-					timeDiff := timestamp.Sub(prevTime).Seconds()
-					if timeDiff > 0 {
-						rate := (currentValue - prevValue) / timeDiff
-						features["rate_of_change"] = []float64{rate}
-					}
-				}
-			}
-			mfe.previousValues[metricKey] = currentValue
-			mfe.previousTimes[metricKey] = timestamp
-			mfe.mutex.Unlock()
-		}
+// ExtractDataPointFeatures extracts the features of a value observed at
+// timestamp on the series identified by seriesKey.
+func (mfe *metricsFeatureExtractor) ExtractDataPointFeatures(seriesKey string, currentValue float64, timestamp time.Time) map[string][]float64 {
+	features := make(map[string][]float64)
+	if slices.Contains(mfe.features, "value") {
+		features["value"] = []float64{currentValue}
+	}
+
+	if mfe.series != nil {
+		mfe.mutex.Lock()
+		mfe.series.observe(seriesKey, currentValue, timestamp, features)
+		mfe.mutex.Unlock()
 	}
 
 	return features
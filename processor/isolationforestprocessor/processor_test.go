@@ -854,7 +854,7 @@ func Test_traceFeatureExtractor_MissingAttributeValue(t *testing.T) {
 func Test_metricsFeatureExtractor_RateCalculation(t *testing.T) {
 	features := []string{"value", "rate_of_change"}
 	logger := zaptest.NewLogger(t)
-	extractor := newMetricsFeatureExtractor(features, logger)
+	extractor := newMetricsFeatureExtractor(features, createDefaultConfig().(*Config).Seasonality, logger)
 
 	// Create first metric
 	metric1 := pmetric.NewMetric()
@@ -886,7 +886,7 @@ func Test_metricsFeatureExtractor_RateCalculation(t *testing.T) {
 func Test_metricsFeatureExtractor_SumMetric(t *testing.T) {
 	features := []string{"value"}
 	logger := zaptest.NewLogger(t)
-	extractor := newMetricsFeatureExtractor(features, logger)
+	extractor := newMetricsFeatureExtractor(features, createDefaultConfig().(*Config).Seasonality, logger)
 
 	metric := pmetric.NewMetric()
 	metric.SetName("test_sum")
@@ -903,7 +903,7 @@ func Test_metricsFeatureExtractor_SumMetric(t *testing.T) {
 func Test_metricsFeatureExtractor_NoDataPoints(t *testing.T) {
 	features := []string{"value"}
 	logger := zaptest.NewLogger(t)
-	extractor := newMetricsFeatureExtractor(features, logger)
+	extractor := newMetricsFeatureExtractor(features, createDefaultConfig().(*Config).Seasonality, logger)
 
 	metric := pmetric.NewMetric()
 	metric.SetName("empty_gauge")
@@ -920,7 +920,7 @@ func Test_metricsFeatureExtractor_NoDataPoints(t *testing.T) {
 func Test_metricsFeatureExtractor_ZeroTimeDiff(t *testing.T) {
 	features := []string{"rate_of_change"}
 	logger := zaptest.NewLogger(t)
-	extractor := newMetricsFeatureExtractor(features, logger)
+	extractor := newMetricsFeatureExtractor(features, createDefaultConfig().(*Config).Seasonality, logger)
 
 	// Create two metrics with same timestamp (zero time diff)
 	timestamp := time.Now()
@@ -1172,7 +1172,7 @@ func Test_traceFeatureExtractor_UnknownFeature(t *testing.T) {
 func Test_metricsFeatureExtractor_UnknownFeature(t *testing.T) {
 	features := []string{"unknown_feature"}
 	logger := zaptest.NewLogger(t)
-	extractor := newMetricsFeatureExtractor(features, logger)
+	extractor := newMetricsFeatureExtractor(features, createDefaultConfig().(*Config).Seasonality, logger)
 
 	metric := pmetric.NewMetric()
 	metric.SetName("test")
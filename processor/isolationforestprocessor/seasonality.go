@@ -0,0 +1,234 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// seasonality.go - Per-series metric features, including the seasonality-aware ones
+package isolationforestprocessor // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/isolationforestprocessor"
+
+import (
+	"container/list"
+	"math"
+	"time"
+)
+
+// Seasonality-aware metric feature names.
+const (
+	featureHourOfDay     = "hour_of_day"
+	featureHourOfWeek    = "hour_of_week"
+	featureRollingZScore = "rolling_zscore"
+	featureEWMAResidual  = "ewma_residual"
+)
+
+// maxStandardScore bounds the standardized residuals, so that a series that
+// was constant until now does not produce arbitrarily large features.
+const maxStandardScore = 10.0
+
+// isSeriesFeature reports whether name is a metric feature that requires
+// per-series state.
+func isSeriesFeature(name string) bool {
+	switch name {
+	case "rate_of_change", featureHourOfDay, featureHourOfWeek, featureRollingZScore, featureEWMAResidual:
+		return true
+	}
+	return false
+}
+
+// runningStat is the running mean and variance of a stream of values,
+// computed with Welford's algorithm.
+type runningStat struct {
+	count uint64
+	mean  float64
+	m2    float64
+}
+
+func (s *runningStat) add(v float64) {
+	s.count++
+	delta := v - s.mean
+	s.mean += delta / float64(s.count)
+	s.m2 += delta * (v - s.mean)
+}
+
+// replace replaces old, a value that was added before, with v, keeping the
+// count, e.g. to slide a window.
+func (s *runningStat) replace(old, v float64) {
+	previousMean := s.mean
+	s.mean += (v - old) / float64(s.count)
+	s.m2 += (v - old) * (v - s.mean + old - previousMean)
+}
+
+// standardScore returns how many standard deviations v is from the mean, or 0
+// while fewer than two values have been seen.
+func (s *runningStat) standardScore(v float64) float64 {
+	if s.count < 2 {
+		return 0
+	}
+	// Rounding errors may make m2 slightly negative once values are replaced.
+	return standardize(v-s.mean, math.Sqrt(math.Max(0, s.m2)/float64(s.count)))
+}
+
+// standardize divides residual by stddev, bounded to ±maxStandardScore.
+func standardize(residual, stddev float64) float64 {
+	if residual == 0 {
+		return 0
+	}
+	if stddev == 0 {
+		return math.Copysign(maxStandardScore, residual)
+	}
+	return math.Max(-maxStandardScore, math.Min(maxStandardScore, residual/stddev))
+}
+
+// seriesState is the history of a single metric series that the per-series
+// features are computed from.
+type seriesState struct {
+	key string
+
+	previousValue float64
+	previousTime  time.Time
+	seen          bool
+
+	hourOfDay  []runningStat // baseline per hour of the day
+	hourOfWeek []runningStat // baseline per hour of the week
+
+	window      []float64 // ring buffer of the most recent values
+	next        int
+	filled      bool
+	windowStats runningStat // mean and variance of the values in window
+
+	ewmaMean     float64
+	ewmaVariance float64
+	ewmaStarted  bool
+}
+
+// seriesTracker keeps the state of up to maxSeries metric series,
+// evicting the least recently updated series once full.
+type seriesTracker struct {
+	features     map[string]bool
+	location     *time.Location
+	zscoreWindow int
+	ewmaAlpha    float64
+	maxSeries    int
+
+	series map[string]*list.Element
+	lru    *list.List // front is the most recently updated series
+}
+
+// newSeriesTracker returns a tracker for the per-series features among
+// features, or nil if none is configured.
+func newSeriesTracker(features []string, cfg SeasonalityConfig) *seriesTracker {
+	enabled := make(map[string]bool)
+	for _, name := range features {
+		if isSeriesFeature(name) {
+			enabled[name] = true
+		}
+	}
+	if len(enabled) == 0 {
+		return nil
+	}
+	location, err := cfg.location()
+	if err != nil {
+		location = time.UTC // rejected by Validate
+	}
+	return &seriesTracker{
+		features:     enabled,
+		location:     location,
+		zscoreWindow: cfg.ZScoreWindow,
+		ewmaAlpha:    cfg.EWMAAlpha,
+		maxSeries:    cfg.MaxSeries,
+		series:       make(map[string]*list.Element),
+		lru:          list.New(),
+	}
+}
+
+// observe adds the per-series features of value, observed at timestamp on
+// the series identified by key, to features and then updates the series
+// history. The caller must hold the extractor's lock.
+func (st *seriesTracker) observe(key string, value float64, timestamp time.Time, features map[string][]float64) {
+	s := st.get(key)
+	if st.features["rate_of_change"] {
+		// Calculate rate of change from previous value
+		if s.seen {
+			if timeDiff := timestamp.Sub(s.previousTime).Seconds(); timeDiff > 0 {
+				features["rate_of_change"] = []float64{(value - s.previousValue) / timeDiff}
+			}
+		}
+		s.previousValue, s.previousTime, s.seen = value, timestamp, true
+	}
+
+	local := timestamp.In(st.location)
+	hour := local.Hour()
+	weekHour := int(local.Weekday())*24 + hour
+
+	if st.features[featureHourOfDay] {
+		features[featureHourOfDay] = []float64{s.hourOfDay[hour].standardScore(value)}
+		s.hourOfDay[hour].add(value)
+	}
+	if st.features[featureHourOfWeek] {
+		features[featureHourOfWeek] = []float64{s.hourOfWeek[weekHour].standardScore(value)}
+		s.hourOfWeek[weekHour].add(value)
+	}
+	if st.features[featureRollingZScore] {
+		features[featureRollingZScore] = []float64{s.windowStats.standardScore(value)}
+		s.push(value)
+	}
+	if st.features[featureEWMAResidual] {
+		score := 0.0
+		if s.ewmaStarted {
+			score = standardize(value-s.ewmaMean, math.Sqrt(s.ewmaVariance))
+			diff := value - s.ewmaMean
+			increment := st.ewmaAlpha * diff
+			s.ewmaMean += increment
+			s.ewmaVariance = (1 - st.ewmaAlpha) * (s.ewmaVariance + diff*increment)
+		} else {
+			s.ewmaMean = value
+			s.ewmaStarted = true
+		}
+		features[featureEWMAResidual] = []float64{score}
+	}
+}
+
+// get returns the state of the series identified by key, creating it, and
+// evicting the least recently updated series if needed, when it is new.
+func (st *seriesTracker) get(key string) *seriesState {
+	if elem, ok := st.series[key]; ok {
+		st.lru.MoveToFront(elem)
+		return elem.Value.(*seriesState)
+	}
+	if st.lru.Len() >= st.maxSeries {
+		oldest := st.lru.Back()
+		st.lru.Remove(oldest)
+		delete(st.series, oldest.Value.(*seriesState).key)
+	}
+
+	s := &seriesState{key: key}
+	if st.features[featureHourOfDay] {
+		s.hourOfDay = make([]runningStat, 24)
+	}
+	if st.features[featureHourOfWeek] {
+		s.hourOfWeek = make([]runningStat, 7*24)
+	}
+	if st.features[featureRollingZScore] {
+		s.window = make([]float64, st.zscoreWindow)
+	}
+	st.series[key] = st.lru.PushFront(s)
+	return s
+}
+
+// push adds value to the window, replacing the oldest value once the window
+// is full.
+func (s *seriesState) push(value float64) {
+	if s.filled {
+		s.windowStats.replace(s.window[s.next], value)
+	} else {
+		s.windowStats.add(value)
+	}
+	s.window[s.next] = value
+	s.next++
+	if s.next == len(s.window) {
+		s.next = 0
+		s.filled = true
+	}
+}
+
+// seriesCount returns the number of tracked series.
+func (st *seriesTracker) seriesCount() int {
+	return st.lru.Len()
+}
@@ -0,0 +1,178 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// seasonality_test.go - Tests for the per-series metric features
+package isolationforestprocessor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSeriesTracker(t *testing.T, features ...string) *seriesTracker {
+	t.Helper()
+	st := newSeriesTracker(features, createDefaultConfig().(*Config).Seasonality)
+	require.NotNil(t, st)
+	return st
+}
+
+// dailyTraffic returns a series with a peak of 1000 at noon and 100 otherwise.
+func dailyTraffic(hour int) float64 {
+	if hour == 12 {
+		return 1000
+	}
+	return 100 + float64(hour%3)
+}
+
+// TestHourOfDayBaseline verifies that a recurring daily peak is not an
+// outlier against the hour-of-day baseline, while the same value at another
+// hour is.
+func TestHourOfDayBaseline(t *testing.T) {
+	st := newTestSeriesTracker(t, featureHourOfDay, featureRollingZScore)
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	var features map[string][]float64
+	for day := range 7 {
+		for hour := range 24 {
+			features = make(map[string][]float64)
+			ts := start.Add(time.Duration(day*24+hour) * time.Hour)
+			st.observe("requests", dailyTraffic(hour)+float64(day%2), ts, features)
+			if day == 6 && hour == 12 {
+				assert.Less(t, features[featureHourOfDay][0], 2.0, "the daily peak matches its hour's baseline")
+				assert.Greater(t, features[featureRollingZScore][0], 3.0, "the daily peak stands out from recent values")
+			}
+		}
+	}
+
+	features = make(map[string][]float64)
+	st.observe("requests", 1000, start.Add(7*24*time.Hour+3*time.Hour), features)
+	assert.Equal(t, maxStandardScore, features[featureHourOfDay][0], "a peak at another hour is an outlier")
+}
+
+func TestHourOfWeekBaseline(t *testing.T) {
+	st := newTestSeriesTracker(t, featureHourOfWeek)
+	monday := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+
+	observe := func(ts time.Time, value float64) float64 {
+		features := make(map[string][]float64)
+		st.observe("logins", value, ts, features)
+		return features[featureHourOfWeek][0]
+	}
+	for week := range 4 {
+		observe(monday.Add(time.Duration(week)*7*24*time.Hour), 500+float64(week))
+		observe(monday.Add(time.Duration(week)*7*24*time.Hour+5*24*time.Hour), 10+float64(week)) // Saturday
+	}
+
+	assert.Less(t, observe(monday.Add(4*7*24*time.Hour), 503), 2.0, "a busy Monday is normal")
+	assert.Equal(t, maxStandardScore, observe(monday.Add(4*7*24*time.Hour+5*24*time.Hour), 500), "a busy Saturday is not")
+}
+
+func TestSeasonalityTimezone(t *testing.T) {
+	cfg := createDefaultConfig().(*Config).Seasonality
+	cfg.Timezone = "America/New_York"
+	st := newSeriesTracker([]string{featureHourOfDay}, cfg)
+	require.NotNil(t, st)
+
+	st.observe("requests", 1, time.Date(2024, 1, 1, 17, 0, 0, 0, time.UTC), map[string][]float64{})
+	s := st.get("requests")
+	assert.Equal(t, uint64(1), s.hourOfDay[12].count, "17:00 UTC is noon in New York")
+}
+
+func TestRollingZScore(t *testing.T) {
+	st := newTestSeriesTracker(t, featureRollingZScore)
+	ts := time.Now()
+
+	var score float64
+	for i, v := range []float64{10, 12, 10, 12, 12} {
+		features := make(map[string][]float64)
+		st.observe("latency", v, ts.Add(time.Duration(i)*time.Second), features)
+		score = features[featureRollingZScore][0]
+		if i < 2 {
+			assert.Zero(t, score, "no score before two values are seen")
+		}
+	}
+	assert.InDelta(t, 1.0, score, 1e-9, "12 is a standard deviation above the mean of 10, 12, 10, 12")
+}
+
+func TestRollingZScoreLargeValues(t *testing.T) {
+	cfg := createDefaultConfig().(*Config).Seasonality
+	cfg.ZScoreWindow = 4
+	st := newSeriesTracker([]string{featureRollingZScore}, cfg)
+	ts := time.Now()
+
+	// The window slides over values whose squares exceed the precision of a
+	// float64, which a sum of squares cannot keep up with.
+	var score float64
+	for i := range 1000 {
+		features := make(map[string][]float64)
+		st.observe("bytes", 1e12+float64(10+2*(i%2)), ts.Add(time.Duration(i)*time.Second), features)
+		score = features[featureRollingZScore][0]
+	}
+	assert.InDelta(t, 1.0, score, 1e-6, "12 is a standard deviation above the mean of 10, 12, 10, 12")
+}
+
+func TestRollingZScoreWindow(t *testing.T) {
+	cfg := createDefaultConfig().(*Config).Seasonality
+	cfg.ZScoreWindow = 3
+	st := newSeriesTracker([]string{featureRollingZScore}, cfg)
+
+	for _, v := range []float64{1000, 1, 2, 3} {
+		st.observe("latency", v, time.Now(), map[string][]float64{})
+	}
+	s := st.get("latency")
+	assert.InDelta(t, 2.0, s.windowStats.mean, 1e-9, "values older than the window are dropped")
+	assert.InDelta(t, 2.0, s.windowStats.m2, 1e-9)
+}
+
+func TestEWMAResidual(t *testing.T) {
+	st := newTestSeriesTracker(t, featureEWMAResidual)
+
+	var scores []float64
+	for _, v := range []float64{10, 10, 12, 11, 50} {
+		features := make(map[string][]float64)
+		st.observe("queue", v, time.Now(), features)
+		scores = append(scores, features[featureEWMAResidual][0])
+	}
+	assert.Equal(t, 0.0, scores[0], "the first value starts the average")
+	assert.Equal(t, 0.0, scores[1], "no residual while the value matches the average")
+	assert.Equal(t, maxStandardScore, scores[2], "a change of a constant series is bounded")
+	assert.Equal(t, maxStandardScore, scores[4])
+	assert.Greater(t, scores[3], 0.0)
+}
+
+func TestRateOfChangePerSeries(t *testing.T) {
+	st := newTestSeriesTracker(t, "rate_of_change")
+	ts := time.Now()
+
+	features := map[string][]float64{}
+	st.observe("a", 10, ts, features)
+	assert.NotContains(t, features, "rate_of_change")
+	st.observe("b", 1000, ts.Add(time.Second), features)
+	assert.NotContains(t, features, "rate_of_change", "series do not share history")
+
+	st.observe("a", 20, ts.Add(2*time.Second), features)
+	assert.Equal(t, []float64{5}, features["rate_of_change"])
+}
+
+func TestSeriesTrackerEviction(t *testing.T) {
+	cfg := createDefaultConfig().(*Config).Seasonality
+	cfg.MaxSeries = 2
+	st := newSeriesTracker([]string{featureEWMAResidual}, cfg)
+
+	st.observe("a", 1, time.Now(), map[string][]float64{})
+	st.observe("b", 1, time.Now(), map[string][]float64{})
+	st.observe("a", 1, time.Now(), map[string][]float64{})
+	st.observe("c", 1, time.Now(), map[string][]float64{})
+
+	assert.Equal(t, 2, st.seriesCount())
+	assert.Contains(t, st.series, "a")
+	assert.Contains(t, st.series, "c")
+	assert.NotContains(t, st.series, "b", "the least recently updated series is evicted")
+}
+
+func TestSeriesTrackerDisabled(t *testing.T) {
+	assert.Nil(t, newSeriesTracker([]string{"value"}, createDefaultConfig().(*Config).Seasonality))
+}
@@ -65,9 +65,20 @@ type LookupConfig struct {
 	// Key is an OTTL value expression for extracting the lookup key.
 	// Examples: attributes["user.id"], Trim(attributes["raw.id"]),
 	//           resource.attributes["service.name"]
-	// Required.
+	// Exactly one of Key and Keys is required.
 	Key string `mapstructure:"key"`
 
+	// Keys is a list of OTTL value expressions forming a composite key, for
+	// joins on several attributes. Their values are joined with KeySeparator,
+	// so a source entry for the (cluster, namespace) pair "prod", "payments"
+	// has the key "prod|payments". Records for which any of the values is
+	// missing or empty are not looked up.
+	Keys []string `mapstructure:"keys"`
+
+	// KeySeparator joins the values of Keys.
+	// Default: "|"
+	KeySeparator string `mapstructure:"key_separator"`
+
 	// Context is the default context for destination attributes.
 	// Valid values: "record", "resource".
 	// Default: "record"
@@ -108,8 +119,19 @@ func (cfg *Config) Validate() error {
 	}
 
 	for i, lookup := range cfg.Lookups {
-		if lookup.Key == "" {
-			return fmt.Errorf("lookups[%d]: key is required", i)
+		if lookup.Key == "" && len(lookup.Keys) == 0 {
+			return fmt.Errorf("lookups[%d]: key is required, or keys for a composite key", i)
+		}
+		if lookup.Key != "" && len(lookup.Keys) > 0 {
+			return fmt.Errorf("lookups[%d]: key and keys cannot both be set", i)
+		}
+		for j, key := range lookup.Keys {
+			if key == "" {
+				return fmt.Errorf("lookups[%d].keys[%d]: must not be empty", i, j)
+			}
+		}
+		if lookup.KeySeparator != "" && len(lookup.Keys) == 0 {
+			return fmt.Errorf("lookups[%d]: key_separator requires keys", i)
 		}
 		if len(lookup.Attributes) == 0 {
 			return fmt.Errorf("lookups[%d]: at least one attribute mapping is required", i)
@@ -124,6 +146,22 @@ func (cfg *Config) Validate() error {
 	return nil
 }
 
+// GetKeys returns the key expressions of this lookup.
+func (l *LookupConfig) GetKeys() []string {
+	if l.Key != "" {
+		return []string{l.Key}
+	}
+	return l.Keys
+}
+
+// GetKeySeparator returns the separator of composite keys, defaulting to "|".
+func (l *LookupConfig) GetKeySeparator() string {
+	if l.KeySeparator == "" {
+		return "|"
+	}
+	return l.KeySeparator
+}
+
 // GetContext returns the context for this lookup, defaulting to ContextRecord.
 func (l *LookupConfig) GetContext() ContextID {
 	if l.Context == "" {
@@ -21,7 +21,9 @@ import (
 	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/lookupprocessor/internal/metadata"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/lookupprocessor/internal/source/csv"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/lookupprocessor/internal/source/dns"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/lookupprocessor/internal/source/http"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/lookupprocessor/internal/source/noop"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/lookupprocessor/internal/source/sql"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/lookupprocessor/internal/source/yaml"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/lookupprocessor/lookupsource"
 )
@@ -64,6 +66,8 @@ func defaultSources() map[string]lookupsource.SourceFactory {
 		"yaml": yaml.NewFactory(),
 		"csv":  csv.NewFactory(),
 		"dns":  dns.NewFactory(),
+		"sql":  sql.NewFactory(),
+		"http": http.NewFactory(),
 	}
 }
 
@@ -231,24 +235,32 @@ func (f *lookupProcessorFactory) createMetricsProcessor(
 	)
 }
 
-// parsedLookup holds a lookup config with its pre-parsed OTTL key expression.
+// parsedLookup holds a lookup config with its pre-parsed OTTL key expressions.
 type parsedLookup[T any] struct {
-	keyExpr    *ottl.ValueExpression[T]
-	context    ContextID
-	attributes []AttributeMapping
+	// keyExprs holds one expression, or the parts of a composite key.
+	keyExprs     []*ottl.ValueExpression[T]
+	keySeparator string
+	context      ContextID
+	attributes   []AttributeMapping
 }
 
 func parseLookups[T any](parser ottl.Parser[T], configs []LookupConfig) ([]parsedLookup[T], error) {
 	lookups := make([]parsedLookup[T], len(configs))
 	for i, cfg := range configs {
-		keyExpr, err := parser.ParseValueExpression(cfg.Key)
-		if err != nil {
-			return nil, fmt.Errorf("lookups[%d]: failed to parse key expression %q: %w", i, cfg.Key, err)
+		keys := cfg.GetKeys()
+		keyExprs := make([]*ottl.ValueExpression[T], len(keys))
+		for j, key := range keys {
+			keyExpr, err := parser.ParseValueExpression(key)
+			if err != nil {
+				return nil, fmt.Errorf("lookups[%d]: failed to parse key expression %q: %w", i, key, err)
+			}
+			keyExprs[j] = keyExpr
 		}
 		lookups[i] = parsedLookup[T]{
-			keyExpr:    keyExpr,
-			context:    cfg.GetContext(),
-			attributes: cfg.Attributes,
+			keyExprs:     keyExprs,
+			keySeparator: cfg.GetKeySeparator(),
+			context:      cfg.GetContext(),
+			attributes:   cfg.Attributes,
 		}
 	}
 	return lookups, nil
@@ -194,6 +194,48 @@ func TestConfigValidation(t *testing.T) {
 			},
 			wantErr: "key is required",
 		},
+		{
+			name: "key and keys",
+			cfg: &Config{
+				Lookups: []LookupConfig{{
+					Key:        `log.attributes["test"]`,
+					Keys:       []string{`log.attributes["a"]`, `log.attributes["b"]`},
+					Attributes: []AttributeMapping{{Destination: "test"}},
+				}},
+			},
+			wantErr: "key and keys cannot both be set",
+		},
+		{
+			name: "empty composite key part",
+			cfg: &Config{
+				Lookups: []LookupConfig{{
+					Keys:       []string{`log.attributes["a"]`, ""},
+					Attributes: []AttributeMapping{{Destination: "test"}},
+				}},
+			},
+			wantErr: "keys[1]: must not be empty",
+		},
+		{
+			name: "key_separator without keys",
+			cfg: &Config{
+				Lookups: []LookupConfig{{
+					Key:          `log.attributes["test"]`,
+					KeySeparator: "/",
+					Attributes:   []AttributeMapping{{Destination: "test"}},
+				}},
+			},
+			wantErr: "key_separator requires keys",
+		},
+		{
+			name: "valid composite key",
+			cfg: &Config{
+				Lookups: []LookupConfig{{
+					Keys:         []string{`resource.attributes["k8s.cluster.name"]`, `resource.attributes["k8s.namespace.name"]`},
+					KeySeparator: "/",
+					Attributes:   []AttributeMapping{{Destination: "team"}},
+				}},
+			},
+		},
 		{
 			name: "missing attributes",
 			cfg: &Config{
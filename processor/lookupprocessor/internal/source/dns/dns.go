@@ -49,6 +49,11 @@ type Config struct {
 	// Default: false
 	MultipleResults bool `mapstructure:"multiple_results"`
 
+	// MaxConcurrentLookups is the maximum number of DNS queries run
+	// concurrently to resolve the distinct keys of a batch of telemetry.
+	// Default: 16
+	MaxConcurrentLookups int `mapstructure:"max_concurrent_lookups"`
+
 	// Cache configures caching for DNS lookups.
 	// Enabled by default.
 	// Disabling is not recommended due to potential performance impact.
@@ -67,6 +72,10 @@ func (c *Config) Validate() error {
 		return errors.New("timeout must be greater than 0")
 	}
 
+	if c.MaxConcurrentLookups < 0 {
+		return errors.New("max_concurrent_lookups must not be negative")
+	}
+
 	if err := c.Cache.Validate(); err != nil {
 		return err
 	}
@@ -84,8 +93,9 @@ func NewFactory() lookupsource.SourceFactory {
 
 func createDefaultConfig() lookupsource.SourceConfig {
 	return &Config{
-		RecordType: RecordTypePTR,
-		Timeout:    1 * time.Second,
+		RecordType:           RecordTypePTR,
+		Timeout:              1 * time.Second,
+		MaxConcurrentLookups: 16,
 		Cache: lookupsource.CacheConfig{
 			Enabled:     true,
 			Size:        10000,
@@ -129,15 +139,19 @@ func createSource(
 		multipleResults: dnsCfg.MultipleResults,
 	}
 
-	// Create the lookup function, optionally wrapped with cache
+	// Create the lookup functions, optionally wrapped with a shared cache.
+	// Batches resolve their distinct keys concurrently.
 	lookupFn := s.lookup
+	batchLookupFn := lookupsource.ParallelBatchLookup(s.lookup, dnsCfg.MaxConcurrentLookups)
 	if dnsCfg.Cache.Enabled {
 		cache := lookupsource.NewCache(dnsCfg.Cache)
 		lookupFn = lookupsource.WrapWithCache(cache, lookupFn)
+		batchLookupFn = lookupsource.WrapBatchWithCache(cache, batchLookupFn)
 	}
 
-	return lookupsource.NewSource(
+	return lookupsource.NewBatchSource(
 		lookupFn,
+		batchLookupFn,
 		func() string { return sourceType },
 		nil, // no start needed
 		nil, // no shutdown needed
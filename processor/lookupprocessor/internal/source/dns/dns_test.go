@@ -87,6 +87,14 @@ func TestConfigValidate(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "negative max_concurrent_lookups",
+			config: &Config{
+				Timeout:              1 * time.Second,
+				MaxConcurrentLookups: -1,
+			},
+			wantErr: true,
+		},
 		{
 			name: "invalid cache size when enabled",
 			config: &Config{
@@ -119,6 +127,9 @@ func TestCreateSource(t *testing.T) {
 	require.NoError(t, err)
 	require.NotNil(t, source)
 	assert.Equal(t, "dns", source.Type())
+
+	_, ok := source.(lookupsource.BatchSource)
+	assert.True(t, ok, "dns source resolves batches concurrently")
 }
 
 func TestCreateSourceWithCustomConfig(t *testing.T) {
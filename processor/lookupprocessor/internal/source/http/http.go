@@ -0,0 +1,320 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package http provides a lookup source that resolves keys with HTTP GET
+// requests to a JSON API.
+package http // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/lookupprocessor/internal/source/http"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tidwall/gjson"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/confighttp"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/lookupprocessor/lookupsource"
+)
+
+const (
+	sourceType = "http"
+
+	// keyPlaceholder is replaced by the escaped key in the endpoint.
+	keyPlaceholder = "{key}"
+
+	// maxResponseSize bounds the response body read for a single key.
+	maxResponseSize = 1 << 20
+)
+
+type Config struct {
+	// ClientConfig configures the HTTP client. Its endpoint is a URL template
+	// in which "{key}" is replaced by the looked up key, e.g.
+	// "https://cmdb.example.com/hosts/{key}".
+	confighttp.ClientConfig `mapstructure:",squash"`
+
+	// ValuePath is the JSONPath of the value to return, e.g. "$.owner.team".
+	// Exactly one of ValuePath and Fields must be set.
+	ValuePath string `mapstructure:"value_path"`
+
+	// Fields maps result names to JSONPaths. Lookups return a map holding the
+	// fields found in the response, for use with `attributes[].source`.
+	Fields map[string]string `mapstructure:"fields"`
+
+	// MaxConcurrentRequests is the maximum number of requests sent
+	// concurrently to resolve the distinct keys of a batch of telemetry.
+	// Default: 8
+	MaxConcurrentRequests int `mapstructure:"max_concurrent_requests"`
+
+	// Cache configures caching of lookups.
+	// Enabled by default.
+	Cache lookupsource.CacheConfig `mapstructure:"cache"`
+}
+
+func (c *Config) Validate() error {
+	if c.Endpoint == "" {
+		return errors.New("endpoint must be specified")
+	}
+	if !strings.Contains(c.Endpoint, keyPlaceholder) {
+		return fmt.Errorf("endpoint must contain the %s placeholder", keyPlaceholder)
+	}
+	if _, err := url.Parse(strings.ReplaceAll(c.Endpoint, keyPlaceholder, "key")); err != nil {
+		return fmt.Errorf("invalid endpoint: %w", err)
+	}
+	if (c.ValuePath == "") == (len(c.Fields) == 0) {
+		return errors.New("exactly one of value_path and fields must be specified")
+	}
+	if _, err := toGJSONPath(c.ValuePath); err != nil {
+		return fmt.Errorf("value_path: %w", err)
+	}
+	for name, path := range c.Fields {
+		if path == "" {
+			return fmt.Errorf("fields[%q] must not be empty", name)
+		}
+		if _, err := toGJSONPath(path); err != nil {
+			return fmt.Errorf("fields[%q]: %w", name, err)
+		}
+	}
+	if c.Timeout <= 0 {
+		return errors.New("timeout must be greater than 0")
+	}
+	if c.MaxConcurrentRequests < 0 {
+		return errors.New("max_concurrent_requests must not be negative")
+	}
+	return c.Cache.Validate()
+}
+
+func NewFactory() lookupsource.SourceFactory {
+	return lookupsource.NewSourceFactory(
+		sourceType,
+		createDefaultConfig,
+		createSource,
+	)
+}
+
+func createDefaultConfig() lookupsource.SourceConfig {
+	clientConfig := confighttp.NewDefaultClientConfig()
+	clientConfig.Timeout = 5 * time.Second
+	return &Config{
+		ClientConfig:          clientConfig,
+		MaxConcurrentRequests: 8,
+		Cache: lookupsource.CacheConfig{
+			Enabled:     true,
+			Size:        10000,
+			TTL:         5 * time.Minute,
+			NegativeTTL: 1 * time.Minute,
+		},
+	}
+}
+
+func createSource(
+	_ context.Context,
+	settings lookupsource.CreateSettings,
+	cfg lookupsource.SourceConfig,
+) (lookupsource.Source, error) {
+	s := &httpSource{
+		cfg:      cfg.(*Config),
+		settings: settings.TelemetrySettings,
+		fields:   make(map[string]string, len(cfg.(*Config).Fields)),
+	}
+	var err error
+	if s.valuePath, err = toGJSONPath(s.cfg.ValuePath); err != nil {
+		return nil, err
+	}
+	for name, path := range s.cfg.Fields {
+		if s.fields[name], err = toGJSONPath(path); err != nil {
+			return nil, err
+		}
+	}
+
+	lookupFn := s.lookup
+	batchLookupFn := lookupsource.ParallelBatchLookup(s.lookup, s.cfg.MaxConcurrentRequests)
+	if s.cfg.Cache.Enabled {
+		cache := lookupsource.NewCache(s.cfg.Cache)
+		lookupFn = lookupsource.WrapWithCache(cache, lookupFn)
+		batchLookupFn = lookupsource.WrapBatchWithCache(cache, batchLookupFn)
+	}
+
+	return lookupsource.NewBatchSource(
+		lookupFn,
+		batchLookupFn,
+		func() string { return sourceType },
+		s.start,
+		s.shutdown,
+	), nil
+}
+
+type httpSource struct {
+	cfg      *Config
+	settings component.TelemetrySettings
+	// valuePath and fields hold the paths of the configuration, as gjson
+	// paths.
+	valuePath string
+	fields    map[string]string
+
+	mu     sync.RWMutex
+	client *http.Client
+}
+
+func (s *httpSource) start(ctx context.Context, host component.Host) error {
+	client, err := s.cfg.ToClient(ctx, host.GetExtensions(), s.settings)
+	if err != nil {
+		return fmt.Errorf("failed to create HTTP client: %w", err)
+	}
+	s.mu.Lock()
+	s.client = client
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *httpSource) shutdown(context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.client != nil {
+		s.client.CloseIdleConnections()
+		s.client = nil
+	}
+	return nil
+}
+
+// keyURL returns the endpoint with the placeholder replaced by key. The key
+// is escaped so that it is valid both in the path and in the query.
+func (s *httpSource) keyURL(key string) string {
+	escaped := strings.ReplaceAll(url.QueryEscape(key), "+", "%20")
+	return strings.ReplaceAll(s.cfg.Endpoint, keyPlaceholder, escaped)
+}
+
+func (s *httpSource) lookup(ctx context.Context, key string) (any, bool, error) {
+	s.mu.RLock()
+	client := s.client
+	s.mu.RUnlock()
+	if client == nil {
+		return nil, false, errors.New("http source is not started")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.keyURL(key), http.NoBody)
+	if err != nil {
+		return nil, false, err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil, false, nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil, false, fmt.Errorf("lookup request failed with status %s", resp.Status)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize+1))
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read response: %w", err)
+	}
+	if len(body) > maxResponseSize {
+		return nil, false, fmt.Errorf("response exceeds %d bytes", maxResponseSize)
+	}
+	if !gjson.ValidBytes(body) {
+		return nil, false, errors.New("response is not valid JSON")
+	}
+	return extract(body, s.valuePath, s.fields)
+}
+
+// extract returns the value at the gjson path valuePath, or a map of the
+// fields found in body. A lookup is not found when no path matches.
+func extract(body []byte, valuePath string, fields map[string]string) (any, bool, error) {
+	if valuePath != "" {
+		result := gjson.GetBytes(body, valuePath)
+		if !result.Exists() || result.Type == gjson.Null {
+			return nil, false, nil
+		}
+		return result.Value(), true, nil
+	}
+
+	values := make(map[string]any, len(fields))
+	for name, path := range fields {
+		result := gjson.GetBytes(body, path)
+		if !result.Exists() || result.Type == gjson.Null {
+			continue
+		}
+		values[name] = result.Value()
+	}
+	if len(values) == 0 {
+		return nil, false, nil
+	}
+	return values, true, nil
+}
+
+// toGJSONPath converts a JSONPath to a gjson path, e.g. "$.items[0].name" to
+// "items.0.name". Only the "$" root, child names in dot or bracket notation
+// and array indexes are supported; gjson metacharacters in names are escaped
+// so that they match literally. Paths without the "$" root are assumed to
+// already be gjson paths.
+func toGJSONPath(path string) (string, error) {
+	if !strings.HasPrefix(path, "$") {
+		return path, nil
+	}
+	if path == "$" {
+		return "@this", nil
+	}
+	var components []string
+	for rest := path[1:]; rest != ""; {
+		var component string
+		var ok bool
+		switch rest[0] {
+		case '.':
+			component, rest, ok = dotChild(rest)
+		case '[':
+			component, rest, ok = bracketChild(rest)
+		}
+		if !ok {
+			return "", fmt.Errorf("unsupported JSONPath %q: only child names, e.g. $.owner or $['owner'], and array indexes, e.g. $.items[0], are supported", path)
+		}
+		components = append(components, component)
+	}
+	return strings.Join(components, "."), nil
+}
+
+// dotChild parses the child name at the start of path, e.g. ".owner", and
+// returns it as an escaped gjson path component along with the rest of path.
+func dotChild(path string) (component, rest string, ok bool) {
+	end := strings.IndexAny(path[1:], ".[") + 1
+	if end == 0 {
+		end = len(path)
+	}
+	name := path[1:end]
+	if name == "" || name == "*" {
+		return "", "", false
+	}
+	return gjson.Escape(name), path[end:], true
+}
+
+// bracketChild parses the quoted child name or array index at the start of
+// path, e.g. "['owner']" or "[0]", and returns it as a gjson path component
+// along with the rest of path.
+func bracketChild(path string) (component, rest string, ok bool) {
+	if len(path) > 1 && (path[1] == '\'' || path[1] == '"') {
+		// The closing quote is looked up first, as the name may contain ']'.
+		end := strings.IndexByte(path[2:], path[1]) + 2
+		if end <= 2 || !strings.HasPrefix(path[end+1:], "]") {
+			return "", "", false
+		}
+		return gjson.Escape(path[2:end]), path[end+2:], true
+	}
+	end := strings.IndexByte(path, ']')
+	if end <= 1 || strings.Trim(path[1:end], "0123456789") != "" {
+		return "", "", false
+	}
+	return path[1:end], path[end+1:], true
+}
@@ -0,0 +1,277 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/lookupprocessor/lookupsource"
+)
+
+func TestNewFactory(t *testing.T) {
+	factory := NewFactory()
+	require.NotNil(t, factory)
+	assert.Equal(t, "http", factory.Type())
+}
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(*Config)
+		wantErr string
+	}{
+		{
+			name:   "valid value_path",
+			modify: func(*Config) {},
+		},
+		{
+			name: "valid fields",
+			modify: func(cfg *Config) {
+				cfg.ValuePath = ""
+				cfg.Fields = map[string]string{"team": "$.team"}
+			},
+		},
+		{
+			name:    "missing endpoint",
+			modify:  func(cfg *Config) { cfg.Endpoint = "" },
+			wantErr: "endpoint must be specified",
+		},
+		{
+			name:    "endpoint without placeholder",
+			modify:  func(cfg *Config) { cfg.Endpoint = "http://localhost/hosts" },
+			wantErr: "placeholder",
+		},
+		{
+			name:    "neither value_path nor fields",
+			modify:  func(cfg *Config) { cfg.ValuePath = "" },
+			wantErr: "exactly one of value_path and fields",
+		},
+		{
+			name:    "both value_path and fields",
+			modify:  func(cfg *Config) { cfg.Fields = map[string]string{"team": "$.team"} },
+			wantErr: "exactly one of value_path and fields",
+		},
+		{
+			name: "empty field path",
+			modify: func(cfg *Config) {
+				cfg.ValuePath = ""
+				cfg.Fields = map[string]string{"team": ""}
+			},
+			wantErr: `fields["team"] must not be empty`,
+		},
+		{
+			name:    "unsupported value_path",
+			modify:  func(cfg *Config) { cfg.ValuePath = "$..owner" },
+			wantErr: `value_path: unsupported JSONPath "$..owner"`,
+		},
+		{
+			name: "unsupported field path",
+			modify: func(cfg *Config) {
+				cfg.ValuePath = ""
+				cfg.Fields = map[string]string{"team": "$.teams[*]"}
+			},
+			wantErr: `fields["team"]: unsupported JSONPath "$.teams[*]"`,
+		},
+		{
+			name:    "zero timeout",
+			modify:  func(cfg *Config) { cfg.Timeout = 0 },
+			wantErr: "timeout must be greater than 0",
+		},
+		{
+			name:    "negative max_concurrent_requests",
+			modify:  func(cfg *Config) { cfg.MaxConcurrentRequests = -1 },
+			wantErr: "max_concurrent_requests must not be negative",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			cfg.Endpoint = "http://localhost/hosts/{key}"
+			cfg.ValuePath = "$.owner"
+			tt.modify(cfg)
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func newTestServer(t *testing.T, requests *atomic.Int64) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /hosts/{host}", func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		switch r.PathValue("host") {
+		case "web 1":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"owner": {"team": "payments", "oncall": null}, "tags": ["prod", "eu"], "replicas": 3}`))
+		case "broken":
+			http.Error(w, "boom", http.StatusInternalServerError)
+		case "garbage":
+			_, _ = w.Write([]byte("not json"))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestSource(t *testing.T, cfg *Config) lookupsource.Source {
+	t.Helper()
+	settings := lookupsource.CreateSettings{TelemetrySettings: componenttest.NewNopTelemetrySettings()}
+	source, err := NewFactory().CreateSource(t.Context(), settings, cfg)
+	require.NoError(t, err)
+	require.NoError(t, source.Start(t.Context(), componenttest.NewNopHost()))
+	t.Cleanup(func() { assert.NoError(t, source.Shutdown(t.Context())) })
+	return source
+}
+
+func TestLookupValuePath(t *testing.T) {
+	var requests atomic.Int64
+	server := newTestServer(t, &requests)
+	cfg := createDefaultConfig().(*Config)
+	cfg.Endpoint = server.URL + "/hosts/{key}"
+	cfg.ValuePath = "$.owner.team"
+	source := newTestSource(t, cfg)
+
+	value, found, err := source.Lookup(t.Context(), "web 1")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "payments", value)
+
+	_, _, err = source.Lookup(t.Context(), "web 1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), requests.Load(), "second lookup is served from the cache")
+
+	_, found, err = source.Lookup(t.Context(), "unknown")
+	require.NoError(t, err)
+	assert.False(t, found, "404 is not found")
+
+	_, _, err = source.Lookup(t.Context(), "broken")
+	assert.ErrorContains(t, err, "500")
+
+	_, _, err = source.Lookup(t.Context(), "garbage")
+	assert.ErrorContains(t, err, "not valid JSON")
+}
+
+func TestLookupFields(t *testing.T) {
+	var requests atomic.Int64
+	server := newTestServer(t, &requests)
+	cfg := createDefaultConfig().(*Config)
+	cfg.Endpoint = server.URL + "/hosts/{key}"
+	cfg.Fields = map[string]string{
+		"team":     "$.owner.team",
+		"oncall":   "$.owner.oncall",
+		"region":   "$.tags[1]",
+		"replicas": "replicas",
+	}
+	cfg.Cache.Enabled = false
+	source := newTestSource(t, cfg)
+
+	value, found, err := source.Lookup(t.Context(), "web 1")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, map[string]any{
+		"team":     "payments",
+		"region":   "eu",
+		"replicas": float64(3),
+	}, value, "null and missing fields are omitted")
+}
+
+func TestLookupBeforeStart(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Endpoint = "http://localhost/hosts/{key}"
+	cfg.ValuePath = "$.owner"
+	cfg.Cache.Enabled = false
+	source, err := NewFactory().CreateSource(t.Context(), lookupsource.CreateSettings{}, cfg)
+	require.NoError(t, err)
+	_, _, err = source.Lookup(t.Context(), "web")
+	assert.ErrorContains(t, err, "not started")
+}
+
+func TestToGJSONPath(t *testing.T) {
+	tests := map[string]string{
+		"$":                    "@this",
+		"$.owner.team":         "owner.team",
+		"$.items[0].name":      "items.0.name",
+		"$['owner']['team']":   "owner.team",
+		`$["owner"].team`:      "owner.team",
+		"$['a.b']['c|d']":      `a\.b.c\|d`,
+		"$.k#*?@":              `k\#\*\?\@`,
+		"$['x]y']":             `x\]y`,
+		"owner.team":           "owner.team",
+		"items.#(name==web).n": "items.#(name==web).n",
+	}
+	for path, want := range tests {
+		got, err := toGJSONPath(path)
+		require.NoError(t, err, path)
+		assert.Equal(t, want, got, path)
+	}
+
+	for _, path := range []string{
+		"$..name",
+		"$.items[*]",
+		"$.items.*",
+		"$.items[?(@.name)]",
+		"$.items[0:2]",
+		"$.items[-1]",
+		"$.items[0,1]",
+		"$['owner'",
+		"$['']",
+		"$.",
+		"$owner",
+	} {
+		_, err := toGJSONPath(path)
+		assert.ErrorContains(t, err, "unsupported JSONPath", path)
+	}
+}
+
+func TestLookupEscapedPath(t *testing.T) {
+	value, found, err := extract([]byte(`{"a.b":{"c|d":"v"},"a":{"b":"wrong"}}`), mustGJSONPath(t, "$['a.b']['c|d']"), nil)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "v", value)
+}
+
+func mustGJSONPath(t *testing.T, path string) string {
+	t.Helper()
+	gjsonPath, err := toGJSONPath(path)
+	require.NoError(t, err)
+	return gjsonPath
+}
+
+func TestBatchLookup(t *testing.T) {
+	var requests atomic.Int64
+	server := newTestServer(t, &requests)
+	cfg := createDefaultConfig().(*Config)
+	cfg.Endpoint = server.URL + "/hosts/{key}"
+	cfg.ValuePath = "$.owner.team"
+	source := newTestSource(t, cfg)
+
+	batchSource, ok := source.(lookupsource.BatchSource)
+	require.True(t, ok)
+	results, err := batchSource.BatchLookup(t.Context(), []string{"web 1", "unknown", "broken"})
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	assert.Equal(t, lookupsource.LookupResult{Value: "payments", Found: true}, results[0])
+	assert.Equal(t, lookupsource.LookupResult{}, results[1])
+	assert.ErrorContains(t, results[2].Err, "500")
+
+	_, err = batchSource.BatchLookup(t.Context(), []string{"web 1", "unknown"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), requests.Load(), "found and not found keys are served from the cache")
+}
@@ -0,0 +1,475 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package sql provides a lookup source backed by a SQL database. It either
+// queries the database for each key, or periodically loads a whole table.
+package sql // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/lookupprocessor/internal/source/sql"
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	// Register the drivers supported by this source.
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/configopaque"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/sqlquery"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/lookupprocessor/internal/metadata"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/lookupprocessor/lookupsource"
+)
+
+const (
+	sourceType = "sql"
+	// keysPlaceholder is replaced with one query parameter per key in the
+	// batch query.
+	keysPlaceholder = "{keys}"
+	// maxBatchKeys is the maximum number of keys resolved by a single batch
+	// query, to stay below the parameter limits of the databases.
+	maxBatchKeys = 1000
+)
+
+// Mode selects how keys are resolved.
+type Mode string
+
+const (
+	// ModePerKey runs the query for each key, with the key as its only
+	// parameter. Results are cached.
+	ModePerKey Mode = "per_key"
+	// ModeFull runs the query once at start, and on every reload, and serves
+	// lookups from the loaded table.
+	ModeFull Mode = "full"
+)
+
+// Config is the configuration for the SQL lookup source. The connection
+// settings are the ones of the sqlquery receiver.
+type Config struct {
+	// Driver is the database driver: "postgres" or "mysql". Required.
+	Driver string `mapstructure:"driver"`
+	// DataSource is the driver-specific connection string. When set, the
+	// individual connection settings below must not be set.
+	DataSource       string              `mapstructure:"datasource"`
+	Host             string              `mapstructure:"host"`
+	Port             int                 `mapstructure:"port"`
+	Database         string              `mapstructure:"database"`
+	Username         string              `mapstructure:"username"`
+	Password         configopaque.String `mapstructure:"password"`
+	AdditionalParams map[string]any      `mapstructure:"additional_params"`
+
+	// MaxOpenConn is the maximum number of open connections. 0 means no limit.
+	// Default: 2
+	MaxOpenConn int `mapstructure:"max_open_conn"`
+
+	// Mode is "per_key" or "full". Default: "per_key"
+	Mode Mode `mapstructure:"mode"`
+
+	// Query is the SQL query. In per_key mode it takes the key as its single
+	// parameter, using the driver's placeholder syntax ($1 or ?). Required.
+	Query string `mapstructure:"query"`
+
+	// BatchQuery is the SQL query resolving all the distinct keys of a batch
+	// of telemetry at once in per_key mode, e.g.
+	// "SELECT id, name FROM users WHERE id IN ({keys})". {keys} is replaced
+	// with one parameter per key, and KeyColumn maps the rows back to the
+	// keys. When empty, Query runs once per key.
+	BatchQuery string `mapstructure:"batch_query"`
+
+	// KeyColumn is the column holding the key. Required in full mode and
+	// with BatchQuery.
+	KeyColumn string `mapstructure:"key_column"`
+
+	// ValueColumn selects a single column, making lookups return that column
+	// as a scalar. When empty, lookups return the whole row as a map.
+	ValueColumn string `mapstructure:"value_column"`
+
+	// ReloadInterval, when > 0, reloads the table on this interval in full
+	// mode. 0 loads the table once at start.
+	ReloadInterval time.Duration `mapstructure:"reload_interval"`
+
+	// Timeout is the maximum time a query may take.
+	// Default: 5 seconds
+	Timeout time.Duration `mapstructure:"timeout"`
+
+	// Cache configures caching of per_key lookups.
+	Cache lookupsource.CacheConfig `mapstructure:"cache"`
+}
+
+// Validate implements lookupsource.SourceConfig.
+func (c *Config) Validate() error {
+	if c.Driver == "" {
+		return errors.New("driver must be specified")
+	}
+	if !slices.Contains(sql.Drivers(), c.Driver) {
+		return fmt.Errorf("driver %q is not supported by the sql source, must be one of: mysql, postgres", c.Driver)
+	}
+	if err := c.validateConnection(); err != nil {
+		return err
+	}
+	if c.Query == "" {
+		return errors.New("query must be specified")
+	}
+	if c.MaxOpenConn < 0 {
+		return errors.New("max_open_conn must not be negative")
+	}
+	if c.Timeout <= 0 {
+		return errors.New("timeout must be greater than 0")
+	}
+
+	switch c.Mode {
+	case ModePerKey:
+		if c.ReloadInterval != 0 {
+			return errors.New("reload_interval requires mode: full")
+		}
+		if c.BatchQuery != "" {
+			if !strings.Contains(c.BatchQuery, keysPlaceholder) {
+				return errors.New("batch_query must contain " + keysPlaceholder)
+			}
+			if c.KeyColumn == "" {
+				return errors.New("key_column is required with batch_query")
+			}
+		}
+		if err := c.Cache.Validate(); err != nil {
+			return err
+		}
+	case ModeFull:
+		if c.BatchQuery != "" {
+			return errors.New("batch_query requires mode: per_key")
+		}
+		if c.KeyColumn == "" {
+			return errors.New("key_column is required in full mode")
+		}
+		if c.ReloadInterval < 0 {
+			return errors.New("reload_interval must not be negative")
+		}
+	default:
+		return fmt.Errorf("invalid mode %q, must be one of: per_key, full", c.Mode)
+	}
+	return nil
+}
+
+// validateConnection checks that the connection is configured either with
+// datasource or with the individual connection settings.
+func (c *Config) validateConnection() error {
+	if c.DataSource == "" {
+		switch {
+		case c.Host == "":
+			return errors.New("host or datasource must be specified")
+		case c.Port == 0:
+			return errors.New("port or datasource must be specified")
+		case c.Database == "":
+			return errors.New("database or datasource must be specified")
+		}
+		return nil
+	}
+	for _, setting := range []struct {
+		name string
+		set  bool
+	}{
+		{"host", c.Host != ""},
+		{"port", c.Port != 0},
+		{"database", c.Database != ""},
+		{"username", c.Username != ""},
+		{"password", c.Password != ""},
+		{"additional_params", len(c.AdditionalParams) > 0},
+	} {
+		if setting.set {
+			return fmt.Errorf("%s cannot be set when datasource is specified", setting.name)
+		}
+	}
+	return nil
+}
+
+// connectionConfig returns the sqlquery configuration for the connection
+// settings, from which the data source string is built.
+func (c *Config) connectionConfig() sqlquery.Config {
+	return sqlquery.Config{
+		Driver:           c.Driver,
+		DataSource:       c.DataSource,
+		Host:             c.Host,
+		Port:             c.Port,
+		Database:         c.Database,
+		Username:         c.Username,
+		Password:         c.Password,
+		AdditionalParams: c.AdditionalParams,
+	}
+}
+
+// NewFactory creates a factory for the SQL source.
+func NewFactory() lookupsource.SourceFactory {
+	return lookupsource.NewSourceFactory(
+		sourceType,
+		createDefaultConfig,
+		createSource,
+	)
+}
+
+func createDefaultConfig() lookupsource.SourceConfig {
+	return &Config{
+		MaxOpenConn: 2,
+		Mode:        ModePerKey,
+		Timeout:     5 * time.Second,
+		Cache: lookupsource.CacheConfig{
+			Enabled:     true,
+			Size:        10000,
+			TTL:         5 * time.Minute,
+			NegativeTTL: 1 * time.Minute,
+		},
+	}
+}
+
+func createSource(
+	_ context.Context,
+	settings lookupsource.CreateSettings,
+	cfg lookupsource.SourceConfig,
+) (lookupsource.Source, error) {
+	return newSource(settings, cfg.(*Config), sql.Open)
+}
+
+func newSource(settings lookupsource.CreateSettings, cfg *Config, opener sqlquery.SQLOpenerFunc) (lookupsource.Source, error) {
+	dataSource := cfg.DataSource
+	if dataSource == "" {
+		var err error
+		if dataSource, err = sqlquery.BuildDataSourceString(cfg.connectionConfig()); err != nil {
+			return nil, err
+		}
+	}
+
+	s := &sqlSource{
+		cfg:        cfg,
+		dataSource: dataSource,
+		opener:     opener,
+		logger:     settings.TelemetrySettings.Logger,
+	}
+
+	if cfg.Mode == ModeFull {
+		reloadMetrics, err := lookupsource.NewReloadMetrics(settings.TelemetrySettings, metadata.ScopeName)
+		if err != nil {
+			return nil, err
+		}
+		s.table = lookupsource.NewTableLookup(lookupsource.TableLookupSettings{
+			ReloadInterval: cfg.ReloadInterval,
+			Load:           s.loadTable,
+			Logger:         s.logger,
+			OnReload:       reloadMetrics.Record,
+		})
+		return lookupsource.NewSource(
+			s.table.Lookup,
+			func() string { return sourceType },
+			s.start,
+			s.shutdown,
+		), nil
+	}
+
+	lookupFn := s.lookup
+	var batchLookupFn lookupsource.BatchLookupFunc
+	if cfg.BatchQuery != "" {
+		batchLookupFn = s.batchLookup
+	}
+	if cfg.Cache.Enabled {
+		cache := lookupsource.NewCache(cfg.Cache)
+		lookupFn = lookupsource.WrapWithCache(cache, lookupFn)
+		if batchLookupFn != nil {
+			batchLookupFn = lookupsource.WrapBatchWithCache(cache, batchLookupFn)
+		}
+	}
+	if batchLookupFn != nil {
+		return lookupsource.NewBatchSource(
+			lookupFn,
+			batchLookupFn,
+			func() string { return sourceType },
+			s.start,
+			s.shutdown,
+		), nil
+	}
+	return lookupsource.NewSource(
+		lookupFn,
+		func() string { return sourceType },
+		s.start,
+		s.shutdown,
+	), nil
+}
+
+type sqlSource struct {
+	cfg        *Config
+	dataSource string
+	opener     sqlquery.SQLOpenerFunc
+	logger     *zap.Logger
+
+	// table serves lookups in full mode.
+	table *lookupsource.TableLookup
+
+	mu     sync.Mutex
+	db     *sql.DB
+	client sqlquery.DbClient
+}
+
+func (s *sqlSource) start(ctx context.Context, host component.Host) error {
+	db, err := s.opener(s.cfg.Driver, s.dataSource)
+	if err != nil {
+		return fmt.Errorf("failed to open database connection: %w", err)
+	}
+	db.SetMaxOpenConns(s.cfg.MaxOpenConn)
+
+	s.mu.Lock()
+	s.db = db
+	s.client = sqlquery.NewDbClient(sqlquery.DbWrapper{Db: db}, s.cfg.Query, s.logger, sqlquery.TelemetryConfig{})
+	s.mu.Unlock()
+
+	if s.table != nil {
+		return s.table.Start(ctx, host)
+	}
+	return nil
+}
+
+func (s *sqlSource) shutdown(ctx context.Context) error {
+	var errs []error
+	if s.table != nil {
+		errs = append(errs, s.table.Shutdown(ctx))
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.db != nil {
+		errs = append(errs, s.db.Close())
+		s.db, s.client = nil, nil
+	}
+	return errors.Join(errs...)
+}
+
+func (s *sqlSource) queryRows(ctx context.Context, args ...any) ([]sqlquery.StringMap, error) {
+	s.mu.Lock()
+	client := s.client
+	s.mu.Unlock()
+	if client == nil {
+		return nil, errors.New("sql source is not started")
+	}
+	return s.queryClient(ctx, client, args...)
+}
+
+func (s *sqlSource) queryClient(ctx context.Context, client sqlquery.DbClient, args ...any) ([]sqlquery.StringMap, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.Timeout)
+	defer cancel()
+	rows, err := client.QueryRows(ctx, args...)
+	if err != nil && !errors.Is(err, sqlquery.ErrNullValueWarning) {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// lookup resolves key with the query in per_key mode, using the first row
+// returned.
+func (s *sqlSource) lookup(ctx context.Context, key string) (any, bool, error) {
+	rows, err := s.queryRows(ctx, key)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(rows) == 0 {
+		return nil, false, nil
+	}
+	return s.rowValue(rows[0])
+}
+
+// batchLookup resolves keys with the batch query in per_key mode, running it
+// once per maxBatchKeys keys. For each key, the first row returned is used.
+func (s *sqlSource) batchLookup(ctx context.Context, keys []string) ([]lookupsource.LookupResult, error) {
+	s.mu.Lock()
+	db := s.db
+	s.mu.Unlock()
+	if db == nil {
+		return nil, errors.New("sql source is not started")
+	}
+
+	results := make([]lookupsource.LookupResult, len(keys))
+	indexes := make(map[string]int, len(keys))
+	for i, key := range keys {
+		indexes[key] = i
+	}
+	for chunk := range slices.Chunk(keys, maxBatchKeys) {
+		args := make([]any, len(chunk))
+		for i, key := range chunk {
+			args[i] = key
+		}
+		client := sqlquery.NewDbClient(sqlquery.DbWrapper{Db: db}, s.batchSQL(len(chunk)), s.logger, sqlquery.TelemetryConfig{})
+		rows, err := s.queryClient(ctx, client, args...)
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			key, ok := row[s.cfg.KeyColumn]
+			if !ok {
+				return nil, fmt.Errorf("key column %q not found in query result", s.cfg.KeyColumn)
+			}
+			i, ok := indexes[key]
+			if !ok || results[i].Found || results[i].Err != nil {
+				continue
+			}
+			results[i].Value, results[i].Found, results[i].Err = s.rowValue(row)
+		}
+	}
+	return results, nil
+}
+
+// batchSQL returns the batch query for n keys, with the parameter syntax of
+// the driver.
+func (s *sqlSource) batchSQL(n int) string {
+	params := make([]string, n)
+	for i := range params {
+		if s.cfg.Driver == sqlquery.DriverPostgres {
+			params[i] = "$" + strconv.Itoa(i+1)
+		} else {
+			params[i] = "?"
+		}
+	}
+	return strings.ReplaceAll(s.cfg.BatchQuery, keysPlaceholder, strings.Join(params, ", "))
+}
+
+// loadTable runs the query in full mode and indexes the rows by key column.
+// When several rows share a key, the first one wins.
+func (s *sqlSource) loadTable(ctx context.Context) (map[string]any, error) {
+	rows, err := s.queryRows(ctx)
+	if err != nil {
+		return nil, err
+	}
+	table := make(map[string]any, len(rows))
+	for _, row := range rows {
+		key, ok := row[s.cfg.KeyColumn]
+		if !ok {
+			return nil, fmt.Errorf("key column %q not found in query result", s.cfg.KeyColumn)
+		}
+		if _, exists := table[key]; exists {
+			continue
+		}
+		value, found, err := s.rowValue(row)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			table[key] = value
+		}
+	}
+	return table, nil
+}
+
+// rowValue returns the value column of row, or the whole row as a map.
+func (s *sqlSource) rowValue(row sqlquery.StringMap) (any, bool, error) {
+	if s.cfg.ValueColumn != "" {
+		value, ok := row[s.cfg.ValueColumn]
+		if !ok {
+			return nil, false, fmt.Errorf("value column %q not found in query result", s.cfg.ValueColumn)
+		}
+		return value, true, nil
+	}
+	m := make(map[string]any, len(row))
+	for column, value := range row {
+		m[column] = value
+	}
+	return m, true, nil
+}
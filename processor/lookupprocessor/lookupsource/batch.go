@@ -0,0 +1,187 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package lookupsource // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/lookupprocessor/lookupsource"
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// LookupResult is the result of the lookup of a single key in a batch.
+type LookupResult struct {
+	// Value is the lookup result when Found is true.
+	Value any
+	// Found is false when the key was not found (not an error).
+	Found bool
+	// Err is set when the lookup of this key failed.
+	Err error
+}
+
+// BatchLookupFunc performs the lookup of several distinct keys in one call.
+// On success, the returned slice holds one result per key, in the order of
+// keys. An error means the whole batch failed.
+type BatchLookupFunc func(ctx context.Context, keys []string) ([]LookupResult, error)
+
+// BatchSource is implemented by sources that can resolve several keys in one
+// call. The processor resolves all the distinct keys of a batch of telemetry
+// with a single BatchLookup call when the source implements it, and calls
+// Lookup once per distinct key otherwise.
+//
+// Use [NewBatchSource] to create implementations.
+type BatchSource interface {
+	Source
+	BatchLookup(ctx context.Context, keys []string) ([]LookupResult, error)
+}
+
+// NewBatchSource creates a BatchSource from functional components. The
+// parameters are the ones of [NewSource], plus:
+//   - batchLookup: Required. The function that performs batch lookups.
+//
+// Example:
+//
+//	lookupFn := lookupsource.WrapWithCache(cache, myLookupFunc)
+//	source := lookupsource.NewBatchSource(
+//	    lookupFn,
+//	    lookupsource.WrapBatchWithCache(cache, lookupsource.ParallelBatchLookup(myLookupFunc, 8)),
+//	    func() string { return "mysource" },
+//	    nil,
+//	    nil,
+//	)
+func NewBatchSource(
+	lookup LookupFunc,
+	batchLookup BatchLookupFunc,
+	typeFunc TypeFunc,
+	start StartFunc,
+	shutdown ShutdownFunc,
+) BatchSource {
+	return &batchSourceImpl{
+		sourceImpl: sourceImpl{
+			lookupFn:   lookup,
+			typeFn:     typeFunc,
+			startFn:    start,
+			shutdownFn: shutdown,
+		},
+		batchLookupFn: batchLookup,
+	}
+}
+
+type batchSourceImpl struct {
+	sourceImpl
+	batchLookupFn BatchLookupFunc
+}
+
+var _ BatchSource = (*batchSourceImpl)(nil)
+
+func (s *batchSourceImpl) BatchLookup(ctx context.Context, keys []string) ([]LookupResult, error) {
+	if s.batchLookupFn == nil {
+		return make([]LookupResult, len(keys)), nil
+	}
+	return s.batchLookupFn(ctx, keys)
+}
+
+// ParallelBatchLookup returns a BatchLookupFunc that resolves each key with
+// fn, running at most parallelism lookups concurrently. It suits sources
+// whose backend has no bulk API, such as DNS, where the latency of a batch
+// is then bounded by the slowest lookups rather than by their sum.
+//
+// Once ctx is canceled, no further lookup is started, and the keys whose
+// lookup was not started get ctx.Err() as their error.
+func ParallelBatchLookup(fn LookupFunc, parallelism int) BatchLookupFunc {
+	parallelism = max(parallelism, 1)
+	return func(ctx context.Context, keys []string) ([]LookupResult, error) {
+		results := make([]LookupResult, len(keys))
+		if len(keys) == 1 || parallelism == 1 {
+			for i, key := range keys {
+				if err := ctx.Err(); err != nil {
+					setErr(results[i:], err)
+					break
+				}
+				results[i].Value, results[i].Found, results[i].Err = fn(ctx, key)
+			}
+			return results, nil
+		}
+
+		var wg sync.WaitGroup
+		sem := make(chan struct{}, parallelism)
+		for i, key := range keys {
+			if err := acquire(ctx, sem); err != nil {
+				setErr(results[i:], err)
+				break
+			}
+			wg.Go(func() {
+				defer func() { <-sem }()
+				results[i].Value, results[i].Found, results[i].Err = fn(ctx, key)
+			})
+		}
+		wg.Wait()
+		return results, nil
+	}
+}
+
+// acquire takes a slot of sem, unless ctx is canceled first.
+func acquire(ctx context.Context, sem chan struct{}) error {
+	select {
+	case sem <- struct{}{}:
+		// select picks a random case when both are ready.
+		if err := ctx.Err(); err != nil {
+			<-sem
+			return err
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// setErr fails the lookups that were not started.
+func setErr(results []LookupResult, err error) {
+	for i := range results {
+		results[i].Err = err
+	}
+}
+
+// WrapBatchWithCache wraps a batch lookup function with caching. Cached keys
+// are served from the cache, and only the remaining keys are passed to fn.
+// Results are cached as by [WrapWithCache], including negative results when
+// the cache has a NegativeTTL; failed lookups are not cached.
+//
+// The same cache can be shared with the LookupFunc of the source, wrapped
+// with [WrapWithCache].
+func WrapBatchWithCache(cache *Cache, fn BatchLookupFunc) BatchLookupFunc {
+	if cache == nil || !cache.config.Enabled {
+		return fn
+	}
+	return func(ctx context.Context, keys []string) ([]LookupResult, error) {
+		results := make([]LookupResult, len(keys))
+		var missIndexes []int
+		var missKeys []string
+		for i, key := range keys {
+			if val, lookupFound, cacheHit := cache.get(key); cacheHit {
+				results[i] = LookupResult{Value: val, Found: lookupFound}
+				continue
+			}
+			missIndexes = append(missIndexes, i)
+			missKeys = append(missKeys, key)
+		}
+		if len(missKeys) == 0 {
+			return results, nil
+		}
+
+		missResults, err := fn(ctx, missKeys)
+		if err != nil {
+			return nil, err
+		}
+		if len(missResults) != len(missKeys) {
+			return nil, fmt.Errorf("batch lookup returned %d results for %d keys", len(missResults), len(missKeys))
+		}
+		for j, result := range missResults {
+			results[missIndexes[j]] = result
+			if result.Err == nil {
+				cache.set(missKeys[j], result.Value, result.Found)
+			}
+		}
+		return results, nil
+	}
+}
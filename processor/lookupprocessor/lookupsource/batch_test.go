@@ -0,0 +1,186 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package lookupsource
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewBatchSource(t *testing.T) {
+	source := NewBatchSource(
+		func(_ context.Context, key string) (any, bool, error) {
+			return "single-" + key, true, nil
+		},
+		func(_ context.Context, keys []string) ([]LookupResult, error) {
+			results := make([]LookupResult, len(keys))
+			for i, key := range keys {
+				results[i] = LookupResult{Value: "batch-" + key, Found: true}
+			}
+			return results, nil
+		},
+		func() string { return "test" },
+		nil,
+		nil,
+	)
+
+	assert.Equal(t, "test", source.Type())
+	val, found, err := source.Lookup(t.Context(), "a")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "single-a", val)
+
+	results, err := source.BatchLookup(t.Context(), []string{"a", "b"})
+	require.NoError(t, err)
+	assert.Equal(t, []LookupResult{
+		{Value: "batch-a", Found: true},
+		{Value: "batch-b", Found: true},
+	}, results)
+
+	var asSource Source = source
+	_, ok := asSource.(BatchSource)
+	assert.True(t, ok)
+}
+
+func TestParallelBatchLookup(t *testing.T) {
+	var running, maxRunning atomic.Int64
+	fn := func(_ context.Context, key string) (any, bool, error) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			m := maxRunning.Load()
+			if n <= m || maxRunning.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		switch key {
+		case "missing":
+			return nil, false, nil
+		case "broken":
+			return nil, false, errors.New("boom")
+		default:
+			return "value-" + key, true, nil
+		}
+	}
+
+	keys := []string{"a", "missing", "b", "broken", "c", "d", "e", "f"}
+	results, err := ParallelBatchLookup(fn, 3)(t.Context(), keys)
+	require.NoError(t, err)
+	require.Len(t, results, len(keys))
+	assert.Equal(t, LookupResult{Value: "value-a", Found: true}, results[0])
+	assert.Equal(t, LookupResult{}, results[1])
+	assert.Equal(t, LookupResult{Value: "value-b", Found: true}, results[2])
+	assert.EqualError(t, results[3].Err, "boom")
+	assert.Equal(t, LookupResult{Value: "value-f", Found: true}, results[7])
+	assert.LessOrEqual(t, maxRunning.Load(), int64(3))
+}
+
+func TestParallelBatchLookupCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(t.Context())
+	started := make(chan string, 10)
+	fn := func(ctx context.Context, key string) (any, bool, error) {
+		started <- key
+		<-ctx.Done()
+		return nil, false, ctx.Err()
+	}
+
+	keys := []string{"a", "b", "c", "d", "e"}
+	go func() {
+		// Cancel once the first lookups hold every slot.
+		<-started
+		<-started
+		cancel()
+	}()
+	results, err := ParallelBatchLookup(fn, 2)(ctx, keys)
+	require.NoError(t, err)
+	require.Len(t, results, len(keys))
+	for i, result := range results {
+		assert.ErrorIs(t, result.Err, context.Canceled, "key %q", keys[i])
+	}
+	assert.Empty(t, started, "no lookup is started once the batch is canceled")
+
+	results, err = ParallelBatchLookup(fn, 1)(ctx, keys)
+	require.NoError(t, err)
+	for i, result := range results {
+		assert.ErrorIs(t, result.Err, context.Canceled, "key %q", keys[i])
+	}
+	assert.Empty(t, started)
+}
+
+func TestWrapBatchWithCache(t *testing.T) {
+	var mu sync.Mutex
+	var calls [][]string
+	fn := func(_ context.Context, keys []string) ([]LookupResult, error) {
+		mu.Lock()
+		calls = append(calls, keys)
+		mu.Unlock()
+		results := make([]LookupResult, len(keys))
+		for i, key := range keys {
+			switch key {
+			case "missing":
+			case "broken":
+				results[i].Err = errors.New("boom")
+			default:
+				results[i] = LookupResult{Value: "value-" + key, Found: true}
+			}
+		}
+		return results, nil
+	}
+
+	cache := NewCache(CacheConfig{Enabled: true, Size: 10, TTL: time.Minute, NegativeTTL: time.Minute})
+	batchLookup := WrapBatchWithCache(cache, fn)
+
+	results, err := batchLookup(t.Context(), []string{"a", "missing", "broken"})
+	require.NoError(t, err)
+	assert.Equal(t, LookupResult{Value: "value-a", Found: true}, results[0])
+	assert.False(t, results[1].Found)
+	require.Error(t, results[2].Err)
+
+	results, err = batchLookup(t.Context(), []string{"b", "a", "missing", "broken"})
+	require.NoError(t, err)
+	assert.Equal(t, []LookupResult{
+		{Value: "value-b", Found: true},
+		{Value: "value-a", Found: true},
+		{},
+	}, results[:3])
+	assert.Equal(t, [][]string{{"a", "missing", "broken"}, {"b", "broken"}}, calls,
+		"positive and negative results are cached, failures are not")
+
+	// Single-key lookups share the cache.
+	lookup := WrapWithCache(cache, func(context.Context, string) (any, bool, error) {
+		return nil, false, errors.New("not expected")
+	})
+	val, found, err := lookup(t.Context(), "b")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "value-b", val)
+
+	results, err = batchLookup(t.Context(), []string{"a", "b"})
+	require.NoError(t, err)
+	assert.Len(t, results, 2)
+	assert.Len(t, calls, 2, "fully cached batches do not call the source")
+}
+
+func TestWrapBatchWithCacheError(t *testing.T) {
+	cache := NewCache(CacheConfig{Enabled: true, Size: 10})
+	batchLookup := WrapBatchWithCache(cache, func(context.Context, []string) ([]LookupResult, error) {
+		return nil, errors.New("backend down")
+	})
+	_, err := batchLookup(t.Context(), []string{"a"})
+	require.EqualError(t, err, "backend down")
+
+	batchLookup = WrapBatchWithCache(cache, func(context.Context, []string) ([]LookupResult, error) {
+		return []LookupResult{}, nil
+	})
+	_, err = batchLookup(t.Context(), []string{"a"})
+	assert.ErrorContains(t, err, "returned 0 results for 1 keys")
+}
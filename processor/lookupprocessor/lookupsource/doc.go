@@ -33,6 +33,14 @@
 //	    return lookupsource.NewSource(lookupFn, func() string { return "mysource" }, nil, nil), nil
 //	}
 //
+// # Batch Lookups
+//
+// Sources that can resolve several keys in one call implement [BatchSource],
+// created with [NewBatchSource]. The processor then resolves all the distinct
+// keys of a batch of telemetry with a single [BatchSource.BatchLookup] call.
+// [ParallelBatchLookup] and [WrapBatchWithCache] help building batch lookups
+// from a [LookupFunc].
+//
 // # Registering with the Processor
 //
 //	import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/lookupprocessor"
@@ -7,10 +7,8 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"sync"
 	"time"
 
-	"go.opentelemetry.io/collector/component"
 	"go.uber.org/zap"
 )
 
@@ -34,17 +32,7 @@ type FileLookupSettings struct {
 // FileLookup is a lookup source backed by a file. With ReloadInterval > 0 it
 // re-reads the file on that interval so changes apply without a restart.
 type FileLookup struct {
-	path           string
-	reloadInterval time.Duration
-	parse          ParseFunc
-	logger         *zap.Logger
-	onReload       ReloadCallback
-
-	mu   sync.RWMutex
-	data map[string]any
-
-	cancel context.CancelFunc
-	wg     sync.WaitGroup
+	*TableLookup
 }
 
 // NewFileLookup creates a FileLookup. Call Start to load the file and begin reloading.
@@ -53,87 +41,21 @@ func NewFileLookup(set FileLookupSettings) *FileLookup {
 	if logger == nil {
 		logger = zap.NewNop()
 	}
-	return &FileLookup{
-		path:           set.Path,
-		reloadInterval: set.ReloadInterval,
-		parse:          set.Parse,
-		logger:         logger,
-		onReload:       set.OnReload,
-		data:           map[string]any{},
-	}
-}
-
-func (f *FileLookup) load() error {
-	content, err := os.ReadFile(f.path)
-	if err != nil {
-		return fmt.Errorf("failed to read file %q: %w", f.path, err)
-	}
-	data, err := f.parse(content)
-	if err != nil {
-		return fmt.Errorf("failed to parse file %q: %w", f.path, err)
-	}
-	if data == nil {
-		data = map[string]any{}
-	}
-
-	f.mu.Lock()
-	f.data = data
-	f.mu.Unlock()
-	return nil
-}
-
-// Start loads the file once and, if ReloadInterval > 0, starts the reload loop.
-func (f *FileLookup) Start(_ context.Context, _ component.Host) error {
-	if err := f.load(); err != nil {
-		return err
-	}
-	if f.reloadInterval <= 0 {
-		return nil
-	}
-
-	ctx, cancel := context.WithCancel(context.Background())
-	f.cancel = cancel
-	f.wg.Add(1)
-	go f.reloadLoop(ctx)
-	return nil
-}
-
-func (f *FileLookup) reloadLoop(ctx context.Context) {
-	defer f.wg.Done()
-
-	ticker := time.NewTicker(f.reloadInterval)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case <-ticker.C:
-			err := f.load()
+	path, parse := set.Path, set.Parse
+	return &FileLookup{TableLookup: NewTableLookup(TableLookupSettings{
+		ReloadInterval: set.ReloadInterval,
+		Load: func(context.Context) (map[string]any, error) {
+			content, err := os.ReadFile(path)
 			if err != nil {
-				f.logger.Warn("failed to reload lookup file; keeping previously loaded data",
-					zap.String("path", f.path), zap.Error(err))
+				return nil, fmt.Errorf("failed to read file %q: %w", path, err)
 			}
-			if f.onReload != nil {
-				f.onReload(ctx, err == nil)
+			data, err := parse(content)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse file %q: %w", path, err)
 			}
-		}
-	}
-}
-
-// Lookup returns the value stored for key, if present.
-func (f *FileLookup) Lookup(_ context.Context, key string) (any, bool, error) {
-	f.mu.RLock()
-	defer f.mu.RUnlock()
-	val, found := f.data[key]
-	return val, found, nil
-}
-
-// Shutdown stops the reload loop and waits for it to exit.
-func (f *FileLookup) Shutdown(_ context.Context) error {
-	if f.cancel != nil {
-		f.cancel()
-	}
-	f.wg.Wait()
-	return nil
+			return data, nil
+		},
+		Logger:   logger.With(zap.String("path", path)),
+		OnReload: set.OnReload,
+	})}
 }
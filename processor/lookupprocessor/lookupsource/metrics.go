@@ -10,7 +10,7 @@ import (
 	"go.opentelemetry.io/otel/metric"
 )
 
-// ReloadMetrics counts file-based source reloads. A nil value is safe to use.
+// ReloadMetrics counts reloads of table-based sources, such as file sources. A nil value is safe to use.
 type ReloadMetrics struct {
 	reloads  metric.Int64Counter
 	failures metric.Int64Counter
@@ -22,7 +22,7 @@ func NewReloadMetrics(ts component.TelemetrySettings, scopeName string) (*Reload
 
 	reloads, err := meter.Int64Counter(
 		"lookup_source_reloads",
-		metric.WithDescription("Number of successful reloads of a file-based lookup source."),
+		metric.WithDescription("Number of successful reloads of a table-based lookup source."),
 		metric.WithUnit("{reload}"),
 	)
 	if err != nil {
@@ -31,7 +31,7 @@ func NewReloadMetrics(ts component.TelemetrySettings, scopeName string) (*Reload
 
 	failures, err := meter.Int64Counter(
 		"lookup_source_reload_failures",
-		metric.WithDescription("Number of failed reloads of a file-based lookup source."),
+		metric.WithDescription("Number of failed reloads of a table-based lookup source."),
 		metric.WithUnit("{reload}"),
 	)
 	if err != nil {
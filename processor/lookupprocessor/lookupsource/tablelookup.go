@@ -0,0 +1,126 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package lookupsource // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/lookupprocessor/lookupsource"
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.uber.org/zap"
+)
+
+// LoadFunc loads a whole lookup table. A value is either a scalar (for 1:1
+// lookups) or a map[string]any (for 1:N lookups).
+type LoadFunc func(ctx context.Context) (map[string]any, error)
+
+// TableLookupSettings configures a TableLookup.
+type TableLookupSettings struct {
+	ReloadInterval time.Duration
+	Load           LoadFunc
+	Logger         *zap.Logger
+	OnReload       ReloadCallback
+}
+
+// TableLookup is a lookup source that holds a whole table in memory. With
+// ReloadInterval > 0 it reloads the table on that interval so changes apply
+// without a restart.
+type TableLookup struct {
+	reloadInterval time.Duration
+	loadFn         LoadFunc
+	logger         *zap.Logger
+	onReload       ReloadCallback
+
+	mu   sync.RWMutex
+	data map[string]any
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewTableLookup creates a TableLookup. Call Start to load the table and begin reloading.
+func NewTableLookup(set TableLookupSettings) *TableLookup {
+	logger := set.Logger
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &TableLookup{
+		reloadInterval: set.ReloadInterval,
+		loadFn:         set.Load,
+		logger:         logger,
+		onReload:       set.OnReload,
+		data:           map[string]any{},
+	}
+}
+
+func (t *TableLookup) load(ctx context.Context) error {
+	data, err := t.loadFn(ctx)
+	if err != nil {
+		return err
+	}
+	if data == nil {
+		data = map[string]any{}
+	}
+
+	t.mu.Lock()
+	t.data = data
+	t.mu.Unlock()
+	return nil
+}
+
+// Start loads the table once and, if ReloadInterval > 0, starts the reload loop.
+func (t *TableLookup) Start(ctx context.Context, _ component.Host) error {
+	if err := t.load(ctx); err != nil {
+		return err
+	}
+	if t.reloadInterval <= 0 {
+		return nil
+	}
+
+	loopCtx, cancel := context.WithCancel(context.Background())
+	t.cancel = cancel
+	t.wg.Add(1)
+	go t.reloadLoop(loopCtx)
+	return nil
+}
+
+func (t *TableLookup) reloadLoop(ctx context.Context) {
+	defer t.wg.Done()
+
+	ticker := time.NewTicker(t.reloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := t.load(ctx)
+			if err != nil {
+				t.logger.Warn("failed to reload lookup table; keeping previously loaded data", zap.Error(err))
+			}
+			if t.onReload != nil {
+				t.onReload(ctx, err == nil)
+			}
+		}
+	}
+}
+
+// Lookup returns the value stored for key, if present.
+func (t *TableLookup) Lookup(_ context.Context, key string) (any, bool, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	val, found := t.data[key]
+	return val, found, nil
+}
+
+// Shutdown stops the reload loop and waits for it to exit.
+func (t *TableLookup) Shutdown(_ context.Context) error {
+	if t.cancel != nil {
+		t.cancel()
+	}
+	t.wg.Wait()
+	return nil
+}
@@ -6,6 +6,7 @@ package lookupprocessor // import "github.com/open-telemetry/opentelemetry-colle
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/pdata/pcommon"
@@ -14,6 +15,7 @@ import (
 	"go.opentelemetry.io/collector/pdata/ptrace"
 	"go.uber.org/zap"
 
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl/contexts/ottldatapoint"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl/contexts/ottllog"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl/contexts/ottlspan"
@@ -21,13 +23,13 @@ import (
 )
 
 // lookupProcessor is generic over the OTTL transform context type.
-type lookupProcessor[T any] struct {
+type lookupProcessor[T transformContext] struct {
 	source  lookupsource.Source
 	lookups []parsedLookup[T]
 	logger  *zap.Logger
 }
 
-func newLookupProcessor[T any](source lookupsource.Source, lookups []parsedLookup[T], logger *zap.Logger) *lookupProcessor[T] {
+func newLookupProcessor[T transformContext](source lookupsource.Source, lookups []parsedLookup[T], logger *zap.Logger) *lookupProcessor[T] {
 	return &lookupProcessor[T]{
 		source:  source,
 		lookups: lookups,
@@ -43,45 +45,154 @@ func (p *lookupProcessor[T]) Shutdown(ctx context.Context) error {
 	return p.source.Shutdown(ctx)
 }
 
-func (p *lookupProcessor[T]) evalAndProcess(ctx context.Context, tCtx T, recordAttrs, resourceAttrs pcommon.Map) {
+// transformContext is implemented by the OTTL transform contexts.
+type transformContext interface {
+	Close()
+}
+
+// batchRecord is a record of a batch with its transform context, and the
+// record and resource attributes lookups write to.
+type batchRecord[T transformContext] struct {
+	tCtx          T
+	recordAttrs   pcommon.Map
+	resourceAttrs pcommon.Map
+}
+
+// pendingWrite is a record waiting for the result of the lookup of key.
+type pendingWrite[T transformContext] struct {
+	key    string
+	record *batchRecord[T]
+}
+
+// process applies the lookups to records, and closes their transform
+// contexts. Each lookup evaluates its key for all records, resolves the
+// distinct keys at once, and writes the results in one pass. The transform
+// contexts are built once for all the lookups, which run one after the
+// other, so a key expression can use the results of the previous lookups.
+func (p *lookupProcessor[T]) process(ctx context.Context, records []batchRecord[T]) {
+	defer func() {
+		for i := range records {
+			records[i].tCtx.Close()
+		}
+	}()
+
+	var pending []pendingWrite[T]
 	for li := range p.lookups {
 		lookup := &p.lookups[li]
-		rawKey, err := lookup.keyExpr.Eval(ctx, tCtx)
-		if err != nil {
-			p.logger.Debug("failed to evaluate key expression", zap.Error(err))
+		pending = pending[:0]
+		for i := range records {
+			key, ok := p.evalKey(ctx, lookup, records[i].tCtx)
+			if !ok {
+				continue
+			}
+			pending = append(pending, pendingWrite[T]{key: key, record: &records[i]})
+		}
+		if len(pending) == 0 {
 			continue
 		}
-		processLookupResult(ctx, p.source, p.logger, rawKey, lookup.context, lookup.attributes, recordAttrs, resourceAttrs)
+
+		results := p.resolve(ctx, pending)
+		for i := range pending {
+			w := &pending[i]
+			result, ok := results[w.key]
+			if !ok {
+				// The lookup failed; nothing is written.
+				continue
+			}
+			writeLookupResult(result.Value, result.Found, lookup.context, lookup.attributes, w.record.recordAttrs, w.record.resourceAttrs)
+		}
 	}
 }
 
-// processLookupResult performs the source lookup and writes results to record or resource attributes.
-// If the key is nil or empty, or if the lookup fails, the function returns without writing.
-func processLookupResult(
-	ctx context.Context,
-	source lookupsource.Source,
-	logger *zap.Logger,
-	key any,
-	lookupCtx ContextID,
-	attributes []AttributeMapping,
-	recordAttrs pcommon.Map,
-	resourceAttrs pcommon.Map,
-) {
-	if key == nil {
-		return
+// evalKey returns the lookup key of a record. Composite keys join the values
+// of their parts with the separator of the lookup. It returns false if the
+// key, or any part of a composite key, is nil or empty.
+func (p *lookupProcessor[T]) evalKey(ctx context.Context, lookup *parsedLookup[T], tCtx T) (string, bool) {
+	if len(lookup.keyExprs) == 1 {
+		return p.evalKeyPart(ctx, lookup.keyExprs[0], tCtx)
 	}
 
-	keyStr := anyToString(key)
-	if keyStr == "" {
-		return
+	var b strings.Builder
+	for i, keyExpr := range lookup.keyExprs {
+		part, ok := p.evalKeyPart(ctx, keyExpr, tCtx)
+		if !ok {
+			return "", false
+		}
+		if i > 0 {
+			b.WriteString(lookup.keySeparator)
+		}
+		b.WriteString(part)
 	}
+	return b.String(), true
+}
 
-	result, found, err := source.Lookup(ctx, keyStr)
+func (p *lookupProcessor[T]) evalKeyPart(ctx context.Context, keyExpr *ottl.ValueExpression[T], tCtx T) (string, bool) {
+	rawKey, err := keyExpr.Eval(ctx, tCtx)
 	if err != nil {
-		logger.Debug("lookup failed", zap.String("key", keyStr), zap.Error(err))
-		return
+		p.logger.Debug("failed to evaluate key expression", zap.Error(err))
+		return "", false
+	}
+	if rawKey == nil {
+		return "", false
 	}
+	key := anyToString(rawKey)
+	return key, key != ""
+}
+
+// resolve looks up the distinct keys of pending. Sources implementing
+// lookupsource.BatchSource resolve them in a single call; other sources are
+// called once per distinct key. Keys whose lookup failed are missing from the
+// returned map.
+func (p *lookupProcessor[T]) resolve(ctx context.Context, pending []pendingWrite[T]) map[string]lookupsource.LookupResult {
+	seen := make(map[string]struct{}, len(pending))
+	keys := make([]string, 0, len(pending))
+	for i := range pending {
+		if _, ok := seen[pending[i].key]; !ok {
+			seen[pending[i].key] = struct{}{}
+			keys = append(keys, pending[i].key)
+		}
+	}
+
+	results := make(map[string]lookupsource.LookupResult, len(keys))
+	if batchSource, ok := p.source.(lookupsource.BatchSource); ok {
+		batch, err := batchSource.BatchLookup(ctx, keys)
+		if err == nil && len(batch) != len(keys) {
+			err = fmt.Errorf("batch lookup returned %d results for %d keys", len(batch), len(keys))
+		}
+		if err != nil {
+			p.logger.Debug("batch lookup failed", zap.Int("keys", len(keys)), zap.Error(err))
+			return results
+		}
+		for i, key := range keys {
+			if batch[i].Err != nil {
+				p.logger.Debug("lookup failed", zap.String("key", key), zap.Error(batch[i].Err))
+				continue
+			}
+			results[key] = batch[i]
+		}
+		return results
+	}
+
+	for _, key := range keys {
+		value, found, err := p.source.Lookup(ctx, key)
+		if err != nil {
+			p.logger.Debug("lookup failed", zap.String("key", key), zap.Error(err))
+			continue
+		}
+		results[key] = lookupsource.LookupResult{Value: value, Found: found}
+	}
+	return results
+}
 
+// writeLookupResult writes the result of a lookup to record or resource attributes.
+func writeLookupResult(
+	result any,
+	found bool,
+	lookupCtx ContextID,
+	attributes []AttributeMapping,
+	recordAttrs pcommon.Map,
+	resourceAttrs pcommon.Map,
+) {
 	for ai := range attributes {
 		attr := &attributes[ai]
 		attrCtx := attr.GetContext(lookupCtx)
@@ -109,6 +220,7 @@ type logsLookupProcessor struct {
 }
 
 func (p *logsLookupProcessor) processLogs(ctx context.Context, ld plog.Logs) (plog.Logs, error) {
+	var records []batchRecord[*ottllog.TransformContext]
 	for i := 0; i < ld.ResourceLogs().Len(); i++ {
 		rl := ld.ResourceLogs().At(i)
 		resourceAttrs := rl.Resource().Attributes()
@@ -116,12 +228,15 @@ func (p *logsLookupProcessor) processLogs(ctx context.Context, ld plog.Logs) (pl
 			sl := rl.ScopeLogs().At(j)
 			for k := 0; k < sl.LogRecords().Len(); k++ {
 				lr := sl.LogRecords().At(k)
-				tCtx := ottllog.NewTransformContextPtr(rl, sl, lr)
-				p.evalAndProcess(ctx, tCtx, lr.Attributes(), resourceAttrs)
-				tCtx.Close()
+				records = append(records, batchRecord[*ottllog.TransformContext]{
+					tCtx:          ottllog.NewTransformContextPtr(rl, sl, lr),
+					recordAttrs:   lr.Attributes(),
+					resourceAttrs: resourceAttrs,
+				})
 			}
 		}
 	}
+	p.process(ctx, records)
 	return ld, nil
 }
 
@@ -130,6 +245,7 @@ type tracesLookupProcessor struct {
 }
 
 func (p *tracesLookupProcessor) processTraces(ctx context.Context, td ptrace.Traces) (ptrace.Traces, error) {
+	var records []batchRecord[*ottlspan.TransformContext]
 	for i := 0; i < td.ResourceSpans().Len(); i++ {
 		rs := td.ResourceSpans().At(i)
 		resourceAttrs := rs.Resource().Attributes()
@@ -137,12 +253,15 @@ func (p *tracesLookupProcessor) processTraces(ctx context.Context, td ptrace.Tra
 			ss := rs.ScopeSpans().At(j)
 			for k := 0; k < ss.Spans().Len(); k++ {
 				span := ss.Spans().At(k)
-				tCtx := ottlspan.NewTransformContextPtr(rs, ss, span)
-				p.evalAndProcess(ctx, tCtx, span.Attributes(), resourceAttrs)
-				tCtx.Close()
+				records = append(records, batchRecord[*ottlspan.TransformContext]{
+					tCtx:          ottlspan.NewTransformContextPtr(rs, ss, span),
+					recordAttrs:   span.Attributes(),
+					resourceAttrs: resourceAttrs,
+				})
 			}
 		}
 	}
+	p.process(ctx, records)
 	return td, nil
 }
 
@@ -151,28 +270,29 @@ type metricsLookupProcessor struct {
 }
 
 func (p *metricsLookupProcessor) processMetrics(ctx context.Context, md pmetric.Metrics) (pmetric.Metrics, error) {
+	var records []batchRecord[*ottldatapoint.TransformContext]
 	for i := 0; i < md.ResourceMetrics().Len(); i++ {
 		rm := md.ResourceMetrics().At(i)
-		resourceAttrs := rm.Resource().Attributes()
 		for j := 0; j < rm.ScopeMetrics().Len(); j++ {
 			sm := rm.ScopeMetrics().At(j)
 			for k := 0; k < sm.Metrics().Len(); k++ {
 				m := sm.Metrics().At(k)
 				switch m.Type() {
 				case pmetric.MetricTypeGauge:
-					processDataPoints(ctx, p.lookupProcessor, m.Gauge().DataPoints(), rm, sm, m, resourceAttrs)
+					records = appendDataPoints(records, m.Gauge().DataPoints(), rm, sm, m)
 				case pmetric.MetricTypeSum:
-					processDataPoints(ctx, p.lookupProcessor, m.Sum().DataPoints(), rm, sm, m, resourceAttrs)
+					records = appendDataPoints(records, m.Sum().DataPoints(), rm, sm, m)
 				case pmetric.MetricTypeHistogram:
-					processDataPoints(ctx, p.lookupProcessor, m.Histogram().DataPoints(), rm, sm, m, resourceAttrs)
+					records = appendDataPoints(records, m.Histogram().DataPoints(), rm, sm, m)
 				case pmetric.MetricTypeExponentialHistogram:
-					processDataPoints(ctx, p.lookupProcessor, m.ExponentialHistogram().DataPoints(), rm, sm, m, resourceAttrs)
+					records = appendDataPoints(records, m.ExponentialHistogram().DataPoints(), rm, sm, m)
 				case pmetric.MetricTypeSummary:
-					processDataPoints(ctx, p.lookupProcessor, m.Summary().DataPoints(), rm, sm, m, resourceAttrs)
+					records = appendDataPoints(records, m.Summary().DataPoints(), rm, sm, m)
 				}
 			}
 		}
 	}
+	p.process(ctx, records)
 	return md, nil
 }
 
@@ -187,21 +307,22 @@ type dataPointWithAttributes interface {
 	Attributes() pcommon.Map
 }
 
-func processDataPoints[DP dataPointWithAttributes](
-	ctx context.Context,
-	p *lookupProcessor[*ottldatapoint.TransformContext],
+func appendDataPoints[DP dataPointWithAttributes](
+	records []batchRecord[*ottldatapoint.TransformContext],
 	dps dataPointSlice[DP],
 	rm pmetric.ResourceMetrics,
 	sm pmetric.ScopeMetrics,
 	m pmetric.Metric,
-	resourceAttrs pcommon.Map,
-) {
+) []batchRecord[*ottldatapoint.TransformContext] {
 	for i := 0; i < dps.Len(); i++ {
 		dp := dps.At(i)
-		tCtx := ottldatapoint.NewTransformContextPtr(rm, sm, m, dp)
-		p.evalAndProcess(ctx, tCtx, dp.Attributes(), resourceAttrs)
-		tCtx.Close()
+		records = append(records, batchRecord[*ottldatapoint.TransformContext]{
+			tCtx:          ottldatapoint.NewTransformContextPtr(rm, sm, m, dp),
+			recordAttrs:   dp.Attributes(),
+			resourceAttrs: rm.Resource().Attributes(),
+		})
 	}
+	return records
 }
 
 // extractValue gets the value to write for a given attribute mapping.
@@ -634,6 +634,257 @@ func TestProcessorValueTypes(t *testing.T) {
 	assert.Equal(t, "{test}", val.Str())
 }
 
+func TestProcessorCompositeKey(t *testing.T) {
+	mappings := map[string]any{
+		"prod/payments": "team-a",
+	}
+
+	factory := NewFactoryWithOptions(WithSources(mockMapSourceFactory(mappings)))
+	cfg := &Config{
+		Source: SourceConfig{Type: "mockmap"},
+		Lookups: []LookupConfig{
+			{
+				Keys:         []string{`resource.attributes["cluster"]`, `log.attributes["namespace"]`},
+				KeySeparator: "/",
+				Attributes:   []AttributeMapping{{Destination: "team", Default: "unknown"}},
+			},
+		},
+	}
+
+	sink := &consumertest.LogsSink{}
+	proc, err := factory.CreateLogs(t.Context(), processortest.NewNopSettings(metadata.Type), cfg, sink)
+	require.NoError(t, err)
+	require.NoError(t, proc.Start(t.Context(), componenttest.NewNopHost()))
+	defer func() { _ = proc.Shutdown(t.Context()) }()
+
+	logs := plog.NewLogs()
+	rl := logs.ResourceLogs().AppendEmpty()
+	rl.Resource().Attributes().PutStr("cluster", "prod")
+	sl := rl.ScopeLogs().AppendEmpty()
+	sl.LogRecords().AppendEmpty().Attributes().PutStr("namespace", "payments")
+	sl.LogRecords().AppendEmpty().Attributes().PutStr("namespace", "search")
+	sl.LogRecords().AppendEmpty()
+
+	require.NoError(t, proc.ConsumeLogs(t.Context(), logs))
+
+	records := sink.AllLogs()[0].ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	team, ok := records.At(0).Attributes().Get("team")
+	assert.True(t, ok)
+	assert.Equal(t, "team-a", team.Str())
+
+	team, ok = records.At(1).Attributes().Get("team")
+	assert.True(t, ok)
+	assert.Equal(t, "unknown", team.Str())
+
+	_, ok = records.At(2).Attributes().Get("team")
+	assert.False(t, ok, "records missing a part of the composite key are not looked up")
+}
+
+func TestProcessorDeduplicatesKeys(t *testing.T) {
+	var lookedUp []string
+	source := lookupsource.NewSourceFactory(
+		"counting",
+		func() lookupsource.SourceConfig { return &mockSourceConfig{} },
+		func(_ context.Context, _ lookupsource.CreateSettings, _ lookupsource.SourceConfig) (lookupsource.Source, error) {
+			return lookupsource.NewSource(
+				func(_ context.Context, key string) (any, bool, error) {
+					lookedUp = append(lookedUp, key)
+					return "value-" + key, true, nil
+				},
+				func() string { return "counting" },
+				nil,
+				nil,
+			), nil
+		},
+	)
+
+	factory := NewFactoryWithOptions(WithSources(source))
+	cfg := &Config{
+		Source: SourceConfig{Type: "counting"},
+		Lookups: []LookupConfig{
+			{
+				Key:        `span.attributes["k"]`,
+				Attributes: []AttributeMapping{{Destination: "out"}},
+			},
+		},
+	}
+
+	sink := &consumertest.TracesSink{}
+	proc, err := factory.CreateTraces(t.Context(), processortest.NewNopSettings(metadata.Type), cfg, sink)
+	require.NoError(t, err)
+	require.NoError(t, proc.Start(t.Context(), componenttest.NewNopHost()))
+	defer func() { _ = proc.Shutdown(t.Context()) }()
+
+	traces := ptrace.NewTraces()
+	spans := traces.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans()
+	for _, key := range []string{"a", "b", "a", "a", "b"} {
+		spans.AppendEmpty().Attributes().PutStr("k", key)
+	}
+
+	require.NoError(t, proc.ConsumeTraces(t.Context(), traces))
+	assert.Equal(t, []string{"a", "b"}, lookedUp, "each distinct key is looked up once")
+
+	spans = sink.AllTraces()[0].ResourceSpans().At(0).ScopeSpans().At(0).Spans()
+	for i := 0; i < spans.Len(); i++ {
+		k, _ := spans.At(i).Attributes().Get("k")
+		out, ok := spans.At(i).Attributes().Get("out")
+		assert.True(t, ok)
+		assert.Equal(t, "value-"+k.Str(), out.Str())
+	}
+}
+
+// mockBatchSourceFactory creates a batch source looking up values from a map,
+// recording the keys of each batch call.
+func mockBatchSourceFactory(mappings map[string]any, batches *[][]string, batchErr error) lookupsource.SourceFactory {
+	return lookupsource.NewSourceFactory(
+		"mockbatch",
+		func() lookupsource.SourceConfig { return &mockSourceConfig{} },
+		func(_ context.Context, _ lookupsource.CreateSettings, _ lookupsource.SourceConfig) (lookupsource.Source, error) {
+			return lookupsource.NewBatchSource(
+				func(context.Context, string) (any, bool, error) {
+					return nil, false, errors.New("single-key lookups are not expected")
+				},
+				func(_ context.Context, keys []string) ([]lookupsource.LookupResult, error) {
+					*batches = append(*batches, keys)
+					if batchErr != nil {
+						return nil, batchErr
+					}
+					results := make([]lookupsource.LookupResult, len(keys))
+					for i, key := range keys {
+						if key == "fails" {
+							results[i].Err = errors.New("key error")
+							continue
+						}
+						results[i].Value, results[i].Found = mappings[key]
+					}
+					return results, nil
+				},
+				func() string { return "mockbatch" },
+				nil,
+				nil,
+			), nil
+		},
+	)
+}
+
+func TestProcessorBatchLookup(t *testing.T) {
+	mappings := map[string]any{
+		"10.0.0.1": "web-1",
+		"10.0.0.2": "web-2",
+	}
+	var batches [][]string
+	factory := NewFactoryWithOptions(WithSources(mockBatchSourceFactory(mappings, &batches, nil)))
+	cfg := &Config{
+		Source: SourceConfig{Type: "mockbatch"},
+		Lookups: []LookupConfig{
+			{
+				Key:        `datapoint.attributes["ip"]`,
+				Attributes: []AttributeMapping{{Destination: "host", Default: "unknown"}},
+			},
+		},
+	}
+
+	sink := &consumertest.MetricsSink{}
+	proc, err := factory.CreateMetrics(t.Context(), processortest.NewNopSettings(metadata.Type), cfg, sink)
+	require.NoError(t, err)
+	require.NoError(t, proc.Start(t.Context(), componenttest.NewNopHost()))
+	defer func() { _ = proc.Shutdown(t.Context()) }()
+
+	md := pmetric.NewMetrics()
+	sm := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty()
+	dps := sm.Metrics().AppendEmpty().SetEmptyGauge().DataPoints()
+	for _, ip := range []string{"10.0.0.1", "10.0.0.3", "10.0.0.1", "fails"} {
+		dps.AppendEmpty().Attributes().PutStr("ip", ip)
+	}
+	sumDps := sm.Metrics().AppendEmpty().SetEmptySum().DataPoints()
+	sumDps.AppendEmpty().Attributes().PutStr("ip", "10.0.0.2")
+
+	require.NoError(t, proc.ConsumeMetrics(t.Context(), md))
+	assert.Equal(t, [][]string{{"10.0.0.1", "10.0.0.3", "fails", "10.0.0.2"}}, batches,
+		"the distinct keys of the whole batch are resolved in one call")
+
+	metrics := sink.AllMetrics()[0].ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+	gotHosts := func(dps pmetric.NumberDataPointSlice) []string {
+		var hosts []string
+		for i := 0; i < dps.Len(); i++ {
+			host, ok := dps.At(i).Attributes().Get("host")
+			if !ok {
+				hosts = append(hosts, "<unset>")
+				continue
+			}
+			hosts = append(hosts, host.Str())
+		}
+		return hosts
+	}
+	assert.Equal(t, []string{"web-1", "unknown", "web-1", "<unset>"}, gotHosts(metrics.At(0).Gauge().DataPoints()))
+	assert.Equal(t, []string{"web-2"}, gotHosts(metrics.At(1).Sum().DataPoints()))
+}
+
+func TestProcessorBatchLookupError(t *testing.T) {
+	var batches [][]string
+	factory := NewFactoryWithOptions(WithSources(mockBatchSourceFactory(nil, &batches, errors.New("backend down"))))
+	cfg := &Config{
+		Source: SourceConfig{Type: "mockbatch"},
+		Lookups: []LookupConfig{
+			{
+				Key:        `log.attributes["k"]`,
+				Attributes: []AttributeMapping{{Destination: "out", Default: "default"}},
+			},
+		},
+	}
+
+	sink := &consumertest.LogsSink{}
+	proc, err := factory.CreateLogs(t.Context(), processortest.NewNopSettings(metadata.Type), cfg, sink)
+	require.NoError(t, err)
+	require.NoError(t, proc.Start(t.Context(), componenttest.NewNopHost()))
+	defer func() { _ = proc.Shutdown(t.Context()) }()
+
+	logs := plog.NewLogs()
+	logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty().Attributes().PutStr("k", "anything")
+	require.NoError(t, proc.ConsumeLogs(t.Context(), logs))
+
+	records := sink.AllLogs()[0].ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	_, ok := records.At(0).Attributes().Get("out")
+	assert.False(t, ok, "out should not be set when the batch lookup fails")
+}
+
+func TestProcessorChainedLookups(t *testing.T) {
+	mappings := map[string]any{
+		"user-1": "team-a",
+		"team-a": "payments",
+	}
+
+	factory := NewFactoryWithOptions(WithSources(mockMapSourceFactory(mappings)))
+	cfg := &Config{
+		Source: SourceConfig{Type: "mockmap"},
+		Lookups: []LookupConfig{
+			{
+				Key:        `log.attributes["user"]`,
+				Attributes: []AttributeMapping{{Destination: "team"}},
+			},
+			{
+				Key:        `log.attributes["team"]`,
+				Attributes: []AttributeMapping{{Destination: "department"}},
+			},
+		},
+	}
+
+	sink := &consumertest.LogsSink{}
+	proc, err := factory.CreateLogs(t.Context(), processortest.NewNopSettings(metadata.Type), cfg, sink)
+	require.NoError(t, err)
+	require.NoError(t, proc.Start(t.Context(), componenttest.NewNopHost()))
+	defer func() { _ = proc.Shutdown(t.Context()) }()
+
+	logs := plog.NewLogs()
+	logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty().Attributes().PutStr("user", "user-1")
+	require.NoError(t, proc.ConsumeLogs(t.Context(), logs))
+
+	attrs := sink.AllLogs()[0].ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Attributes()
+	department, ok := attrs.Get("department")
+	assert.True(t, ok, "a lookup key can use the result of a previous lookup")
+	assert.Equal(t, "payments", department.Str())
+}
+
 // mockSourceFactory creates a source factory that always returns the given value.
 func mockSourceFactory(value string) lookupsource.SourceFactory {
 	return lookupsource.NewSourceFactory(
@@ -0,0 +1,202 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package cache // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/tailsamplingprocessor/cache"
+
+import (
+	"context"
+	"encoding/binary"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/extension/xextension/storage"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+const (
+	storageValueHeaderLen = 8
+	// maxPublishBatch bounds the number of decisions written to the storage
+	// client in a single batch operation.
+	maxPublishBatch = 128
+)
+
+// StorageTelemetry receives measurements for the operations a storage-backed
+// decision cache performs against its storage client.
+type StorageTelemetry interface {
+	// RecordLookups is called after every batch of lookups that reached the storage client.
+	RecordLookups(hits, misses int, latency time.Duration, err error)
+	// RecordPublish is called after every batch of decisions published to the storage client.
+	RecordPublish(latency time.Duration, err error)
+	// RecordPublishDropped is called for every decision not published because the queue was full.
+	RecordPublishDropped()
+}
+
+type publishedDecision struct {
+	id       pcommon.TraceID
+	metadata DecisionMetadata
+}
+
+// StorageDecisionCache is a Cache that shares decisions through a storage
+// client, so that processors backed by the same storage see each other's
+// decisions.
+//
+// Get and Put never reach the storage client from the caller's goroutine:
+// decisions of other processors are only found after Prefetch looked them up,
+// and decisions are published asynchronously.
+type StorageDecisionCache struct {
+	local     Cache
+	client    storage.Client
+	ttl       time.Duration
+	timeout   time.Duration
+	telemetry StorageTelemetry
+	now       func() time.Time
+
+	queue chan publishedDecision
+	done  chan struct{}
+	once  sync.Once
+}
+
+var _ Cache = (*StorageDecisionCache)(nil)
+
+// NewStorageDecisionCache returns a StorageDecisionCache serving lookups from
+// local, which must be able to hold decisions, e.g. an LRU cache.
+//
+// Decisions are published to the storage client by a background goroutine
+// through a queue of queueSize decisions. Decisions put while the queue is
+// full are only cached locally. Shutdown must be called to stop publishing.
+//
+// Decisions older than ttl are treated as missing. The storage client is not
+// asked to remove them, so it should expire entries on its own to bound its
+// size. Every storage operation is bounded by timeout, and a failed operation
+// is treated as a miss.
+func NewStorageDecisionCache(local Cache, client storage.Client, ttl, timeout time.Duration, queueSize int, telemetry StorageTelemetry) *StorageDecisionCache {
+	c := &StorageDecisionCache{
+		local:     local,
+		client:    client,
+		ttl:       ttl,
+		timeout:   timeout,
+		telemetry: telemetry,
+		now:       time.Now,
+		queue:     make(chan publishedDecision, queueSize),
+		done:      make(chan struct{}),
+	}
+	go c.publish()
+	return c
+}
+
+// Get returns the decision for id from the local cache.
+func (c *StorageDecisionCache) Get(id pcommon.TraceID) (DecisionMetadata, bool) {
+	return c.local.Get(id)
+}
+
+// Put caches the decision locally and queues it to be published. Decisions
+// already cached locally were either published before or read from the
+// storage client, so they are not published again.
+func (c *StorageDecisionCache) Put(id pcommon.TraceID, metadata DecisionMetadata) {
+	if _, ok := c.local.Get(id); ok {
+		c.local.Put(id, metadata)
+		return
+	}
+	c.local.Put(id, metadata)
+
+	select {
+	case c.queue <- publishedDecision{id: id, metadata: metadata}:
+	default:
+		c.telemetry.RecordPublishDropped()
+	}
+}
+
+// Prefetch looks up the decisions for the ids missing from the local cache
+// with a single storage operation, and adds the ones found to the local cache.
+func (c *StorageDecisionCache) Prefetch(ctx context.Context, ids []pcommon.TraceID) {
+	ops := make([]*storage.Operation, 0, len(ids))
+	missing := make([]pcommon.TraceID, 0, len(ids))
+	for _, id := range ids {
+		if _, ok := c.local.Get(id); ok {
+			continue
+		}
+		ops = append(ops, storage.GetOperation(id.String()))
+		missing = append(missing, id)
+	}
+	if len(ops) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := c.now()
+	err := c.client.Batch(ctx, ops...)
+	latency := c.now().Sub(start)
+	if err != nil {
+		c.telemetry.RecordLookups(0, len(ops), latency, err)
+		return
+	}
+
+	var hits int
+	for i, op := range ops {
+		if metadata, ok := c.decode(op.Value, start); ok {
+			c.local.Put(missing[i], metadata)
+			hits++
+		}
+	}
+	c.telemetry.RecordLookups(hits, len(ops)-hits, latency, nil)
+}
+
+// Shutdown publishes the queued decisions and stops publishing. Decisions
+// still queued when ctx is done are dropped.
+func (c *StorageDecisionCache) Shutdown(ctx context.Context) error {
+	c.once.Do(func() { close(c.queue) })
+	select {
+	case <-c.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// publish writes the queued decisions to the storage client, batching the
+// decisions queued while the previous batch was written.
+func (c *StorageDecisionCache) publish() {
+	defer close(c.done)
+	ops := make([]*storage.Operation, 0, maxPublishBatch)
+	for decision := range c.queue {
+		start := c.now()
+		ops = append(ops[:0], storage.SetOperation(decision.id.String(), c.encode(decision.metadata, start)))
+	drain:
+		for len(ops) < maxPublishBatch {
+			select {
+			case decision, ok := <-c.queue:
+				if !ok {
+					break drain
+				}
+				ops = append(ops, storage.SetOperation(decision.id.String(), c.encode(decision.metadata, start)))
+			default:
+				break drain
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+		err := c.client.Batch(ctx, ops...)
+		cancel()
+		c.telemetry.RecordPublish(c.now().Sub(start), err)
+	}
+}
+
+// encode stores the decision as the time it expires followed by the policy name.
+func (c *StorageDecisionCache) encode(metadata DecisionMetadata, now time.Time) []byte {
+	val := make([]byte, storageValueHeaderLen, storageValueHeaderLen+len(metadata.PolicyName))
+	binary.BigEndian.PutUint64(val, uint64(now.Add(c.ttl).UnixNano()))
+	return append(val, metadata.PolicyName...)
+}
+
+func (*StorageDecisionCache) decode(val []byte, now time.Time) (DecisionMetadata, bool) {
+	if len(val) < storageValueHeaderLen {
+		return DecisionMetadata{}, false
+	}
+	expiresAt := time.Unix(0, int64(binary.BigEndian.Uint64(val)))
+	if !now.Before(expiresAt) {
+		return DecisionMetadata{}, false
+	}
+	return DecisionMetadata{PolicyName: string(val[storageValueHeaderLen:])}, true
+}
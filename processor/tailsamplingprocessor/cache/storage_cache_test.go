@@ -0,0 +1,277 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/extension/xextension/storage"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+func TestStorageCacheSharesDecisions(t *testing.T) {
+	client := newMapClient()
+	writer := newTestStorageCache(t, client, &recordingTelemetry{}, 10)
+	reader := newTestStorageCache(t, client, &recordingTelemetry{}, 10)
+
+	id, err := traceIDFromHex("12341234123412341234123412341234")
+	require.NoError(t, err)
+	writer.Put(id, DecisionMetadata{PolicyName: "mock-policy"})
+	require.NoError(t, writer.Shutdown(t.Context()))
+
+	_, ok := reader.Get(id)
+	assert.False(t, ok, "lookups must not reach the storage client")
+
+	reader.Prefetch(t.Context(), []pcommon.TraceID{id})
+	v, ok := reader.Get(id)
+	assert.True(t, ok)
+	assert.Equal(t, DecisionMetadata{PolicyName: "mock-policy"}, v)
+}
+
+func TestStorageCachePrefetchBatchesLookups(t *testing.T) {
+	client := newMapClient()
+	telemetry := &recordingTelemetry{}
+	c := newTestStorageCache(t, client, telemetry, 10)
+
+	local, err := traceIDFromHex("12341234123412341234123412341234")
+	require.NoError(t, err)
+	shared, err := traceIDFromHex("12341234123412341234123412341235")
+	require.NoError(t, err)
+	unknown, err := traceIDFromHex("12341234123412341234123412341236")
+	require.NoError(t, err)
+
+	c.local.Put(local, DecisionMetadata{})
+	publisher := newTestStorageCache(t, client, &recordingTelemetry{}, 10)
+	publisher.Put(shared, DecisionMetadata{PolicyName: "mock-policy"})
+	require.NoError(t, publisher.Shutdown(t.Context()))
+
+	client.resetCounts()
+	c.Prefetch(t.Context(), []pcommon.TraceID{local, shared, unknown})
+	assert.Equal(t, 1, client.batches, "lookups must be sent in a single batch")
+	assert.Equal(t, 2, client.gets, "only ids missing from the local cache must be looked up")
+	assert.Equal(t, []lookups{{hits: 1, misses: 1}}, telemetry.getLookups())
+
+	_, ok := c.Get(shared)
+	assert.True(t, ok)
+	_, ok = c.Get(unknown)
+	assert.False(t, ok)
+}
+
+func TestStorageCacheDoesNotRepublishSharedDecisions(t *testing.T) {
+	client := newMapClient()
+	c := newTestStorageCache(t, client, &recordingTelemetry{}, 10)
+
+	id, err := traceIDFromHex("12341234123412341234123412341234")
+	require.NoError(t, err)
+	publisher := newTestStorageCache(t, client, &recordingTelemetry{}, 10)
+	publisher.Put(id, DecisionMetadata{PolicyName: "mock-policy"})
+	require.NoError(t, publisher.Shutdown(t.Context()))
+
+	client.resetCounts()
+	c.Prefetch(t.Context(), []pcommon.TraceID{id})
+	c.Put(id, DecisionMetadata{PolicyName: "mock-policy"})
+	require.NoError(t, c.Shutdown(t.Context()))
+	assert.Zero(t, client.sets)
+}
+
+func TestStorageCacheExpiresDecisions(t *testing.T) {
+	client := newMapClient()
+	writer := newTestStorageCache(t, client, &recordingTelemetry{}, 10)
+	reader := newTestStorageCache(t, client, &recordingTelemetry{}, 10)
+
+	now := time.Now()
+	writer.now = func() time.Time { return now }
+	reader.now = func() time.Time { return now.Add(time.Minute) }
+
+	id, err := traceIDFromHex("12341234123412341234123412341234")
+	require.NoError(t, err)
+	writer.Put(id, DecisionMetadata{PolicyName: "mock-policy"})
+	require.NoError(t, writer.Shutdown(t.Context()))
+
+	reader.Prefetch(t.Context(), []pcommon.TraceID{id})
+	_, ok := reader.Get(id)
+	assert.False(t, ok)
+}
+
+func TestStorageCacheTreatsErrorsAsMisses(t *testing.T) {
+	client := newMapClient()
+	client.err = errors.New("unavailable")
+	telemetry := &recordingTelemetry{}
+	c := newTestStorageCache(t, client, telemetry, 10)
+
+	id, err := traceIDFromHex("12341234123412341234123412341234")
+	require.NoError(t, err)
+	c.Put(id, DecisionMetadata{PolicyName: "mock-policy"})
+	require.NoError(t, c.Shutdown(t.Context()))
+	_, ok := c.Get(id)
+	assert.True(t, ok, "decision must still be cached locally")
+
+	other, err := traceIDFromHex("12341234123412341234123412341235")
+	require.NoError(t, err)
+	c.Prefetch(t.Context(), []pcommon.TraceID{other})
+	_, ok = c.Get(other)
+	assert.False(t, ok)
+	assert.Equal(t, []error{client.err}, telemetry.publishErrs)
+	assert.Equal(t, []lookups{{misses: 1, err: client.err}}, telemetry.getLookups())
+}
+
+func TestStorageCacheDropsDecisionsWhenQueueIsFull(t *testing.T) {
+	client := newMapClient()
+	client.block = make(chan struct{})
+	client.blocked = make(chan struct{}, 1)
+	telemetry := &recordingTelemetry{}
+	c := newTestStorageCache(t, client, telemetry, 1)
+
+	ids := make([]pcommon.TraceID, 3)
+	for i := range ids {
+		ids[i] = pcommon.TraceID([16]byte{15: byte(i + 1)})
+	}
+
+	// The first decision is being published, the second one waits in the
+	// queue, and the third one does not fit.
+	c.Put(ids[0], DecisionMetadata{})
+	<-client.blocked
+	c.Put(ids[1], DecisionMetadata{})
+	c.Put(ids[2], DecisionMetadata{})
+	assert.Equal(t, 1, telemetry.droppedPublishes())
+
+	close(client.block)
+	require.NoError(t, c.Shutdown(t.Context()))
+	assert.Equal(t, 2, client.sets)
+	for _, id := range ids {
+		_, ok := c.Get(id)
+		assert.True(t, ok, "dropped decisions must still be cached locally")
+	}
+}
+
+func newTestStorageCache(t *testing.T, client storage.Client, telemetry StorageTelemetry, queueSize int) *StorageDecisionCache {
+	local, err := NewLRUDecisionCache(10)
+	require.NoError(t, err)
+	c := NewStorageDecisionCache(local, client, 30*time.Second, time.Second, queueSize, telemetry)
+	t.Cleanup(func() {
+		require.NoError(t, c.Shutdown(context.Background()))
+	})
+	return c
+}
+
+type lookups struct {
+	hits, misses int
+	err          error
+}
+
+type recordingTelemetry struct {
+	mu          sync.Mutex
+	lookups     []lookups
+	publishErrs []error
+	dropped     int
+}
+
+func (r *recordingTelemetry) RecordLookups(hits, misses int, _ time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lookups = append(r.lookups, lookups{hits: hits, misses: misses, err: err})
+}
+
+func (r *recordingTelemetry) RecordPublish(_ time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err != nil {
+		r.publishErrs = append(r.publishErrs, err)
+	}
+}
+
+func (r *recordingTelemetry) RecordPublishDropped() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.dropped++
+}
+
+func (r *recordingTelemetry) getLookups() []lookups {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.lookups
+}
+
+func (r *recordingTelemetry) droppedPublishes() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.dropped
+}
+
+// mapClient is a storage client counting the operations it performs. When
+// block is set, batches signal blocked, without waiting for the signal to be
+// received, and wait for block to be closed.
+type mapClient struct {
+	mu      sync.Mutex
+	data    map[string][]byte
+	err     error
+	block   chan struct{}
+	blocked chan struct{}
+
+	batches, gets, sets int
+}
+
+func newMapClient() *mapClient {
+	return &mapClient{data: map[string][]byte{}}
+}
+
+func (c *mapClient) resetCounts() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.batches, c.gets, c.sets = 0, 0, 0
+}
+
+func (c *mapClient) Get(ctx context.Context, key string) ([]byte, error) {
+	op := storage.GetOperation(key)
+	err := c.Batch(ctx, op)
+	return op.Value, err
+}
+
+func (c *mapClient) Set(ctx context.Context, key string, value []byte) error {
+	return c.Batch(ctx, storage.SetOperation(key, value))
+}
+
+func (c *mapClient) Delete(ctx context.Context, key string) error {
+	return c.Batch(ctx, storage.DeleteOperation(key))
+}
+
+func (c *mapClient) Batch(_ context.Context, ops ...*storage.Operation) error {
+	if c.block != nil {
+		select {
+		case c.blocked <- struct{}{}:
+		default:
+		}
+		<-c.block
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.batches++
+	if c.err != nil {
+		return c.err
+	}
+	for _, op := range ops {
+		switch op.Type {
+		case storage.Get:
+			c.gets++
+			op.Value = c.data[op.Key]
+		case storage.Set:
+			c.sets++
+			c.data[op.Key] = op.Value
+		case storage.Delete:
+			delete(c.data, op.Key)
+		}
+	}
+	return nil
+}
+
+func (*mapClient) Close(context.Context) error {
+	return nil
+}
@@ -4,6 +4,7 @@
 package tailsamplingprocessor // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/tailsamplingprocessor"
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
@@ -55,6 +56,8 @@ const (
 	BytesLimiting PolicyType = "bytes_limiting"
 	// TraceFlags sample traces which have specific trace flags set.
 	TraceFlags PolicyType = "trace_flags"
+	// Extension samples traces using an evaluator created by a sampling policy extension.
+	Extension PolicyType = "extension"
 )
 
 const (
@@ -97,6 +100,8 @@ type sharedPolicyCfg struct {
 	BooleanAttributeCfg BooleanAttributeCfg `mapstructure:"boolean_attribute"`
 	// Configs for OTTL condition filter sampling policy evaluator
 	OTTLConditionCfg OTTLConditionCfg `mapstructure:"ottl_condition"`
+	// Configs for extension sampling policy evaluator.
+	ExtensionPolicyCfg ExtensionPolicyCfg `mapstructure:"extension"`
 	// Configs for any extensions that are used.
 	ExtensionCfg map[string]map[string]any `mapstructure:",remain"`
 }
@@ -306,6 +311,17 @@ type OTTLConditionCfg struct {
 	_ struct{}
 }
 
+// ExtensionPolicyCfg holds the configurable settings to create a sampling policy
+// evaluator using a sampling policy extension.
+type ExtensionPolicyCfg struct {
+	// ID of the extension creating the evaluator. The extension must implement samplingpolicy.Extension.
+	ID component.ID `mapstructure:"id"`
+	// Config is forwarded as is to the extension when creating the evaluator.
+	Config map[string]any `mapstructure:"config"`
+	// prevent unkeyed literal initialization
+	_ struct{}
+}
+
 type DecisionCacheConfig struct {
 	// SampledCacheSize specifies the size of the cache that holds the sampled trace IDs.
 	// This value will be the maximum amount of trace IDs that the cache can hold before overwriting previous IDs.
@@ -317,6 +333,18 @@ type DecisionCacheConfig struct {
 	// For effective use, this value should be at least an order of magnitude greater than Config.NumTraces.
 	// If left as default 0, a no-op DecisionCache will be used.
 	NonSampledCacheSize int `mapstructure:"non_sampled_cache_size"`
+	// StorageID specifies an optional storage extension used to share decisions with other
+	// tail sampling processors configured with the same storage extension and component ID.
+	// Decisions missing from the local caches are looked up in it, and every decision is published to it.
+	// Both local caches must be enabled when it is set.
+	StorageID *component.ID `mapstructure:"storage"`
+	// TTL is how long a decision published to the storage extension is honored by other processors.
+	TTL time.Duration `mapstructure:"ttl"`
+	// Timeout bounds each lookup and publish against the storage extension.
+	Timeout time.Duration `mapstructure:"timeout"`
+	// QueueSize is the number of decisions per cache waiting to be published to the storage extension.
+	// Decisions made while the queue is full are only cached locally.
+	QueueSize int `mapstructure:"queue_size"`
 	// prevent unkeyed literal initialization
 	_ struct{}
 }
@@ -380,6 +408,21 @@ func (cfg *Config) Validate() error {
 		)
 	}
 
+	if cfg.DecisionCache.StorageID != nil {
+		if cfg.DecisionCache.TTL <= 0 {
+			return errors.New("decision_cache::ttl must be positive when decision_cache::storage is set")
+		}
+		if cfg.DecisionCache.Timeout <= 0 {
+			return errors.New("decision_cache::timeout must be positive when decision_cache::storage is set")
+		}
+		if cfg.DecisionCache.QueueSize <= 0 {
+			return errors.New("decision_cache::queue_size must be positive when decision_cache::storage is set")
+		}
+		if cfg.DecisionCache.SampledCacheSize <= 0 || cfg.DecisionCache.NonSampledCacheSize <= 0 {
+			return errors.New("decision_cache::sampled_cache_size and decision_cache::non_sampled_cache_size must be positive when decision_cache::storage is set")
+		}
+	}
+
 	if cfg.TailStorageID != nil && !tailstorageextension.IsFeatureGateEnabled() {
 		return fmt.Errorf(
 			"'tail_storage' requires the %q feature gate to be enabled, use --feature-gates=+%s",
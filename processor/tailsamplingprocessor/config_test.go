@@ -38,7 +38,13 @@ func TestLoadConfig(t *testing.T) {
 			NumTraces:               100,
 			ExpectedNewTracesPerSec: 10,
 			SamplingStrategy:        samplingStrategyTraceComplete,
-			DecisionCache:           DecisionCacheConfig{SampledCacheSize: 1_000, NonSampledCacheSize: 10_000},
+			DecisionCache: DecisionCacheConfig{
+				SampledCacheSize:    1_000,
+				NonSampledCacheSize: 10_000,
+				TTL:                 5 * time.Minute,
+				Timeout:             100 * time.Millisecond,
+				QueueSize:           10000,
+			},
 			PolicyCfgs: []PolicyCfg{
 				{
 					sharedPolicyCfg: sharedPolicyCfg{
@@ -227,6 +233,31 @@ func TestLoadConfig(t *testing.T) {
 		}, cfg)
 }
 
+func TestLoadExtensionPolicyConfig(t *testing.T) {
+	t.Parallel()
+
+	cm, err := confmaptest.LoadConf(filepath.Join("testdata", "tail_sampling_config.yaml"))
+	require.NoError(t, err)
+
+	cfg := NewFactory().CreateDefaultConfig()
+	sub, err := cm.Sub(component.NewIDWithName(metadata.Type, "extension").String())
+	require.NoError(t, err)
+	require.NoError(t, sub.Unmarshal(cfg))
+
+	assert.Equal(t, []PolicyCfg{
+		{
+			sharedPolicyCfg: sharedPolicyCfg{
+				Name: "extension-policy-1",
+				Type: Extension,
+				ExtensionPolicyCfg: ExtensionPolicyCfg{
+					ID:     component.MustNewIDWithName("tenant_sampling_policy", "prod"),
+					Config: map[string]any{"default_percentage": 10},
+				},
+			},
+		},
+	}, cfg.(*Config).PolicyCfgs)
+}
+
 func TestConfigValidateTailStorageFeatureGate(t *testing.T) {
 	tailStorageID := component.MustNewID("tail_storage_pebble")
 
@@ -282,3 +313,58 @@ func TestConfigValidateTailStorageFeatureGate(t *testing.T) {
 		})
 	}
 }
+
+func TestConfigValidateDecisionCacheStorage(t *testing.T) {
+	storageID := component.MustNewID("redis_storage")
+
+	testCases := []struct {
+		name          string
+		decisionCache DecisionCacheConfig
+		wantErr       string
+	}{
+		{
+			name:          "storage set with ttl, timeout, queue size and caches is valid",
+			decisionCache: DecisionCacheConfig{StorageID: &storageID, SampledCacheSize: 10, NonSampledCacheSize: 10, TTL: time.Minute, Timeout: time.Second, QueueSize: 10},
+		},
+		{
+			name:          "storage set without ttl returns error",
+			decisionCache: DecisionCacheConfig{StorageID: &storageID, SampledCacheSize: 10, NonSampledCacheSize: 10, Timeout: time.Second, QueueSize: 10},
+			wantErr:       "decision_cache::ttl must be positive when decision_cache::storage is set",
+		},
+		{
+			name:          "storage set without timeout returns error",
+			decisionCache: DecisionCacheConfig{StorageID: &storageID, SampledCacheSize: 10, NonSampledCacheSize: 10, TTL: time.Minute, QueueSize: 10},
+			wantErr:       "decision_cache::timeout must be positive when decision_cache::storage is set",
+		},
+		{
+			name:          "storage set without queue size returns error",
+			decisionCache: DecisionCacheConfig{StorageID: &storageID, SampledCacheSize: 10, NonSampledCacheSize: 10, TTL: time.Minute, Timeout: time.Second},
+			wantErr:       "decision_cache::queue_size must be positive when decision_cache::storage is set",
+		},
+		{
+			name:          "storage set without local caches returns error",
+			decisionCache: DecisionCacheConfig{StorageID: &storageID, NonSampledCacheSize: 10, TTL: time.Minute, Timeout: time.Second, QueueSize: 10},
+			wantErr:       "decision_cache::sampled_cache_size and decision_cache::non_sampled_cache_size must be positive when decision_cache::storage is set",
+		},
+		{
+			name:          "storage not set ignores the storage options",
+			decisionCache: DecisionCacheConfig{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &Config{
+				SamplingStrategy: samplingStrategyTraceComplete,
+				DecisionCache:    tc.decisionCache,
+			}
+
+			err := cfg.Validate()
+			if tc.wantErr != "" {
+				require.EqualError(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,150 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package tailsamplingprocessor // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/tailsamplingprocessor"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/extension/xextension/storage"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/tailsamplingprocessor/cache"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/tailsamplingprocessor/internal/metadata"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/tailsamplingprocessor/pkg/samplingpolicy"
+)
+
+// shareDecisionCaches wraps the decision caches so that decisions are looked
+// up in and published to the storage extension identified by storageID.
+func (tsp *tailSamplingSpanProcessor) shareDecisionCaches(ctx context.Context, host component.Host, storageID component.ID) error {
+	if host == nil {
+		return fmt.Errorf("decision cache storage extension '%s' configured but host is nil", storageID)
+	}
+	ext, ok := host.GetExtensions()[storageID]
+	if !ok {
+		return fmt.Errorf("decision cache storage extension '%s' not found", storageID)
+	}
+	storageExt, ok := ext.(storage.Extension)
+	if !ok {
+		return fmt.Errorf("non-storage extension '%s' found", storageID)
+	}
+
+	sampledClient, err := storageExt.GetClient(ctx, component.KindProcessor, tsp.set.ID, "sampled_decisions")
+	if err != nil {
+		return fmt.Errorf("failed to get sampled decisions storage client: %w", err)
+	}
+	tsp.storageClients = append(tsp.storageClients, sampledClient)
+	nonSampledClient, err := storageExt.GetClient(ctx, component.KindProcessor, tsp.set.ID, "non_sampled_decisions")
+	if err != nil {
+		return fmt.Errorf("failed to get non-sampled decisions storage client: %w", err)
+	}
+	tsp.storageClients = append(tsp.storageClients, nonSampledClient)
+
+	dc := tsp.cfg.DecisionCache
+	sampled := cache.NewStorageDecisionCache(tsp.sampledIDCache, sampledClient, dc.TTL, dc.Timeout, dc.QueueSize,
+		newDecisionCacheTelemetry(tsp.ctx, tsp.telemetry, true))
+	nonSampled := cache.NewStorageDecisionCache(tsp.nonSampledIDCache, nonSampledClient, dc.TTL, dc.Timeout, dc.QueueSize,
+		newDecisionCacheTelemetry(tsp.ctx, tsp.telemetry, false))
+	tsp.sharedCaches = []*cache.StorageDecisionCache{sampled, nonSampled}
+	tsp.sampledIDCache = sampled
+	tsp.nonSampledIDCache = nonSampled
+	return nil
+}
+
+// prefetchSharedDecisions looks up the decisions other processors published
+// for ids, so that they are found in the decision caches. Each cache looks up
+// all ids with a single storage operation.
+func (tsp *tailSamplingSpanProcessor) prefetchSharedDecisions(ctx context.Context, ids []pcommon.TraceID) {
+	for _, c := range tsp.sharedCaches {
+		c.Prefetch(ctx, ids)
+	}
+}
+
+// shutdownSharedDecisionCaches publishes the decisions still queued and closes
+// the storage clients.
+func (tsp *tailSamplingSpanProcessor) shutdownSharedDecisionCaches(ctx context.Context) error {
+	var errs error
+	for _, c := range tsp.sharedCaches {
+		errs = errors.Join(errs, c.Shutdown(ctx))
+	}
+	for _, client := range tsp.storageClients {
+		errs = errors.Join(errs, client.Close(ctx))
+	}
+	return errs
+}
+
+// sharedDecision returns the decision for id if it is found in either
+// decision cache.
+func (tsp *tailSamplingSpanProcessor) sharedDecision(id pcommon.TraceID) (samplingpolicy.Decision, string, bool) {
+	if metadata, ok := tsp.sampledIDCache.Get(id); ok {
+		return samplingpolicy.Sampled, metadata.PolicyName, true
+	}
+	if metadata, ok := tsp.nonSampledIDCache.Get(id); ok {
+		return samplingpolicy.NotSampled, metadata.PolicyName, true
+	}
+	return samplingpolicy.Unspecified, "", false
+}
+
+// decisionCacheTelemetry records the storage operations of one of the
+// decision caches. Measurement options are built once since they are
+// recorded for every storage operation.
+type decisionCacheTelemetry struct {
+	ctx       context.Context
+	telemetry *metadata.TelemetryBuilder
+
+	sampled                metric.MeasurementOption
+	hit, miss, lookupError metric.MeasurementOption
+	get, put               metric.MeasurementOption
+}
+
+var _ cache.StorageTelemetry = (*decisionCacheTelemetry)(nil)
+
+func newDecisionCacheTelemetry(ctx context.Context, telemetry *metadata.TelemetryBuilder, sampled bool) *decisionCacheTelemetry {
+	sampledAttr := attribute.Bool("sampled", sampled)
+	return &decisionCacheTelemetry{
+		ctx:         ctx,
+		telemetry:   telemetry,
+		sampled:     metric.WithAttributes(sampledAttr),
+		hit:         metric.WithAttributes(sampledAttr, attribute.String("lookup_result", "hit")),
+		miss:        metric.WithAttributes(sampledAttr, attribute.String("lookup_result", "miss")),
+		lookupError: metric.WithAttributes(sampledAttr, attribute.String("lookup_result", "error")),
+		get:         metric.WithAttributes(sampledAttr, attribute.String("operation", "get")),
+		put:         metric.WithAttributes(sampledAttr, attribute.String("operation", "put")),
+	}
+}
+
+func (t *decisionCacheTelemetry) RecordLookups(hits, misses int, latency time.Duration, err error) {
+	if err != nil {
+		t.telemetry.ProcessorTailSamplingDecisionCacheRemoteErrors.Add(t.ctx, 1, t.get)
+		t.telemetry.ProcessorTailSamplingDecisionCacheRemoteLookups.Add(t.ctx, int64(hits+misses), t.lookupError)
+	} else {
+		if hits > 0 {
+			t.telemetry.ProcessorTailSamplingDecisionCacheRemoteLookups.Add(t.ctx, int64(hits), t.hit)
+		}
+		if misses > 0 {
+			t.telemetry.ProcessorTailSamplingDecisionCacheRemoteLookups.Add(t.ctx, int64(misses), t.miss)
+		}
+	}
+	t.telemetry.ProcessorTailSamplingDecisionCacheRemoteLatency.Record(t.ctx, milliseconds(latency), t.get)
+}
+
+func (t *decisionCacheTelemetry) RecordPublish(latency time.Duration, err error) {
+	if err != nil {
+		t.telemetry.ProcessorTailSamplingDecisionCacheRemoteErrors.Add(t.ctx, 1, t.put)
+	}
+	t.telemetry.ProcessorTailSamplingDecisionCacheRemoteLatency.Record(t.ctx, milliseconds(latency), t.put)
+}
+
+func (t *decisionCacheTelemetry) RecordPublishDropped() {
+	t.telemetry.ProcessorTailSamplingDecisionCacheRemoteDroppedPublishes.Add(t.ctx, 1, t.sampled)
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
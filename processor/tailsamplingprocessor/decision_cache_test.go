@@ -0,0 +1,262 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package tailsamplingprocessor
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/extension/xextension/storage"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/processor"
+	"go.opentelemetry.io/collector/processor/processortest"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata/metricdatatest"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/tailsamplingprocessor/internal/metadata"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/tailsamplingprocessor/internal/metadatatest"
+)
+
+var testStorageID = component.MustNewID("shared_storage")
+
+// neverSamplePolicy samples only traces with an attribute no test trace has.
+var neverSamplePolicy = []PolicyCfg{
+	{
+		sharedPolicyCfg: sharedPolicyCfg{
+			Name: "never",
+			Type: StringAttribute,
+			StringAttributeCfg: StringAttributeCfg{
+				Key:    "missing",
+				Values: []string{"value"},
+			},
+		},
+	},
+}
+
+func TestSharedDecisionCacheReleasesLateSpans(t *testing.T) {
+	host := &sharedStorageHost{ext: &sharedStorageExtension{}}
+	firstController := newTestTSPController()
+	first, firstSink := newSharedDecisionCacheProcessor(t, host, firstController, testPolicy, processortest.NewNopSettings(metadata.Type))
+
+	tel := componenttest.NewTelemetry()
+	t.Cleanup(func() { require.NoError(t, tel.Shutdown(context.Background())) })
+	secondController := newTestTSPController()
+	second, secondSink := newSharedDecisionCacheProcessor(t, host, secondController, neverSamplePolicy, metadatatest.NewSettings(tel))
+
+	traceID := pcommon.TraceID([16]byte{1, 2, 3})
+	require.NoError(t, first.ConsumeTraces(t.Context(), simpleTracesWithID(traceID)))
+	firstController.waitForTick()
+	firstController.waitForTick()
+	require.Len(t, firstSink.AllTraces(), 1)
+	host.ext.(*sharedStorageExtension).waitForDecision(t, "sampled_decisions", traceID)
+
+	// The second processor has never seen the trace, but finds the decision
+	// made by the first one and releases the late span right away.
+	require.NoError(t, second.ConsumeTraces(t.Context(), simpleTracesWithID(traceID)))
+	secondController.waitForTick()
+	require.Len(t, secondSink.AllTraces(), 1)
+	assert.NotContains(t, second.(*tailSamplingSpanProcessor).idToTrace, traceID)
+
+	metadatatest.AssertEqualProcessorTailSamplingDecisionCacheRemoteLookups(t, tel, []metricdata.DataPoint[int64]{
+		{
+			Value: 1,
+			Attributes: attribute.NewSet(
+				attribute.Bool("sampled", true),
+				attribute.String("lookup_result", "hit"),
+			),
+		},
+		{
+			Value: 1,
+			Attributes: attribute.NewSet(
+				attribute.Bool("sampled", false),
+				attribute.String("lookup_result", "miss"),
+			),
+		},
+	}, metricdatatest.IgnoreTimestamp())
+}
+
+func TestSharedDecisionCacheAdoptsPeerDecision(t *testing.T) {
+	host := &sharedStorageHost{ext: &sharedStorageExtension{}}
+	firstController := newTestTSPController()
+	first, firstSink := newSharedDecisionCacheProcessor(t, host, firstController, testPolicy, processortest.NewNopSettings(metadata.Type))
+	secondController := newTestTSPController()
+	second, secondSink := newSharedDecisionCacheProcessor(t, host, secondController, neverSamplePolicy, processortest.NewNopSettings(metadata.Type))
+
+	// Both processors receive part of the trace before either decides.
+	traceID := pcommon.TraceID([16]byte{4, 5, 6})
+	require.NoError(t, second.ConsumeTraces(t.Context(), simpleTracesWithID(traceID)))
+	require.NoError(t, first.ConsumeTraces(t.Context(), simpleTracesWithID(traceID)))
+
+	firstController.waitForTick()
+	firstController.waitForTick()
+	require.Len(t, firstSink.AllTraces(), 1)
+	host.ext.(*sharedStorageExtension).waitForDecision(t, "sampled_decisions", traceID)
+
+	// The second processor would not sample the trace on its own, but honors
+	// the decision the first one already published.
+	secondController.waitForTick()
+	secondController.waitForTick()
+	require.Len(t, secondSink.AllTraces(), 1)
+	assert.NotContains(t, second.(*tailSamplingSpanProcessor).idToTrace, traceID)
+}
+
+func TestSharedDecisionCacheStorageNotFound(t *testing.T) {
+	cfg := Config{
+		DecisionWait:     defaultTestDecisionWait,
+		NumTraces:        defaultNumTraces,
+		SamplingStrategy: samplingStrategyTraceComplete,
+		PolicyCfgs:       testPolicy,
+		DecisionCache: DecisionCacheConfig{
+			SampledCacheSize:    10,
+			NonSampledCacheSize: 10,
+			StorageID:           &testStorageID,
+			TTL:                 time.Minute,
+			Timeout:             time.Second,
+			QueueSize:           10,
+		},
+	}
+	p, err := newTracesProcessor(t.Context(), processortest.NewNopSettings(metadata.Type), consumertest.NewNop(), cfg)
+	require.NoError(t, err)
+
+	err = p.Start(t.Context(), componenttest.NewNopHost())
+	require.EqualError(t, err, "decision cache storage extension 'shared_storage' not found")
+	require.NoError(t, p.Shutdown(t.Context()))
+
+	p, err = newTracesProcessor(t.Context(), processortest.NewNopSettings(metadata.Type), consumertest.NewNop(), cfg)
+	require.NoError(t, err)
+	err = p.Start(t.Context(), &sharedStorageHost{ext: &nonTailStorageExtension{}})
+	require.EqualError(t, err, "non-storage extension 'shared_storage' found")
+	require.NoError(t, p.Shutdown(t.Context()))
+}
+
+func newSharedDecisionCacheProcessor(t *testing.T, host component.Host, controller *testTSPController, policies []PolicyCfg, set processor.Settings) (processor.Traces, *consumertest.TracesSink) {
+	t.Helper()
+	sink := new(consumertest.TracesSink)
+	cfg := Config{
+		DecisionWait:     defaultTestDecisionWait,
+		NumTraces:        defaultNumTraces,
+		SamplingStrategy: samplingStrategyTraceComplete,
+		PolicyCfgs:       policies,
+		DecisionCache: DecisionCacheConfig{
+			SampledCacheSize:    10,
+			NonSampledCacheSize: 10,
+			StorageID:           &testStorageID,
+			TTL:                 time.Minute,
+			Timeout:             time.Second,
+			QueueSize:           10,
+		},
+		Options: []Option{
+			withTestController(controller),
+		},
+	}
+	p, err := newTracesProcessor(t.Context(), set, sink, cfg)
+	require.NoError(t, err)
+	require.NoError(t, p.Start(t.Context(), host))
+	t.Cleanup(func() {
+		require.NoError(t, p.Shutdown(context.Background()))
+	})
+	return p, sink
+}
+
+type sharedStorageHost struct {
+	ext component.Component
+}
+
+func (h *sharedStorageHost) GetExtensions() map[component.ID]component.Component {
+	return map[component.ID]component.Component{
+		testStorageID: h.ext,
+	}
+}
+
+// sharedStorageExtension hands out the same client for every component, the
+// way a remote storage such as redis does.
+type sharedStorageExtension struct {
+	component.StartFunc
+	component.ShutdownFunc
+
+	mu      sync.Mutex
+	clients map[string]*sharedStorageClient
+}
+
+var _ storage.Extension = (*sharedStorageExtension)(nil)
+
+func (e *sharedStorageExtension) GetClient(_ context.Context, _ component.Kind, _ component.ID, name string) (storage.Client, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.clients == nil {
+		e.clients = map[string]*sharedStorageClient{}
+	}
+	if _, ok := e.clients[name]; !ok {
+		e.clients[name] = &sharedStorageClient{data: map[string][]byte{}}
+	}
+	return e.clients[name], nil
+}
+
+// waitForDecision waits for the decision on id to be published to the client
+// of the given name, since decisions are published asynchronously.
+func (e *sharedStorageExtension) waitForDecision(t *testing.T, name string, id pcommon.TraceID) {
+	t.Helper()
+	client, err := e.GetClient(t.Context(), component.KindProcessor, component.ID{}, name)
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		val, err := client.Get(t.Context(), id.String())
+		return err == nil && val != nil
+	}, time.Second, time.Millisecond)
+}
+
+type sharedStorageClient struct {
+	mu   sync.Mutex
+	data map[string][]byte
+}
+
+func (c *sharedStorageClient) Get(_ context.Context, key string) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.data[key], nil
+}
+
+func (c *sharedStorageClient) Set(_ context.Context, key string, value []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.data[key] = value
+	return nil
+}
+
+func (c *sharedStorageClient) Delete(_ context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.data, key)
+	return nil
+}
+
+func (c *sharedStorageClient) Batch(_ context.Context, ops ...*storage.Operation) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, op := range ops {
+		switch op.Type {
+		case storage.Get:
+			op.Value = c.data[op.Key]
+		case storage.Set:
+			c.data[op.Key] = op.Value
+		case storage.Delete:
+			delete(c.data, op.Key)
+		default:
+			return fmt.Errorf("unsupported operation type %d", op.Type)
+		}
+	}
+	return nil
+}
+
+func (*sharedStorageClient) Close(context.Context) error {
+	return nil
+}
@@ -32,6 +32,11 @@ func createDefaultConfig() component.Config {
 		NumTraces:          50000,
 		SampleOnFirstMatch: false,
 		SamplingStrategy:   samplingStrategyTraceComplete,
+		DecisionCache: DecisionCacheConfig{
+			TTL:       5 * time.Minute,
+			Timeout:   100 * time.Millisecond,
+			QueueSize: 10000,
+		},
 	}
 }
 
@@ -29,6 +29,10 @@ type TelemetryBuilder struct {
 	ProcessorTailSamplingCountSpansSampled                   metric.Int64Counter
 	ProcessorTailSamplingCountSpansWithUnparseableTracestate metric.Int64Counter
 	ProcessorTailSamplingCountTracesSampled                  metric.Int64Counter
+	ProcessorTailSamplingDecisionCacheRemoteDroppedPublishes metric.Int64Counter
+	ProcessorTailSamplingDecisionCacheRemoteErrors           metric.Int64Counter
+	ProcessorTailSamplingDecisionCacheRemoteLatency          metric.Float64Histogram
+	ProcessorTailSamplingDecisionCacheRemoteLookups          metric.Int64Counter
 	ProcessorTailSamplingEarlyReleasesFromCacheDecision      metric.Int64Counter
 	ProcessorTailSamplingGlobalCountTracesSampled            metric.Int64Counter
 	ProcessorTailSamplingNewTraceIDReceived                  metric.Int64Counter
@@ -96,6 +100,31 @@ func NewTelemetryBuilder(settings component.TelemetrySettings, options ...Teleme
 		metric.WithUnit("{traces}"),
 	)
 	errs = errors.Join(errs, err)
+	builder.ProcessorTailSamplingDecisionCacheRemoteDroppedPublishes, err = builder.meter.Int64Counter(
+		"otelcol_processor_tail_sampling_decision_cache_remote_dropped_publishes",
+		metric.WithDescription("Count of decisions not published to the storage extension because the publish queue was full [Development]"),
+		metric.WithUnit("{decisions}"),
+	)
+	errs = errors.Join(errs, err)
+	builder.ProcessorTailSamplingDecisionCacheRemoteErrors, err = builder.meter.Int64Counter(
+		"otelcol_processor_tail_sampling_decision_cache_remote_errors",
+		metric.WithDescription("Count of failed decision cache operations against the storage extension [Development]"),
+		metric.WithUnit("{errors}"),
+	)
+	errs = errors.Join(errs, err)
+	builder.ProcessorTailSamplingDecisionCacheRemoteLatency, err = builder.meter.Float64Histogram(
+		"otelcol_processor_tail_sampling_decision_cache_remote_latency",
+		metric.WithDescription("Latency (in milliseconds) of batched decision cache operations against the storage extension [Development]"),
+		metric.WithUnit("ms"),
+		metric.WithExplicitBucketBoundaries([]float64{0.1, 0.25, 0.5, 1, 2, 5, 10, 25, 50, 100, 250, 500, 1000}...),
+	)
+	errs = errors.Join(errs, err)
+	builder.ProcessorTailSamplingDecisionCacheRemoteLookups, err = builder.meter.Int64Counter(
+		"otelcol_processor_tail_sampling_decision_cache_remote_lookups",
+		metric.WithDescription("Count of decision cache lookups that missed the local cache and reached the storage extension [Development]"),
+		metric.WithUnit("{lookups}"),
+	)
+	errs = errors.Join(errs, err)
 	builder.ProcessorTailSamplingEarlyReleasesFromCacheDecision, err = builder.meter.Int64Counter(
 		"otelcol_processor_tail_sampling_early_releases_from_cache_decision",
 		metric.WithDescription("Number of spans that were able to be immediately released due to a decision cache hit. [Development]"),
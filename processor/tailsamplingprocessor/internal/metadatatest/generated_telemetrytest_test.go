@@ -23,6 +23,10 @@ func TestSetupTelemetry(t *testing.T) {
 	tb.ProcessorTailSamplingCountSpansSampled.Add(context.Background(), 1)
 	tb.ProcessorTailSamplingCountSpansWithUnparseableTracestate.Add(context.Background(), 1)
 	tb.ProcessorTailSamplingCountTracesSampled.Add(context.Background(), 1)
+	tb.ProcessorTailSamplingDecisionCacheRemoteDroppedPublishes.Add(context.Background(), 1)
+	tb.ProcessorTailSamplingDecisionCacheRemoteErrors.Add(context.Background(), 1)
+	tb.ProcessorTailSamplingDecisionCacheRemoteLatency.Record(context.Background(), 1)
+	tb.ProcessorTailSamplingDecisionCacheRemoteLookups.Add(context.Background(), 1)
 	tb.ProcessorTailSamplingEarlyReleasesFromCacheDecision.Add(context.Background(), 1)
 	tb.ProcessorTailSamplingGlobalCountTracesSampled.Add(context.Background(), 1)
 	tb.ProcessorTailSamplingNewTraceIDReceived.Add(context.Background(), 1)
@@ -47,6 +51,18 @@ func TestSetupTelemetry(t *testing.T) {
 	AssertEqualProcessorTailSamplingCountTracesSampled(t, testTel,
 		[]metricdata.DataPoint[int64]{{Value: 1}},
 		metricdatatest.IgnoreTimestamp())
+	AssertEqualProcessorTailSamplingDecisionCacheRemoteDroppedPublishes(t, testTel,
+		[]metricdata.DataPoint[int64]{{Value: 1}},
+		metricdatatest.IgnoreTimestamp())
+	AssertEqualProcessorTailSamplingDecisionCacheRemoteErrors(t, testTel,
+		[]metricdata.DataPoint[int64]{{Value: 1}},
+		metricdatatest.IgnoreTimestamp())
+	AssertEqualProcessorTailSamplingDecisionCacheRemoteLatency(t, testTel,
+		[]metricdata.HistogramDataPoint[float64]{{}}, metricdatatest.IgnoreValue(),
+		metricdatatest.IgnoreTimestamp())
+	AssertEqualProcessorTailSamplingDecisionCacheRemoteLookups(t, testTel,
+		[]metricdata.DataPoint[int64]{{Value: 1}},
+		metricdatatest.IgnoreTimestamp())
 	AssertEqualProcessorTailSamplingEarlyReleasesFromCacheDecision(t, testTel,
 		[]metricdata.DataPoint[int64]{{Value: 1}},
 		metricdatatest.IgnoreTimestamp())
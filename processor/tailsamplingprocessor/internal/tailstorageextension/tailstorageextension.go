@@ -4,6 +4,8 @@
 package tailstorageextension // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/tailsamplingprocessor/internal/tailstorageextension"
 
 import (
+	"time"
+
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/ptrace"
 )
@@ -35,3 +37,24 @@ type TailStorage interface {
 	// an empty ptrace.Traces until Append is called again for traceID.
 	Delete(traceID pcommon.TraceID) error
 }
+
+// PersistentTailStorage is an optional interface for TailStorage
+// implementations that keep pending traces and sampling decisions across
+// collector restarts. When the configured storage implements it, the
+// processor rebuilds its pending-trace index and decision caches on start
+// and leaves pending traces in storage on shutdown instead of deciding early.
+type PersistentTailStorage interface {
+	TailStorage
+
+	// RecoverPendingTraces calls fn once for every trace with stored spans,
+	// passing the time its first batch was appended and the span count and
+	// size in bytes of everything stored for it.
+	RecoverPendingTraces(fn func(traceID pcommon.TraceID, arrivalTime time.Time, spanCount int64, sizeBytes uint64)) error
+
+	// RecordDecision persists the final sampling decision for traceID so it
+	// can be restored into the decision cache by RecoverDecisions.
+	RecordDecision(traceID pcommon.TraceID, sampled bool, policyName string) error
+
+	// RecoverDecisions calls fn for every retained decision, oldest first.
+	RecoverDecisions(fn func(traceID pcommon.TraceID, sampled bool, policyName string)) error
+}
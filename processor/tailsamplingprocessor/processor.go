@@ -15,6 +15,7 @@ import (
 
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/extension/xextension/storage"
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/ptrace"
 	"go.opentelemetry.io/collector/processor"
@@ -76,10 +77,13 @@ type tailSamplingSpanProcessor struct {
 	policies           []*policy
 	idToTrace          map[pcommon.TraceID]*TraceData
 	tailStorage        tailstorageextension.TailStorage
+	persistentStorage  tailstorageextension.PersistentTailStorage
 	tickerFrequency    time.Duration
 	decisionBatcher    idbatcher.Batcher
 	sampledIDCache     cache.Cache
 	nonSampledIDCache  cache.Cache
+	storageClients     []storage.Client
+	sharedCaches       []*cache.StorageDecisionCache
 	recordPolicy       bool
 	useTracestate      bool
 	sampleOnFirstMatch bool
@@ -168,7 +172,7 @@ func (*tailSamplingSpanProcessor) Capabilities() consumer.Capabilities {
 }
 
 // Start is invoked during service startup.
-func (tsp *tailSamplingSpanProcessor) Start(_ context.Context, host component.Host) error {
+func (tsp *tailSamplingSpanProcessor) Start(ctx context.Context, host component.Host) error {
 	tsp.host = host
 	if tsp.cfg.TailStorageID != nil {
 		tailStorageExt, err := tailStorageExtension(host, *tsp.cfg.TailStorageID)
@@ -176,6 +180,9 @@ func (tsp *tailSamplingSpanProcessor) Start(_ context.Context, host component.Ho
 			return err
 		}
 		tsp.tailStorage = tailStorageExt
+		if persistentStorage, ok := tailStorageExt.(tailstorageextension.PersistentTailStorage); ok {
+			tsp.persistentStorage = persistentStorage
+		}
 	} else {
 		tsp.tailStorage = tailstorageextension.NewInMemoryTailStorage()
 	}
@@ -201,6 +208,18 @@ func (tsp *tailSamplingSpanProcessor) Start(_ context.Context, host component.Ho
 		tsp.decisionBatcher = idBatcher
 	}
 
+	if tsp.persistentStorage != nil {
+		if err := tsp.recoverFromTailStorage(); err != nil {
+			return err
+		}
+	}
+
+	if tsp.cfg.DecisionCache.StorageID != nil {
+		if err := tsp.shareDecisionCaches(ctx, host, *tsp.cfg.DecisionCache.StorageID); err != nil {
+			return err
+		}
+	}
+
 	tsp.doneChan = make(chan struct{})
 	go tsp.loop()
 	return nil
@@ -213,6 +232,7 @@ func (tsp *tailSamplingSpanProcessor) ConsumeTraces(ctx context.Context, td ptra
 
 	var totalSpans, totalTraces, totalResourceSpans int64
 
+	batches := make([][]traceBatch, 0, td.ResourceSpans().Len())
 	for _, rss := range td.ResourceSpans().All() {
 		totalResourceSpans++
 		// First group all spans by trace.
@@ -234,10 +254,26 @@ func (tsp *tailSamplingSpanProcessor) ConsumeTraces(ctx context.Context, td ptra
 			totalTraces++
 		}
 		if len(batch) > 0 {
-			tsp.workChan <- batch
+			batches = append(batches, batch)
 		}
 	}
 
+	// Look up the decisions shared by other processors for the whole request
+	// at once, instead of for every trace on the processing goroutine.
+	if len(tsp.sharedCaches) > 0 {
+		ids := make([]pcommon.TraceID, 0, totalTraces)
+		for _, batch := range batches {
+			for _, trace := range batch {
+				ids = append(ids, trace.id)
+			}
+		}
+		tsp.prefetchSharedDecisions(ctx, ids)
+	}
+
+	for _, batch := range batches {
+		tsp.workChan <- batch
+	}
+
 	if span.IsRecording() {
 		span.SetAttributes(
 			attribute.Int64("traces.count", totalTraces),
@@ -461,6 +497,8 @@ func getSharedPolicyEvaluator(settings component.TelemetrySettings, cfg *sharedP
 		return sampling.NewOTTLConditionFilter(settings, ottlfCfg.SpanConditions, ottlfCfg.SpanEventConditions, ottlfCfg.ErrorMode)
 	case TraceFlags:
 		return sampling.NewTraceFlags(settings), nil
+	case Extension:
+		return getExtensionPolicyEvaluator(cfg.Name, &cfg.ExtensionPolicyCfg, policyExtensions)
 	default:
 		t := string(cfg.Type)
 		extension, ok := policyExtensions[t]
@@ -476,6 +514,28 @@ func getSharedPolicyEvaluator(settings component.TelemetrySettings, cfg *sharedP
 	}
 }
 
+// getExtensionPolicyEvaluator creates the evaluator of an extension policy
+// using the sampling policy extension identified in its config. Evaluators
+// implementing samplingpolicy.ThresholdEvaluator keep reporting their
+// threshold, so it is propagated on tracestate like for built-in policies.
+func getExtensionPolicyEvaluator(policyName string, cfg *ExtensionPolicyCfg, policyExtensions map[string]samplingpolicy.Extension) (samplingpolicy.Evaluator, error) {
+	if cfg.ID == (component.ID{}) {
+		return nil, errors.New("extension policy requires extension::id to be set")
+	}
+	extension, ok := policyExtensions[cfg.ID.String()]
+	if !ok {
+		return nil, fmt.Errorf("extension %q not found or not a sampling policy extension", cfg.ID)
+	}
+	evaluator, err := extension.NewEvaluator(policyName, cfg.Config)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load extension %s: %w", cfg.ID, err)
+	}
+	if evaluator == nil {
+		return nil, fmt.Errorf("extension %s returned no evaluator", cfg.ID)
+	}
+	return evaluator, nil
+}
+
 type policyDecisionMetrics struct {
 	tracesSampled int
 	spansSampled  int64
@@ -569,9 +629,18 @@ func (tsp *tailSamplingSpanProcessor) iter(tickChan <-chan time.Time, workChan <
 			tsp.decisionBatcher.Stop()
 
 			// Do the best decision we can for any traces we have already ingested unless a user wants to drop them.
-			if !tsp.cfg.DropPendingTracesOnShutdown {
+			// Pending traces in a persistent tail storage are left for the next start to decide on time,
+			// unless a user wants to drop them, in which case they are deleted so that they are not recovered.
+			switch {
+			case !tsp.cfg.DropPendingTracesOnShutdown && tsp.persistentStorage == nil:
 				for tsp.samplingPolicyOnTick() {
 				}
+			case tsp.cfg.DropPendingTracesOnShutdown && tsp.persistentStorage != nil:
+				for id := range tsp.idToTrace {
+					if err := tsp.tailStorage.Delete(id); err != nil {
+						tsp.logger.Error("Failed to delete pending trace from tail storage", zap.Error(err))
+					}
+				}
 			}
 			return false
 		}
@@ -697,6 +766,18 @@ func (tsp *tailSamplingSpanProcessor) samplingPolicyOnTick() bool {
 		defer span.End()
 	}
 
+	// Another processor sharing the decision caches may have decided some of
+	// these traces already. Look them up at once before deciding.
+	if len(tsp.sharedCaches) > 0 && tsp.cfg.SamplingStrategy != samplingStrategySpanIngest {
+		ids := make([]pcommon.TraceID, 0, batchLen)
+		for id := range batch {
+			if trace, ok := tsp.idToTrace[id]; ok && trace.FinalDecision == samplingpolicy.Unspecified {
+				ids = append(ids, id)
+			}
+		}
+		tsp.prefetchSharedDecisions(ctx, ids)
+	}
+
 	for id := range batch {
 		trace, ok := tsp.idToTrace[id]
 		if !ok {
@@ -740,6 +821,33 @@ func (tsp *tailSamplingSpanProcessor) samplingPolicyOnTick() bool {
 		}
 
 		trace.decisionTime = time.Now()
+
+		// Another processor sharing the decision caches may have decided this
+		// trace already. Honor its decision to keep sampling consistent.
+		if len(tsp.sharedCaches) > 0 {
+			if decision, policyName, ok := tsp.sharedDecision(id); ok {
+				globalTracesSampledByDecision[decision]++
+				trace.ReceivedBatches = allSpans
+				trace.FinalDecision = decision
+				trace.PolicyName = policyName
+				if decision == samplingpolicy.Sampled {
+					metrics.decisionSampled++
+					if tsp.recordPolicy {
+						if policyName != "" {
+							sampling.SetAttrOnScopeSpans(allSpans, "tailsampling.policy", policyName)
+						}
+						sampling.SetBoolAttrOnScopeSpans(allSpans, "tailsampling.cached_decision", true)
+					}
+					tsp.releaseSampledTrace(ctx, id, trace)
+				} else {
+					metrics.decisionNotSampled++
+					tsp.releaseNotSampledTrace(id, trace)
+				}
+				trace.ReceivedBatches = ptrace.NewTraces()
+				continue
+			}
+		}
+
 		traceForDecision := samplingpolicy.TraceData{
 			SpanCount:       trace.SpanCount,
 			SizeBytes:       trace.SizeBytes,
@@ -1103,6 +1211,103 @@ func extensions(host component.Host) map[string]samplingpolicy.Extension {
 	return scoped
 }
 
+// recoverFromTailStorage rebuilds the decision caches and the pending-trace
+// index from a tail storage that persists them across restarts. Recovered
+// traces keep their original arrival time, so they are decided once
+// decision_wait has elapsed since their first span arrived.
+func (tsp *tailSamplingSpanProcessor) recoverFromTailStorage() error {
+	var decisions int
+	err := tsp.persistentStorage.RecoverDecisions(func(id pcommon.TraceID, sampled bool, policyName string) {
+		decisions++
+		metadata := cache.DecisionMetadata{PolicyName: policyName}
+		if sampled {
+			tsp.sampledIDCache.Put(id, metadata)
+		} else {
+			tsp.nonSampledIDCache.Put(id, metadata)
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("failed to recover decisions from tail storage: %w", err)
+	}
+
+	type pendingTrace struct {
+		id          pcommon.TraceID
+		arrivalTime time.Time
+		spanCount   int64
+		sizeBytes   uint64
+	}
+	var pending []pendingTrace
+	err = tsp.persistentStorage.RecoverPendingTraces(func(id pcommon.TraceID, arrivalTime time.Time, spanCount int64, sizeBytes uint64) {
+		pending = append(pending, pendingTrace{id: id, arrivalTime: arrivalTime, spanCount: spanCount, sizeBytes: sizeBytes})
+	})
+	if err != nil {
+		return fmt.Errorf("failed to recover pending traces from tail storage: %w", err)
+	}
+
+	// Insert in arrival order so that the oldest traces are evicted first,
+	// dropping the oldest ones now if they do not fit in num_traces.
+	slices.SortFunc(pending, func(a, b pendingTrace) int {
+		return a.arrivalTime.Compare(b.arrivalTime)
+	})
+	var dropped int
+	if uint64(len(pending)) > tsp.cfg.NumTraces {
+		excess := len(pending) - int(tsp.cfg.NumTraces)
+		for _, p := range pending[:excess] {
+			if err := tsp.tailStorage.Delete(p.id); err != nil {
+				return fmt.Errorf("failed to delete trace from tail storage: %w", err)
+			}
+		}
+		dropped = excess
+		pending = pending[excess:]
+	}
+
+	now := time.Now()
+	for _, p := range pending {
+		trace := &TraceData{
+			arrivalTime: p.arrivalTime,
+			TraceData: samplingpolicy.TraceData{
+				SpanCount:       p.spanCount,
+				SizeBytes:       p.sizeBytes,
+				ReceivedBatches: ptrace.NewTraces(),
+			},
+		}
+		tsp.idToTrace[p.id] = trace
+
+		trace.batchID = tsp.decisionBatcher.AddToCurrentBatch(p.id)
+		remainingWait := max(0, tsp.cfg.DecisionWait-now.Sub(p.arrivalTime))
+		trace.batchID = tsp.decisionBatcher.MoveToEarlierBatch(p.id, trace.batchID, uint64(remainingWait.Seconds()))
+
+		if !tsp.blockOnOverflow {
+			trace.deleteElement = tsp.deleteTraceQueue.PushBack(p.id)
+		}
+	}
+
+	tsp.logger.Info("Recovered tail sampling state from tail storage",
+		zap.Int("pending_traces", len(pending)),
+		zap.Int("pending_traces_dropped", dropped),
+		zap.Int("decisions", decisions),
+	)
+	return nil
+}
+
+// recordDecision persists a final decision when the tail storage supports it
+// and the matching decision cache is enabled to restore it into.
+func (tsp *tailSamplingSpanProcessor) recordDecision(id pcommon.TraceID, sampled bool, policyName string) {
+	if tsp.persistentStorage == nil {
+		return
+	}
+	cacheSize := tsp.cfg.DecisionCache.NonSampledCacheSize
+	if sampled {
+		cacheSize = tsp.cfg.DecisionCache.SampledCacheSize
+	}
+	if cacheSize <= 0 {
+		return
+	}
+	if err := tsp.persistentStorage.RecordDecision(id, sampled, policyName); err != nil {
+		tsp.logger.Error("Failed to record decision in tail storage", zap.Error(err))
+	}
+}
+
 func tailStorageExtension(host component.Host, storageID component.ID) (tailstorageextension.TailStorage, error) {
 	if host == nil {
 		return nil, errors.New("tail storage extension configured but host is nil")
@@ -1119,13 +1324,13 @@ func tailStorageExtension(host component.Host, storageID component.ID) (tailstor
 }
 
 // Shutdown is invoked during service shutdown.
-func (tsp *tailSamplingSpanProcessor) Shutdown(context.Context) error {
+func (tsp *tailSamplingSpanProcessor) Shutdown(ctx context.Context) error {
 	// All receivers will be shutdown before processors so no sends will be done anymore.
 	close(tsp.workChan)
 	if tsp.doneChan != nil {
 		<-tsp.doneChan
 	}
-	return nil
+	return tsp.shutdownSharedDecisionCaches(ctx)
 }
 
 // dropTrace removes the trace from all memory locations. Returns true if it was removed and false if not found.
@@ -1168,6 +1373,7 @@ func (tsp *tailSamplingSpanProcessor) releaseSampledTrace(ctx context.Context, i
 		hook(ctx, id, td)
 	}
 	tsp.sampledIDCache.Put(id, cache.DecisionMetadata{PolicyName: td.PolicyName})
+	tsp.recordDecision(id, true, td.PolicyName)
 	tsp.forwardSpans(ctx, td.ReceivedBatches)
 	_, ok := tsp.sampledIDCache.Get(id)
 	if ok {
@@ -1182,6 +1388,7 @@ func (tsp *tailSamplingSpanProcessor) releaseNotSampledTrace(id pcommon.TraceID,
 		hook(context.Background(), id, td)
 	}
 	tsp.nonSampledIDCache.Put(id, cache.DecisionMetadata{PolicyName: td.PolicyName})
+	tsp.recordDecision(id, false, td.PolicyName)
 	_, ok := tsp.nonSampledIDCache.Get(id)
 	if ok {
 		tsp.dropTrace(id, time.Now())
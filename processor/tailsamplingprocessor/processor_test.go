@@ -1657,6 +1657,110 @@ func TestExtension(t *testing.T) {
 	assert.Equal(t, map[string]any{"foo": "bar"}, host.extension.cfg)
 }
 
+func TestExtensionPolicy(t *testing.T) {
+	threshold, err := pkgsampling.ProbabilityToThreshold(0.25)
+	require.NoError(t, err)
+	ext := &thresholdExtension{threshold: threshold}
+
+	controller := newTestTSPController()
+	msp := new(consumertest.TracesSink)
+	cfg := Config{
+		SamplingStrategy: samplingStrategyTraceComplete,
+		DecisionWait:     defaultTestDecisionWait,
+		NumTraces:        defaultNumTraces,
+		PolicyCfgs: []PolicyCfg{
+			{
+				sharedPolicyCfg: sharedPolicyCfg{
+					Name: "extension-policy",
+					Type: Extension,
+					ExtensionPolicyCfg: ExtensionPolicyCfg{
+						ID:     testExtensionID,
+						Config: map[string]any{"foo": "bar"},
+					},
+				},
+			},
+		},
+		Options: []Option{
+			withTestController(controller),
+			withUseTracestate(),
+		},
+	}
+	p, err := newTracesProcessor(t.Context(), processortest.NewNopSettings(metadata.Type), msp, cfg)
+	require.NoError(t, err)
+	require.NoError(t, p.Start(t.Context(), &policyExtensionHost{extension: ext}))
+	defer func() {
+		require.NoError(t, p.Shutdown(t.Context()))
+	}()
+
+	assert.Equal(t, "extension-policy", ext.policyName)
+	assert.Equal(t, map[string]any{"foo": "bar"}, ext.cfg)
+
+	require.NoError(t, p.ConsumeTraces(t.Context(), simpleTraces()))
+	controller.waitForTick()
+	controller.waitForTick()
+
+	// The threshold reported by the extension evaluator is propagated.
+	require.Len(t, msp.AllTraces(), 1)
+	span := msp.AllTraces()[0].ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0)
+	assert.Equal(t, "ot=th:c", span.TraceState().AsRaw())
+}
+
+func TestExtensionPolicyErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     ExtensionPolicyCfg
+		host    component.Host
+		wantErr string
+	}{
+		{
+			name:    "missing id",
+			host:    &policyExtensionHost{extension: &thresholdExtension{}},
+			wantErr: "extension policy requires extension::id to be set",
+		},
+		{
+			name:    "extension not found",
+			cfg:     ExtensionPolicyCfg{ID: component.MustNewID("missing")},
+			host:    &policyExtensionHost{extension: &thresholdExtension{}},
+			wantErr: `extension "missing" not found or not a sampling policy extension`,
+		},
+		{
+			name:    "not a sampling policy extension",
+			cfg:     ExtensionPolicyCfg{ID: testExtensionID},
+			host:    &nonTailStorageExtensionHost{},
+			wantErr: `extension "my_extension" not found or not a sampling policy extension`,
+		},
+		{
+			name:    "invalid config",
+			cfg:     ExtensionPolicyCfg{ID: testExtensionID},
+			host:    &policyExtensionHost{extension: &thresholdExtension{err: errors.New("invalid config")}},
+			wantErr: "unable to load extension my_extension: invalid config",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{
+				SamplingStrategy: samplingStrategyTraceComplete,
+				DecisionWait:     defaultTestDecisionWait,
+				NumTraces:        defaultNumTraces,
+				PolicyCfgs: []PolicyCfg{
+					{
+						sharedPolicyCfg: sharedPolicyCfg{
+							Name:               "extension-policy",
+							Type:               Extension,
+							ExtensionPolicyCfg: tt.cfg,
+						},
+					},
+				},
+			}
+			p, err := newTracesProcessor(t.Context(), processortest.NewNopSettings(metadata.Type), consumertest.NewNop(), cfg)
+			require.NoError(t, err)
+			err = p.Start(t.Context(), tt.host)
+			require.ErrorContains(t, err, tt.wantErr)
+			require.NoError(t, p.Shutdown(t.Context()))
+		})
+	}
+}
+
 func TestTailStorageExtensionFromHost(t *testing.T) {
 	enableTailStorageFeatureGateForTest(t)
 
@@ -1891,6 +1995,116 @@ func TestTailStorageExtensionWrongType(t *testing.T) {
 	assert.Contains(t, err.Error(), "non-tail-storage extension 'my_extension' found")
 }
 
+func TestPersistentTailStorageRecovery(t *testing.T) {
+	enableTailStorageFeatureGateForTest(t)
+
+	pendingID := pcommon.TraceID([16]byte{1})
+	sampledID := pcommon.TraceID([16]byte{2})
+
+	ext := &persistentExtension{}
+	require.NoError(t, ext.Append(pendingID, simpleTracesWithID(pendingID)))
+	ext.arrivalTimes[pendingID] = time.Now().Add(-time.Hour)
+	require.NoError(t, ext.RecordDecision(sampledID, true, "earlier-policy"))
+
+	controller := newTestTSPController()
+	msp := new(consumertest.TracesSink)
+	cfg := Config{
+		DecisionWait:     defaultTestDecisionWait,
+		NumTraces:        defaultNumTraces,
+		SamplingStrategy: samplingStrategyTraceComplete,
+		PolicyCfgs:       testPolicy,
+		TailStorageID:    &testExtensionID,
+		DecisionCache: DecisionCacheConfig{
+			SampledCacheSize:    10,
+			NonSampledCacheSize: 10,
+		},
+		Options: []Option{
+			withTestController(controller),
+		},
+	}
+	p, err := newTracesProcessor(t.Context(), processortest.NewNopSettings(metadata.Type), msp, cfg)
+	require.NoError(t, err)
+	require.NoError(t, p.Start(t.Context(), &persistentExtensionHost{extension: ext}))
+	defer func() {
+		require.NoError(t, p.Shutdown(t.Context()))
+	}()
+
+	tsp := p.(*tailSamplingSpanProcessor)
+	require.Contains(t, tsp.idToTrace, pendingID)
+	assert.Equal(t, int64(1), tsp.idToTrace[pendingID].SpanCount)
+	assert.Equal(t, 1, tsp.deleteTraceQueue.Len())
+	metadata, ok := tsp.sampledIDCache.Get(sampledID)
+	require.True(t, ok)
+	assert.Equal(t, "earlier-policy", metadata.PolicyName)
+
+	// Late spans for a trace decided before the restart are released from the cache.
+	require.NoError(t, p.ConsumeTraces(t.Context(), simpleTracesWithID(sampledID)))
+	require.Len(t, msp.AllTraces(), 1)
+
+	// The recovered trace is decided on the next tick and its decision persisted.
+	controller.waitForTick()
+	controller.waitForTick()
+	require.Len(t, msp.AllTraces(), 2)
+	assert.Equal(t, 1, msp.AllTraces()[1].SpanCount())
+	assert.NotContains(t, tsp.idToTrace, pendingID)
+	assert.Contains(t, ext.decisions, pendingID)
+}
+
+func TestPersistentTailStorageKeepsPendingTracesOnShutdown(t *testing.T) {
+	enableTailStorageFeatureGateForTest(t)
+
+	msp := new(consumertest.TracesSink)
+	cfg := Config{
+		DecisionWait:     defaultTestDecisionWait,
+		NumTraces:        defaultNumTraces,
+		SamplingStrategy: samplingStrategyTraceComplete,
+		PolicyCfgs:       testPolicy,
+		TailStorageID:    &testExtensionID,
+	}
+	p, err := newTracesProcessor(t.Context(), processortest.NewNopSettings(metadata.Type), msp, cfg)
+	require.NoError(t, err)
+
+	ext := &persistentExtension{}
+	require.NoError(t, p.Start(t.Context(), &persistentExtensionHost{extension: ext}))
+	require.NoError(t, p.ConsumeTraces(t.Context(), simpleTraces()))
+	require.NoError(t, p.Shutdown(t.Context()))
+
+	assert.Empty(t, msp.AllTraces())
+	recovered := 0
+	require.NoError(t, ext.RecoverPendingTraces(func(pcommon.TraceID, time.Time, int64, uint64) {
+		recovered++
+	}))
+	assert.Equal(t, 1, recovered)
+}
+
+func TestPersistentTailStorageDropsPendingTracesOnShutdown(t *testing.T) {
+	enableTailStorageFeatureGateForTest(t)
+
+	msp := new(consumertest.TracesSink)
+	cfg := Config{
+		DecisionWait:                defaultTestDecisionWait,
+		NumTraces:                   defaultNumTraces,
+		SamplingStrategy:            samplingStrategyTraceComplete,
+		PolicyCfgs:                  testPolicy,
+		TailStorageID:               &testExtensionID,
+		DropPendingTracesOnShutdown: true,
+	}
+	p, err := newTracesProcessor(t.Context(), processortest.NewNopSettings(metadata.Type), msp, cfg)
+	require.NoError(t, err)
+
+	ext := &persistentExtension{}
+	require.NoError(t, p.Start(t.Context(), &persistentExtensionHost{extension: ext}))
+	require.NoError(t, p.ConsumeTraces(t.Context(), simpleTraces()))
+	require.NoError(t, p.Shutdown(t.Context()))
+
+	assert.Empty(t, msp.AllTraces())
+	recovered := 0
+	require.NoError(t, ext.RecoverPendingTraces(func(pcommon.TraceID, time.Time, int64, uint64) {
+		recovered++
+	}))
+	assert.Zero(t, recovered)
+}
+
 type extensionHost struct {
 	extension *extension
 }
@@ -1959,6 +2173,136 @@ func (*extension) Shutdown(_ context.Context) error {
 	return nil
 }
 
+type policyExtensionHost struct {
+	extension *thresholdExtension
+}
+
+func (h *policyExtensionHost) GetExtensions() map[component.ID]component.Component {
+	return map[component.ID]component.Component{
+		testExtensionID: h.extension,
+	}
+}
+
+// thresholdExtension creates evaluators sampling every trace with a fixed threshold.
+type thresholdExtension struct {
+	component.StartFunc
+	component.ShutdownFunc
+
+	threshold  pkgsampling.Threshold
+	err        error
+	policyName string
+	cfg        map[string]any
+}
+
+var _ samplingpolicy.Extension = &thresholdExtension{}
+
+func (e *thresholdExtension) NewEvaluator(policyName string, cfg map[string]any) (samplingpolicy.Evaluator, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	e.policyName = policyName
+	e.cfg = cfg
+	return &thresholdEvaluator{threshold: e.threshold}, nil
+}
+
+type thresholdEvaluator struct {
+	threshold pkgsampling.Threshold
+}
+
+var _ samplingpolicy.ThresholdEvaluator = &thresholdEvaluator{}
+
+func (e *thresholdEvaluator) Evaluate(ctx context.Context, traceID pcommon.TraceID, trace *samplingpolicy.TraceData) (samplingpolicy.Decision, error) {
+	decision, _, err := e.EvaluateWithThreshold(ctx, traceID, trace)
+	return decision, err
+}
+
+func (e *thresholdEvaluator) EvaluateWithThreshold(context.Context, pcommon.TraceID, *samplingpolicy.TraceData) (samplingpolicy.Decision, pkgsampling.Threshold, error) {
+	return samplingpolicy.Sampled, e.threshold, nil
+}
+
+func (*thresholdEvaluator) IsStateful() bool {
+	return false
+}
+
+type persistentExtensionHost struct {
+	extension *persistentExtension
+}
+
+func (h *persistentExtensionHost) GetExtensions() map[component.ID]component.Component {
+	return map[component.ID]component.Component{
+		testExtensionID: h.extension,
+	}
+}
+
+type persistedDecision struct {
+	sampled    bool
+	policyName string
+}
+
+// persistentExtension is an in-memory stand-in for a tail storage that
+// persists its state across restarts.
+type persistentExtension struct {
+	extension
+	arrivalTimes map[pcommon.TraceID]time.Time
+	spanCounts   map[pcommon.TraceID]int64
+	decisions    map[pcommon.TraceID]persistedDecision
+}
+
+var _ tailstorageextension.PersistentTailStorage = &persistentExtension{}
+
+func (e *persistentExtension) ensureState() {
+	if e.arrivalTimes == nil {
+		e.arrivalTimes = map[pcommon.TraceID]time.Time{}
+		e.spanCounts = map[pcommon.TraceID]int64{}
+		e.decisions = map[pcommon.TraceID]persistedDecision{}
+	}
+}
+
+func (e *persistentExtension) Append(traceID pcommon.TraceID, td ptrace.Traces) error {
+	e.ensureState()
+	if _, ok := e.arrivalTimes[traceID]; !ok {
+		e.arrivalTimes[traceID] = time.Now()
+	}
+	e.spanCounts[traceID] += int64(td.SpanCount())
+	return e.extension.Append(traceID, td)
+}
+
+func (e *persistentExtension) Take(traceID pcommon.TraceID) (ptrace.Traces, error) {
+	e.ensureState()
+	delete(e.arrivalTimes, traceID)
+	delete(e.spanCounts, traceID)
+	return e.extension.Take(traceID)
+}
+
+func (e *persistentExtension) Delete(traceID pcommon.TraceID) error {
+	e.ensureState()
+	delete(e.arrivalTimes, traceID)
+	delete(e.spanCounts, traceID)
+	return e.extension.Delete(traceID)
+}
+
+func (e *persistentExtension) RecoverPendingTraces(fn func(traceID pcommon.TraceID, arrivalTime time.Time, spanCount int64, sizeBytes uint64)) error {
+	e.ensureState()
+	for traceID, arrivalTime := range e.arrivalTimes {
+		fn(traceID, arrivalTime, e.spanCounts[traceID], 0)
+	}
+	return nil
+}
+
+func (e *persistentExtension) RecordDecision(traceID pcommon.TraceID, sampled bool, policyName string) error {
+	e.ensureState()
+	e.decisions[traceID] = persistedDecision{sampled: sampled, policyName: policyName}
+	return nil
+}
+
+func (e *persistentExtension) RecoverDecisions(fn func(traceID pcommon.TraceID, sampled bool, policyName string)) error {
+	e.ensureState()
+	for traceID, d := range e.decisions {
+		fn(traceID, d.sampled, d.policyName)
+	}
+	return nil
+}
+
 type nonTailStorageExtensionHost struct{}
 
 func (*nonTailStorageExtensionHost) GetExtensions() map[component.ID]component.Component {
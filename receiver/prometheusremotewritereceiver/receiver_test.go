@@ -130,7 +130,7 @@ func TestHandlePRWContentTypeNegotiation(t *testing.T) {
 		{
 			name:         "x-protobuf/no proto parameter",
 			contentType:  "application/x-protobuf",
-			expectedCode: http.StatusUnsupportedMediaType,
+			expectedCode: http.StatusNoContent,
 			expectedStats: remote.WriteResponseStats{
 				Confirmed:  false,
 				Samples:    0,
@@ -141,7 +141,7 @@ func TestHandlePRWContentTypeNegotiation(t *testing.T) {
 		{
 			name:         "x-protobuf/v1 proto parameter",
 			contentType:  fmt.Sprintf("application/x-protobuf;proto=%s", remoteapi.WriteV1MessageType),
-			expectedCode: http.StatusUnsupportedMediaType,
+			expectedCode: http.StatusNoContent,
 			expectedStats: remote.WriteResponseStats{
 				Confirmed:  false,
 				Samples:    0,
@@ -181,10 +181,15 @@ func TestHandlePRWContentTypeNegotiation(t *testing.T) {
 			resp := w.Result()
 
 			assert.Equal(t, tc.expectedCode, resp.StatusCode)
-			if tc.expectedCode == http.StatusNoContent { // We went until the end
+			if tc.expectedStats.Confirmed { // We went until the end of a Remote Write 2.0 request
 				assert.NotEmpty(t, resp.Header.Get("X-Prometheus-Remote-Write-Samples-Written"))
 				assert.NotEmpty(t, resp.Header.Get("X-Prometheus-Remote-Write-Histograms-Written"))
 				assert.NotEmpty(t, resp.Header.Get("X-Prometheus-Remote-Write-Exemplars-Written"))
+			} else {
+				// Remote Write 1.0 does not define the written stats headers.
+				assert.Empty(t, resp.Header.Get("X-Prometheus-Remote-Write-Samples-Written"))
+				assert.Empty(t, resp.Header.Get("X-Prometheus-Remote-Write-Histograms-Written"))
+				assert.Empty(t, resp.Header.Get("X-Prometheus-Remote-Write-Exemplars-Written"))
 			}
 		})
 	}
@@ -1199,6 +1204,65 @@ func TestTranslateV2(t *testing.T) {
 				return metrics
 			}(),
 		},
+		{
+			name: "NHCB without custom values is skipped",
+			request: &writev2.Request{
+				Symbols: []string{
+					"",
+					"__name__",
+					"test_hncb_histogram",
+					"job",
+					"test",
+					"instance",
+					"localhost:8080",
+				},
+				Timeseries: []writev2.TimeSeries{
+					{
+						LabelsRefs: []uint32{1, 2, 3, 4, 5, 6}, // __name__=test_hncb_histogram, job=test, instance=localhost:8080
+						Metadata: writev2.Metadata{
+							Type: writev2.Metadata_METRIC_TYPE_HISTOGRAM,
+						},
+						Histograms: []writev2.Histogram{
+							{
+								Timestamp:      123456789,
+								StartTimestamp: 123456000,
+								Schema:         -53, // NHCB schema
+								Sum:            100.5,
+								Count:          &writev2.Histogram_CountInt{CountInt: 10},
+								PositiveSpans: []writev2.BucketSpan{
+									{Offset: 0, Length: 1},
+								},
+								PositiveDeltas: []int64{10},
+							},
+						},
+					},
+				},
+			},
+			expectedStats: remote.WriteResponseStats{
+				Confirmed:  true,
+				Samples:    0,
+				Histograms: 0,
+				Exemplars:  0,
+			},
+			expectedMetrics: func() pmetric.Metrics {
+				metrics := pmetric.NewMetrics()
+				rm := metrics.ResourceMetrics().AppendEmpty()
+				attrs := rm.Resource().Attributes()
+				attrs.PutStr("service.name", "test")
+				attrs.PutStr("service.instance.id", "localhost:8080")
+
+				sm := rm.ScopeMetrics().AppendEmpty()
+				sm.Scope().SetName("OpenTelemetry Collector")
+				sm.Scope().SetVersion("latest")
+				m1 := sm.Metrics().AppendEmpty()
+				m1.SetName("test_hncb_histogram")
+				m1.Metadata().PutStr(prometheus.MetricMetadataTypeKey, "histogram")
+				// The histogram has no data points.
+				m1.SetEmptyHistogram().SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+
+				return metrics
+			}(),
+		},
 		{
 			name: "NHCB translation with stale NaN",
 			request: &writev2.Request{
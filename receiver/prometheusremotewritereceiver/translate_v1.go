@@ -0,0 +1,458 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package prometheusremotewritereceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/prometheusremotewritereceiver"
+
+import (
+	"context"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/model/value"
+	"github.com/prometheus/prometheus/prompb"
+	writev2 "github.com/prometheus/prometheus/prompb/io/prometheus/write/v2"
+	promremote "github.com/prometheus/prometheus/storage/remote"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/zap/zapcore"
+)
+
+// nhcbSchema is the native histogram schema of custom bucket histograms, the
+// form classic histograms are reassembled into.
+// See https://prometheus.io/docs/specs/native_histograms/#schema
+const nhcbSchema = -53
+
+// translateV1 translates a remote-write 1.0 request into OTLP metrics.
+//
+// The request is converted into a v2 request and translated by translateV2,
+// so both protocol versions share the same translation and resource cache.
+// As remote-write 1.0 rarely carries metadata, the type of series without
+// metadata in the request is inferred from their names, and the series of
+// classic histograms are reassembled into custom bucket histograms.
+func (prw *prometheusRemoteWriteReceiver) translateV1(ctx context.Context, req *prompb.WriteRequest) (pmetric.Metrics, promremote.WriteResponseStats, error) {
+	m, stats, err := prw.translateV2(ctx, prw.convertV1(req))
+	removeInfBounds(m)
+	return m, stats, err
+}
+
+// removeInfBounds removes the +Inf bound that classic histograms with only a
+// +Inf bucket are given, as custom bucket histograms without custom values
+// are dropped, along with the empty bucket above it.
+func removeInfBounds(m pmetric.Metrics) {
+	for i := 0; i < m.ResourceMetrics().Len(); i++ {
+		sms := m.ResourceMetrics().At(i).ScopeMetrics()
+		for j := 0; j < sms.Len(); j++ {
+			metrics := sms.At(j).Metrics()
+			for k := 0; k < metrics.Len(); k++ {
+				if metrics.At(k).Type() != pmetric.MetricTypeHistogram {
+					continue
+				}
+				dps := metrics.At(k).Histogram().DataPoints()
+				for l := 0; l < dps.Len(); l++ {
+					dp := dps.At(l)
+					bounds := dp.ExplicitBounds()
+					if bounds.Len() != 1 || !math.IsInf(bounds.At(0), 1) {
+						continue
+					}
+					bounds.FromRaw(nil)
+					dp.BucketCounts().FromRaw(dp.BucketCounts().AsRaw()[:1])
+				}
+			}
+		}
+	}
+}
+
+// classicHistogram collects the series of one classic histogram, identified
+// by its family name and labels other than "le".
+type classicHistogram struct {
+	ls        labels.Labels
+	metadata  writev2.Metadata
+	points    map[int64]*classicHistogramPoint
+	exemplars []writev2.Exemplar
+}
+
+// classicHistogramPoint collects the samples of a classic histogram at one
+// timestamp.
+type classicHistogramPoint struct {
+	buckets  []classicBucket
+	sum      float64
+	count    float64
+	hasSum   bool
+	hasCount bool
+	stale    bool
+}
+
+type classicBucket struct {
+	upperBound float64
+	count      float64
+}
+
+// v1Converter converts a remote-write 1.0 request into a v2 request.
+type v1Converter struct {
+	prw      *prometheusRemoteWriteReceiver
+	symbols  writev2.SymbolsTable
+	builder  labels.ScratchBuilder
+	metadata map[string]prompb.MetricMetadata
+
+	// bucketFamilies and summaryFamilies hold the family names of the
+	// classic histograms and summaries in the request. They tell the _sum
+	// and _count series of histograms and summaries apart from counters.
+	bucketFamilies  map[string]bool
+	summaryFamilies map[string]bool
+
+	histograms     map[string]*classicHistogram
+	histogramOrder []string
+}
+
+func (prw *prometheusRemoteWriteReceiver) convertV1(req *prompb.WriteRequest) *writev2.Request {
+	c := &v1Converter{
+		prw:             prw,
+		symbols:         writev2.NewSymbolTable(),
+		builder:         labels.NewScratchBuilder(0),
+		metadata:        make(map[string]prompb.MetricMetadata, len(req.Metadata)),
+		bucketFamilies:  make(map[string]bool),
+		summaryFamilies: make(map[string]bool),
+		histograms:      make(map[string]*classicHistogram),
+	}
+	for _, md := range req.Metadata {
+		c.metadata[md.MetricFamilyName] = md
+	}
+
+	series := make([]labels.Labels, len(req.Timeseries))
+	for i := range req.Timeseries {
+		ls := req.Timeseries[i].ToLabels(&c.builder, nil)
+		series[i] = ls
+		name := ls.Get(model.MetricNameLabel)
+		if family, ok := strings.CutSuffix(name, "_bucket"); ok && ls.Has(model.BucketLabel) {
+			c.bucketFamilies[family] = true
+		}
+		if ls.Has(model.QuantileLabel) {
+			c.summaryFamilies[name] = true
+		}
+	}
+
+	// target_info series go first, so that the resource attributes they carry
+	// are known before the other series of the request are translated.
+	var targetInfo, out []writev2.TimeSeries
+	for i := range req.Timeseries {
+		ts := &req.Timeseries[i]
+		ls := series[i]
+		name := ls.Get(model.MetricNameLabel)
+		metadata := c.metadataFor(name)
+
+		metricType := c.metricType(name, ls, metadata)
+		if len(ts.Histograms) > 0 && (metricType == writev2.Metadata_METRIC_TYPE_UNSPECIFIED || metricType == writev2.Metadata_METRIC_TYPE_HISTOGRAM) {
+			out = append(out, c.nativeHistogramSeries(ls, ts, metadata))
+			continue
+		}
+		switch metricType {
+		case writev2.Metadata_METRIC_TYPE_HISTOGRAM:
+			c.addClassicHistogramSeries(name, ls, ts, metadata)
+		case writev2.Metadata_METRIC_TYPE_SUMMARY, writev2.Metadata_METRIC_TYPE_GAUGEHISTOGRAM:
+			// Summaries are dropped like in remote-write 2.0; gauge histograms
+			// are not supported.
+			continue
+		default:
+			metadata.Type = metricType
+			v2ts := writev2.TimeSeries{
+				LabelsRefs: c.symbols.SymbolizeLabels(ls, nil),
+				Samples:    make([]writev2.Sample, 0, len(ts.Samples)),
+				Exemplars:  c.exemplars(ts.Exemplars),
+				Metadata:   metadata,
+			}
+			for _, s := range ts.Samples {
+				v2ts.Samples = append(v2ts.Samples, writev2.Sample{Value: s.Value, Timestamp: s.Timestamp})
+			}
+			if name == "target_info" {
+				targetInfo = append(targetInfo, v2ts)
+			} else {
+				out = append(out, v2ts)
+			}
+		}
+	}
+
+	for _, key := range c.histogramOrder {
+		if v2ts, ok := c.classicHistogramSeries(c.histograms[key]); ok {
+			out = append(out, v2ts)
+		}
+	}
+
+	return &writev2.Request{
+		Timeseries: append(targetInfo, out...),
+		Symbols:    c.symbols.Symbols(),
+	}
+}
+
+// metadataFor returns the v2 metadata of the metric family of name, from the
+// metadata sent in the request. The family name of counters and histograms
+// may or may not include the suffix of the series, depending on the
+// exposition format the metric was scraped with.
+func (c *v1Converter) metadataFor(name string) writev2.Metadata {
+	md, ok := c.metadata[name]
+	if !ok {
+		for _, suffix := range []string{"_total", "_bucket", "_count", "_sum"} {
+			if family, found := strings.CutSuffix(name, suffix); found {
+				if md, ok = c.metadata[family]; ok {
+					break
+				}
+			}
+		}
+	}
+	if !ok {
+		return writev2.Metadata{}
+	}
+	// The metric type enums of both protocol versions share their values.
+	return writev2.Metadata{
+		Type:    writev2.Metadata_MetricType(md.Type),
+		HelpRef: c.symbols.Symbolize(md.Help),
+		UnitRef: c.symbols.Symbolize(md.Unit),
+	}
+}
+
+// metricType returns the type of the series from its metadata, or infers it
+// from its name and labels when the request carries no metadata for it:
+//
+//   - _bucket series with an "le" label, and the _sum and _count series of
+//     the same family, are classic histograms;
+//   - series with a "quantile" label, and the _sum and _count series of the
+//     same family, are summaries;
+//   - other _total, _sum and _count series are counters;
+//   - everything else has an unknown type and is translated as a gauge.
+func (c *v1Converter) metricType(name string, ls labels.Labels, metadata writev2.Metadata) writev2.Metadata_MetricType {
+	if metadata.Type != writev2.Metadata_METRIC_TYPE_UNSPECIFIED {
+		return metadata.Type
+	}
+	if strings.HasSuffix(name, "_bucket") && ls.Has(model.BucketLabel) {
+		return writev2.Metadata_METRIC_TYPE_HISTOGRAM
+	}
+	if ls.Has(model.QuantileLabel) {
+		return writev2.Metadata_METRIC_TYPE_SUMMARY
+	}
+	for _, suffix := range []string{"_sum", "_count"} {
+		if family, ok := strings.CutSuffix(name, suffix); ok {
+			switch {
+			case c.bucketFamilies[family]:
+				return writev2.Metadata_METRIC_TYPE_HISTOGRAM
+			case c.summaryFamilies[family]:
+				return writev2.Metadata_METRIC_TYPE_SUMMARY
+			default:
+				return writev2.Metadata_METRIC_TYPE_COUNTER
+			}
+		}
+	}
+	if strings.HasSuffix(name, "_total") {
+		return writev2.Metadata_METRIC_TYPE_COUNTER
+	}
+	return writev2.Metadata_METRIC_TYPE_UNSPECIFIED
+}
+
+// exemplars converts remote-write 1.0 exemplars.
+func (c *v1Converter) exemplars(exemplars []prompb.Exemplar) []writev2.Exemplar {
+	if len(exemplars) == 0 {
+		return nil
+	}
+	out := make([]writev2.Exemplar, 0, len(exemplars))
+	for _, ex := range exemplars {
+		e := ex.ToExemplar(&c.builder, nil)
+		out = append(out, writev2.Exemplar{
+			LabelsRefs: c.symbols.SymbolizeLabels(e.Labels, nil),
+			Value:      e.Value,
+			Timestamp:  e.Ts,
+		})
+	}
+	return out
+}
+
+// nativeHistogramSeries converts a native histogram series.
+func (c *v1Converter) nativeHistogramSeries(ls labels.Labels, ts *prompb.TimeSeries, metadata writev2.Metadata) writev2.TimeSeries {
+	metadata.Type = writev2.Metadata_METRIC_TYPE_HISTOGRAM
+	v2ts := writev2.TimeSeries{
+		LabelsRefs: c.symbols.SymbolizeLabels(ls, nil),
+		Histograms: make([]writev2.Histogram, 0, len(ts.Histograms)),
+		Exemplars:  c.exemplars(ts.Exemplars),
+		Metadata:   metadata,
+	}
+	for _, h := range ts.Histograms {
+		if h.IsFloatHistogram() {
+			v2ts.Histograms = append(v2ts.Histograms, writev2.FromFloatHistogram(h.Timestamp, h.ToFloatHistogram()))
+		} else {
+			v2ts.Histograms = append(v2ts.Histograms, writev2.FromIntHistogram(h.Timestamp, h.ToIntHistogram()))
+		}
+	}
+	return v2ts
+}
+
+// addClassicHistogramSeries adds the samples of a _bucket, _sum or _count
+// series to its classic histogram.
+func (c *v1Converter) addClassicHistogramSeries(name string, ls labels.Labels, ts *prompb.TimeSeries, metadata writev2.Metadata) {
+	var (
+		family     string
+		suffix     string
+		upperBound float64
+	)
+	for _, s := range []string{"_bucket", "_sum", "_count"} {
+		if f, ok := strings.CutSuffix(name, s); ok {
+			family, suffix = f, s
+			break
+		}
+	}
+	if suffix == "" {
+		c.logDropped(name, "histogram series without a _bucket, _sum or _count suffix")
+		return
+	}
+	if suffix == "_bucket" {
+		var err error
+		upperBound, err = strconv.ParseFloat(ls.Get(model.BucketLabel), 64)
+		if err != nil {
+			c.logDropped(name, "histogram bucket with an invalid le label")
+			return
+		}
+	}
+
+	b := labels.NewBuilder(ls)
+	b.Del(model.BucketLabel)
+	b.Set(model.MetricNameLabel, family)
+	histLabels := b.Labels()
+	key := histLabels.String()
+
+	h, ok := c.histograms[key]
+	if !ok {
+		h = &classicHistogram{ls: histLabels, points: make(map[int64]*classicHistogramPoint)}
+		c.histograms[key] = h
+		c.histogramOrder = append(c.histogramOrder, key)
+	}
+	if h.metadata.HelpRef == 0 && h.metadata.UnitRef == 0 {
+		h.metadata = metadata
+	}
+	h.exemplars = append(h.exemplars, c.exemplars(ts.Exemplars)...)
+
+	for _, s := range ts.Samples {
+		p, ok := h.points[s.Timestamp]
+		if !ok {
+			p = &classicHistogramPoint{}
+			h.points[s.Timestamp] = p
+		}
+		if value.IsStaleNaN(s.Value) {
+			p.stale = true
+		}
+		switch suffix {
+		case "_bucket":
+			p.buckets = append(p.buckets, classicBucket{upperBound: upperBound, count: s.Value})
+		case "_sum":
+			p.sum, p.hasSum = s.Value, true
+		case "_count":
+			p.count, p.hasCount = s.Value, true
+		}
+	}
+}
+
+// classicHistogramSeries returns the custom bucket histogram series of a
+// classic histogram. Points without a +Inf bucket or a _sum are incomplete,
+// most likely because the histogram's series were split across requests, and
+// are dropped.
+func (c *v1Converter) classicHistogramSeries(h *classicHistogram) (writev2.TimeSeries, bool) {
+	timestamps := make([]int64, 0, len(h.points))
+	for t := range h.points {
+		timestamps = append(timestamps, t)
+	}
+	slices.Sort(timestamps)
+
+	histograms := make([]writev2.Histogram, 0, len(timestamps))
+	for _, t := range timestamps {
+		hist, reason := h.points[t].toHistogram(t)
+		if reason != "" {
+			c.logDropped(h.ls.Get(model.MetricNameLabel), reason)
+			continue
+		}
+		histograms = append(histograms, hist)
+	}
+	if len(histograms) == 0 {
+		return writev2.TimeSeries{}, false
+	}
+
+	metadata := h.metadata
+	metadata.Type = writev2.Metadata_METRIC_TYPE_HISTOGRAM
+	return writev2.TimeSeries{
+		LabelsRefs: c.symbols.SymbolizeLabels(h.ls, nil),
+		Histograms: histograms,
+		Exemplars:  h.exemplars,
+		Metadata:   metadata,
+	}, true
+}
+
+// toHistogram converts the cumulative bucket counts of p into a float custom
+// bucket histogram. It returns the reason when p cannot be converted.
+func (p *classicHistogramPoint) toHistogram(timestamp int64) (writev2.Histogram, string) {
+	slices.SortFunc(p.buckets, func(a, b classicBucket) int {
+		switch {
+		case a.upperBound < b.upperBound:
+			return -1
+		case a.upperBound > b.upperBound:
+			return 1
+		default:
+			return 0
+		}
+	})
+	if len(p.buckets) == 0 || !math.IsInf(p.buckets[len(p.buckets)-1].upperBound, 1) {
+		return writev2.Histogram{}, "incomplete classic histogram without a +Inf bucket"
+	}
+
+	bounds := make([]float64, 0, len(p.buckets)-1)
+	counts := make([]float64, 0, len(p.buckets))
+	var previous float64
+	for _, b := range p.buckets {
+		if !math.IsInf(b.upperBound, 1) {
+			bounds = append(bounds, b.upperBound)
+		}
+		if p.stale {
+			counts = append(counts, 0)
+			continue
+		}
+		if b.count < previous {
+			return writev2.Histogram{}, "classic histogram with decreasing cumulative bucket counts"
+		}
+		counts = append(counts, b.count-previous)
+		previous = b.count
+	}
+
+	if len(bounds) == 0 {
+		// A custom bucket histogram needs custom values: the +Inf bucket is
+		// given a +Inf bound, removed by removeInfBounds, and an empty
+		// bucket above it.
+		bounds = append(bounds, math.Inf(1))
+		counts = append(counts, 0)
+	}
+
+	hist := writev2.Histogram{
+		Schema:         nhcbSchema,
+		PositiveSpans:  []writev2.BucketSpan{{Offset: 0, Length: uint32(len(counts))}},
+		PositiveCounts: counts,
+		CustomValues:   bounds,
+		Timestamp:      timestamp,
+	}
+	switch {
+	case p.stale:
+		// Translated into a data point without a recorded value.
+		hist.Sum = math.Float64frombits(value.StaleNaN)
+		hist.Count = &writev2.Histogram_CountFloat{}
+	case !p.hasSum:
+		return writev2.Histogram{}, "incomplete classic histogram without a _sum series"
+	default:
+		count := previous
+		if p.hasCount {
+			count = p.count
+		}
+		hist.Sum = p.sum
+		hist.Count = &writev2.Histogram_CountFloat{CountFloat: count}
+	}
+	return hist, ""
+}
+
+func (c *v1Converter) logDropped(name, reason string) {
+	c.prw.settings.Logger.Debug("Dropping remote-write 1.0 series",
+		zapcore.Field{Key: "metric_name", Type: zapcore.StringType, String: name},
+		zapcore.Field{Key: "reason", Type: zapcore.StringType, String: reason})
+}
@@ -0,0 +1,299 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package prometheusremotewritereceiver
+
+import (
+	"bytes"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/prometheus/prometheus/model/histogram"
+	"github.com/prometheus/prometheus/model/value"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/pdatatest/pmetrictest"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/translator/prometheus"
+)
+
+// v1Series returns a remote-write 1.0 series of the given labels, as name
+// value pairs, with a sample per value at timestamps 1, 2, ...
+func v1Series(nameValues []string, values ...float64) prompb.TimeSeries {
+	ts := prompb.TimeSeries{}
+	for i := 0; i < len(nameValues); i += 2 {
+		ts.Labels = append(ts.Labels, prompb.Label{Name: nameValues[i], Value: nameValues[i+1]})
+	}
+	for i, v := range values {
+		ts.Samples = append(ts.Samples, prompb.Sample{Value: v, Timestamp: int64(i + 1)})
+	}
+	return ts
+}
+
+// newV1ExpectedMetrics returns metrics with the resource of job "test_job"
+// and instance "test_instance" and the default scope.
+func newV1ExpectedMetrics() (pmetric.Metrics, pmetric.ResourceMetrics, pmetric.ScopeMetrics) {
+	md := pmetric.NewMetrics()
+	rm := md.ResourceMetrics().AppendEmpty()
+	rm.Resource().Attributes().PutStr("service.name", "test_job")
+	rm.Resource().Attributes().PutStr("service.instance.id", "test_instance")
+	sm := rm.ScopeMetrics().AppendEmpty()
+	sm.Scope().SetName("OpenTelemetry Collector")
+	sm.Scope().SetVersion("latest")
+	return md, rm, sm
+}
+
+func TestTranslateV1(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		request  *prompb.WriteRequest
+		expected func() pmetric.Metrics
+	}{
+		{
+			name: "type inference and target_info",
+			request: &prompb.WriteRequest{
+				Timeseries: []prompb.TimeSeries{
+					v1Series([]string{"__name__", "http_requests_total", "instance", "test_instance", "job", "test_job", "code", "200"}, 10),
+					v1Series([]string{"__name__", "queue_length", "instance", "test_instance", "job", "test_job"}, 3),
+					v1Series([]string{"__name__", "target_info", "instance", "test_instance", "job", "test_job", "region", "eu-west-1"}, 1),
+				},
+			},
+			expected: func() pmetric.Metrics {
+				md, rm, sm := newV1ExpectedMetrics()
+				rm.Resource().Attributes().PutStr("region", "eu-west-1")
+
+				counter := sm.Metrics().AppendEmpty()
+				counter.SetName("http_requests_total")
+				counter.Metadata().PutStr(prometheus.MetricMetadataTypeKey, "counter")
+				sum := counter.SetEmptySum()
+				sum.SetIsMonotonic(true)
+				sum.SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+				dp := sum.DataPoints().AppendEmpty()
+				dp.SetTimestamp(pcommon.Timestamp(time.Millisecond))
+				dp.SetDoubleValue(10)
+				dp.Attributes().PutStr("code", "200")
+
+				gauge := sm.Metrics().AppendEmpty()
+				gauge.SetName("queue_length")
+				gauge.Metadata().PutStr(prometheus.MetricMetadataTypeKey, "unknown")
+				dp = gauge.SetEmptyGauge().DataPoints().AppendEmpty()
+				dp.SetTimestamp(pcommon.Timestamp(time.Millisecond))
+				dp.SetDoubleValue(3)
+				return md
+			},
+		},
+		{
+			name: "metadata in the request",
+			request: &prompb.WriteRequest{
+				Timeseries: []prompb.TimeSeries{
+					v1Series([]string{"__name__", "jobs_processed", "instance", "test_instance", "job", "test_job"}, 5),
+					v1Series([]string{"__name__", "temperature_sum", "instance", "test_instance", "job", "test_job"}, 21.5),
+				},
+				Metadata: []prompb.MetricMetadata{
+					{Type: prompb.MetricMetadata_COUNTER, MetricFamilyName: "jobs_processed", Help: "Processed jobs.", Unit: "jobs"},
+					{Type: prompb.MetricMetadata_GAUGE, MetricFamilyName: "temperature_sum", Unit: "celsius"},
+				},
+			},
+			expected: func() pmetric.Metrics {
+				md, _, sm := newV1ExpectedMetrics()
+
+				counter := sm.Metrics().AppendEmpty()
+				counter.SetName("jobs_processed")
+				counter.SetDescription("Processed jobs.")
+				counter.SetUnit(prometheus.UnitWordToUCUM("jobs"))
+				counter.Metadata().PutStr(prometheus.MetricMetadataTypeKey, "counter")
+				sum := counter.SetEmptySum()
+				sum.SetIsMonotonic(true)
+				sum.SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+				dp := sum.DataPoints().AppendEmpty()
+				dp.SetTimestamp(pcommon.Timestamp(time.Millisecond))
+				dp.SetDoubleValue(5)
+
+				gauge := sm.Metrics().AppendEmpty()
+				gauge.SetName("temperature_sum")
+				gauge.SetUnit(prometheus.UnitWordToUCUM("celsius"))
+				gauge.Metadata().PutStr(prometheus.MetricMetadataTypeKey, "gauge")
+				dp = gauge.SetEmptyGauge().DataPoints().AppendEmpty()
+				dp.SetTimestamp(pcommon.Timestamp(time.Millisecond))
+				dp.SetDoubleValue(21.5)
+				return md
+			},
+		},
+		{
+			name: "classic histogram reassembly",
+			request: &prompb.WriteRequest{
+				Timeseries: []prompb.TimeSeries{
+					v1Series([]string{"__name__", "rpc_duration_seconds_bucket", "instance", "test_instance", "job", "test_job", "le", "+Inf"}, 6, 8),
+					v1Series([]string{"__name__", "rpc_duration_seconds_bucket", "instance", "test_instance", "job", "test_job", "le", "0.1"}, 2, 2),
+					v1Series([]string{"__name__", "rpc_duration_seconds_bucket", "instance", "test_instance", "job", "test_job", "le", "1"}, 5, 6),
+					v1Series([]string{"__name__", "rpc_duration_seconds_count", "instance", "test_instance", "job", "test_job"}, 6, 8),
+					v1Series([]string{"__name__", "rpc_duration_seconds_sum", "instance", "test_instance", "job", "test_job"}, 4.2, 7.5),
+					// Incomplete: the +Inf bucket is missing.
+					v1Series([]string{"__name__", "partial_seconds_bucket", "instance", "test_instance", "job", "test_job", "le", "1"}, 1),
+					v1Series([]string{"__name__", "partial_seconds_sum", "instance", "test_instance", "job", "test_job"}, 0.5),
+				},
+			},
+			expected: func() pmetric.Metrics {
+				md, _, sm := newV1ExpectedMetrics()
+				m := sm.Metrics().AppendEmpty()
+				m.SetName("rpc_duration_seconds")
+				m.Metadata().PutStr(prometheus.MetricMetadataTypeKey, "histogram")
+				hist := m.SetEmptyHistogram()
+				hist.SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+
+				dp := hist.DataPoints().AppendEmpty()
+				dp.SetTimestamp(pcommon.Timestamp(time.Millisecond))
+				dp.SetCount(6)
+				dp.SetSum(4.2)
+				dp.ExplicitBounds().FromRaw([]float64{0.1, 1})
+				dp.BucketCounts().FromRaw([]uint64{2, 3, 1})
+
+				dp = hist.DataPoints().AppendEmpty()
+				dp.SetTimestamp(pcommon.Timestamp(2 * time.Millisecond))
+				dp.SetCount(8)
+				dp.SetSum(7.5)
+				dp.ExplicitBounds().FromRaw([]float64{0.1, 1})
+				dp.BucketCounts().FromRaw([]uint64{2, 4, 2})
+				return md
+			},
+		},
+		{
+			name: "classic histogram with only a +Inf bucket",
+			request: &prompb.WriteRequest{
+				Timeseries: []prompb.TimeSeries{
+					v1Series([]string{"__name__", "queue_wait_seconds_bucket", "instance", "test_instance", "job", "test_job", "le", "+Inf"}, 3),
+					v1Series([]string{"__name__", "queue_wait_seconds_count", "instance", "test_instance", "job", "test_job"}, 3),
+					v1Series([]string{"__name__", "queue_wait_seconds_sum", "instance", "test_instance", "job", "test_job"}, 1.5),
+				},
+			},
+			expected: func() pmetric.Metrics {
+				md, _, sm := newV1ExpectedMetrics()
+				m := sm.Metrics().AppendEmpty()
+				m.SetName("queue_wait_seconds")
+				m.Metadata().PutStr(prometheus.MetricMetadataTypeKey, "histogram")
+				hist := m.SetEmptyHistogram()
+				hist.SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+
+				dp := hist.DataPoints().AppendEmpty()
+				dp.SetTimestamp(pcommon.Timestamp(time.Millisecond))
+				dp.SetCount(3)
+				dp.SetSum(1.5)
+				dp.BucketCounts().FromRaw([]uint64{3})
+				return md
+			},
+		},
+		{
+			name: "summaries are dropped",
+			request: &prompb.WriteRequest{
+				Timeseries: []prompb.TimeSeries{
+					v1Series([]string{"__name__", "gc_seconds", "instance", "test_instance", "job", "test_job", "quantile", "0.5"}, 0.01),
+					v1Series([]string{"__name__", "gc_seconds_sum", "instance", "test_instance", "job", "test_job"}, 2),
+					v1Series([]string{"__name__", "gc_seconds_count", "instance", "test_instance", "job", "test_job"}, 100),
+					v1Series([]string{"__name__", "events_count", "instance", "test_instance", "job", "test_job"}, 7),
+				},
+			},
+			expected: func() pmetric.Metrics {
+				md, _, sm := newV1ExpectedMetrics()
+				m := sm.Metrics().AppendEmpty()
+				m.SetName("events_count")
+				m.Metadata().PutStr(prometheus.MetricMetadataTypeKey, "counter")
+				sum := m.SetEmptySum()
+				sum.SetIsMonotonic(true)
+				sum.SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+				dp := sum.DataPoints().AppendEmpty()
+				dp.SetTimestamp(pcommon.Timestamp(time.Millisecond))
+				dp.SetDoubleValue(7)
+				return md
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			prwReceiver := setupMetricsReceiver(t)
+			metrics, _, err := prwReceiver.translateV1(t.Context(), tc.request)
+			require.NoError(t, err)
+			assert.NoError(t, pmetrictest.CompareMetrics(tc.expected(), metrics))
+		})
+	}
+}
+
+func TestTranslateV1StaleClassicHistogram(t *testing.T) {
+	stale := math.Float64frombits(value.StaleNaN)
+	prwReceiver := setupMetricsReceiver(t)
+	metrics, _, err := prwReceiver.translateV1(t.Context(), &prompb.WriteRequest{
+		Timeseries: []prompb.TimeSeries{
+			v1Series([]string{"__name__", "rpc_duration_seconds_bucket", "job", "test_job", "le", "1"}, stale),
+			v1Series([]string{"__name__", "rpc_duration_seconds_bucket", "job", "test_job", "le", "+Inf"}, stale),
+			v1Series([]string{"__name__", "rpc_duration_seconds_sum", "job", "test_job"}, stale),
+			v1Series([]string{"__name__", "rpc_duration_seconds_count", "job", "test_job"}, stale),
+		},
+	})
+	require.NoError(t, err)
+
+	dps := metrics.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Histogram().DataPoints()
+	require.Equal(t, 1, dps.Len())
+	assert.True(t, dps.At(0).Flags().NoRecordedValue())
+}
+
+func TestTranslateV1NativeHistogram(t *testing.T) {
+	h := &histogram.Histogram{
+		Count:           5,
+		Sum:             10,
+		Schema:          0,
+		ZeroThreshold:   0.001,
+		ZeroCount:       1,
+		PositiveSpans:   []histogram.Span{{Offset: 1, Length: 2}},
+		PositiveBuckets: []int64{2, 0},
+	}
+	ts := v1Series([]string{"__name__", "latency_seconds", "job", "test_job"})
+	ts.Histograms = []prompb.Histogram{prompb.FromIntHistogram(1, h)}
+
+	prwReceiver := setupMetricsReceiver(t)
+	metrics, stats, err := prwReceiver.translateV1(t.Context(), &prompb.WriteRequest{Timeseries: []prompb.TimeSeries{ts}})
+	require.NoError(t, err)
+	assert.Equal(t, 1, stats.Histograms)
+
+	m := metrics.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0)
+	require.Equal(t, pmetric.MetricTypeExponentialHistogram, m.Type())
+	dp := m.ExponentialHistogram().DataPoints().At(0)
+	assert.Equal(t, uint64(5), dp.Count())
+	assert.Equal(t, uint64(1), dp.ZeroCount())
+	assert.Equal(t, []uint64{2, 2}, dp.Positive().BucketCounts().AsRaw())
+}
+
+// TestHandlePRWV1 verifies that remote-write 1.0 requests, sent without a
+// proto parameter, are accepted and share the resource cache with v2.
+func TestHandlePRWV1(t *testing.T) {
+	mockConsumer := new(mockConsumer)
+	prwReceiver := setupMetricsReceiver(t)
+	prwReceiver.nextConsumer = mockConsumer
+
+	send := func(req *prompb.WriteRequest) {
+		t.Helper()
+		body, err := proto.Marshal(req)
+		require.NoError(t, err)
+		httpReq := httptest.NewRequest(http.MethodPost, "/api/v1/write", bytes.NewReader(body))
+		httpReq.Header.Set("Content-Type", "application/x-protobuf")
+		w := httptest.NewRecorder()
+		prwReceiver.handlePRW(w, httpReq)
+		require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+	}
+
+	send(&prompb.WriteRequest{Timeseries: []prompb.TimeSeries{
+		v1Series([]string{"__name__", "target_info", "instance", "test_instance", "job", "test_job", "region", "eu-west-1"}, 1),
+	}})
+	send(&prompb.WriteRequest{Timeseries: []prompb.TimeSeries{
+		v1Series([]string{"__name__", "up", "instance", "test_instance", "job", "test_job"}, 1),
+	}})
+
+	require.Len(t, mockConsumer.metrics, 1)
+	region, ok := mockConsumer.metrics[0].ResourceMetrics().At(0).Resource().Attributes().Get("region")
+	require.True(t, ok, "target_info of an earlier request is promoted")
+	assert.Equal(t, "eu-west-1", region.Str())
+}
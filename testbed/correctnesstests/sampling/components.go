@@ -0,0 +1,47 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package sampling // import "github.com/open-telemetry/opentelemetry-collector-contrib/testbed/correctnesstests/sampling"
+
+import (
+	"go.opentelemetry.io/collector/exporter"
+	"go.opentelemetry.io/collector/exporter/otlpexporter"
+	"go.opentelemetry.io/collector/otelcol"
+	"go.opentelemetry.io/collector/processor"
+	"go.opentelemetry.io/collector/receiver"
+	"go.opentelemetry.io/collector/receiver/otlpreceiver"
+	"go.opentelemetry.io/collector/service/telemetry/otelconftelemetry"
+	"go.uber.org/multierr"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/loadbalancingexporter"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/tailsamplingprocessor"
+)
+
+// factories returns the minimal set of component factories needed by the
+// sampling correctness tests, for both the front and the backend collectors.
+func factories() (otelcol.Factories, error) {
+	var errs error
+
+	receivers, err := otelcol.MakeFactoryMap[receiver.Factory](
+		otlpreceiver.NewFactory(),
+	)
+	errs = multierr.Append(errs, err)
+
+	exporters, err := otelcol.MakeFactoryMap[exporter.Factory](
+		loadbalancingexporter.NewFactory(),
+		otlpexporter.NewFactory(),
+	)
+	errs = multierr.Append(errs, err)
+
+	processors, err := otelcol.MakeFactoryMap[processor.Factory](
+		tailsamplingprocessor.NewFactory(),
+	)
+	errs = multierr.Append(errs, err)
+
+	return otelcol.Factories{
+		Receivers:  receivers,
+		Processors: processors,
+		Exporters:  exporters,
+		Telemetry:  otelconftelemetry.NewFactory(),
+	}, errs
+}
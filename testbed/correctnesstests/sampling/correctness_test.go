@@ -0,0 +1,120 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package sampling
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/otelcol"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/common/testutil"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/testbed/testbed"
+)
+
+var correctnessResults testbed.TestResultsSummary = &testbed.CorrectnessResults{}
+
+func TestMain(m *testing.M) {
+	testbed.DoTestMain(m, correctnessResults)
+}
+
+func TestLoadBalancingTailSampling(t *testing.T) {
+	const (
+		numBackends  = 3
+		decisionWait = 2 * time.Second
+	)
+	spec := traceSpec{
+		numTraces:   90,
+		numBatches:  5,
+		numServices: 3,
+		errorEvery:  3,
+	}
+
+	f, err := factories()
+	require.NoError(t, err, "default components resulted in: %v", err)
+
+	backends := make([]*testbed.MockBackend, numBackends)
+	backendPorts := make([]int, numBackends)
+	for i := range backends {
+		receiver := testbed.NewOTLPDataReceiver(testutil.GetAvailablePort(t))
+		backends[i] = testbed.NewMockBackend(filepath.Join(t.TempDir(), fmt.Sprintf("backend-%d.log", i)), receiver)
+		backends[i].EnableRecording()
+		require.NoError(t, backends[i].Start())
+		t.Cleanup(backends[i].Stop)
+
+		backendPorts[i] = testutil.GetAvailablePort(t)
+		startCollector(t, f, fmt.Sprintf("backend-%d", i), createBackendConfigYaml(t, backendPorts[i], receiver, decisionWait))
+	}
+
+	sender := testbed.NewOTLPTraceDataSender(testbed.DefaultHost, testutil.GetAvailablePort(t))
+	startCollector(t, f, "front", createFrontConfigYaml(t, sender, backendPorts))
+	require.NoError(t, sender.Start())
+
+	// Send the batches over several ticks of the tail sampling processors, all within
+	// the decision wait of the first one.
+	for _, td := range spec.generateBatches() {
+		require.NoError(t, sender.ConsumeTraces(t.Context(), td))
+		time.Sleep(200 * time.Millisecond)
+	}
+
+	var expectedTraces int
+	for i := range spec.numTraces {
+		if spec.sampled(i) {
+			expectedTraces++
+		}
+	}
+	expectedSpans := uint64(expectedTraces * spec.numBatches)
+	assert.Eventually(t, func() bool {
+		var received uint64
+		for _, backend := range backends {
+			received += backend.DataItemsReceived()
+		}
+		return received >= expectedSpans
+	}, 10*decisionWait, 100*time.Millisecond, "all sampled spans received")
+
+	received := indexReceivedSpans(backends)
+	backendsWithTraces := map[int]bool{}
+	for i := range spec.numTraces {
+		counts, ok := received[traceID(i)]
+		if !spec.sampled(i) {
+			assert.False(t, ok, "trace %d should have been dropped, received spans: %v", i, counts)
+			continue
+		}
+		if !assert.True(t, ok, "trace %d should have been sampled", i) {
+			continue
+		}
+		var receivedBy []int
+		for b, n := range counts {
+			if n == 0 {
+				continue
+			}
+			receivedBy = append(receivedBy, b)
+			backendsWithTraces[b] = true
+			assert.Equal(t, spec.numBatches, n, "trace %d is incomplete at backend %d", i, b)
+		}
+		assert.Len(t, receivedBy, 1, "trace %d should be received by exactly one backend, received by %v", i, receivedBy)
+	}
+	assert.Len(t, received, expectedTraces, "unexpected traces received")
+	assert.Greater(t, len(backendsWithTraces), 1, "traces should be spread across the backends")
+}
+
+// startCollector starts an in-process collector with the given config, stopping it when the test ends.
+func startCollector(t *testing.T, f otelcol.Factories, name, config string) {
+	log.Println(config)
+	runner := testbed.NewInProcessCollector(f)
+	configCleanup, err := runner.PrepareConfig(t, config)
+	require.NoError(t, err, "collector configuration resulted in: %v", err)
+	t.Cleanup(configCleanup)
+
+	require.NoError(t, runner.Start(testbed.StartParams{Name: name}))
+	t.Cleanup(func() {
+		_, stopErr := runner.Stop()
+		assert.NoError(t, stopErr)
+	})
+}
@@ -0,0 +1,15 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package sampling contains correctness tests for multi-collector topologies doing tail sampling. A front
+// collector load balances the traces by trace ID across several backend collectors running the tail sampling
+// processor, each exporting to its own mock backend:
+//
+//	                                                   -> [otelcol tail_sampling 1] -> [mock backend 1]
+//	[testbed exporter] -> [otelcol load_balancing] -> ...
+//	                                                   -> [otelcol tail_sampling N] -> [mock backend N]
+//
+// The spans of every trace are spread across several batches, so a tail sampling decision is only correct if
+// all of them were routed to the same backend collector. The tests assert that every sampled trace is received
+// complete by exactly one mock backend, and that no span of a trace that should be dropped is received.
+package sampling // import "github.com/open-telemetry/opentelemetry-collector-contrib/testbed/correctnesstests/sampling"
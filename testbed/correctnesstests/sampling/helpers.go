@@ -0,0 +1,206 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package sampling // import "github.com/open-telemetry/opentelemetry-collector-contrib/testbed/correctnesstests/sampling"
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/common/testutil"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/testbed/testbed"
+)
+
+// telemetryConfigYAML returns the telemetry section of a collector config, serving
+// the internal metrics on a free port so that several collectors can run in-process.
+func telemetryConfigYAML(tb testing.TB) string {
+	return fmt.Sprintf(`
+  telemetry:
+    metrics:
+      readers:
+        - pull:
+            exporter:
+              prometheus:
+                host: '127.0.0.1'
+                port: %d`, testutil.GetAvailablePort(tb))
+}
+
+// createFrontConfigYaml creates the config of the front collector, receiving the data
+// of sender and load balancing it by trace ID across the given backend collector ports.
+func createFrontConfigYaml(tb testing.TB, sender testbed.DataSender, backendPorts []int) string {
+	var hostnames strings.Builder
+	for _, port := range backendPorts {
+		fmt.Fprintf(&hostnames, `
+          - "%s:%d"`, testbed.DefaultHost, port)
+	}
+
+	format := `
+receivers:%v
+exporters:
+  load_balancing:
+    routing_key: traceID
+    protocol:
+      otlp:
+        timeout: 5s
+        tls:
+          insecure: true
+    resolver:
+      static:
+        hostnames:%s
+
+service:%s
+  pipelines:
+    traces:
+      receivers: [%v]
+      exporters: [load_balancing]
+`
+	return fmt.Sprintf(
+		format,
+		sender.GenConfigYAMLStr(),
+		hostnames.String(),
+		telemetryConfigYAML(tb),
+		sender.ProtocolName(),
+	)
+}
+
+// createBackendConfigYaml creates the config of a backend collector listening on port,
+// keeping the traces with an error span and exporting them to receiver.
+func createBackendConfigYaml(tb testing.TB, port int, receiver testbed.DataReceiver, decisionWait time.Duration) string {
+	format := `
+receivers:
+  otlp:
+    protocols:
+      grpc:
+        endpoint: "%s:%d"
+exporters:%v
+processors:
+  tail_sampling:
+    decision_wait: %s
+    policies:
+      - name: errors
+        type: status_code
+        status_code:
+          status_codes: [ERROR]
+
+service:%s
+  pipelines:
+    traces:
+      receivers: [otlp]
+      processors: [tail_sampling]
+      exporters: [%v]
+`
+	return fmt.Sprintf(
+		format,
+		testbed.DefaultHost,
+		port,
+		receiver.GenConfigYAMLStr(),
+		decisionWait,
+		telemetryConfigYAML(tb),
+		receiver.ProtocolName(),
+	)
+}
+
+// traceSpec describes the traces generated for a test.
+type traceSpec struct {
+	// numTraces is the number of traces to generate.
+	numTraces int
+	// numBatches is the number of batches the spans of every trace are spread across,
+	// every trace having one span per batch.
+	numBatches int
+	// numServices is the number of services, i.e. resources, the spans are spread across.
+	// Consecutive spans of a trace belong to different services, so that routing by
+	// anything but the trace ID splits the traces.
+	numServices int
+	// errorEvery sets the status of the last span of every errorEvery-th trace to error,
+	// which is what the backend collectors sample on.
+	errorEvery int
+}
+
+// traceID returns the ID of the i-th trace.
+func traceID(i int) pcommon.TraceID {
+	var id pcommon.TraceID
+	binary.BigEndian.PutUint64(id[:8], 0x5a3e_c0de_0000_0000)
+	binary.BigEndian.PutUint64(id[8:], uint64(i))
+	return id
+}
+
+// spanID returns the ID of the span of the i-th trace in the given batch.
+func spanID(i, batch int) pcommon.SpanID {
+	var id pcommon.SpanID
+	binary.BigEndian.PutUint32(id[:4], uint32(i))
+	binary.BigEndian.PutUint32(id[4:], uint32(batch+1))
+	return id
+}
+
+// sampled returns whether the i-th trace should be kept by the backend collectors.
+func (s traceSpec) sampled(i int) bool {
+	return i%s.errorEvery == 0
+}
+
+// generateBatches generates the batches to send, every one of them holding one span of
+// every trace, so that no trace is complete before its last batch was received. The
+// error span deciding whether a trace is sampled is in the last batch.
+func (s traceSpec) generateBatches() []ptrace.Traces {
+	start := pcommon.NewTimestampFromTime(time.Now())
+	batches := make([]ptrace.Traces, s.numBatches)
+	for batch := range batches {
+		td := ptrace.NewTraces()
+		scopeSpans := make([]ptrace.ScopeSpans, s.numServices)
+		for service := range scopeSpans {
+			rs := td.ResourceSpans().AppendEmpty()
+			rs.Resource().Attributes().PutStr("service.name", fmt.Sprintf("service-%d", service))
+			scopeSpans[service] = rs.ScopeSpans().AppendEmpty()
+		}
+		for i := range s.numTraces {
+			span := scopeSpans[(i+batch)%s.numServices].Spans().AppendEmpty()
+			span.SetTraceID(traceID(i))
+			span.SetSpanID(spanID(i, batch))
+			if batch > 0 {
+				span.SetParentSpanID(spanID(i, 0))
+			}
+			span.SetName(fmt.Sprintf("operation-%d", batch))
+			span.SetKind(ptrace.SpanKindServer)
+			span.SetStartTimestamp(start)
+			span.SetEndTimestamp(start + pcommon.Timestamp(time.Millisecond))
+			if batch == s.numBatches-1 && s.sampled(i) {
+				span.Status().SetCode(ptrace.StatusCodeError)
+			}
+		}
+		batches[batch] = td
+	}
+	return batches
+}
+
+// receivedSpans maps the trace IDs to the number of their spans received by every
+// backend, indexed like the backends.
+type receivedSpans map[pcommon.TraceID][]int
+
+// indexReceivedSpans counts the spans received by every backend, per trace.
+func indexReceivedSpans(backends []*testbed.MockBackend) receivedSpans {
+	received := receivedSpans{}
+	for b, backend := range backends {
+		for _, td := range backend.GetReceivedTraces() {
+			rss := td.ResourceSpans()
+			for i := 0; i < rss.Len(); i++ {
+				ilss := rss.At(i).ScopeSpans()
+				for j := 0; j < ilss.Len(); j++ {
+					spans := ilss.At(j).Spans()
+					for k := 0; k < spans.Len(); k++ {
+						id := spans.At(k).TraceID()
+						if received[id] == nil {
+							received[id] = make([]int, len(backends))
+						}
+						received[id][b]++
+					}
+				}
+			}
+		}
+	}
+	return received
+}